@@ -1,7 +1,7 @@
-//go:build darwin
-// +build darwin
+//go:build darwin || linux
+// +build darwin linux
 
-// Package main implements the macOS menu bar application for Legible.
+// Package main implements the macOS menu bar and Linux system tray application for Legible.
 // This application provides a system tray interface for managing the Legible daemon
 // and triggering document synchronization from reMarkable tablets.
 package main
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/platinummonkey/legible/internal/rmclient"
+	"github.com/spf13/cobra"
+)
+
+// accountCmd groups commands about the reMarkable account
+var accountCmd = &cobra.Command{
+	Use:   "account",
+	Short: "Show the authenticated reMarkable account",
+}
+
+// accountInfoCmd shows which account legible is authenticated as
+var accountInfoCmd = &cobra.Command{
+	Use:   "info",
+	Short: "Show the account legible is authenticated as",
+	Long: `Show the reMarkable account legible is authenticated as, read from the user
+token: the user ID, sync protocol version, scopes, and when the token expires. An
+expired user token is renewed first, as for a sync.
+
+Also checks that the reMarkable cloud API can be reached, by fetching the version of
+the account's documents.
+
+Examples:
+  legible account info
+  legible account info --json`,
+	RunE: runAccountInfo,
+}
+
+func init() {
+	rootCmd.AddCommand(accountCmd)
+	accountCmd.AddCommand(accountInfoCmd)
+
+	accountInfoCmd.Flags().Bool("json", false, "output in JSON format")
+}
+
+// accountSource is the part of the reMarkable client used to describe the account
+type accountSource interface {
+	Account() (*rmclient.Account, error)
+	CloudVersion() (string, error)
+}
+
+// accountInfo is the account as shown by the account info command
+type accountInfo struct {
+	*rmclient.Account
+	APIReachable bool          `json:"api_reachable"`
+	APILatency   time.Duration `json:"api_latency_ns"`
+	APIError     string        `json:"api_error,omitempty"`
+}
+
+func runAccountInfo(cmd *cobra.Command, _ []string) error {
+	cfg, log, err := initConfigAndLogger()
+	if err != nil {
+		return err
+	}
+
+	rmClient, err := rmclient.NewClient(&rmclient.Config{
+		Logger:       log.Named("rmclient"),
+		HostRewrites: cfg.HostRewrites,
+		AuthURL:      cfg.APIAuthURL,
+		StorageURL:   cfg.APIStorageURL,
+		SyncURL:      cfg.APISyncURL,
+		LabelMatch:   rmclient.LabelMatch(cfg.LabelMatch),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	if err := rmClient.Authenticate(); err != nil {
+		return fmt.Errorf("authentication failed: %w. Please run 'legible auth' first", err)
+	}
+
+	info, err := collectAccountInfo(rmClient)
+	if err != nil {
+		return err
+	}
+
+	if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+		data, err := json.MarshalIndent(info, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal account: %w", err)
+		}
+		_, err = fmt.Println(string(data))
+		return err
+	}
+	return writeAccountInfo(os.Stdout, info, time.Now())
+}
+
+// collectAccountInfo reads the account from the client and checks the API can be reached
+// An unreachable API is reported in the result rather than as an error.
+func collectAccountInfo(client accountSource) (*accountInfo, error) {
+	account, err := client.Account()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read account: %w", err)
+	}
+
+	info := &accountInfo{Account: account}
+	start := time.Now()
+	_, err = client.CloudVersion()
+	info.APILatency = time.Since(start)
+	if err != nil {
+		info.APIError = err.Error()
+	} else {
+		info.APIReachable = true
+	}
+	return info, nil
+}
+
+// writeAccountInfo writes the account as of now in a human-readable format
+func writeAccountInfo(w io.Writer, info *accountInfo, now time.Time) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "User ID: %s\n", info.UserID)
+	if info.Email != "" {
+		fmt.Fprintf(&b, "Email: %s\n", info.Email)
+	}
+	fmt.Fprintf(&b, "Sync version: %s\n", info.SyncVersion)
+	if len(info.Scopes) > 0 {
+		fmt.Fprintf(&b, "Scopes: %s\n", strings.Join(info.Scopes, ", "))
+	} else {
+		b.WriteString("Scopes: none\n")
+	}
+
+	switch {
+	case info.ExpiresAt.IsZero():
+		b.WriteString("Token expires: never\n")
+	case info.ExpiresAt.After(now):
+		fmt.Fprintf(&b, "Token expires: %s (in %s)\n",
+			info.ExpiresAt.Local().Format("2006-01-02 15:04:05"), strings.TrimSpace(formatDuration(info.ExpiresAt.Sub(now))))
+	default:
+		fmt.Fprintf(&b, "Token expires: %s (expired)\n", info.ExpiresAt.Local().Format("2006-01-02 15:04:05"))
+	}
+
+	if info.APIReachable {
+		fmt.Fprintf(&b, "API: reachable (%s)\n", info.APILatency.Round(time.Millisecond))
+	} else {
+		fmt.Fprintf(&b, "API: unreachable (%s)\n", info.APIError)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
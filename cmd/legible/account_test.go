@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+
+	"github.com/platinummonkey/legible/internal/rmclient"
+)
+
+// mockAccountSource reads the account from a user token and answers the API check
+type mockAccountSource struct {
+	userToken string
+	apiErr    error
+	checks    int
+}
+
+func (m *mockAccountSource) Account() (*rmclient.Account, error) {
+	return rmclient.ParseAccount(m.userToken)
+}
+
+func (m *mockAccountSource) CloudVersion() (string, error) {
+	m.checks++
+	if m.apiErr != nil {
+		return "", m.apiErr
+	}
+	return "7-abc", nil
+}
+
+// mockUserToken returns a user token as issued by the reMarkable cloud, expiring at expires
+func mockUserToken(t *testing.T, expires time.Time) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"auth0-profile": map[string]interface{}{"UserID": "auth0|5f1e", "Email": "reader@example.com"},
+		"scopes":        "intgr sync:tortoise",
+		"exp":           expires.Unix(),
+	}).SignedString([]byte("test"))
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	return token
+}
+
+func TestAccountInfoOutput(t *testing.T) {
+	now := time.Now().Truncate(time.Second) // tokens expire on a whole second
+
+	tests := []struct {
+		name    string
+		expires time.Time
+		apiErr  error
+		want    []string
+	}{
+		{
+			name:    "reachable",
+			expires: now.Add(25 * time.Hour),
+			want: []string{
+				"User ID: auth0|5f1e\n",
+				"Email: reader@example.com\n",
+				"Sync version: 1.5\n",
+				"Scopes: intgr, sync:tortoise\n",
+				"(in 1d 1h 0m)\n",
+				"API: reachable (",
+			},
+		},
+		{
+			name:    "expired and unreachable",
+			expires: now.Add(-time.Hour),
+			apiErr:  errors.New("connection refused"),
+			want: []string{
+				"User ID: auth0|5f1e\n",
+				"Sync version: unknown\n",
+				"(expired)\n",
+				"API: unreachable (connection refused)\n",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := &mockAccountSource{userToken: mockUserToken(t, tt.expires), apiErr: tt.apiErr}
+
+			info, err := collectAccountInfo(source)
+			if err != nil {
+				t.Fatalf("collectAccountInfo() error = %v", err)
+			}
+			if source.checks != 1 || info.APIReachable != (tt.apiErr == nil) {
+				t.Errorf("API checked %d times, reachable = %t", source.checks, info.APIReachable)
+			}
+
+			var buf bytes.Buffer
+			if err := writeAccountInfo(&buf, info, now); err != nil {
+				t.Fatalf("writeAccountInfo() error = %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("output missing %q:\n%s", want, buf.String())
+				}
+			}
+		})
+	}
+}
+
+func TestCollectAccountInfo_InvalidToken(t *testing.T) {
+	if _, err := collectAccountInfo(&mockAccountSource{userToken: "garbage"}); err == nil {
+		t.Error("collectAccountInfo() expected error for an unreadable token")
+	}
+}
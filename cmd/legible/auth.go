@@ -40,8 +40,9 @@ func runAuth(_ *cobra.Command, _ []string) error {
 
 	// Initialize logger
 	log, err := logger.New(&logger.Config{
-		Level:  cfg.LogLevel,
-		Format: "console",
+		Level:           cfg.LogLevel,
+		Format:          "console",
+		ComponentLevels: cfg.LogLevels,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to initialize logger: %w", err)
@@ -51,7 +52,12 @@ func runAuth(_ *cobra.Command, _ []string) error {
 
 	// Create rmclient
 	client, err := rmclient.NewClient(&rmclient.Config{
-		Logger: log,
+		Logger:       log.Named("rmclient"),
+		HostRewrites: cfg.HostRewrites,
+		AuthURL:      cfg.APIAuthURL,
+		StorageURL:   cfg.APIStorageURL,
+		SyncURL:      cfg.APISyncURL,
+		LabelMatch:   rmclient.LabelMatch(cfg.LabelMatch),
 	})
 	if err != nil {
 		log.Fatal("Failed to create client:", err)
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/platinummonkey/legible/internal/config"
+	"github.com/platinummonkey/legible/internal/converter"
+	"github.com/platinummonkey/legible/internal/logger"
+	"github.com/platinummonkey/legible/internal/ocr"
+	"github.com/platinummonkey/legible/internal/pdfenhancer"
+	"github.com/spf13/cobra"
+)
+
+// convertCmd converts a local .rmdoc file to PDF
+var convertCmd = &cobra.Command{
+	Use:   "convert <input.rmdoc> [output.pdf]",
+	Short: "Convert a local .rmdoc file to PDF",
+	Long: `Convert an already downloaded .rmdoc file to PDF without contacting the
+reMarkable cloud.
+
+The same converter used by sync renders the pages and, unless --no-ocr is set,
+adds an OCR text layer using the configured LLM provider.
+
+Examples:
+  # Convert with OCR
+  legible convert notes.rmdoc notes.pdf
+
+  # Convert without OCR onto landscape A4 pages
+  legible convert notes.rmdoc notes.pdf --no-ocr --paper-size A4 --orientation landscape
+
+  # Convert only pages 1 to 3, 5, and 8 to 10 of a large notebook
+  legible convert notes.rmdoc excerpt.pdf --pages 1-3,5,8-10
+
+  # Check that a document still converts, for example in CI, without writing a PDF
+  legible convert notes.rmdoc --dry-run`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runConvert,
+}
+
+func init() {
+	rootCmd.AddCommand(convertCmd)
+
+	convertCmd.Flags().String("ocr-languages", "", "OCR language(s), e.g. eng or eng+fra (default: from config)")
+	convertCmd.Flags().String("paper-size", string(converter.DefaultPaperSize), "output paper size (A4, A5, Letter, Legal, Remarkable)")
+	convertCmd.Flags().String("orientation", string(converter.DefaultOrientation), "page orientation (portrait, landscape)")
+	convertCmd.Flags().String("pages", "", "pages to convert, e.g. 1-3,5,8-10 (default: all pages)")
+	convertCmd.Flags().Bool("dry-run", false, "run the whole conversion but write no output, failing if any page can't be read")
+}
+
+func runConvert(cmd *cobra.Command, args []string) error {
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	inputPath, outputPath := args[0], ""
+	if len(args) == 2 {
+		outputPath = args[1]
+	} else if !dryRun {
+		return fmt.Errorf("an output path is required unless --dry-run is set")
+	}
+
+	cfg, log, err := initConfigAndLogger()
+	if err != nil {
+		return err
+	}
+
+	opts, err := convertOptions(cmd, inputPath, outputPath, dryRun)
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(inputPath); err != nil {
+		return fmt.Errorf("cannot read input file: %w", err)
+	}
+	if !dryRun {
+		if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+			return fmt.Errorf("failed to create output directory: %w", err)
+		}
+	}
+
+	conv, err := newConvertConverter(cmd, cfg, log)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	result, err := conv.ConvertRmdocWithOptions(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("conversion failed: %w", err)
+	}
+
+	displayConversionResult(result)
+	if failed := result.FailedPages(); dryRun && len(failed) > 0 {
+		return fmt.Errorf("dry run found %d pages that could not be read", len(failed))
+	}
+	return nil
+}
+
+// convertOptions returns the conversion options of the convert command's flags
+func convertOptions(cmd *cobra.Command, inputPath, outputPath string, dryRun bool) (*converter.ConversionOptions, error) {
+	paperFlag, _ := cmd.Flags().GetString("paper-size")
+	paperSize, err := converter.ParsePaperSize(paperFlag)
+	if err != nil {
+		return nil, err
+	}
+	orientationFlag, _ := cmd.Flags().GetString("orientation")
+	orientation, err := converter.ParseOrientation(orientationFlag)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := converter.NewConversionOptions(inputPath, outputPath)
+	opts.PaperSize = paperSize
+	opts.Orientation = orientation
+	opts.DryRun = dryRun
+	opts.Pages, _ = cmd.Flags().GetString("pages")
+	return opts, nil
+}
+
+// newConvertConverter creates the converter of the convert command, with the OCR
+// languages of its flag or cfg, and the OCR processor and PDF enhancer if OCR is enabled
+func newConvertConverter(cmd *cobra.Command, cfg *config.Config, log *logger.Logger) (*converter.Converter, error) {
+	ocrLangs := []string{"eng"}
+	if langs, _ := cmd.Flags().GetString("ocr-languages"); langs != "" {
+		ocrLangs = []string{langs}
+		cfg.OCRLanguages = langs // also selects the OCR prompt and Tesseract's languages
+	} else if cfg.OCRLanguages != "" {
+		ocrLangs = []string{cfg.OCRLanguages}
+	}
+
+	var ocrProc *ocr.Processor
+	var pdfEnhancer *pdfenhancer.PDFEnhancer
+	if cfg.OCREnabled {
+		var err error
+		if ocrProc, err = newOCRProcessor(cfg, log); err != nil {
+			return nil, err
+		}
+
+		pdfEnhancer = pdfenhancer.New(&pdfenhancer.Config{
+			Logger: log.Named("pdfenhancer"),
+		})
+	}
+
+	conv, err := newConverter(cfg, log, ocrLangs, ocrProc, pdfEnhancer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create converter: %w", err)
+	}
+	return conv, nil
+}
+
+func displayConversionResult(result *converter.ConversionResult) {
+	fmt.Println()
+	fmt.Println("=== Conversion Complete ===")
+	switch {
+	case result.DryRun:
+		fmt.Println("Output: none (dry run)")
+	case result.Skipped:
+		fmt.Println("Output: none (no page could be parsed)")
+	default:
+		fmt.Printf("Output: %s\n", result.OutputPath)
+	}
+	fmt.Printf("Pages: %d%s\n", result.PageCount, pageOutcomeSummary(result.Pages))
+	fmt.Printf("File size: %d bytes\n", result.FileSize)
+	fmt.Printf("Duration: %v\n", result.Duration.Round(time.Millisecond))
+
+	if result.OCREnabled {
+		fmt.Printf("OCR words: %d (%.1f%% average confidence)\n", result.OCRWordCount, result.OCRConfidence)
+	} else {
+		fmt.Println("OCR: disabled")
+	}
+	if result.MarkdownPath != "" {
+		fmt.Printf("Markdown: %s\n", result.MarkdownPath)
+	}
+	if result.SidecarPath != "" {
+		fmt.Printf("OCR sidecar: %s\n", result.SidecarPath)
+	}
+	if len(result.PageTagPaths) > 0 {
+		tags := make([]string, 0, len(result.PageTagPaths))
+		for tag := range result.PageTagPaths {
+			tags = append(tags, tag)
+		}
+		sort.Strings(tags)
+		for _, tag := range tags {
+			fmt.Printf("Pages tagged %q: %s\n", tag, result.PageTagPaths[tag])
+		}
+	}
+	for i, path := range result.ChunkPaths {
+		fmt.Printf("Chunk %d: %s\n", i+1, path)
+	}
+
+	if failed := result.FailedPages(); len(failed) > 0 {
+		fmt.Println("\nPages that could not be read (left blank and marked with a dashed red frame):")
+		for _, page := range failed {
+			fmt.Printf("  - Page %d: %s\n", page.Number, page.Error)
+		}
+	}
+
+	if len(result.Warnings) > 0 {
+		fmt.Println("\nWarnings:")
+		for _, warning := range result.Warnings {
+			fmt.Printf("  - %s\n", warning)
+		}
+	}
+}
+
+// pageOutcomeSummary describes how many pages were empty or failed, such as
+// " (2 empty, 1 failed)", or returns "" when every page was rendered
+func pageOutcomeSummary(pages []converter.PageOutcome) string {
+	var empty, failed int
+	for _, page := range pages {
+		switch page.Status {
+		case converter.PageEmpty:
+			empty++
+		case converter.PageFailed:
+			failed++
+		}
+	}
+
+	var parts []string
+	if empty > 0 {
+		parts = append(parts, fmt.Sprintf("%d empty", empty))
+	}
+	if failed > 0 {
+		parts = append(parts, fmt.Sprintf("%d failed", failed))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(parts, ", ") + ")"
+}
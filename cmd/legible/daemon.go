@@ -6,7 +6,6 @@ import (
 	"time"
 
 	"github.com/platinummonkey/legible/internal/config"
-	"github.com/platinummonkey/legible/internal/converter"
 	"github.com/platinummonkey/legible/internal/daemon"
 	"github.com/platinummonkey/legible/internal/logger"
 	"github.com/platinummonkey/legible/internal/ocr"
@@ -18,6 +17,13 @@ import (
 	"github.com/spf13/viper"
 )
 
+// daemonLogMaxSizeMB is the size at which the daemon's log file is rotated
+const daemonLogMaxSizeMB = 10
+
+// ocrHealthCheckTimeout bounds the OCR provider's health check at daemon startup, so an
+// unreachable provider doesn't hang the daemon before its first sync
+const ocrHealthCheckTimeout = 30 * time.Second
+
 // daemonCmd represents the daemon command
 var daemonCmd = &cobra.Command{
 	Use:   "daemon",
@@ -28,10 +34,11 @@ The daemon performs periodic synchronization at the specified interval.
 It handles signals gracefully and can be monitored via health check endpoints.
 
 Features:
-- Periodic sync at configurable interval
+- Periodic sync at configurable interval, or on a cron schedule
 - Graceful shutdown on SIGTERM/SIGINT
 - Optional health check HTTP endpoints
 - Optional PID file for process management
+- Optional local watch directory for dropped .rmdoc files
 - Continues running even if individual syncs fail
 
 Examples:
@@ -41,12 +48,22 @@ Examples:
   # Run with custom interval
   legible daemon --interval 10m
 
+  # Sync at the top of every hour during working hours instead of at an interval
+  legible daemon --schedule "0 9-17 * * 1-5"
+
+  # Check the cloud for changes every 30 seconds and sync as soon as one is found,
+  # with a full sync every hour regardless
+  legible daemon --interval 1h --change-poll-interval 30s
+
   # Run with health check endpoint
   legible daemon --health-addr :8080
 
   # Run with PID file
   legible daemon --pid-file /var/run/legible.pid
 
+  # Also convert .rmdoc files dropped into a local directory
+  legible daemon --watch ~/Documents/ReMarkable/import
+
   # Full example with all options
   legible daemon \
     --interval 10m \
@@ -62,22 +79,33 @@ func init() {
 
 	// Daemon-specific flags
 	daemonCmd.Flags().Duration("interval", 5*time.Minute, "sync interval (e.g., 5m, 1h)")
+	daemonCmd.Flags().String("schedule", "", "cron expression for when to sync, used instead of --interval (e.g., \"0 * * * *\")")
+	daemonCmd.Flags().Duration("change-poll-interval", 0, "how often to check the cloud for changes and sync when one is found (e.g., 30s, 0 = off)")
 	daemonCmd.Flags().String("health-addr", "", "health check HTTP address (e.g., :8080)")
 	daemonCmd.Flags().String("pid-file", "", "PID file path")
 	daemonCmd.Flags().Bool("monitor-tokens", false, "enable token renewal monitoring and statistics")
 	daemonCmd.Flags().String("token-stats-file", "", "file to save token statistics (requires --monitor-tokens)")
+	daemonCmd.Flags().String("watch", "", "local directory to watch for .rmdoc files to convert alongside cloud sync")
 
 	_ = viper.BindPFlag("daemon.interval", daemonCmd.Flags().Lookup("interval"))
+	_ = viper.BindPFlag("daemon.schedule", daemonCmd.Flags().Lookup("schedule"))
+	_ = viper.BindPFlag("daemon.change_poll_interval", daemonCmd.Flags().Lookup("change-poll-interval"))
 	_ = viper.BindPFlag("daemon.health_addr", daemonCmd.Flags().Lookup("health-addr"))
 	_ = viper.BindPFlag("daemon.pid_file", daemonCmd.Flags().Lookup("pid-file"))
 	_ = viper.BindPFlag("daemon.monitor_tokens", daemonCmd.Flags().Lookup("monitor-tokens"))
 	_ = viper.BindPFlag("daemon.token_stats_file", daemonCmd.Flags().Lookup("token-stats-file"))
+	_ = viper.BindPFlag("daemon.watch_dir", daemonCmd.Flags().Lookup("watch"))
 }
 
 // configureRMClient creates and configures the reMarkable client with monitoring options
-func configureRMClient(log *logger.Logger) (*rmclient.Client, error) {
+func configureRMClient(cfg *config.Config, log *logger.Logger) (*rmclient.Client, error) {
 	rmClientCfg := &rmclient.Config{
-		Logger: log,
+		Logger:       log.Named("rmclient"),
+		HostRewrites: cfg.HostRewrites,
+		AuthURL:      cfg.APIAuthURL,
+		StorageURL:   cfg.APIStorageURL,
+		SyncURL:      cfg.APISyncURL,
+		LabelMatch:   rmclient.LabelMatch(cfg.LabelMatch),
 	}
 
 	// Enable token monitoring if requested
@@ -102,39 +130,39 @@ func initializeOCR(cfg *config.Config, log *logger.Logger) (*ocr.Processor, *pdf
 	var pdfEnhancer *pdfenhancer.PDFEnhancer
 
 	if cfg.OCREnabled {
-		// Convert config.LLMConfig to ocr.VisionClientConfig
-		visionConfig := &ocr.VisionClientConfig{
-			Provider:    ocr.ProviderType(cfg.LLM.Provider),
-			Model:       cfg.LLM.Model,
-			Endpoint:    cfg.LLM.Endpoint,
-			APIKey:      cfg.LLM.APIKey,
-			MaxRetries:  cfg.LLM.MaxRetries,
-			Temperature: cfg.LLM.Temperature,
-		}
-
 		var err error
-		ocrProc, err = ocr.New(&ocr.Config{
-			Logger:       log,
-			VisionConfig: visionConfig,
-		})
+		ocrProc, err = newOCRProcessor(cfg, log)
 		if err != nil {
-			return nil, nil, fmt.Errorf("failed to create OCR processor: %w", err)
+			return nil, nil, err
 		}
 
 		pdfEnhancer = pdfenhancer.New(&pdfenhancer.Config{
-			Logger: log,
+			Logger: log.Named("pdfenhancer"),
 		})
 	}
 
 	return ocrProc, pdfEnhancer, nil
 }
 
+// checkOCRHealth runs the OCR processor's health check within ocrHealthCheckTimeout,
+// warning rather than failing when the provider isn't available, since it may come up
+// before the first document needs OCR
+func checkOCRHealth(ocrProc *ocr.Processor, log *logger.Logger) {
+	ctx, cancel := context.WithTimeout(context.Background(), ocrHealthCheckTimeout)
+	defer cancel()
+
+	if err := ocrProc.HealthCheck(ctx); err != nil {
+		log.WithError(err).WithFields("timeout", ocrHealthCheckTimeout).
+			Warn("OCR provider health check failed, continuing startup")
+	}
+}
+
 // createDaemonWithComponents initializes converter, orchestrator, and daemon
 func createDaemonWithComponents(
 	cfg *config.Config,
 	log *logger.Logger,
 	rmClient *rmclient.Client,
-	stateStore *state.Manager,
+	stateStore state.Store,
 	ocrProc *ocr.Processor,
 	pdfEnhancer *pdfenhancer.PDFEnhancer,
 ) (*daemon.Daemon, error) {
@@ -145,38 +173,47 @@ func createDaemonWithComponents(
 	}
 
 	// Initialize converter with pre-configured processors
-	conv, err := converter.New(&converter.Config{
-		Logger:       log,
-		EnableOCR:    cfg.OCREnabled,
-		OCRLanguages: ocrLangs,
-		OCRProcessor: ocrProc,
-		PDFEnhancer:  pdfEnhancer,
-	})
+	conv, err := newConverter(cfg, log, ocrLangs, ocrProc, pdfEnhancer)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create converter: %w", err)
 	}
 
 	// Create sync orchestrator
 	orch, err := sync.New(&sync.Config{
-		Config:       cfg,
-		Logger:       log,
-		RMClient:     rmClient,
-		StateStore:   stateStore,
-		Converter:    conv,
-		OCRProcessor: ocrProc,
-		PDFEnhancer:  pdfEnhancer,
+		Config:              cfg,
+		Logger:              log.Named("sync"),
+		RMClient:            rmClient,
+		StateStore:          stateStore,
+		Converter:           conv,
+		OCRProcessor:        ocrProc,
+		PDFEnhancer:         pdfEnhancer,
+		Concurrency:         cfg.Concurrency,
+		DownloadConcurrency: cfg.DownloadConcurrency,
+		PipelineDepth:       cfg.PipelineDepth,
+		StateLockTimeout:    cfg.StateLockTimeout,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create orchestrator: %w", err)
 	}
 
+	var ocrMetrics *ocr.Metrics
+	if ocrProc != nil {
+		ocrMetrics = ocrProc.Metrics()
+	}
+
 	// Create daemon
 	d, err := daemon.New(&daemon.Config{
-		Orchestrator:    orch,
-		Logger:          log,
-		SyncInterval:    cfg.SyncInterval,
-		HealthCheckAddr: viper.GetString("daemon.health_addr"),
-		PIDFile:         viper.GetString("daemon.pid_file"),
+		Orchestrator:       orch,
+		Logger:             log.Named("daemon"),
+		SyncInterval:       cfg.SyncInterval,
+		Schedule:           cfg.SyncSchedule,
+		ChangePollInterval: cfg.ChangePollInterval,
+		VersionChecker:     rmClient,
+		HealthCheckAddr:    viper.GetString("daemon.health_addr"),
+		PIDFile:            viper.GetString("daemon.pid_file"),
+		WatchDir:           viper.GetString("daemon.watch_dir"),
+		Reload:             reloadDaemonSettings,
+		OCRMetrics:         ocrMetrics,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create daemon: %w", err)
@@ -185,6 +222,51 @@ func createDaemonWithComponents(
 	return d, nil
 }
 
+// reloadDaemonSettings re-reads the configuration file for a running daemon, keeping
+// the command-line flags' overrides
+func reloadDaemonSettings() (*daemon.Settings, error) {
+	if err := viper.ReadInConfig(); err != nil && viper.ConfigFileUsed() != "" {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	applyDaemonFlags(cfg)
+
+	return &daemon.Settings{
+		SyncInterval: cfg.SyncInterval,
+		Labels:       cfg.Labels,
+		OCREnabled:   cfg.OCREnabled,
+	}, nil
+}
+
+// applyDaemonFlags overrides the sync interval, schedule, and change poll interval of
+// cfg with the daemon command's flags, where set
+func applyDaemonFlags(cfg *config.Config) {
+	if viper.IsSet("daemon.interval") {
+		cfg.SyncInterval = viper.GetDuration("daemon.interval")
+	}
+	if viper.IsSet("daemon.schedule") {
+		cfg.SyncSchedule = viper.GetString("daemon.schedule")
+	}
+	if viper.IsSet("daemon.change_poll_interval") {
+		cfg.ChangePollInterval = viper.GetDuration("daemon.change_poll_interval")
+	}
+}
+
+// closeRMClient prints the client's token monitoring statistics, if enabled, and
+// closes it
+func closeRMClient(rmClient *rmclient.Client, log *logger.Logger) {
+	if tokenMonitor := rmClient.GetTokenMonitor(); tokenMonitor != nil {
+		tokenMonitor.PrintSummary()
+	}
+
+	if err := rmClient.Close(); err != nil {
+		log.WithError(err).Error("Failed to close client")
+	}
+}
+
 func runDaemon(_ *cobra.Command, _ []string) error {
 	// Load configuration first
 	cfg, err := loadConfig()
@@ -192,15 +274,23 @@ func runDaemon(_ *cobra.Command, _ []string) error {
 		return fmt.Errorf("failed to load configuration: %w", err)
 	}
 
-	// Override sync interval from daemon flag if set
-	if viper.IsSet("daemon.interval") {
-		cfg.SyncInterval = viper.GetDuration("daemon.interval")
+	applyDaemonFlags(cfg)
+
+	// Report a bad schedule before authenticating
+	if cfg.SyncSchedule != "" {
+		if _, err := daemon.ParseSchedule(cfg.SyncSchedule); err != nil {
+			return err
+		}
 	}
 
-	// Initialize logger (JSON format for daemon mode)
+	// Initialize logger (JSON format for daemon mode), also writing to a rotated
+	// log file when configured
 	log, err := logger.New(&logger.Config{
-		Level:  cfg.LogLevel,
-		Format: "json",
+		Level:           cfg.LogLevel,
+		Format:          "json",
+		OutputPath:      cfg.LogFile,
+		MaxSizeMB:       daemonLogMaxSizeMB,
+		ComponentLevels: cfg.LogLevels,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to initialize logger: %w", err)
@@ -209,10 +299,12 @@ func runDaemon(_ *cobra.Command, _ []string) error {
 	log.WithFields(
 		"output_dir", cfg.OutputDir,
 		"interval", cfg.SyncInterval,
+		"schedule", cfg.SyncSchedule,
+		"change_poll_interval", cfg.ChangePollInterval,
 	).Info("Starting daemon")
 
 	// Initialize reMarkable client
-	rmClient, err := configureRMClient(log)
+	rmClient, err := configureRMClient(cfg, log)
 	if err != nil {
 		log.Fatal("Failed to create client:", err)
 	}
@@ -223,27 +315,26 @@ func runDaemon(_ *cobra.Command, _ []string) error {
 	}
 
 	// Ensure client cleanup on exit
-	defer func() {
-		// Print token monitoring statistics if enabled
-		if tokenMonitor := rmClient.GetTokenMonitor(); tokenMonitor != nil {
-			tokenMonitor.PrintSummary()
-		}
+	defer closeRMClient(rmClient, log)
 
-		if err := rmClient.Close(); err != nil {
-			log.WithError(err).Error("Failed to close client")
-		}
-	}()
-
-	stateStore, err := state.LoadOrCreate(cfg.StateFile)
+	stateStore, err := state.Open(cfg.StateBackend, cfg.StateFile)
 	if err != nil {
 		log.Fatal("Failed to initialize state:", err)
 	}
+	defer func() {
+		if err := stateStore.Close(); err != nil {
+			log.WithError(err).Error("Failed to close state")
+		}
+	}()
 
 	// Initialize OCR components if enabled
 	ocrProc, pdfEnhancer, err := initializeOCR(cfg, log)
 	if err != nil {
 		return err
 	}
+	if ocrProc != nil {
+		checkOCRHealth(ocrProc, log)
+	}
 
 	// Create daemon with all components
 	d, err := createDaemonWithComponents(cfg, log, rmClient, stateStore, ocrProc, pdfEnhancer)
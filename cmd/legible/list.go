@@ -0,0 +1,197 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/platinummonkey/legible/internal/rmclient"
+	"github.com/spf13/cobra"
+)
+
+// listCmd lists documents in the reMarkable cloud
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List documents in the reMarkable cloud",
+	Long: `List the documents in your reMarkable cloud as a folder tree, showing each
+document's type, version, and last-modified time.
+
+Nothing is downloaded or converted; use this to check what a sync with the same
+--labels would pick up.
+
+Examples:
+  # List every document
+  legible list
+
+  # List documents labelled "work"
+  legible list --labels work
+
+  # Machine-readable output
+  legible list --json`,
+	RunE: runList,
+}
+
+func init() {
+	rootCmd.AddCommand(listCmd)
+
+	listCmd.Flags().Bool("json", false, "output in JSON format")
+}
+
+// documentLister is the part of the reMarkable client used to list documents
+type documentLister interface {
+	ListDocuments(labels []string) ([]rmclient.Document, error)
+	GetFolderPath(documentID string) (string, error)
+}
+
+// listedDocument is a document as shown by the list command
+type listedDocument struct {
+	ID       string    `json:"id"`
+	Name     string    `json:"name"`
+	Folder   string    `json:"folder"`
+	Type     string    `json:"type"`
+	Version  int       `json:"version"`
+	Modified time.Time `json:"modified"`
+	Tags     []string  `json:"tags,omitempty"`
+}
+
+func runList(cmd *cobra.Command, _ []string) error {
+	cfg, log, err := initConfigAndLogger()
+	if err != nil {
+		return err
+	}
+
+	rmClient, err := rmclient.NewClient(&rmclient.Config{
+		Logger:       log.Named("rmclient"),
+		HostRewrites: cfg.HostRewrites,
+		AuthURL:      cfg.APIAuthURL,
+		StorageURL:   cfg.APIStorageURL,
+		SyncURL:      cfg.APISyncURL,
+		LabelMatch:   rmclient.LabelMatch(cfg.LabelMatch),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	if err := rmClient.Authenticate(); err != nil {
+		return fmt.Errorf("authentication failed: %w. Please run 'legible auth' first", err)
+	}
+
+	docs, err := collectListing(rmClient, cfg.Labels)
+	if err != nil {
+		return err
+	}
+
+	if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+		return writeListingJSON(os.Stdout, docs)
+	}
+	return writeListingTree(os.Stdout, docs)
+}
+
+// collectListing lists documents matching labels with their folder paths, sorted by
+// folder and then name
+//
+// Documents whose folder cannot be resolved are listed in the root folder.
+func collectListing(client documentLister, labels []string) ([]listedDocument, error) {
+	docs, err := client.ListDocuments(labels)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list documents: %w", err)
+	}
+
+	listing := make([]listedDocument, 0, len(docs))
+	for _, doc := range docs {
+		folder, err := client.GetFolderPath(doc.ID)
+		if err != nil {
+			folder = ""
+		}
+
+		listing = append(listing, listedDocument{
+			ID:       doc.ID,
+			Name:     doc.Name,
+			Folder:   folder,
+			Type:     documentTypeName(doc.Type),
+			Version:  doc.Version,
+			Modified: doc.ModifiedClient,
+			Tags:     doc.Tags,
+		})
+	}
+
+	sort.Slice(listing, func(i, j int) bool {
+		if listing[i].Folder != listing[j].Folder {
+			return listing[i].Folder < listing[j].Folder
+		}
+		return listing[i].Name < listing[j].Name
+	})
+
+	return listing, nil
+}
+
+// documentTypeName returns a short display name for an API document type
+func documentTypeName(docType string) string {
+	switch docType {
+	case rmclient.DocumentType:
+		return "document"
+	case rmclient.CollectionType:
+		return "folder"
+	default:
+		return strings.ToLower(docType)
+	}
+}
+
+// writeListingJSON writes docs as an indented JSON array
+func writeListingJSON(w io.Writer, docs []listedDocument) error {
+	data, err := json.MarshalIndent(docs, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal documents: %w", err)
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
+
+// writeListingTree writes docs as an indented folder tree with aligned columns
+//
+// docs must be sorted by folder as returned by collectListing.
+func writeListingTree(w io.Writer, docs []listedDocument) error {
+	if len(docs) == 0 {
+		_, err := fmt.Fprintln(w, "No documents found")
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "NAME\tTYPE\tVERSION\tMODIFIED")
+
+	var open []string // folder path segments already printed
+	for _, doc := range docs {
+		var segments []string
+		if doc.Folder != "" {
+			segments = strings.Split(doc.Folder, "/")
+		}
+
+		// Keep the folders shared with the previous document, then print the rest
+		common := 0
+		for common < len(open) && common < len(segments) && open[common] == segments[common] {
+			common++
+		}
+		for depth := common; depth < len(segments); depth++ {
+			_, _ = fmt.Fprintf(tw, "%s%s/\t\t\t\n", strings.Repeat("  ", depth), segments[depth])
+		}
+		open = segments
+
+		modified := "-"
+		if !doc.Modified.IsZero() {
+			modified = doc.Modified.Local().Format("2006-01-02 15:04")
+		}
+		_, _ = fmt.Fprintf(tw, "%s%s\t%s\t%d\t%s\n",
+			strings.Repeat("  ", len(segments)), doc.Name, doc.Type, doc.Version, modified)
+	}
+
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "\n%d documents\n", len(docs))
+	return err
+}
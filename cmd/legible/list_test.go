@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/platinummonkey/legible/internal/rmclient"
+)
+
+// mockLister returns fixed documents and folder paths
+type mockLister struct {
+	docs    []rmclient.Document
+	folders map[string]string
+	labels  []string
+}
+
+func (m *mockLister) ListDocuments(labels []string) ([]rmclient.Document, error) {
+	m.labels = labels
+	return m.docs, nil
+}
+
+func (m *mockLister) GetFolderPath(documentID string) (string, error) {
+	folder, ok := m.folders[documentID]
+	if !ok {
+		return "", fmt.Errorf("document not found: %s", documentID)
+	}
+	return folder, nil
+}
+
+func newMockLister() *mockLister {
+	modified := time.Date(2025, 3, 14, 9, 30, 0, 0, time.UTC)
+	return &mockLister{
+		docs: []rmclient.Document{
+			{ID: "d1", Name: "Standup", Type: rmclient.DocumentType, Version: 4, ModifiedClient: modified, Tags: []string{"work"}},
+			{ID: "d2", Name: "Groceries", Type: rmclient.DocumentType, Version: 1, ModifiedClient: modified},
+			{ID: "d3", Name: "Retro", Type: rmclient.DocumentType, Version: 2, ModifiedClient: modified},
+			{ID: "d4", Name: "Ideas", Type: rmclient.DocumentType, Version: 7, ModifiedClient: modified},
+		},
+		folders: map[string]string{
+			"d1": "Work/Meetings",
+			"d2": "",
+			"d3": "Work/Meetings",
+			// d4's folder cannot be resolved
+		},
+	}
+}
+
+//nolint:gocyclo // Test function with multiple validation steps
+func TestCollectListing_JSON(t *testing.T) {
+	lister := newMockLister()
+
+	docs, err := collectListing(lister, []string{"work"})
+	if err != nil {
+		t.Fatalf("collectListing() error = %v", err)
+	}
+	if len(lister.labels) != 1 || lister.labels[0] != "work" {
+		t.Errorf("labels passed to ListDocuments = %v, want [work]", lister.labels)
+	}
+
+	var buf bytes.Buffer
+	if err := writeListingJSON(&buf, docs); err != nil {
+		t.Fatalf("writeListingJSON() error = %v", err)
+	}
+
+	var got []map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, buf.String())
+	}
+	if len(got) != 4 {
+		t.Fatalf("got %d documents, want 4", len(got))
+	}
+
+	// Sorted by folder, then name; unresolved folders fall back to the root
+	var order []string
+	for _, doc := range got {
+		order = append(order, fmt.Sprintf("%s/%s", doc["folder"], doc["name"]))
+	}
+	want := "/Groceries,/Ideas,Work/Meetings/Retro,Work/Meetings/Standup"
+	if strings.Join(order, ",") != want {
+		t.Errorf("order = %v, want %s", order, want)
+	}
+
+	standup := got[3]
+	if standup["id"] != "d1" || standup["type"] != "document" || standup["version"] != float64(4) {
+		t.Errorf("unexpected document fields: %v", standup)
+	}
+	if standup["modified"] != "2025-03-14T09:30:00Z" {
+		t.Errorf("modified = %v, want RFC 3339 timestamp", standup["modified"])
+	}
+	if tags, ok := standup["tags"].([]any); !ok || len(tags) != 1 || tags[0] != "work" {
+		t.Errorf("tags = %v, want [work]", standup["tags"])
+	}
+	if _, ok := got[0]["tags"]; ok {
+		t.Error("documents without tags should omit the tags field")
+	}
+}
+
+func TestWriteListingTree(t *testing.T) {
+	docs, err := collectListing(newMockLister(), nil)
+	if err != nil {
+		t.Fatalf("collectListing() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := writeListingTree(&buf, docs); err != nil {
+		t.Fatalf("writeListingTree() error = %v", err)
+	}
+	out := buf.String()
+
+	// Each folder is printed once, with its documents indented beneath it
+	for _, line := range []string{"Work/", "  Meetings/", "    Retro", "    Standup"} {
+		if !strings.Contains(out, "\n"+line) {
+			t.Errorf("output missing line starting %q:\n%s", line, out)
+		}
+	}
+	if strings.Count(out, "Meetings/") != 1 {
+		t.Errorf("folder printed more than once:\n%s", out)
+	}
+	if !strings.Contains(out, "4 documents") {
+		t.Errorf("output missing document count:\n%s", out)
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/platinummonkey/legible/internal/ocrbench"
+	"github.com/platinummonkey/legible/internal/pdfrender"
+	"github.com/spf13/cobra"
+)
+
+// ocrCmd represents the ocr command
+var ocrCmd = &cobra.Command{
+	Use:   "ocr",
+	Short: "OCR tools",
+	Long:  `Tools for checking and tuning OCR against the configured LLM provider.`,
+}
+
+// ocrBenchCmd benchmarks OCR throughput
+var ocrBenchCmd = &cobra.Command{
+	Use:   "bench",
+	Short: "Benchmark OCR throughput of the configured model",
+	Long: `Render bundled handwritten pages and OCR them one at a time with the
+configured LLM provider and model, then report throughput (pages/minute),
+average confidence, and per-page latency.
+
+Higher --dpi values usually improve accuracy at the cost of larger images and
+slower requests.
+
+Examples:
+  # Benchmark 4 pages at the default 300 DPI
+  legible ocr bench
+
+  # Compare a lower resolution
+  legible ocr bench --pages 10 --dpi 150`,
+	RunE: runOCRBench,
+}
+
+func init() {
+	rootCmd.AddCommand(ocrCmd)
+	ocrCmd.AddCommand(ocrBenchCmd)
+
+	ocrBenchCmd.Flags().Int("pages", ocrbench.DefaultPages, "number of pages to OCR")
+	ocrBenchCmd.Flags().Int("dpi", pdfrender.DefaultDPI, "resolution to render pages at")
+}
+
+func runOCRBench(cmd *cobra.Command, _ []string) error {
+	cfg, log, err := initConfigAndLogger()
+	if err != nil {
+		return err
+	}
+
+	pages, _ := cmd.Flags().GetInt("pages")
+	dpi, _ := cmd.Flags().GetInt("dpi")
+	if pages <= 0 || dpi <= 0 {
+		return fmt.Errorf("--pages and --dpi must be positive")
+	}
+
+	ocrProc, err := newOCRProcessor(cfg, log)
+	if err != nil {
+		return err
+	}
+	if err := ocrProc.HealthCheck(cmd.Context()); err != nil {
+		return fmt.Errorf("OCR provider is not available: %w", err)
+	}
+
+	bench, err := ocrbench.New(&ocrbench.Config{
+		Logger:    log.Named("ocrbench"),
+		Processor: ocrProc,
+		Pages:     pages,
+		DPI:       dpi,
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	result, err := bench.Run(ctx)
+	if err != nil {
+		return fmt.Errorf("benchmark failed: %w", err)
+	}
+
+	fmt.Println()
+	fmt.Println("=== OCR Benchmark ===")
+	fmt.Printf("Provider/model: %s/%s\n", cfg.LLM.Provider, result.Model)
+	fmt.Printf("Pages: %d processed, %d failed (at %d DPI)\n", result.Pages, result.Failed, result.DPI)
+	fmt.Printf("Duration: %v\n", result.Duration.Round(time.Millisecond))
+	fmt.Printf("Throughput: %.1f pages/minute\n", result.PagesPerMinute)
+	fmt.Printf("Average confidence: %.1f%%\n", result.AverageConfidence)
+	fmt.Printf("Words recognized: %d\n", result.Words)
+	fmt.Println("Latency per page:")
+	fmt.Printf("  min %v  mean %v  p50 %v  p90 %v  max %v\n",
+		result.Latency.Min.Round(time.Millisecond),
+		result.Latency.Mean.Round(time.Millisecond),
+		result.Latency.P50.Round(time.Millisecond),
+		result.Latency.P90.Round(time.Millisecond),
+		result.Latency.Max.Round(time.Millisecond),
+	)
+
+	return nil
+}
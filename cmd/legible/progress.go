@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	gosync "sync"
+	"time"
+
+	"github.com/platinummonkey/legible/internal/sync"
+)
+
+const (
+	// largeDownloadSize is the size from which sync shows a download's progress bar
+	largeDownloadSize = 1 << 20
+
+	// progressRedrawInterval is the most often the progress bar is redrawn
+	progressRedrawInterval = 100 * time.Millisecond
+
+	// progressBarWidth is the number of cells in the progress bar
+	progressBarWidth = 20
+)
+
+// downloadProgressBar draws a single-line progress bar for large document downloads
+//
+// Small documents download too quickly for a bar to be useful, so a bar is only drawn
+// once a download reaches largeDownloadSize. When several large documents download at
+// once, the bar follows the first until it finishes.
+type downloadProgressBar struct {
+	w   io.Writer
+	now func() time.Time
+
+	mu      gosync.Mutex
+	current string    // document whose bar is on the line, "" when the line is free
+	drawn   time.Time // when the bar was last drawn
+}
+
+// newDownloadProgressBar creates a progress bar that draws on w
+func newDownloadProgressBar(w io.Writer) *downloadProgressBar {
+	return &downloadProgressBar{w: w, now: time.Now}
+}
+
+// update redraws the bar for a download's progress
+func (b *downloadProgressBar) update(p sync.DownloadProgress) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if p.Done {
+		// Leave the finished bar on its own line
+		if p.DocumentID == b.current {
+			_, _ = fmt.Fprintf(b.w, "\r%s\x1b[K\n", progressLine(p))
+			b.current = ""
+		}
+		return
+	}
+
+	if p.Total < largeDownloadSize || (b.current != "" && b.current != p.DocumentID) {
+		return
+	}
+	now := b.now()
+	if b.current == p.DocumentID && now.Sub(b.drawn) < progressRedrawInterval {
+		return
+	}
+
+	b.current = p.DocumentID
+	b.drawn = now
+	_, _ = fmt.Fprintf(b.w, "\r%s\x1b[K", progressLine(p))
+}
+
+// progressLine renders a download's progress as "Downloading <title> [###---] x of y"
+func progressLine(p sync.DownloadProgress) string {
+	filled := 0
+	if p.Total > 0 {
+		filled = int(min(p.Downloaded, p.Total) * progressBarWidth / p.Total)
+	}
+
+	title := p.Title
+	if title == "" {
+		title = p.DocumentID
+	}
+	return fmt.Sprintf("Downloading %s [%s%s] %s of %s", title,
+		strings.Repeat("#", filled), strings.Repeat("-", progressBarWidth-filled),
+		formatBytes(p.Downloaded), formatBytes(p.Total))
+}
+
+// formatBytes renders a byte count with a binary unit, e.g. "1.5 MB"
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// isTerminal reports whether f is a terminal rather than a file or pipe
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/platinummonkey/legible/internal/sync"
+)
+
+func TestDownloadProgressBar(t *testing.T) {
+	var buf bytes.Buffer
+	bar := newDownloadProgressBar(&buf)
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	bar.now = func() time.Time { return now }
+
+	const total = 4 << 20
+	big := sync.DownloadProgress{DocumentID: "big", Title: "Annotated Paper", Total: total}
+
+	// Small downloads never draw a bar
+	bar.update(sync.DownloadProgress{DocumentID: "small", Downloaded: 10, Total: 100})
+	bar.update(sync.DownloadProgress{DocumentID: "small", Downloaded: 100, Total: 100, Done: true})
+	if buf.Len() != 0 {
+		t.Fatalf("small download drew %q", buf.String())
+	}
+
+	big.Downloaded = total / 4
+	bar.update(big)
+
+	// Redraws are throttled, and a second large download waits for the line
+	big.Downloaded = total / 2
+	bar.update(big)
+	bar.update(sync.DownloadProgress{DocumentID: "other", Downloaded: 1, Total: total})
+
+	now = now.Add(time.Second)
+	big.Downloaded = total
+	bar.update(big)
+	big.Done = true
+	bar.update(big)
+
+	want := "\rDownloading Annotated Paper [#####---------------] 1.0 MB of 4.0 MB\x1b[K" +
+		"\rDownloading Annotated Paper [####################] 4.0 MB of 4.0 MB\x1b[K" +
+		"\rDownloading Annotated Paper [####################] 4.0 MB of 4.0 MB\x1b[K\n"
+	if got := buf.String(); got != want {
+		t.Errorf("progress output =\n%q\nwant\n%q", got, want)
+	}
+	if strings.Contains(buf.String(), "other") {
+		t.Error("a second download drew over the first")
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := map[int64]string{
+		0:               "0 B",
+		1023:            "1023 B",
+		1536:            "1.5 KB",
+		5 << 20:         "5.0 MB",
+		3 << 30:         "3.0 GB",
+		(1 << 20) - 512: "1023.5 KB",
+	}
+	for n, want := range tests {
+		if got := formatBytes(n); got != want {
+			t.Errorf("formatBytes(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/platinummonkey/legible/internal/rmclient"
+	"github.com/platinummonkey/legible/internal/state"
+	"github.com/spf13/cobra"
+)
+
+// reconvertCmd represents the reconvert command
+var reconvertCmd = &cobra.Command{
+	Use:   "reconvert",
+	Short: "Convert synced documents again from cached downloads",
+	Long: `Convert every synced document again from its cached download, overwriting
+its output, without contacting the reMarkable cloud.
+
+Use it to re-render documents after upgrading legible or changing conversion
+settings, such as OCR. Outputs are rewritten where they were synced, in the
+same format. It needs the download cache (download-dir) that syncs keep;
+documents whose synced version isn't cached are skipped.
+
+Examples:
+  # Re-render everything with the current settings
+  legible reconvert
+
+  # Re-render with the download cache given by the environment
+  LEGIBLE_DOWNLOAD_DIR=~/.legible-downloads legible reconvert`,
+	RunE: runReconvert,
+}
+
+func init() {
+	rootCmd.AddCommand(reconvertCmd)
+}
+
+func runReconvert(_ *cobra.Command, _ []string) error {
+	cfg, log, err := initConfigAndLogger()
+	if err != nil {
+		return err
+	}
+	if cfg.DownloadDir == "" {
+		return fmt.Errorf("reconvert needs the download cache: set download-dir to the directory syncs download to")
+	}
+
+	// The client is never used to contact the cloud, so it isn't authenticated
+	rmClient, err := rmclient.NewClient(&rmclient.Config{
+		Logger:       log.Named("rmclient"),
+		HostRewrites: cfg.HostRewrites,
+		AuthURL:      cfg.APIAuthURL,
+		StorageURL:   cfg.APIStorageURL,
+		SyncURL:      cfg.APISyncURL,
+		LabelMatch:   rmclient.LabelMatch(cfg.LabelMatch),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+	stateStore, err := state.Open(cfg.StateBackend, cfg.StateFile)
+	if err != nil {
+		return fmt.Errorf("failed to initialize state: %w", err)
+	}
+	orch, err := newOrchestrator(cfg, log, rmClient, stateStore)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	result, err := orch.Reconvert(ctx)
+	if err != nil {
+		return fmt.Errorf("reconvert failed: %w", err)
+	}
+
+	fmt.Println()
+	fmt.Print(result.Summary())
+	if len(result.Failures) > 0 {
+		return fmt.Errorf("reconvert completed with %d failures", len(result.Failures))
+	}
+	return nil
+}
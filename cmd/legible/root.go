@@ -45,14 +45,38 @@ func init() {
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.legible.yaml)")
 	rootCmd.PersistentFlags().String("output", "", "output directory for PDFs")
 	rootCmd.PersistentFlags().StringSlice("labels", []string{}, "filter documents by labels (comma-separated)")
+	rootCmd.PersistentFlags().String("label-match", "any", "require any or all of --labels (any, all)")
 	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "info", "log level (debug, info, warn, error)")
 	rootCmd.PersistentFlags().Bool("no-ocr", false, "disable OCR processing")
+	rootCmd.PersistentFlags().Bool("text-only", false, "write OCR transcripts on blank pages instead of the handwriting")
+	rootCmd.PersistentFlags().Bool("markdown", false, "also write each document's OCR text as a Markdown file next to its PDF")
+	rootCmd.PersistentFlags().String("ocr-sidecar", "", "also write each document's raw OCR results next to its PDF (json, text, hocr)")
+	rootCmd.PersistentFlags().Float64("ocr-min-confidence", 0, "drop OCR words below this confidence (0-100) from the text layer")
+	rootCmd.PersistentFlags().Bool("split-by-page-tag", false, "also write each page tag's pages as a PDF of their own")
+	rootCmd.PersistentFlags().Bool("bookmarks-from-tags", false, "add a PDF bookmark for each tagged page, named after its page tags")
+	rootCmd.PersistentFlags().Int("chunk-pages", 0, "also write documents longer than this many pages in chunks of this many pages")
+	rootCmd.PersistentFlags().String("encrypt-password", "", "encrypt every PDF written with this password (or set LEGIBLE_ENCRYPT_PASSWORD)")
+	rootCmd.PersistentFlags().String("ocr-cache-dir", "", "directory caching OCR results of unchanged pages (default: ~/.legible-ocr-cache)")
+	rootCmd.PersistentFlags().Bool("no-ocr-cache", false, "run OCR on every page instead of reusing cached results")
+	rootCmd.PersistentFlags().String("ocr-prompt-file", "", "YAML prompt file (model, system, prompt) for structured OCR with Ollama")
 
 	// Bind flags to viper (using dash-separated keys to match config file format)
 	_ = viper.BindPFlag("output-dir", rootCmd.PersistentFlags().Lookup("output"))
 	_ = viper.BindPFlag("labels", rootCmd.PersistentFlags().Lookup("labels"))
+	_ = viper.BindPFlag("label-match", rootCmd.PersistentFlags().Lookup("label-match"))
 	_ = viper.BindPFlag("log-level", rootCmd.PersistentFlags().Lookup("log-level"))
 	_ = viper.BindPFlag("no-ocr", rootCmd.PersistentFlags().Lookup("no-ocr"))
+	_ = viper.BindPFlag("text-only", rootCmd.PersistentFlags().Lookup("text-only"))
+	_ = viper.BindPFlag("markdown-transcript", rootCmd.PersistentFlags().Lookup("markdown"))
+	_ = viper.BindPFlag("ocr-sidecar", rootCmd.PersistentFlags().Lookup("ocr-sidecar"))
+	_ = viper.BindPFlag("ocr-min-confidence", rootCmd.PersistentFlags().Lookup("ocr-min-confidence"))
+	_ = viper.BindPFlag("split-by-page-tag", rootCmd.PersistentFlags().Lookup("split-by-page-tag"))
+	_ = viper.BindPFlag("bookmarks-from-tags", rootCmd.PersistentFlags().Lookup("bookmarks-from-tags"))
+	_ = viper.BindPFlag("chunk-pages", rootCmd.PersistentFlags().Lookup("chunk-pages"))
+	_ = viper.BindPFlag("encrypt-password", rootCmd.PersistentFlags().Lookup("encrypt-password"))
+	_ = viper.BindPFlag("ocr-cache-dir", rootCmd.PersistentFlags().Lookup("ocr-cache-dir"))
+	_ = viper.BindPFlag("no-ocr-cache", rootCmd.PersistentFlags().Lookup("no-ocr-cache"))
+	_ = viper.BindPFlag("ocr-prompt-file", rootCmd.PersistentFlags().Lookup("ocr-prompt-file"))
 }
 
 func initConfig() {
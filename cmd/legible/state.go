@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/platinummonkey/legible/internal/state"
+	"github.com/spf13/cobra"
+)
+
+// stateCmd represents the state command
+var stateCmd = &cobra.Command{
+	Use:   "state",
+	Short: "Inspect sync state files",
+	Long:  `Inspect the sync state files that track which documents have been synced.`,
+}
+
+// stateDiffCmd compares two state files
+var stateDiffCmd = &cobra.Command{
+	Use:   "diff <old.json> <new.json>",
+	Short: "Show differences between two sync states",
+	Long: `Compare two sync state files and report documents that were added,
+removed, or changed between them.
+
+Changed documents list each differing field (name, version, conversion status,
+local path). Useful for understanding what a sync run did, e.g. by keeping a
+copy of the state file before syncing:
+
+  cp ~/.legible-state.json /tmp/before.json
+  legible sync
+  legible state diff /tmp/before.json ~/.legible-state.json`,
+	Args: cobra.ExactArgs(2),
+	RunE: runStateDiff,
+}
+
+func init() {
+	rootCmd.AddCommand(stateCmd)
+	stateCmd.AddCommand(stateDiffCmd)
+
+	stateDiffCmd.Flags().Bool("json", false, "output in JSON format")
+}
+
+func runStateDiff(cmd *cobra.Command, args []string) error {
+	oldState, err := state.LoadStateFile(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to load %s: %w", args[0], err)
+	}
+
+	newState, err := state.LoadStateFile(args[1])
+	if err != nil {
+		return fmt.Errorf("failed to load %s: %w", args[1], err)
+	}
+
+	diff := state.DiffStates(oldState, newState)
+
+	if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+		data, err := json.MarshalIndent(diff, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal diff: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if diff.IsEmpty() {
+		fmt.Println("No differences")
+		return nil
+	}
+
+	fmt.Printf("Added: %d, Removed: %d, Changed: %d\n", len(diff.Added), len(diff.Removed), len(diff.Changed))
+
+	if len(diff.Added) > 0 {
+		fmt.Println()
+		fmt.Println("Added:")
+		for _, doc := range diff.Added {
+			fmt.Printf("  + %s (%s) v%d %s\n", doc.Name, doc.ID, doc.Version, doc.ConversionStatus)
+		}
+	}
+
+	if len(diff.Removed) > 0 {
+		fmt.Println()
+		fmt.Println("Removed:")
+		for _, doc := range diff.Removed {
+			fmt.Printf("  - %s (%s) v%d %s\n", doc.Name, doc.ID, doc.Version, doc.ConversionStatus)
+		}
+	}
+
+	if len(diff.Changed) > 0 {
+		fmt.Println()
+		fmt.Println("Changed:")
+		for _, change := range diff.Changed {
+			fmt.Printf("  ~ %s (%s)\n", change.Name, change.ID)
+			for _, field := range change.Changes {
+				fmt.Printf("      %s: %q -> %q\n", field.Field, field.Old, field.New)
+			}
+		}
+	}
+
+	return nil
+}
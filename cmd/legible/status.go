@@ -0,0 +1,221 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/platinummonkey/legible/internal/daemon"
+	"github.com/spf13/cobra"
+)
+
+// statusRequestTimeout bounds each request to the daemon's status endpoint
+const statusRequestTimeout = 5 * time.Second
+
+// statusCmd reports the state of a running daemon
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the status of a running daemon",
+	Long: `Query a running daemon over HTTP and print its current state, the progress of
+an in-progress sync, and the result of the last completed sync.
+
+The daemon must have been started with --health-addr. If it cannot be reached
+its state is reported as Offline.
+
+Examples:
+  # Show the status of a daemon started with --health-addr :8080
+  legible status
+
+  # Refresh every 2 seconds until interrupted
+  legible status --watch --interval 2s
+
+  # Query a daemon on another port
+  legible status --daemon-addr http://localhost:9090`,
+	Args: cobra.NoArgs,
+	RunE: runStatus,
+}
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+
+	statusCmd.Flags().String("daemon-addr", "http://localhost:8080", "daemon HTTP address")
+	statusCmd.Flags().Bool("watch", false, "keep polling the daemon and print its status on each poll")
+	statusCmd.Flags().Duration("interval", 3*time.Second, "polling interval for --watch")
+}
+
+func runStatus(cmd *cobra.Command, _ []string) error {
+	addr, _ := cmd.Flags().GetString("daemon-addr")
+	watch, _ := cmd.Flags().GetBool("watch")
+	interval, _ := cmd.Flags().GetDuration("interval")
+
+	if interval <= 0 {
+		return fmt.Errorf("invalid --interval %s: must be positive", interval)
+	}
+
+	client := &http.Client{Timeout: statusRequestTimeout}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if !watch {
+		status, err := fetchDaemonStatus(ctx, client, addr)
+		return writeDaemonStatus(os.Stdout, status, err, time.Now())
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		status, err := fetchDaemonStatus(ctx, client, addr)
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		now := time.Now()
+		fmt.Printf("--- %s ---\n", now.Format("15:04:05"))
+		if err := writeDaemonStatus(os.Stdout, status, err, now); err != nil {
+			return err
+		}
+		fmt.Println()
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// fetchDaemonStatus requests the status endpoint of the daemon at addr
+func fetchDaemonStatus(ctx context.Context, client *http.Client, addr string) (*daemon.Status, error) {
+	url := strings.TrimRight(addr, "/") + "/status"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid daemon address %q: %w", addr, err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach daemon: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("daemon returned status %d", resp.StatusCode)
+	}
+
+	var status daemon.Status
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		return nil, fmt.Errorf("failed to decode daemon status: %w", err)
+	}
+
+	return &status, nil
+}
+
+// writeDaemonStatus prints status as reported at now
+//
+// A nil status means the daemon could not be reached; it is reported as Offline
+// along with fetchErr.
+func writeDaemonStatus(w io.Writer, status *daemon.Status, fetchErr error, now time.Time) error {
+	if status == nil {
+		if fetchErr != nil {
+			_, err := fmt.Fprintf(w, "State: Offline (%v)\n", fetchErr)
+			return err
+		}
+		_, err := fmt.Fprintln(w, "State: Offline")
+		return err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "State: %s\n", stateDisplayName(status.State))
+
+	if status.State == daemon.StateError && status.ErrorMessage != "" {
+		fmt.Fprintf(&b, "Error: %s\n", status.ErrorMessage)
+	}
+
+	if status.CurrentSync != nil {
+		writeSyncProgress(&b, status.CurrentSync, now)
+	}
+
+	if status.LastSyncTime != nil {
+		fmt.Fprintf(&b, "Last sync: %s (%s ago)\n",
+			status.LastSyncTime.Local().Format("2006-01-02 15:04:05"),
+			now.Sub(*status.LastSyncTime).Round(time.Second))
+	} else {
+		b.WriteString("Last sync: never\n")
+	}
+
+	if result := status.LastSyncResult; result != nil {
+		fmt.Fprintf(&b, "Last result: %d succeeded, %d failed, %d skipped of %d documents (took %s)\n",
+			result.SuccessCount, result.FailureCount, result.SkippedCount, result.TotalDocuments,
+			result.Duration.Round(time.Millisecond))
+	}
+
+	if status.LastChangeTime != nil {
+		fmt.Fprintf(&b, "Last cloud change: %s (%s ago)\n",
+			status.LastChangeTime.Local().Format("2006-01-02 15:04:05"),
+			now.Sub(*status.LastChangeTime).Round(time.Second))
+	}
+
+	if status.NextSyncTime != nil && status.State != daemon.StateSyncing {
+		fmt.Fprintf(&b, "Next sync: %s", status.NextSyncTime.Local().Format("2006-01-02 15:04:05"))
+		if status.Schedule != "" {
+			fmt.Fprintf(&b, " (schedule: %s)", status.Schedule)
+		}
+		b.WriteString("\n")
+	}
+
+	fmt.Fprintf(&b, "Uptime: %s\n", time.Duration(status.UptimeSeconds)*time.Second)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeSyncProgress prints the progress of a running sync as reported at now
+func writeSyncProgress(b *strings.Builder, progress *daemon.SyncProgress, now time.Time) {
+	fmt.Fprintf(b, "Progress: %d/%d documents", progress.DocumentsProcessed, progress.DocumentsTotal)
+	if progress.CurrentDocument != "" {
+		fmt.Fprintf(b, " (current: %s", progress.CurrentDocument)
+		if progress.Stage != "" {
+			fmt.Fprintf(b, ", %s", progress.Stage)
+		}
+		b.WriteString(")")
+	}
+	b.WriteString("\n")
+	for _, download := range progress.Downloads {
+		title := download.Title
+		if title == "" {
+			title = download.DocumentID
+		}
+		fmt.Fprintf(b, "Downloading: %s (%s of %s)\n", title,
+			formatBytes(download.BytesDownloaded), formatBytes(download.BytesTotal))
+	}
+	if !progress.StartTime.IsZero() {
+		fmt.Fprintf(b, "Running for: %s\n", now.Sub(progress.StartTime).Round(time.Second))
+	}
+}
+
+// stateDisplayName returns the capitalized name of a daemon state
+func stateDisplayName(state daemon.SyncState) string {
+	switch state {
+	case daemon.StateIdle:
+		return "Idle"
+	case daemon.StateSyncing:
+		return "Syncing"
+	case daemon.StateError:
+		return "Error"
+	case "":
+		return "Unknown"
+	default:
+		return string(state)
+	}
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/platinummonkey/legible/internal/daemon"
+)
+
+// statusServer serves status as the daemon's /status endpoint
+func statusServer(t *testing.T, status daemon.Status) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/status" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(status)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestDaemonStatusOutput(t *testing.T) {
+	now := time.Now()
+	lastSync := now.Add(-2 * time.Minute)
+	nextSync := now.Add(3 * time.Minute)
+	lastResult := &daemon.SyncSummary{
+		TotalDocuments: 12,
+		SuccessCount:   3,
+		FailureCount:   1,
+		SkippedCount:   8,
+		Duration:       90 * time.Second,
+	}
+
+	tests := []struct {
+		name    string
+		status  daemon.Status
+		want    []string
+		notWant []string
+	}{
+		{
+			name: "idle",
+			status: daemon.Status{
+				State:          daemon.StateIdle,
+				LastSyncTime:   &lastSync,
+				NextSyncTime:   &nextSync,
+				Schedule:       "0 * * * *",
+				LastChangeTime: &lastSync,
+				LastSyncResult: lastResult,
+				UptimeSeconds:  3600,
+			},
+			want: []string{
+				"State: Idle\n",
+				"(2m0s ago)",
+				"Last result: 3 succeeded, 1 failed, 8 skipped of 12 documents (took 1m30s)\n",
+				"Next sync: ",
+				" (schedule: 0 * * * *)\n",
+				"Last cloud change: ",
+				"Uptime: 1h0m0s\n",
+			},
+			notWant: []string{"Progress:", "Error:"},
+		},
+		{
+			name: "syncing",
+			status: daemon.Status{
+				State:        daemon.StateSyncing,
+				LastSyncTime: &lastSync,
+				NextSyncTime: &nextSync,
+				CurrentSync: &daemon.SyncProgress{
+					StartTime:          now.Add(-45 * time.Second),
+					DocumentsTotal:     10,
+					DocumentsProcessed: 4,
+					CurrentDocument:    "Meeting Notes",
+					Stage:              "converting",
+					Downloads: []daemon.DownloadStatus{
+						{DocumentID: "doc-1", Title: "Annotated Paper", BytesDownloaded: 3 << 20, BytesTotal: 12 << 20},
+					},
+				},
+			},
+			want: []string{
+				"State: Syncing\n",
+				"Progress: 4/10 documents (current: Meeting Notes, converting)\n",
+				"Downloading: Annotated Paper (3.0 MB of 12.0 MB)\n",
+				"Running for: 45s\n",
+			},
+			notWant: []string{"Next sync:", "Last result:"},
+		},
+		{
+			name: "error",
+			status: daemon.Status{
+				State:          daemon.StateError,
+				ErrorMessage:   "authentication failed",
+				LastSyncTime:   &lastSync,
+				LastSyncResult: lastResult,
+			},
+			want: []string{
+				"State: Error\n",
+				"Error: authentication failed\n",
+				"Last result: 3 succeeded, 1 failed",
+			},
+			notWant: []string{"Progress:"},
+		},
+		{
+			name:   "never synced",
+			status: daemon.Status{State: daemon.StateIdle},
+			want:   []string{"State: Idle\n", "Last sync: never\n"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := statusServer(t, tt.status)
+
+			status, err := fetchDaemonStatus(context.Background(), server.Client(), server.URL+"/")
+			if err != nil {
+				t.Fatalf("fetchDaemonStatus() error = %v", err)
+			}
+
+			var buf bytes.Buffer
+			if err := writeDaemonStatus(&buf, status, nil, now); err != nil {
+				t.Fatalf("writeDaemonStatus() error = %v", err)
+			}
+			out := buf.String()
+
+			for _, want := range tt.want {
+				if !strings.Contains(out, want) {
+					t.Errorf("output missing %q:\n%s", want, out)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(out, notWant) {
+					t.Errorf("output should not contain %q:\n%s", notWant, out)
+				}
+			}
+		})
+	}
+}
+
+func TestDaemonStatusOutput_Offline(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	addr := server.URL
+	server.Close()
+
+	status, err := fetchDaemonStatus(context.Background(), &http.Client{Timeout: time.Second}, addr)
+	if err == nil {
+		t.Fatal("fetchDaemonStatus() should fail when the daemon is not running")
+	}
+
+	var buf bytes.Buffer
+	if err := writeDaemonStatus(&buf, status, err, time.Now()); err != nil {
+		t.Fatalf("writeDaemonStatus() error = %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "State: Offline") {
+		t.Errorf("output = %q, want Offline state", buf.String())
+	}
+}
+
+func TestFetchDaemonStatus_HTTPError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	if _, err := fetchDaemonStatus(context.Background(), server.Client(), server.URL); err == nil {
+		t.Error("fetchDaemonStatus() should fail on a non-200 response")
+	}
+}
@@ -4,12 +4,14 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/platinummonkey/legible/internal/config"
 	"github.com/platinummonkey/legible/internal/converter"
 	"github.com/platinummonkey/legible/internal/logger"
 	"github.com/platinummonkey/legible/internal/ocr"
+	"github.com/platinummonkey/legible/internal/ollama"
 	"github.com/platinummonkey/legible/internal/pdfenhancer"
 	"github.com/platinummonkey/legible/internal/rmclient"
 	"github.com/platinummonkey/legible/internal/state"
@@ -26,7 +28,7 @@ var syncCmd = &cobra.Command{
 
 This command:
 1. Lists documents from reMarkable API
-2. Filters by labels (if specified)
+2. Filters by folder and labels (if specified)
 3. Identifies new or changed documents
 4. Downloads and converts to PDF
 5. Optionally adds OCR text layer
@@ -41,11 +43,21 @@ Examples:
   # Sync only documents with "work" label
   legible sync --labels work
 
+  # Sync only documents in a folder (and its subfolders)
+  legible sync --folder "Work/Journal"
+
   # Sync to specific directory without OCR
   legible sync --output ~/Documents/ReMarkable --no-ocr
 
   # Force re-sync all documents (ignores state)
-  legible sync --force`,
+  legible sync --force
+
+  # List the documents a sync would process, without syncing them
+  legible sync --dry-run
+
+  # Preview, then remove, documents deleted from the cloud and their PDFs
+  legible sync --prune --prune-files --dry-run-prune
+  legible sync --prune --prune-files`,
 	RunE: runSync,
 }
 
@@ -55,6 +67,16 @@ func init() {
 	// Sync-specific flags
 	syncCmd.Flags().Bool("force", false, "force re-sync all documents (ignore state)")
 	_ = viper.BindPFlag("force", syncCmd.Flags().Lookup("force"))
+	syncCmd.Flags().String("folder", "", "sync only documents under this folder (name or path, e.g. \"Work/Journal\")")
+	_ = viper.BindPFlag("folder", syncCmd.Flags().Lookup("folder"))
+	syncCmd.Flags().Bool("dry-run", false, "list the documents that would be synced and why, without downloading or converting them")
+	_ = viper.BindPFlag("dry-run", syncCmd.Flags().Lookup("dry-run"))
+	syncCmd.Flags().Bool("prune", false, "remove documents deleted from the reMarkable cloud from the sync state")
+	_ = viper.BindPFlag("prune", syncCmd.Flags().Lookup("prune"))
+	syncCmd.Flags().Bool("prune-files", false, "with --prune, also delete the local PDFs of deleted documents")
+	_ = viper.BindPFlag("prune-files", syncCmd.Flags().Lookup("prune-files"))
+	syncCmd.Flags().Bool("dry-run-prune", false, "with --prune, list the documents that would be pruned without removing them")
+	_ = viper.BindPFlag("dry-run-prune", syncCmd.Flags().Lookup("dry-run-prune"))
 }
 
 func runSync(_ *cobra.Command, _ []string) error {
@@ -72,12 +94,20 @@ func runSync(_ *cobra.Command, _ []string) error {
 		return err
 	}
 
+	// Show the progress of large downloads when run interactively
+	if isTerminal(os.Stderr) {
+		orch.OnDownloadProgress(newDownloadProgressBar(os.Stderr).update)
+	}
+
 	// Run sync
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
 	defer cancel()
 
 	result, err := orch.Sync(ctx)
 	if err != nil {
+		if hint := sync.RemediationHint(err); hint != "" {
+			return fmt.Errorf("sync failed: %w\nHint: %s", err, hint)
+		}
 		return fmt.Errorf("sync failed: %w", err)
 	}
 
@@ -92,8 +122,9 @@ func initConfigAndLogger() (*config.Config, *logger.Logger, error) {
 	}
 
 	log, err := logger.New(&logger.Config{
-		Level:  cfg.LogLevel,
-		Format: "console",
+		Level:           cfg.LogLevel,
+		Format:          "console",
+		ComponentLevels: cfg.LogLevels,
 	})
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to initialize logger: %w", err)
@@ -105,7 +136,12 @@ func initConfigAndLogger() (*config.Config, *logger.Logger, error) {
 func initSyncComponents(cfg *config.Config, log *logger.Logger) (*sync.Orchestrator, error) {
 	// Initialize reMarkable client
 	rmClient, err := rmclient.NewClient(&rmclient.Config{
-		Logger: log,
+		Logger:       log.Named("rmclient"),
+		HostRewrites: cfg.HostRewrites,
+		AuthURL:      cfg.APIAuthURL,
+		StorageURL:   cfg.APIStorageURL,
+		SyncURL:      cfg.APISyncURL,
+		LabelMatch:   rmclient.LabelMatch(cfg.LabelMatch),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create client: %w", err)
@@ -117,77 +153,156 @@ func initSyncComponents(cfg *config.Config, log *logger.Logger) (*sync.Orchestra
 	}
 
 	// Load state store
-	stateStore, err := state.LoadOrCreate(cfg.StateFile)
+	stateStore, err := state.Open(cfg.StateBackend, cfg.StateFile)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize state: %w", err)
 	}
 
-	// Handle force flag
-	if viper.GetBool("force") {
+	// Handle force flag; a dry run leaves the state alone
+	if viper.GetBool("force") && viper.GetBool("dry-run") {
+		log.Warn("Ignoring --force during a dry run")
+	} else if viper.GetBool("force") {
 		log.Info("Force flag set, clearing sync state")
-		if err := os.Remove(cfg.StateFile); err != nil && !os.IsNotExist(err) {
-			log.WithFields("error", err).Warn("Failed to remove state file")
+		lock, err := stateStore.Lock(cfg.StateLockTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("failed to lock sync state: %w", err)
+		}
+		stateStore.Reset()
+		if err := stateStore.Save(); err != nil {
+			log.WithFields("error", err).Warn("Failed to clear state")
 		}
+		_ = lock.Unlock()
 	}
 
+	return newOrchestrator(cfg, log, rmClient, stateStore)
+}
+
+// newOrchestrator creates the sync orchestrator, with its OCR processor and converter,
+// for an rmClient and state store
+func newOrchestrator(cfg *config.Config, log *logger.Logger, rmClient *rmclient.Client, stateStore state.Store) (*sync.Orchestrator, error) {
 	// Parse OCR languages from config
 	ocrLangs := []string{"eng"}
 	if cfg.OCRLanguages != "" {
 		ocrLangs = []string{cfg.OCRLanguages}
 	}
 
-	// Initialize OCR processor and PDF enhancer if enabled
+	// Initialize OCR processor if enabled
 	var ocrProc *ocr.Processor
-	var pdfEnhancer *pdfenhancer.PDFEnhancer
 	if cfg.OCREnabled {
-		// Convert config.LLMConfig to ocr.VisionClientConfig
-		visionConfig := &ocr.VisionClientConfig{
-			Provider:    ocr.ProviderType(cfg.LLM.Provider),
-			Model:       cfg.LLM.Model,
-			Endpoint:    cfg.LLM.Endpoint,
-			APIKey:      cfg.LLM.APIKey,
-			MaxRetries:  cfg.LLM.MaxRetries,
-			Temperature: cfg.LLM.Temperature,
-		}
-
-		ocrProc, err = ocr.New(&ocr.Config{
-			Logger:       log,
-			VisionConfig: visionConfig,
-		})
+		var err error
+		ocrProc, err = newOCRProcessor(cfg, log)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create OCR processor: %w", err)
+			return nil, err
 		}
-
-		pdfEnhancer = pdfenhancer.New(&pdfenhancer.Config{
-			Logger: log,
-		})
 	}
 
-	// Initialize converter with pre-configured processors
-	conv, err := converter.New(&converter.Config{
-		Logger:       log,
-		EnableOCR:    cfg.OCREnabled,
-		OCRLanguages: ocrLangs,
-		OCRProcessor: ocrProc,
-		PDFEnhancer:  pdfEnhancer,
+	// The orchestrator requires a PDF enhancer even without OCR
+	pdfEnhancer := pdfenhancer.New(&pdfenhancer.Config{
+		Logger: log.Named("pdfenhancer"),
 	})
+
+	// Initialize converter with pre-configured processors
+	conv, err := newConverter(cfg, log, ocrLangs, ocrProc, pdfEnhancer)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create converter: %w", err)
 	}
 
 	// Create and return sync orchestrator
 	return sync.New(&sync.Config{
-		Config:       cfg,
-		Logger:       log,
-		RMClient:     rmClient,
-		StateStore:   stateStore,
-		Converter:    conv,
-		OCRProcessor: ocrProc,
-		PDFEnhancer:  pdfEnhancer,
+		Config:              cfg,
+		Logger:              log.Named("sync"),
+		RMClient:            rmClient,
+		StateStore:          stateStore,
+		Converter:           conv,
+		OCRProcessor:        ocrProc,
+		PDFEnhancer:         pdfEnhancer,
+		Concurrency:         cfg.Concurrency,
+		DownloadConcurrency: cfg.DownloadConcurrency,
+		PipelineDepth:       cfg.PipelineDepth,
+		StateLockTimeout:    cfg.StateLockTimeout,
+		DryRun:              viper.GetBool("dry-run"),
+
+		PruneDeleted:     viper.GetBool("prune"),
+		PruneOutputFiles: viper.GetBool("prune-files"),
+		DryRunPrune:      viper.GetBool("dry-run-prune"),
+	})
+}
+
+// newOCRProcessor creates an OCR processor for the configured LLM provider
+func newOCRProcessor(cfg *config.Config, log *logger.Logger) (*ocr.Processor, error) {
+	// Convert config.LLMConfig to ocr.VisionClientConfig
+	visionConfig := &ocr.VisionClientConfig{
+		Provider:    ocr.ProviderType(cfg.LLM.Provider),
+		Model:       cfg.LLM.Model,
+		Endpoint:    cfg.LLM.Endpoint,
+		APIKey:      cfg.LLM.APIKey,
+		MaxRetries:  cfg.LLM.MaxRetries,
+		Temperature: cfg.LLM.Temperature,
+
+		StructuredImage: ollama.ImageEncoding{Format: cfg.OCRStructuredImageFormat, Quality: cfg.OCRStructuredImageQuality},
+		SimpleImage:     ollama.ImageEncoding{Format: cfg.OCRSimpleImageFormat, Quality: cfg.OCRSimpleImageQuality},
+		Languages:       cfg.OCRLanguages,
+		PromptPath:      cfg.OCRPromptFile,
+	}
+
+	ocrProc, err := ocr.New(&ocr.Config{
+		Logger:       log.Named("ocr"),
+		VisionConfig: visionConfig,
+
+		// OCR languages are Tesseract language codes, e.g. "eng+fra"
+		Engine:             ocr.Engine(cfg.OCREngine),
+		TesseractLanguages: cfg.OCRLanguages,
+
+		Grayscale:         cfg.OCRGrayscale,
+		MaxImageDimension: cfg.OCRMaxImageDimension,
+		ContrastFactor:    cfg.OCRContrast,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OCR processor: %w", err)
+	}
+	return ocrProc, nil
+}
+
+// newConverter creates the converter used by convert, sync, and the daemon, so each
+// produces the same PDFs, including the OCR text layer when ocrProc is set
+func newConverter(cfg *config.Config, log *logger.Logger, ocrLangs []string, ocrProc *ocr.Processor, pdfEnhancer *pdfenhancer.PDFEnhancer) (*converter.Converter, error) {
+	return converter.New(&converter.Config{
+		Logger:        log.Named("converter"),
+		EnableOCR:     cfg.OCREnabled,
+		OCRLanguages:  ocrLangs,
+		OCRProcessor:  ocrProc,
+		OCRPromptPath: cfg.OCRPromptFile,
+		PDFEnhancer:   pdfEnhancer,
+		TextOnly:      cfg.TextOnly,
+
+		MarkdownTranscript: cfg.MarkdownTranscript,
+		OCRSidecarFormat:   cfg.OCRSidecar,
+		OCRMinConfidence:   cfg.OCRMinConfidence,
+		SplitByPageTag:     cfg.SplitByPageTag,
+		RenderConcurrency:  cfg.RenderConcurrency,
+		BookmarksFromTags:  cfg.BookmarksFromTags,
+		OCRCacheDir:        ocrCacheDir(cfg),
+		RenderCacheDir:     cfg.RenderCacheDir,
+		EncryptPassword:    cfg.EncryptPassword,
+		ChunkPages:         cfg.ChunkPages,
+		UnparseablePolicy:  cfg.UnparseableDocuments,
 	})
 }
 
+// ocrCacheDir returns the OCR cache directory, or "" if the cache is disabled
+func ocrCacheDir(cfg *config.Config) string {
+	if !cfg.OCRCache {
+		return ""
+	}
+	return cfg.OCRCacheDir
+}
+
 func displaySyncResults(result *sync.Result) error {
+	if result.DryRun {
+		displaySyncPlan(result)
+		return nil
+	}
+
 	fmt.Println()
 	fmt.Println("=== Sync Complete ===")
 	fmt.Printf("Total documents: %d\n", result.TotalDocuments)
@@ -195,11 +310,19 @@ func displaySyncResults(result *sync.Result) error {
 	fmt.Printf("Successful: %d\n", result.SuccessCount)
 	fmt.Printf("Failed: %d\n", result.FailureCount)
 	fmt.Printf("Duration: %v\n", result.Duration)
+	if !result.OCR.IsZero() {
+		fmt.Printf("OCR cache: %d hits, %d misses\n", result.OCR.CacheHits, result.OCR.CacheMisses)
+		fmt.Printf("OCR fallback: %d requests\n", result.OCR.Fallbacks)
+	}
+	displayPruned(result)
 
 	if result.HasFailures() {
 		fmt.Println("\nFailures:")
 		for _, failure := range result.Failures {
 			fmt.Printf("  - %s: %v\n", failure.Title, failure.Error)
+			if failure.Hint != "" {
+				fmt.Printf("    Hint: %s\n", failure.Hint)
+			}
 		}
 		return fmt.Errorf("sync completed with %d failures", result.FailureCount)
 	}
@@ -207,6 +330,40 @@ func displaySyncResults(result *sync.Result) error {
 	return nil
 }
 
+func displaySyncPlan(result *sync.Result) {
+	fmt.Println()
+	fmt.Println("=== Sync Plan (dry run) ===")
+	fmt.Printf("Total documents: %d\n", result.TotalDocuments)
+	fmt.Printf("Would sync: %d\n", len(result.Planned))
+
+	for _, doc := range result.Planned {
+		fmt.Printf("  - %s (%s, version %d): %s\n", doc.Title, doc.DocumentID, doc.Version, doc.Reason)
+	}
+	displayPruned(result)
+}
+
+func displayPruned(result *sync.Result) {
+	if len(result.Pruned) == 0 {
+		return
+	}
+
+	verb := "Pruned"
+	if result.PruneDryRun {
+		verb = "Would prune"
+	}
+	fmt.Printf("\n%s %d documents deleted from the cloud:\n", verb, len(result.Pruned))
+	for _, doc := range result.Pruned {
+		switch {
+		case doc.FileDeleted && result.PruneDryRun:
+			fmt.Printf("  - %s (%s), would delete %s\n", doc.Title, doc.DocumentID, doc.LocalPath)
+		case doc.FileDeleted:
+			fmt.Printf("  - %s (%s), deleted %s\n", doc.Title, doc.DocumentID, doc.LocalPath)
+		default:
+			fmt.Printf("  - %s (%s)\n", doc.Title, doc.DocumentID)
+		}
+	}
+}
+
 func loadConfig() (*config.Config, error) {
 	// Get the config file path if viper has read one
 	// Pass empty string if no config file was found (will use defaults)
@@ -219,18 +376,72 @@ func loadConfig() (*config.Config, error) {
 	}
 
 	// Override with command-line flags if provided
-	if viper.IsSet("output-dir") {
-		cfg.OutputDir = viper.GetString("output-dir")
+	applyFilterFlags(cfg)
+	applyOCRFlags(cfg)
+	applyOutputFlags(cfg)
+	if viper.IsSet("log-level") {
+		cfg.LogLevel = viper.GetString("log-level")
 	}
+
+	return cfg, nil
+}
+
+// applyFilterFlags overrides the document selection of cfg with the command-line flags
+// that are set
+func applyFilterFlags(cfg *config.Config) {
 	if viper.IsSet("labels") {
 		cfg.Labels = viper.GetStringSlice("labels")
 	}
+	if viper.IsSet("label-match") {
+		cfg.LabelMatch = viper.GetString("label-match")
+	}
+	if viper.IsSet("folder") {
+		cfg.Folder = viper.GetString("folder")
+	}
+}
+
+// applyOCRFlags overrides the OCR settings of cfg with the command-line flags that
+// are set
+func applyOCRFlags(cfg *config.Config) {
 	if viper.IsSet("no-ocr") {
 		cfg.OCREnabled = !viper.GetBool("no-ocr")
 	}
-	if viper.IsSet("log-level") {
-		cfg.LogLevel = viper.GetString("log-level")
+	if viper.IsSet("text-only") {
+		cfg.TextOnly = viper.GetBool("text-only")
+	}
+	if viper.IsSet("markdown-transcript") {
+		cfg.MarkdownTranscript = viper.GetBool("markdown-transcript")
+	}
+	if viper.IsSet("ocr-sidecar") {
+		cfg.OCRSidecar = strings.ToLower(viper.GetString("ocr-sidecar"))
+	}
+	if viper.IsSet("ocr-min-confidence") {
+		cfg.OCRMinConfidence = viper.GetFloat64("ocr-min-confidence")
+	}
+	if viper.IsSet("ocr-cache-dir") {
+		cfg.OCRCacheDir = viper.GetString("ocr-cache-dir")
+	}
+	if viper.IsSet("no-ocr-cache") {
+		cfg.OCRCache = !viper.GetBool("no-ocr-cache")
 	}
+}
 
-	return cfg, nil
+// applyOutputFlags overrides the output settings of cfg with the command-line flags
+// that are set
+func applyOutputFlags(cfg *config.Config) {
+	if viper.IsSet("output-dir") {
+		cfg.OutputDir = viper.GetString("output-dir")
+	}
+	if viper.IsSet("split-by-page-tag") {
+		cfg.SplitByPageTag = viper.GetBool("split-by-page-tag")
+	}
+	if viper.IsSet("bookmarks-from-tags") {
+		cfg.BookmarksFromTags = viper.GetBool("bookmarks-from-tags")
+	}
+	if viper.IsSet("chunk-pages") {
+		cfg.ChunkPages = viper.GetInt("chunk-pages")
+	}
+	if viper.IsSet("encrypt-password") {
+		cfg.EncryptPassword = viper.GetString("encrypt-password")
+	}
 }
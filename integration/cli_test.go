@@ -6,6 +6,8 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
 )
 
 // TestCLIBuild tests that the CLI binary can be built
@@ -103,6 +105,7 @@ func TestCLIHelp(t *testing.T) {
 		{"sync help", []string{"sync", "--help"}},
 		{"auth help", []string{"auth", "--help"}},
 		{"daemon help", []string{"daemon", "--help"}},
+		{"convert help", []string{"convert", "--help"}},
 	}
 
 	for _, tt := range tests {
@@ -301,3 +304,53 @@ func TestCLIInvalidCommand(t *testing.T) {
 		t.Errorf("Should show error for invalid command\nOutput: %s", outputStr)
 	}
 }
+
+// TestCLIConvert tests converting a local .rmdoc file without OCR
+func TestCLIConvert(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping CLI test in short mode")
+	}
+
+	testRmdoc := "../testdata/rmdoc/Test.rmdoc"
+	if _, err := os.Stat(testRmdoc); os.IsNotExist(err) {
+		t.Skip("Test.rmdoc not found in testdata, skipping test")
+	}
+
+	tmpDir := t.TempDir()
+	binaryPath := filepath.Join(tmpDir, "legible-test")
+	outputPath := filepath.Join(tmpDir, "out", "Test.pdf")
+
+	// Build binary
+	cmd := exec.Command("go", "build", "-o", binaryPath, "../cmd/legible")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("Failed to build CLI: %v\nOutput: %s", err, output)
+	}
+
+	// Use an empty home directory so no user config is picked up
+	cmd = exec.Command(binaryPath, "convert", testRmdoc, outputPath, "--no-ocr", "--paper-size", "a4")
+	cmd.Env = append(os.Environ(), "HOME="+tmpDir)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("Convert command failed: %v\nOutput: %s", err, output)
+	}
+
+	outputStr := string(output)
+	if !strings.Contains(outputStr, "Pages: 2") || !strings.Contains(outputStr, "OCR: disabled") {
+		t.Errorf("Convert output should summarize the conversion\nOutput: %s", outputStr)
+	}
+
+	pageCount, err := api.PageCountFile(outputPath)
+	if err != nil {
+		t.Fatalf("Failed to read output PDF: %v", err)
+	}
+	if pageCount != 2 {
+		t.Errorf("Output PDF has %d pages, want 2", pageCount)
+	}
+
+	// Invalid options are rejected before converting
+	cmd = exec.Command(binaryPath, "convert", testRmdoc, outputPath, "--no-ocr", "--paper-size", "B5")
+	cmd.Env = append(os.Environ(), "HOME="+tmpDir)
+	if output, err := cmd.CombinedOutput(); err == nil {
+		t.Errorf("Convert should reject an invalid paper size\nOutput: %s", output)
+	}
+}
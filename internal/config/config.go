@@ -10,6 +10,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/robfig/cron"
 	"github.com/spf13/viper"
 )
 
@@ -22,24 +23,185 @@ type Config struct {
 	// Labels filters documents by reMarkable labels (empty means sync all documents)
 	Labels []string
 
+	// LabelMatch selects documents having any (default) or all of Labels
+	LabelMatch string
+
+	// Folder limits syncing to documents under this folder, given by name or path
+	// such as "Work/Journal" (empty means all folders)
+	Folder string
+
 	// OCREnabled determines whether OCR processing should be performed
 	OCREnabled bool
 
 	// OCRLanguages specifies the languages to use for OCR (e.g., "eng", "eng+fra")
 	OCRLanguages string
 
+	// OCREngine selects the OCR engine: OCREngineOllama (the llm provider),
+	// OCREngineTesseract, or OCREngineAuto (the llm provider, falling back to Tesseract
+	// while it is unavailable)
+	OCREngine string
+
+	// TextOnly writes transcripts instead of the handwriting: each page is blank apart
+	// from its OCR text, drawn visibly (requires OCREnabled)
+	TextOnly bool
+
+	// MarkdownTranscript also writes each document's OCR text as a Markdown file next to
+	// its PDF, with tables as Markdown tables (requires OCREnabled)
+	MarkdownTranscript bool
+
+	// OCRSidecar also writes each document's raw OCR results next to its PDF, as
+	// OCRSidecarJSON, OCRSidecarText, or OCRSidecarHOCR ("" writes none; requires OCREnabled)
+	OCRSidecar string
+
+	// OCRMinConfidence drops OCR words recognized with less confidence (0-100) from the
+	// searchable text layer (0 keeps every word)
+	OCRMinConfidence float64
+
+	// OCRGrayscale converts page images to grayscale before OCR
+	OCRGrayscale bool
+
+	// OCRMaxImageDimension downscales page images whose longest side is larger, in
+	// pixels, before OCR (0 sends them at full size)
+	OCRMaxImageDimension int
+
+	// OCRStructuredImageFormat and OCRStructuredImageQuality are the format of page
+	// images sent to Ollama for structured OCR (OCRImagePNG or OCRImageJPEG), and the
+	// JPEG quality (1-100)
+	OCRStructuredImageFormat  string
+	OCRStructuredImageQuality int
+
+	// OCRSimpleImageFormat and OCRSimpleImageQuality are the same for simple OCR, the
+	// fallback when structured OCR fails
+	OCRSimpleImageFormat  string
+	OCRSimpleImageQuality int
+
+	// OCRContrast multiplies the contrast of page images before OCR (0 or 1 leaves it
+	// unchanged)
+	OCRContrast float64
+
+	// OCRPromptFile is a YAML prompt file (model, system, prompt) used for structured
+	// OCR with Ollama in place of the embedded prompts (empty uses the embedded ones)
+	OCRPromptFile string
+
+	// OCRCache keeps each page's OCR result in OCRCacheDir, keyed by a hash of the
+	// rendered page and the OCR model, so unchanged pages aren't sent to the model again
+	OCRCache bool
+
+	// OCRCacheDir is the OCR cache directory (default: ~/.legible-ocr-cache; empty
+	// disables the cache)
+	OCRCacheDir string
+
+	// SplitByPageTag also writes the pages carrying each page tag as a PDF of their own
+	// next to the document's PDF, named "<name> - <tag>.pdf"
+	SplitByPageTag bool
+
+	// BookmarksFromTags adds a PDF outline with a bookmark for each tagged page,
+	// named after its page tags
+	BookmarksFromTags bool
+
+	// EncryptPassword encrypts every PDF written with this password, needed to open
+	// them (empty leaves them unencrypted)
+	EncryptPassword string
+
+	// ChunkPages also writes documents longer than this many pages in chunks of this
+	// many pages, as "<name>-001.pdf", "<name>-002.pdf", ... (0 writes no chunks)
+	ChunkPages int
+
+	// OutputFormats picks the output format of documents by label or folder; the first
+	// matching rule wins, and documents matching none are written as PDFs
+	OutputFormats []OutputFormatRule
+
+	// DuplicateNames controls how documents with the same name in the same folder are saved
+	// ("suffix" appends a short document ID, "overwrite" keeps the previous behavior)
+	DuplicateNames string
+
+	// ExistingOutput controls whether an output PDF that is newer than its source document
+	// is overwritten ("overwrite") or kept, e.g. to preserve local edits ("skip-newer")
+	ExistingOutput string
+
+	// UnparseableDocuments controls documents none of whose pages could be parsed, such
+	// as ones written in a newer .rm format: UnparseableSkip, UnparseablePlaceholder, or
+	// UnparseableFail
+	UnparseableDocuments string
+
+	// ProcessOrder is the order documents are processed in each sync: ProcessOrderFiletree,
+	// ProcessOrderModified, ProcessOrderName, or ProcessOrderSize
+	ProcessOrder string
+
+	// DownloadDir caches downloaded .rmdoc files by document ID and version so retries
+	// reuse them (empty means download to a temporary directory that is removed after processing)
+	DownloadDir string
+
+	// MaxRetries is how many times a failed document is retried, with exponential backoff,
+	// before it is skipped until its remote version changes
+	MaxRetries int
+
+	// RetryBackoff is the delay before a failed document's first retry, doubling for each
+	// further retry
+	RetryBackoff time.Duration
+
+	// Concurrency is the number of documents synced in parallel
+	Concurrency int
+
+	// DownloadConcurrency is the most documents downloaded at once (0 = limited only by
+	// Concurrency)
+	DownloadConcurrency int
+
+	// RenderConcurrency is the number of pages of a document rendered in parallel
+	RenderConcurrency int
+
+	// RenderCacheDir caches rendered pages, keyed by a hash of their .rm files, so
+	// pages unchanged between syncs aren't rendered again (empty disables the cache)
+	RenderCacheDir string
+
+	// PipelineDepth pipelines syncs: documents download one after another, up to this
+	// many ahead of the conversions, so downloads overlap conversions (0 = not pipelined)
+	PipelineDepth int
+
 	// SyncInterval is the duration between sync operations in daemon mode (0 = run once)
 	SyncInterval time.Duration
 
+	// SyncSchedule is a cron expression for when the daemon syncs (e.g. "0 * * * *");
+	// when set it is used instead of SyncInterval
+	SyncSchedule string
+
+	// ChangePollInterval is how often the daemon checks the cloud for changes, syncing as
+	// soon as one is found rather than waiting for the next scheduled sync (0 = don't check)
+	ChangePollInterval time.Duration
+
 	// StateFile is the path to the sync state persistence file
 	StateFile string
 
+	// StateBackend is where the sync state is kept (json, sqlite); with sqlite, StateFile
+	// is the path of the database
+	StateBackend string
+
+	// StateLockTimeout is how long a sync waits for another legible process to release
+	// the state lock before failing (0 = fail immediately)
+	StateLockTimeout time.Duration
+
 	// LogLevel controls logging verbosity (debug, info, warn, error)
 	LogLevel string
 
+	// LogLevels overrides LogLevel per component (e.g. rmclient, ocr, converter, sync)
+	LogLevels map[string]string
+
+	// LogFile is a file that receives log output in addition to the console (empty = console only)
+	LogFile string
+
 	// RemarkableToken is the authentication token for the reMarkable API
 	RemarkableToken string
 
+	// HostRewrites overrides the rewrites applied to known-bad reMarkable API hosts
+	// (hostname -> replacement; an empty replacement disables a built-in rule)
+	HostRewrites map[string]string
+
+	// APIAuthURL, APIStorageURL, and APISyncURL replace the base URLs of the reMarkable
+	// auth, document storage, and sync APIs (empty = the reMarkable cloud)
+	APIAuthURL    string
+	APIStorageURL string
+	APISyncURL    string
+
 	// DaemonMode enables continuous sync operation
 	DaemonMode bool
 
@@ -47,6 +209,125 @@ type Config struct {
 	LLM LLMConfig
 }
 
+// Label match modes for filtering documents by Labels
+const (
+	// LabelMatchAny selects documents with at least one of the labels
+	LabelMatchAny = "any"
+
+	// LabelMatchAll selects documents with every label
+	LabelMatchAll = "all"
+)
+
+// Duplicate name strategies for documents sharing a name in the same output folder
+const (
+	// DuplicateNamesSuffix appends a short document ID suffix to colliding file names
+	DuplicateNamesSuffix = "suffix"
+
+	// DuplicateNamesOverwrite lets later documents overwrite earlier ones
+	DuplicateNamesOverwrite = "overwrite"
+)
+
+// Policies for output PDFs that already exist when a document is synced
+const (
+	// ExistingOutputOverwrite always replaces the output PDF
+	ExistingOutputOverwrite = "overwrite"
+
+	// ExistingOutputSkipNewer keeps an output PDF modified after the source document
+	ExistingOutputSkipNewer = "skip-newer"
+)
+
+// Policies for documents none of whose pages could be parsed
+const (
+	// UnparseableSkip writes no output, logging a warning; the document is tried again
+	// once a new version is available
+	UnparseableSkip = "skip"
+
+	// UnparseablePlaceholder writes a one page PDF with a note saying why the document
+	// could not be converted
+	UnparseablePlaceholder = "placeholder"
+
+	// UnparseableFail fails the document, so it is retried like any other failure
+	UnparseableFail = "fail"
+)
+
+// Orders documents are processed in during a sync
+const (
+	// ProcessOrderFiletree processes documents in the order the cloud lists them
+	ProcessOrderFiletree = "filetree"
+
+	// ProcessOrderModified processes the most recently modified documents first
+	ProcessOrderModified = "modified"
+
+	// ProcessOrderName processes documents alphabetically by name
+	ProcessOrderName = "name"
+
+	// ProcessOrderSize processes the smallest documents first, by their size at the
+	// last sync; documents never synced come last
+	ProcessOrderSize = "size"
+)
+
+// OCR engines
+const (
+	// OCREngineOllama performs OCR with the configured llm provider (Ollama by default)
+	OCREngineOllama = "ollama"
+
+	// OCREngineTesseract performs OCR locally with the tesseract CLI
+	OCREngineTesseract = "tesseract"
+
+	// OCREngineAuto uses the llm provider, falling back to Tesseract while it is unavailable
+	OCREngineAuto = "auto"
+)
+
+// OCR sidecar formats
+const (
+	// OCRSidecarJSON writes the OCR results, with word boxes and confidence, as <name>.json
+	OCRSidecarJSON = "json"
+
+	// OCRSidecarText writes each page's OCR text as <name>.txt
+	OCRSidecarText = "text"
+
+	// OCRSidecarHOCR writes the OCR results as hOCR, with word boxes, as <name>.hocr
+	OCRSidecarHOCR = "hocr"
+)
+
+// Formats of page images sent for OCR
+const (
+	// OCRImagePNG sends page images as lossless PNGs
+	OCRImagePNG = "png"
+
+	// OCRImageJPEG sends page images as JPEGs, which are smaller
+	OCRImageJPEG = "jpeg"
+)
+
+// defaultOCRImageQuality is the JPEG quality of page images sent for OCR
+const defaultOCRImageQuality = 90
+
+// Output formats for synced documents
+const (
+	// OutputFormatPDF writes a PDF, with an OCR text layer when OCR is enabled
+	OutputFormatPDF = "pdf"
+
+	// OutputFormatPNG writes a directory holding a PNG image of each page
+	OutputFormatPNG = "png"
+)
+
+// OutputFormatRule selects the output format of the documents with a label, or under
+// a folder (a path such as "Sketches/2025", matching its subfolders too)
+type OutputFormatRule struct {
+	Label  string `mapstructure:"label"`
+	Folder string `mapstructure:"folder"`
+	Format string `mapstructure:"format"`
+}
+
+// Backends for the sync state
+const (
+	// StateBackendJSON keeps the state in a JSON file rewritten on every save
+	StateBackendJSON = "json"
+
+	// StateBackendSQLite keeps the state in a SQLite database, writing only changed documents
+	StateBackendSQLite = "sqlite"
+)
+
 // LLMConfig holds configuration for LLM-based OCR providers
 type LLMConfig struct {
 	// Provider is the LLM provider to use (ollama, openai, anthropic, google)
@@ -128,15 +409,59 @@ func Load(configFile string) (*Config, error) {
 
 	// Build config struct
 	config := &Config{
-		OutputDir:       v.GetString("output-dir"),
-		Labels:          v.GetStringSlice("labels"),
-		OCREnabled:      v.GetBool("ocr-enabled"),
-		OCRLanguages:    v.GetString("ocr-languages"),
-		SyncInterval:    v.GetDuration("sync-interval"),
-		StateFile:       v.GetString("state-file"),
-		LogLevel:        v.GetString("log-level"),
-		RemarkableToken: v.GetString("api-token"),
-		DaemonMode:      v.GetBool("daemon-mode"),
+		OutputDir:            v.GetString("output-dir"),
+		Labels:               v.GetStringSlice("labels"),
+		LabelMatch:           v.GetString("label-match"),
+		Folder:               v.GetString("folder"),
+		OCREnabled:           v.GetBool("ocr-enabled"),
+		OCRLanguages:         v.GetString("ocr-languages"),
+		OCREngine:            v.GetString("ocr-engine"),
+		TextOnly:             v.GetBool("text-only"),
+		MarkdownTranscript:   v.GetBool("markdown-transcript"),
+		OCRSidecar:           v.GetString("ocr-sidecar"),
+		OCRMinConfidence:     v.GetFloat64("ocr-min-confidence"),
+		OCRGrayscale:         v.GetBool("ocr-grayscale"),
+		OCRMaxImageDimension: v.GetInt("ocr-max-image-dimension"),
+		OCRContrast:          v.GetFloat64("ocr-contrast"),
+		OCRPromptFile:        v.GetString("ocr-prompt-file"),
+
+		OCRStructuredImageFormat:  v.GetString("ocr-structured-image-format"),
+		OCRStructuredImageQuality: v.GetInt("ocr-structured-image-quality"),
+		OCRSimpleImageFormat:      v.GetString("ocr-simple-image-format"),
+		OCRSimpleImageQuality:     v.GetInt("ocr-simple-image-quality"),
+		OCRCache:                  v.GetBool("ocr-cache"),
+		OCRCacheDir:               v.GetString("ocr-cache-dir"),
+		SplitByPageTag:            v.GetBool("split-by-page-tag"),
+		BookmarksFromTags:         v.GetBool("bookmarks-from-tags"),
+		EncryptPassword:           v.GetString("encrypt-password"),
+		ChunkPages:                v.GetInt("chunk-pages"),
+		DuplicateNames:            v.GetString("duplicate-names"),
+		ExistingOutput:            v.GetString("existing-output"),
+		UnparseableDocuments:      v.GetString("unparseable-documents"),
+		ProcessOrder:              v.GetString("process-order"),
+		DownloadDir:               v.GetString("download-dir"),
+		MaxRetries:                v.GetInt("max-retries"),
+		RetryBackoff:              v.GetDuration("retry-backoff"),
+		Concurrency:               v.GetInt("concurrency"),
+		DownloadConcurrency:       v.GetInt("download-concurrency"),
+		RenderConcurrency:         v.GetInt("render-concurrency"),
+		RenderCacheDir:            v.GetString("render-cache-dir"),
+		PipelineDepth:             v.GetInt("pipeline-depth"),
+		SyncInterval:              v.GetDuration("sync-interval"),
+		SyncSchedule:              v.GetString("sync-schedule"),
+		ChangePollInterval:        v.GetDuration("change-poll-interval"),
+		StateFile:                 v.GetString("state-file"),
+		StateBackend:              v.GetString("state-backend"),
+		StateLockTimeout:          v.GetDuration("state-lock-timeout"),
+		LogLevel:                  v.GetString("log-level"),
+		LogLevels:                 v.GetStringMapString("log-levels"),
+		LogFile:                   v.GetString("log-file"),
+		RemarkableToken:           v.GetString("api-token"),
+		HostRewrites:              v.GetStringMapString("host-rewrites"),
+		APIAuthURL:                v.GetString("api-auth-url"),
+		APIStorageURL:             v.GetString("api-storage-url"),
+		APISyncURL:                v.GetString("api-sync-url"),
+		DaemonMode:                v.GetBool("daemon-mode"),
 		LLM: LLMConfig{
 			Provider:              v.GetString("llm.provider"),
 			Model:                 v.GetString("llm.model"),
@@ -151,6 +476,10 @@ func Load(configFile string) (*Config, error) {
 	// Load API keys from keychain or environment variables based on provider
 	config.LLM.APIKey = loadAPIKeyForProvider(config.LLM.Provider, config.LLM.UseKeychain, config.LLM.KeychainServicePrefix)
 
+	if err := v.UnmarshalKey("output-formats", &config.OutputFormats); err != nil {
+		return nil, fmt.Errorf("invalid output-formats: %w", err)
+	}
+
 	// Validate configuration
 	if err := config.Validate(); err != nil {
 		return nil, fmt.Errorf("invalid configuration: %w", err)
@@ -169,15 +498,59 @@ func setDefaults(v *viper.Viper) {
 
 	defaultOutputDir := filepath.Join(home, "legible")
 	defaultStateFile := filepath.Join(home, ".legible-state.json")
+	defaultOCRCacheDir := filepath.Join(home, ".legible-ocr-cache")
 
 	v.SetDefault("output-dir", defaultOutputDir)
 	v.SetDefault("labels", []string{})
+	v.SetDefault("label-match", LabelMatchAny)
+	v.SetDefault("folder", "")
 	v.SetDefault("ocr-enabled", true)
 	v.SetDefault("ocr-languages", "eng")
+	v.SetDefault("ocr-engine", OCREngineOllama)
+	v.SetDefault("text-only", false)
+	v.SetDefault("markdown-transcript", false)
+	v.SetDefault("split-by-page-tag", false)
+	v.SetDefault("bookmarks-from-tags", false)
+	v.SetDefault("encrypt-password", "")
+	v.SetDefault("chunk-pages", 0)
+	v.SetDefault("ocr-cache", true)
+	v.SetDefault("ocr-cache-dir", defaultOCRCacheDir)
+	v.SetDefault("ocr-sidecar", "")
+	v.SetDefault("ocr-min-confidence", 0.0)
+	v.SetDefault("ocr-grayscale", false)
+	v.SetDefault("ocr-max-image-dimension", 0)
+	v.SetDefault("ocr-contrast", 1.0)
+	v.SetDefault("ocr-prompt-file", "")
+	v.SetDefault("ocr-structured-image-format", OCRImagePNG)
+	v.SetDefault("ocr-structured-image-quality", defaultOCRImageQuality)
+	v.SetDefault("ocr-simple-image-format", OCRImagePNG)
+	v.SetDefault("ocr-simple-image-quality", defaultOCRImageQuality)
+	v.SetDefault("duplicate-names", DuplicateNamesSuffix)
+	v.SetDefault("existing-output", ExistingOutputOverwrite)
+	v.SetDefault("unparseable-documents", UnparseableSkip)
+	v.SetDefault("process-order", ProcessOrderFiletree)
+	v.SetDefault("download-dir", "")
+	v.SetDefault("max-retries", 3)
+	v.SetDefault("retry-backoff", 2*time.Second)
+	v.SetDefault("concurrency", 1)
+	v.SetDefault("download-concurrency", 0)
+	v.SetDefault("render-concurrency", 1)
+	v.SetDefault("render-cache-dir", "")
+	v.SetDefault("pipeline-depth", 0)            // 0 = not pipelined
 	v.SetDefault("sync-interval", 0*time.Second) // 0 = run once
+	v.SetDefault("sync-schedule", "")
+	v.SetDefault("change-poll-interval", 0*time.Second) // 0 = only scheduled syncs
 	v.SetDefault("state-file", defaultStateFile)
+	v.SetDefault("state-backend", StateBackendJSON)
+	v.SetDefault("state-lock-timeout", time.Minute)
 	v.SetDefault("log-level", "info")
+	v.SetDefault("log-levels", map[string]string{})
+	v.SetDefault("log-file", "")
 	v.SetDefault("api-token", "")
+	v.SetDefault("host-rewrites", map[string]string{})
+	v.SetDefault("api-auth-url", "")
+	v.SetDefault("api-storage-url", "")
+	v.SetDefault("api-sync-url", "")
 	v.SetDefault("daemon-mode", false)
 
 	// LLM defaults (Ollama by default for backward compatibility)
@@ -192,18 +565,30 @@ func setDefaults(v *viper.Viper) {
 
 // Validate checks that the configuration is valid and internally consistent
 func (c *Config) Validate() error {
-	// Validate output directory
+	for _, validate := range []func() error{
+		c.validatePaths,
+		c.validateLogging,
+		c.validateFilters,
+		c.validateOutput,
+		c.validateProcessing,
+		c.validateOCR,
+		c.validateOCRPages,
+		c.validateDaemon,
+	} {
+		if err := validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validatePaths expands the configured paths and creates the output and state directories
+func (c *Config) validatePaths() error {
 	if c.OutputDir == "" {
 		return fmt.Errorf("output-dir cannot be empty")
 	}
-
-	// Expand home directory if present
-	if strings.HasPrefix(c.OutputDir, "~/") {
-		home, err := os.UserHomeDir()
-		if err != nil {
-			return fmt.Errorf("failed to expand home directory in output-dir: %w", err)
-		}
-		c.OutputDir = filepath.Join(home, c.OutputDir[2:])
+	if err := expandHome("output-dir", &c.OutputDir); err != nil {
+		return err
 	}
 
 	// Create output directory if it doesn't exist
@@ -211,18 +596,18 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("failed to create output directory %s: %w", c.OutputDir, err)
 	}
 
-	// Validate state file path
 	if c.StateFile == "" {
 		return fmt.Errorf("state-file cannot be empty")
 	}
-
-	// Expand home directory in state file path
-	if strings.HasPrefix(c.StateFile, "~/") {
-		home, err := os.UserHomeDir()
-		if err != nil {
-			return fmt.Errorf("failed to expand home directory in state-file: %w", err)
-		}
-		c.StateFile = filepath.Join(home, c.StateFile[2:])
+	if err := expandHome("state-file", &c.StateFile); err != nil {
+		return err
+	}
+	if err := normalizeChoice("state-backend", &c.StateBackend, StateBackendJSON,
+		StateBackendJSON, StateBackendSQLite); err != nil {
+		return err
+	}
+	if c.StateLockTimeout < 0 {
+		return fmt.Errorf("state-lock-timeout must be non-negative, got %s", c.StateLockTimeout)
 	}
 
 	// Create state file directory if it doesn't exist
@@ -231,7 +616,26 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("failed to create state file directory %s: %w", stateDir, err)
 	}
 
-	// Validate log level
+	// The remaining paths are created or read on first use
+	for _, path := range []struct {
+		key   string
+		value *string
+	}{
+		{"download-dir", &c.DownloadDir},
+		{"ocr-cache-dir", &c.OCRCacheDir},
+		{"ocr-prompt-file", &c.OCRPromptFile},
+		{"render-cache-dir", &c.RenderCacheDir},
+		{"log-file", &c.LogFile},
+	} {
+		if err := expandHome(path.key, path.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateLogging normalizes the global and per-component log levels
+func (c *Config) validateLogging() error {
 	validLogLevels := map[string]bool{
 		"debug": true,
 		"info":  true,
@@ -243,25 +647,211 @@ func (c *Config) Validate() error {
 	}
 	c.LogLevel = strings.ToLower(c.LogLevel)
 
-	// Validate OCR settings
-	if c.OCREnabled {
-		if c.OCRLanguages == "" {
-			return fmt.Errorf("ocr-languages cannot be empty when OCR is enabled")
+	for component, level := range c.LogLevels {
+		if !validLogLevels[strings.ToLower(level)] {
+			return fmt.Errorf("invalid log-levels.%s %q, must be one of: debug, info, warn, error", component, level)
+		}
+		c.LogLevels[component] = strings.ToLower(level)
+	}
+	return nil
+}
+
+// validateFilters normalizes the label and folder filters selecting documents to sync
+func (c *Config) validateFilters() error {
+	if err := normalizeChoice("label-match", &c.LabelMatch, LabelMatchAny,
+		LabelMatchAny, LabelMatchAll); err != nil {
+		return err
+	}
+
+	// Leading and trailing slashes are optional
+	c.Folder = strings.Trim(strings.TrimSpace(c.Folder), "/")
+	return nil
+}
+
+// validateOutput checks the output format rules and the policies for naming and
+// replacing output files
+func (c *Config) validateOutput() error {
+	for i := range c.OutputFormats {
+		rule := &c.OutputFormats[i]
+		rule.Label = strings.TrimSpace(rule.Label)
+		rule.Folder = strings.Trim(strings.TrimSpace(rule.Folder), "/")
+		if (rule.Label == "") == (rule.Folder == "") {
+			return fmt.Errorf("output-formats rule %d must set exactly one of label or folder", i+1)
 		}
+		switch format := strings.ToLower(strings.TrimSpace(rule.Format)); format {
+		case OutputFormatPDF, OutputFormatPNG:
+			rule.Format = format
+		default:
+			return fmt.Errorf("invalid output-formats rule %d format %q, must be one of: %s, %s",
+				i+1, rule.Format, OutputFormatPDF, OutputFormatPNG)
+		}
+	}
+
+	if err := normalizeChoice("duplicate-names", &c.DuplicateNames, DuplicateNamesSuffix,
+		DuplicateNamesSuffix, DuplicateNamesOverwrite); err != nil {
+		return err
 	}
+	if err := normalizeChoice("existing-output", &c.ExistingOutput, ExistingOutputOverwrite,
+		ExistingOutputOverwrite, ExistingOutputSkipNewer); err != nil {
+		return err
+	}
+	return normalizeChoice("unparseable-documents", &c.UnparseableDocuments, UnparseableSkip,
+		UnparseableSkip, UnparseablePlaceholder, UnparseableFail)
+}
 
-	// Validate sync interval for daemon mode
-	if c.DaemonMode && c.SyncInterval <= 0 {
-		return fmt.Errorf("sync-interval must be positive when daemon-mode is enabled")
+// validateProcessing checks the document order, retry, and concurrency settings
+func (c *Config) validateProcessing() error {
+	if err := normalizeChoice("process-order", &c.ProcessOrder, ProcessOrderFiletree,
+		ProcessOrderFiletree, ProcessOrderModified, ProcessOrderName, ProcessOrderSize); err != nil {
+		return err
+	}
+
+	if c.MaxRetries < 0 {
+		return fmt.Errorf("max-retries must be non-negative, got %d", c.MaxRetries)
+	}
+	if c.RetryBackoff < 0 {
+		return fmt.Errorf("retry-backoff must be non-negative, got %s", c.RetryBackoff)
+	}
+
+	for _, setting := range []struct {
+		key   string
+		value int
+	}{
+		{"concurrency", c.Concurrency},
+		{"download-concurrency", c.DownloadConcurrency},
+		{"chunk-pages", c.ChunkPages},
+		{"render-concurrency", c.RenderConcurrency},
+		{"pipeline-depth", c.PipelineDepth},
+	} {
+		if setting.value < 0 {
+			return fmt.Errorf("%s must be non-negative, got %d", setting.key, setting.value)
+		}
+	}
+	if c.Concurrency == 0 {
+		c.Concurrency = 1
+	}
+	if c.RenderConcurrency == 0 {
+		c.RenderConcurrency = 1
+	}
+	return nil
+}
+
+// validateOCR checks the OCR settings, and the LLM provider when OCR is enabled
+func (c *Config) validateOCR() error {
+	if c.OCREnabled && c.OCRLanguages == "" {
+		return fmt.Errorf("ocr-languages cannot be empty when OCR is enabled")
+	}
+	if err := normalizeChoice("ocr-engine", &c.OCREngine, OCREngineOllama,
+		OCREngineOllama, OCREngineTesseract, OCREngineAuto); err != nil {
+		return err
+	}
+	if c.TextOnly && !c.OCREnabled {
+		return fmt.Errorf("text-only requires ocr-enabled")
+	}
+	if c.MarkdownTranscript && !c.OCREnabled {
+		return fmt.Errorf("markdown-transcript requires ocr-enabled")
+	}
+	if err := normalizeChoice("ocr-sidecar", &c.OCRSidecar, "",
+		OCRSidecarJSON, OCRSidecarText, OCRSidecarHOCR); err != nil {
+		return err
+	}
+	if c.OCRSidecar != "" && !c.OCREnabled {
+		return fmt.Errorf("ocr-sidecar requires ocr-enabled")
 	}
 
-	// Validate LLM configuration
 	if c.OCREnabled {
 		if err := c.validateLLMConfig(); err != nil {
 			return fmt.Errorf("invalid LLM configuration: %w", err)
 		}
 	}
+	return nil
+}
 
+// validateOCRPages checks how page images are prepared for OCR and which recognized
+// words are kept
+func (c *Config) validateOCRPages() error {
+	if c.OCRMinConfidence < 0 || c.OCRMinConfidence > 100 {
+		return fmt.Errorf("ocr-min-confidence must be between 0 and 100")
+	}
+	if c.OCRMaxImageDimension < 0 {
+		return fmt.Errorf("ocr-max-image-dimension must not be negative")
+	}
+	if c.OCRContrast < 0 {
+		return fmt.Errorf("ocr-contrast must not be negative")
+	}
+	if err := validateOCRImage("ocr-structured-image", &c.OCRStructuredImageFormat, &c.OCRStructuredImageQuality); err != nil {
+		return err
+	}
+	return validateOCRImage("ocr-simple-image", &c.OCRSimpleImageFormat, &c.OCRSimpleImageQuality)
+}
+
+// validateDaemon checks when syncs run: the schedule, interval, and change polling
+func (c *Config) validateDaemon() error {
+	// The sync schedule replaces the interval in daemon mode
+	if c.SyncSchedule != "" {
+		if _, err := cron.ParseStandard(c.SyncSchedule); err != nil {
+			return fmt.Errorf("invalid sync-schedule %q: %w", c.SyncSchedule, err)
+		}
+	}
+	if c.ChangePollInterval < 0 {
+		return fmt.Errorf("change-poll-interval must not be negative")
+	}
+	if c.DaemonMode && c.SyncInterval <= 0 && c.SyncSchedule == "" {
+		return fmt.Errorf("sync-interval must be positive when daemon-mode is enabled without a sync-schedule")
+	}
+	return nil
+}
+
+// expandHome replaces a leading "~/" in the path of option key with the home directory
+func expandHome(key string, path *string) error {
+	if !strings.HasPrefix(*path, "~/") {
+		return nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("failed to expand home directory in %s: %w", key, err)
+	}
+	*path = filepath.Join(home, (*path)[2:])
+	return nil
+}
+
+// normalizeChoice lowercases the value of option key, which must be one of choices,
+// and sets it to def when empty
+func normalizeChoice(key string, value *string, def string, choices ...string) error {
+	normalized := strings.ToLower(strings.TrimSpace(*value))
+	if normalized == "" {
+		*value = def
+		return nil
+	}
+	for _, choice := range choices {
+		if normalized == choice {
+			*value = normalized
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid %s %q, must be one of: %s", key, *value, strings.Join(choices, ", "))
+}
+
+// validateOCRImage normalizes the format and JPEG quality of the page images sent for
+// one mode of OCR, the options named key+"-format" and key+"-quality"
+func validateOCRImage(key string, format *string, quality *int) error {
+	switch f := strings.ToLower(strings.TrimSpace(*format)); f {
+	case "":
+		*format = OCRImagePNG
+	case OCRImagePNG, OCRImageJPEG:
+		*format = f
+	case "jpg":
+		*format = OCRImageJPEG
+	default:
+		return fmt.Errorf("invalid %s-format %q, must be one of: %s, %s", key, *format, OCRImagePNG, OCRImageJPEG)
+	}
+
+	if *quality == 0 {
+		*quality = defaultOCRImageQuality
+	}
+	if *quality < 1 || *quality > 100 {
+		return fmt.Errorf("%s-quality must be between 1 and 100, got %d", key, *quality)
+	}
 	return nil
 }
 
@@ -380,15 +970,62 @@ func (c *Config) String() string {
 		}
 	}
 
+	encryptPassword := "not set"
+	if c.EncryptPassword != "" {
+		encryptPassword = "***"
+	}
+
 	return fmt.Sprintf(`Configuration:
   OutputDir: %s
   Labels: %v
+  LabelMatch: %s
+  Folder: %s
   OCREnabled: %t
   OCRLanguages: %s
+  OCREngine: %s
+  TextOnly: %t
+  MarkdownTranscript: %t
+  OCRSidecar: %s
+  OCRMinConfidence: %.1f
+  OCRGrayscale: %t
+  OCRMaxImageDimension: %d
+  OCRContrast: %.2f
+  OCRPromptFile: %s
+  OCRStructuredImage: %s (quality %d)
+  OCRSimpleImage: %s (quality %d)
+  OCRCache: %t
+  OCRCacheDir: %s
+  SplitByPageTag: %t
+  BookmarksFromTags: %t
+  EncryptPassword: %s
+  ChunkPages: %d
+  OutputFormats: %v
+  DuplicateNames: %s
+  ExistingOutput: %s
+  UnparseableDocuments: %s
+  ProcessOrder: %s
+  DownloadDir: %s
+  MaxRetries: %d
+  RetryBackoff: %s
+  Concurrency: %d
+  DownloadConcurrency: %d
+  RenderConcurrency: %d
+  RenderCacheDir: %s
+  PipelineDepth: %d
   SyncInterval: %s
+  SyncSchedule: %s
+  ChangePollInterval: %s
   StateFile: %s
+  StateBackend: %s
+  StateLockTimeout: %s
   LogLevel: %s
+  LogLevels: %v
+  LogFile: %s
   RemarkableToken: %s
+  HostRewrites: %v
+  APIAuthURL: %s
+  APIStorageURL: %s
+  APISyncURL: %s
   DaemonMode: %t
   LLM:
     Provider: %s
@@ -401,12 +1038,56 @@ func (c *Config) String() string {
     KeychainServicePrefix: %s`,
 		c.OutputDir,
 		c.Labels,
+		c.LabelMatch,
+		c.Folder,
 		c.OCREnabled,
 		c.OCRLanguages,
+		c.OCREngine,
+		c.TextOnly,
+		c.MarkdownTranscript,
+		c.OCRSidecar,
+		c.OCRMinConfidence,
+		c.OCRGrayscale,
+		c.OCRMaxImageDimension,
+		c.OCRContrast,
+		c.OCRPromptFile,
+		c.OCRStructuredImageFormat,
+		c.OCRStructuredImageQuality,
+		c.OCRSimpleImageFormat,
+		c.OCRSimpleImageQuality,
+		c.OCRCache,
+		c.OCRCacheDir,
+		c.SplitByPageTag,
+		c.BookmarksFromTags,
+		encryptPassword,
+		c.ChunkPages,
+		c.OutputFormats,
+		c.DuplicateNames,
+		c.ExistingOutput,
+		c.UnparseableDocuments,
+		c.ProcessOrder,
+		c.DownloadDir,
+		c.MaxRetries,
+		c.RetryBackoff,
+		c.Concurrency,
+		c.DownloadConcurrency,
+		c.RenderConcurrency,
+		c.RenderCacheDir,
+		c.PipelineDepth,
 		c.SyncInterval,
+		c.SyncSchedule,
+		c.ChangePollInterval,
 		c.StateFile,
+		c.StateBackend,
+		c.StateLockTimeout,
 		c.LogLevel,
+		c.LogLevels,
+		c.LogFile,
 		token,
+		c.HostRewrites,
+		c.APIAuthURL,
+		c.APIStorageURL,
+		c.APISyncURL,
 		c.DaemonMode,
 		c.LLM.Provider,
 		c.LLM.Model,
@@ -3,11 +3,13 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
 )
 
+//nolint:gocyclo // Test function with multiple validation steps
 func TestLoad_Defaults(t *testing.T) {
 	// Create a temporary directory for test output
 	tmpDir := t.TempDir()
@@ -42,6 +44,111 @@ func TestLoad_Defaults(t *testing.T) {
 	if cfg.SyncInterval != 0 {
 		t.Errorf("expected SyncInterval = 0, got %s", cfg.SyncInterval)
 	}
+
+	if cfg.SyncSchedule != "" {
+		t.Errorf("expected SyncSchedule to be empty, got %q", cfg.SyncSchedule)
+	}
+
+	if cfg.ChangePollInterval != 0 {
+		t.Errorf("expected ChangePollInterval = 0, got %s", cfg.ChangePollInterval)
+	}
+
+	if cfg.MaxRetries != 3 || cfg.RetryBackoff != 2*time.Second {
+		t.Errorf("expected MaxRetries = 3 and RetryBackoff = 2s, got %d and %s", cfg.MaxRetries, cfg.RetryBackoff)
+	}
+
+	if cfg.Concurrency != 1 {
+		t.Errorf("expected Concurrency = 1, got %d", cfg.Concurrency)
+	}
+	if cfg.DownloadConcurrency != 0 {
+		t.Errorf("expected DownloadConcurrency = 0, got %d", cfg.DownloadConcurrency)
+	}
+	if cfg.RenderConcurrency != 1 {
+		t.Errorf("expected RenderConcurrency = 1, got %d", cfg.RenderConcurrency)
+	}
+	if cfg.RenderCacheDir != "" {
+		t.Errorf("expected the render cache to be disabled, got RenderCacheDir = %s", cfg.RenderCacheDir)
+	}
+	if cfg.PipelineDepth != 0 {
+		t.Errorf("expected PipelineDepth = 0, got %d", cfg.PipelineDepth)
+	}
+
+	if cfg.ExistingOutput != ExistingOutputOverwrite {
+		t.Errorf("expected ExistingOutput = %s, got %s", ExistingOutputOverwrite, cfg.ExistingOutput)
+	}
+
+	if cfg.UnparseableDocuments != UnparseableSkip {
+		t.Errorf("expected UnparseableDocuments = %s, got %s", UnparseableSkip, cfg.UnparseableDocuments)
+	}
+
+	if cfg.APIAuthURL != "" || cfg.APIStorageURL != "" || cfg.APISyncURL != "" {
+		t.Errorf("expected the default API base URLs, got %q, %q, %q", cfg.APIAuthURL, cfg.APIStorageURL, cfg.APISyncURL)
+	}
+
+	if cfg.ProcessOrder != ProcessOrderFiletree {
+		t.Errorf("expected ProcessOrder = %s, got %s", ProcessOrderFiletree, cfg.ProcessOrder)
+	}
+
+	if cfg.TextOnly {
+		t.Error("expected TextOnly = false")
+	}
+
+	if cfg.MarkdownTranscript {
+		t.Error("expected MarkdownTranscript = false")
+	}
+
+	if cfg.SplitByPageTag {
+		t.Error("expected SplitByPageTag = false")
+	}
+
+	if cfg.BookmarksFromTags {
+		t.Error("expected BookmarksFromTags = false")
+	}
+	if cfg.EncryptPassword != "" {
+		t.Error("expected PDFs to be unencrypted by default")
+	}
+	if cfg.ChunkPages != 0 {
+		t.Errorf("expected ChunkPages = 0, got %d", cfg.ChunkPages)
+	}
+
+	if !cfg.OCRCache || cfg.OCRCacheDir != filepath.Join(tmpDir, ".legible-ocr-cache") {
+		t.Errorf("expected the OCR cache in %s, got OCRCache = %t, OCRCacheDir = %s",
+			filepath.Join(tmpDir, ".legible-ocr-cache"), cfg.OCRCache, cfg.OCRCacheDir)
+	}
+
+	if cfg.OCREngine != OCREngineOllama {
+		t.Errorf("expected OCREngine = %s, got %s", OCREngineOllama, cfg.OCREngine)
+	}
+
+	if cfg.OCRMinConfidence != 0 {
+		t.Errorf("expected OCRMinConfidence = 0, got %v", cfg.OCRMinConfidence)
+	}
+
+	if cfg.OCRSidecar != "" {
+		t.Errorf("expected OCRSidecar = \"\", got %s", cfg.OCRSidecar)
+	}
+
+	if cfg.OCRStructuredImageFormat != OCRImagePNG || cfg.OCRStructuredImageQuality != 90 ||
+		cfg.OCRSimpleImageFormat != OCRImagePNG || cfg.OCRSimpleImageQuality != 90 {
+		t.Errorf("expected PNG images for structured and simple OCR, got %s (%d), %s (%d)",
+			cfg.OCRStructuredImageFormat, cfg.OCRStructuredImageQuality, cfg.OCRSimpleImageFormat, cfg.OCRSimpleImageQuality)
+	}
+
+	if cfg.OCRPromptFile != "" {
+		t.Errorf("expected OCRPromptFile = \"\", got %s", cfg.OCRPromptFile)
+	}
+
+	if cfg.OCRGrayscale || cfg.OCRMaxImageDimension != 0 || cfg.OCRContrast != 1 {
+		t.Errorf("expected no OCR image preprocessing, got grayscale %t, max dimension %d, contrast %v",
+			cfg.OCRGrayscale, cfg.OCRMaxImageDimension, cfg.OCRContrast)
+	}
+
+	if cfg.StateBackend != StateBackendJSON {
+		t.Errorf("expected StateBackend = %s, got %s", StateBackendJSON, cfg.StateBackend)
+	}
+	if cfg.StateLockTimeout != time.Minute {
+		t.Errorf("expected StateLockTimeout = 1m, got %s", cfg.StateLockTimeout)
+	}
 }
 
 func TestLoad_EnvironmentVariables(t *testing.T) {
@@ -124,6 +231,64 @@ state-file: ` + filepath.Join(tmpDir, "state.json") + `
 	}
 }
 
+func TestLoad_OutputFormats(t *testing.T) {
+	tmpDir := t.TempDir()
+	configFile := filepath.Join(tmpDir, "test-config.yaml")
+
+	configContent := `
+output-dir: ` + tmpDir + `
+ocr-enabled: false
+state-file: ` + filepath.Join(tmpDir, "state.json") + `
+output-formats:
+  - label: sketch
+    format: PNG
+  - folder: /Journals/
+    format: pdf
+`
+	if err := os.WriteFile(configFile, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	cfg, err := Load(configFile)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	want := []OutputFormatRule{
+		{Label: "sketch", Format: OutputFormatPNG},
+		{Folder: "Journals", Format: OutputFormatPDF},
+	}
+	if !reflect.DeepEqual(cfg.OutputFormats, want) {
+		t.Errorf("OutputFormats = %+v, want %+v", cfg.OutputFormats, want)
+	}
+}
+
+func TestValidate_OutputFormats(t *testing.T) {
+	tests := []struct {
+		name string
+		rule OutputFormatRule
+	}{
+		{name: "no selector", rule: OutputFormatRule{Format: "png"}},
+		{name: "both selectors", rule: OutputFormatRule{Label: "a", Folder: "b", Format: "png"}},
+		{name: "unknown format", rule: OutputFormatRule{Label: "a", Format: "gif"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			cfg := &Config{
+				OutputDir:     tmpDir,
+				StateFile:     filepath.Join(tmpDir, "state.json"),
+				LogLevel:      "info",
+				OutputFormats: []OutputFormatRule{tt.rule},
+			}
+			if err := cfg.Validate(); err == nil {
+				t.Errorf("Validate() should reject %+v", tt.rule)
+			}
+		})
+	}
+}
+
 func TestValidate_InvalidLogLevel(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -145,6 +310,564 @@ func TestValidate_InvalidLogLevel(t *testing.T) {
 	}
 }
 
+func TestValidate_ComponentLogLevels(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	cfg := &Config{
+		OutputDir:    tmpDir,
+		StateFile:    filepath.Join(tmpDir, "state.json"),
+		LogLevel:     "info",
+		LogLevels:    map[string]string{"rmclient": "DEBUG"},
+		OCRLanguages: "eng",
+	}
+
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if cfg.LogLevels["rmclient"] != "debug" {
+		t.Errorf("LogLevels[rmclient] = %q, want debug", cfg.LogLevels["rmclient"])
+	}
+
+	cfg.LogLevels["ocr"] = "loud"
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "log-levels.ocr") {
+		t.Errorf("expected error about log-levels.ocr, got: %v", err)
+	}
+}
+
+func TestValidate_DuplicateNames(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{value: "", want: DuplicateNamesSuffix},
+		{value: "suffix", want: DuplicateNamesSuffix},
+		{value: "Overwrite", want: DuplicateNamesOverwrite},
+		{value: "rename", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			cfg := &Config{
+				OutputDir:      tmpDir,
+				StateFile:      filepath.Join(tmpDir, "state.json"),
+				LogLevel:       "info",
+				DuplicateNames: tt.value,
+			}
+
+			err := cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && cfg.DuplicateNames != tt.want {
+				t.Errorf("DuplicateNames = %q, want %q", cfg.DuplicateNames, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate_ProcessOrder(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{value: "", want: ProcessOrderFiletree},
+		{value: "modified", want: ProcessOrderModified},
+		{value: "Name", want: ProcessOrderName},
+		{value: "size", want: ProcessOrderSize},
+		{value: "random", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			cfg := &Config{
+				OutputDir:    tmpDir,
+				StateFile:    filepath.Join(tmpDir, "state.json"),
+				LogLevel:     "info",
+				ProcessOrder: tt.value,
+			}
+
+			err := cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && cfg.ProcessOrder != tt.want {
+				t.Errorf("ProcessOrder = %q, want %q", cfg.ProcessOrder, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate_ExistingOutput(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{value: "", want: ExistingOutputOverwrite},
+		{value: "overwrite", want: ExistingOutputOverwrite},
+		{value: "Skip-Newer", want: ExistingOutputSkipNewer},
+		{value: "skip", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			cfg := &Config{
+				OutputDir:      tmpDir,
+				StateFile:      filepath.Join(tmpDir, "state.json"),
+				LogLevel:       "info",
+				ExistingOutput: tt.value,
+			}
+
+			err := cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && cfg.ExistingOutput != tt.want {
+				t.Errorf("ExistingOutput = %q, want %q", cfg.ExistingOutput, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate_UnparseableDocuments(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{value: "", want: UnparseableSkip},
+		{value: "skip", want: UnparseableSkip},
+		{value: "Placeholder", want: UnparseablePlaceholder},
+		{value: "fail", want: UnparseableFail},
+		{value: "ignore", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			cfg := &Config{
+				OutputDir:            tmpDir,
+				StateFile:            filepath.Join(tmpDir, "state.json"),
+				LogLevel:             "info",
+				UnparseableDocuments: tt.value,
+			}
+
+			err := cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && cfg.UnparseableDocuments != tt.want {
+				t.Errorf("UnparseableDocuments = %q, want %q", cfg.UnparseableDocuments, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate_DownloadConcurrency(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := &Config{
+		OutputDir:           tmpDir,
+		StateFile:           filepath.Join(tmpDir, "state.json"),
+		LogLevel:            "info",
+		DownloadConcurrency: -1,
+	}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() should reject a negative download-concurrency")
+	}
+
+	cfg.DownloadConcurrency = 2
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
+
+func TestValidate_ChunkPages(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := &Config{
+		OutputDir:  tmpDir,
+		StateFile:  filepath.Join(tmpDir, "state.json"),
+		LogLevel:   "info",
+		ChunkPages: -1,
+	}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() should reject a negative chunk-pages")
+	}
+
+	cfg.ChunkPages = 100
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
+
+func TestValidate_RenderConcurrency(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := &Config{
+		OutputDir:         tmpDir,
+		StateFile:         filepath.Join(tmpDir, "state.json"),
+		LogLevel:          "info",
+		RenderConcurrency: -1,
+	}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() should reject a negative render-concurrency")
+	}
+
+	cfg.RenderConcurrency = 0
+	if err := cfg.Validate(); err != nil || cfg.RenderConcurrency != 1 {
+		t.Errorf("Validate() error = %v, RenderConcurrency = %d, want 0 to render pages in turn", err, cfg.RenderConcurrency)
+	}
+}
+
+func TestValidate_PipelineDepth(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := &Config{
+		OutputDir:     tmpDir,
+		StateFile:     filepath.Join(tmpDir, "state.json"),
+		LogLevel:      "info",
+		PipelineDepth: -1,
+	}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() should reject a negative pipeline-depth")
+	}
+
+	cfg.PipelineDepth = 2
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
+
+func TestValidate_StateLockTimeout(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := &Config{
+		OutputDir:        tmpDir,
+		StateFile:        filepath.Join(tmpDir, "state.json"),
+		LogLevel:         "info",
+		StateLockTimeout: -time.Second,
+	}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() should reject a negative state-lock-timeout")
+	}
+
+	cfg.StateLockTimeout = 0
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
+
+func TestValidate_StateBackend(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{value: "", want: StateBackendJSON},
+		{value: "json", want: StateBackendJSON},
+		{value: "SQLite", want: StateBackendSQLite},
+		{value: "postgres", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			cfg := &Config{
+				OutputDir:    tmpDir,
+				StateFile:    filepath.Join(tmpDir, "state.db"),
+				LogLevel:     "info",
+				StateBackend: tt.value,
+			}
+
+			err := cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && cfg.StateBackend != tt.want {
+				t.Errorf("StateBackend = %q, want %q", cfg.StateBackend, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate_TextOnly(t *testing.T) {
+	tests := []struct {
+		name       string
+		ocrEnabled bool
+		wantErr    bool
+	}{
+		{name: "with OCR", ocrEnabled: true},
+		{name: "without OCR", ocrEnabled: false, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			cfg := &Config{
+				OutputDir:    tmpDir,
+				StateFile:    filepath.Join(tmpDir, "state.json"),
+				LogLevel:     "info",
+				OCREnabled:   tt.ocrEnabled,
+				OCRLanguages: "eng",
+				TextOnly:     true,
+				LLM:          LLMConfig{Provider: "ollama", Model: "llava", Endpoint: "http://localhost:11434"},
+			}
+
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidate_MarkdownTranscript(t *testing.T) {
+	tests := []struct {
+		name       string
+		ocrEnabled bool
+		wantErr    bool
+	}{
+		{name: "with OCR", ocrEnabled: true},
+		{name: "without OCR", ocrEnabled: false, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			cfg := &Config{
+				OutputDir:          tmpDir,
+				StateFile:          filepath.Join(tmpDir, "state.json"),
+				LogLevel:           "info",
+				OCREnabled:         tt.ocrEnabled,
+				OCRLanguages:       "eng",
+				MarkdownTranscript: true,
+				LLM:                LLMConfig{Provider: "ollama", Model: "llava", Endpoint: "http://localhost:11434"},
+			}
+
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidate_OCRSidecar(t *testing.T) {
+	tests := []struct {
+		name       string
+		sidecar    string
+		ocrEnabled bool
+		want       string
+		wantErr    bool
+	}{
+		{name: "none", ocrEnabled: false},
+		{name: "json", sidecar: "json", ocrEnabled: true, want: OCRSidecarJSON},
+		{name: "normalized", sidecar: " hOCR ", ocrEnabled: true, want: OCRSidecarHOCR},
+		{name: "without OCR", sidecar: "text", ocrEnabled: false, wantErr: true},
+		{name: "unknown format", sidecar: "alto", ocrEnabled: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			cfg := &Config{
+				OutputDir:    tmpDir,
+				StateFile:    filepath.Join(tmpDir, "state.json"),
+				LogLevel:     "info",
+				OCREnabled:   tt.ocrEnabled,
+				OCRLanguages: "eng",
+				OCRSidecar:   tt.sidecar,
+				LLM:          LLMConfig{Provider: "ollama", Model: "llava", Endpoint: "http://localhost:11434"},
+			}
+
+			err := cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && cfg.OCRSidecar != tt.want {
+				t.Errorf("OCRSidecar = %q, want %q", cfg.OCRSidecar, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate_OCREngine(t *testing.T) {
+	tests := []struct {
+		name    string
+		engine  string
+		want    string
+		wantErr bool
+	}{
+		{name: "empty defaults to ollama", engine: "", want: OCREngineOllama},
+		{name: "tesseract", engine: "tesseract", want: OCREngineTesseract},
+		{name: "normalized", engine: " Auto ", want: OCREngineAuto},
+		{name: "unknown", engine: "easyocr", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			cfg := &Config{
+				OutputDir:    tmpDir,
+				StateFile:    filepath.Join(tmpDir, "state.json"),
+				LogLevel:     "info",
+				OCREnabled:   true,
+				OCRLanguages: "eng",
+				OCREngine:    tt.engine,
+				LLM:          LLMConfig{Provider: "ollama", Model: "llava", Endpoint: "http://localhost:11434"},
+			}
+
+			err := cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && cfg.OCREngine != tt.want {
+				t.Errorf("OCREngine = %q, want %q", cfg.OCREngine, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate_OCRMinConfidence(t *testing.T) {
+	tests := []struct {
+		name          string
+		minConfidence float64
+		wantErr       bool
+	}{
+		{name: "keep everything", minConfidence: 0},
+		{name: "threshold", minConfidence: 60},
+		{name: "maximum", minConfidence: 100},
+		{name: "negative", minConfidence: -1, wantErr: true},
+		{name: "above 100", minConfidence: 101, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			cfg := &Config{
+				OutputDir:        tmpDir,
+				StateFile:        filepath.Join(tmpDir, "state.json"),
+				LogLevel:         "info",
+				OCREnabled:       true,
+				OCRLanguages:     "eng",
+				OCRMinConfidence: tt.minConfidence,
+				LLM:              LLMConfig{Provider: "ollama", Model: "llava", Endpoint: "http://localhost:11434"},
+			}
+
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidate_OCRPreprocessing(t *testing.T) {
+	tests := []struct {
+		name         string
+		maxDimension int
+		contrast     float64
+		wantErr      bool
+	}{
+		{name: "unchanged", maxDimension: 0, contrast: 1},
+		{name: "downscale and boost contrast", maxDimension: 1600, contrast: 1.5},
+		{name: "negative max dimension", maxDimension: -1, contrast: 1, wantErr: true},
+		{name: "negative contrast", maxDimension: 0, contrast: -0.5, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			cfg := &Config{
+				OutputDir:            tmpDir,
+				StateFile:            filepath.Join(tmpDir, "state.json"),
+				LogLevel:             "info",
+				OCREnabled:           true,
+				OCRLanguages:         "eng",
+				OCRMaxImageDimension: tt.maxDimension,
+				OCRContrast:          tt.contrast,
+				LLM:                  LLMConfig{Provider: "ollama", Model: "llava", Endpoint: "http://localhost:11434"},
+			}
+
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidate_OCRImageFormats(t *testing.T) {
+	tests := []struct {
+		name        string
+		format      string
+		quality     int
+		wantFormat  string
+		wantQuality int
+		wantErr     bool
+	}{
+		{name: "unset", wantFormat: OCRImagePNG, wantQuality: 90},
+		{name: "jpeg", format: "JPEG", quality: 75, wantFormat: OCRImageJPEG, wantQuality: 75},
+		{name: "jpg", format: "jpg", quality: 100, wantFormat: OCRImageJPEG, wantQuality: 100},
+		{name: "unknown format", format: "webp", wantErr: true},
+		{name: "quality too high", format: "jpeg", quality: 101, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			cfg := &Config{
+				OutputDir:                 tmpDir,
+				StateFile:                 filepath.Join(tmpDir, "state.json"),
+				LogLevel:                  "info",
+				OCRStructuredImageFormat:  tt.format,
+				OCRStructuredImageQuality: tt.quality,
+			}
+
+			err := cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && (cfg.OCRStructuredImageFormat != tt.wantFormat || cfg.OCRStructuredImageQuality != tt.wantQuality) {
+				t.Errorf("structured image = %s (%d), want %s (%d)",
+					cfg.OCRStructuredImageFormat, cfg.OCRStructuredImageQuality, tt.wantFormat, tt.wantQuality)
+			}
+			if !tt.wantErr && cfg.OCRSimpleImageFormat != OCRImagePNG {
+				t.Errorf("OCRSimpleImageFormat = %s, want %s", cfg.OCRSimpleImageFormat, OCRImagePNG)
+			}
+		})
+	}
+}
+
+func TestValidate_Retries(t *testing.T) {
+	tests := []struct {
+		name         string
+		maxRetries   int
+		retryBackoff time.Duration
+		wantErr      bool
+	}{
+		{name: "defaults", maxRetries: 3, retryBackoff: 2 * time.Second},
+		{name: "no retries", maxRetries: 0, retryBackoff: 0},
+		{name: "negative retries", maxRetries: -1, wantErr: true},
+		{name: "negative backoff", maxRetries: 3, retryBackoff: -time.Second, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			cfg := &Config{
+				OutputDir:    tmpDir,
+				StateFile:    filepath.Join(tmpDir, "state.json"),
+				LogLevel:     "info",
+				MaxRetries:   tt.maxRetries,
+				RetryBackoff: tt.retryBackoff,
+			}
+
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestValidate_EmptyOutputDir(t *testing.T) {
 	cfg := &Config{
 		OutputDir:  "",
@@ -207,6 +930,52 @@ func TestValidate_DaemonModeRequiresSyncInterval(t *testing.T) {
 	}
 }
 
+func TestValidate_SyncSchedule(t *testing.T) {
+	tmpDir := t.TempDir()
+	base := Config{
+		OutputDir:    tmpDir,
+		StateFile:    filepath.Join(tmpDir, "state.json"),
+		LogLevel:     "info",
+		OCRLanguages: "eng",
+		DaemonMode:   true,
+	}
+
+	// A schedule stands in for the interval in daemon mode
+	cfg := base
+	cfg.SyncSchedule = "30 8 * * 1-5"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("expected a schedule without an interval to be valid, got: %v", err)
+	}
+
+	cfg = base
+	cfg.SyncSchedule = "every morning"
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "invalid sync-schedule") {
+		t.Errorf("expected an error about the sync-schedule, got: %v", err)
+	}
+}
+
+func TestValidate_ChangePollInterval(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := &Config{
+		OutputDir:          tmpDir,
+		StateFile:          filepath.Join(tmpDir, "state.json"),
+		LogLevel:           "info",
+		OCRLanguages:       "eng",
+		ChangePollInterval: -time.Second,
+	}
+
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "change-poll-interval") {
+		t.Errorf("expected an error about change-poll-interval, got: %v", err)
+	}
+
+	cfg.ChangePollInterval = 30 * time.Second
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("expected a positive change-poll-interval to be valid, got: %v", err)
+	}
+}
+
 func TestValidate_ValidConfiguration(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -0,0 +1,77 @@
+package converter
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+)
+
+// pageTagBookmarks returns an outline entry for each tagged page, in page order, named
+// after its tags; a page with several tags gets one entry listing them alphabetically
+func pageTagBookmarks(content *ContentFile) []pdfcpu.Bookmark {
+	tagsByPage := make(map[int][]string)
+	for tag, pages := range pageTagGroups(content) {
+		for _, page := range pages {
+			tagsByPage[page] = append(tagsByPage[page], tag)
+		}
+	}
+
+	pages := make([]int, 0, len(tagsByPage))
+	for page := range tagsByPage {
+		pages = append(pages, page)
+	}
+	sort.Ints(pages)
+
+	bookmarks := make([]pdfcpu.Bookmark, 0, len(pages))
+	for _, page := range pages {
+		tags := tagsByPage[page]
+		sort.Strings(tags)
+		bookmarks = append(bookmarks, pdfcpu.Bookmark{Title: strings.Join(tags, ", "), PageFrom: page})
+	}
+	return bookmarks
+}
+
+// addTagBookmarks bookmarks the tagged pages of the PDF at outputPath, if the
+// converter is configured to
+func (c *Converter) addTagBookmarks(outputPath string, content *ContentFile, result *ConversionResult) {
+	if !c.tagBookmarks {
+		return
+	}
+	bookmarks := pageTagBookmarks(content)
+	if len(bookmarks) == 0 {
+		return
+	}
+	if err := c.addBookmarks(outputPath, bookmarks); err != nil {
+		result.AddWarning(fmt.Sprintf("Failed to add bookmarks: %v", err))
+		return
+	}
+	result.Bookmarks = len(bookmarks)
+	c.logger.WithFields("bookmarks", len(bookmarks)).Debug("Added page tag bookmarks")
+}
+
+// addBookmarks replaces the outline of the PDF at pdfPath with bookmarks
+func (c *Converter) addBookmarks(pdfPath string, bookmarks []pdfcpu.Bookmark) error {
+	// Write to a temp file in the same directory and replace the original
+	tmpFile, err := os.CreateTemp(filepath.Dir(pdfPath), "pdf-bookmarks-*.pdf")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	tmpPath := tmpFile.Name()
+	_ = tmpFile.Close()
+	defer func() { _ = os.Remove(tmpPath) }()
+
+	if err := api.AddBookmarksFile(pdfPath, tmpPath, bookmarks, true, model.NewDefaultConfiguration()); err != nil {
+		return fmt.Errorf("failed to add bookmarks: %w", err)
+	}
+	if err := os.Rename(tmpPath, pdfPath); err != nil {
+		return fmt.Errorf("failed to replace original file: %w", err)
+	}
+
+	return nil
+}
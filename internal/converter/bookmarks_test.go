@@ -0,0 +1,116 @@
+package converter
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+func TestPageTagBookmarks(t *testing.T) {
+	content := &ContentFile{
+		CPages: CPages{Pages: []PageInfo{{ID: "p1"}, {ID: "p2"}, {ID: "p3"}}},
+		PageTags: []PageTag{
+			{Name: "work", PageID: "p3"},
+			{Name: "ideas", PageID: "p3"},
+			{Name: "todo", PageID: "p1"},
+			{Name: "deleted", PageID: "p9"},
+		},
+	}
+
+	want := []pdfcpu.Bookmark{
+		{Title: "todo", PageFrom: 1},
+		{Title: "ideas, work", PageFrom: 3},
+	}
+	if got := pageTagBookmarks(content); !reflect.DeepEqual(got, want) {
+		t.Errorf("pageTagBookmarks() = %+v, want %+v", got, want)
+	}
+
+	if got := pageTagBookmarks(&ContentFile{CPages: content.CPages}); len(got) != 0 {
+		t.Errorf("pageTagBookmarks() = %+v for untagged pages, want none", got)
+	}
+}
+
+// pdfBookmarks returns the title and page of each top-level outline entry of the PDF
+func pdfBookmarks(t *testing.T, pdfPath string) map[string]int {
+	t.Helper()
+	f, err := os.Open(pdfPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+
+	bookmarks, err := api.Bookmarks(f, nil)
+	if err != nil {
+		t.Fatalf("Bookmarks(%s) error = %v", pdfPath, err)
+	}
+	pages := make(map[string]int, len(bookmarks))
+	for _, bookmark := range bookmarks {
+		pages[bookmark.Title] = bookmark.PageFrom
+	}
+	return pages
+}
+
+func TestConvertRmdoc_BookmarksFromTags(t *testing.T) {
+	src := "../../example/Test.rmdoc"
+	if _, err := os.Stat(src); os.IsNotExist(err) {
+		t.Skipf("Test file not found: %s", src)
+	}
+
+	// Leave the first page untagged and tag the second both "work" and "ideas"
+	tmpDir := t.TempDir()
+	rmdocPath := filepath.Join(tmpDir, "Tagged.rmdoc")
+	rewriteRmdoc(t, src, rmdocPath, func(name string, data []byte) []byte {
+		if !strings.HasSuffix(name, ".content") {
+			return data
+		}
+		var content map[string]interface{}
+		if err := json.Unmarshal(data, &content); err != nil {
+			t.Fatal(err)
+		}
+		pages := content["cPages"].(map[string]interface{})["pages"].([]interface{})
+		second := pages[1].(map[string]interface{})["id"]
+		content["pageTags"] = []map[string]interface{}{
+			{"name": "work", "pageId": second, "timestamp": 1},
+			{"name": "ideas", "pageId": second, "timestamp": 2},
+		}
+		out, err := json.Marshal(content)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return out
+	})
+
+	for _, tt := range []struct {
+		name      string
+		bookmarks bool
+		want      map[string]int
+	}{
+		{name: "enabled", bookmarks: true, want: map[string]int{"ideas, work": 2}},
+		{name: "disabled", bookmarks: false, want: map[string]int{}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			conv, err := New(&Config{OCRLanguages: []string{"eng"}, BookmarksFromTags: tt.bookmarks})
+			if err != nil {
+				t.Fatalf("New() error: %v", err)
+			}
+			outputPath := filepath.Join(t.TempDir(), "Notes.pdf")
+			result, err := conv.ConvertRmdoc(rmdocPath, outputPath)
+			if err != nil {
+				t.Fatalf("ConvertRmdoc() error: %v", err)
+			}
+
+			if result.Bookmarks != len(tt.want) {
+				t.Errorf("result Bookmarks = %d, want %d", result.Bookmarks, len(tt.want))
+			}
+			if got := pdfBookmarks(t, outputPath); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PDF outline = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
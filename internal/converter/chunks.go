@@ -0,0 +1,70 @@
+package converter
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// chunkPages divides pageCount pages into chunks of at most size pages, returning the
+// 1-based page numbers of each chunk in order
+func chunkPages(pageCount, size int) [][]int {
+	if size <= 0 || pageCount <= 0 {
+		return nil
+	}
+
+	chunks := make([][]int, 0, (pageCount+size-1)/size)
+	for first := 1; first <= pageCount; first += size {
+		last := min(first+size-1, pageCount)
+		chunk := make([]int, 0, last-first+1)
+		for page := first; page <= last; page++ {
+			chunk = append(chunk, page)
+		}
+		chunks = append(chunks, chunk)
+	}
+	return chunks
+}
+
+// chunkPDFPath returns the path of chunk n (1-based) of the document at pdfPath:
+// "<name>-001.pdf" in the same directory
+func chunkPDFPath(pdfPath string, n int) string {
+	return fmt.Sprintf("%s-%03d.pdf", strings.TrimSuffix(pdfPath, filepath.Ext(pdfPath)), n)
+}
+
+// splitIntoChunks writes very large PDFs at outputPath in chunks too, if the
+// converter is configured to, recording their paths on result
+func (c *Converter) splitIntoChunks(outputPath string, pageCount int, result *ConversionResult) {
+	if c.chunkPages <= 0 {
+		return
+	}
+	paths, err := c.writeChunkPDFs(outputPath, pageCount)
+	if err != nil {
+		result.AddWarning(fmt.Sprintf("Failed to write chunks: %v", err))
+	}
+	result.ChunkPaths = paths
+}
+
+// writeChunkPDFs writes the pages of the PDF at pdfPath in chunks of the converter's
+// chunk size to PDFs of their own next to it, and returns their paths in page order
+//
+// A PDF with no more pages than the chunk size isn't chunked. The pages are copied
+// from the converted PDF, so they keep any OCR text layer and metadata.
+func (c *Converter) writeChunkPDFs(pdfPath string, pageCount int) ([]string, error) {
+	if pageCount <= c.chunkPages {
+		return nil, nil
+	}
+
+	var paths []string
+	for i, pages := range chunkPages(pageCount, c.chunkPages) {
+		path := chunkPDFPath(pdfPath, i+1)
+		if err := c.pdfEnhancer.ReorderPages(pdfPath, path, pages); err != nil {
+			_ = os.Remove(path)
+			return paths, fmt.Errorf("failed to write pages %d-%d: %w", pages[0], pages[len(pages)-1], err)
+		}
+		paths = append(paths, path)
+		c.logger.WithFields("chunk", i+1, "first_page", pages[0], "last_page", pages[len(pages)-1], "output", path).Debug("Wrote chunk PDF")
+	}
+
+	return paths, nil
+}
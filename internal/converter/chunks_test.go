@@ -0,0 +1,89 @@
+package converter
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestChunkPages(t *testing.T) {
+	tests := []struct {
+		pages, size int
+		want        [][]int
+	}{
+		{pages: 7, size: 3, want: [][]int{{1, 2, 3}, {4, 5, 6}, {7}}},
+		{pages: 6, size: 3, want: [][]int{{1, 2, 3}, {4, 5, 6}}},
+		{pages: 2, size: 5, want: [][]int{{1, 2}}},
+		{pages: 3, size: 1, want: [][]int{{1}, {2}, {3}}},
+		{pages: 5, size: 0, want: nil},
+		{pages: 0, size: 3, want: nil},
+	}
+
+	for _, tt := range tests {
+		if got := chunkPages(tt.pages, tt.size); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("chunkPages(%d, %d) = %v, want %v", tt.pages, tt.size, got, tt.want)
+		}
+	}
+}
+
+func TestConvertRmdoc_ChunkPages(t *testing.T) {
+	src := "../../example/Test.rmdoc"
+	if _, err := os.Stat(src); os.IsNotExist(err) {
+		t.Skipf("Test file not found: %s", src)
+	}
+
+	// Seven pages, chunked by three, make chunks of three, three, and one page
+	order := []int{0, 1, 1, 0, 1, 0, 0}
+	rmdocPath := filepath.Join(t.TempDir(), "Long.rmdoc")
+	repeatPages(t, src, rmdocPath, order)
+
+	conv, err := New(&Config{OCRLanguages: []string{"eng"}, ChunkPages: 3})
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	outputPath := filepath.Join(t.TempDir(), "notebook.pdf")
+	result, err := conv.ConvertRmdoc(rmdocPath, outputPath)
+	if err != nil {
+		t.Fatalf("ConvertRmdoc() error: %v", err)
+	}
+
+	want := []string{
+		filepath.Join(filepath.Dir(outputPath), "notebook-001.pdf"),
+		filepath.Join(filepath.Dir(outputPath), "notebook-002.pdf"),
+		filepath.Join(filepath.Dir(outputPath), "notebook-003.pdf"),
+	}
+	if !reflect.DeepEqual(result.ChunkPaths, want) {
+		t.Fatalf("ChunkPaths = %v, want %v", result.ChunkPaths, want)
+	}
+
+	// The whole PDF is still written, and the chunks hold its pages in order
+	all := pdfPageContents(t, outputPath)
+	if len(all) != len(order) {
+		t.Fatalf("PDF has %d pages, want %d", len(all), len(order))
+	}
+	var chunked []string
+	for i, path := range result.ChunkPaths {
+		pages := pdfPageContents(t, path)
+		if wantPages := []int{3, 3, 1}[i]; len(pages) != wantPages {
+			t.Errorf("chunk %d has %d pages, want %d", i+1, len(pages), wantPages)
+		}
+		chunked = append(chunked, pages...)
+	}
+	if !reflect.DeepEqual(chunked, all) {
+		t.Error("chunks don't hold the PDF's pages in order")
+	}
+
+	// A document no longer than the chunk size isn't chunked
+	conv, err = New(&Config{OCRLanguages: []string{"eng"}, ChunkPages: len(order)})
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	result, err = conv.ConvertRmdoc(rmdocPath, filepath.Join(t.TempDir(), "short.pdf"))
+	if err != nil {
+		t.Fatalf("ConvertRmdoc() error: %v", err)
+	}
+	if len(result.ChunkPaths) != 0 {
+		t.Errorf("ChunkPaths = %v, want none for a document of the chunk size", result.ChunkPaths)
+	}
+}
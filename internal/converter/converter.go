@@ -3,12 +3,18 @@ package converter
 
 import (
 	"archive/zip"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"image"
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/pdfcpu/pdfcpu/pkg/api"
@@ -16,24 +22,148 @@ import (
 	"github.com/platinummonkey/legible/internal/logger"
 	"github.com/platinummonkey/legible/internal/ocr"
 	"github.com/platinummonkey/legible/internal/pdfenhancer"
+	"github.com/platinummonkey/legible/internal/pdfrender"
 	"github.com/platinummonkey/legible/internal/rmparse"
+	"github.com/platinummonkey/legible/internal/rmrender"
 	"github.com/signintech/gopdf"
 )
 
 // Converter handles conversion of .rmdoc files to PDF
 type Converter struct {
 	logger       *logger.Logger
-	ocrEnabled   bool
+	ocrEnabled   atomic.Bool // changed by SetOCREnabled while conversions run
 	ocrLanguages []string
 	ocrProc      *ocr.Processor
 	pdfEnhancer  *pdfenhancer.PDFEnhancer
+	pageRenderer *pdfrender.Renderer
+
+	ocrConcurrency int
+	ocrDPI         int
+	ocrMinDPI      int
+	adaptiveDPI    bool
+	textOnly       bool
+	markdown       bool
+	sidecarFormat  string
+	minConfidence  float64
+	splitPageTags  bool
+	tagBookmarks   bool
+	ocrCache       *ocr.Cache
+	unparseable    string
+
+	// renderConcurrency is the number of pages rendered at once
+	renderConcurrency int
+	// renderCache reuses pages rendered before; nil disables it
+	renderCache *renderCache
+
+	// encryptPassword encrypts written PDFs as the last step ("" leaves them unencrypted)
+	encryptPassword string
+
+	// chunkPages also writes PDFs longer than this many pages in chunks (0 disables it)
+	chunkPages int
+
+	// writeFile writes rendered PDFs; replaced in tests to simulate write failures
+	writeFile func(name string, data []byte, perm os.FileMode) error
 }
 
+// Adaptive OCR DPI tuning
+const (
+	// DefaultOCRMinDPI is the render DPI for pages without strokes when AdaptiveDPI is on
+	DefaultOCRMinDPI = 150
+
+	// denseStrokeCount is the stroke count at which a page is rendered at the full OCR DPI,
+	// roughly a page of small handwriting
+	denseStrokeCount = 500
+)
+
+// OCR sidecar formats, written next to the PDF with the raw OCR results
+const (
+	// OCRSidecarJSON writes the OCR results (pages, words, boxes, confidence) as .json
+	OCRSidecarJSON = "json"
+
+	// OCRSidecarText writes each page's OCR text as .txt, pages separated by form feeds
+	OCRSidecarText = "text"
+
+	// OCRSidecarHOCR writes the OCR results as hOCR with word bounding boxes, as .hocr
+	OCRSidecarHOCR = "hocr"
+)
+
+// Policies for documents none of whose pages could be parsed, such as documents
+// written in a newer .rm format
+const (
+	// UnparseableSkip writes no PDF and records a warning in the result
+	UnparseableSkip = "skip"
+
+	// UnparseablePlaceholder writes a single page PDF with a note saying why the
+	// document could not be converted
+	UnparseablePlaceholder = "placeholder"
+
+	// UnparseableFail fails the conversion with an error wrapping ErrUnparseable
+	UnparseableFail = "fail"
+)
+
+// ErrUnparseable is returned, wrapped, for a document none of whose pages could be
+// parsed under the UnparseableFail policy
+var ErrUnparseable = errors.New("no page of the document could be parsed")
+
 // Config holds configuration for the converter
 type Config struct {
 	Logger       *logger.Logger
 	EnableOCR    bool     // Enable OCR text layer (default: true)
 	OCRLanguages []string // Language codes for OCR via Ollama (default: ["eng"])
+	// OCRPromptPath is a YAML prompt file for structured OCR with Ollama, used by the
+	// OCR processor created when OCRProcessor isn't set (default: the embedded prompts)
+	OCRPromptPath string
+	// OCRConcurrency is the number of pages OCR'd in parallel (default: 1)
+	OCRConcurrency int
+	// RenderConcurrency is the number of pages rendered in parallel, each to a PDF of
+	// its own that is then merged in page order (default: 1, rendering pages in turn
+	// straight into the document)
+	RenderConcurrency int
+	// OCRDPI is the resolution pages are rendered at for OCR, and the upper bound
+	// when AdaptiveDPI is set (default: pdfrender.DefaultDPI)
+	OCRDPI int
+	// AdaptiveDPI renders each page for OCR at a DPI between OCRMinDPI and OCRDPI
+	// chosen from its stroke count, so sparse pages OCR faster
+	AdaptiveDPI bool
+	// OCRMinDPI is the lower bound for AdaptiveDPI (default: DefaultOCRMinDPI)
+	OCRMinDPI int
+	// TextOnly writes a transcript instead of the handwriting: each page is blank
+	// apart from its OCR text, drawn visibly (requires OCR)
+	TextOnly bool
+	// MarkdownTranscript also writes the OCR text as Markdown next to the PDF, with
+	// the same name and a .md extension (requires OCR)
+	MarkdownTranscript bool
+	// OCRSidecarFormat also writes the raw OCR results next to the PDF in this format:
+	// OCRSidecarJSON, OCRSidecarText, or OCRSidecarHOCR ("" writes none; requires OCR)
+	OCRSidecarFormat string
+	// OCRMinConfidence drops OCR words recognized with less confidence (0-100) from
+	// the text layer and the word count (default: 0, keeping every word)
+	OCRMinConfidence float64
+	// SplitByPageTag also writes the pages carrying each page tag as a PDF of their
+	// own next to the PDF, named "<name> - <tag>.pdf"; a page with several tags is in
+	// each of their PDFs
+	SplitByPageTag bool
+	// BookmarksFromTags adds a PDF outline with a bookmark for each tagged page, named
+	// after its page tags
+	BookmarksFromTags bool
+	// OCRCacheDir caches each page's OCR result here, keyed by a hash of the rendered
+	// page and the OCR model, so unchanged pages aren't sent to the model again
+	// ("" disables the cache)
+	OCRCacheDir string
+	// RenderCacheDir caches each rendered page here as a single page PDF, keyed by a
+	// hash of its .rm file, template, and page size, so pages unchanged between syncs
+	// aren't rendered again ("" disables the cache)
+	RenderCacheDir string
+	// EncryptPassword encrypts the PDF, and any page tag and chunk PDFs, with this
+	// password once everything else has been added to them ("" leaves them unencrypted)
+	EncryptPassword string
+	// ChunkPages also writes the pages of a PDF with more than this many pages in
+	// chunks of this many, as "<name>-001.pdf", "<name>-002.pdf", ... next to it, so
+	// very large notebooks can be opened a part at a time (0 writes no chunks)
+	ChunkPages int
+	// UnparseablePolicy handles documents none of whose pages could be parsed:
+	// UnparseableSkip, UnparseablePlaceholder, or UnparseableFail (default: UnparseableSkip)
+	UnparseablePolicy string
 	// Optional pre-configured processors (if nil, will create with defaults)
 	OCRProcessor *ocr.Processor
 	PDFEnhancer  *pdfenhancer.PDFEnhancer
@@ -45,18 +175,15 @@ func New(cfg *Config) (*Converter, error) {
 	if cfg == nil {
 		cfg = &Config{}
 	}
+	if err := validateConfig(cfg); err != nil {
+		return nil, err
+	}
 
 	log := cfg.Logger
 	if log == nil {
 		log = logger.Get()
 	}
-
-	// Enable OCR by default
-	enableOCR := cfg.EnableOCR
-	if !cfg.EnableOCR && cfg.OCRLanguages == nil {
-		// If not explicitly configured, enable by default
-		enableOCR = true
-	}
+	enableOCR := ocrEnabledByConfig(cfg)
 
 	// Set default languages
 	languages := cfg.OCRLanguages
@@ -64,45 +191,167 @@ func New(cfg *Config) (*Converter, error) {
 		languages = []string{"eng"}
 	}
 
-	// Use provided processors or create new ones if enabled
+	unparseable := cfg.UnparseablePolicy
+	if unparseable == "" {
+		unparseable = UnparseableSkip
+	}
+
+	ocrDPI, ocrMinDPI := ocrDPIRange(cfg)
+
 	var ocrProc *ocr.Processor
-	var pdfEnhancerInst *pdfenhancer.PDFEnhancer
+	var ocrCache *ocr.Cache
 	if enableOCR {
-		// Use pre-configured OCR processor if provided, otherwise create default
-		if cfg.OCRProcessor != nil {
-			ocrProc = cfg.OCRProcessor
-			log.Debug("Using provided OCR processor")
-		} else {
-			proc, err := ocr.New(&ocr.Config{
-				Logger: log,
-				// Ollama handles language detection automatically via vision models
-			})
-			if err != nil {
-				return nil, fmt.Errorf("failed to create OCR processor: %w", err)
-			}
-			ocrProc = proc
-			log.Debug("Created default OCR processor")
+		proc, err := newOCRProcessor(cfg, log)
+		if err != nil {
+			return nil, err
 		}
-
-		// Use pre-configured PDF enhancer if provided, otherwise create default
-		if cfg.PDFEnhancer != nil {
-			pdfEnhancerInst = cfg.PDFEnhancer
-			log.Debug("Using provided PDF enhancer")
-		} else {
-			pdfEnhancerInst = pdfenhancer.New(&pdfenhancer.Config{
-				Logger: log,
-			})
-			log.Debug("Created default PDF enhancer")
+		ocrProc = proc
+		if cfg.OCRCacheDir != "" {
+			ocrCache = ocr.NewCache(cfg.OCRCacheDir)
 		}
 	}
 
-	return &Converter{
+	var rc *renderCache
+	if cfg.RenderCacheDir != "" {
+		rc = &renderCache{dir: cfg.RenderCacheDir}
+	}
+
+	conv := &Converter{
 		logger:       log,
-		ocrEnabled:   enableOCR,
 		ocrLanguages: languages,
 		ocrProc:      ocrProc,
-		pdfEnhancer:  pdfEnhancerInst,
-	}, nil
+		pdfEnhancer:  newPDFEnhancer(cfg, log, enableOCR || rc != nil),
+		pageRenderer: pdfrender.New(&pdfrender.Config{Logger: log}),
+
+		// Process pages sequentially unless configured otherwise
+		ocrConcurrency: max(cfg.OCRConcurrency, 1),
+		ocrDPI:         ocrDPI,
+		ocrMinDPI:      ocrMinDPI,
+		adaptiveDPI:    cfg.AdaptiveDPI,
+		textOnly:       cfg.TextOnly,
+		markdown:       cfg.MarkdownTranscript,
+		sidecarFormat:  cfg.OCRSidecarFormat,
+		minConfidence:  cfg.OCRMinConfidence,
+		splitPageTags:  cfg.SplitByPageTag,
+		tagBookmarks:   cfg.BookmarksFromTags,
+		ocrCache:       ocrCache,
+		unparseable:    unparseable,
+
+		renderConcurrency: max(cfg.RenderConcurrency, 1),
+		renderCache:       rc,
+
+		encryptPassword: cfg.EncryptPassword,
+		chunkPages:      max(cfg.ChunkPages, 0),
+
+		writeFile: os.WriteFile,
+	}
+	conv.ocrEnabled.Store(enableOCR)
+	return conv, nil
+}
+
+// ocrEnabledByConfig reports whether cfg enables OCR, which is on unless disabled
+// explicitly or by setting languages without EnableOCR
+func ocrEnabledByConfig(cfg *Config) bool {
+	return cfg.EnableOCR || cfg.OCRLanguages == nil
+}
+
+// validateConfig checks that the options of cfg are valid and can be used together
+func validateConfig(cfg *Config) error {
+	enableOCR := ocrEnabledByConfig(cfg)
+
+	// A transcript is made of the OCR text, so it can't be written without OCR
+	if cfg.TextOnly && !enableOCR {
+		return fmt.Errorf("text-only output requires OCR to be enabled")
+	}
+	if cfg.MarkdownTranscript && !enableOCR {
+		return fmt.Errorf("markdown transcripts require OCR to be enabled")
+	}
+	switch cfg.OCRSidecarFormat {
+	case "", OCRSidecarJSON, OCRSidecarText, OCRSidecarHOCR:
+	default:
+		return fmt.Errorf("unknown OCR sidecar format %q (want %s, %s, or %s)",
+			cfg.OCRSidecarFormat, OCRSidecarJSON, OCRSidecarText, OCRSidecarHOCR)
+	}
+	if cfg.OCRSidecarFormat != "" && !enableOCR {
+		return fmt.Errorf("OCR sidecars require OCR to be enabled")
+	}
+	switch cfg.UnparseablePolicy {
+	case "", UnparseableSkip, UnparseablePlaceholder, UnparseableFail:
+	default:
+		return fmt.Errorf("unknown unparseable document policy %q (want %s, %s, or %s)",
+			cfg.UnparseablePolicy, UnparseableSkip, UnparseablePlaceholder, UnparseableFail)
+	}
+	if cfg.OCRMinConfidence < 0 || cfg.OCRMinConfidence > 100 {
+		return fmt.Errorf("OCR minimum confidence must be between 0 and 100, got %v", cfg.OCRMinConfidence)
+	}
+	return nil
+}
+
+// ocrDPIRange returns the resolution pages are rendered at for OCR, and the lowest
+// resolution adaptive DPI may choose
+func ocrDPIRange(cfg *Config) (ocrDPI, ocrMinDPI int) {
+	ocrDPI = cfg.OCRDPI
+	if ocrDPI <= 0 {
+		ocrDPI = pdfrender.DefaultDPI
+	}
+	ocrMinDPI = cfg.OCRMinDPI
+	if ocrMinDPI <= 0 {
+		ocrMinDPI = DefaultOCRMinDPI
+	}
+	return ocrDPI, min(ocrMinDPI, ocrDPI)
+}
+
+// newOCRProcessor returns the OCR processor provided in cfg, or creates a default one
+func newOCRProcessor(cfg *Config, log *logger.Logger) (*ocr.Processor, error) {
+	if cfg.OCRProcessor != nil {
+		log.Debug("Using provided OCR processor")
+		return cfg.OCRProcessor, nil
+	}
+
+	proc, err := ocr.New(&ocr.Config{
+		Logger:     log,
+		PromptPath: cfg.OCRPromptPath,
+		// Ollama handles language detection automatically via vision models
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OCR processor: %w", err)
+	}
+	log.Debug("Created default OCR processor")
+	return proc, nil
+}
+
+// newPDFEnhancer returns the PDF enhancer provided in cfg, or creates a default one,
+// when the configured conversions need one
+//
+// The PDF enhancer adds the text layer, splits pages out by tag or into chunks, merges
+// pages rendered in parallel or read from the render cache, and encrypts PDFs.
+func newPDFEnhancer(cfg *Config, log *logger.Logger, needed bool) *pdfenhancer.PDFEnhancer {
+	if !needed && !cfg.SplitByPageTag && cfg.ChunkPages <= 0 && cfg.RenderConcurrency <= 1 && cfg.EncryptPassword == "" {
+		return nil
+	}
+	if cfg.PDFEnhancer != nil {
+		log.Debug("Using provided PDF enhancer")
+		return cfg.PDFEnhancer
+	}
+	log.Debug("Created default PDF enhancer")
+	return pdfenhancer.New(&pdfenhancer.Config{Logger: log})
+}
+
+// SetOCREnabled turns OCR on or off for conversions that start afterwards
+// OCR can only be turned on for a converter created with it enabled, which has an OCR
+// processor, and can't be turned off while the output is made from the OCR text.
+func (c *Converter) SetOCREnabled(enabled bool) error {
+	switch {
+	case enabled && (c.ocrProc == nil || c.pdfEnhancer == nil):
+		return fmt.Errorf("OCR was disabled when the converter was created")
+	case !enabled && (c.textOnly || c.markdown || c.sidecarFormat != ""):
+		return fmt.Errorf("OCR can't be disabled while text-only output, markdown transcripts, or OCR sidecars are enabled")
+	}
+
+	if c.ocrEnabled.Swap(enabled) != enabled {
+		c.logger.WithFields("enabled", enabled).Info("Changed OCR setting")
+	}
+	return nil
 }
 
 // DocumentMetadata represents the .metadata JSON file from a .rmdoc
@@ -150,11 +399,48 @@ type PageTag struct {
 
 // ConvertRmdoc converts a .rmdoc file to PDF
 func (c *Converter) ConvertRmdoc(rmdocPath, outputPath string) (*ConversionResult, error) {
-	c.logger.WithFields("input", rmdocPath, "output", outputPath).Info("Converting .rmdoc to PDF")
+	return c.ConvertRmdocContext(context.Background(), rmdocPath, outputPath)
+}
+
+// ConvertRmdocContext converts a .rmdoc file to PDF, stopping early if ctx is cancelled
+//
+// Cancellation is checked between pages while rendering and during OCR. On cancellation
+// the temporary files and any partially written output are removed and ctx.Err() is returned.
+func (c *Converter) ConvertRmdocContext(ctx context.Context, rmdocPath, outputPath string) (*ConversionResult, error) {
+	return c.ConvertRmdocWithOptions(ctx, NewConversionOptions(rmdocPath, outputPath))
+}
+
+// ConvertRmdocWithOptions converts opts.InputPath to a PDF at opts.OutputPath
+//
+// Pages are sized from opts.PaperSize and opts.Orientation; an empty paper size or
+// PaperSizeRemarkable in portrait keeps the device's own page size. opts.Pages limits
+// the conversion, OCR included, to a range of pages. Tags written to the PDF metadata
+// follow opts.TagPrefix and opts.IncludePageTags; OCR still follows the converter's
+// Config.
+func (c *Converter) ConvertRmdocWithOptions(ctx context.Context, opts *ConversionOptions) (*ConversionResult, error) {
+	if opts == nil {
+		return nil, fmt.Errorf("conversion options cannot be nil")
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	rmdocPath, outputPath := opts.InputPath, opts.OutputPath
+
+	c.logger.WithFields("input", rmdocPath, "output", outputPath, "dry_run", opts.DryRun).Info("Converting .rmdoc to PDF")
 
 	startTime := time.Now()
 	result := NewConversionResult()
 
+	if opts.DryRun {
+		dryRunPath, cleanup, err := dryRunOutputPath()
+		if err != nil {
+			return nil, err
+		}
+		defer cleanup()
+		outputPath = dryRunPath
+	}
+
 	// Extract .rmdoc (ZIP file) to temporary directory
 	tmpDir, err := os.MkdirTemp("", "rmdoc-*")
 	if err != nil {
@@ -165,18 +451,71 @@ func (c *Converter) ConvertRmdoc(rmdocPath, outputPath string) (*ConversionResul
 	if err := c.extractRmdoc(rmdocPath, tmpDir); err != nil {
 		return nil, fmt.Errorf("failed to extract .rmdoc: %w", err)
 	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	metadata, content, err := c.readDocument(tmpDir, opts, result)
+	if err != nil {
+		return nil, err
+	}
+
+	// Convert pages to PDF
+	pages, err := c.convertPages(ctx, tmpDir, content, outputPath, opts)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, c.abortConversion(ctx, outputPath)
+		}
+		return nil, fmt.Errorf("failed to convert pages: %w", err)
+	}
+	result.Pages = pages
+	if allPagesFailed(pages) {
+		return c.convertUnparseable(outputPath, metadata.VisibleName, content, opts, result, startTime)
+	}
+	if failed := result.FailedPages(); len(failed) > 0 {
+		c.logger.WithFields("failed_pages", len(failed), "pages", len(pages)).Warn("Some pages could not be read and were left blank")
+	}
+
+	c.addTagMetadata(outputPath, metadata, content, opts, result)
+	c.addThumbnail(outputPath, tmpDir, content, result)
+	if err := c.addOCR(ctx, outputPath, content.PageCount, result); err != nil {
+		return nil, err
+	}
+
+	// Bookmark, split and chunk once OCR has finished rewriting the PDF, so the
+	// split and chunk PDFs keep the text layer
+	c.addTagBookmarks(outputPath, content, result)
+	c.splitByPageTag(outputPath, content, result)
+	c.splitIntoChunks(outputPath, content.PageCount, result)
 
-	// Read metadata
-	metadata, err := c.readMetadata(tmpDir)
+	// Encrypt last, since nothing can change the PDFs afterwards
+	if err := c.encryptResult(outputPath, result); err != nil {
+		return nil, err
+	}
+
+	return c.finishConversion(outputPath, content.PageCount, opts, result, startTime)
+}
+
+// readDocument reads the metadata and content of a document extracted to extractDir,
+// keeping only the pages selected by opts.Pages
+//
+// Metadata that can't be read is recorded as a warning on result, and the document is
+// converted as untitled.
+func (c *Converter) readDocument(extractDir string, opts *ConversionOptions, result *ConversionResult) (*DocumentMetadata, *ContentFile, error) {
+	metadata, err := c.readMetadata(extractDir)
 	if err != nil {
 		result.AddWarning(fmt.Sprintf("Failed to read metadata: %v", err))
 		metadata = &DocumentMetadata{VisibleName: "Untitled"}
 	}
 
-	// Read content
-	content, err := c.readContent(tmpDir)
+	content, err := c.readContent(extractDir)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read content: %w", err)
+		return nil, nil, fmt.Errorf("failed to read content: %w", err)
+	}
+	if opts.Pages != "" {
+		if err := selectPages(content, opts.Pages); err != nil {
+			return nil, nil, err
+		}
 	}
 
 	c.logger.WithFields(
@@ -184,47 +523,91 @@ func (c *Converter) ConvertRmdoc(rmdocPath, outputPath string) (*ConversionResul
 		"pages", content.PageCount,
 		"format", content.FormatVersion,
 	).Debug("Extracted document metadata")
+	return metadata, content, nil
+}
 
-	// Convert pages to PDF
-	if err := c.convertPages(tmpDir, content, outputPath); err != nil {
-		return nil, fmt.Errorf("failed to convert pages: %w", err)
+// finishConversion records the successful conversion to outputPath on result,
+// discarding the outputs of a dry run
+func (c *Converter) finishConversion(outputPath string, pageCount int, opts *ConversionOptions, result *ConversionResult, startTime time.Time) (*ConversionResult, error) {
+	fileInfo, err := os.Stat(outputPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat output file: %w", err)
 	}
 
-	// Extract tags and add PDF metadata
-	tags := c.extractTags(content)
-	if len(tags) > 0 {
-		if err := c.addPDFMetadata(outputPath, metadata, tags); err != nil {
-			result.AddWarning(fmt.Sprintf("Failed to add PDF metadata: %v", err))
-		} else {
-			c.logger.WithFields("tags", tags).Info("Added PDF metadata with tags")
-		}
+	duration := time.Since(startTime)
+	result.SetSuccess(outputPath, pageCount, fileInfo.Size(), duration)
+	if opts.DryRun {
+		result.discardOutputs()
+		c.logger.WithFields("input", opts.InputPath, "pages", pageCount, "duration", duration).Info("Dry run conversion succeeded, output discarded")
+		return result, nil
 	}
+	c.logger.WithFields("output", outputPath, "pages", pageCount, "duration", duration).Info("Successfully converted .rmdoc to PDF")
 
-	// Add OCR text layer if enabled
-	if c.ocrEnabled {
-		if err := c.addOCRTextLayer(outputPath, content.PageCount, result); err != nil {
-			result.AddWarning(fmt.Sprintf("Failed to add OCR text layer: %v", err))
-			c.logger.WithFields("error", err).Warn("OCR processing failed, continuing without text layer")
-		} else {
-			c.logger.WithFields(
-				"word_count", result.OCRWordCount,
-				"confidence", result.OCRConfidence,
-				"duration", result.OCRDuration,
-			).Info("Successfully added OCR text layer")
-		}
-	}
+	return result, nil
+}
 
-	// Get output file size
-	fileInfo, err := os.Stat(outputPath)
+// dryRunOutputPath returns the output path of a dry run, in a directory of its own,
+// and a function removing that directory along with everything written next to the
+// PDF, such as OCR sidecars and page tag PDFs
+func dryRunOutputPath() (string, func(), error) {
+	dir, err := os.MkdirTemp("", "rmdoc-dry-run-*")
 	if err != nil {
-		return nil, fmt.Errorf("failed to stat output file: %w", err)
+		return "", nil, fmt.Errorf("failed to create dry run directory: %w", err)
 	}
+	return filepath.Join(dir, "output.pdf"), func() { _ = os.RemoveAll(dir) }, nil
+}
 
-	duration := time.Since(startTime)
-	result.SetSuccess(outputPath, content.PageCount, fileInfo.Size(), duration)
-	c.logger.WithFields("output", outputPath, "pages", content.PageCount, "duration", duration).Info("Successfully converted .rmdoc to PDF")
+// addTagMetadata writes the document's metadata and tags to the PDF at outputPath,
+// if it has any tags
+func (c *Converter) addTagMetadata(outputPath string, metadata *DocumentMetadata, content *ContentFile, opts *ConversionOptions, result *ConversionResult) {
+	tags := c.extractTags(content, opts)
+	if len(tags) == 0 {
+		return
+	}
+	if err := c.addPDFMetadata(outputPath, metadata, tags); err != nil {
+		result.AddWarning(fmt.Sprintf("Failed to add PDF metadata: %v", err))
+		return
+	}
+	c.logger.WithFields("tags", tags).Info("Added PDF metadata with tags")
+}
 
-	return result, nil
+// addOCR adds the OCR text layer to the PDF at outputPath if OCR is enabled
+//
+// OCR failures are recorded as warnings on result; an error is only returned, and the
+// output removed, when ctx is cancelled.
+func (c *Converter) addOCR(ctx context.Context, outputPath string, pageCount int, result *ConversionResult) error {
+	if !c.ocrEnabled.Load() {
+		return nil
+	}
+
+	strokeCounts := make([]int, len(result.Pages))
+	for i, page := range result.Pages {
+		strokeCounts[i] = page.Strokes
+	}
+	if err := c.addOCRTextLayer(ctx, outputPath, pageCount, strokeCounts, result); err != nil {
+		if ctx.Err() != nil {
+			return c.abortConversion(ctx, outputPath)
+		}
+		result.AddWarning(fmt.Sprintf("Failed to add OCR text layer: %v", err))
+		c.logger.WithFields("error", err).Warn("OCR processing failed, continuing without text layer")
+		return nil
+	}
+
+	c.logger.WithFields(
+		"word_count", result.OCRWordCount,
+		"confidence", result.OCRConfidence,
+		"duration", result.OCRDuration,
+	).Info("Successfully added OCR text layer")
+	return nil
+}
+
+// abortConversion removes any partially written output after a cancelled conversion
+func (c *Converter) abortConversion(ctx context.Context, outputPath string) error {
+	if err := os.Remove(outputPath); err != nil && !os.IsNotExist(err) {
+		c.logger.WithFields("output", outputPath, "error", err).Warn("Failed to remove partial output")
+	}
+	c.logger.WithFields("output", outputPath, "error", ctx.Err()).Warn("Conversion cancelled")
+	return ctx.Err()
 }
 
 // extractRmdoc extracts a .rmdoc ZIP file to the specified directory
@@ -321,82 +704,206 @@ func (c *Converter) readContent(extractDir string) (*ContentFile, error) {
 	return &content, nil
 }
 
-// convertPages converts the .rm files to PDF pages
-func (c *Converter) convertPages(extractDir string, content *ContentFile, outputPath string) error {
+// convertPages converts the .rm files to PDF pages, returning each page's outcome
+func (c *Converter) convertPages(ctx context.Context, extractDir string, content *ContentFile, outputPath string, opts *ConversionOptions) ([]PageOutcome, error) {
 	c.logger.WithFields("pages", content.PageCount).Debug("Converting pages to PDF")
 
 	// Find the directory containing .rm files
 	var rmDir string
 	entries, err := os.ReadDir(extractDir)
 	if err != nil {
-		return fmt.Errorf("failed to read extract directory: %w", err)
+		return nil, fmt.Errorf("failed to read extract directory: %w", err)
 	}
 
 	for _, entry := range entries {
-		if entry.IsDir() && !strings.HasSuffix(entry.Name(), ".metadata") && !strings.HasSuffix(entry.Name(), ".content") {
+		if entry.IsDir() && !strings.HasSuffix(entry.Name(), ".metadata") && !strings.HasSuffix(entry.Name(), ".content") &&
+			!strings.HasSuffix(entry.Name(), thumbnailsDirSuffix) {
 			rmDir = filepath.Join(extractDir, entry.Name())
 			break
 		}
 	}
 
 	if rmDir == "" {
-		return fmt.Errorf(".rm files directory not found")
+		return nil, fmt.Errorf(".rm files directory not found")
 	}
 
 	c.logger.WithFields("rm_dir", rmDir).Debug("Found .rm files directory")
 
 	// Create PDF with rendered pages
-	if err := c.renderPagesToPDF(rmDir, content, outputPath); err != nil {
-		return fmt.Errorf("failed to render pages: %w", err)
+	pages, err := c.renderPagesToPDF(ctx, rmDir, content, outputPath, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render pages: %w", err)
 	}
 
-	return nil
+	return pages, nil
 }
 
-// renderPagesToPDF renders .rm files to PDF pages
-func (c *Converter) renderPagesToPDF(rmDir string, content *ContentFile, outputPath string) error {
+// renderPagesToPDF renders .rm files to PDF pages and returns the outcome of each page
+//
+// Pages without a .rm file or strokes are empty. Pages whose .rm file fails to parse
+// or render are failed, and get a marker so they aren't mistaken for empty pages.
+// opts may be nil, in which case pages use the device's own page size. With a render
+// concurrency above 1 or a render cache, pages are rendered to PDFs of their own and
+// merged; see renderPageFiles.
+func (c *Converter) renderPagesToPDF(ctx context.Context, rmDir string, content *ContentFile, outputPath string, opts *ConversionOptions) ([]PageOutcome, error) {
+	// Page size depends on the device that wrote the document and the requested paper
+	layout := newPageRenderLayout(rmparse.PageSizeForFormatVersion(content.FormatVersion), opts)
+	c.logger.WithFields(
+		"format", content.FormatVersion,
+		"native_width", layout.pageSize.NativeWidth,
+		"native_height", layout.pageSize.NativeHeight,
+		"page_width", layout.width,
+		"page_height", layout.height,
+	).Debug("Using page dimensions")
+
+	if n := len(content.CPages.Pages); (c.renderConcurrency > 1 && n > 1) || (c.renderCache != nil && n > 0) {
+		return c.renderPageFiles(ctx, rmDir, content, outputPath, layout)
+	}
+
 	// Initialize PDF
-	pdf := gopdf.GoPdf{}
-	pdf.Start(gopdf.Config{
-		PageSize: gopdf.Rect{W: rmparse.PDFWidth, H: rmparse.PDFHeight},
-	})
+	pdf := layout.newPDF()
 
 	// Process each page in order
+	pages := make([]PageOutcome, len(content.CPages.Pages))
 	for i, pageInfo := range content.CPages.Pages {
-		c.logger.WithFields("page", i+1, "id", pageInfo.ID).Debug("Rendering page")
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		pages[i] = c.renderPage(pdf, rmDir, i+1, pageInfo, layout)
+	}
 
-		// Add new page
-		pdf.AddPage()
+	// Write PDF to output file
+	if err := c.writeRenderedPDF(pdf, outputPath); err != nil {
+		return nil, err
+	}
 
-		// Find corresponding .rm file
-		rmPath := filepath.Join(rmDir, pageInfo.ID+".rm")
-		if _, err := os.Stat(rmPath); os.IsNotExist(err) {
-			c.logger.WithFields("page", i+1, "path", rmPath).Warn("Page .rm file not found, skipping")
-			continue
-		}
+	return pages, nil
+}
 
-		// Parse .rm file
-		rmFile, err := rmparse.ParseRM(rmPath)
-		if err != nil {
-			c.logger.WithFields("page", i+1, "error", err).Warn("Failed to parse .rm file, skipping")
-			continue
-		}
+// pageRenderLayout is how the pages of a document are laid out in its PDF
+type pageRenderLayout struct {
+	pageSize      rmparse.PageSize
+	width, height float64 // PDF page size in points
+	templateArea  rmrender.TemplateArea
+}
 
-		// Render to current page
-		if err := rmparse.RenderToPage(&pdf, rmFile); err != nil {
-			c.logger.WithFields("page", i+1, "error", err).Warn("Failed to render page, continuing")
-			// Continue with blank page
-		}
+// newPageRenderLayout returns the layout of pages written on a device with the native
+// page size, on the paper requested by opts
+func newPageRenderLayout(native rmparse.PageSize, opts *ConversionOptions) *pageRenderLayout {
+	pageSize, width, height := pageLayout(native, opts)
+	return &pageRenderLayout{
+		pageSize: pageSize,
+		width:    width,
+		height:   height,
+		// Templates cover the same area of the page as the strokes
+		templateArea: rmrender.TemplateArea{
+			NativeWidth:  pageSize.NativeWidth,
+			NativeHeight: pageSize.NativeHeight,
+			X:            pageSize.OffsetX,
+			Y:            pageSize.OffsetY,
+			Width:        pageSize.PDFWidth,
+			Height:       pageSize.PDFHeight,
+		},
+	}
+}
+
+// newPDF starts an empty PDF with the layout's page size
+func (l *pageRenderLayout) newPDF() *gopdf.GoPdf {
+	pdf := &gopdf.GoPdf{}
+	pdf.Start(gopdf.Config{
+		PageSize: gopdf.Rect{W: l.width, H: l.height},
+	})
+	return pdf
+}
 
-		c.logger.WithFields("page", i+1, "layers", len(rmFile.Layers)).Debug("Successfully rendered page")
+// renderPage adds page number of the document, described by pageInfo, to pdf and
+// returns its outcome
+func (c *Converter) renderPage(pdf *gopdf.GoPdf, rmDir string, number int, pageInfo PageInfo, layout *pageRenderLayout) PageOutcome {
+	page := PageOutcome{Number: number, Status: PageEmpty}
+	c.logger.WithFields("page", number, "id", pageInfo.ID).Debug("Rendering page")
+
+	// Add new page with its own template beneath the strokes
+	pdf.AddPage()
+	if err := rmrender.DrawTemplate(pdf, pageInfo.Template.Value, layout.templateArea); err != nil {
+		c.logger.WithFields("page", number, "template", pageInfo.Template.Value, "error", err).Debug("Skipping page template")
 	}
 
-	// Write PDF to output file
-	if err := pdf.WritePdf(outputPath); err != nil {
-		return fmt.Errorf("failed to write PDF: %w", err)
+	// Find corresponding .rm file; pages never written on don't have one
+	rmPath := filepath.Join(rmDir, pageInfo.ID+".rm")
+	if _, err := os.Stat(rmPath); os.IsNotExist(err) {
+		c.logger.WithFields("page", number, "path", rmPath).Debug("Page .rm file not found, page is empty")
+		return page
 	}
 
-	return nil
+	// Parse .rm file
+	rmFile, err := rmparse.ParseRM(rmPath)
+	if err != nil {
+		c.logger.WithFields("page", number, "error", err).Warn("Failed to parse .rm file, marking page as failed")
+		page.Status, page.Error = PageFailed, fmt.Sprintf("failed to parse page: %v", err)
+		drawFailedPageMarker(pdf, layout.width, layout.height)
+		return page
+	}
+
+	for _, layer := range rmFile.Layers {
+		page.Strokes += len(layer.Lines)
+	}
+	if page.Strokes > 0 {
+		page.Status = PageRendered
+	}
+
+	// Render to current page, keeping whatever was drawn before an error
+	if err := rmparse.RenderToPageWithSize(pdf, rmFile, layout.pageSize); err != nil {
+		c.logger.WithFields("page", number, "error", err).Warn("Failed to render page, marking page as failed")
+		page.Status, page.Error = PageFailed, fmt.Sprintf("failed to render page: %v", err)
+		drawFailedPageMarker(pdf, layout.width, layout.height)
+		return page
+	}
+
+	c.logger.WithFields("page", number, "layers", len(rmFile.Layers), "strokes", page.Strokes).Debug("Successfully rendered page")
+	return page
+}
+
+// failedPageMarkerInset is the distance in points of the failed page marker from the
+// page edges
+const failedPageMarkerInset = 6.0
+
+// drawFailedPageMarker frames the current page with a thin dashed red line, marking it
+// as a page that could not be read rather than an empty one
+func drawFailedPageMarker(pdf *gopdf.GoPdf, pageWidth, pageHeight float64) {
+	pdf.SetStrokeColor(220, 90, 90)
+	pdf.SetLineWidth(1)
+	pdf.SetLineType("dashed")
+	pdf.RectFromUpperLeftWithStyle(failedPageMarkerInset, failedPageMarkerInset,
+		pageWidth-2*failedPageMarkerInset, pageHeight-2*failedPageMarkerInset, "D")
+	pdf.SetLineType("solid")
+}
+
+// pageLayout returns how a device's native page maps onto the PDF page requested by opts,
+// along with the PDF page width and height in points
+//
+// Without options, or for the reMarkable paper size in portrait, the device's own page size
+// is used unchanged. Other sizes are converted from PaperSize.Dimensions (300 DPI pixels),
+// swapped for landscape, and the native page is scaled uniformly to fit and centered.
+func pageLayout(native rmparse.PageSize, opts *ConversionOptions) (rmparse.PageSize, float64, float64) {
+	if opts == nil {
+		return native, native.PDFWidth, native.PDFHeight
+	}
+
+	width, height := native.PDFWidth, native.PDFHeight
+	if opts.PaperSize != "" && opts.PaperSize != PaperSizeRemarkable {
+		w, h := opts.PaperSize.Dimensions()
+		width = float64(w) * 72.0 / DefaultDPI
+		height = float64(h) * 72.0 / DefaultDPI
+	}
+
+	if opts.Orientation == OrientationLandscape {
+		width, height = height, width
+	}
+
+	if width == native.PDFWidth && height == native.PDFHeight {
+		return native, width, height
+	}
+	return native.FitToPage(width, height), width, height
 }
 
 // createPlaceholderPDF creates a valid PDF with the specified number of blank pages using pdfcpu
@@ -485,21 +992,32 @@ func parseTimestamp(ts string) time.Time {
 	return time.Unix(ms/1000, (ms%1000)*1000000)
 }
 
-// extractTags extracts all tags from the content file (document-level and page-level)
-func (c *Converter) extractTags(content *ContentFile) []string {
+// extractTags returns the document's tags for its PDF metadata, sorted: its
+// document-level tags and, if opts.IncludePageTags is set, its page tags, each with
+// opts.TagPrefix prepended and listed once
+//
+// opts may be nil, in which case page tags are included without a prefix.
+func (c *Converter) extractTags(content *ContentFile, opts *ConversionOptions) []string {
+	prefix, includePageTags := "", true
+	if opts != nil {
+		prefix, includePageTags = opts.TagPrefix, opts.IncludePageTags
+	}
+
 	tagSet := make(map[string]bool)
 
 	// Add document-level tags
 	for _, tag := range content.Tags {
 		if tag != "" {
-			tagSet[tag] = true
+			tagSet[prefix+tag] = true
 		}
 	}
 
 	// Add page-level tags
-	for _, pageTag := range content.PageTags {
-		if pageTag.Name != "" {
-			tagSet[pageTag.Name] = true
+	if includePageTags {
+		for _, pageTag := range content.PageTags {
+			if pageTag.Name != "" {
+				tagSet[prefix+pageTag.Name] = true
+			}
 		}
 	}
 
@@ -508,20 +1026,22 @@ func (c *Converter) extractTags(content *ContentFile) []string {
 	for tag := range tagSet {
 		tags = append(tags, tag)
 	}
+	sort.Strings(tags)
 
 	return tags
 }
 
 // addOCRTextLayer performs OCR on the PDF and adds a searchable text layer
-func (c *Converter) addOCRTextLayer(pdfPath string, pageCount int, result *ConversionResult) error {
+//
+// strokeCounts holds each page's stroke count for adaptive DPI; pages beyond it are
+// rendered at the full OCR DPI.
+func (c *Converter) addOCRTextLayer(ctx context.Context, pdfPath string, pageCount int, strokeCounts []int, result *ConversionResult) error {
 	c.logger.WithFields("pdf", pdfPath, "pages", pageCount).Info("Starting OCR processing")
 
 	ocrStartTime := time.Now()
 
 	// Render PDF pages to images for OCR
-	// Use 300 DPI for good OCR accuracy
-	const ocrDPI = 300
-	images, err := c.renderAllPagesToImages(pdfPath, ocrDPI)
+	images, err := c.pageRenderer.RenderAllWithDPI(ctx, pdfPath, c.ocrPageDPI(strokeCounts))
 	if err != nil {
 		return fmt.Errorf("failed to render PDF pages: %w", err)
 	}
@@ -533,63 +1053,69 @@ func (c *Converter) addOCRTextLayer(pdfPath string, pageCount int, result *Conve
 		return fmt.Errorf("failed to get page dimensions: %w", err)
 	}
 
-	// Create document OCR result
-	docOCR := ocr.NewDocumentOCR("", strings.Join(c.ocrLanguages, "+"))
+	pages, err := c.ocrPages(ctx, images, pageInfo)
+	if err != nil {
+		return err
+	}
 
-	// Process each page with OCR
-	for i, img := range images {
-		pageNum := i + 1
-		c.logger.WithFields("page", pageNum, "total", pageCount).Debug("Processing page with OCR")
+	docOCR, err := c.documentOCR(pages)
+	if err != nil {
+		return err
+	}
+	if err := c.writeTextLayer(pdfPath, docOCR); err != nil {
+		return err
+	}
+	if err := c.writeOCRFiles(pdfPath, docOCR, result); err != nil {
+		return err
+	}
 
-		// Convert image to bytes
-		imageData, err := c.imageToBytes(img)
-		if err != nil {
-			c.logger.WithFields("page", pageNum, "error", err).Warn("Failed to convert image to bytes, skipping OCR for this page")
-			continue
-		}
+	ocrDuration := time.Since(ocrStartTime)
 
-		// Process with OCR
-		pageOCR, err := c.ocrProc.ProcessImage(imageData, pageNum)
-		if err != nil {
-			c.logger.WithFields("page", pageNum, "error", err).Warn("Failed to process page with OCR, skipping")
-			continue
-		}
+	// Update result with OCR statistics
+	result.OCREnabled = true
+	result.OCRWordCount = docOCR.TotalWords
+	result.OCRConfidence = docOCR.AverageConfidence
+	result.OCRDuration = ocrDuration
 
-		// Scale OCR coordinates from image pixels to PDF points
-		// Image was rendered at ocrDPI, so pixels -> PDF points conversion is:
-		// pdfPoint = imagePixel * 72 / ocrDPI
-		bounds := img.Bounds()
-		imageWidth := bounds.Dx()
-		imageHeight := bounds.Dy()
-		scaleX := float64(pageInfo.Width) / float64(imageWidth)
-		scaleY := float64(pageInfo.Height) / float64(imageHeight)
-
-		// Scale all word bounding boxes
-		for j := range pageOCR.Words {
-			word := &pageOCR.Words[j]
-			word.BoundingBox.X = int(float64(word.BoundingBox.X) * scaleX)
-			word.BoundingBox.Y = int(float64(word.BoundingBox.Y) * scaleY)
-			word.BoundingBox.Width = int(float64(word.BoundingBox.Width) * scaleX)
-			word.BoundingBox.Height = int(float64(word.BoundingBox.Height) * scaleY)
-		}
+	return nil
+}
 
-		// Update page dimensions to match PDF
-		pageOCR.Width = pageInfo.Width
-		pageOCR.Height = pageInfo.Height
+// ocrPageDPI returns the DPI each page is rendered at for OCR, from the stroke counts
+// of the pages when adaptive DPI is enabled
+func (c *Converter) ocrPageDPI(strokeCounts []int) func(page int) int {
+	return func(page int) int {
+		if !c.adaptiveDPI || page > len(strokeCounts) {
+			return c.ocrDPI
+		}
+		return c.adaptiveOCRDPI(strokeCounts[page-1])
+	}
+}
 
-		// Add to document results
-		docOCR.AddPage(*pageOCR)
-		c.logger.WithFields(
-			"page", pageNum,
-			"words", len(pageOCR.Words),
-			"confidence", pageOCR.Confidence,
-			"scale", fmt.Sprintf("%.3fx%.3f", scaleX, scaleY),
-		).Debug("Completed OCR for page")
+// documentOCR combines the OCR results of the pages, in document order, without the
+// words below the minimum confidence
+//
+// Pages that failed OCR are nil and left out. It fails if every page did, since a text
+// layer of no pages means OCR is unavailable rather than the pages blank.
+func (c *Converter) documentOCR(pages []*ocr.PageOCR) (*ocr.DocumentOCR, error) {
+	docOCR := ocr.NewDocumentOCR("", strings.Join(c.ocrLanguages, "+"))
+	for _, pageOCR := range pages {
+		if pageOCR != nil {
+			c.dropLowConfidenceWords(pageOCR)
+			docOCR.AddPage(*pageOCR)
+		}
+	}
+	if len(docOCR.Pages) == 0 && len(pages) > 0 {
+		return nil, fmt.Errorf("OCR failed on all %d pages", len(pages))
 	}
 
 	// Finalize document OCR statistics
 	docOCR.Finalize()
+	return docOCR, nil
+}
 
+// writeTextLayer adds the OCR text layer to the PDF at pdfPath in place, or replaces
+// the handwriting with it for a transcript
+func (c *Converter) writeTextLayer(pdfPath string, docOCR *ocr.DocumentOCR) error {
 	// Create temporary enhanced PDF
 	tmpFile, err := os.CreateTemp(filepath.Dir(pdfPath), "enhanced-*.pdf")
 	if err != nil {
@@ -599,8 +1125,11 @@ func (c *Converter) addOCRTextLayer(pdfPath string, pageCount int, result *Conve
 	_ = tmpFile.Close()
 	defer func() { _ = os.Remove(tmpPath) }()
 
-	// Add text layer to PDF
-	if err := c.pdfEnhancer.AddTextLayer(pdfPath, tmpPath, docOCR); err != nil {
+	if c.textOnly {
+		if err := c.pdfEnhancer.WriteTranscript(pdfPath, tmpPath, docOCR); err != nil {
+			return fmt.Errorf("failed to write transcript: %w", err)
+		}
+	} else if err := c.pdfEnhancer.AddTextLayer(pdfPath, tmpPath, docOCR); err != nil {
 		return fmt.Errorf("failed to add text layer: %w", err)
 	}
 
@@ -608,18 +1137,260 @@ func (c *Converter) addOCRTextLayer(pdfPath string, pageCount int, result *Conve
 	if err := os.Rename(tmpPath, pdfPath); err != nil {
 		return fmt.Errorf("failed to replace PDF with enhanced version: %w", err)
 	}
+	return nil
+}
 
-	ocrDuration := time.Since(ocrStartTime)
-
-	// Update result with OCR statistics
-	result.OCREnabled = true
-	result.OCRWordCount = docOCR.TotalWords
-	result.OCRConfidence = docOCR.AverageConfidence
-	result.OCRDuration = ocrDuration
+// writeOCRFiles writes the configured Markdown transcript and OCR sidecar next to the
+// PDF at pdfPath, recording their paths on result
+func (c *Converter) writeOCRFiles(pdfPath string, docOCR *ocr.DocumentOCR, result *ConversionResult) error {
+	if c.markdown {
+		mdPath, err := writeMarkdownTranscript(pdfPath, docOCR)
+		if err != nil {
+			return err
+		}
+		result.MarkdownPath = mdPath
+	}
 
+	if c.sidecarFormat != "" {
+		sidecarPath, err := writeOCRSidecar(pdfPath, c.sidecarFormat, docOCR)
+		if err != nil {
+			return err
+		}
+		result.SidecarPath = sidecarPath
+	}
 	return nil
 }
 
+// writeMarkdownTranscript writes the OCR results as a Markdown sidecar of the PDF,
+// titled after the PDF's file name, and returns its path
+func writeMarkdownTranscript(pdfPath string, docOCR *ocr.DocumentOCR) (string, error) {
+	title := strings.TrimSuffix(filepath.Base(pdfPath), filepath.Ext(pdfPath))
+	mdPath := strings.TrimSuffix(pdfPath, filepath.Ext(pdfPath)) + ".md"
+	if err := os.WriteFile(mdPath, []byte(docOCR.Markdown(title)), 0644); err != nil {
+		return "", fmt.Errorf("failed to write Markdown transcript: %w", err)
+	}
+	return mdPath, nil
+}
+
+// writeOCRSidecar writes the OCR results next to the PDF in format (one of the
+// OCRSidecar formats) and returns its path
+func writeOCRSidecar(pdfPath, format string, docOCR *ocr.DocumentOCR) (string, error) {
+	base := strings.TrimSuffix(pdfPath, filepath.Ext(pdfPath))
+	title := filepath.Base(base)
+
+	var path string
+	var data []byte
+	switch format {
+	case OCRSidecarJSON:
+		var err error
+		if data, err = docOCR.JSON(); err != nil {
+			return "", err
+		}
+		path = base + ".json"
+	case OCRSidecarText:
+		path, data = base+".txt", []byte(docOCR.PlainText())
+	case OCRSidecarHOCR:
+		path, data = base+".hocr", []byte(docOCR.HOCR(title))
+	default:
+		return "", fmt.Errorf("unknown OCR sidecar format %q", format)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write OCR sidecar: %w", err)
+	}
+	return path, nil
+}
+
+// adaptiveOCRDPI returns the OCR render DPI for a page with the given number of strokes
+//
+// DPI scales linearly from ocrMinDPI for an empty page to ocrDPI at denseStrokeCount
+// strokes or more, trading speed on sparse sketches for accuracy on dense handwriting.
+func (c *Converter) adaptiveOCRDPI(strokes int) int {
+	if strokes >= denseStrokeCount {
+		return c.ocrDPI
+	}
+	if strokes <= 0 {
+		return c.ocrMinDPI
+	}
+	return c.ocrMinDPI + (c.ocrDPI-c.ocrMinDPI)*strokes/denseStrokeCount
+}
+
+// ocrPages runs OCR over rendered page images using up to c.ocrConcurrency workers
+//
+// Results are indexed by page. Pages that fail OCR are logged and left nil so the
+// remaining pages still contribute to the text layer.
+func (c *Converter) ocrPages(ctx context.Context, images []image.Image, pageInfo *pdfenhancer.PageInfo) ([]*ocr.PageOCR, error) {
+	results := make([]*ocr.PageOCR, len(images))
+
+	workers := c.ocrConcurrency
+	if workers > len(images) {
+		workers = len(images)
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = c.ocrPage(ctx, images[i], i+1, len(images), pageInfo)
+			}
+		}()
+	}
+
+dispatch:
+	for i := range images {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// ocrPage runs OCR on a single page image and scales the results to PDF points
+// Returns nil if the page could not be processed.
+func (c *Converter) ocrPage(ctx context.Context, img image.Image, pageNum, pageCount int, pageInfo *pdfenhancer.PageInfo) *ocr.PageOCR {
+	if ctx.Err() != nil {
+		return nil
+	}
+
+	c.logger.WithFields("page", pageNum, "total", pageCount).Debug("Processing page with OCR")
+
+	// Convert image to bytes
+	imageData, err := pdfrender.EncodePNG(img)
+	if err != nil {
+		c.logger.WithFields("page", pageNum, "error", err).Warn("Failed to convert image to bytes, skipping OCR for this page")
+		return nil
+	}
+
+	// Process with OCR
+	pageOCR, err := c.recognizePage(ctx, imageData, pageNum)
+	if err != nil {
+		if ctx.Err() == nil {
+			c.logger.WithFields("page", pageNum, "error", err).Warn("Failed to process page with OCR, skipping")
+		}
+		return nil
+	}
+
+	// Scale OCR coordinates from image pixels to PDF points
+	// Pages may be rendered at different DPIs, so scale by each image's own size:
+	// pdfPoint = imagePixel * pageSize / imageSize
+	bounds := img.Bounds()
+	imageWidth := bounds.Dx()
+	imageHeight := bounds.Dy()
+	scaleX := float64(pageInfo.Width) / float64(imageWidth)
+	scaleY := float64(pageInfo.Height) / float64(imageHeight)
+
+	// Scale all word bounding boxes
+	for j := range pageOCR.Words {
+		word := &pageOCR.Words[j]
+		word.BoundingBox.X = int(float64(word.BoundingBox.X) * scaleX)
+		word.BoundingBox.Y = int(float64(word.BoundingBox.Y) * scaleY)
+		word.BoundingBox.Width = int(float64(word.BoundingBox.Width) * scaleX)
+		word.BoundingBox.Height = int(float64(word.BoundingBox.Height) * scaleY)
+	}
+
+	// Keep the invisible text on the page, where it can be selected
+	words, dropped := clampWordsToPage(pageOCR.Words, pageInfo.Width, pageInfo.Height)
+	if dropped > 0 {
+		c.logger.WithFields("page", pageNum, "dropped", dropped).
+			Debug("Dropped OCR words outside the page")
+	}
+	pageOCR.Words = words
+
+	// Update page dimensions to match PDF
+	pageOCR.Width = pageInfo.Width
+	pageOCR.Height = pageInfo.Height
+
+	c.logger.WithFields(
+		"page", pageNum,
+		"words", len(pageOCR.Words),
+		"confidence", pageOCR.Confidence,
+		"scale", fmt.Sprintf("%.3fx%.3f", scaleX, scaleY),
+	).Debug("Completed OCR for page")
+
+	return pageOCR
+}
+
+// recognizePage returns the OCR result of a page image in image pixels, from the OCR
+// cache if it holds one, otherwise from the OCR processor, caching the result
+func (c *Converter) recognizePage(ctx context.Context, imageData []byte, pageNum int) (*ocr.PageOCR, error) {
+	if c.ocrCache == nil {
+		return c.ocrProc.ProcessImageContext(ctx, imageData, pageNum)
+	}
+
+	key := c.ocrProc.CacheKey(imageData)
+	cached, err := c.ocrCache.Get(key)
+	if err != nil {
+		c.logger.WithFields("page", pageNum, "error", err).Warn("Failed to read OCR cache, running OCR")
+	}
+	if cached != nil {
+		c.logger.WithFields("page", pageNum, "key", key).Debug("Using cached OCR result")
+		c.ocrProc.Metrics().RecordCacheHit()
+		cached.PageNumber = pageNum
+		return cached, nil
+	}
+	c.ocrProc.Metrics().RecordCacheMiss()
+
+	pageOCR, err := c.ocrProc.ProcessImageContext(ctx, imageData, pageNum)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.ocrCache.Put(key, pageOCR); err != nil {
+		c.logger.WithFields("page", pageNum, "error", err).Warn("Failed to cache OCR result")
+	}
+	return pageOCR, nil
+}
+
+// dropLowConfidenceWords removes words below the minimum confidence from a page, so
+// they don't pollute the searchable text layer, and updates its text and confidence
+func (c *Converter) dropLowConfidenceWords(page *ocr.PageOCR) {
+	if c.minConfidence <= 0 {
+		return
+	}
+
+	kept := page.Words[:0]
+	for _, word := range page.Words {
+		if word.Confidence >= c.minConfidence {
+			kept = append(kept, word)
+		}
+	}
+	if dropped := len(page.Words) - len(kept); dropped > 0 {
+		c.logger.WithFields("page", page.PageNumber, "dropped", dropped, "min_confidence", c.minConfidence).
+			Debug("Dropped low-confidence OCR words")
+		page.Words = kept
+		page.BuildText()
+		page.CalculateConfidence()
+	}
+}
+
+// clampWordsToPage clips word bounding boxes to a width x height page and drops words
+// that fall entirely outside it, returning the remaining words and the number dropped
+func clampWordsToPage(words []ocr.Word, width, height int) ([]ocr.Word, int) {
+	kept := words[:0]
+	for _, word := range words {
+		box := word.BoundingBox
+		x0, y0 := max(box.X, 0), max(box.Y, 0)
+		x1, y1 := min(box.X+box.Width, width), min(box.Y+box.Height, height)
+		if x1 <= x0 || y1 <= y0 {
+			continue
+		}
+		word.BoundingBox = ocr.NewRectangle(x0, y0, x1-x0, y1-y0)
+		kept = append(kept, word)
+	}
+	return kept, len(words) - len(kept)
+}
+
 // addPDFMetadata adds metadata to the PDF file using pdfcpu
 func (c *Converter) addPDFMetadata(pdfPath string, metadata *DocumentMetadata, tags []string) error {
 	// Prepare metadata properties
@@ -633,9 +1404,10 @@ func (c *Converter) addPDFMetadata(pdfPath string, metadata *DocumentMetadata, t
 		properties["Title"] = metadata.VisibleName
 	}
 
-	// Add subject (tags)
+	// Add subject and keywords (tags)
 	if len(tags) > 0 {
 		properties["Subject"] = strings.Join(tags, ", ")
+		properties["Keywords"] = strings.Join(tags, ", ")
 	}
 
 	// Add creation date
@@ -667,3 +1439,15 @@ func (c *Converter) addPDFMetadata(pdfPath string, metadata *DocumentMetadata, t
 
 	return nil
 }
+
+// validatePDFWithPdfcpu validates that a PDF is readable
+func (c *Converter) validatePDFWithPdfcpu(pdfPath string) error {
+	conf := model.NewDefaultConfiguration()
+	conf.ValidationMode = model.ValidationRelaxed
+
+	if err := api.ValidateFile(pdfPath, conf); err != nil {
+		return fmt.Errorf("PDF validation failed: %w", err)
+	}
+
+	return nil
+}
@@ -1,13 +1,34 @@
 package converter
 
 import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
 	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/platinummonkey/legible/internal/ocr"
+	"github.com/platinummonkey/legible/internal/ollama"
+	"github.com/platinummonkey/legible/internal/pdfenhancer"
+	"github.com/platinummonkey/legible/internal/pdfrender"
+	"github.com/platinummonkey/legible/internal/rmparse"
 )
 
 func TestNew(t *testing.T) {
@@ -344,7 +365,7 @@ func TestExtractTags(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := converter.extractTags(tt.content)
+			result := converter.extractTags(tt.content, nil)
 
 			if len(result) != len(tt.expected) {
 				t.Errorf("expected %d tags, got %d", len(tt.expected), len(result))
@@ -365,6 +386,57 @@ func TestExtractTags(t *testing.T) {
 	}
 }
 
+func TestExtractTags_Options(t *testing.T) {
+	converter, err := New(&Config{})
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	content := &ContentFile{
+		Tags: []string{"work", "project"},
+		PageTags: []PageTag{
+			{Name: "work", PageID: "page1"},
+			{Name: "draft", PageID: "page2"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		opts     *ConversionOptions
+		expected []string
+	}{
+		{
+			name:     "default options",
+			opts:     NewConversionOptions("in", "out"),
+			expected: []string{"draft", "project", "work"},
+		},
+		{
+			name:     "prefix applied to every tag",
+			opts:     &ConversionOptions{TagPrefix: "rm:", IncludePageTags: true},
+			expected: []string{"rm:draft", "rm:project", "rm:work"},
+		},
+		{
+			name:     "page tags excluded",
+			opts:     &ConversionOptions{IncludePageTags: false},
+			expected: []string{"project", "work"},
+		},
+		{
+			name:     "page tags excluded with prefix",
+			opts:     &ConversionOptions{TagPrefix: "rm:", IncludePageTags: false},
+			expected: []string{"rm:project", "rm:work"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// "work" is both a document and a page tag, and is listed once
+			if got := converter.extractTags(content, tt.opts); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("extractTags() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
 func TestExtractTags_RealDocument(t *testing.T) {
 	converter, err := New(&Config{})
 	if err != nil {
@@ -386,7 +458,7 @@ func TestExtractTags_RealDocument(t *testing.T) {
 		t.Fatalf("readContent() error = %v", err)
 	}
 
-	tags := converter.extractTags(content)
+	tags := converter.extractTags(content, nil)
 
 	// The Test.rmdoc has a "test" tag on page 2
 	if len(tags) == 0 {
@@ -502,3 +574,999 @@ func TestConvertRmdoc_PDFMetadata(t *testing.T) {
 		t.Errorf("expected Creator 'legible', got '%s'", pdfInfo.Creator)
 	}
 }
+
+func TestConvertRmdocWithOptions_TagOptions(t *testing.T) {
+	converter, err := New(&Config{})
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	rmdocPath := "../../example/Test.rmdoc"
+	if _, err := os.Stat(rmdocPath); os.IsNotExist(err) {
+		t.Skipf("Test file not found: %s", rmdocPath)
+	}
+
+	readInfo := func(path string) *pdfcpu.PDFInfo {
+		t.Helper()
+		f, err := os.Open(path)
+		if err != nil {
+			t.Fatalf("failed to open PDF: %v", err)
+		}
+		defer func() { _ = f.Close() }()
+		info, err := api.PDFInfo(f, path, nil, false, model.NewDefaultConfiguration())
+		if err != nil {
+			t.Fatalf("failed to read PDF info: %v", err)
+		}
+		return info
+	}
+
+	// Test.rmdoc's "test" tag is on page 2
+	opts := NewConversionOptions(rmdocPath, filepath.Join(t.TempDir(), "prefixed.pdf"))
+	opts.TagPrefix = "rm:"
+	if _, err := converter.ConvertRmdocWithOptions(context.Background(), opts); err != nil {
+		t.Fatalf("ConvertRmdocWithOptions() error = %v", err)
+	}
+	info := readInfo(opts.OutputPath)
+	if info.Subject != "rm:test" {
+		t.Errorf("Subject = %q, want %q", info.Subject, "rm:test")
+	}
+	if len(info.Keywords) != 1 || info.Keywords[0] != "rm:test" {
+		t.Errorf("Keywords = %v, want [rm:test]", info.Keywords)
+	}
+
+	opts = NewConversionOptions(rmdocPath, filepath.Join(t.TempDir(), "no-page-tags.pdf"))
+	opts.IncludePageTags = false
+	if _, err := converter.ConvertRmdocWithOptions(context.Background(), opts); err != nil {
+		t.Fatalf("ConvertRmdocWithOptions() error = %v", err)
+	}
+	if info := readInfo(opts.OutputPath); strings.Contains(info.Subject, "test") {
+		t.Errorf("Subject = %q, want no page tags", info.Subject)
+	}
+}
+
+// cancellingVisionClient cancels the conversion context on the first OCR request
+type cancellingVisionClient struct {
+	cancel context.CancelFunc
+	calls  int
+}
+
+func (c *cancellingVisionClient) GenerateOCR(ctx context.Context, _ string, _ string) ([]ollama.OCRWord, error) {
+	c.calls++
+	c.cancel()
+	return nil, ctx.Err()
+}
+
+func (c *cancellingVisionClient) HealthCheck(_ context.Context, _ string) error { return nil }
+
+func (c *cancellingVisionClient) Name() string { return "cancelling" }
+
+func (c *cancellingVisionClient) SupportedModels() []string { return nil }
+
+func TestConvertRmdocContext_AlreadyCancelled(t *testing.T) {
+	converter, err := New(&Config{})
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	outputPath := filepath.Join(t.TempDir(), "output.pdf")
+	_, err = converter.ConvertRmdocContext(ctx, "../../example/Test.rmdoc", outputPath)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("ConvertRmdocContext() error = %v, want context.Canceled", err)
+	}
+}
+
+func TestConvertRmdocContext_CancelDuringOCR(t *testing.T) {
+	rmdocPath := "../../example/Test.rmdoc"
+	if _, err := os.Stat(rmdocPath); os.IsNotExist(err) {
+		t.Skipf("Test file not found: %s", rmdocPath)
+	}
+
+	outputDir := t.TempDir()
+	outputPath := filepath.Join(outputDir, "output.pdf")
+
+	// Isolate temp files created during conversion so leftovers can be detected
+	tmpRoot := t.TempDir()
+	t.Setenv("TMPDIR", tmpRoot)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	vision := &cancellingVisionClient{cancel: cancel}
+	ocrProc, err := ocr.New(&ocr.Config{VisionClient: vision})
+	if err != nil {
+		t.Fatalf("ocr.New() error: %v", err)
+	}
+
+	converter, err := New(&Config{EnableOCR: true, OCRProcessor: ocrProc})
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	_, err = converter.ConvertRmdocContext(ctx, rmdocPath, outputPath)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("ConvertRmdocContext() error = %v, want context.Canceled", err)
+	}
+
+	if vision.calls != 1 {
+		t.Errorf("OCR called %d times, want 1 (remaining pages should be skipped)", vision.calls)
+	}
+
+	// Partial output should be removed
+	if _, err := os.Stat(outputPath); !os.IsNotExist(err) {
+		t.Error("partial output PDF should be removed after cancellation")
+	}
+
+	entries, err := os.ReadDir(outputDir)
+	if err != nil {
+		t.Fatalf("failed to read output dir: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("output dir has %d leftover files, want 0", len(entries))
+	}
+
+	// Temporary extraction directory should be cleaned up
+	leftovers, err := os.ReadDir(tmpRoot)
+	if err != nil {
+		t.Fatalf("failed to read temp dir: %v", err)
+	}
+	if len(leftovers) != 0 {
+		t.Errorf("temp dir has %d leftover entries, want 0", len(leftovers))
+	}
+}
+
+// staticVisionClient recognizes the same words on every page
+type staticVisionClient struct{}
+
+func (staticVisionClient) GenerateOCR(_ context.Context, _ string, _ string) ([]ollama.OCRWord, error) {
+	return []ollama.OCRWord{
+		{Text: "typed", BBox: []int{100, 100, 200, 40}, Confidence: 0.9},
+		{Text: "transcript", BBox: []int{320, 100, 240, 40}, Confidence: 0.9},
+	}, nil
+}
+
+func (staticVisionClient) HealthCheck(_ context.Context, _ string) error { return nil }
+
+func (staticVisionClient) Name() string { return "static" }
+
+func (staticVisionClient) SupportedModels() []string { return nil }
+
+func TestConvertRmdoc_TextOnly(t *testing.T) {
+	rmdocPath := "../../example/Test.rmdoc"
+	if _, err := os.Stat(rmdocPath); os.IsNotExist(err) {
+		t.Skipf("Test file not found: %s", rmdocPath)
+	}
+
+	ocrProc, err := ocr.New(&ocr.Config{VisionClient: staticVisionClient{}})
+	if err != nil {
+		t.Fatalf("ocr.New() error: %v", err)
+	}
+	converter, err := New(&Config{EnableOCR: true, OCRProcessor: ocrProc, TextOnly: true})
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	outputPath := filepath.Join(t.TempDir(), "transcript.pdf")
+	result, err := converter.ConvertRmdoc(rmdocPath, outputPath)
+	if err != nil {
+		t.Fatalf("ConvertRmdoc() error: %v", err)
+	}
+	if len(result.Warnings) > 0 {
+		t.Fatalf("ConvertRmdoc() warnings: %v", result.Warnings)
+	}
+
+	contentDir := t.TempDir()
+	if err := api.ExtractContentFile(outputPath, contentDir, nil, nil); err != nil {
+		t.Fatalf("ExtractContentFile() error: %v", err)
+	}
+	files, _ := filepath.Glob(filepath.Join(contentDir, "*"))
+	if len(files) != 2 {
+		t.Fatalf("got content for %d pages, want 2", len(files))
+	}
+
+	// Each page holds only visible text: no path construction or painting operators
+	// from the strokes or templates remain
+	textOperators := map[string]bool{"q": true, "Q": true, "BT": true, "ET": true, "g": true, "Tr": true, "Tf": true, "Tm": true, "Tj": true}
+	for i, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		content := string(data)
+
+		for _, want := range []string{"0 Tr", "(typed) Tj", "(transcript) Tj"} {
+			if !strings.Contains(content, want) {
+				t.Errorf("page %d should contain %q", i+1, want)
+			}
+		}
+		for _, line := range strings.Split(strings.TrimSpace(content), "\n") {
+			fields := strings.Fields(line)
+			if len(fields) > 0 && !textOperators[fields[len(fields)-1]] {
+				t.Errorf("page %d has non-text operator %q", i+1, line)
+				break
+			}
+		}
+	}
+}
+
+func TestConvertRmdoc_MarkdownTranscript(t *testing.T) {
+	rmdocPath := "../../example/Test.rmdoc"
+	if _, err := os.Stat(rmdocPath); os.IsNotExist(err) {
+		t.Skipf("Test file not found: %s", rmdocPath)
+	}
+
+	ocrProc, err := ocr.New(&ocr.Config{VisionClient: staticVisionClient{}})
+	if err != nil {
+		t.Fatalf("ocr.New() error: %v", err)
+	}
+	converter, err := New(&Config{EnableOCR: true, OCRProcessor: ocrProc, MarkdownTranscript: true})
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	outputPath := filepath.Join(t.TempDir(), "Notes.pdf")
+	result, err := converter.ConvertRmdoc(rmdocPath, outputPath)
+	if err != nil {
+		t.Fatalf("ConvertRmdoc() error: %v", err)
+	}
+
+	wantPath := strings.TrimSuffix(outputPath, ".pdf") + ".md"
+	if result.MarkdownPath != wantPath {
+		t.Errorf("MarkdownPath = %q, want %q", result.MarkdownPath, wantPath)
+	}
+	data, err := os.ReadFile(wantPath)
+	if err != nil {
+		t.Fatalf("Markdown transcript not written: %v", err)
+	}
+	want := "# Notes\n\ntyped transcript\n\n---\n\ntyped transcript\n"
+	if string(data) != want {
+		t.Errorf("Markdown transcript =\n%s\nwant\n%s", data, want)
+	}
+}
+
+func TestConvertRmdoc_OCRSidecar(t *testing.T) {
+	rmdocPath := "../../example/Test.rmdoc"
+	if _, err := os.Stat(rmdocPath); os.IsNotExist(err) {
+		t.Skipf("Test file not found: %s", rmdocPath)
+	}
+
+	ocrProc, err := ocr.New(&ocr.Config{VisionClient: staticVisionClient{}})
+	if err != nil {
+		t.Fatalf("ocr.New() error: %v", err)
+	}
+
+	tests := []struct {
+		format string
+		ext    string
+		check  func(t *testing.T, data []byte)
+	}{
+		{format: OCRSidecarJSON, ext: ".json", check: func(t *testing.T, data []byte) {
+			var doc ocr.DocumentOCR
+			if err := json.Unmarshal(data, &doc); err != nil {
+				t.Fatalf("sidecar is not valid JSON: %v", err)
+			}
+			if len(doc.Pages) != 2 || len(doc.Pages[0].Words) != 2 || doc.Pages[0].Words[0].Text != "typed" {
+				t.Errorf("sidecar = %+v, want both pages' words", doc)
+			}
+		}},
+		{format: OCRSidecarText, ext: ".txt", check: func(t *testing.T, data []byte) {
+			if want := "typed transcript\n\ftyped transcript\n\f"; string(data) != want {
+				t.Errorf("sidecar = %q, want %q", data, want)
+			}
+		}},
+		{format: OCRSidecarHOCR, ext: ".hocr", check: func(t *testing.T, data []byte) {
+			if words := countHOCRWords(t, data); words != 4 {
+				t.Errorf("sidecar has %d ocrx_word elements, want 4", words)
+			}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			converter, err := New(&Config{EnableOCR: true, OCRProcessor: ocrProc, OCRSidecarFormat: tt.format})
+			if err != nil {
+				t.Fatalf("New() error: %v", err)
+			}
+
+			outputPath := filepath.Join(t.TempDir(), "Notes.pdf")
+			result, err := converter.ConvertRmdoc(rmdocPath, outputPath)
+			if err != nil {
+				t.Fatalf("ConvertRmdoc() error: %v", err)
+			}
+
+			wantPath := strings.TrimSuffix(outputPath, ".pdf") + tt.ext
+			if result.SidecarPath != wantPath {
+				t.Errorf("SidecarPath = %q, want %q", result.SidecarPath, wantPath)
+			}
+			data, err := os.ReadFile(wantPath)
+			if err != nil {
+				t.Fatalf("OCR sidecar not written: %v", err)
+			}
+			tt.check(t, data)
+		})
+	}
+}
+
+// countHOCRWords returns the number of ocrx_word elements in an hOCR document
+func countHOCRWords(t *testing.T, data []byte) int {
+	t.Helper()
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	words := 0
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			return words
+		}
+		if err != nil {
+			t.Fatalf("sidecar is not well-formed hOCR: %v", err)
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		for _, attr := range start.Attr {
+			if attr.Name.Local == "class" && attr.Value == "ocrx_word" {
+				words++
+			}
+		}
+	}
+}
+
+// mixedConfidenceVisionClient recognizes one confident word and one guess on every page
+type mixedConfidenceVisionClient struct{ staticVisionClient }
+
+func (mixedConfidenceVisionClient) GenerateOCR(_ context.Context, _ string, _ string) ([]ollama.OCRWord, error) {
+	return []ollama.OCRWord{
+		{Text: "legible", BBox: []int{100, 100, 200, 40}, Confidence: 0.92},
+		{Text: "zxqv", BBox: []int{320, 100, 240, 40}, Confidence: 0.31},
+	}, nil
+}
+
+func TestConvertRmdoc_OCRMinConfidence(t *testing.T) {
+	rmdocPath := "../../example/Test.rmdoc"
+	if _, err := os.Stat(rmdocPath); os.IsNotExist(err) {
+		t.Skipf("Test file not found: %s", rmdocPath)
+	}
+
+	ocrProc, err := ocr.New(&ocr.Config{VisionClient: mixedConfidenceVisionClient{}})
+	if err != nil {
+		t.Fatalf("ocr.New() error: %v", err)
+	}
+	converter, err := New(&Config{EnableOCR: true, OCRProcessor: ocrProc, OCRMinConfidence: 50})
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	outputPath := filepath.Join(t.TempDir(), "Notes.pdf")
+	result, err := converter.ConvertRmdoc(rmdocPath, outputPath)
+	if err != nil {
+		t.Fatalf("ConvertRmdoc() error: %v", err)
+	}
+	if result.OCRWordCount != 2 {
+		t.Errorf("OCRWordCount = %d, want the 2 confident words", result.OCRWordCount)
+	}
+
+	contentDir := t.TempDir()
+	if err := api.ExtractContentFile(outputPath, contentDir, nil, nil); err != nil {
+		t.Fatalf("ExtractContentFile() error: %v", err)
+	}
+	files, _ := filepath.Glob(filepath.Join(contentDir, "*"))
+	if len(files) == 0 {
+		t.Fatal("no page content extracted")
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		content := string(data)
+		if !strings.Contains(content, "(legible) Tj") {
+			t.Errorf("%s is missing the confident word", filepath.Base(file))
+		}
+		if strings.Contains(content, "zxqv") {
+			t.Errorf("%s contains the low-confidence word", filepath.Base(file))
+		}
+	}
+}
+
+func TestNew_TextOnlyRequiresOCR(t *testing.T) {
+	if _, err := New(&Config{EnableOCR: false, OCRLanguages: []string{"eng"}, TextOnly: true}); err == nil {
+		t.Error("New() should error when TextOnly is set without OCR")
+	}
+	if _, err := New(&Config{EnableOCR: false, OCRLanguages: []string{"eng"}, MarkdownTranscript: true}); err == nil {
+		t.Error("New() should error when MarkdownTranscript is set without OCR")
+	}
+	if _, err := New(&Config{EnableOCR: false, OCRLanguages: []string{"eng"}, OCRSidecarFormat: OCRSidecarJSON}); err == nil {
+		t.Error("New() should error when OCRSidecarFormat is set without OCR")
+	}
+	if _, err := New(&Config{EnableOCR: true, OCRMinConfidence: 101}); err == nil {
+		t.Error("New() should error for an OCRMinConfidence above 100")
+	}
+	if _, err := New(&Config{EnableOCR: true, OCRSidecarFormat: "alto"}); err == nil {
+		t.Error("New() should error for an unknown OCRSidecarFormat")
+	}
+}
+
+func TestRenderPagesToPDF_FormatVersionDimensions(t *testing.T) {
+	rmSource := "../../example/b68e57f6-4fc9-4a71-b300-e0fa100ef8d7/aefd8acc-a17d-4e24-a76c-66a3ee15b4ba.rm"
+	rmData, err := os.ReadFile(rmSource)
+	if err != nil {
+		t.Skipf("Test file not found: %s", rmSource)
+	}
+
+	converter, err := New(&Config{})
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		formatVersion int
+		wantWidth     int
+		wantHeight    int
+	}{
+		{
+			name:          "reMarkable 2",
+			formatVersion: 2,
+			wantWidth:     420,
+			wantHeight:    595,
+		},
+		{
+			name:          "Paper Pro",
+			formatVersion: rmparse.FormatVersionPaperPro,
+			wantWidth:     509, // 1620px at 229 DPI
+			wantHeight:    679, // 2160px at 229 DPI
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			rmDir := filepath.Join(tmpDir, "doc")
+			if err := os.MkdirAll(rmDir, 0755); err != nil {
+				t.Fatalf("failed to create rm dir: %v", err)
+			}
+			if err := os.WriteFile(filepath.Join(rmDir, "page-1.rm"), rmData, 0644); err != nil {
+				t.Fatalf("failed to write .rm file: %v", err)
+			}
+
+			content := &ContentFile{
+				PageCount:     1,
+				FormatVersion: tt.formatVersion,
+				CPages:        CPages{Pages: []PageInfo{{ID: "page-1"}}},
+			}
+
+			outputPath := filepath.Join(tmpDir, "output.pdf")
+			if _, err := converter.renderPagesToPDF(context.Background(), rmDir, content, outputPath, nil); err != nil {
+				t.Fatalf("renderPagesToPDF() error = %v", err)
+			}
+
+			enhancer := pdfenhancer.New(&pdfenhancer.Config{})
+			info, err := enhancer.ExtractPageInfo(outputPath, 1)
+			if err != nil {
+				t.Fatalf("ExtractPageInfo() error = %v", err)
+			}
+
+			if info.Width != tt.wantWidth || info.Height != tt.wantHeight {
+				t.Errorf("page size = %dx%d, want %dx%d", info.Width, info.Height, tt.wantWidth, tt.wantHeight)
+			}
+		})
+	}
+}
+
+func TestRenderPagesToPDF_PageTemplates(t *testing.T) {
+	converter, err := New(&Config{OCRLanguages: []string{"eng"}})
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	// Pages without .rm files still get their template
+	render := func(templates ...string) int {
+		content := &ContentFile{PageCount: len(templates)}
+		for i, name := range templates {
+			page := PageInfo{ID: fmt.Sprintf("page-%d", i+1)}
+			page.Template.Value = name
+			content.CPages.Pages = append(content.CPages.Pages, page)
+		}
+
+		outputPath := filepath.Join(t.TempDir(), "output.pdf")
+		if _, err := converter.renderPagesToPDF(context.Background(), t.TempDir(), content, outputPath, nil); err != nil {
+			t.Fatalf("renderPagesToPDF() error = %v", err)
+		}
+		info, err := os.Stat(outputPath)
+		if err != nil {
+			t.Fatalf("output not written: %v", err)
+		}
+		return int(info.Size())
+	}
+
+	blank := render("Blank", "Blank")
+	mixed := render("P Grid small", "Blank")
+	unknown := render("P Calendar", "Blank")
+
+	if mixed <= blank {
+		t.Errorf("grid page output (%d bytes) should be larger than blank pages (%d bytes)", mixed, blank)
+	}
+	if unknown != blank {
+		t.Errorf("unsupported template should render blank: got %d bytes, want %d", unknown, blank)
+	}
+}
+
+func TestAdaptiveOCRDPI(t *testing.T) {
+	converter, err := New(&Config{OCRLanguages: []string{"eng"}, AdaptiveDPI: true})
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	sparse := converter.adaptiveOCRDPI(10)
+	dense := converter.adaptiveOCRDPI(400)
+	if dense <= sparse {
+		t.Errorf("dense page DPI %d should be higher than sparse page DPI %d", dense, sparse)
+	}
+
+	if got := converter.adaptiveOCRDPI(0); got != DefaultOCRMinDPI {
+		t.Errorf("empty page DPI = %d, want %d", got, DefaultOCRMinDPI)
+	}
+	if got := converter.adaptiveOCRDPI(10 * denseStrokeCount); got != pdfrender.DefaultDPI {
+		t.Errorf("very dense page DPI = %d, want %d", got, pdfrender.DefaultDPI)
+	}
+}
+
+func TestRenderPagesToPDF_StrokeCounts(t *testing.T) {
+	exampleDir := "../../example/b68e57f6-4fc9-4a71-b300-e0fa100ef8d7"
+	if _, err := os.Stat(exampleDir); os.IsNotExist(err) {
+		t.Skipf("Test files not found: %s", exampleDir)
+	}
+
+	converter, err := New(&Config{OCRLanguages: []string{"eng"}, AdaptiveDPI: true, OCRMinDPI: 100, OCRDPI: 200})
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	content := &ContentFile{
+		PageCount: 3,
+		CPages: CPages{Pages: []PageInfo{
+			{ID: "7ac5c320-e3e5-4c6c-8adc-204662ee929a"},
+			{ID: "aefd8acc-a17d-4e24-a76c-66a3ee15b4ba"},
+			{ID: "missing"},
+		}},
+	}
+
+	outputPath := filepath.Join(t.TempDir(), "output.pdf")
+	pages, err := converter.renderPagesToPDF(context.Background(), exampleDir, content, outputPath, nil)
+	if err != nil {
+		t.Fatalf("renderPagesToPDF() error = %v", err)
+	}
+
+	counts := make([]int, len(pages))
+	for i, page := range pages {
+		counts[i] = page.Strokes
+	}
+	if len(counts) != 3 || counts[0] != 3 || counts[1] != 33 || counts[2] != 0 {
+		t.Fatalf("stroke counts = %v, want [3 33 0]", counts)
+	}
+
+	// The busier page is rendered for OCR at a higher DPI within the configured bounds
+	sparse, dense := converter.adaptiveOCRDPI(counts[0]), converter.adaptiveOCRDPI(counts[1])
+	if dense <= sparse || sparse < 100 || dense > 200 {
+		t.Errorf("page DPIs = %d and %d, want 100 <= sparse < dense <= 200", sparse, dense)
+	}
+	if got := converter.adaptiveOCRDPI(counts[2]); got != 100 {
+		t.Errorf("missing page DPI = %d, want 100", got)
+	}
+}
+
+func TestRenderPagesToPDF_EmptyAndFailedPages(t *testing.T) {
+	rmData, err := os.ReadFile("../../example/b68e57f6-4fc9-4a71-b300-e0fa100ef8d7/7ac5c320-e3e5-4c6c-8adc-204662ee929a.rm")
+	if os.IsNotExist(err) {
+		t.Skip("Test files not found")
+	}
+
+	converter, err := New(&Config{OCRLanguages: []string{"eng"}})
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	// A written page, a page whose strokes were all erased, a page never written on,
+	// and a corrupt page
+	rmDir := t.TempDir()
+	files := map[string][]byte{
+		"written": rmData,
+		"erased":  []byte("reMarkable .lines file, version=6          "),
+		"corrupt": []byte("not a reMarkable page"),
+	}
+	for id, data := range files {
+		if err := os.WriteFile(filepath.Join(rmDir, id+".rm"), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	content := &ContentFile{
+		PageCount: 4,
+		CPages:    CPages{Pages: []PageInfo{{ID: "written"}, {ID: "erased"}, {ID: "unwritten"}, {ID: "corrupt"}}},
+	}
+
+	pages, err := converter.renderPagesToPDF(context.Background(), rmDir, content, filepath.Join(t.TempDir(), "output.pdf"), nil)
+	if err != nil {
+		t.Fatalf("renderPagesToPDF() error = %v", err)
+	}
+
+	want := []PageStatus{PageRendered, PageEmpty, PageEmpty, PageFailed}
+	if len(pages) != len(want) {
+		t.Fatalf("got %d page outcomes, want %d", len(pages), len(want))
+	}
+	for i, page := range pages {
+		if page.Number != i+1 || page.Status != want[i] {
+			t.Errorf("page %d = %+v, want number %d and status %s", i, page, i+1, want[i])
+		}
+		if (page.Error != "") != (want[i] == PageFailed) {
+			t.Errorf("page %d error = %q, want one only for the failed page", i+1, page.Error)
+		}
+	}
+
+	result := &ConversionResult{Pages: pages}
+	if failed := result.FailedPages(); len(failed) != 1 || failed[0].Number != 4 {
+		t.Errorf("FailedPages() = %+v, want page 4", failed)
+	}
+}
+
+// newTestConverter returns a converter with OCR disabled, after applying opts to its config.
+// Setting languages without EnableOCR disables OCR, which would otherwise need an Ollama server.
+func newTestConverter(t *testing.T, opts ...func(*Config)) *Converter {
+	t.Helper()
+
+	cfg := &Config{OCRLanguages: []string{"eng"}}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	conv, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	return conv
+}
+
+func TestConvertRmdocWithOptions_PaperSize(t *testing.T) {
+	rmdocPath := "../../example/Test.rmdoc"
+	if _, err := os.Stat(rmdocPath); os.IsNotExist(err) {
+		t.Skipf("Test file not found: %s", rmdocPath)
+	}
+
+	converter := newTestConverter(t)
+
+	tests := []struct {
+		name        string
+		paperSize   PaperSize
+		orientation Orientation
+		wantWidth   int
+		wantHeight  int
+	}{
+		{
+			name:       "default keeps device size",
+			wantWidth:  420,
+			wantHeight: 595,
+		},
+		{
+			name:        "A4 landscape",
+			paperSize:   PaperSizeA4,
+			orientation: OrientationLandscape,
+			wantWidth:   841, // 297mm
+			wantHeight:  595, // 210mm
+		},
+		{
+			name:        "A4 portrait",
+			paperSize:   PaperSizeA4,
+			orientation: OrientationPortrait,
+			wantWidth:   595,
+			wantHeight:  841,
+		},
+		{
+			name:        "reMarkable landscape",
+			paperSize:   PaperSizeRemarkable,
+			orientation: OrientationLandscape,
+			wantWidth:   595,
+			wantHeight:  420,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := &ConversionOptions{
+				InputPath:   rmdocPath,
+				OutputPath:  filepath.Join(t.TempDir(), "output.pdf"),
+				PaperSize:   tt.paperSize,
+				Orientation: tt.orientation,
+			}
+
+			result, err := converter.ConvertRmdocWithOptions(context.Background(), opts)
+			if err != nil {
+				t.Fatalf("ConvertRmdocWithOptions() error = %v", err)
+			}
+
+			enhancer := pdfenhancer.New(&pdfenhancer.Config{})
+			for page := 1; page <= result.PageCount; page++ {
+				info, err := enhancer.ExtractPageInfo(opts.OutputPath, page)
+				if err != nil {
+					t.Fatalf("ExtractPageInfo() error = %v", err)
+				}
+				if info.Width != tt.wantWidth || info.Height != tt.wantHeight {
+					t.Errorf("page %d size = %dx%d, want %dx%d", page, info.Width, info.Height, tt.wantWidth, tt.wantHeight)
+				}
+			}
+		})
+	}
+}
+
+func TestConvertRmdocWithOptions_DryRun(t *testing.T) {
+	rmdocPath := "../../example/Test.rmdoc"
+	if _, err := os.Stat(rmdocPath); os.IsNotExist(err) {
+		t.Skipf("Test file not found: %s", rmdocPath)
+	}
+
+	// Isolate temp files created during conversion so leftovers can be detected
+	tmpRoot := t.TempDir()
+	t.Setenv("TMPDIR", tmpRoot)
+
+	converter := newTestConverter(t, func(cfg *Config) { cfg.SplitByPageTag = true })
+
+	outputDir := t.TempDir()
+	opts := NewConversionOptions(rmdocPath, filepath.Join(outputDir, "output.pdf"))
+	opts.DryRun = true
+
+	result, err := converter.ConvertRmdocWithOptions(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("ConvertRmdocWithOptions() error = %v", err)
+	}
+
+	if !result.Success || !result.DryRun || result.PageCount != 2 {
+		t.Errorf("result Success = %t, DryRun = %t, PageCount = %d, want a successful 2 page dry run", result.Success, result.DryRun, result.PageCount)
+	}
+	if len(result.Pages) != 2 || result.FileSize == 0 {
+		t.Errorf("result has %d page outcomes and FileSize %d, want the stats of the discarded PDF", len(result.Pages), result.FileSize)
+	}
+	if result.OutputPath != "" || len(result.PageTagPaths) != 0 {
+		t.Errorf("result OutputPath = %q, PageTagPaths = %v, want no paths to discarded files", result.OutputPath, result.PageTagPaths)
+	}
+
+	// Nothing is written to the output directory, and nothing is left behind
+	for _, dir := range []string{outputDir, tmpRoot} {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != 0 {
+			t.Errorf("%s has %d entries after a dry run, want none", dir, len(entries))
+		}
+	}
+}
+
+func TestConvertRmdocWithOptions_NilOptions(t *testing.T) {
+	converter, err := New(&Config{})
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	if _, err := converter.ConvertRmdocWithOptions(context.Background(), nil); err == nil {
+		t.Error("ConvertRmdocWithOptions() expected error for nil options")
+	}
+}
+
+func TestPageLayout(t *testing.T) {
+	native := rmparse.RM2PageSize
+
+	// No options and the reMarkable default leave the device mapping untouched
+	for _, opts := range []*ConversionOptions{nil, NewConversionOptions("in", "out")} {
+		size, w, h := pageLayout(native, opts)
+		if size != native || w != native.PDFWidth || h != native.PDFHeight {
+			t.Errorf("pageLayout(%v) = %+v, %vx%v, want device size unchanged", opts, size, w, h)
+		}
+	}
+
+	// A4 landscape: the portrait note is scaled to the page height and centered horizontally
+	size, w, h := pageLayout(native, &ConversionOptions{PaperSize: PaperSizeA4, Orientation: OrientationLandscape})
+	if w <= h {
+		t.Fatalf("landscape page = %vx%v, want width > height", w, h)
+	}
+
+	const epsilon = 0.01
+	if diff := size.PDFHeight - h; diff > epsilon || diff < -epsilon {
+		t.Errorf("rendered height = %v, want page height %v", size.PDFHeight, h)
+	}
+	if got, want := size.PDFWidth/size.PDFHeight, native.NativeWidth/native.NativeHeight; got-want > epsilon || want-got > epsilon {
+		t.Errorf("rendered aspect ratio = %v, want %v", got, want)
+	}
+	if diff := size.OffsetX*2 + size.PDFWidth - w; diff > epsilon || diff < -epsilon {
+		t.Errorf("content not centered: offset %v, width %v on page %v", size.OffsetX, size.PDFWidth, w)
+	}
+	if size.OffsetY != 0 {
+		t.Errorf("OffsetY = %v, want 0", size.OffsetY)
+	}
+}
+
+//nolint:gocyclo // Test function with multiple validation steps
+func TestOCRPages_Concurrent(t *testing.T) {
+	var inFlight, maxInFlight, requests atomic.Int32
+
+	// Mock Ollama: each page image has a distinct width, which the server echoes
+	// back as the first word so results can be matched to their page
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			m := maxInFlight.Load()
+			if n <= m || maxInFlight.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+
+		var req ollama.GenerateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.Images) != 1 {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		data, err := base64.StdEncoding.DecodeString(req.Images[0])
+		if err != nil {
+			http.Error(w, "bad image", http.StatusBadRequest)
+			return
+		}
+		img, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			http.Error(w, "bad image", http.StatusBadRequest)
+			return
+		}
+
+		response := "not json"
+		if width := img.Bounds().Dx(); width != 300 {
+			response = fmt.Sprintf(`{"lines":[{"bbox":[0,0,40,10],"type":"text","content":"w%d abcd efgh ijkl"}]}`, width)
+		}
+		_ = json.NewEncoder(w).Encode(ollama.GenerateResponse{Model: req.Model, Response: response, Done: true})
+	}))
+	defer server.Close()
+
+	ocrProc, err := ocr.New(&ocr.Config{OllamaEndpoint: server.URL, MaxRetries: 1})
+	if err != nil {
+		t.Fatalf("ocr.New() error: %v", err)
+	}
+
+	converter, err := New(&Config{EnableOCR: true, OCRProcessor: ocrProc, OCRConcurrency: 3})
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	// Page 3 (width 300) gets an unparseable response and should be skipped
+	var images []image.Image
+	for i := 1; i <= 6; i++ {
+		images = append(images, image.NewGray(image.Rect(0, 0, 100*i, 50)))
+	}
+
+	pages, err := converter.ocrPages(context.Background(), images, &pdfenhancer.PageInfo{Width: 200, Height: 100})
+	if err != nil {
+		t.Fatalf("ocrPages() error: %v", err)
+	}
+
+	if len(pages) != len(images) {
+		t.Fatalf("ocrPages() returned %d pages, want %d", len(pages), len(images))
+	}
+	if maxInFlight.Load() < 2 {
+		t.Errorf("max concurrent requests = %d, want > 1", maxInFlight.Load())
+	}
+
+	for i, page := range pages {
+		width := 100 * (i + 1)
+		if width == 300 {
+			if page != nil {
+				t.Errorf("page %d should be skipped after OCR failure", i+1)
+			}
+			continue
+		}
+		if page == nil {
+			t.Fatalf("page %d missing OCR result", i+1)
+		}
+		if page.PageNumber != i+1 {
+			t.Errorf("page %d has PageNumber %d", i+1, page.PageNumber)
+		}
+
+		var texts []string
+		for _, word := range page.Words {
+			texts = append(texts, word.Text)
+		}
+		want := []string{fmt.Sprintf("w%d", width), "abcd", "efgh", "ijkl"}
+		if strings.Join(texts, " ") != strings.Join(want, " ") {
+			t.Errorf("page %d words = %v, want %v", i+1, texts, want)
+		}
+
+		// Coordinates are scaled per page from image pixels to PDF points
+		if page.Width != 200 || page.Height != 100 {
+			t.Errorf("page %d size = %dx%d, want 200x100", i+1, page.Width, page.Height)
+		}
+		if got, want := page.Words[1].BoundingBox.X, int(10*200/float64(width)); got != want {
+			t.Errorf("page %d second word X = %d, want %d", i+1, got, want)
+		}
+	}
+}
+
+func TestOCRPage_ClampsWordsToPage(t *testing.T) {
+	// Mock Ollama: one word inside the image, one overhanging its bottom-right corner,
+	// and two entirely outside it
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ollama.GenerateRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		response := `{"lines":[` +
+			`{"bbox":[10,10,40,20],"type":"text","content":"inside"},` +
+			`{"bbox":[80,40,120,60],"type":"text","content":"overhanging"},` +
+			`{"bbox":[150,10,180,20],"type":"text","content":"right"},` +
+			`{"bbox":[10,-30,40,-10],"type":"text","content":"above"}]}`
+		_ = json.NewEncoder(w).Encode(ollama.GenerateResponse{Model: req.Model, Response: response, Done: true})
+	}))
+	defer server.Close()
+
+	ocrProc, err := ocr.New(&ocr.Config{OllamaEndpoint: server.URL, MaxRetries: 1})
+	if err != nil {
+		t.Fatalf("ocr.New() error: %v", err)
+	}
+	converter, err := New(&Config{EnableOCR: true, OCRProcessor: ocrProc})
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	// The page is twice the image's size, so boxes scale by 2
+	img := image.NewGray(image.Rect(0, 0, 100, 50))
+	page := converter.ocrPage(context.Background(), img, 1, 1, &pdfenhancer.PageInfo{Width: 200, Height: 100})
+	if page == nil {
+		t.Fatal("ocrPage() returned no result")
+	}
+
+	want := map[string]ocr.Rectangle{
+		"inside":      ocr.NewRectangle(20, 20, 60, 20),
+		"overhanging": ocr.NewRectangle(160, 80, 40, 20),
+	}
+	if len(page.Words) != len(want) {
+		t.Fatalf("got %d words %+v, want the off-page words dropped", len(page.Words), page.Words)
+	}
+	for _, word := range page.Words {
+		if box, ok := want[word.Text]; !ok || word.BoundingBox != box {
+			t.Errorf("word %q box = %+v, want %+v", word.Text, word.BoundingBox, box)
+		}
+	}
+}
+
+func TestConverter_SetOCREnabled(t *testing.T) {
+	// A converter created without OCR has no processor to turn it on with
+	noOCR, err := New(&Config{OCRLanguages: []string{"eng"}})
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	if err := noOCR.SetOCREnabled(true); err == nil || noOCR.ocrEnabled.Load() {
+		t.Errorf("SetOCREnabled(true) error = %v, enabled = %t, want an error leaving OCR off", err, noOCR.ocrEnabled.Load())
+	}
+	if err := noOCR.SetOCREnabled(false); err != nil {
+		t.Errorf("SetOCREnabled(false) error = %v", err)
+	}
+
+	ocrProc, err := ocr.New(&ocr.Config{VisionClient: &cancellingVisionClient{cancel: func() {}}})
+	if err != nil {
+		t.Fatalf("ocr.New() error: %v", err)
+	}
+	withOCR, err := New(&Config{EnableOCR: true, OCRProcessor: ocrProc})
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	for _, enabled := range []bool{false, true} {
+		if err := withOCR.SetOCREnabled(enabled); err != nil || withOCR.ocrEnabled.Load() != enabled {
+			t.Errorf("SetOCREnabled(%t) error = %v, enabled = %t", enabled, err, withOCR.ocrEnabled.Load())
+		}
+	}
+
+	// Text-only output is made from the OCR text
+	textOnly, err := New(&Config{EnableOCR: true, OCRProcessor: ocrProc, TextOnly: true})
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	if err := textOnly.SetOCREnabled(false); err == nil || !textOnly.ocrEnabled.Load() {
+		t.Errorf("SetOCREnabled(false) with text-only output error = %v, want an error leaving OCR on", err)
+	}
+}
@@ -0,0 +1,51 @@
+package converter
+
+import (
+	"fmt"
+	"os"
+	"sort"
+)
+
+// encryptResult encrypts the PDF at outputPath and the page tag and chunk PDFs of
+// result, if the converter has a password; a PDF that fails to encrypt is removed
+// rather than left readable
+func (c *Converter) encryptResult(outputPath string, result *ConversionResult) error {
+	if c.encryptPassword == "" {
+		return nil
+	}
+	if err := c.encryptOutputs(outputPath, result.PageTagPaths, result.ChunkPaths); err != nil {
+		return err
+	}
+	result.Encrypted = true
+	return nil
+}
+
+// encryptOutputs encrypts the PDF at outputPath, the page tag PDFs in tagPaths, and the
+// chunk PDFs in chunkPaths in place with the converter's password, removing them all if
+// any fails to encrypt
+func (c *Converter) encryptOutputs(outputPath string, tagPaths map[string]string, chunkPaths []string) error {
+	paths := []string{outputPath}
+	tags := make([]string, 0, len(tagPaths))
+	for tag := range tagPaths {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	for _, tag := range tags {
+		paths = append(paths, tagPaths[tag])
+	}
+	paths = append(paths, chunkPaths...)
+
+	for _, path := range paths {
+		if err := c.pdfEnhancer.EncryptPDF(path, path, c.encryptPassword, "", 0); err != nil {
+			for _, p := range paths {
+				if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+					c.logger.WithFields("path", p, "error", err).Warn("Failed to remove unencrypted PDF")
+				}
+			}
+			return fmt.Errorf("failed to encrypt %s: %w", path, err)
+		}
+	}
+
+	c.logger.WithFields("output", outputPath, "page_tag_pdfs", len(tags), "chunk_pdfs", len(chunkPaths)).Debug("Encrypted PDFs")
+	return nil
+}
@@ -0,0 +1,49 @@
+package converter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/platinummonkey/legible/internal/pdfenhancer"
+)
+
+func TestConvertRmdoc_EncryptPassword(t *testing.T) {
+	rmdocPath := "../../example/Test.rmdoc"
+	if _, err := os.Stat(rmdocPath); os.IsNotExist(err) {
+		t.Skipf("Test file not found: %s", rmdocPath)
+	}
+
+	conv, err := New(&Config{OCRLanguages: []string{"eng"}, SplitByPageTag: true, EncryptPassword: "secret"})
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	outputPath := filepath.Join(t.TempDir(), "Test.pdf")
+	result, err := conv.ConvertRmdoc(rmdocPath, outputPath)
+	if err != nil {
+		t.Fatalf("ConvertRmdoc() error: %v", err)
+	}
+	if !result.Encrypted {
+		t.Error("Encrypted = false, want true")
+	}
+
+	// Test.rmdoc's "test" page tag is split out into a PDF of its own, encrypted too
+	tagPath := result.PageTagPaths["test"]
+	if tagPath == "" {
+		t.Fatalf("PageTagPaths = %v, want a PDF for the test tag", result.PageTagPaths)
+	}
+
+	enhancer := pdfenhancer.New(&pdfenhancer.Config{})
+	for _, path := range []string{outputPath, tagPath} {
+		if _, err := enhancer.GetPDFInfo(path); err == nil {
+			t.Errorf("%s could be read without its password", filepath.Base(path))
+		}
+		info, err := enhancer.GetPDFInfoWithPassword(path, "secret")
+		if err != nil {
+			t.Fatalf("GetPDFInfoWithPassword(%s) error = %v", filepath.Base(path), err)
+		}
+		if !info.Encrypted {
+			t.Errorf("%s is not encrypted", filepath.Base(path))
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package converter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/platinummonkey/legible/internal/ocr"
+	"github.com/platinummonkey/legible/internal/ollama"
+)
+
+func TestConvertRmdoc_OCRCache(t *testing.T) {
+	rmdocPath := "../../example/Test.rmdoc"
+	if _, err := os.Stat(rmdocPath); os.IsNotExist(err) {
+		t.Skipf("Test file not found: %s", rmdocPath)
+	}
+
+	// Mock Ollama counting the OCR requests it answers
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		var req ollama.GenerateRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		response := `{"lines":[{"bbox":[10,10,200,60],"type":"text","content":"cached words"}]}`
+		_ = json.NewEncoder(w).Encode(ollama.GenerateResponse{Model: req.Model, Response: response, Done: true})
+	}))
+	defer server.Close()
+
+	tmpDir := t.TempDir()
+	cacheDir := filepath.Join(tmpDir, "ocr-cache")
+	convert := func(name string) *ConversionResult {
+		t.Helper()
+		ocrProc, err := ocr.New(&ocr.Config{OllamaEndpoint: server.URL, MaxRetries: 1})
+		if err != nil {
+			t.Fatalf("ocr.New() error: %v", err)
+		}
+		conv, err := New(&Config{EnableOCR: true, OCRProcessor: ocrProc, OCRCacheDir: cacheDir})
+		if err != nil {
+			t.Fatalf("New() error: %v", err)
+		}
+		result, err := conv.ConvertRmdoc(rmdocPath, filepath.Join(tmpDir, name))
+		if err != nil {
+			t.Fatalf("ConvertRmdoc() error: %v", err)
+		}
+		return result
+	}
+
+	first := convert("first.pdf")
+	if !first.OCREnabled || first.OCRWordCount != 4 {
+		t.Fatalf("first run OCR'd %d words (enabled %t), want 2 per page", first.OCRWordCount, first.OCREnabled)
+	}
+	if got := requests.Load(); got != 2 {
+		t.Fatalf("first run made %d OCR requests, want one per page", got)
+	}
+
+	// The same pages render to the same images, so the second run reads the cache
+	requests.Store(0)
+	second := convert("second.pdf")
+	if got := requests.Load(); got != 0 {
+		t.Errorf("second run made %d OCR requests, want none", got)
+	}
+	if !second.OCREnabled || second.OCRWordCount != first.OCRWordCount {
+		t.Errorf("second run OCR'd %d words (enabled %t), want %d from the cache",
+			second.OCRWordCount, second.OCREnabled, first.OCRWordCount)
+	}
+}
+
+// downVisionClient is a vision client whose health check always fails
+type downVisionClient struct{}
+
+func (downVisionClient) GenerateOCR(_ context.Context, _ string, _ string) ([]ollama.OCRWord, error) {
+	return nil, errors.New("provider is down")
+}
+
+func (downVisionClient) HealthCheck(_ context.Context, _ string) error {
+	return errors.New("provider is down")
+}
+
+func (downVisionClient) Name() string { return "down" }
+
+func (downVisionClient) SupportedModels() []string { return nil }
+
+func TestConvertRmdoc_OCRMetrics(t *testing.T) {
+	rmdocPath := "../../example/Test.rmdoc"
+	if _, err := os.Stat(rmdocPath); os.IsNotExist(err) {
+		t.Skipf("Test file not found: %s", rmdocPath)
+	}
+
+	// The primary is down, so every page that isn't cached is OCR'd by the fallback
+	fallback := ocr.NewFallbackVisionClient(downVisionClient{}, staticVisionClient{}, nil)
+	ocrProc, err := ocr.New(&ocr.Config{VisionClient: fallback})
+	if err != nil {
+		t.Fatalf("ocr.New() error: %v", err)
+	}
+	// Choose the engine up front, as the daemon's startup health check does, so the
+	// cache keys, which name the engine in use, are the same for every page
+	if err := ocrProc.HealthCheck(context.Background()); err != nil {
+		t.Fatalf("HealthCheck() error: %v", err)
+	}
+
+	tmpDir := t.TempDir()
+	conv, err := New(&Config{EnableOCR: true, OCRProcessor: ocrProc, OCRCacheDir: filepath.Join(tmpDir, "ocr-cache")})
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	if _, err := conv.ConvertRmdoc(rmdocPath, filepath.Join(tmpDir, "first.pdf")); err != nil {
+		t.Fatalf("ConvertRmdoc() error: %v", err)
+	}
+	want := ocr.MetricsSnapshot{CacheMisses: 2, Fallbacks: 2}
+	if got := ocrProc.Metrics().Snapshot(); got != want {
+		t.Errorf("metrics after the first run = %+v, want %+v", got, want)
+	}
+
+	// The second run reads both pages from the cache, without asking either engine
+	if _, err := conv.ConvertRmdoc(rmdocPath, filepath.Join(tmpDir, "second.pdf")); err != nil {
+		t.Fatalf("ConvertRmdoc() error: %v", err)
+	}
+	want = ocr.MetricsSnapshot{CacheHits: 2, CacheMisses: 2, Fallbacks: 2}
+	if got := ocrProc.Metrics().Snapshot(); got != want {
+		t.Errorf("metrics after the second run = %+v, want %+v", got, want)
+	}
+}
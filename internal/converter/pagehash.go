@@ -0,0 +1,143 @@
+package converter
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+)
+
+// PageHashes returns a content hash for each page of an .rmdoc file, in page order
+//
+// A page's hash covers its template and the bytes of its .rm file, so pages with
+// equal hashes render identically. Pages without a .rm file hash as blank pages with
+// their template. Comparing hashes across versions of a document shows whether its
+// pages changed or were only reordered.
+func PageHashes(rmdocPath string) ([]string, error) {
+	r, err := zip.OpenReader(rmdocPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ZIP: %w", err)
+	}
+	defer func() { _ = r.Close() }()
+
+	return pageHashes(&r.Reader)
+}
+
+// ContentHash returns a hash of what an .rmdoc file renders: its pages, in order, and
+// any PDF or EPUB it annotates
+//
+// Unlike a hash of the file itself, it ignores the archive's timestamps and the
+// document's metadata and bookkeeping (such as the last opened page), which change on
+// every download or when a document is opened, so equal hashes mean the document
+// converts to the same output.
+func ContentHash(rmdocPath string) (string, error) {
+	r, err := zip.OpenReader(rmdocPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open ZIP: %w", err)
+	}
+	defer func() { _ = r.Close() }()
+
+	pages, err := pageHashes(&r.Reader)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	for _, page := range pages {
+		h.Write([]byte(page))
+		h.Write([]byte{0})
+	}
+
+	var bases []*zip.File
+	for _, f := range r.File {
+		if ext := path.Ext(f.Name); path.Dir(f.Name) == "." && (ext == ".pdf" || ext == ".epub") {
+			bases = append(bases, f)
+		}
+	}
+	sort.Slice(bases, func(i, j int) bool { return bases[i].Name < bases[j].Name })
+	for _, f := range bases {
+		rc, err := f.Open()
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s: %w", f.Name, err)
+		}
+		h.Write([]byte(path.Ext(f.Name)))
+		_, err = io.Copy(h, rc)
+		_ = rc.Close()
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s: %w", f.Name, err)
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// pageHashes returns the content hash of each page of an opened .rmdoc archive
+func pageHashes(r *zip.Reader) ([]string, error) {
+	content, rmFiles, err := readRmdocPages(r)
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make([]string, len(content.CPages.Pages))
+	for i, page := range content.CPages.Pages {
+		h := sha256.New()
+		h.Write([]byte(page.Template.Value))
+		h.Write([]byte{0})
+
+		if f, ok := rmFiles[page.ID]; ok {
+			data, err := readZipFile(f)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read page %d: %w", i+1, err)
+			}
+			h.Write(data)
+		}
+
+		hashes[i] = hex.EncodeToString(h.Sum(nil))
+	}
+
+	return hashes, nil
+}
+
+// readRmdocPages reads the content file of an opened .rmdoc archive and indexes its
+// .rm files by page ID
+func readRmdocPages(r *zip.Reader) (*ContentFile, map[string]*zip.File, error) {
+	var content *ContentFile
+	rmFiles := make(map[string]*zip.File)
+	for _, f := range r.File {
+		switch {
+		case path.Dir(f.Name) == "." && strings.HasSuffix(f.Name, ".content"):
+			data, err := readZipFile(f)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to read content file: %w", err)
+			}
+			content = &ContentFile{}
+			if err := json.Unmarshal(data, content); err != nil {
+				return nil, nil, fmt.Errorf("failed to parse content JSON: %w", err)
+			}
+		case strings.HasSuffix(f.Name, ".rm"):
+			rmFiles[strings.TrimSuffix(path.Base(f.Name), ".rm")] = f
+		}
+	}
+
+	if content == nil {
+		return nil, nil, fmt.Errorf("content file not found")
+	}
+
+	return content, rmFiles, nil
+}
+
+// readZipFile returns the contents of a file in a ZIP archive
+func readZipFile(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rc.Close() }()
+
+	return io.ReadAll(rc)
+}
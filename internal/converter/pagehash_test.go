@@ -0,0 +1,209 @@
+package converter
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// reorderRmdoc copies an .rmdoc to dst with its pages listed in reverse order
+func reorderRmdoc(t *testing.T, src, dst string) {
+	t.Helper()
+
+	r, err := zip.OpenReader(src)
+	if err != nil {
+		t.Fatalf("failed to open %s: %v", src, err)
+	}
+	defer func() { _ = r.Close() }()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		t.Fatalf("failed to create %s: %v", dst, err)
+	}
+	defer func() { _ = out.Close() }()
+	w := zip.NewWriter(out)
+
+	for _, f := range r.File {
+		data, err := readZipFile(f)
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", f.Name, err)
+		}
+
+		if strings.HasSuffix(f.Name, ".content") {
+			var content map[string]any
+			if err := json.Unmarshal(data, &content); err != nil {
+				t.Fatalf("failed to parse content: %v", err)
+			}
+			pages := content["cPages"].(map[string]any)["pages"].([]any)
+			for i, j := 0, len(pages)-1; i < j; i, j = i+1, j-1 {
+				pages[i], pages[j] = pages[j], pages[i]
+			}
+			if data, err = json.Marshal(content); err != nil {
+				t.Fatalf("failed to encode content: %v", err)
+			}
+		}
+
+		fw, err := w.Create(f.Name)
+		if err != nil {
+			t.Fatalf("failed to add %s: %v", f.Name, err)
+		}
+		if _, err := fw.Write(data); err != nil {
+			t.Fatalf("failed to write %s: %v", f.Name, err)
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("failed to finish %s: %v", dst, err)
+	}
+}
+
+func TestPageHashes(t *testing.T) {
+	rmdocPath := "../../example/Test.rmdoc"
+
+	hashes, err := PageHashes(rmdocPath)
+	if err != nil {
+		t.Fatalf("PageHashes() error = %v", err)
+	}
+	if len(hashes) < 2 {
+		t.Fatalf("got %d page hashes, want at least 2", len(hashes))
+	}
+	if hashes[0] == hashes[1] {
+		t.Error("pages with different strokes should hash differently")
+	}
+
+	again, err := PageHashes(rmdocPath)
+	if err != nil {
+		t.Fatalf("PageHashes() error = %v", err)
+	}
+	if strings.Join(again, ",") != strings.Join(hashes, ",") {
+		t.Error("PageHashes() should be deterministic")
+	}
+
+	// Reordering pages reorders the hashes without changing them
+	reordered := filepath.Join(t.TempDir(), "reordered.rmdoc")
+	reorderRmdoc(t, rmdocPath, reordered)
+
+	got, err := PageHashes(reordered)
+	if err != nil {
+		t.Fatalf("PageHashes() error = %v", err)
+	}
+	if len(got) != len(hashes) {
+		t.Fatalf("got %d hashes after reordering, want %d", len(got), len(hashes))
+	}
+	for i := range hashes {
+		if got[i] != hashes[len(hashes)-1-i] {
+			t.Errorf("hash of page %d = %s, want hash of original page %d", i+1, got[i], len(hashes)-i)
+		}
+	}
+}
+
+func TestPageHashes_InvalidFile(t *testing.T) {
+	if _, err := PageHashes(filepath.Join(t.TempDir(), "missing.rmdoc")); err == nil {
+		t.Error("PageHashes() should fail for a missing file")
+	}
+}
+
+func TestContentHash(t *testing.T) {
+	rmdocPath := "../../example/Test.rmdoc"
+	dir := t.TempDir()
+
+	hash, err := ContentHash(rmdocPath)
+	if err != nil {
+		t.Fatalf("ContentHash() error = %v", err)
+	}
+
+	// Re-downloading, or opening the document, rewrites timestamps and metadata only
+	touched := filepath.Join(dir, "touched.rmdoc")
+	rewriteRmdoc(t, rmdocPath, touched, func(name string, data []byte) []byte {
+		if strings.HasSuffix(name, ".metadata") {
+			return []byte(`{"visibleName":"Renamed","lastModified":"1767225600000"}`)
+		}
+		return data
+	})
+	if got, err := ContentHash(touched); err != nil || got != hash {
+		t.Errorf("ContentHash() of a re-written archive = %s, %v, want %s", got, err, hash)
+	}
+
+	// Erasing a page's strokes changes the content
+	var erased bool
+	erasedPath := filepath.Join(dir, "erased.rmdoc")
+	rewriteRmdoc(t, rmdocPath, erasedPath, func(name string, data []byte) []byte {
+		if strings.HasSuffix(name, ".rm") && !erased {
+			erased = true
+			return nil
+		}
+		return data
+	})
+	if got, err := ContentHash(erasedPath); err != nil || got == hash {
+		t.Errorf("ContentHash() of an edited document = %s, %v, want a new hash", got, err)
+	}
+
+	// So does reordering pages
+	reordered := filepath.Join(dir, "reordered.rmdoc")
+	reorderRmdoc(t, rmdocPath, reordered)
+	if got, err := ContentHash(reordered); err != nil || got == hash {
+		t.Errorf("ContentHash() of a reordered document = %s, %v, want a new hash", got, err)
+	}
+}
+
+// rewriteRmdoc copies an .rmdoc to dst with each file's contents passed through edit,
+// with new archive timestamps
+// Each extra hook receives the copied files by name and returns entries to add to the archive.
+func rewriteRmdoc(t *testing.T, src, dst string, edit func(name string, data []byte) []byte,
+	extra ...func(files map[string][]byte) map[string][]byte) {
+	t.Helper()
+
+	r, err := zip.OpenReader(src)
+	if err != nil {
+		t.Fatalf("failed to open %s: %v", src, err)
+	}
+	defer func() { _ = r.Close() }()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		t.Fatalf("failed to create %s: %v", dst, err)
+	}
+	defer func() { _ = out.Close() }()
+	w := zip.NewWriter(out)
+
+	add := func(name string, data []byte) {
+		fw, err := w.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: time.Now()})
+		if err != nil {
+			t.Fatalf("failed to add %s: %v", name, err)
+		}
+		if _, err := fw.Write(data); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	files := make(map[string][]byte, len(r.File))
+	for _, f := range r.File {
+		data, err := readZipFile(f)
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", f.Name, err)
+		}
+		files[f.Name] = edit(f.Name, data)
+		add(f.Name, files[f.Name])
+	}
+
+	for _, hook := range extra {
+		entries := hook(files)
+		names := make([]string, 0, len(entries))
+		for name := range entries {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			add(name, entries[name])
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("failed to finish %s: %v", dst, err)
+	}
+}
@@ -0,0 +1,108 @@
+package converter
+
+import (
+	"archive/zip"
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+
+	"github.com/platinummonkey/legible/internal/pdfrender"
+	"github.com/platinummonkey/legible/internal/rmrender"
+)
+
+// RenderPageImage renders one page of an .rmdoc file to an image at the given DPI,
+// without converting the rest of the document
+//
+// pageNum is 1-based. The page's strokes are drawn on a white background without its
+// template, so a page without a .rm file renders blank. At the reMarkable's native
+// 226 DPI the image is 1404x1872 pixels.
+func RenderPageImage(rmdocPath string, pageNum, dpi int) (image.Image, error) {
+	if dpi <= 0 {
+		return nil, fmt.Errorf("dpi must be positive, got %d", dpi)
+	}
+
+	r, err := zip.OpenReader(rmdocPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ZIP: %w", err)
+	}
+	defer func() { _ = r.Close() }()
+
+	content, rmFiles, err := readRmdocPages(&r.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	pages := content.CPages.Pages
+	if pageNum < 1 || pageNum > len(pages) {
+		return nil, fmt.Errorf("page %d out of range (document has %d pages)", pageNum, len(pages))
+	}
+
+	return renderPage(rmFiles[pages[pageNum-1].ID], pageNum, dpi)
+}
+
+// ExportPNG renders every page of an .rmdoc file to a PNG in outputDir, named
+// page-001.png, page-002.png, and so on, and returns the paths written
+//
+// Pages are rendered as by RenderPageImage. outputDir is created if needed.
+func ExportPNG(rmdocPath, outputDir string, dpi int) ([]string, error) {
+	if dpi <= 0 {
+		return nil, fmt.Errorf("dpi must be positive, got %d", dpi)
+	}
+
+	r, err := zip.OpenReader(rmdocPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ZIP: %w", err)
+	}
+	defer func() { _ = r.Close() }()
+
+	content, rmFiles, err := readRmdocPages(&r.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	var paths []string
+	for i, page := range content.CPages.Pages {
+		img, err := renderPage(rmFiles[page.ID], i+1, dpi)
+		if err != nil {
+			return nil, err
+		}
+		data, err := pdfrender.EncodePNG(img)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode page %d: %w", i+1, err)
+		}
+
+		path := filepath.Join(outputDir, fmt.Sprintf("page-%03d.png", i+1))
+		if err := writeFileViaTemp(path, data); err != nil {
+			return nil, fmt.Errorf("failed to write page %d: %w", i+1, err)
+		}
+		paths = append(paths, path)
+	}
+
+	return paths, nil
+}
+
+// renderPage renders the strokes of a page's .rm file, or a blank page if f is nil
+func renderPage(f *zip.File, pageNum, dpi int) (image.Image, error) {
+	doc := &rmrender.Document{}
+	if f != nil {
+		data, err := readZipFile(f)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read page %d: %w", pageNum, err)
+		}
+		if doc, err = rmrender.NewParser().Parse(data); err != nil {
+			return nil, fmt.Errorf("failed to parse page %d: %w", pageNum, err)
+		}
+	}
+
+	img, err := rmrender.NewRenderer().RenderToImage(doc, dpi)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render page %d: %w", pageNum, err)
+	}
+
+	return img, nil
+}
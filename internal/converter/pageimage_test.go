@@ -0,0 +1,81 @@
+package converter
+
+import (
+	"image/png"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestRenderPageImage(t *testing.T) {
+	rmdocPath := "../../example/Test.rmdoc"
+	if _, err := os.Stat(rmdocPath); os.IsNotExist(err) {
+		t.Skipf("Test file not found: %s", rmdocPath)
+	}
+
+	img, err := RenderPageImage(rmdocPath, 1, 113)
+	if err != nil {
+		t.Fatalf("RenderPageImage() error = %v", err)
+	}
+
+	// Half of the 1404x1872 native page
+	if got := img.Bounds().Size(); got.X != 702 || got.Y != 936 {
+		t.Errorf("image size = %v, want 702x936", got)
+	}
+
+	ink := false
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y && !ink; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if r, g, b, _ := img.At(x, y).RGBA(); r != 0xffff || g != 0xffff || b != 0xffff {
+				ink = true
+				break
+			}
+		}
+	}
+	if !ink {
+		t.Error("rendered page has no ink")
+	}
+
+	for _, page := range []int{0, 3} {
+		if _, err := RenderPageImage(rmdocPath, page, 113); err == nil {
+			t.Errorf("RenderPageImage() should reject page %d of a 2-page document", page)
+		}
+	}
+	if _, err := RenderPageImage(rmdocPath, 1, 0); err == nil {
+		t.Error("RenderPageImage() should reject a non-positive DPI")
+	}
+}
+
+func TestExportPNG(t *testing.T) {
+	rmdocPath := "../../example/Test.rmdoc"
+	if _, err := os.Stat(rmdocPath); os.IsNotExist(err) {
+		t.Skipf("Test file not found: %s", rmdocPath)
+	}
+
+	outputDir := filepath.Join(t.TempDir(), "Test")
+	paths, err := ExportPNG(rmdocPath, outputDir, 113)
+	if err != nil {
+		t.Fatalf("ExportPNG() error = %v", err)
+	}
+
+	want := []string{filepath.Join(outputDir, "page-001.png"), filepath.Join(outputDir, "page-002.png")}
+	if !reflect.DeepEqual(paths, want) {
+		t.Fatalf("ExportPNG() = %v, want %v", paths, want)
+	}
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cfg, err := png.DecodeConfig(f)
+		_ = f.Close()
+		if err != nil {
+			t.Fatalf("%s is not a PNG: %v", path, err)
+		}
+		if cfg.Width != 702 || cfg.Height != 936 {
+			t.Errorf("%s is %dx%d, want 702x936", path, cfg.Width, cfg.Height)
+		}
+	}
+}
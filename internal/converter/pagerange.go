@@ -0,0 +1,98 @@
+package converter
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ParsePageRange parses a page range spec such as "1-3,5,8-10" for a document of
+// pageCount pages, returning the selected page numbers (1-indexed) in ascending order
+//
+// A spec is a comma-separated list of pages and inclusive ranges of pages; pages
+// listed more than once are selected once. Empty parts, reversed ranges, and pages
+// outside the document are errors.
+func ParsePageRange(spec string, pageCount int) ([]int, error) {
+	if strings.TrimSpace(spec) == "" {
+		return nil, fmt.Errorf("invalid page range %q: no pages selected", spec)
+	}
+
+	selected := make(map[int]bool)
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			return nil, fmt.Errorf("invalid page range %q: empty entry", spec)
+		}
+
+		first, last := part, part
+		if i := strings.Index(part, "-"); i >= 0 {
+			first, last = strings.TrimSpace(part[:i]), strings.TrimSpace(part[i+1:])
+		}
+		from, err := parsePageNumber(first)
+		if err != nil {
+			return nil, fmt.Errorf("invalid page range %q: %w", spec, err)
+		}
+		to, err := parsePageNumber(last)
+		if err != nil {
+			return nil, fmt.Errorf("invalid page range %q: %w", spec, err)
+		}
+		if from > to {
+			return nil, fmt.Errorf("invalid page range %q: %d-%d is reversed", spec, from, to)
+		}
+		if to > pageCount {
+			return nil, fmt.Errorf("invalid page range %q: page %d is out of range, the document has %d pages", spec, to, pageCount)
+		}
+
+		for page := from; page <= to; page++ {
+			selected[page] = true
+		}
+	}
+
+	pages := make([]int, 0, len(selected))
+	for page := range selected {
+		pages = append(pages, page)
+	}
+	sort.Ints(pages)
+	return pages, nil
+}
+
+// parsePageNumber parses a 1-indexed page number of a page range
+func parsePageNumber(s string) (int, error) {
+	page, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a page number", s)
+	}
+	if page < 1 {
+		return 0, fmt.Errorf("page %d is out of range, pages start at 1", page)
+	}
+	return page, nil
+}
+
+// selectPages narrows content to the pages selected by spec, so only they are rendered
+// and OCR'd, numbered in order from 1, and drops the page tags of the other pages
+func selectPages(content *ContentFile, spec string) error {
+	numbers, err := ParsePageRange(spec, len(content.CPages.Pages))
+	if err != nil {
+		return err
+	}
+
+	pages := make([]PageInfo, len(numbers))
+	ids := make(map[string]bool, len(numbers))
+	for i, number := range numbers {
+		pages[i] = content.CPages.Pages[number-1]
+		ids[pages[i].ID] = true
+	}
+
+	var pageTags []PageTag
+	for _, pageTag := range content.PageTags {
+		if ids[pageTag.PageID] {
+			pageTags = append(pageTags, pageTag)
+		}
+	}
+
+	content.CPages.Pages = pages
+	content.PageCount = len(pages)
+	content.PageTags = pageTags
+	return nil
+}
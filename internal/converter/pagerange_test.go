@@ -0,0 +1,109 @@
+package converter
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParsePageRange(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    []int
+		wantErr string
+	}{
+		{spec: "1", want: []int{1}},
+		{spec: "1-3,5,8-10", want: []int{1, 2, 3, 5, 8, 9, 10}},
+		{spec: " 2 - 4 , 7 ", want: []int{2, 3, 4, 7}},
+		{spec: "5,1-2,2", want: []int{1, 2, 5}},
+		{spec: "10-10", want: []int{10}},
+		{spec: "", wantErr: "no pages selected"},
+		{spec: "1,,3", wantErr: "empty entry"},
+		{spec: "a-3", wantErr: `"a" is not a page number`},
+		{spec: "3-", wantErr: `"" is not a page number`},
+		{spec: "0-2", wantErr: "pages start at 1"},
+		{spec: "5-3", wantErr: "5-3 is reversed"},
+		{spec: "8-11", wantErr: "page 11 is out of range, the document has 10 pages"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := ParsePageRange(tt.spec, 10)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParsePageRange(%q) error = %v, want one containing %q", tt.spec, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParsePageRange(%q) error = %v", tt.spec, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParsePageRange(%q) = %v, want %v", tt.spec, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertRmdocWithOptions_Pages(t *testing.T) {
+	src := "../../example/Test.rmdoc"
+	if _, err := os.Stat(src); os.IsNotExist(err) {
+		t.Skipf("Test file not found: %s", src)
+	}
+
+	// Lengthen the document to six pages, alternating its two pages
+	rmdocPath := filepath.Join(t.TempDir(), "Long.rmdoc")
+	repeatPages(t, src, rmdocPath, []int{0, 1, 0, 1, 0, 1})
+
+	conv, err := New(&Config{OCRLanguages: []string{"eng"}})
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	convert := func(spec string) (*ConversionResult, []string, error) {
+		t.Helper()
+		opts := NewConversionOptions(rmdocPath, filepath.Join(t.TempDir(), "out.pdf"))
+		opts.Pages = spec
+		result, err := conv.ConvertRmdocWithOptions(t.Context(), opts)
+		if err != nil {
+			return nil, nil, err
+		}
+		return result, pdfPageContents(t, opts.OutputPath), nil
+	}
+
+	_, all, err := convert("")
+	if err != nil {
+		t.Fatalf("ConvertRmdocWithOptions() error = %v", err)
+	}
+
+	for _, tt := range []struct {
+		spec  string
+		pages []int
+	}{
+		{spec: "1-3", pages: []int{1, 2, 3}},
+		{spec: "2,4-5", pages: []int{2, 4, 5}},
+		{spec: "6", pages: []int{6}},
+	} {
+		result, got, err := convert(tt.spec)
+		if err != nil {
+			t.Fatalf("ConvertRmdocWithOptions(%q) error = %v", tt.spec, err)
+		}
+		if result.PageCount != len(tt.pages) || len(got) != len(tt.pages) {
+			t.Errorf("pages %q: PageCount = %d with %d PDF pages, want %d", tt.spec, result.PageCount, len(got), len(tt.pages))
+			continue
+		}
+		for i, page := range tt.pages {
+			if result.Pages[i].Number != i+1 {
+				t.Errorf("pages %q: page %d is numbered %d, want %d", tt.spec, page, result.Pages[i].Number, i+1)
+			}
+			if got[i] != all[page-1] {
+				t.Errorf("pages %q: PDF page %d differs from page %d of the document", tt.spec, i+1, page)
+			}
+		}
+	}
+
+	if _, _, err := convert("4-7"); err == nil || !strings.Contains(err.Error(), "out of range") {
+		t.Errorf("ConvertRmdocWithOptions(%q) error = %v, want an out of range error", "4-7", err)
+	}
+}
@@ -0,0 +1,97 @@
+package converter
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// pageTagGroups returns the 1-based page numbers of each page tag, in document order
+//
+// A page with several tags appears under each of them. Tags on pages that are not in
+// the document, and empty tag names, are ignored.
+func pageTagGroups(content *ContentFile) map[string][]int {
+	pageNumbers := make(map[string]int, len(content.CPages.Pages))
+	for i, page := range content.CPages.Pages {
+		pageNumbers[page.ID] = i + 1
+	}
+
+	tagged := make(map[string]map[int]bool)
+	for _, pageTag := range content.PageTags {
+		name := strings.TrimSpace(pageTag.Name)
+		page, ok := pageNumbers[pageTag.PageID]
+		if name == "" || !ok {
+			continue
+		}
+		if tagged[name] == nil {
+			tagged[name] = make(map[int]bool)
+		}
+		tagged[name][page] = true
+	}
+
+	groups := make(map[string][]int, len(tagged))
+	for name, pages := range tagged {
+		for page := range pages {
+			groups[name] = append(groups[name], page)
+		}
+		sort.Ints(groups[name])
+	}
+	return groups
+}
+
+// pageTagPDFPath returns the path of tag's PDF for the document at pdfPath:
+// "<name> - <tag>.pdf" in the same directory
+func pageTagPDFPath(pdfPath, tag string) string {
+	safe := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|`, r) || r < ' ' {
+			return '-'
+		}
+		return r
+	}, tag)
+	return strings.TrimSuffix(pdfPath, filepath.Ext(pdfPath)) + " - " + safe + ".pdf"
+}
+
+// splitByPageTag writes each page tag's pages of the PDF at outputPath to a PDF of
+// their own, if the converter is configured to, recording their paths on result
+func (c *Converter) splitByPageTag(outputPath string, content *ContentFile, result *ConversionResult) {
+	if !c.splitPageTags {
+		return
+	}
+	paths, err := c.writePageTagPDFs(outputPath, content)
+	if err != nil {
+		result.AddWarning(fmt.Sprintf("Failed to split pages by tag: %v", err))
+	}
+	result.PageTagPaths = paths
+}
+
+// writePageTagPDFs writes each page tag's pages of the PDF at pdfPath to a PDF of
+// their own next to it, and returns the paths written by tag
+//
+// The pages are copied from the converted PDF, so they keep any OCR text layer.
+func (c *Converter) writePageTagPDFs(pdfPath string, content *ContentFile) (map[string]string, error) {
+	groups := pageTagGroups(content)
+	if len(groups) == 0 {
+		return nil, nil
+	}
+
+	tags := make([]string, 0, len(groups))
+	for tag := range groups {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	paths := make(map[string]string, len(tags))
+	for _, tag := range tags {
+		path := pageTagPDFPath(pdfPath, tag)
+		if err := c.pdfEnhancer.ReorderPages(pdfPath, path, groups[tag]); err != nil {
+			_ = os.Remove(path)
+			return paths, fmt.Errorf("failed to write pages tagged %q: %w", tag, err)
+		}
+		paths[tag] = path
+		c.logger.WithFields("tag", tag, "pages", groups[tag], "output", path).Debug("Wrote page tag PDF")
+	}
+
+	return paths, nil
+}
@@ -0,0 +1,129 @@
+package converter
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+)
+
+func TestPageTagGroups(t *testing.T) {
+	content := &ContentFile{
+		CPages: CPages{Pages: []PageInfo{{ID: "p1"}, {ID: "p2"}, {ID: "p3"}}},
+		PageTags: []PageTag{
+			{Name: "work", PageID: "p3"},
+			{Name: "work", PageID: "p1"},
+			{Name: "ideas", PageID: "p3"},
+			{Name: "work", PageID: "p1"},
+			{Name: " ", PageID: "p2"},
+			{Name: "deleted", PageID: "p9"},
+		},
+	}
+
+	want := map[string][]int{"work": {1, 3}, "ideas": {3}}
+	if got := pageTagGroups(content); !reflect.DeepEqual(got, want) {
+		t.Errorf("pageTagGroups() = %v, want %v", got, want)
+	}
+}
+
+func TestPageTagPDFPath(t *testing.T) {
+	if got, want := pageTagPDFPath("/out/Notes.pdf", "a/b: c"), "/out/Notes - a-b- c.pdf"; got != want {
+		t.Errorf("pageTagPDFPath() = %q, want %q", got, want)
+	}
+}
+
+// pdfPageContents returns the content stream of each page of the PDF at pdfPath
+func pdfPageContents(t *testing.T, pdfPath string) []string {
+	t.Helper()
+	outDir := t.TempDir()
+	if err := api.ExtractContentFile(pdfPath, outDir, nil, nil); err != nil {
+		t.Fatalf("ExtractContentFile(%s) error = %v", pdfPath, err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(outDir, "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	contents := make([]string, len(files))
+	for _, file := range files {
+		var page int
+		name := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+		if _, err := fmt.Sscanf(name[strings.LastIndex(name, "_")+1:], "%d", &page); err != nil || page < 1 || page > len(files) {
+			t.Fatalf("unexpected content file %s", file)
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		contents[page-1] = string(data)
+	}
+	return contents
+}
+
+func TestConvertRmdoc_SplitByPageTag(t *testing.T) {
+	src := "../../example/Test.rmdoc"
+	if _, err := os.Stat(src); os.IsNotExist(err) {
+		t.Skipf("Test file not found: %s", src)
+	}
+
+	// Tag the first page "work" and the second both "work" and "ideas"
+	tmpDir := t.TempDir()
+	rmdocPath := filepath.Join(tmpDir, "Tagged.rmdoc")
+	rewriteRmdoc(t, src, rmdocPath, func(name string, data []byte) []byte {
+		if !strings.HasSuffix(name, ".content") {
+			return data
+		}
+		var content map[string]interface{}
+		if err := json.Unmarshal(data, &content); err != nil {
+			t.Fatal(err)
+		}
+		pages := content["cPages"].(map[string]interface{})["pages"].([]interface{})
+		first := pages[0].(map[string]interface{})["id"]
+		second := pages[1].(map[string]interface{})["id"]
+		content["pageTags"] = []map[string]interface{}{
+			{"name": "work", "pageId": first, "timestamp": 1},
+			{"name": "work", "pageId": second, "timestamp": 2},
+			{"name": "ideas", "pageId": second, "timestamp": 3},
+		}
+		out, err := json.Marshal(content)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return out
+	})
+
+	conv, err := New(&Config{EnableOCR: false, OCRLanguages: []string{}, SplitByPageTag: true})
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	outputPath := filepath.Join(tmpDir, "Notes.pdf")
+	result, err := conv.ConvertRmdoc(rmdocPath, outputPath)
+	if err != nil {
+		t.Fatalf("ConvertRmdoc() error: %v", err)
+	}
+
+	wantPaths := map[string]string{
+		"work":  filepath.Join(tmpDir, "Notes - work.pdf"),
+		"ideas": filepath.Join(tmpDir, "Notes - ideas.pdf"),
+	}
+	if !reflect.DeepEqual(result.PageTagPaths, wantPaths) {
+		t.Fatalf("PageTagPaths = %v, want %v", result.PageTagPaths, wantPaths)
+	}
+
+	// Each tag's PDF holds copies of its pages of the full PDF
+	full := pdfPageContents(t, outputPath)
+	if len(full) != 2 || full[0] == full[1] {
+		t.Fatalf("expected two distinct pages in the full PDF, got %d", len(full))
+	}
+	if got := pdfPageContents(t, wantPaths["work"]); !reflect.DeepEqual(got, full) {
+		t.Error("work PDF does not hold both pages in order")
+	}
+	if got := pdfPageContents(t, wantPaths["ideas"]); !reflect.DeepEqual(got, full[1:]) {
+		t.Error("ideas PDF does not hold only the second page")
+	}
+}
@@ -0,0 +1,101 @@
+package converter
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/signintech/gopdf"
+)
+
+// writeRenderedPDF writes a rendered PDF to outputPath
+//
+// The document is compiled to memory once, since gopdf cannot compile the same
+// document twice, and written by writePDFData.
+func (c *Converter) writeRenderedPDF(pdf *gopdf.GoPdf, outputPath string) error {
+	data, err := pdf.GetBytesPdfReturnErr()
+	if err != nil {
+		return fmt.Errorf("failed to compile PDF: %w", err)
+	}
+
+	return c.writePDFData(data, pdf.GetNumberOfPages(), outputPath)
+}
+
+// writePDFData writes the bytes of a PDF with the given number of pages to outputPath
+//
+// If writing the file fails, the bytes are written again through a temporary file in
+// the output directory that is renamed into place, so a transient failure doesn't
+// discard the rendered pages.
+func (c *Converter) writePDFData(data []byte, pages int, outputPath string) error {
+	writeErr := c.writeFile(outputPath, data, 0644)
+	if writeErr == nil {
+		return nil
+	}
+
+	c.logger.WithFields(c.writeDiagnostics(outputPath, len(data), pages, writeErr)...).
+		Warn("Failed to write PDF, retrying through a temporary file")
+
+	if err := writeFileViaTemp(outputPath, data); err != nil {
+		c.logger.WithFields(c.writeDiagnostics(outputPath, len(data), pages, err)...).
+			Error("Fallback PDF write failed")
+		return fmt.Errorf("failed to write PDF: %w (fallback also failed: %v)", writeErr, err)
+	}
+
+	c.logger.WithFields("output", outputPath, "bytes", len(data)).Info("Wrote PDF using fallback")
+	return nil
+}
+
+// writeDiagnostics returns log fields describing a failed write of a PDF to outputPath
+func (c *Converter) writeDiagnostics(outputPath string, size, pages int, err error) []interface{} {
+	dir := filepath.Dir(outputPath)
+	fields := []interface{}{"output", outputPath, "bytes", size, "pages", pages, "error", err}
+
+	info, statErr := os.Stat(dir)
+	switch {
+	case statErr != nil:
+		fields = append(fields, "dir_error", statErr)
+	case !info.IsDir():
+		fields = append(fields, "dir_error", "not a directory")
+	default:
+		fields = append(fields, "dir_mode", info.Mode().String())
+	}
+
+	return fields
+}
+
+// writeFileViaTemp writes data to a temporary file beside path, creating the directory
+// if needed, and renames it to path once fully written
+func writeFileViaTemp(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer func() { _ = os.Remove(tmpPath) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to sync temporary file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close temporary file: %w", err)
+	}
+	if err := os.Chmod(tmpPath, 0644); err != nil {
+		return fmt.Errorf("failed to set file permissions: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to move temporary file into place: %w", err)
+	}
+
+	return nil
+}
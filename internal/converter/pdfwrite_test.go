@@ -0,0 +1,87 @@
+package converter
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+)
+
+func TestConvertRmdoc_WriteFallback(t *testing.T) {
+	rmdocPath := "../../example/Test.rmdoc"
+	if _, err := os.Stat(rmdocPath); os.IsNotExist(err) {
+		t.Skipf("Test file not found: %s", rmdocPath)
+	}
+
+	conv, err := New(&Config{OCRLanguages: []string{"eng"}})
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	attempts := 0
+	conv.writeFile = func(string, []byte, os.FileMode) error {
+		attempts++
+		return errors.New("simulated write failure")
+	}
+
+	outputPath := filepath.Join(t.TempDir(), "output.pdf")
+	result, err := conv.ConvertRmdoc(rmdocPath, outputPath)
+	if err != nil {
+		t.Fatalf("ConvertRmdoc() error = %v, want fallback to succeed", err)
+	}
+	if attempts != 1 {
+		t.Errorf("primary write attempted %d times, want 1", attempts)
+	}
+	if result.PageCount != 2 {
+		t.Errorf("PageCount = %d, want 2", result.PageCount)
+	}
+
+	count, err := api.PageCountFile(outputPath)
+	if err != nil {
+		t.Fatalf("fallback output is not a valid PDF: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("fallback PDF has %d pages, want 2", count)
+	}
+
+	// No temporary files are left beside the output
+	entries, err := os.ReadDir(filepath.Dir(outputPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("output directory has %d entries, want only the PDF", len(entries))
+	}
+}
+
+func TestConvertRmdoc_WriteFallbackFails(t *testing.T) {
+	rmdocPath := "../../example/Test.rmdoc"
+	if _, err := os.Stat(rmdocPath); os.IsNotExist(err) {
+		t.Skipf("Test file not found: %s", rmdocPath)
+	}
+
+	conv, err := New(&Config{OCRLanguages: []string{"eng"}})
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	conv.writeFile = func(string, []byte, os.FileMode) error {
+		return errors.New("simulated write failure")
+	}
+
+	// A file where the output directory should be makes the fallback fail too
+	blocker := filepath.Join(t.TempDir(), "blocker")
+	if err := os.WriteFile(blocker, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = conv.ConvertRmdoc(rmdocPath, filepath.Join(blocker, "output.pdf"))
+	if err == nil {
+		t.Fatal("ConvertRmdoc() should fail when both writes fail")
+	}
+	if !strings.Contains(err.Error(), "simulated write failure") || !strings.Contains(err.Error(), "fallback") {
+		t.Errorf("error = %v, want both the primary and fallback failures", err)
+	}
+}
@@ -0,0 +1,122 @@
+package converter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// renderCacheFormatVersion is part of every render cache key; bump it when the way
+// strokes or templates are drawn changes, so pages rendered the old way are no longer used
+const renderCacheFormatVersion = "1"
+
+// renderCache stores rendered pages on disk as single page PDFs, keyed by a hash of the
+// page's .rm file, template, and layout (see renderCacheKey)
+//
+// Each entry is a PDF and a JSON file with the page's outcome, named after its key. The
+// PDF is written first, so an entry whose JSON file exists is complete. Entries are never
+// expired, so the directory can be deleted at any time to clear it.
+type renderCache struct {
+	dir string
+}
+
+// renderCacheEntry is the outcome of a cached page, stored next to its PDF
+type renderCacheEntry struct {
+	Status  PageStatus `json:"status"`
+	Strokes int        `json:"strokes"`
+}
+
+// path returns the file of a cache key with extension ext, in a subdirectory named
+// after the key's first two characters to keep directories small
+func (rc *renderCache) path(key, ext string) string {
+	return filepath.Join(rc.dir, key[:2], key+ext)
+}
+
+// get returns the PDF and outcome of the page cached under key, or "" if there is none
+func (rc *renderCache) get(key string) (string, *renderCacheEntry, error) {
+	data, err := os.ReadFile(rc.path(key, ".json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil, nil
+	}
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read cached page: %w", err)
+	}
+
+	var entry renderCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return "", nil, fmt.Errorf("failed to parse cached page: %w", err)
+	}
+
+	pdfPath := rc.path(key, ".pdf")
+	if _, err := os.Stat(pdfPath); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", nil, nil
+		}
+		return "", nil, fmt.Errorf("failed to read cached page: %w", err)
+	}
+	return pdfPath, &entry, nil
+}
+
+// put stores the single page PDF at pdfPath and its outcome under key, replacing any
+// previous entry
+func (rc *renderCache) put(key, pdfPath string, entry *renderCacheEntry) error {
+	pdfData, err := os.ReadFile(pdfPath)
+	if err != nil {
+		return fmt.Errorf("failed to read rendered page: %w", err)
+	}
+	entryData, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal page outcome: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(rc.path(key, "")), 0755); err != nil {
+		return fmt.Errorf("failed to create render cache directory: %w", err)
+	}
+	if err := rc.write(rc.path(key, ".pdf"), pdfData); err != nil {
+		return err
+	}
+	return rc.write(rc.path(key, ".json"), entryData)
+}
+
+// write writes data to path under a temporary name and renames it into place, so
+// concurrent readers never see a partial file
+func (rc *renderCache) write(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create render cache file: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write render cache file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write render cache file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write render cache file: %w", err)
+	}
+	return nil
+}
+
+// renderCacheKey returns the render cache key of a page: a hash of its .rm file's bytes,
+// its template, and the layout it's drawn in, so pages with equal keys render identically
+func renderCacheKey(rmData []byte, template string, layout *pageRenderLayout) string {
+	h := sha256.New()
+	for _, part := range []string{
+		renderCacheFormatVersion,
+		template,
+		fmt.Sprintf("%+v", layout.pageSize),
+		fmt.Sprintf("%gx%g", layout.width, layout.height),
+	} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	h.Write(rmData)
+	return hex.EncodeToString(h.Sum(nil))
+}
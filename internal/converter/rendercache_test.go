@@ -0,0 +1,77 @@
+package converter
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/platinummonkey/legible/internal/rmparse"
+)
+
+func TestConvertRmdoc_RenderCache(t *testing.T) {
+	rmdocPath := "../../example/Test.rmdoc"
+	if _, err := os.Stat(rmdocPath); os.IsNotExist(err) {
+		t.Skipf("Test file not found: %s", rmdocPath)
+	}
+
+	tmpDir := t.TempDir()
+	cacheDir := filepath.Join(tmpDir, "render-cache")
+	convert := func(name string) (*ConversionResult, []string) {
+		t.Helper()
+		conv, err := New(&Config{OCRLanguages: []string{"eng"}, RenderCacheDir: cacheDir})
+		if err != nil {
+			t.Fatalf("New() error: %v", err)
+		}
+		outputPath := filepath.Join(tmpDir, name)
+		result, err := conv.ConvertRmdoc(rmdocPath, outputPath)
+		if err != nil {
+			t.Fatalf("ConvertRmdoc() error: %v", err)
+		}
+		return result, pdfPageContents(t, outputPath)
+	}
+
+	first, firstPages := convert("first.pdf")
+	if len(first.Pages) != 2 {
+		t.Fatalf("first run has %d pages, want 2", len(first.Pages))
+	}
+	for _, page := range first.Pages {
+		if page.Cached {
+			t.Errorf("page %d was cached on the first run, want it rendered", page.Number)
+		}
+	}
+
+	// The same .rm files hash the same, so the second run merges the cached pages
+	second, secondPages := convert("second.pdf")
+	for i, page := range second.Pages {
+		if !page.Cached {
+			t.Errorf("page %d was rendered again on the second run, want it from the cache", page.Number)
+		}
+		if page.Status != first.Pages[i].Status || page.Strokes != first.Pages[i].Strokes {
+			t.Errorf("cached page %d = %+v, want the outcome of %+v", page.Number, page, first.Pages[i])
+		}
+	}
+	if !reflect.DeepEqual(secondPages, firstPages) {
+		t.Error("pages read from the render cache differ from the rendered pages")
+	}
+}
+
+func TestRenderCacheKey(t *testing.T) {
+	native := rmparse.PageSizeForFormatVersion(0)
+	layout := newPageRenderLayout(native, nil)
+	key := renderCacheKey([]byte("strokes"), "Blank", layout)
+
+	if got := renderCacheKey([]byte("strokes"), "Blank", layout); got != key {
+		t.Error("renderCacheKey() differs for the same page")
+	}
+	if renderCacheKey([]byte("other strokes"), "Blank", layout) == key {
+		t.Error("renderCacheKey() ignores the .rm file")
+	}
+	if renderCacheKey([]byte("strokes"), "P Lines medium", layout) == key {
+		t.Error("renderCacheKey() ignores the template")
+	}
+	landscape := newPageRenderLayout(native, &ConversionOptions{Orientation: OrientationLandscape})
+	if renderCacheKey([]byte("strokes"), "Blank", landscape) == key {
+		t.Error("renderCacheKey() ignores the page layout")
+	}
+}
@@ -0,0 +1,110 @@
+package converter
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// renderPageFiles renders pages with up to c.renderConcurrency workers, each page to a
+// single page PDF of its own (or read from the render cache), and merges them into
+// outputPath in page order
+func (c *Converter) renderPageFiles(ctx context.Context, rmDir string, content *ContentFile, outputPath string, layout *pageRenderLayout) ([]PageOutcome, error) {
+	tmpDir, err := os.MkdirTemp("", "rmdoc-pages-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	pageInfos := content.CPages.Pages
+	pages := make([]PageOutcome, len(pageInfos))
+	paths := make([]string, len(pageInfos))
+	errs := make([]error, len(pageInfos))
+
+	workers := min(c.renderConcurrency, len(pageInfos))
+	c.logger.WithFields("pages", len(pageInfos), "workers", workers).Debug("Rendering pages to separate PDFs")
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				paths[i], pages[i], errs[i] = c.renderPageFile(tmpDir, rmDir, i+1, pageInfos[i], layout)
+			}
+		}()
+	}
+
+feed:
+	for i := range pageInfos {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("failed to write page %d: %w", i+1, err)
+		}
+	}
+
+	merged := filepath.Join(tmpDir, "merged.pdf")
+	if err := c.pdfEnhancer.MergePDFs(paths, merged); err != nil {
+		return nil, fmt.Errorf("failed to merge pages: %w", err)
+	}
+	data, err := os.ReadFile(merged)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read merged PDF: %w", err)
+	}
+	if err := c.writePDFData(data, len(pages), outputPath); err != nil {
+		return nil, err
+	}
+
+	return pages, nil
+}
+
+// renderPageFile renders page number of the document, described by pageInfo, to a single
+// page PDF in dir and returns its path and outcome
+//
+// With a render cache, a page whose .rm file, template, and layout match a cached page
+// is merged from the cache instead of being rendered, and rendered pages are cached.
+// Pages without a .rm file and failed pages aren't cached.
+func (c *Converter) renderPageFile(dir, rmDir string, number int, pageInfo PageInfo, layout *pageRenderLayout) (string, PageOutcome, error) {
+	var key string
+	if c.renderCache != nil {
+		if rmData, err := os.ReadFile(filepath.Join(rmDir, pageInfo.ID+".rm")); err == nil {
+			key = renderCacheKey(rmData, pageInfo.Template.Value, layout)
+			path, entry, err := c.renderCache.get(key)
+			if err != nil {
+				c.logger.WithFields("page", number, "error", err).Warn("Failed to read render cache, rendering page")
+			} else if entry != nil {
+				c.logger.WithFields("page", number, "key", key[:12]).Debug("Using cached page")
+				return path, PageOutcome{Number: number, Status: entry.Status, Strokes: entry.Strokes, Cached: true}, nil
+			}
+		}
+	}
+
+	pdf := layout.newPDF()
+	page := c.renderPage(pdf, rmDir, number, pageInfo, layout)
+	path := filepath.Join(dir, fmt.Sprintf("page-%05d.pdf", number))
+	if err := pdf.WritePdf(path); err != nil {
+		return "", page, err
+	}
+
+	if key != "" && page.Status != PageFailed {
+		if err := c.renderCache.put(key, path, &renderCacheEntry{Status: page.Status, Strokes: page.Strokes}); err != nil {
+			c.logger.WithFields("page", number, "error", err).Warn("Failed to cache rendered page")
+		}
+	}
+	return path, page, nil
+}
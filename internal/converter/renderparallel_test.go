@@ -0,0 +1,81 @@
+package converter
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestConvertRmdoc_RenderConcurrency(t *testing.T) {
+	src := "../../example/Test.rmdoc"
+	if _, err := os.Stat(src); os.IsNotExist(err) {
+		t.Skipf("Test file not found: %s", src)
+	}
+
+	// Lengthen the document to seven pages by repeating its two pages out of order, so
+	// a page merged out of place shows
+	order := []int{0, 1, 1, 0, 1, 0, 0}
+	rmdocPath := filepath.Join(t.TempDir(), "Long.rmdoc")
+	repeatPages(t, src, rmdocPath, order)
+
+	convert := func(concurrency int) (*ConversionResult, []string) {
+		t.Helper()
+		conv, err := New(&Config{OCRLanguages: []string{"eng"}, RenderConcurrency: concurrency})
+		if err != nil {
+			t.Fatalf("New() error: %v", err)
+		}
+		outputPath := filepath.Join(t.TempDir(), "Long.pdf")
+		result, err := conv.ConvertRmdoc(rmdocPath, outputPath)
+		if err != nil {
+			t.Fatalf("ConvertRmdoc() with render concurrency %d error: %v", concurrency, err)
+		}
+		return result, pdfPageContents(t, outputPath)
+	}
+
+	serialResult, serial := convert(1)
+	if len(serial) != len(order) || serial[0] == serial[1] {
+		t.Fatalf("serial PDF has %d pages, want %d with distinct first and second pages", len(serial), len(order))
+	}
+
+	parallelResult, parallel := convert(3)
+	if serialResult.PageCount != parallelResult.PageCount {
+		t.Errorf("PageCount = %d in parallel, want %d as rendered serially", parallelResult.PageCount, serialResult.PageCount)
+	}
+	if !reflect.DeepEqual(parallel, serial) {
+		t.Error("pages rendered in parallel differ from, or are out of the order of, the pages rendered serially")
+	}
+	if !reflect.DeepEqual(parallelResult.Pages, serialResult.Pages) {
+		t.Errorf("page outcomes = %+v in parallel, want %+v", parallelResult.Pages, serialResult.Pages)
+	}
+}
+
+// repeatPages writes the .rmdoc at src to dst with its pages replaced by the pages of
+// src at the 0-based indexes in order, repeating them to make longer documents
+func repeatPages(t *testing.T, src, dst string, order []int) {
+	t.Helper()
+	rewriteRmdoc(t, src, dst, func(name string, data []byte) []byte {
+		if !strings.HasSuffix(name, ".content") {
+			return data
+		}
+		var content map[string]interface{}
+		if err := json.Unmarshal(data, &content); err != nil {
+			t.Fatal(err)
+		}
+		cPages := content["cPages"].(map[string]interface{})
+		pages := cPages["pages"].([]interface{})
+		long := make([]interface{}, len(order))
+		for i, page := range order {
+			long[i] = pages[page]
+		}
+		cPages["pages"] = long
+		content["pageCount"] = len(long)
+		out, err := json.Marshal(content)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return out
+	})
+}
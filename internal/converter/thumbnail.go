@@ -0,0 +1,99 @@
+package converter
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+)
+
+// thumbnailsDirSuffix ends the name of the .rmdoc directory holding page previews,
+// <document id>.thumbnails, with one image per page named after the page's ID
+const thumbnailsDirSuffix = ".thumbnails"
+
+// findThumbnail returns the path of the extracted thumbnail of a document's first
+// page, or "" if the .rmdoc has none; thumbnails are PNG, or JPEG from older software
+func findThumbnail(extractDir string, content *ContentFile) string {
+	if len(content.CPages.Pages) == 0 {
+		return ""
+	}
+	pageID := content.CPages.Pages[0].ID
+
+	dirs, err := filepath.Glob(filepath.Join(extractDir, "*"+thumbnailsDirSuffix))
+	if err != nil {
+		return ""
+	}
+	for _, dir := range dirs {
+		for _, ext := range []string{".png", ".jpg"} {
+			path := filepath.Join(dir, pageID+ext)
+			if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() {
+				return path
+			}
+		}
+	}
+	return ""
+}
+
+// addThumbnail embeds the tablet's preview of the first page, extracted to
+// extractDir, as the thumbnail of the PDF at outputPath
+func (c *Converter) addThumbnail(outputPath, extractDir string, content *ContentFile, result *ConversionResult) {
+	thumbPath := findThumbnail(extractDir, content)
+	if thumbPath == "" {
+		return
+	}
+	if err := c.embedThumbnail(outputPath, thumbPath); err != nil {
+		result.AddWarning(fmt.Sprintf("Failed to embed thumbnail: %v", err))
+		return
+	}
+	result.Thumbnail = true
+	c.logger.WithFields("thumbnail", filepath.Base(thumbPath)).Debug("Embedded first page thumbnail")
+}
+
+// embedThumbnail sets the image at thumbPath as the thumbnail of the PDF's first
+// page, which viewers show as the document's preview
+func (c *Converter) embedThumbnail(pdfPath, thumbPath string) error {
+	ctx, err := api.ReadContextFile(pdfPath)
+	if err != nil {
+		return fmt.Errorf("failed to read PDF: %w", err)
+	}
+
+	f, err := os.Open(thumbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open thumbnail: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	thumb, _, _, err := model.CreateImageResource(ctx.XRefTable, f)
+	if err != nil {
+		return fmt.Errorf("failed to read thumbnail image: %w", err)
+	}
+
+	pageDict, _, _, err := ctx.PageDict(1, false)
+	if err != nil {
+		return fmt.Errorf("failed to get first page: %w", err)
+	}
+	if pageDict == nil {
+		return fmt.Errorf("page dictionary is nil")
+	}
+	pageDict.Update("Thumb", *thumb)
+
+	// Write to a temp file in the same directory and replace the original
+	tmpFile, err := os.CreateTemp(filepath.Dir(pdfPath), "pdf-thumbnail-*.pdf")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	tmpPath := tmpFile.Name()
+	_ = tmpFile.Close()
+	defer func() { _ = os.Remove(tmpPath) }()
+
+	if err := api.WriteContextFile(ctx, tmpPath); err != nil {
+		return fmt.Errorf("failed to write PDF: %w", err)
+	}
+	if err := os.Rename(tmpPath, pdfPath); err != nil {
+		return fmt.Errorf("failed to replace original file: %w", err)
+	}
+
+	return nil
+}
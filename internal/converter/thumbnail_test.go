@@ -0,0 +1,134 @@
+package converter
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// Size of the thumbnail added by thumbnailRmdoc, the size the tablet writes
+const (
+	testThumbnailWidth  = 280
+	testThumbnailHeight = 374
+)
+
+// thumbnailRmdoc copies an .rmdoc to dst, adding a thumbnail of its first page the way
+// the tablet stores them
+func thumbnailRmdoc(t *testing.T, src, dst string) {
+	t.Helper()
+
+	unchanged := func(_ string, data []byte) []byte { return data }
+	rewriteRmdoc(t, src, dst, unchanged, func(files map[string][]byte) map[string][]byte {
+		// The thumbnail is named after the first page's ID
+		var content []byte
+		var docID string
+		for name, data := range files {
+			if strings.HasSuffix(name, ".content") {
+				content, docID = data, strings.TrimSuffix(name, ".content")
+			}
+		}
+		var parsed ContentFile
+		if err := json.Unmarshal(content, &parsed); err != nil || len(parsed.CPages.Pages) == 0 {
+			t.Fatalf("failed to find the first page of %s: %v", src, err)
+		}
+
+		img := image.NewGray(image.Rect(0, 0, testThumbnailWidth, testThumbnailHeight))
+		for i := range img.Pix {
+			img.Pix[i] = 0xff
+		}
+		for x := 20; x < testThumbnailWidth-20; x++ {
+			img.SetGray(x, testThumbnailHeight/2, color.Gray{})
+		}
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, img); err != nil {
+			t.Fatalf("failed to encode thumbnail: %v", err)
+		}
+		return map[string][]byte{docID + thumbnailsDirSuffix + "/" + parsed.CPages.Pages[0].ID + ".png": buf.Bytes()}
+	})
+}
+
+// firstPageThumbnail returns the thumbnail of the first page of the PDF at pdfPath, or
+// nil if it has none
+func firstPageThumbnail(t *testing.T, pdfPath string) *types.StreamDict {
+	t.Helper()
+
+	ctx, err := api.ReadContextFile(pdfPath)
+	if err != nil {
+		t.Fatalf("failed to read PDF: %v", err)
+	}
+	pageDict, _, _, err := ctx.PageDict(1, false)
+	if err != nil {
+		t.Fatalf("failed to get first page: %v", err)
+	}
+	thumb, ok := pageDict.Find("Thumb")
+	if !ok {
+		return nil
+	}
+	sd, _, err := ctx.DereferenceStreamDict(thumb)
+	if err != nil {
+		t.Fatalf("failed to read thumbnail: %v", err)
+	}
+	return sd
+}
+
+func TestConvertRmdoc_Thumbnail(t *testing.T) {
+	src := "../../example/Test.rmdoc"
+	if _, err := os.Stat(src); os.IsNotExist(err) {
+		t.Skipf("Test file not found: %s", src)
+	}
+	withThumbnail := filepath.Join(t.TempDir(), "thumbnail.rmdoc")
+	thumbnailRmdoc(t, src, withThumbnail)
+
+	for _, tt := range []struct {
+		name          string
+		rmdocPath     string
+		wantThumbnail bool
+	}{
+		{name: "with thumbnail", rmdocPath: withThumbnail, wantThumbnail: true},
+		{name: "without thumbnail", rmdocPath: src, wantThumbnail: false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			conv := newTestConverter(t)
+
+			outputPath := filepath.Join(t.TempDir(), "output.pdf")
+			result, err := conv.ConvertRmdoc(tt.rmdocPath, outputPath)
+			if err != nil {
+				t.Fatalf("ConvertRmdoc() error = %v", err)
+			}
+			if result.Thumbnail != tt.wantThumbnail || len(result.Warnings) != 0 {
+				t.Errorf("result Thumbnail = %t, Warnings = %v, want Thumbnail = %t and no warnings",
+					result.Thumbnail, result.Warnings, tt.wantThumbnail)
+			}
+			if result.PageCount != 2 {
+				t.Errorf("result PageCount = %d, want 2", result.PageCount)
+			}
+
+			thumb := firstPageThumbnail(t, outputPath)
+			if !tt.wantThumbnail {
+				if thumb != nil {
+					t.Error("PDF has a thumbnail, want none")
+				}
+				return
+			}
+			if thumb == nil {
+				t.Fatal("PDF has no thumbnail")
+			}
+			width, height := thumb.IntEntry("Width"), thumb.IntEntry("Height")
+			if width == nil || height == nil || *width != testThumbnailWidth || *height != testThumbnailHeight {
+				t.Errorf("thumbnail is %v x %v, want %d x %d", width, height, testThumbnailWidth, testThumbnailHeight)
+			}
+			if err := conv.validatePDFWithPdfcpu(outputPath); err != nil {
+				t.Errorf("PDF with thumbnail is invalid: %v", err)
+			}
+		})
+	}
+}
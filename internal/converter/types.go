@@ -1,6 +1,10 @@
 package converter
 
-import "time"
+import (
+	"fmt"
+	"strings"
+	"time"
+)
 
 // ConversionOptions holds configuration for PDF conversion
 type ConversionOptions struct {
@@ -51,6 +55,14 @@ type ConversionOptions struct {
 
 	// OCRLanguages is the list of language codes to use for OCR via Ollama (default: ["eng"])
 	OCRLanguages []string
+
+	// Pages selects the pages to convert, such as "1-3,5,8-10" (see ParsePageRange);
+	// they're written in document order and numbered from 1. Empty converts every page.
+	Pages string
+
+	// DryRun runs the whole conversion, including OCR, but discards the PDF and any
+	// files written alongside it, so OutputPath is never written
+	DryRun bool
 }
 
 // ConversionResult represents the result of a PDF conversion
@@ -87,6 +99,99 @@ type ConversionResult struct {
 
 	// OCRDuration is the time taken for OCR processing
 	OCRDuration time.Duration
+
+	// MarkdownPath is the path to the Markdown transcript, if one was written
+	MarkdownPath string
+
+	// SidecarPath is the path to the OCR sidecar file, if one was written
+	SidecarPath string
+
+	// PageTagPaths maps each page tag to the PDF of its pages, if SplitByPageTag is set
+	PageTagPaths map[string]string
+
+	// ChunkPaths are the PDFs of the document's pages in chunks, in page order, if
+	// ChunkPages is set and the document is longer
+	ChunkPaths []string
+
+	// Pages is the outcome of rendering each page, in page order
+	Pages []PageOutcome
+
+	// DryRun indicates the conversion's output was discarded; OutputPath and the
+	// paths of files written alongside it are empty, and FileSize is the size the
+	// PDF would have been
+	DryRun bool
+
+	// Unparseable indicates none of the document's pages could be parsed, and the
+	// converter's UnparseablePolicy was applied
+	Unparseable bool
+
+	// Skipped indicates no PDF was written, because the document was unparseable
+	// under UnparseableSkip; OutputPath is empty
+	Skipped bool
+
+	// Thumbnail indicates the document's thumbnail of its first page was embedded as
+	// the PDF's first page thumbnail
+	Thumbnail bool
+
+	// Bookmarks is the number of outline entries added for tagged pages, if
+	// BookmarksFromTags is set
+	Bookmarks int
+
+	// Encrypted indicates the PDF and any page tag and chunk PDFs were encrypted with
+	// the converter's EncryptPassword
+	Encrypted bool
+}
+
+// discardOutputs marks the result as a dry run, clearing the paths of the discarded
+// PDF and the files written alongside it
+func (cr *ConversionResult) discardOutputs() {
+	cr.DryRun = true
+	cr.OutputPath, cr.MarkdownPath, cr.SidecarPath, cr.PageTagPaths, cr.ChunkPaths = "", "", "", nil, nil
+}
+
+// PageStatus is the outcome of rendering a page
+type PageStatus string
+
+const (
+	// PageRendered is a page whose strokes were drawn
+	PageRendered PageStatus = "rendered"
+
+	// PageEmpty is a page with no strokes, including pages never written on, which have
+	// no .rm file
+	PageEmpty PageStatus = "empty"
+
+	// PageFailed is a page whose .rm file could not be parsed or rendered; it is left
+	// blank, apart from strokes drawn before a render error, and framed by a marker
+	PageFailed PageStatus = "failed"
+)
+
+// PageOutcome is the outcome of rendering a single page
+type PageOutcome struct {
+	// Number is the page number (1-indexed)
+	Number int
+
+	// Status is whether the page was rendered, empty, or failed
+	Status PageStatus
+
+	// Strokes is the number of strokes on the page
+	Strokes int
+
+	// Error describes why a failed page could not be read
+	Error string
+
+	// Cached indicates the page was taken from the render cache instead of rendered
+	Cached bool
+}
+
+// FailedPages returns the pages that could not be read
+func (cr *ConversionResult) FailedPages() []PageOutcome {
+	var failed []PageOutcome
+	for _, page := range cr.Pages {
+		if page.Status == PageFailed {
+			failed = append(failed, page)
+		}
+	}
+	return failed
 }
 
 // PDFMetadata represents metadata to embed in the PDF
@@ -304,3 +409,36 @@ func (ps PaperSize) Dimensions() (width, height int) {
 		return 1404, 1872 // Default to reMarkable size
 	}
 }
+
+// ParsePaperSize parses a paper size name case-insensitively, treating an empty
+// string as DefaultPaperSize
+func ParsePaperSize(s string) (PaperSize, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return DefaultPaperSize, nil
+	}
+	for _, size := range []PaperSize{PaperSizeA4, PaperSizeA5, PaperSizeLetter, PaperSizeLegal, PaperSizeRemarkable} {
+		if strings.EqualFold(s, string(size)) {
+			return size, nil
+		}
+	}
+	return "", fmt.Errorf("invalid paper size %q, must be one of: %s, %s, %s, %s, %s",
+		s, PaperSizeA4, PaperSizeA5, PaperSizeLetter, PaperSizeLegal, PaperSizeRemarkable)
+}
+
+// ParseOrientation parses a page orientation case-insensitively, treating an empty
+// string as DefaultOrientation
+func ParseOrientation(s string) (Orientation, error) {
+	s = strings.TrimSpace(s)
+	switch {
+	case s == "":
+		return DefaultOrientation, nil
+	case strings.EqualFold(s, string(OrientationPortrait)):
+		return OrientationPortrait, nil
+	case strings.EqualFold(s, string(OrientationLandscape)):
+		return OrientationLandscape, nil
+	default:
+		return "", fmt.Errorf("invalid orientation %q, must be one of: %s, %s",
+			s, OrientationPortrait, OrientationLandscape)
+	}
+}
@@ -225,3 +225,54 @@ func TestOrientationConstants(t *testing.T) {
 		t.Error("OrientationPortrait and OrientationLandscape should be different")
 	}
 }
+
+func TestParsePaperSize(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    PaperSize
+		wantErr bool
+	}{
+		{input: "", want: DefaultPaperSize},
+		{input: "a4", want: PaperSizeA4},
+		{input: "LETTER", want: PaperSizeLetter},
+		{input: "remarkable", want: PaperSizeRemarkable},
+		{input: "B5", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParsePaperSize(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePaperSize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParsePaperSize() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseOrientation(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Orientation
+		wantErr bool
+	}{
+		{input: "", want: DefaultOrientation},
+		{input: "portrait", want: OrientationPortrait},
+		{input: "Landscape", want: OrientationLandscape},
+		{input: "sideways", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseOrientation(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseOrientation() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseOrientation() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package converter
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/platinummonkey/legible/internal/rmparse"
+)
+
+// Placeholder page text layout, in points
+const (
+	placeholderMargin   = 36.0
+	placeholderFontSize = 12.0
+	placeholderLeading  = 16.0
+)
+
+// allPagesFailed reports whether a document has pages and none of them could be read
+func allPagesFailed(pages []PageOutcome) bool {
+	for _, page := range pages {
+		if page.Status != PageFailed {
+			return false
+		}
+	}
+	return len(pages) > 0
+}
+
+// convertUnparseable applies the unparseable policy to a document none of whose pages
+// could be parsed, replacing or removing the PDF already rendered to outputPath
+//
+// The outputs of a dry run are discarded from the result.
+func (c *Converter) convertUnparseable(outputPath, title string, content *ContentFile, opts *ConversionOptions, result *ConversionResult, startTime time.Time) (*ConversionResult, error) {
+	result, err := c.applyUnparseablePolicy(outputPath, title, content, opts, result, startTime)
+	if err == nil && opts.DryRun {
+		result.discardOutputs()
+	}
+	return result, err
+}
+
+// applyUnparseablePolicy writes a placeholder PDF, skips the document or fails,
+// following the converter's unparseable policy
+func (c *Converter) applyUnparseablePolicy(outputPath, title string, content *ContentFile, opts *ConversionOptions, result *ConversionResult, startTime time.Time) (*ConversionResult, error) {
+	pages := result.Pages
+	reason := fmt.Sprintf("none of its %d pages could be parsed (page 1: %s)", len(pages), pages[0].Error)
+	result.Unparseable = true
+
+	switch c.unparseable {
+	case UnparseableFail:
+		c.removeOutput(outputPath)
+		return nil, fmt.Errorf("%w: %s", ErrUnparseable, reason)
+
+	case UnparseablePlaceholder:
+		_, width, height := pageLayout(rmparse.PageSizeForFormatVersion(content.FormatVersion), opts)
+		note := []string{title, "", "This document could not be converted: " + reason + "."}
+		data := placeholderPDF(width, height, note)
+		if err := c.writeFile(outputPath, data, 0644); err != nil {
+			return nil, fmt.Errorf("failed to write placeholder PDF: %w", err)
+		}
+
+		result.AddWarning("Wrote a placeholder PDF, " + reason)
+		c.logger.WithFields("output", outputPath, "pages", len(pages)).Warn("No page could be parsed, wrote a placeholder PDF")
+		result.SetSuccess(outputPath, 1, int64(len(data)), time.Since(startTime))
+		return result, nil
+
+	default:
+		c.removeOutput(outputPath)
+		result.AddWarning("Skipped the document, " + reason)
+		c.logger.WithFields("output", outputPath, "pages", len(pages)).Warn("No page could be parsed, skipping the document")
+		result.Skipped = true
+		result.SetSuccess("", 0, 0, time.Since(startTime))
+		return result, nil
+	}
+}
+
+// removeOutput removes a PDF that won't be kept
+func (c *Converter) removeOutput(outputPath string) {
+	if err := os.Remove(outputPath); err != nil && !os.IsNotExist(err) {
+		c.logger.WithFields("output", outputPath, "error", err).Warn("Failed to remove output")
+	}
+}
+
+// placeholderPDF returns a single page PDF of the given size showing lines of text,
+// wrapped to the page width, in Helvetica
+func placeholderPDF(width, height float64, lines []string) []byte {
+	// Helvetica averages about half the font size per character
+	maxChars := int((width - 2*placeholderMargin) / (placeholderFontSize / 2))
+
+	var text bytes.Buffer
+	fmt.Fprintf(&text, "BT\n/F1 %.0f Tf\n%.0f TL\n%.2f %.2f Td\n", placeholderFontSize, placeholderLeading,
+		placeholderMargin, height-placeholderMargin)
+	for _, line := range lines {
+		for _, wrapped := range wrapText(line, maxChars) {
+			fmt.Fprintf(&text, "(%s) '\n", escapePDFText(wrapped))
+		}
+	}
+	text.WriteString("ET\n")
+
+	objects := []string{
+		"<</Type/Catalog/Pages 2 0 R>>",
+		"<</Type/Pages/Count 1/Kids[3 0 R]>>",
+		fmt.Sprintf("<</Type/Page/Parent 2 0 R/MediaBox[0 0 %.2f %.2f]/Resources<</Font<</F1 4 0 R>>>>/Contents 5 0 R>>", width, height),
+		"<</Type/Font/Subtype/Type1/BaseFont/Helvetica/Encoding/WinAnsiEncoding>>",
+		fmt.Sprintf("<</Length %d>>\nstream\n%sendstream", text.Len(), text.String()),
+	}
+
+	var pdf bytes.Buffer
+	pdf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = pdf.Len()
+		fmt.Fprintf(&pdf, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	xref := pdf.Len()
+	fmt.Fprintf(&pdf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&pdf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&pdf, "trailer\n<</Size %d/Root 1 0 R>>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	return pdf.Bytes()
+}
+
+// wrapText splits s into lines of at most maxChars characters, breaking between words
+// where possible; an empty s is a single empty line
+func wrapText(s string, maxChars int) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(s) {
+		for len(word) > maxChars {
+			if line != "" {
+				lines, line = append(lines, line), ""
+			}
+			lines, word = append(lines, word[:maxChars]), word[maxChars:]
+		}
+		switch {
+		case line == "":
+			line = word
+		case len(line)+1+len(word) <= maxChars:
+			line += " " + word
+		default:
+			lines, line = append(lines, line), word
+		}
+	}
+	return append(lines, line)
+}
+
+// escapePDFText escapes s for a PDF string, replacing characters outside printable
+// ASCII, which the standard fonts can't be relied on to show
+func escapePDFText(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			sb.WriteByte('\\')
+			sb.WriteRune(r)
+		case r < 0x20 || r > 0x7e:
+			sb.WriteByte('?')
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,107 @@
+package converter
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+)
+
+//nolint:gocyclo // Test function with multiple validation steps
+func TestConvertRmdoc_UnparseablePolicy(t *testing.T) {
+	src := "../../example/Test.rmdoc"
+	if _, err := os.Stat(src); os.IsNotExist(err) {
+		t.Skipf("Test file not found: %s", src)
+	}
+	rmdocPath := filepath.Join(t.TempDir(), "unparseable.rmdoc")
+	// Replace every page's .rm file with one in a format the parser doesn't recognize
+	rewriteRmdoc(t, src, rmdocPath, func(name string, data []byte) []byte {
+		if strings.HasSuffix(name, ".rm") {
+			return []byte("reMarkable .lines v9, a format from the future, padded out")
+		}
+		return data
+	})
+
+	for _, tt := range []struct {
+		name       string
+		policy     string
+		wantErr    bool
+		wantOutput bool
+	}{
+		{name: "default skips", policy: ""},
+		{name: "skip", policy: UnparseableSkip},
+		{name: "placeholder", policy: UnparseablePlaceholder, wantOutput: true},
+		{name: "fail", policy: UnparseableFail, wantErr: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			conv := newTestConverter(t, func(cfg *Config) { cfg.UnparseablePolicy = tt.policy })
+
+			outputPath := filepath.Join(t.TempDir(), "output.pdf")
+			result, err := conv.ConvertRmdoc(rmdocPath, outputPath)
+
+			if tt.wantErr {
+				if !errors.Is(err, ErrUnparseable) {
+					t.Errorf("ConvertRmdoc() error = %v, want ErrUnparseable", err)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("ConvertRmdoc() error = %v", err)
+				}
+				if !result.Unparseable || result.Skipped == tt.wantOutput || len(result.Warnings) == 0 {
+					t.Errorf("result Unparseable = %t, Skipped = %t, Warnings = %v, want an unparseable document with a warning",
+						result.Unparseable, result.Skipped, result.Warnings)
+				}
+				if len(result.FailedPages()) != 2 {
+					t.Errorf("result has %d failed pages, want 2", len(result.FailedPages()))
+				}
+			}
+
+			_, statErr := os.Stat(outputPath)
+			if exists := statErr == nil; exists != tt.wantOutput {
+				t.Fatalf("output exists = %t, want %t", exists, tt.wantOutput)
+			}
+			if !tt.wantOutput {
+				return
+			}
+
+			count, err := api.PageCountFile(outputPath)
+			if err != nil {
+				t.Fatalf("placeholder is not a valid PDF: %v", err)
+			}
+			data, _ := os.ReadFile(outputPath)
+			if count != 1 || !strings.Contains(string(data), "could not be converted") {
+				t.Errorf("placeholder has %d pages, want 1 with a note saying why", count)
+			}
+			if result.OutputPath != outputPath || result.PageCount != 1 {
+				t.Errorf("result OutputPath = %q, PageCount = %d, want the placeholder's", result.OutputPath, result.PageCount)
+			}
+		})
+	}
+}
+
+func TestNew_InvalidUnparseablePolicy(t *testing.T) {
+	if _, err := New(&Config{OCRLanguages: []string{"eng"}, UnparseablePolicy: "ignore"}); err == nil {
+		t.Error("New() expected error for an unknown unparseable policy")
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	tests := []struct {
+		s        string
+		maxChars int
+		want     []string
+	}{
+		{s: "", maxChars: 10, want: []string{""}},
+		{s: "one two three", maxChars: 7, want: []string{"one two", "three"}},
+		{s: "abcdefghij xy", maxChars: 4, want: []string{"abcd", "efgh", "ij", "xy"}},
+	}
+
+	for _, tt := range tests {
+		if got := wrapText(tt.s, tt.maxChars); strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("wrapText(%q, %d) = %q, want %q", tt.s, tt.maxChars, got, tt.want)
+		}
+	}
+}
@@ -7,31 +7,70 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	gosync "sync"
 	"syscall"
 	"time"
 
+	"github.com/fsnotify/fsnotify"
+	"github.com/robfig/cron"
+
 	"github.com/platinummonkey/legible/internal/logger"
+	"github.com/platinummonkey/legible/internal/ocr"
 	"github.com/platinummonkey/legible/internal/sync"
 )
 
+// syncRunner is the subset of the sync orchestrator used by the daemon
+type syncRunner interface {
+	Sync(ctx context.Context) (*sync.Result, error)
+	ImportLocal(ctx context.Context, rmdocPath string) (*sync.DocumentResult, error)
+}
+
+// VersionChecker reports the version of the documents in the reMarkable cloud, which
+// changes whenever one is edited; rmclient.Client implements it
+type VersionChecker interface {
+	CloudVersion() (string, error)
+}
+
 // Daemon manages periodic synchronization in the background
 type Daemon struct {
-	orchestrator  *sync.Orchestrator
-	logger        *logger.Logger
-	interval      time.Duration
-	healthAddr    string
-	pidFile       string
-	httpServer    *http.Server
-	statusTracker *StatusTracker
+	orchestrator     syncRunner
+	logger           *logger.Logger
+	intervalMu       gosync.Mutex // guards interval, which a reload can change
+	interval         time.Duration
+	intervalChanged  chan struct{} // signals Run to reschedule the next sync
+	schedule         cron.Schedule // replaces interval when set
+	scheduleSpec     string
+	healthAddr       string
+	pidFile          string
+	watchDir         string
+	watchSettleDelay time.Duration
+	versionChecker   VersionChecker
+	changeInterval   time.Duration // how often to check for cloud changes, 0 = never
+	cloudVersion     string        // last version seen by the change check
+	httpServer       *http.Server
+	statusTracker    *StatusTracker
+	reloadConfig     func() (*Settings, error)
+	ocrMetrics       *ocr.Metrics
 }
 
 // Config holds configuration for the daemon
 type Config struct {
-	Orchestrator    *sync.Orchestrator
-	Logger          *logger.Logger
-	SyncInterval    time.Duration // How often to sync (default: 5 minutes)
-	HealthCheckAddr string        // Optional health check address (e.g. ":8080")
-	PIDFile         string        // Optional PID file path
+	Orchestrator     *sync.Orchestrator
+	Logger           *logger.Logger
+	SyncInterval     time.Duration // How often to sync (default: 5 minutes)
+	Schedule         string        // Optional cron expression for when to sync, used instead of SyncInterval
+	HealthCheckAddr  string        // Optional health check address (e.g. ":8080")
+	PIDFile          string        // Optional PID file path
+	WatchDir         string        // Optional local directory watched for dropped .rmdoc files
+	WatchSettleDelay time.Duration // How long WatchDir must go unchanged before importing (default: 2 seconds)
+	// ChangePollInterval is how often to check the cloud for changes and sync as soon
+	// as one is found, between the scheduled syncs (0 = only scheduled syncs)
+	ChangePollInterval time.Duration
+	VersionChecker     VersionChecker // Required with ChangePollInterval
+	// Reload re-reads the configuration for POST /reload, which is disabled when nil
+	Reload func() (*Settings, error)
+	// OCRMetrics are the OCR counters served on /metrics (optional, zero when nil)
+	OCRMetrics *ocr.Metrics
 }
 
 // New creates a new daemon instance
@@ -56,19 +95,76 @@ func New(cfg *Config) (*Daemon, error) {
 		interval = 5 * time.Minute
 	}
 
+	// A cron schedule replaces the interval
+	var schedule cron.Schedule
+	if cfg.Schedule != "" {
+		var err error
+		if schedule, err = ParseSchedule(cfg.Schedule); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.ChangePollInterval > 0 && cfg.VersionChecker == nil {
+		return nil, fmt.Errorf("a version checker is required to poll for changes")
+	}
+
+	// Default watch settle delay
+	watchSettleDelay := cfg.WatchSettleDelay
+	if watchSettleDelay == 0 {
+		watchSettleDelay = DefaultWatchSettleDelay
+	}
+
+	// Report document downloads in the status while a sync runs
+	statusTracker := NewStatusTracker()
+	statusTracker.SetSchedule(cfg.Schedule)
+	cfg.Orchestrator.OnDownloadProgress(func(p sync.DownloadProgress) {
+		statusTracker.UpdateDownload(DownloadStatus{
+			DocumentID:      p.DocumentID,
+			Title:           p.Title,
+			BytesDownloaded: p.Downloaded,
+			BytesTotal:      p.Total,
+		}, p.Done)
+	})
+
 	return &Daemon{
-		orchestrator:  cfg.Orchestrator,
-		logger:        log,
-		interval:      interval,
-		healthAddr:    cfg.HealthCheckAddr,
-		pidFile:       cfg.PIDFile,
-		statusTracker: NewStatusTracker(),
+		orchestrator:     cfg.Orchestrator,
+		logger:           log,
+		interval:         interval,
+		intervalChanged:  make(chan struct{}, 1),
+		schedule:         schedule,
+		scheduleSpec:     cfg.Schedule,
+		healthAddr:       cfg.HealthCheckAddr,
+		pidFile:          cfg.PIDFile,
+		watchDir:         cfg.WatchDir,
+		watchSettleDelay: watchSettleDelay,
+		versionChecker:   cfg.VersionChecker,
+		changeInterval:   cfg.ChangePollInterval,
+		statusTracker:    statusTracker,
+		reloadConfig:     cfg.Reload,
+		ocrMetrics:       cfg.OCRMetrics,
 	}, nil
 }
 
+// ParseSchedule parses a standard five-field cron expression (minute, hour, day of
+// month, month, day of week), such as "0 9-17 * * 1-5"
+func ParseSchedule(spec string) (cron.Schedule, error) {
+	schedule, err := cron.ParseStandard(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule %q: %w", spec, err)
+	}
+	if schedule.Next(time.Now()).IsZero() {
+		return nil, fmt.Errorf("invalid schedule %q: it never runs", spec)
+	}
+	return schedule, nil
+}
+
 // Run starts the daemon and blocks until shutdown signal received
 func (d *Daemon) Run(ctx context.Context) error {
-	d.logger.WithFields("interval", d.interval).Info("Starting daemon")
+	if d.schedule != nil {
+		d.logger.WithFields("schedule", d.scheduleSpec).Info("Starting daemon")
+	} else {
+		d.logger.WithFields("interval", d.syncInterval()).Info("Starting daemon")
+	}
 
 	// Write PID file if configured
 	if d.pidFile != "" {
@@ -94,16 +190,24 @@ func (d *Daemon) Run(ctx context.Context) error {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGTERM, syscall.SIGINT)
 
-	// Create ticker for periodic syncs
-	ticker := time.NewTicker(d.interval)
-	defer ticker.Stop()
+	// Watch the local import directory if configured
+	watch, err := d.watch()
+	if err != nil {
+		return err
+	}
+	defer watch.close()
+
+	// Check the cloud for changes between scheduled syncs if configured
+	changeTick, stopChangePoll := d.startChangePoll()
+	defer stopChangePoll()
 
 	// Run initial sync immediately
 	d.logger.Info("Running initial sync")
 	d.runSync(ctx)
 
 	// Schedule next sync
-	d.statusTracker.SetNextSyncTime(time.Now().Add(d.interval))
+	timer := time.NewTimer(d.scheduleNextSync())
+	defer timer.Stop()
 
 	// Main loop
 	for {
@@ -117,15 +221,205 @@ func (d *Daemon) Run(ctx context.Context) error {
 			// Allow current sync to complete by returning gracefully
 			return nil
 
-		case <-ticker.C:
-			d.logger.Info("Sync interval elapsed, triggering sync")
-			d.runSync(ctx)
-			// Schedule next sync
-			d.statusTracker.SetNextSyncTime(time.Now().Add(d.interval))
+		case <-timer.C:
+			d.scheduledSync(ctx, timer)
+
+		case <-d.intervalChanged:
+			// Count the new interval from now rather than waiting out the old one
+			timer.Reset(d.scheduleNextSync())
+
+		case event := <-watch.events:
+			d.watchEvent(watch, event)
+
+		case err := <-watch.errors:
+			d.logger.WithFields("dir", d.watchDir, "error", err).Warn("Failed to watch directory")
+
+		case <-watch.settled:
+			d.importDropped(ctx, watch.watcher)
+
+		case <-changeTick:
+			d.pollChanges(ctx, timer)
 		}
 	}
 }
 
+// dirWatch is the local import directory watched by Run
+//
+// Its channels are nil when no directory is watched; a nil channel blocks forever, so
+// Run's select ignores them.
+type dirWatch struct {
+	watcher     *dirWatcher
+	events      <-chan fsnotify.Event
+	errors      <-chan error
+	settleTimer *time.Timer
+	settled     <-chan time.Time
+}
+
+// watch starts watching the local import directory, if one is configured
+func (d *Daemon) watch() (*dirWatch, error) {
+	if d.watchDir == "" {
+		return &dirWatch{}, nil
+	}
+	if err := os.MkdirAll(d.watchDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create watch directory: %w", err)
+	}
+	watcher, err := newDirWatcher(d.watchDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to watch directory: %w", err)
+	}
+
+	// Files already in the directory are imported once the settle delay passes
+	settleTimer := time.NewTimer(d.watchSettleDelay)
+	if !watcher.hasPending() {
+		settleTimer.Stop()
+	}
+	d.logger.WithFields("dir", d.watchDir, "settle_delay", d.watchSettleDelay).
+		Info("Watching local directory for .rmdoc files")
+
+	return &dirWatch{
+		watcher:     watcher,
+		events:      watcher.fsw.Events,
+		errors:      watcher.fsw.Errors,
+		settleTimer: settleTimer,
+		settled:     settleTimer.C,
+	}, nil
+}
+
+// close stops watching the directory
+func (w *dirWatch) close() {
+	if w.watcher == nil {
+		return
+	}
+	w.settleTimer.Stop()
+	_ = w.watcher.Close()
+}
+
+// watchEvent records a change to the watched directory, waiting for the directory to
+// settle again so files still being copied aren't imported
+func (d *Daemon) watchEvent(w *dirWatch, event fsnotify.Event) {
+	if w.watcher.handle(event) && w.watcher.hasPending() {
+		w.settleTimer.Reset(d.watchSettleDelay)
+	}
+}
+
+// startChangePoll starts checking the cloud for changes between scheduled syncs, if
+// configured, returning the channel of checks (nil when disabled) and a function
+// stopping them
+func (d *Daemon) startChangePoll() (<-chan time.Time, func()) {
+	if d.changeInterval <= 0 {
+		return nil, func() {}
+	}
+	changeTicker := time.NewTicker(d.changeInterval)
+	d.logger.WithFields("interval", d.changeInterval).Info("Polling the cloud for changes")
+
+	// The initial sync covers everything up to this version
+	d.cloudVersion, _ = d.checkCloudVersion()
+	return changeTicker.C, changeTicker.Stop
+}
+
+// scheduledSync runs the sync whose time has been reached and schedules the next one
+// on timer
+func (d *Daemon) scheduledSync(ctx context.Context, timer *time.Timer) {
+	d.logger.Info("Scheduled sync time reached, triggering sync")
+	d.runSync(ctx)
+	timer.Reset(d.scheduleNextSync())
+}
+
+// pollChanges syncs if the cloud documents changed since the last check, counting the
+// next scheduled sync on timer from this one
+func (d *Daemon) pollChanges(ctx context.Context, timer *time.Timer) {
+	if !d.cloudChanged() {
+		return
+	}
+	d.logger.Info("Cloud documents changed, triggering sync")
+	d.runSync(ctx)
+	timer.Reset(d.scheduleNextSync())
+}
+
+// nextSyncTime returns when the sync after now runs, from the schedule if set and
+// otherwise the interval
+func (d *Daemon) nextSyncTime(now time.Time) time.Time {
+	if d.schedule != nil {
+		return d.schedule.Next(now)
+	}
+	return now.Add(d.syncInterval())
+}
+
+// syncInterval returns how long the daemon waits between syncs
+func (d *Daemon) syncInterval() time.Duration {
+	d.intervalMu.Lock()
+	defer d.intervalMu.Unlock()
+	return d.interval
+}
+
+// scheduleNextSync records the time of the next sync in the status and returns how
+// long until it runs
+func (d *Daemon) scheduleNextSync() time.Duration {
+	now := time.Now()
+	next := d.nextSyncTime(now)
+	d.statusTracker.SetNextSyncTime(next)
+	d.logger.WithFields("next_sync", next.Format(time.RFC3339)).Debug("Next sync scheduled")
+	return next.Sub(now)
+}
+
+// checkCloudVersion returns the current version of the cloud documents
+func (d *Daemon) checkCloudVersion() (string, error) {
+	version, err := d.versionChecker.CloudVersion()
+	if err != nil {
+		d.logger.WithFields("error", err).Warn("Failed to check the cloud for changes")
+		return "", err
+	}
+	return version, nil
+}
+
+// cloudChanged reports whether the cloud documents changed since the last check,
+// recording when a change is found
+func (d *Daemon) cloudChanged() bool {
+	version, err := d.checkCloudVersion()
+	if err != nil {
+		return false
+	}
+
+	previous := d.cloudVersion
+	d.cloudVersion = version
+	if previous == "" || version == previous {
+		// Without a previous version there is nothing to compare, so the next check is
+		// the first that can find a change
+		return false
+	}
+
+	d.logger.WithFields("previous", previous, "current", version).Debug("Cloud version changed")
+	d.statusTracker.SetLastChangeTime(time.Now())
+	return true
+}
+
+// importDropped converts the .rmdoc files dropped into the watch directory since the last import
+func (d *Daemon) importDropped(ctx context.Context, watcher *dirWatcher) {
+	paths := watcher.ready()
+	for _, path := range paths {
+		if ctx.Err() != nil {
+			return
+		}
+
+		d.logger.WithFields("file", path).Info("Importing local document")
+		result, err := d.orchestrator.ImportLocal(ctx, path)
+		if err != nil {
+			d.logger.WithFields("file", path, "error", err).Error("Local import failed")
+			continue
+		}
+		if result == nil {
+			d.logger.WithFields("file", path).Debug("Local document unchanged, skipped")
+			continue
+		}
+
+		d.logger.WithFields(
+			"file", path,
+			"output", result.OutputPath,
+			"pages", result.PageCount,
+		).Info("Local document imported")
+	}
+}
+
 // runSync executes a single sync operation with error recovery
 func (d *Daemon) runSync(ctx context.Context) {
 	d.logger.Info("Starting sync")
@@ -234,6 +528,12 @@ func (d *Daemon) startHealthCheck() error {
 	mux.HandleFunc("/api/sync/trigger", d.handleTriggerSync)
 	mux.HandleFunc("/api/sync/cancel", d.handleCancelSync)
 
+	// Reload endpoint - applies configuration changes without a restart
+	mux.HandleFunc("/reload", d.handleReload)
+
+	// Metrics endpoint - counters in the Prometheus text format
+	mux.HandleFunc("/metrics", d.handleMetrics)
+
 	d.httpServer = &http.Server{
 		Addr:    d.healthAddr,
 		Handler: mux,
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -32,6 +33,21 @@ func (m *mockOrchestrator) Sync(_ context.Context) (*sync.Result, error) {
 	return m.syncResult, nil
 }
 
+// countingOrchestrator counts the syncs run by the daemon, which are safe to run
+// concurrently with the test reading the count
+type countingOrchestrator struct {
+	syncs atomic.Int32
+}
+
+func (o *countingOrchestrator) Sync(_ context.Context) (*sync.Result, error) {
+	o.syncs.Add(1)
+	return sync.NewResult(), nil
+}
+
+func (o *countingOrchestrator) ImportLocal(_ context.Context, _ string) (*sync.DocumentResult, error) {
+	return nil, nil
+}
+
 func TestNew(t *testing.T) {
 	mockOrch := &mockOrchestrator{}
 
@@ -105,6 +121,144 @@ func TestNew_CustomInterval(t *testing.T) {
 	}
 }
 
+func TestNew_InvalidSchedule(t *testing.T) {
+	for _, spec := range []string{"every hour", "61 * * * *", "0 0 30 2 *"} {
+		_, err := New(&Config{Orchestrator: &sync.Orchestrator{}, Schedule: spec})
+		if err == nil || !strings.Contains(err.Error(), "invalid schedule") {
+			t.Errorf("New() with schedule %q error = %v, want an invalid schedule error", spec, err)
+		}
+	}
+}
+
+func TestRun_ScheduleReportedInStatus(t *testing.T) {
+	schedule, err := ParseSchedule("0 3 * * *")
+	if err != nil {
+		t.Fatalf("ParseSchedule() error = %v", err)
+	}
+	orch := &countingOrchestrator{}
+	d := &Daemon{
+		orchestrator:  orch,
+		logger:        logger.Get(),
+		interval:      time.Millisecond, // ignored in favor of the schedule
+		schedule:      schedule,
+		scheduleSpec:  "0 3 * * *",
+		statusTracker: NewStatusTracker(),
+	}
+	d.statusTracker.SetSchedule(d.scheduleSpec)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- d.Run(ctx)
+	}()
+
+	// Wait for the initial sync to schedule the next one
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) && d.statusTracker.GetStatus().NextSyncTime == nil {
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("daemon did not shut down after context cancellation")
+	}
+
+	if got := orch.syncs.Load(); got != 1 {
+		t.Errorf("sync called %d times, want only the initial sync before 03:00", got)
+	}
+	status := d.statusTracker.GetStatus()
+	if status.Schedule != "0 3 * * *" {
+		t.Errorf("status schedule = %q, want %q", status.Schedule, "0 3 * * *")
+	}
+	next := status.NextSyncTime
+	if next == nil || next.Hour() != 3 || next.Minute() != 0 || !next.After(time.Now()) || next.After(time.Now().Add(24*time.Hour)) {
+		t.Errorf("next sync = %v, want the coming 03:00", next)
+	}
+}
+
+// fakeVersionChecker reports a cloud version set by the test
+type fakeVersionChecker struct {
+	version atomic.Value
+	checks  atomic.Int32
+}
+
+func (f *fakeVersionChecker) CloudVersion() (string, error) {
+	f.checks.Add(1)
+	return f.version.Load().(string), nil
+}
+
+func TestNew_ChangePollingRequiresVersionChecker(t *testing.T) {
+	_, err := New(&Config{Orchestrator: &sync.Orchestrator{}, ChangePollInterval: time.Second})
+	if err == nil {
+		t.Error("New() should error when polling for changes without a version checker")
+	}
+}
+
+func TestRun_SyncsWhenCloudChanges(t *testing.T) {
+	checker := &fakeVersionChecker{}
+	checker.version.Store("1-aaa")
+	orch := &countingOrchestrator{}
+	d := &Daemon{
+		orchestrator:   orch,
+		logger:         logger.Get(),
+		interval:       time.Hour,
+		versionChecker: checker,
+		changeInterval: 5 * time.Millisecond,
+		statusTracker:  NewStatusTracker(),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- d.Run(ctx)
+	}()
+
+	waitFor := func(what string, cond func() bool) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for !cond() {
+			if time.Now().After(deadline) {
+				t.Fatalf("timed out waiting for %s", what)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	// Checks of an unchanged cloud don't sync
+	waitFor("unchanged checks", func() bool { return checker.checks.Load() >= 5 })
+	if got := orch.syncs.Load(); got != 1 {
+		t.Errorf("sync called %d times before any change, want only the initial sync", got)
+	}
+	if d.statusTracker.GetStatus().LastChangeTime != nil {
+		t.Error("status reports a change before the cloud changed")
+	}
+
+	// A change triggers one full sync
+	checker.version.Store("2-bbb")
+	waitFor("the sync after the change", func() bool { return orch.syncs.Load() >= 2 })
+	checks := checker.checks.Load()
+	waitFor("checks after the change", func() bool { return checker.checks.Load() >= checks+5 })
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("daemon did not shut down after context cancellation")
+	}
+
+	if got := orch.syncs.Load(); got != 2 {
+		t.Errorf("sync called %d times, want 2 (initial + one for the change)", got)
+	}
+	if d.statusTracker.GetStatus().LastChangeTime == nil {
+		t.Error("status does not report the detected change")
+	}
+}
+
 func TestWritePIDFile(t *testing.T) {
 	tmpDir := t.TempDir()
 	pidFile := filepath.Join(tmpDir, "test.pid")
@@ -0,0 +1,24 @@
+package daemon
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// handleMetrics serves the daemon's counters in the Prometheus text format
+func (d *Daemon) handleMetrics(w http.ResponseWriter, _ *http.Request) {
+	snapshot := d.ocrMetrics.Snapshot()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	writeCounter(w, "legible_ocr_cache_hits_total",
+		"Pages whose OCR result was read from the OCR cache.", snapshot.CacheHits)
+	writeCounter(w, "legible_ocr_cache_misses_total",
+		"Pages that were not in the OCR cache and were OCR'd.", snapshot.CacheMisses)
+	writeCounter(w, "legible_ocr_fallbacks_total",
+		"OCR requests served by the fallback engine while the primary was unavailable.", snapshot.Fallbacks)
+}
+
+// writeCounter writes a counter with its help text in the Prometheus text format
+func writeCounter(w http.ResponseWriter, name, help string, value int64) {
+	_, _ = fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n%s %d\n", name, help, name, name, value)
+}
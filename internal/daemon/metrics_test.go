@@ -0,0 +1,48 @@
+package daemon
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/platinummonkey/legible/internal/logger"
+	"github.com/platinummonkey/legible/internal/ocr"
+)
+
+func TestHandleMetrics(t *testing.T) {
+	metrics := &ocr.Metrics{}
+	metrics.RecordCacheHit()
+	metrics.RecordCacheHit()
+	metrics.RecordCacheMiss()
+	metrics.RecordFallback()
+	d := &Daemon{logger: logger.Get(), ocrMetrics: metrics}
+
+	rec := httptest.NewRecorder()
+	d.handleMetrics(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{
+		"# TYPE legible_ocr_cache_hits_total counter\nlegible_ocr_cache_hits_total 2\n",
+		"legible_ocr_cache_misses_total 1\n",
+		"legible_ocr_fallbacks_total 1\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics missing %q:\n%s", want, body)
+		}
+	}
+}
+
+func TestHandleMetrics_WithoutOCR(t *testing.T) {
+	d := &Daemon{logger: logger.Get()}
+
+	rec := httptest.NewRecorder()
+	d.handleMetrics(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if body := rec.Body.String(); !strings.Contains(body, "legible_ocr_cache_hits_total 0\n") {
+		t.Errorf("metrics without OCR should report zero counters:\n%s", body)
+	}
+}
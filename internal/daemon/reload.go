@@ -0,0 +1,110 @@
+package daemon
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Settings are the configuration a reload applies to a running daemon; every other
+// setting, such as the output directory, schedule or OCR provider, needs a restart
+type Settings struct {
+	SyncInterval time.Duration // 0 keeps the current interval
+	Labels       []string      // labels to filter documents by, empty for all
+	OCREnabled   bool
+}
+
+// settingsApplier is the part of the sync orchestrator a reload changes
+type settingsApplier interface {
+	SetLabels(labels []string)
+	SetOCREnabled(enabled bool) error
+}
+
+// handleReload handles POST /reload
+// Re-reads the configuration and applies the settings that can change while running
+func (d *Daemon) handleReload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if d.reloadConfig == nil {
+		respondJSON(w, http.StatusNotImplemented, ControlResponse{
+			Success: false,
+			Message: "Reloading the configuration is not supported",
+		})
+		return
+	}
+
+	settings, err := d.reloadConfig()
+	if err != nil {
+		d.logger.WithFields("error", err).Warn("Failed to reload configuration")
+		respondJSON(w, http.StatusInternalServerError, ControlResponse{
+			Success: false,
+			Message: "Failed to reload configuration",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	if err := d.applySettings(settings); err != nil {
+		d.logger.WithFields("error", err).Warn("Failed to apply reloaded configuration")
+		respondJSON(w, http.StatusUnprocessableEntity, ControlResponse{
+			Success: false,
+			Message: "Failed to apply configuration",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	respondJSON(w, http.StatusOK, ControlResponse{
+		Success: true,
+		Message: fmt.Sprintf("Configuration reloaded: interval %s, labels [%s], OCR %s",
+			d.syncInterval(), strings.Join(settings.Labels, ", "), onOff(settings.OCREnabled)),
+	})
+}
+
+// applySettings applies reloaded settings to the daemon and its orchestrator
+// OCR is applied first since it is the only setting that can be refused, so a refused
+// reload changes nothing. Label changes take effect from the next sync, and an
+// interval change reschedules the next sync.
+func (d *Daemon) applySettings(settings *Settings) error {
+	if applier, ok := d.orchestrator.(settingsApplier); ok {
+		if err := applier.SetOCREnabled(settings.OCREnabled); err != nil {
+			return err
+		}
+		applier.SetLabels(settings.Labels)
+	}
+
+	if settings.SyncInterval > 0 {
+		d.intervalMu.Lock()
+		changed := settings.SyncInterval != d.interval
+		d.interval = settings.SyncInterval
+		d.intervalMu.Unlock()
+
+		// Without a schedule the interval decides the next sync, so Run reschedules it;
+		// a pending signal already covers this change
+		if changed && d.schedule == nil {
+			select {
+			case d.intervalChanged <- struct{}{}:
+			default:
+			}
+		}
+	}
+
+	d.logger.WithFields(
+		"interval", d.syncInterval(),
+		"labels", settings.Labels,
+		"ocr_enabled", settings.OCREnabled,
+	).Info("Reloaded configuration")
+	return nil
+}
+
+// onOff formats a boolean setting
+func onOff(enabled bool) string {
+	if enabled {
+		return "on"
+	}
+	return "off"
+}
@@ -0,0 +1,160 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/platinummonkey/legible/internal/logger"
+)
+
+// settingsOrchestrator records syncs and the settings a reload applies
+type settingsOrchestrator struct {
+	countingOrchestrator
+	labels     []string
+	ocrEnabled bool
+	ocrErr     error
+}
+
+func (o *settingsOrchestrator) SetLabels(labels []string) {
+	o.labels = labels
+}
+
+func (o *settingsOrchestrator) SetOCREnabled(enabled bool) error {
+	if o.ocrErr != nil {
+		return o.ocrErr
+	}
+	o.ocrEnabled = enabled
+	return nil
+}
+
+// postReload sends POST /reload to the daemon and decodes its response
+func postReload(t *testing.T, d *Daemon) (int, ControlResponse) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	d.handleReload(rec, httptest.NewRequest(http.MethodPost, "/reload", nil))
+
+	var resp ControlResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	return rec.Code, resp
+}
+
+func TestHandleReload_AppliesSettings(t *testing.T) {
+	orch := &settingsOrchestrator{}
+	settings := &Settings{SyncInterval: time.Hour}
+	d := &Daemon{
+		orchestrator:    orch,
+		logger:          logger.Get(),
+		interval:        time.Hour,
+		intervalChanged: make(chan struct{}, 1),
+		statusTracker:   NewStatusTracker(),
+		reloadConfig:    func() (*Settings, error) { return settings, nil },
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- d.Run(ctx)
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for orch.syncs.Load() < 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the initial sync")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// Shortening the interval reschedules the next sync instead of waiting out the hour
+	settings = &Settings{SyncInterval: 10 * time.Millisecond, Labels: []string{"work"}, OCREnabled: true}
+	code, resp := postReload(t, d)
+	if code != http.StatusOK || !resp.Success {
+		t.Fatalf("POST /reload = %d %+v, want success", code, resp)
+	}
+	if got := d.syncInterval(); got != 10*time.Millisecond {
+		t.Errorf("effective interval = %s, want 10ms", got)
+	}
+	if strings.Join(orch.labels, ",") != "work" || !orch.ocrEnabled {
+		t.Errorf("orchestrator labels = %v, OCR = %t, want [work] with OCR on", orch.labels, orch.ocrEnabled)
+	}
+	if !strings.Contains(resp.Message, "10ms") {
+		t.Errorf("response message %q doesn't report the new interval", resp.Message)
+	}
+
+	deadline = time.Now().Add(5 * time.Second)
+	for orch.syncs.Load() < 3 {
+		if time.Now().After(deadline) {
+			t.Fatalf("only %d syncs after shortening the interval", orch.syncs.Load())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("daemon did not shut down after context cancellation")
+	}
+}
+
+func TestHandleReload_Failures(t *testing.T) {
+	newDaemon := func(orch *settingsOrchestrator, reload func() (*Settings, error)) *Daemon {
+		return &Daemon{
+			orchestrator:    orch,
+			logger:          logger.Get(),
+			interval:        time.Hour,
+			intervalChanged: make(chan struct{}, 1),
+			statusTracker:   NewStatusTracker(),
+			reloadConfig:    reload,
+		}
+	}
+	changed := func() (*Settings, error) {
+		return &Settings{SyncInterval: time.Minute, Labels: []string{"work"}, OCREnabled: true}, nil
+	}
+
+	t.Run("not supported", func(t *testing.T) {
+		if code, resp := postReload(t, newDaemon(&settingsOrchestrator{}, nil)); code != http.StatusNotImplemented || resp.Success {
+			t.Errorf("POST /reload = %d %+v, want 501", code, resp)
+		}
+	})
+
+	t.Run("method not allowed", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		newDaemon(&settingsOrchestrator{}, changed).handleReload(rec, httptest.NewRequest(http.MethodGet, "/reload", nil))
+		if rec.Code != http.StatusMethodNotAllowed {
+			t.Errorf("GET /reload = %d, want 405", rec.Code)
+		}
+	})
+
+	t.Run("unreadable config", func(t *testing.T) {
+		d := newDaemon(&settingsOrchestrator{}, func() (*Settings, error) { return nil, errors.New("bad yaml") })
+		code, resp := postReload(t, d)
+		if code != http.StatusInternalServerError || resp.Success || resp.Error != "bad yaml" {
+			t.Errorf("POST /reload = %d %+v, want 500 reporting the error", code, resp)
+		}
+		if d.syncInterval() != time.Hour {
+			t.Errorf("effective interval = %s, want it unchanged", d.syncInterval())
+		}
+	})
+
+	t.Run("refused OCR change", func(t *testing.T) {
+		orch := &settingsOrchestrator{ocrErr: errors.New("OCR was disabled when the converter was created")}
+		d := newDaemon(orch, changed)
+		code, resp := postReload(t, d)
+		if code != http.StatusUnprocessableEntity || resp.Success {
+			t.Errorf("POST /reload = %d %+v, want 422", code, resp)
+		}
+		// Nothing is applied
+		if d.syncInterval() != time.Hour || orch.labels != nil {
+			t.Errorf("interval = %s, labels = %v, want both unchanged", d.syncInterval(), orch.labels)
+		}
+	})
+}
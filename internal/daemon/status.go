@@ -3,6 +3,7 @@ package daemon
 import (
 	"encoding/json"
 	"net/http"
+	"sort"
 	"sync"
 	"time"
 )
@@ -32,6 +33,13 @@ type Status struct {
 	// NextSyncTime is the estimated time of the next sync
 	NextSyncTime *time.Time `json:"next_sync_time,omitempty"`
 
+	// Schedule is the cron expression syncs run on, empty when they run at an interval
+	Schedule string `json:"schedule,omitempty"`
+
+	// LastChangeTime is when the daemon last found the cloud documents changed, when it
+	// polls for changes
+	LastChangeTime *time.Time `json:"last_change_time,omitempty"`
+
 	// SyncDuration is how long the last sync took
 	SyncDuration *time.Duration `json:"sync_duration,omitempty"`
 
@@ -64,6 +72,24 @@ type SyncProgress struct {
 
 	// Stage is the current stage of processing (downloading, converting, ocr, enhancing)
 	Stage string `json:"stage,omitempty"`
+
+	// Downloads lists the documents currently downloading
+	Downloads []DownloadStatus `json:"downloads,omitempty"`
+}
+
+// DownloadStatus is the progress of a document download
+type DownloadStatus struct {
+	// DocumentID is the ID of the document being downloaded
+	DocumentID string `json:"document_id"`
+
+	// Title is the document's name
+	Title string `json:"title,omitempty"`
+
+	// BytesDownloaded is the number of bytes received so far
+	BytesDownloaded int64 `json:"bytes_downloaded"`
+
+	// BytesTotal is the size of the document's files requested so far
+	BytesTotal int64 `json:"bytes_total"`
 }
 
 // SyncSummary contains a summary of a completed sync operation
@@ -100,10 +126,13 @@ type StatusTracker struct {
 	startTime  time.Time
 	lastSync   *time.Time
 	nextSync   *time.Time
+	schedule   string
+	lastChange *time.Time
 	lastDur    *time.Duration
 	errMsg     string
 	curSync    *SyncProgress
 	lastResult *SyncSummary
+	downloads  map[string]DownloadStatus // in-progress downloads of the current sync
 }
 
 // NewStatusTracker creates a new status tracker
@@ -121,13 +150,28 @@ func (st *StatusTracker) GetStatus() Status {
 
 	uptime := time.Since(st.startTime)
 
+	// Copy the progress so the downloads can be listed without sharing the tracker's state
+	var curSync *SyncProgress
+	if st.curSync != nil {
+		progress := *st.curSync
+		for _, download := range st.downloads {
+			progress.Downloads = append(progress.Downloads, download)
+		}
+		sort.Slice(progress.Downloads, func(i, j int) bool {
+			return progress.Downloads[i].DocumentID < progress.Downloads[j].DocumentID
+		})
+		curSync = &progress
+	}
+
 	return Status{
 		State:          st.state,
 		LastSyncTime:   st.lastSync,
 		NextSyncTime:   st.nextSync,
+		Schedule:       st.schedule,
+		LastChangeTime: st.lastChange,
 		SyncDuration:   st.lastDur,
 		ErrorMessage:   st.errMsg,
-		CurrentSync:    st.curSync,
+		CurrentSync:    curSync,
 		LastSyncResult: st.lastResult,
 		UptimeSeconds:  int64(uptime.Seconds()),
 	}
@@ -163,6 +207,7 @@ func (st *StatusTracker) SyncStarted(totalDocs int) {
 		StartTime:      now,
 		DocumentsTotal: totalDocs,
 	}
+	st.downloads = nil
 }
 
 // UpdateProgress updates the current sync progress
@@ -177,6 +222,22 @@ func (st *StatusTracker) UpdateProgress(processed int, currentDoc, stage string)
 	}
 }
 
+// UpdateDownload records the progress of a document download during the current sync
+// A finished download is removed from the status.
+func (st *StatusTracker) UpdateDownload(download DownloadStatus, done bool) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if done || st.curSync == nil {
+		delete(st.downloads, download.DocumentID)
+		return
+	}
+	if st.downloads == nil {
+		st.downloads = make(map[string]DownloadStatus)
+	}
+	st.downloads[download.DocumentID] = download
+}
+
 // SyncCompleted records a successful sync completion
 func (st *StatusTracker) SyncCompleted(summary SyncSummary) {
 	st.mu.Lock()
@@ -184,6 +245,7 @@ func (st *StatusTracker) SyncCompleted(summary SyncSummary) {
 
 	st.state = StateIdle
 	st.curSync = nil
+	st.downloads = nil
 	st.lastResult = &summary
 	st.errMsg = ""
 
@@ -198,6 +260,7 @@ func (st *StatusTracker) SyncFailed(err error, duration time.Duration) {
 
 	st.state = StateError
 	st.curSync = nil
+	st.downloads = nil
 	st.lastDur = &duration
 
 	if err != nil {
@@ -212,6 +275,20 @@ func (st *StatusTracker) SetNextSyncTime(t time.Time) {
 	st.nextSync = &t
 }
 
+// SetSchedule records the cron expression syncs are scheduled with
+func (st *StatusTracker) SetSchedule(schedule string) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.schedule = schedule
+}
+
+// SetLastChangeTime records when a change to the cloud documents was found
+func (st *StatusTracker) SetLastChangeTime(t time.Time) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.lastChange = &t
+}
+
 // handleStatus serves the current status as JSON
 func (d *Daemon) handleStatus(w http.ResponseWriter, _ *http.Request) {
 	status := d.statusTracker.GetStatus()
@@ -89,6 +89,35 @@ func TestStatusTrackerError(t *testing.T) {
 	}
 }
 
+func TestStatusTrackerDownloads(t *testing.T) {
+	st := NewStatusTracker()
+
+	// Downloads outside a sync are not reported
+	st.UpdateDownload(DownloadStatus{DocumentID: "early", BytesDownloaded: 1}, false)
+
+	st.SyncStarted(2)
+	st.UpdateDownload(DownloadStatus{DocumentID: "doc-b", Title: "B", BytesDownloaded: 10, BytesTotal: 100}, false)
+	st.UpdateDownload(DownloadStatus{DocumentID: "doc-a", Title: "A", BytesDownloaded: 5, BytesTotal: 50}, false)
+	st.UpdateDownload(DownloadStatus{DocumentID: "doc-b", Title: "B", BytesDownloaded: 60, BytesTotal: 100}, false)
+
+	downloads := st.GetStatus().CurrentSync.Downloads
+	if len(downloads) != 2 || downloads[0].DocumentID != "doc-a" || downloads[1].BytesDownloaded != 60 {
+		t.Fatalf("Downloads = %+v, want doc-a and doc-b at 60 bytes", downloads)
+	}
+
+	st.UpdateDownload(DownloadStatus{DocumentID: "doc-a"}, true)
+	downloads = st.GetStatus().CurrentSync.Downloads
+	if len(downloads) != 1 || downloads[0].DocumentID != "doc-b" {
+		t.Errorf("Downloads after doc-a finished = %+v, want only doc-b", downloads)
+	}
+
+	st.SyncCompleted(SyncSummary{})
+	st.SyncStarted(1)
+	if downloads := st.GetStatus().CurrentSync.Downloads; len(downloads) != 0 {
+		t.Errorf("Downloads of a new sync = %+v, want none", downloads)
+	}
+}
+
 func TestStatusJSON(t *testing.T) {
 	st := NewStatusTracker()
 
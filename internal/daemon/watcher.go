@@ -0,0 +1,127 @@
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// DefaultWatchSettleDelay is how long the watch directory must go without changes
+// before dropped files are imported
+const DefaultWatchSettleDelay = 2 * time.Second
+
+// fileSnapshot captures the attributes used to detect that a file has changed
+type fileSnapshot struct {
+	size    int64
+	modTime time.Time
+}
+
+// dirWatcher watches a local directory for dropped .rmdoc files using fsnotify
+//
+// Files touched by an event are held as pending; the daemon collects them once no
+// event arrived for the settle delay, so partially copied files are not picked up.
+// Files are reported again if their contents change after being reported.
+type dirWatcher struct {
+	dir      string
+	fsw      *fsnotify.Watcher
+	pending  map[string]bool
+	reported map[string]fileSnapshot
+}
+
+// newDirWatcher starts watching the given directory
+// Files already in the directory are pending, so documents dropped while the daemon
+// was stopped are imported too.
+func newDirWatcher(dir string) (*dirWatcher, error) {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create watcher: %w", err)
+	}
+	if err := fsw.Add(dir); err != nil {
+		_ = fsw.Close()
+		return nil, fmt.Errorf("failed to watch %s: %w", dir, err)
+	}
+
+	w := &dirWatcher{
+		dir:      dir,
+		fsw:      fsw,
+		pending:  make(map[string]bool),
+		reported: make(map[string]fileSnapshot),
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		_ = fsw.Close()
+		return nil, err
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() && isRmdoc(entry.Name()) {
+			w.pending[filepath.Join(dir, entry.Name())] = true
+		}
+	}
+	return w, nil
+}
+
+// isRmdoc reports whether a file name has the .rmdoc extension
+func isRmdoc(name string) bool {
+	return strings.EqualFold(filepath.Ext(name), ".rmdoc")
+}
+
+// hasPending reports whether files are waiting to settle
+func (w *dirWatcher) hasPending() bool {
+	return len(w.pending) > 0
+}
+
+// handle records a file system event, reporting whether it touched a .rmdoc file
+func (w *dirWatcher) handle(event fsnotify.Event) bool {
+	if !isRmdoc(event.Name) {
+		return false
+	}
+
+	switch {
+	case event.Has(fsnotify.Create), event.Has(fsnotify.Write):
+		w.pending[event.Name] = true
+		return true
+	case event.Has(fsnotify.Remove), event.Has(fsnotify.Rename):
+		// Forget removed files so they are re-imported if dropped again
+		delete(w.pending, event.Name)
+		delete(w.reported, event.Name)
+		return true
+	}
+	return false
+}
+
+// ready returns the pending files that are ready to import, once the directory has settled
+func (w *dirWatcher) ready() []string {
+	var ready []string
+	for path := range w.pending {
+		delete(w.pending, path)
+
+		info, err := os.Stat(path)
+		if err != nil || info.IsDir() {
+			// File disappeared before it settled
+			continue
+		}
+
+		// Already reported and unchanged
+		current := fileSnapshot{size: info.Size(), modTime: info.ModTime()}
+		if last, ok := w.reported[path]; ok && last == current {
+			continue
+		}
+
+		w.reported[path] = current
+		ready = append(ready, path)
+	}
+
+	sort.Strings(ready)
+	return ready
+}
+
+// Close stops watching the directory
+func (w *dirWatcher) Close() error {
+	return w.fsw.Close()
+}
@@ -0,0 +1,218 @@
+package daemon
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/platinummonkey/legible/internal/config"
+	"github.com/platinummonkey/legible/internal/converter"
+	"github.com/platinummonkey/legible/internal/logger"
+	"github.com/platinummonkey/legible/internal/pdfenhancer"
+	"github.com/platinummonkey/legible/internal/rmclient"
+	"github.com/platinummonkey/legible/internal/state"
+	"github.com/platinummonkey/legible/internal/sync"
+)
+
+// stubCloud is a reMarkable cloud holding a single document
+type stubCloud struct {
+	doc       rmclient.Document
+	refreshes atomic.Int32
+}
+
+func (c *stubCloud) Refresh() error {
+	c.refreshes.Add(1)
+	return nil
+}
+
+func (c *stubCloud) ListDocuments(_ []string) ([]rmclient.Document, error) {
+	return []rmclient.Document{c.doc}, nil
+}
+
+func (c *stubCloud) ListDocumentsInFolder(_ string, labels []string) ([]rmclient.Document, error) {
+	return c.ListDocuments(labels)
+}
+
+func (c *stubCloud) GetFolderPath(_ string) (string, error) {
+	return "", nil
+}
+
+func (c *stubCloud) DownloadDocument(_, outputPath string) error {
+	return os.WriteFile(outputPath, []byte("cloud rmdoc"), 0644)
+}
+
+func (c *stubCloud) DownloadDocumentWithProgress(id, outputPath string, _ rmclient.ProgressFunc) error {
+	return c.DownloadDocument(id, outputPath)
+}
+
+// stubConverter writes a stub PDF for every document, counting the conversions of
+// files in dir
+type stubConverter struct {
+	dir   string
+	local atomic.Int32
+}
+
+func (c *stubConverter) ConvertRmdocContext(_ context.Context, rmdocPath, outputPath string) (*converter.ConversionResult, error) {
+	if filepath.Dir(rmdocPath) == c.dir {
+		c.local.Add(1)
+	}
+	if err := os.WriteFile(outputPath, []byte("%PDF-1.4 stub"), 0644); err != nil {
+		return nil, err
+	}
+	result := converter.NewConversionResult()
+	result.PageCount = 1
+	return result, nil
+}
+
+func TestDirWatcher_ReportsFilesOnce(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "existing.rmdoc")
+	if err := os.WriteFile(existing, []byte("content"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	w, err := newDirWatcher(dir)
+	if err != nil {
+		t.Fatalf("newDirWatcher() error = %v", err)
+	}
+	defer func() { _ = w.Close() }()
+
+	// Files already in the directory are pending from the start
+	if ready := w.ready(); len(ready) != 1 || ready[0] != existing {
+		t.Errorf("ready() = %v, want [%s]", ready, existing)
+	}
+
+	dropped := filepath.Join(dir, "notes.rmdoc")
+	if err := os.WriteFile(dropped, []byte("content"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if w.handle(fsnotify.Event{Name: filepath.Join(dir, "ignored.txt"), Op: fsnotify.Create}) {
+		t.Error("handle() reported an event for a file that isn't a .rmdoc")
+	}
+	if !w.handle(fsnotify.Event{Name: dropped, Op: fsnotify.Create}) {
+		t.Error("handle() ignored a dropped .rmdoc")
+	}
+	if ready := w.ready(); len(ready) != 1 || ready[0] != dropped {
+		t.Errorf("ready() = %v, want [%s]", ready, dropped)
+	}
+
+	// Events that leave the file unchanged don't report it again
+	w.handle(fsnotify.Event{Name: dropped, Op: fsnotify.Write})
+	if ready := w.ready(); len(ready) != 0 {
+		t.Errorf("ready() = %v, want no files", ready)
+	}
+
+	// A removed file is reported again when dropped again
+	w.handle(fsnotify.Event{Name: dropped, Op: fsnotify.Remove})
+	w.handle(fsnotify.Event{Name: dropped, Op: fsnotify.Create})
+	if ready := w.ready(); len(ready) != 1 || ready[0] != dropped {
+		t.Errorf("ready() = %v, want [%s]", ready, dropped)
+	}
+}
+
+// waitFor polls cond until it holds, for up to five seconds
+func waitFor(cond func() bool) {
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline) && !cond(); {
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestRun_ScheduledSyncAndWatchDirShareState(t *testing.T) {
+	tmpDir := t.TempDir()
+	watchDir := filepath.Join(tmpDir, "watch")
+	store := state.NewManager(filepath.Join(tmpDir, "state.json"))
+	cloud := &stubCloud{doc: rmclient.Document{
+		ID:      "cloud-doc",
+		Name:    "Cloud Doc",
+		Type:    rmclient.DocumentType,
+		Version: 1,
+	}}
+	conv := &stubConverter{dir: watchDir}
+
+	orch, err := sync.New(&sync.Config{
+		Config:      &config.Config{OutputDir: filepath.Join(tmpDir, "output")},
+		Logger:      logger.Get(),
+		RMClient:    cloud,
+		StateStore:  store,
+		Converter:   conv,
+		PDFEnhancer: pdfenhancer.New(&pdfenhancer.Config{}),
+	})
+	if err != nil {
+		t.Fatalf("sync.New() error = %v", err)
+	}
+
+	d, err := New(&Config{
+		Orchestrator:     orch,
+		Logger:           logger.Get(),
+		SyncInterval:     20 * time.Millisecond,
+		WatchDir:         watchDir,
+		WatchSettleDelay: 10 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- d.Run(ctx)
+	}()
+
+	// Drop a local document once the daemon is watching
+	waitFor(func() bool {
+		_, err := os.Stat(watchDir)
+		return err == nil
+	})
+	if err := os.WriteFile(filepath.Join(watchDir, "notes.rmdoc"), []byte("local rmdoc"), 0644); err != nil {
+		t.Fatalf("failed to drop file: %v", err)
+	}
+
+	// Wait for the initial sync, at least one scheduled sync, and the import
+	waitFor(func() bool {
+		return cloud.refreshes.Load() >= 2 && conv.local.Load() >= 1
+	})
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("daemon did not shut down after context cancellation")
+	}
+
+	if got := cloud.refreshes.Load(); got < 2 {
+		t.Errorf("synced %d times, want at least 2 (initial + scheduled)", got)
+	}
+	if got := conv.local.Load(); got != 1 {
+		t.Errorf("dropped document converted %d times, want 1", got)
+	}
+
+	// The syncs and the import both saved into the same state file, and neither
+	// dropped the other's entry
+	saved := state.NewManager(filepath.Join(tmpDir, "state.json"))
+	if err := saved.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	var localIDs []string
+	for id := range saved.GetState().Documents {
+		if strings.HasPrefix(id, "local-") {
+			localIDs = append(localIDs, id)
+		}
+	}
+	if doc := saved.GetDocument("cloud-doc"); doc == nil || doc.ConversionStatus != state.ConversionStatusCompleted {
+		t.Errorf("synced document state = %+v, want completed", doc)
+	}
+	if len(localIDs) != 1 {
+		t.Errorf("dropped document entries = %v, want one", localIDs)
+	}
+	if saved.Count() != 2 {
+		t.Errorf("saved state has %d documents, want 2", saved.Count())
+	}
+}
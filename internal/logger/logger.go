@@ -3,7 +3,9 @@ package logger
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"time"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -13,6 +15,7 @@ import (
 type Logger struct {
 	*zap.SugaredLogger
 	config *Config
+	levels map[string]zapcore.Level
 }
 
 // Config holds logger configuration options
@@ -23,16 +26,43 @@ type Config struct {
 	// Format determines output format: "console" (human-readable) or "json" (machine-parseable)
 	Format string
 
-	// OutputPath is the file path for log output (empty = stdout only)
+	// OutputPath is a file that receives log output in addition to the console (empty = console only)
 	OutputPath string
 
+	// MaxSizeMB rotates the OutputPath file once it grows past this size (0 = never rotate)
+	MaxSizeMB int
+
+	// MaxBackups is the number of rotated files to keep (default: 3)
+	MaxBackups int
+
+	// Console is the console sink (default: os.Stdout)
+	Console io.Writer
+
+	// DisableConsole sends output only to OutputPath
+	DisableConsole bool
+
 	// EnableCaller adds caller information to log entries
 	EnableCaller bool
 
 	// EnableStacktrace adds stack traces to error-level logs
 	EnableStacktrace bool
+
+	// ComponentLevels overrides Level for loggers derived with Named or
+	// WithFields("component", ...), e.g. {"rmclient": "debug", "ocr": "warn"}
+	ComponentLevels map[string]string
+
+	// SamplingInitial logs the first N entries with the same level and message each
+	// second; further ones are sampled per SamplingThereafter (0 = no sampling)
+	SamplingInitial int
+
+	// SamplingThereafter logs every Mth entry past SamplingInitial within the second
+	// (0 = drop them all)
+	SamplingThereafter int
 }
 
+// samplingTick is the window over which identical entries are counted for sampling
+const samplingTick = time.Second
+
 var (
 	// defaultLogger is the global logger instance
 	defaultLogger *Logger
@@ -55,41 +85,42 @@ func New(cfg *Config) (*Logger, error) {
 		return nil, err
 	}
 
-	// Configure encoder
-	var encoderConfig zapcore.EncoderConfig
-	if cfg.Format == "json" {
-		encoderConfig = zap.NewProductionEncoderConfig()
-	} else {
-		encoderConfig = zap.NewDevelopmentEncoderConfig()
-		encoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
-		encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	// Sinks accept the most verbose configured level; each logger filters to its own
+	levels, err := parseComponentLevels(cfg.ComponentLevels)
+	if err != nil {
+		return nil, err
 	}
-
-	// Create encoder
-	var encoder zapcore.Encoder
-	if cfg.Format == "json" {
-		encoder = zapcore.NewJSONEncoder(encoderConfig)
-	} else {
-		encoder = zapcore.NewConsoleEncoder(encoderConfig)
+	sinkLevel := level
+	for _, l := range levels {
+		if l < sinkLevel {
+			sinkLevel = l
+		}
 	}
 
-	// Configure output
-	var writeSyncs []zapcore.WriteSyncer
-	writeSyncs = append(writeSyncs, zapcore.AddSync(os.Stdout))
+	// Each sink gets its own core so the file never receives terminal color codes
+	var cores []zapcore.Core
+	if !cfg.DisableConsole {
+		console := cfg.Console
+		if console == nil {
+			console = os.Stdout
+		}
+		cores = append(cores, zapcore.NewCore(newEncoder(cfg.Format, true), zapcore.AddSync(console), sinkLevel))
+	}
 
 	if cfg.OutputPath != "" {
-		file, err := os.OpenFile(cfg.OutputPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		file, err := openLogFile(cfg.OutputPath, cfg.MaxSizeMB, cfg.MaxBackups)
 		if err != nil {
-			return nil, fmt.Errorf("failed to open log file %s: %w", cfg.OutputPath, err)
+			return nil, err
 		}
-		writeSyncs = append(writeSyncs, zapcore.AddSync(file))
+		cores = append(cores, zapcore.NewCore(newEncoder(cfg.Format, false), file, sinkLevel))
 	}
 
-	// Combine outputs
-	writer := zapcore.NewMultiWriteSyncer(writeSyncs...)
-
-	// Create core
-	core := zapcore.NewCore(encoder, writer, level)
+	// Tee entries to every sink, throttling repeated entries before they fan out
+	core := zapcore.NewTee(cores...)
+	if cfg.SamplingInitial > 0 {
+		core = zapcore.NewSamplerWithOptions(core, samplingTick, cfg.SamplingInitial, cfg.SamplingThereafter)
+	}
+	core = withLevel(core, level)
 
 	// Build logger options
 	opts := []zap.Option{}
@@ -106,9 +137,25 @@ func New(cfg *Config) (*Logger, error) {
 	return &Logger{
 		SugaredLogger: zapLogger.Sugar(),
 		config:        cfg,
+		levels:        levels,
 	}, nil
 }
 
+// newEncoder creates the encoder for a sink; color only applies to console format
+func newEncoder(format string, color bool) zapcore.Encoder {
+	if format == "json" {
+		return zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig())
+	}
+
+	encoderConfig := zap.NewDevelopmentEncoderConfig()
+	encoderConfig.EncodeLevel = zapcore.CapitalLevelEncoder
+	if color {
+		encoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
+	}
+	encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	return zapcore.NewConsoleEncoder(encoderConfig)
+}
+
 // Init initializes the global logger instance
 func Init(cfg *Config) error {
 	logger, err := New(cfg)
@@ -130,13 +177,44 @@ func Get() *Logger {
 }
 
 // WithFields returns a logger with the specified fields attached for structured logging
+// A "component" field applies that component's level override, if one is configured.
 func (l *Logger) WithFields(fields ...interface{}) *Logger {
+	sugared := l.With(fields...)
+	if level, ok := l.componentLevel(fields); ok {
+		sugared = sugared.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+			return withLevel(core, level)
+		}))
+	}
+
 	return &Logger{
-		SugaredLogger: l.With(fields...),
+		SugaredLogger: sugared,
 		config:        l.config,
+		levels:        l.levels,
 	}
 }
 
+// Named returns a logger for a component, tagged with a "component" field and
+// filtered at the component's configured level
+func (l *Logger) Named(component string) *Logger {
+	return l.WithFields("component", component)
+}
+
+// componentLevel returns the level override for a "component" field among fields
+func (l *Logger) componentLevel(fields []interface{}) (zapcore.Level, bool) {
+	if len(l.levels) == 0 {
+		return 0, false
+	}
+	for i := 0; i+1 < len(fields); i += 2 {
+		if key, ok := fields[i].(string); ok && key == "component" {
+			if component, ok := fields[i+1].(string); ok {
+				level, found := l.levels[component]
+				return level, found
+			}
+		}
+	}
+	return 0, false
+}
+
 // WithDocumentID returns a logger with document_id field attached
 func (l *Logger) WithDocumentID(docID string) *Logger {
 	return l.WithFields("document_id", docID)
@@ -168,6 +246,52 @@ func parseLevel(level string) (zapcore.Level, error) {
 	}
 }
 
+// parseComponentLevels parses per-component level overrides
+func parseComponentLevels(levels map[string]string) (map[string]zapcore.Level, error) {
+	parsed := make(map[string]zapcore.Level, len(levels))
+	for component, levelStr := range levels {
+		level, err := parseLevel(levelStr)
+		if err != nil {
+			return nil, fmt.Errorf("component %q: %w", component, err)
+		}
+		parsed[component] = level
+	}
+	return parsed, nil
+}
+
+// levelCore filters a core to a minimum level, independent of the level the
+// underlying sinks were built with
+type levelCore struct {
+	zapcore.Core
+	level zapcore.Level
+}
+
+// withLevel filters core to level, replacing any filter already applied
+func withLevel(core zapcore.Core, level zapcore.Level) zapcore.Core {
+	if lc, ok := core.(*levelCore); ok {
+		core = lc.Core
+	}
+	return &levelCore{Core: core, level: level}
+}
+
+// Enabled reports whether entries at lvl pass the filter
+func (c *levelCore) Enabled(lvl zapcore.Level) bool {
+	return lvl >= c.level
+}
+
+// With adds fields while keeping the filter
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelCore{Core: c.Core.With(fields), level: c.level}
+}
+
+// Check adds the underlying core to ce if the entry passes the filter
+func (c *levelCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.Enabled(ent.Level) {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
+
 // Sync flushes any buffered log entries
 func (l *Logger) Sync() error {
 	return l.SugaredLogger.Sync()
@@ -240,6 +364,11 @@ func WithOperation(operation string) *Logger {
 	return Get().WithOperation(operation)
 }
 
+// Named returns a logger for a component with its configured level override
+func Named(component string) *Logger {
+	return Get().Named(component)
+}
+
 // WithError returns a logger with error field attached
 func WithError(err error) *Logger {
 	return Get().WithError(err)
@@ -116,6 +116,197 @@ func TestNew_FileOutput(t *testing.T) {
 	}
 }
 
+func TestNew_FileAndConsoleOutput(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "test.log")
+	var console bytes.Buffer
+
+	logger, err := New(&Config{
+		Level:      "info",
+		Format:     "console",
+		OutputPath: logFile,
+		Console:    &console,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	testMsg := "tee log message"
+	logger.Info(testMsg)
+	if err := logger.Sync(); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+
+	if !strings.Contains(console.String(), testMsg) {
+		t.Errorf("console should contain %q, got: %s", testMsg, console.String())
+	}
+
+	content, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	if !strings.Contains(string(content), testMsg) {
+		t.Errorf("log file should contain %q, got: %s", testMsg, string(content))
+	}
+
+	// Color codes are for terminals only
+	if strings.Contains(string(content), "\x1b[") {
+		t.Errorf("log file should not contain color codes, got: %q", string(content))
+	}
+	if !strings.Contains(console.String(), "\x1b[") {
+		t.Errorf("console output should be colored, got: %q", console.String())
+	}
+}
+
+func TestNew_DisableConsole(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "test.log")
+	var console bytes.Buffer
+
+	logger, err := New(&Config{
+		Level:          "info",
+		Format:         "json",
+		OutputPath:     logFile,
+		Console:        &console,
+		DisableConsole: true,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	logger.Info("file only")
+	_ = logger.Sync()
+
+	if console.Len() != 0 {
+		t.Errorf("console should be empty, got: %s", console.String())
+	}
+	content, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	if !strings.Contains(string(content), "file only") {
+		t.Errorf("log file should contain message, got: %s", string(content))
+	}
+}
+
+func TestRotatingFile(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "test.log")
+
+	ws, err := openLogFile(logFile, 1, 2)
+	if err != nil {
+		t.Fatalf("openLogFile() error = %v", err)
+	}
+	r := ws.(*rotatingFile)
+	r.maxSize = 10
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		if _, err := r.Write([]byte(line)); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+
+	want := map[string]string{
+		logFile:        "fourth\n",
+		logFile + ".1": "third\n",
+		logFile + ".2": "second\n",
+	}
+	for path, content := range want {
+		got, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", path, err)
+		}
+		if string(got) != content {
+			t.Errorf("%s = %q, want %q", filepath.Base(path), got, content)
+		}
+	}
+
+	// Only MaxBackups rotated files are kept
+	if _, err := os.Stat(logFile + ".3"); !os.IsNotExist(err) {
+		t.Error("rotation should keep at most 2 backups")
+	}
+}
+
+func TestComponentLevels(t *testing.T) {
+	var console bytes.Buffer
+	logger, err := New(&Config{
+		Level:           "info",
+		Format:          "json",
+		Console:         &console,
+		ComponentLevels: map[string]string{"rmclient": "debug", "ocr": "warn"},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	logger.Debug("global debug")
+	logger.Info("global info")
+	logger.Named("rmclient").Debug("rmclient debug")
+	logger.WithFields("component", "rmclient").WithFields("doc", "1").Debug("rmclient field debug")
+	logger.WithFields("component", "ocr").Info("ocr info")
+	logger.Named("ocr").Warn("ocr warn")
+	logger.Named("sync").Debug("sync debug")
+	_ = logger.Sync()
+
+	out := console.String()
+	for _, want := range []string{"global info", "rmclient debug", "rmclient field debug", "ocr warn"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output should contain %q, got: %s", want, out)
+		}
+	}
+	for _, unwanted := range []string{"global debug", "ocr info", "sync debug"} {
+		if strings.Contains(out, unwanted) {
+			t.Errorf("output should not contain %q, got: %s", unwanted, out)
+		}
+	}
+
+	if !strings.Contains(out, `"component":"rmclient"`) {
+		t.Errorf("Named() should add a component field, got: %s", out)
+	}
+}
+
+func TestComponentLevels_Invalid(t *testing.T) {
+	_, err := New(&Config{Level: "info", ComponentLevels: map[string]string{"ocr": "loud"}})
+	if err == nil {
+		t.Error("New() should error for an invalid component level")
+	}
+}
+
+func TestSampling(t *testing.T) {
+	var console bytes.Buffer
+	logger, err := New(&Config{
+		Level:              "debug",
+		Format:             "json",
+		Console:            &console,
+		SamplingInitial:    2,
+		SamplingThereafter: 3,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	for i := 0; i < 10; i++ {
+		logger.Debug("stroke parsed")
+	}
+	logger.Info("stroke parsed")
+	logger.Debug("page parsed")
+	_ = logger.Sync()
+
+	out := console.String()
+	repeated := 0
+	for _, line := range strings.Split(out, "\n") {
+		if strings.Contains(line, `"level":"debug"`) && strings.Contains(line, "stroke parsed") {
+			repeated++
+		}
+	}
+	// Entries 1 and 2, then every 3rd after that (5 and 8)
+	if repeated != 4 {
+		t.Errorf("got %d sampled debug entries, want 4: %s", repeated, out)
+	}
+	for _, want := range []string{`"level":"info"`, "page parsed"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output should contain %q, got: %s", want, out)
+		}
+	}
+}
+
 func TestNew_InvalidLogLevel(t *testing.T) {
 	cfg := &Config{
 		Level:  "invalid",
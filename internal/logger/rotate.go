@@ -0,0 +1,107 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// defaultMaxBackups is the number of rotated log files kept when MaxBackups is unset
+const defaultMaxBackups = 3
+
+// rotatingFile is a log file that is rotated once it exceeds maxSize bytes
+//
+// Rotated files are renamed path.1, path.2, ... with path.1 the most recent;
+// files beyond maxBackups are removed.
+type rotatingFile struct {
+	mu         sync.Mutex
+	path       string
+	file       *os.File
+	size       int64
+	maxSize    int64
+	maxBackups int
+}
+
+// openLogFile opens path for appending, rotating it when maxSizeMB is positive
+func openLogFile(path string, maxSizeMB, maxBackups int) (zapcore.WriteSyncer, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log file %s: %w", path, err)
+	}
+
+	if maxSizeMB <= 0 {
+		return zapcore.AddSync(file), nil
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("failed to stat log file %s: %w", path, err)
+	}
+
+	if maxBackups <= 0 {
+		maxBackups = defaultMaxBackups
+	}
+
+	return &rotatingFile{
+		path:       path,
+		file:       file,
+		size:       info.Size(),
+		maxSize:    int64(maxSizeMB) * 1024 * 1024,
+		maxBackups: maxBackups,
+	}, nil
+}
+
+// Write appends p to the log file, rotating first if p would push it past the size limit
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// Sync flushes the current log file to disk
+func (r *rotatingFile) Sync() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file.Sync()
+}
+
+// rotate shifts existing backups up by one and starts a new, empty log file
+func (r *rotatingFile) rotate() error {
+	if err := r.file.Close(); err != nil {
+		return fmt.Errorf("failed to close log file: %w", err)
+	}
+
+	_ = os.Remove(r.backupName(r.maxBackups))
+	for i := r.maxBackups - 1; i >= 1; i-- {
+		_ = os.Rename(r.backupName(i), r.backupName(i+1))
+	}
+	if err := os.Rename(r.path, r.backupName(1)); err != nil {
+		return fmt.Errorf("failed to rotate log file: %w", err)
+	}
+
+	file, err := os.OpenFile(r.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to reopen log file %s: %w", r.path, err)
+	}
+
+	r.file = file
+	r.size = 0
+	return nil
+}
+
+// backupName returns the path of the n-th rotated log file
+func (r *rotatingFile) backupName(n int) string {
+	return fmt.Sprintf("%s.%d", r.path, n)
+}
@@ -1,14 +1,15 @@
-//go:build darwin
-// +build darwin
+//go:build darwin || linux
+// +build darwin linux
 
-// Package menubar provides the menu bar application logic for macOS.
+// Package menubar provides the menu bar application logic for the macOS menu bar and
+// the Linux system tray.
 package menubar
 
 import (
+	"bytes"
 	"context"
 	"fmt"
-	"os/exec"
-	"runtime"
+	"sync"
 	"time"
 
 	"fyne.io/systray"
@@ -19,21 +20,32 @@ import (
 type App struct {
 	// Menu items
 	mStatus        *systray.MenuItem
+	mProgress      *systray.MenuItem
 	mStartSync     *systray.MenuItem
 	mStopSync      *systray.MenuItem
 	mOpenOutput    *systray.MenuItem
+	mRecent        *systray.MenuItem
+	mRecentItems   []*systray.MenuItem
 	mStartDaemon   *systray.MenuItem
 	mRestartDaemon *systray.MenuItem
 	mStopDaemon    *systray.MenuItem
 	mAutoStart     *systray.MenuItem
 	mPreferences   *systray.MenuItem
+	mApplySettings *systray.MenuItem
 	mQuit          *systray.MenuItem
 
 	// Application state
-	isRunning  bool
-	outputDir  string
-	statusText string
-	daemonAddr string
+	isRunning    bool
+	outputDir    string
+	statusText   string
+	progressText string // "" while the progress item is hidden
+	icon         []byte
+	lastState    SyncState // the daemon's state at the last status update, for notifications
+	daemonAddr   string
+
+	// Recent documents, newest first, shown in the Recent Documents submenu
+	recentDocs []string
+	recentMu   sync.Mutex
 
 	// Configuration
 	menuBarConfig *MenuBarConfig
@@ -97,13 +109,17 @@ func (a *App) onReady() {
 	logger.Info("Menu bar application starting")
 
 	// Set initial icon (gray/starting state)
-	systray.SetIcon(iconGreen())
+	a.icon = iconGreen()
+	systray.SetIcon(a.icon)
 	systray.SetTitle("") // No text - just icon
 	systray.SetTooltip("reMarkable Sync - Starting...")
 
 	// Create menu items
 	a.mStatus = systray.AddMenuItem("Status: Starting daemon...", "Current sync status")
 	a.mStatus.Disable() // Status is informational only
+	a.mProgress = systray.AddMenuItem("", "Sync progress")
+	a.mProgress.Disable()
+	a.mProgress.Hide() // Shown while syncing
 
 	systray.AddSeparator()
 
@@ -113,7 +129,8 @@ func (a *App) onReady() {
 
 	systray.AddSeparator()
 
-	a.mOpenOutput = systray.AddMenuItem("Open Output Folder", "Open the output directory in Finder")
+	a.mOpenOutput = systray.AddMenuItem("Open Output Folder", "Open the output directory")
+	a.addRecentDocumentsMenu()
 
 	systray.AddSeparator()
 
@@ -137,6 +154,8 @@ func (a *App) onReady() {
 	}
 
 	a.mPreferences = systray.AddMenuItem("Preferences...", "Configure settings")
+	a.mApplySettings = systray.AddMenuItem("Apply Settings", "Reload the daemon's configuration without restarting it")
+	a.mApplySettings.Disable() // Enabled while the daemon is reachable
 
 	systray.AddSeparator()
 
@@ -155,6 +174,7 @@ func (a *App) onReady() {
 
 	// Start event loop and status polling
 	go a.handleMenuEvents()
+	a.handleRecentDocumentClicks()
 	go a.pollDaemonStatus()
 }
 
@@ -194,6 +214,8 @@ func (a *App) handleMenuEvents() {
 			a.handleAutoStartToggle()
 		case <-a.mPreferences.ClickedCh:
 			a.handlePreferences()
+		case <-a.mApplySettings.ClickedCh:
+			a.handleApplySettings()
 		case <-a.mQuit.ClickedCh:
 			systray.Quit()
 			return
@@ -232,17 +254,11 @@ func (a *App) handleStopSync() {
 	logger.Info("Sync cancellation requested")
 }
 
-// handleOpenOutput opens the output directory in Finder.
+// handleOpenOutput opens the output directory in the file manager.
 func (a *App) handleOpenOutput() {
 	logger.Info("Open output folder clicked", "path", a.outputDir)
 
-	if runtime.GOOS != "darwin" {
-		logger.Warn("Open folder only supported on macOS")
-		return
-	}
-
-	cmd := exec.Command("open", a.outputDir)
-	if err := cmd.Run(); err != nil {
+	if err := openPath(a.outputDir); err != nil {
 		logger.Error("Failed to open output folder", "error", err, "path", a.outputDir)
 	}
 }
@@ -365,12 +381,24 @@ func (a *App) handleStopDaemon() {
 func (a *App) handlePreferences() {
 	logger.Info("Preferences clicked")
 
-	if runtime.GOOS == "darwin" {
-		// Don't use goroutine - Cocoa must run on main thread
-		a.showPreferencesWindow()
-	} else {
-		logger.Info("Preferences", "config_path", a.configPath)
+	// Don't use goroutine - on macOS, Cocoa must run on main thread
+	a.showPreferencesWindow()
+}
+
+// handleApplySettings asks the daemon to reload its configuration, applying setting
+// changes that don't need a restart.
+func (a *App) handleApplySettings() {
+	logger.Info("Apply settings clicked")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := a.daemonClient.Reload(ctx); err != nil {
+		logger.Error("Failed to apply settings", "error", err)
+		a.setStatus(fmt.Sprintf("Error: %s", err.Error()), iconRed())
+		return
 	}
+
+	logger.Info("Settings applied")
 }
 
 // showPreferencesWindow shows the native preferences window
@@ -379,35 +407,42 @@ func (a *App) showPreferencesWindow() {
 	// Note: callback will handle save and restart prompt
 }
 
-// showRestartPrompt shows a dialog asking user to restart the app
-func (a *App) showRestartPrompt() {
-	script := `display dialog "Settings saved successfully!\n\nTo apply changes, please restart the menu bar app.\n\nWould you like to quit now?" buttons {"Later", "Quit App"} default button "Quit App" with title "Restart Required" with icon note`
-	cmd := exec.Command("osascript", "-e", script)
-	output, err := cmd.CombinedOutput()
-
-	if err == nil && len(output) > 0 {
-		outputStr := string(output)
-		if len(outputStr) > 20 && outputStr[16:24] == "Quit App" {
-			logger.Info("User requested app restart, quitting...")
-			systray.Quit()
-		}
+// setStatus updates the status display and icon.
+// Only what changed is updated, so polls that find the same status don't redraw the
+// icon and make it flicker.
+func (a *App) setStatus(status string, icon []byte) {
+	if status != a.statusText {
+		a.statusText = status
+		a.mStatus.SetTitle(fmt.Sprintf("Status: %s", status))
+		systray.SetTooltip(fmt.Sprintf("reMarkable Sync - %s", status))
+	}
+	if !bytes.Equal(icon, a.icon) {
+		a.icon = icon
+		systray.SetIcon(icon)
 	}
 }
 
-// showErrorDialog displays an error message in a native macOS dialog
-func (a *App) showErrorDialog(message string) {
-	script := fmt.Sprintf(`display dialog %q buttons {"OK"} default button "OK" with title "Legible Error" with icon stop`,
-		message)
-	cmd := exec.Command("osascript", "-e", script)
-	_ = cmd.Run() // Ignore error
-}
+// setProgress shows a sync's progress in the progress menu item, or hides it when
+// progress is nil
+func (a *App) setProgress(progress *SyncProgress) {
+	text := ""
+	if progress != nil {
+		text = progressBar(progress.DocumentsProcessed, progress.DocumentsTotal)
+	}
+	if text == a.progressText {
+		return
+	}
 
-// setStatus updates the status display and icon.
-func (a *App) setStatus(status string, icon []byte) {
-	a.statusText = status
-	a.mStatus.SetTitle(fmt.Sprintf("Status: %s", status))
-	systray.SetIcon(icon)
-	systray.SetTooltip(fmt.Sprintf("reMarkable Sync - %s", status))
+	switch {
+	case text == "":
+		a.mProgress.Hide()
+	case a.progressText == "":
+		a.mProgress.SetTitle(text)
+		a.mProgress.Show()
+	default:
+		a.mProgress.SetTitle(text)
+	}
+	a.progressText = text
 }
 
 // SetStatusIdle sets the status to idle (green).
@@ -452,15 +487,20 @@ func (a *App) pollDaemonStatus() {
 
 // updateStatusFromDaemon fetches status from daemon and updates UI
 func (a *App) updateStatusFromDaemon() {
+	a.updateRecentDocuments()
+
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 	defer cancel()
 
 	status, err := a.daemonClient.GetStatus(ctx)
 	if err != nil {
 		logger.Error("Failed to get daemon status", "error", err)
+		a.notifyTransition(&Status{State: StateOffline})
 		a.setStatus("Error: Cannot connect to daemon", iconRed())
+		a.setProgress(nil)
 		a.mStartSync.Disable()
 		a.mStopSync.Disable()
+		a.mApplySettings.Disable()
 
 		// Show start daemon option when daemon is unreachable
 		if a.daemonManager != nil {
@@ -472,12 +512,16 @@ func (a *App) updateStatusFromDaemon() {
 		return
 	}
 
+	a.notifyTransition(status)
+
 	// Update UI based on daemon state
 	switch status.State {
 	case StateOffline:
 		a.setStatus("Daemon offline", iconRed())
+		a.setProgress(nil)
 		a.mStartSync.Disable()
 		a.mStopSync.Disable()
+		a.mApplySettings.Disable()
 
 		// Show start daemon option when daemon is offline
 		if a.daemonManager != nil {
@@ -497,8 +541,10 @@ func (a *App) updateStatusFromDaemon() {
 				status.LastSyncResult.FailureCount)
 		}
 		a.setStatus(statusText, iconGreen())
+		a.setProgress(nil)
 		a.mStartSync.Enable()
 		a.mStopSync.Disable()
+		a.mApplySettings.Enable()
 
 		// Show restart/stop options when daemon is running
 		if a.daemonManager != nil {
@@ -510,8 +556,9 @@ func (a *App) updateStatusFromDaemon() {
 		}
 
 	case StateSyncing:
-		// Show sync progress
+		// Show sync progress, in the icon and as a progress bar below the status
 		statusText := "Syncing..."
+		icon := iconYellow()
 		if status.CurrentSync != nil {
 			if status.CurrentSync.DocumentsTotal > 0 {
 				statusText = fmt.Sprintf("Syncing: %d/%d docs",
@@ -521,10 +568,13 @@ func (a *App) updateStatusFromDaemon() {
 			if status.CurrentSync.CurrentDocument != "" {
 				statusText += fmt.Sprintf(" (%s)", status.CurrentSync.CurrentDocument)
 			}
+			icon = iconProgress(status.CurrentSync.DocumentsProcessed, status.CurrentSync.DocumentsTotal)
 		}
-		a.setStatus(statusText, iconYellow())
+		a.setStatus(statusText, icon)
+		a.setProgress(status.CurrentSync)
 		a.mStartSync.Disable()
 		a.mStopSync.Enable()
+		a.mApplySettings.Enable()
 
 		// Show restart/stop options when daemon is running
 		if a.daemonManager != nil {
@@ -541,8 +591,10 @@ func (a *App) updateStatusFromDaemon() {
 			errMsg = fmt.Sprintf("Error: %s", status.ErrorMessage)
 		}
 		a.setStatus(errMsg, iconRed())
+		a.setProgress(nil)
 		a.mStartSync.Enable()
 		a.mStopSync.Disable()
+		a.mApplySettings.Enable()
 
 		// Show restart/stop options when daemon is running
 		if a.daemonManager != nil {
@@ -0,0 +1,115 @@
+//go:build linux
+// +build linux
+
+package menubar
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// autostartEntryTemplate is an XDG autostart desktop entry, which desktop environments,
+// and systemd's xdg-autostart-generator, start at login
+const autostartEntryTemplate = `[Desktop Entry]
+Type=Application
+Name=Legible
+Comment=reMarkable sync status in the system tray
+Exec={{.Exec}}
+Terminal=false
+X-GNOME-Autostart-enabled=true
+`
+
+// AutostartEntryConfig holds the configuration for the autostart desktop entry
+type AutostartEntryConfig struct {
+	Exec string
+}
+
+// GetAutostartEntryPath returns the path to the XDG autostart desktop entry
+func GetAutostartEntryPath() (string, error) {
+	configDir := os.Getenv("XDG_CONFIG_HOME")
+	if configDir == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get home directory: %w", err)
+		}
+		configDir = filepath.Join(homeDir, ".config")
+	}
+	return filepath.Join(configDir, "autostart", "legible-menubar.desktop"), nil
+}
+
+// IsAutoStartEnabled checks if auto-start is currently enabled
+func IsAutoStartEnabled() (bool, error) {
+	entryPath, err := GetAutostartEntryPath()
+	if err != nil {
+		return false, err
+	}
+
+	_, err = os.Stat(entryPath)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check autostart entry: %w", err)
+	}
+
+	return true, nil
+}
+
+// EnableAutoStart writes the autostart desktop entry for the running executable
+func EnableAutoStart() error {
+	exePath, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to find executable: %w", err)
+	}
+
+	entryPath, err := GetAutostartEntryPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(entryPath), 0755); err != nil {
+		return fmt.Errorf("failed to create autostart directory: %w", err)
+	}
+
+	tmpl, err := template.New("desktop").Parse(autostartEntryTemplate)
+	if err != nil {
+		return fmt.Errorf("failed to parse autostart entry template: %w", err)
+	}
+
+	var entry strings.Builder
+	if err := tmpl.Execute(&entry, AutostartEntryConfig{Exec: desktopExecQuote(exePath)}); err != nil {
+		return fmt.Errorf("failed to render autostart entry: %w", err)
+	}
+	if err := os.WriteFile(entryPath, []byte(entry.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write autostart entry: %w", err)
+	}
+
+	return nil
+}
+
+// DisableAutoStart removes the autostart desktop entry
+func DisableAutoStart() error {
+	entryPath, err := GetAutostartEntryPath()
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(entryPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove autostart entry: %w", err)
+	}
+
+	return nil
+}
+
+// desktopExecQuote quotes a path for the Exec key of a desktop entry
+// Paths with characters the desktop entry specification reserves are double-quoted,
+// with ", `, $ and \ escaped, and every backslash escaped again for the string value.
+func desktopExecQuote(path string) string {
+	if !strings.ContainsAny(path, " \t\n\"'\\><~|&;$*?#()`") {
+		return path
+	}
+	escaped := strings.NewReplacer(`"`, `\"`, "`", "\\`", `$`, `\$`, `\`, `\\`).Replace(path)
+	return `"` + strings.ReplaceAll(escaped, `\`, `\\`) + `"`
+}
@@ -0,0 +1,74 @@
+//go:build linux
+// +build linux
+
+package menubar
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAutoStart_EnableDisable(t *testing.T) {
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+
+	entryPath, err := GetAutostartEntryPath()
+	if err != nil {
+		t.Fatalf("GetAutostartEntryPath() error = %v", err)
+	}
+	if want := filepath.Join(configHome, "autostart", "legible-menubar.desktop"); entryPath != want {
+		t.Errorf("GetAutostartEntryPath() = %s, want %s", entryPath, want)
+	}
+
+	if enabled, err := IsAutoStartEnabled(); err != nil || enabled {
+		t.Fatalf("IsAutoStartEnabled() = %t, %v before enabling, want false", enabled, err)
+	}
+
+	if err := EnableAutoStart(); err != nil {
+		t.Fatalf("EnableAutoStart() error = %v", err)
+	}
+	if enabled, err := IsAutoStartEnabled(); err != nil || !enabled {
+		t.Errorf("IsAutoStartEnabled() = %t, %v after enabling, want true", enabled, err)
+	}
+
+	data, err := os.ReadFile(entryPath)
+	if err != nil {
+		t.Fatalf("Failed to read autostart entry: %v", err)
+	}
+	exePath, _ := os.Executable()
+	entry := string(data)
+	if !strings.HasPrefix(entry, "[Desktop Entry]\n") || !strings.Contains(entry, "Exec="+desktopExecQuote(exePath)+"\n") {
+		t.Errorf("autostart entry = %q, want a desktop entry starting %s", entry, exePath)
+	}
+
+	if err := DisableAutoStart(); err != nil {
+		t.Fatalf("DisableAutoStart() error = %v", err)
+	}
+	if enabled, err := IsAutoStartEnabled(); err != nil || enabled {
+		t.Errorf("IsAutoStartEnabled() = %t, %v after disabling, want false", enabled, err)
+	}
+
+	// Disabling again is a no-op
+	if err := DisableAutoStart(); err != nil {
+		t.Errorf("DisableAutoStart() when already disabled error = %v", err)
+	}
+}
+
+func TestDesktopExecQuote(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "/usr/bin/legible-menubar", want: "/usr/bin/legible-menubar"},
+		{path: "/opt/My Apps/legible-menubar", want: `"/opt/My Apps/legible-menubar"`},
+		{path: `/opt/$x/legible-menubar`, want: `"/opt/\\$x/legible-menubar"`},
+	}
+
+	for _, tt := range tests {
+		if got := desktopExecQuote(tt.path); got != tt.want {
+			t.Errorf("desktopExecQuote(%q) = %s, want %s", tt.path, got, tt.want)
+		}
+	}
+}
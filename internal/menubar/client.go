@@ -1,5 +1,5 @@
-//go:build darwin
-// +build darwin
+//go:build darwin || linux
+// +build darwin linux
 
 package menubar
 
@@ -155,6 +155,40 @@ func (c *DaemonClient) CancelSync(ctx context.Context) error {
 	return nil
 }
 
+// Reload asks the daemon to re-read its configuration file and apply the settings
+// that can change while it runs
+func (c *DaemonClient) Reload(ctx context.Context) error {
+	url := fmt.Sprintf("%s/reload", c.baseURL)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reload configuration: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	// Report why the daemon couldn't apply the configuration when it says
+	var body struct {
+		Message string `json:"message"`
+		Error   string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err == nil && body.Message != "" {
+		if body.Error != "" {
+			return fmt.Errorf("%s: %s", body.Message, body.Error)
+		}
+		return fmt.Errorf("%s", body.Message)
+	}
+	return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+}
+
 // IsHealthy checks if the daemon is responding
 func (c *DaemonClient) IsHealthy(ctx context.Context) bool {
 	url := fmt.Sprintf("%s/health", c.baseURL)
@@ -1,5 +1,5 @@
-//go:build darwin
-// +build darwin
+//go:build darwin || linux
+// +build darwin linux
 
 package menubar
 
@@ -152,6 +152,52 @@ func TestDaemonClient_TriggerSync_Conflict(t *testing.T) {
 	}
 }
 
+func TestDaemonClient_Reload_Success(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("Expected POST, got %s", r.Method)
+		}
+
+		if r.URL.Path != "/reload" {
+			t.Errorf("Expected /reload, got %s", r.URL.Path)
+		}
+
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"message": "Configuration reloaded",
+		})
+	}))
+	defer server.Close()
+
+	client := NewDaemonClient(server.URL)
+	if err := client.Reload(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+}
+
+func TestDaemonClient_Reload_Failed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"message": "Failed to apply configuration",
+			"error":   "OCR was disabled when the converter was created",
+		})
+	}))
+	defer server.Close()
+
+	client := NewDaemonClient(server.URL)
+	err := client.Reload(context.Background())
+	if err == nil {
+		t.Fatal("Expected error for a refused reload, got nil")
+	}
+
+	if err.Error() != "Failed to apply configuration: OCR was disabled when the converter was created" {
+		t.Errorf("Expected the daemon's reason, got %s", err.Error())
+	}
+}
+
 func TestDaemonClient_IsHealthy(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path != "/health" {
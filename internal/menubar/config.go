@@ -1,5 +1,5 @@
-//go:build darwin
-// +build darwin
+//go:build darwin || linux
+// +build darwin linux
 
 package menubar
 
@@ -29,6 +29,9 @@ type MenuBarConfig struct {
 
 	// Enable OCR
 	OCREnabled bool `yaml:"ocr_enabled"`
+
+	// Post desktop notifications when a sync finishes or fails
+	NotificationsEnabled bool `yaml:"notifications_enabled"`
 }
 
 // DefaultMenuBarConfig returns default configuration.
@@ -37,11 +40,12 @@ func DefaultMenuBarConfig() *MenuBarConfig {
 	defaultDaemonConfig := filepath.Join(homeDir, ".legible.yaml")
 
 	return &MenuBarConfig{
-		DaemonConfigFile: defaultDaemonConfig,
-		AutoStartEnabled: false,
-		DaemonAddr:       "http://localhost:8080",
-		SyncInterval:     "30m",
-		OCREnabled:       true,
+		DaemonConfigFile:     defaultDaemonConfig,
+		AutoStartEnabled:     false,
+		DaemonAddr:           "http://localhost:8080",
+		SyncInterval:         "30m",
+		OCREnabled:           true,
+		NotificationsEnabled: true,
 	}
 }
 
@@ -69,13 +73,14 @@ func LoadMenuBarConfig(path string) (*MenuBarConfig, error) {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
-	// Parse YAML
-	var cfg MenuBarConfig
-	if err := yaml.Unmarshal(data, &cfg); err != nil {
+	// Parse YAML over the defaults, so settings missing from the file (such as ones
+	// added since it was saved) keep their default
+	cfg := DefaultMenuBarConfig()
+	if err := yaml.Unmarshal(data, cfg); err != nil {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
 
-	return &cfg, nil
+	return cfg, nil
 }
 
 // SaveMenuBarConfig saves the menu bar configuration to file.
@@ -1,5 +1,5 @@
-//go:build darwin
-// +build darwin
+//go:build darwin || linux
+// +build darwin linux
 
 package menubar
 
@@ -31,6 +31,10 @@ func TestDefaultMenuBarConfig(t *testing.T) {
 	if cfg.AutoStartEnabled != false {
 		t.Error("Expected default AutoStartEnabled to be false")
 	}
+
+	if cfg.NotificationsEnabled != true {
+		t.Error("Expected default NotificationsEnabled to be true")
+	}
 }
 
 func TestSaveAndLoadMenuBarConfig(t *testing.T) {
@@ -91,6 +95,28 @@ func TestSaveAndLoadMenuBarConfig(t *testing.T) {
 	}
 }
 
+func TestLoadMenuBarConfigMissingSettings(t *testing.T) {
+	// A config saved before notifications were added doesn't have the setting
+	testPath := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(testPath, []byte("daemon_addr: http://localhost:9090\nocr_enabled: false\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	cfg, err := LoadMenuBarConfig(testPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if cfg.DaemonAddr != "http://localhost:9090" || cfg.OCREnabled {
+		t.Errorf("Expected the saved settings, got DaemonAddr %s and OCREnabled %v", cfg.DaemonAddr, cfg.OCREnabled)
+	}
+
+	if cfg.SyncInterval != "30m" || !cfg.NotificationsEnabled {
+		t.Errorf("Expected missing settings to keep their defaults, got SyncInterval %s and NotificationsEnabled %v",
+			cfg.SyncInterval, cfg.NotificationsEnabled)
+	}
+}
+
 func TestLoadMenuBarConfigNonExistent(t *testing.T) {
 	// Try to load config from non-existent path
 	// Should return default config, not an error
@@ -114,8 +140,13 @@ func TestLoadMenuBarConfigNonExistent(t *testing.T) {
 func TestSaveMenuBarConfigInvalidPath(t *testing.T) {
 	cfg := DefaultMenuBarConfig()
 
-	// Try to save to invalid path (directory that doesn't exist)
-	err := SaveMenuBarConfig(cfg, "/non-existent-directory/test-config.yaml")
+	// Try to save to invalid path (a directory that can't be created, even as root,
+	// because its parent is a file)
+	parent := filepath.Join(t.TempDir(), "not-a-directory")
+	if err := os.WriteFile(parent, nil, 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	err := SaveMenuBarConfig(cfg, filepath.Join(parent, "test-config.yaml"))
 
 	if err == nil {
 		t.Error("Expected error when saving to invalid path, got nil")
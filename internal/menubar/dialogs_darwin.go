@@ -0,0 +1,48 @@
+//go:build darwin
+// +build darwin
+
+package menubar
+
+import (
+	"fmt"
+	"os/exec"
+
+	"fyne.io/systray"
+	"github.com/platinummonkey/legible/internal/logger"
+)
+
+// openPath opens a file or directory with its default application, directories in Finder
+func openPath(path string) error {
+	return exec.Command("open", path).Run()
+}
+
+// showRestartPrompt shows a dialog asking user to restart the app
+func (a *App) showRestartPrompt() {
+	script := `display dialog "Settings saved successfully!\n\nTo apply changes, please restart the menu bar app.\n\nWould you like to quit now?" buttons {"Later", "Quit App"} default button "Quit App" with title "Restart Required" with icon note`
+	cmd := exec.Command("osascript", "-e", script)
+	output, err := cmd.CombinedOutput()
+
+	if err == nil && len(output) > 0 {
+		outputStr := string(output)
+		if len(outputStr) > 20 && outputStr[16:24] == "Quit App" {
+			logger.Info("User requested app restart, quitting...")
+			systray.Quit()
+		}
+	}
+}
+
+// showErrorDialog displays an error message in a native macOS dialog
+func (a *App) showErrorDialog(message string) {
+	script := fmt.Sprintf(`display dialog %q buttons {"OK"} default button "OK" with title "Legible Error" with icon stop`,
+		message)
+	cmd := exec.Command("osascript", "-e", script)
+	_ = cmd.Run() // Ignore error
+}
+
+// notify posts a notification to Notification Center
+func notify(title, message string) {
+	script := fmt.Sprintf(`display notification %q with title %q`, message, title)
+	if err := exec.Command("osascript", "-e", script).Run(); err != nil {
+		logger.Warn("Failed to show notification", "title", title, "message", message, "error", err)
+	}
+}
@@ -0,0 +1,56 @@
+//go:build linux
+// +build linux
+
+package menubar
+
+import (
+	"os/exec"
+
+	"fyne.io/systray"
+	"github.com/platinummonkey/legible/internal/logger"
+)
+
+// openPath opens a file or directory with the desktop's default application, such as
+// its file manager for directories
+func openPath(path string) error {
+	return exec.Command("xdg-open", path).Run()
+}
+
+// showRestartPrompt shows a dialog asking user to restart the app
+// Without zenity, a notification asks the user to restart it instead.
+func (a *App) showRestartPrompt() {
+	const message = "Settings saved successfully!\n\nTo apply changes, please restart the tray app."
+	if _, err := exec.LookPath("zenity"); err != nil {
+		notify("Restart Required", message)
+		return
+	}
+
+	cmd := exec.Command("zenity", "--question",
+		"--title=Restart Required",
+		"--text="+message+"\n\nWould you like to quit now?",
+		"--ok-label=Quit App",
+		"--cancel-label=Later")
+	// zenity exits with status 0 when the OK button is pressed
+	if err := cmd.Run(); err == nil {
+		logger.Info("User requested app restart, quitting...")
+		systray.Quit()
+	}
+}
+
+// showErrorDialog displays an error message in a zenity dialog, or a notification
+// without zenity
+func (a *App) showErrorDialog(message string) {
+	if _, err := exec.LookPath("zenity"); err != nil {
+		notify("Legible Error", message)
+		return
+	}
+	cmd := exec.Command("zenity", "--error", "--title=Legible Error", "--text="+message)
+	_ = cmd.Run() // Ignore error
+}
+
+// notify shows a desktop notification with notify-send, if it's installed
+func notify(title, message string) {
+	if err := exec.Command("notify-send", "--app-name=Legible", title, message).Run(); err != nil {
+		logger.Warn("Failed to show notification", "title", title, "message", message, "error", err)
+	}
+}
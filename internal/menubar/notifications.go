@@ -0,0 +1,60 @@
+//go:build darwin || linux
+// +build darwin linux
+
+package menubar
+
+import "fmt"
+
+// syncNotification returns the notification to post when the daemon goes from the
+// previous state to status, if the change is one the user should hear about: a sync
+// finishing, or the daemon starting to report an error. Nothing is posted for the
+// first status seen, when there is no previous state.
+func syncNotification(previous SyncState, status *Status) (title, message string, ok bool) {
+	switch {
+	case previous == StateSyncing && status.State == StateIdle:
+		summary := status.LastSyncResult
+		switch {
+		case summary == nil:
+			return "Sync complete", "The sync finished", true
+		case summary.FailureCount > 0:
+			return "Sync complete", fmt.Sprintf("Synced %s, %d failed",
+				pluralDocuments(summary.SuccessCount), summary.FailureCount), true
+		case summary.SuccessCount == 0:
+			return "Sync complete", "Everything is up to date", true
+		default:
+			return "Sync complete", fmt.Sprintf("Synced %s", pluralDocuments(summary.SuccessCount)), true
+		}
+
+	case previous != "" && previous != StateError && status.State == StateError:
+		message := "The sync failed"
+		if status.ErrorMessage != "" {
+			message = status.ErrorMessage
+		}
+		return "Sync failed", message, true
+	}
+
+	return "", "", false
+}
+
+// pluralDocuments formats a count of documents, such as "1 document" or "3 documents"
+func pluralDocuments(n int) string {
+	if n == 1 {
+		return "1 document"
+	}
+	return fmt.Sprintf("%d documents", n)
+}
+
+// notifyTransition records the daemon's state and posts a notification if the change
+// from the last state calls for one
+func (a *App) notifyTransition(status *Status) {
+	previous := a.lastState
+	a.lastState = status.State
+	if !a.menuBarConfig.NotificationsEnabled {
+		return
+	}
+
+	if title, message, ok := syncNotification(previous, status); ok {
+		// Posting a notification runs a command, which mustn't hold up status updates
+		go notify(title, message)
+	}
+}
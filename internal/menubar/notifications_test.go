@@ -0,0 +1,100 @@
+//go:build darwin || linux
+// +build darwin linux
+
+package menubar
+
+import "testing"
+
+func TestSyncNotification(t *testing.T) {
+	tests := []struct {
+		name        string
+		previous    SyncState
+		status      *Status
+		wantOK      bool
+		wantTitle   string
+		wantMessage string
+	}{
+		{
+			name:     "sync finished",
+			previous: StateSyncing,
+			status: &Status{State: StateIdle, LastSyncResult: &SyncSummary{
+				ProcessedDocuments: 3, SuccessCount: 3,
+			}},
+			wantOK:      true,
+			wantTitle:   "Sync complete",
+			wantMessage: "Synced 3 documents",
+		},
+		{
+			name:     "sync finished with failures",
+			previous: StateSyncing,
+			status: &Status{State: StateIdle, LastSyncResult: &SyncSummary{
+				ProcessedDocuments: 3, SuccessCount: 1, FailureCount: 2,
+			}},
+			wantOK:      true,
+			wantTitle:   "Sync complete",
+			wantMessage: "Synced 1 document, 2 failed",
+		},
+		{
+			name:        "sync finished with nothing to do",
+			previous:    StateSyncing,
+			status:      &Status{State: StateIdle, LastSyncResult: &SyncSummary{}},
+			wantOK:      true,
+			wantTitle:   "Sync complete",
+			wantMessage: "Everything is up to date",
+		},
+		{
+			name:        "sync finished without a summary",
+			previous:    StateSyncing,
+			status:      &Status{State: StateIdle},
+			wantOK:      true,
+			wantTitle:   "Sync complete",
+			wantMessage: "The sync finished",
+		},
+		{
+			name:        "sync failed",
+			previous:    StateSyncing,
+			status:      &Status{State: StateError, ErrorMessage: "authentication expired"},
+			wantOK:      true,
+			wantTitle:   "Sync failed",
+			wantMessage: "authentication expired",
+		},
+		{
+			name:        "error while idle",
+			previous:    StateIdle,
+			status:      &Status{State: StateError},
+			wantOK:      true,
+			wantTitle:   "Sync failed",
+			wantMessage: "The sync failed",
+		},
+		{name: "still syncing", previous: StateSyncing, status: &Status{State: StateSyncing}},
+		{name: "still idle", previous: StateIdle, status: &Status{State: StateIdle}},
+		{name: "still in error", previous: StateError, status: &Status{State: StateError}},
+		{name: "sync started", previous: StateIdle, status: &Status{State: StateSyncing}},
+		{name: "daemon came online", previous: StateOffline, status: &Status{State: StateIdle}},
+		{name: "daemon went offline while syncing", previous: StateSyncing, status: &Status{State: StateOffline}},
+		{name: "first status is an error", previous: "", status: &Status{State: StateError}},
+		{name: "first status is idle", previous: "", status: &Status{State: StateIdle}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			title, message, ok := syncNotification(tt.previous, tt.status)
+			if ok != tt.wantOK || title != tt.wantTitle || message != tt.wantMessage {
+				t.Errorf("syncNotification() = %q, %q, %v, want %q, %q, %v",
+					title, message, ok, tt.wantTitle, tt.wantMessage, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestNotifyTransition_TracksState(t *testing.T) {
+	// With notifications disabled nothing is posted, but the state is still tracked
+	a := &App{menuBarConfig: &MenuBarConfig{}}
+
+	for _, state := range []SyncState{StateIdle, StateSyncing, StateOffline} {
+		a.notifyTransition(&Status{State: state})
+		if a.lastState != state {
+			t.Errorf("lastState = %q, want %q", a.lastState, state)
+		}
+	}
+}
@@ -13,6 +13,7 @@ void *createPreferencesController(const char *daemonAddr,
                                   const char *syncInterval,
                                   int ocrEnabled,
                                   const char *daemonConfigFile,
+                                  int notificationsEnabled,
                                   void *context);
 void showPreferencesWindow(void *controller);
 void releasePreferencesController(void *controller);
@@ -26,13 +27,13 @@ import (
 )
 
 var (
-	preferencesCallbacks  = make(map[uintptr]func(string, string, bool, string))
+	preferencesCallbacks  = make(map[uintptr]func(string, string, bool, string, bool))
 	preferencesCallbackMu sync.Mutex
 	preferencesCallbackID uintptr
 )
 
 //export preferencesGoCallback
-func preferencesGoCallback(cDaemonAddr *C.char, cSyncInterval *C.char, cOCREnabled C.int, cDaemonConfigFile *C.char, context unsafe.Pointer) {
+func preferencesGoCallback(cDaemonAddr *C.char, cSyncInterval *C.char, cOCREnabled C.int, cDaemonConfigFile *C.char, cNotificationsEnabled C.int, context unsafe.Pointer) {
 	contextID := uintptr(context)
 
 	preferencesCallbackMu.Lock()
@@ -50,9 +51,10 @@ func preferencesGoCallback(cDaemonAddr *C.char, cSyncInterval *C.char, cOCREnabl
 	syncInterval := C.GoString(cSyncInterval)
 	ocrEnabled := (cOCREnabled != 0)
 	daemonConfigFile := C.GoString(cDaemonConfigFile)
+	notificationsEnabled := (cNotificationsEnabled != 0)
 
 	// Call the Go callback in a goroutine to avoid blocking Objective-C main thread
-	go callback(daemonAddr, syncInterval, ocrEnabled, daemonConfigFile)
+	go callback(daemonAddr, syncInterval, ocrEnabled, daemonConfigFile, notificationsEnabled)
 }
 
 // ShowNativePreferences shows the native macOS preferences window
@@ -69,17 +71,22 @@ func (a *App) ShowNativePreferences() {
 	if a.menuBarConfig.OCREnabled {
 		cOCREnabled = C.int(1)
 	}
+	cNotificationsEnabled := C.int(0)
+	if a.menuBarConfig.NotificationsEnabled {
+		cNotificationsEnabled = C.int(1)
+	}
 
 	// Create callback context
 	preferencesCallbackMu.Lock()
 	preferencesCallbackID++
 	contextID := preferencesCallbackID
-	preferencesCallbacks[contextID] = func(daemonAddr, syncInterval string, ocrEnabled bool, daemonConfigFile string) {
+	preferencesCallbacks[contextID] = func(daemonAddr, syncInterval string, ocrEnabled bool, daemonConfigFile string, notificationsEnabled bool) {
 		// Update configuration
 		a.menuBarConfig.DaemonAddr = daemonAddr
 		a.menuBarConfig.SyncInterval = syncInterval
 		a.menuBarConfig.OCREnabled = ocrEnabled
 		a.menuBarConfig.DaemonConfigFile = daemonConfigFile
+		a.menuBarConfig.NotificationsEnabled = notificationsEnabled
 
 		// Save to file
 		if err := SaveMenuBarConfig(a.menuBarConfig, a.configPath); err != nil {
@@ -103,6 +110,7 @@ func (a *App) ShowNativePreferences() {
 		cSyncInterval,
 		cOCREnabled,
 		cDaemonConfigFile,
+		cNotificationsEnabled,
 		unsafe.Pointer(contextID),
 	)
 	if controller == nil {
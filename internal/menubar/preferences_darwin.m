@@ -6,6 +6,7 @@ extern void preferencesGoCallback(const char *daemonAddr,
                                   const char *syncInterval,
                                   int ocrEnabled,
                                   const char *daemonConfigFile,
+                                  int notificationsEnabled,
                                   void *context);
 
 @interface PreferencesController : NSObject <NSWindowDelegate>
@@ -14,10 +15,12 @@ extern void preferencesGoCallback(const char *daemonAddr,
 @property (strong, nonatomic) NSTextField *syncIntervalField;
 @property (strong, nonatomic) NSButton *ocrCheckbox;
 @property (strong, nonatomic) NSTextField *daemonConfigField;
+@property (strong, nonatomic) NSButton *notificationsCheckbox;
 @property (copy, nonatomic) NSString *daemonAddr;
 @property (copy, nonatomic) NSString *syncInterval;
 @property (nonatomic) BOOL ocrEnabled;
 @property (copy, nonatomic) NSString *daemonConfigFile;
+@property (nonatomic) BOOL notificationsEnabled;
 @property (nonatomic) void *callbackContext;
 @end
 
@@ -26,13 +29,15 @@ extern void preferencesGoCallback(const char *daemonAddr,
 - (instancetype)initWithDaemonAddr:(NSString *)daemonAddr
                       syncInterval:(NSString *)syncInterval
                         ocrEnabled:(BOOL)ocrEnabled
-                  daemonConfigFile:(NSString *)daemonConfigFile {
+                  daemonConfigFile:(NSString *)daemonConfigFile
+              notificationsEnabled:(BOOL)notificationsEnabled {
     self = [super init];
     if (self) {
         self.daemonAddr = daemonAddr;
         self.syncInterval = syncInterval;
         self.ocrEnabled = ocrEnabled;
         self.daemonConfigFile = daemonConfigFile;
+        self.notificationsEnabled = notificationsEnabled;
         [self createWindow];
     }
     return self;
@@ -40,7 +45,7 @@ extern void preferencesGoCallback(const char *daemonAddr,
 
 - (void)createWindow {
     // Create window
-    NSRect frame = NSMakeRect(0, 0, 500, 320);
+    NSRect frame = NSMakeRect(0, 0, 500, 370);
     NSWindowStyleMask styleMask = NSWindowStyleMaskTitled |
                                   NSWindowStyleMaskClosable |
                                   NSWindowStyleMaskMiniaturizable;
@@ -159,6 +164,26 @@ extern void preferencesGoCallback(const char *daemonAddr,
     [self.daemonConfigField setPlaceholderString:@"~/.legible.yaml"];
     [contentView addSubview:self.daemonConfigField];
 
+    y -= rowHeight;
+
+    // Notifications Checkbox
+    NSTextField *notificationsLabel = [[NSTextField alloc] initWithFrame:NSMakeRect(20, y, labelWidth, 24)];
+    [notificationsLabel setStringValue:@"Notifications:"];
+    [notificationsLabel setBezeled:NO];
+    [notificationsLabel setDrawsBackground:NO];
+    [notificationsLabel setEditable:NO];
+    [notificationsLabel setSelectable:NO];
+    if (@available(macOS 10.10, *)) {
+        [notificationsLabel setTextColor:[NSColor labelColor]];
+    }
+    [contentView addSubview:notificationsLabel];
+
+    self.notificationsCheckbox = [[NSButton alloc] initWithFrame:NSMakeRect(160, y, fieldWidth, 24)];
+    [self.notificationsCheckbox setButtonType:NSButtonTypeSwitch];
+    [self.notificationsCheckbox setTitle:@"Notify when a sync finishes or fails"];
+    [self.notificationsCheckbox setState:self.notificationsEnabled ? NSControlStateValueOn : NSControlStateValueOff];
+    [contentView addSubview:self.notificationsCheckbox];
+
     // Buttons
     NSButton *saveButton = [[NSButton alloc] initWithFrame:NSMakeRect(frame.size.width - 180, 20, 80, 32)];
     [saveButton setTitle:@"Save"];
@@ -182,6 +207,7 @@ extern void preferencesGoCallback(const char *daemonAddr,
     self.syncInterval = [self.syncIntervalField stringValue];
     self.ocrEnabled = ([self.ocrCheckbox state] == NSControlStateValueOn);
     self.daemonConfigFile = [self.daemonConfigField stringValue];
+    self.notificationsEnabled = ([self.notificationsCheckbox state] == NSControlStateValueOn);
 
     // Call the Go callback directly
     preferencesGoCallback(
@@ -189,6 +215,7 @@ extern void preferencesGoCallback(const char *daemonAddr,
         [self.syncInterval UTF8String],
         self.ocrEnabled ? 1 : 0,
         [self.daemonConfigFile UTF8String],
+        self.notificationsEnabled ? 1 : 0,
         self.callbackContext
     );
 
@@ -212,6 +239,7 @@ void *createPreferencesController(const char *daemonAddr,
                                   const char *syncInterval,
                                   int ocrEnabled,
                                   const char *daemonConfigFile,
+                                  int notificationsEnabled,
                                   void *context) {
     __block void *result = NULL;
 
@@ -226,7 +254,8 @@ void *createPreferencesController(const char *daemonAddr,
                 initWithDaemonAddr:nsAddr
                 syncInterval:nsInterval
                 ocrEnabled:(ocrEnabled != 0)
-                daemonConfigFile:nsConfig];
+                daemonConfigFile:nsConfig
+                notificationsEnabled:(notificationsEnabled != 0)];
 
             controller.callbackContext = context;
 
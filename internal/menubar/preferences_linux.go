@@ -0,0 +1,107 @@
+//go:build linux
+// +build linux
+
+package menubar
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/platinummonkey/legible/internal/logger"
+)
+
+// preferencesFormSeparator separates the fields zenity prints for the preferences form
+const preferencesFormSeparator = "\t"
+
+// ShowNativePreferences shows the preferences as a zenity form
+//
+// zenity forms can't be pre-filled, so the current settings are listed above the form
+// and fields left empty keep their value. Without zenity, a notification points at the
+// configuration file instead.
+func (a *App) ShowNativePreferences() {
+	if _, err := exec.LookPath("zenity"); err != nil {
+		logger.Info("zenity not found, pointing to the configuration file", "config_path", a.configPath)
+		notify("Legible Preferences", fmt.Sprintf("Edit %s to change settings, then restart the tray app.", a.configPath))
+		return
+	}
+
+	// zenity blocks until the form is closed, so it mustn't hold up the menu event loop
+	go func() {
+		cfg := a.menuBarConfig
+		text := fmt.Sprintf("Leave a field empty to keep its current value.\n\n"+
+			"Daemon address: %s\nSync interval: %s\nOCR: %s\nDaemon config file: %s\nNotifications: %s",
+			cfg.DaemonAddr, cfg.SyncInterval, enabledText(cfg.OCREnabled), cfg.DaemonConfigFile,
+			enabledText(cfg.NotificationsEnabled))
+
+		output, err := exec.Command("zenity", "--forms",
+			"--title=Legible Preferences",
+			"--text="+text,
+			"--separator="+preferencesFormSeparator,
+			"--add-entry=Daemon address",
+			"--add-entry=Sync interval",
+			"--add-combo=OCR",
+			"--combo-values=Enabled|Disabled",
+			"--add-entry=Daemon config file",
+			"--add-combo=Notifications",
+			"--combo-values=Enabled|Disabled",
+		).Output()
+		if err != nil {
+			// zenity exits with a non-zero status when the form is cancelled
+			logger.Debug("Preferences closed without saving", "error", err)
+			return
+		}
+
+		applyPreferencesForm(cfg, strings.TrimSuffix(string(output), "\n"))
+		if err := SaveMenuBarConfig(cfg, a.configPath); err != nil {
+			logger.Error("Failed to save configuration", "error", err)
+			a.showErrorDialog("Failed to save settings")
+			return
+		}
+
+		logger.Info("Configuration saved successfully")
+		a.showRestartPrompt()
+	}()
+}
+
+// applyPreferencesForm updates cfg from the fields of a submitted preferences form
+// Empty fields keep their current value.
+func applyPreferencesForm(cfg *MenuBarConfig, output string) {
+	fields := strings.Split(output, preferencesFormSeparator)
+	field := func(i int) string {
+		if i < len(fields) {
+			return strings.TrimSpace(fields[i])
+		}
+		return ""
+	}
+
+	if v := field(0); v != "" {
+		cfg.DaemonAddr = v
+	}
+	if v := field(1); v != "" {
+		cfg.SyncInterval = v
+	}
+	switch field(2) {
+	case "Enabled":
+		cfg.OCREnabled = true
+	case "Disabled":
+		cfg.OCREnabled = false
+	}
+	if v := field(3); v != "" {
+		cfg.DaemonConfigFile = v
+	}
+	switch field(4) {
+	case "Enabled":
+		cfg.NotificationsEnabled = true
+	case "Disabled":
+		cfg.NotificationsEnabled = false
+	}
+}
+
+// enabledText describes a setting's value the way the preferences form's combos do
+func enabledText(enabled bool) string {
+	if enabled {
+		return "Enabled"
+	}
+	return "Disabled"
+}
@@ -0,0 +1,109 @@
+//go:build darwin || linux
+// +build darwin linux
+
+package menubar
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"strings"
+	"sync"
+
+	"github.com/platinummonkey/legible/internal/logger"
+)
+
+// Progress bar layout
+const (
+	// progressBarWidth is the number of cells in the menu's textual progress bar
+	progressBarWidth = 10
+
+	// progressIconBarX and progressIconBarWidth place the icon's progress bar under the
+	// tablet, in the rows the 22x22 icons leave empty
+	progressIconBarX     = 2
+	progressIconBarWidth = 16
+	progressIconBarY     = 20
+	progressIconBarH     = 2
+)
+
+var (
+	// progressIcons caches the syncing icon for each filled width of its progress bar,
+	// so every poll at the same progress sets the same bytes
+	progressIcons   [progressIconBarWidth + 1][]byte
+	progressIconsMu sync.Mutex
+)
+
+// progressFraction returns how much of a sync is done, from 0 to 1; a sync with no
+// documents counted yet hasn't started
+func progressFraction(processed, total int) float64 {
+	if total <= 0 || processed <= 0 {
+		return 0
+	}
+	if processed >= total {
+		return 1
+	}
+	return float64(processed) / float64(total)
+}
+
+// progressBar formats sync progress as a textual bar, such as "[####------] 4/10"
+func progressBar(processed, total int) string {
+	filled := int(progressFraction(processed, total) * progressBarWidth)
+	if processed < 0 {
+		processed = 0
+	}
+	if total < 0 {
+		total = 0
+	}
+	return fmt.Sprintf("[%s%s] %d/%d", strings.Repeat("#", filled), strings.Repeat("-", progressBarWidth-filled), processed, total)
+}
+
+// iconProgress returns the syncing icon with a progress bar along its bottom edge
+// filled to the sync's progress, falling back to the plain syncing icon if the icon
+// can't be drawn
+func iconProgress(processed, total int) []byte {
+	filled := int(progressFraction(processed, total) * progressIconBarWidth)
+
+	progressIconsMu.Lock()
+	defer progressIconsMu.Unlock()
+	if progressIcons[filled] == nil {
+		icon, err := drawProgressIcon(filled)
+		if err != nil {
+			logger.Warn("Failed to draw progress icon", "error", err)
+			return iconYellow()
+		}
+		progressIcons[filled] = icon
+	}
+	return progressIcons[filled]
+}
+
+// drawProgressIcon draws a progress bar with filled of its pixels filled onto the
+// syncing icon; the bar is black like the tablet, so it follows the icon's tinting
+func drawProgressIcon(filled int) ([]byte, error) {
+	base, err := png.Decode(bytes.NewReader(iconYellow()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode icon: %w", err)
+	}
+	icon := image.NewNRGBA(base.Bounds())
+	draw.Draw(icon, icon.Bounds(), base, base.Bounds().Min, draw.Src)
+
+	track := color.NRGBA{A: 0x50}
+	bar := color.NRGBA{A: 0xff}
+	for x := 0; x < progressIconBarWidth; x++ {
+		c := track
+		if x < filled {
+			c = bar
+		}
+		for y := progressIconBarY; y < progressIconBarY+progressIconBarH; y++ {
+			icon.SetNRGBA(progressIconBarX+x, y, c)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, icon); err != nil {
+		return nil, fmt.Errorf("failed to encode icon: %w", err)
+	}
+	return buf.Bytes(), nil
+}
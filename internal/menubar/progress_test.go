@@ -0,0 +1,57 @@
+//go:build darwin || linux
+// +build darwin linux
+
+package menubar
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+)
+
+func TestProgressBar(t *testing.T) {
+	tests := []struct {
+		processed int
+		total     int
+		want      string
+	}{
+		{processed: 0, total: 0, want: "[----------] 0/0"},
+		{processed: 3, total: 0, want: "[----------] 3/0"},
+		{processed: 0, total: 10, want: "[----------] 0/10"},
+		{processed: 4, total: 10, want: "[####------] 4/10"},
+		{processed: 1, total: 3, want: "[###-------] 1/3"},
+		{processed: 10, total: 10, want: "[##########] 10/10"},
+		{processed: 12, total: 10, want: "[##########] 12/10"},
+		{processed: -1, total: -5, want: "[----------] 0/0"},
+	}
+
+	for _, tt := range tests {
+		if got := progressBar(tt.processed, tt.total); got != tt.want {
+			t.Errorf("progressBar(%d, %d) = %q, want %q", tt.processed, tt.total, got, tt.want)
+		}
+	}
+}
+
+func TestIconProgress(t *testing.T) {
+	empty := iconProgress(0, 0)
+	full := iconProgress(10, 10)
+
+	for _, icon := range [][]byte{empty, full} {
+		img, err := png.Decode(bytes.NewReader(icon))
+		if err != nil {
+			t.Fatalf("progress icon is not a valid PNG: %v", err)
+		}
+		if b := img.Bounds(); b.Dx() != 22 || b.Dy() != 22 {
+			t.Errorf("progress icon is %dx%d, want 22x22", b.Dx(), b.Dy())
+		}
+	}
+
+	if bytes.Equal(empty, full) {
+		t.Error("empty and full progress icons are the same")
+	}
+
+	// The same progress gives the same icon, so polling doesn't redraw it
+	if again := iconProgress(5, 5); &again[0] != &full[0] {
+		t.Error("iconProgress() redrew a cached icon")
+	}
+}
@@ -0,0 +1,172 @@
+//go:build darwin || linux
+// +build darwin linux
+
+package menubar
+
+import (
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"fyne.io/systray"
+	"github.com/platinummonkey/legible/internal/logger"
+)
+
+// maxRecentDocuments is the number of documents in the Recent Documents submenu
+const maxRecentDocuments = 10
+
+// recentPDF is a PDF in the output directory
+type recentPDF struct {
+	path    string
+	modTime time.Time
+}
+
+// recentPDFs returns the paths of the n most recently modified PDFs under dir, newest
+// first; PDFs modified at the same time are ordered by path
+func recentPDFs(dir string, n int) ([]string, error) {
+	var pdfs []recentPDF
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == dir {
+				return err
+			}
+			// Skip whatever can't be read rather than giving up on the whole tree
+			if d != nil && d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		// Hidden files include the temporary files PDFs are written through
+		if strings.HasPrefix(d.Name(), ".") && path != dir {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || !strings.EqualFold(filepath.Ext(path), ".pdf") {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return nil // removed since it was listed
+		}
+		pdfs = append(pdfs, recentPDF{path: path, modTime: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(pdfs, func(i, j int) bool {
+		if !pdfs[i].modTime.Equal(pdfs[j].modTime) {
+			return pdfs[i].modTime.After(pdfs[j].modTime)
+		}
+		return pdfs[i].path < pdfs[j].path
+	})
+	if len(pdfs) > n {
+		pdfs = pdfs[:n]
+	}
+
+	paths := make([]string, len(pdfs))
+	for i, pdf := range pdfs {
+		paths[i] = pdf.path
+	}
+	return paths, nil
+}
+
+// addRecentDocumentsMenu adds the Recent Documents submenu, with a hidden item for each
+// document it can show
+func (a *App) addRecentDocumentsMenu() {
+	a.mRecent = systray.AddMenuItem("Recent Documents", "Open a recently synced document")
+	a.mRecent.Disable() // Enabled once there are documents
+	a.mRecentItems = make([]*systray.MenuItem, maxRecentDocuments)
+	for i := range a.mRecentItems {
+		a.mRecentItems[i] = a.mRecent.AddSubMenuItem("", "")
+		a.mRecentItems[i].Hide()
+	}
+}
+
+// handleRecentDocumentClicks opens the document shown by a Recent Documents item when
+// it's clicked
+func (a *App) handleRecentDocumentClicks() {
+	for i, item := range a.mRecentItems {
+		go func(i int, item *systray.MenuItem) {
+			for {
+				select {
+				case <-item.ClickedCh:
+					a.openRecentDocument(i)
+				case <-a.quitChan:
+					return
+				}
+			}
+		}(i, item)
+	}
+}
+
+// openRecentDocument opens the document shown by the i'th Recent Documents item
+func (a *App) openRecentDocument(i int) {
+	a.recentMu.Lock()
+	if i >= len(a.recentDocs) {
+		a.recentMu.Unlock()
+		return
+	}
+	path := a.recentDocs[i]
+	a.recentMu.Unlock()
+
+	logger.Info("Recent document clicked", "path", path)
+	if err := openPath(path); err != nil {
+		logger.Error("Failed to open document", "error", err, "path", path)
+	}
+}
+
+// updateRecentDocuments refreshes the Recent Documents submenu from the output
+// directory, changing the menu only if the documents changed
+func (a *App) updateRecentDocuments() {
+	docs, err := recentPDFs(a.outputDir, maxRecentDocuments)
+	if err != nil {
+		logger.Debug("Failed to list recent documents", "error", err, "path", a.outputDir)
+		docs = nil
+	}
+
+	a.recentMu.Lock()
+	defer a.recentMu.Unlock()
+	if equalStrings(docs, a.recentDocs) {
+		return
+	}
+	a.recentDocs = docs
+
+	for i, item := range a.mRecentItems {
+		if i >= len(docs) {
+			item.Hide()
+			continue
+		}
+		title := docs[i]
+		if rel, err := filepath.Rel(a.outputDir, docs[i]); err == nil {
+			title = rel
+		}
+		item.SetTitle(title)
+		item.SetTooltip(docs[i])
+		item.Show()
+	}
+	if len(docs) == 0 {
+		a.mRecent.Disable()
+	} else {
+		a.mRecent.Enable()
+	}
+}
+
+// equalStrings reports whether a and b hold the same strings in the same order
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
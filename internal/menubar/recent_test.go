@@ -0,0 +1,79 @@
+//go:build darwin || linux
+// +build darwin linux
+
+package menubar
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecentPDFs(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+
+	// Files and their modification times, in minutes after base
+	files := map[string]int{
+		"old.pdf":               0,
+		"newest.pdf":            30,
+		"Work/meeting.pdf":      20,
+		"Work/b-tied.pdf":       10,
+		"Work/a-tied.pdf":       10,
+		"notes.PDF":             5,
+		"notes.md":              40,
+		".hidden.pdf":           50,
+		".cache/cached.pdf":     50,
+		"Work/.report.pdf.tmp":  50,
+		"Personal/journal.pdf":  15,
+		"Personal/empty/x.txt":  45,
+		"Personal/sketches.pdf": 1,
+	}
+	for name, minutes := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("%PDF-1.4"), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+		modTime := base.Add(time.Duration(minutes) * time.Minute)
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatalf("Failed to set times of %s: %v", name, err)
+		}
+	}
+
+	tests := []struct {
+		n    int
+		want []string
+	}{
+		{n: 3, want: []string{"newest.pdf", "Work/meeting.pdf", "Personal/journal.pdf"}},
+		// PDFs modified at the same time are ordered by path
+		{n: 5, want: []string{"newest.pdf", "Work/meeting.pdf", "Personal/journal.pdf", "Work/a-tied.pdf", "Work/b-tied.pdf"}},
+		{n: 10, want: []string{"newest.pdf", "Work/meeting.pdf", "Personal/journal.pdf", "Work/a-tied.pdf", "Work/b-tied.pdf",
+			"notes.PDF", "Personal/sketches.pdf", "old.pdf"}},
+		{n: 0, want: []string{}},
+	}
+
+	for _, tt := range tests {
+		got, err := recentPDFs(dir, tt.n)
+		if err != nil {
+			t.Fatalf("recentPDFs(%d) error: %v", tt.n, err)
+		}
+		for i := range got {
+			rel, _ := filepath.Rel(dir, got[i])
+			got[i] = filepath.ToSlash(rel)
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("recentPDFs(%d) = %v, want %v", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestRecentPDFs_MissingDirectory(t *testing.T) {
+	if _, err := recentPDFs(filepath.Join(t.TempDir(), "missing"), maxRecentDocuments); err == nil {
+		t.Error("Expected an error for a missing output directory")
+	}
+}
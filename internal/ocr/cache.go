@@ -0,0 +1,109 @@
+package ocr
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// cacheFormatVersion is part of every cache key; bump it when the OCR prompts or the
+// parsing of responses change, so results from the old ones are no longer used
+const cacheFormatVersion = "1"
+
+// Cache stores OCR results on disk, keyed by a hash of the page image and of how it
+// was recognized (see Processor.CacheKey)
+//
+// Pages that didn't change between versions of a document render to the same image,
+// so their OCR is read back instead of querying the vision model again. Each result is
+// a JSON file named after its key; entries are never expired, so the directory can be
+// deleted at any time to clear it.
+type Cache struct {
+	dir string
+}
+
+// NewCache creates a cache in dir, which is created on the first Put
+func NewCache(dir string) *Cache {
+	return &Cache{dir: dir}
+}
+
+// Dir returns the cache directory
+func (c *Cache) Dir() string {
+	return c.dir
+}
+
+// path returns the file of a cache key, in a subdirectory named after its first two
+// characters to keep directories small
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.dir, key[:2], key+".json")
+}
+
+// Get returns the cached OCR result for key, or nil if there is none
+func (c *Cache) Get(key string) (*PageOCR, error) {
+	data, err := os.ReadFile(c.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cached OCR result: %w", err)
+	}
+
+	var page PageOCR
+	if err := json.Unmarshal(data, &page); err != nil {
+		return nil, fmt.Errorf("failed to parse cached OCR result: %w", err)
+	}
+	return &page, nil
+}
+
+// Put stores an OCR result under key, replacing any previous result
+// The file is written under a temporary name and renamed into place, so concurrent
+// readers never see a partial result.
+func (c *Cache) Put(key string, page *PageOCR) error {
+	data, err := json.Marshal(page)
+	if err != nil {
+		return fmt.Errorf("failed to marshal OCR result: %w", err)
+	}
+
+	path := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create OCR cache directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+key+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create OCR cache file: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write OCR cache file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write OCR cache file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write OCR cache file: %w", err)
+	}
+	return nil
+}
+
+// CacheKey returns the OCR cache key of a page image: a hash of the image bytes and
+// of the provider, model, prompt, and preprocessing that recognize it, so changing any
+// of them misses the cache
+func (p *Processor) CacheKey(imageData []byte) string {
+	h := sha256.New()
+	parts := []string{cacheFormatVersion, p.visionClient.Name(), p.model, p.promptTemplate, p.preprocessing.String()}
+	if p.promptVariant != "" {
+		// Only added when set, so keys for the default prompt don't change
+		parts = append(parts, p.promptVariant)
+	}
+	for _, part := range parts {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	h.Write(imageData)
+	return hex.EncodeToString(h.Sum(nil))
+}
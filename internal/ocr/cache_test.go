@@ -0,0 +1,58 @@
+package ocr
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestCache_PutGet(t *testing.T) {
+	cache := NewCache(filepath.Join(t.TempDir(), "cache"))
+	key := (&Processor{visionClient: staticClient{}, model: "llava"}).CacheKey([]byte("page image"))
+
+	if page, err := cache.Get(key); err != nil || page != nil {
+		t.Fatalf("Get() on an empty cache = %v, %v, want a miss", page, err)
+	}
+
+	page := NewPageOCR(1, 100, 50, "eng")
+	page.AddWord(NewWord("hello", NewRectangle(1, 2, 3, 4), 90))
+	page.BuildText()
+	if err := cache.Put(key, page); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	got, err := cache.Get(key)
+	if err != nil || got == nil {
+		t.Fatalf("Get() = %v, %v, want the stored page", got, err)
+	}
+	if got.Text != "hello" || len(got.Words) != 1 || got.Words[0].BoundingBox != NewRectangle(1, 2, 3, 4) {
+		t.Errorf("Get() = %+v, want the stored page", got)
+	}
+}
+
+func TestProcessor_CacheKey(t *testing.T) {
+	llava := &Processor{visionClient: staticClient{}, model: "llava"}
+	key := llava.CacheKey([]byte("page image"))
+
+	if llava.CacheKey([]byte("page image")) != key {
+		t.Error("CacheKey() is not stable for the same image")
+	}
+	if llava.CacheKey([]byte("other page")) == key {
+		t.Error("CacheKey() is the same for a different image")
+	}
+	other := &Processor{visionClient: staticClient{}, model: "mistral-small3.1"}
+	if other.CacheKey([]byte("page image")) == key {
+		t.Error("CacheKey() is the same for a different model")
+	}
+	prompt := &Processor{visionClient: staticClient{}, model: "llava", promptTemplate: "different prompt"}
+	if prompt.CacheKey([]byte("page image")) == key {
+		t.Error("CacheKey() is the same for a different prompt")
+	}
+	german := &Processor{visionClient: staticClient{}, model: "llava", promptVariant: "language=German"}
+	if german.CacheKey([]byte("page image")) == key {
+		t.Error("CacheKey() is the same for a different prompt language")
+	}
+	gray := &Processor{visionClient: staticClient{}, model: "llava", preprocessing: preprocessing{grayscale: true}}
+	if gray.CacheKey([]byte("page image")) == key {
+		t.Error("CacheKey() is the same for different preprocessing")
+	}
+}
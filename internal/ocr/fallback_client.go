@@ -0,0 +1,120 @@
+package ocr
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/platinummonkey/legible/internal/logger"
+	"github.com/platinummonkey/legible/internal/ollama"
+)
+
+// fallbackRecheckInterval is how long the fallback client keeps using the engine it
+// chose before checking the primary's health again
+const fallbackRecheckInterval = 5 * time.Minute
+
+// FallbackVisionClient is a VisionClient that uses a primary client while its health
+// check passes, and a fallback client while it fails
+//
+// The choice is made by the first health check or OCR request and rechecked every
+// fallbackRecheckInterval, so a long-running daemon returns to the primary once it is
+// back.
+type FallbackVisionClient struct {
+	primary  VisionClient
+	fallback VisionClient
+	logger   *logger.Logger
+	now      func() time.Time
+	metrics  *Metrics // counts requests served by fallback, set by the processor using it
+
+	mu      sync.Mutex
+	active  VisionClient // nil until the first check
+	checked time.Time
+}
+
+// NewFallbackVisionClient creates a client that falls back from primary to fallback
+func NewFallbackVisionClient(primary, fallback VisionClient, log *logger.Logger) *FallbackVisionClient {
+	if log == nil {
+		log = logger.Get()
+	}
+	return &FallbackVisionClient{primary: primary, fallback: fallback, logger: log, now: time.Now}
+}
+
+// choose returns the client to use, checking the primary's health if the last check
+// is stale
+func (f *FallbackVisionClient) choose(ctx context.Context, model string) VisionClient {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.active != nil && f.now().Sub(f.checked) < fallbackRecheckInterval {
+		return f.active
+	}
+
+	active := f.primary
+	if err := f.primary.HealthCheck(ctx, model); err != nil {
+		if f.active != f.fallback {
+			f.logger.WithFields("primary", f.primary.Name(), "fallback", f.fallback.Name(), "error", err).
+				Warn("OCR provider unavailable, falling back")
+		}
+		active = f.fallback
+	} else if f.active == f.fallback {
+		f.logger.WithFields("primary", f.primary.Name()).Info("OCR provider available again")
+	}
+	f.active = active
+	f.checked = f.now()
+	return active
+}
+
+// chooseForRequest returns the client to perform an OCR request with, counting the
+// request in the metrics when it goes to the fallback
+func (f *FallbackVisionClient) chooseForRequest(ctx context.Context, model string) VisionClient {
+	client := f.choose(ctx, model)
+	if client == f.fallback {
+		f.metrics.RecordFallback()
+	}
+	return client
+}
+
+// GenerateOCR performs OCR with the primary client, or the fallback while the primary
+// is unavailable
+func (f *FallbackVisionClient) GenerateOCR(ctx context.Context, model string, imageData string) ([]ollama.OCRWord, error) {
+	return f.chooseForRequest(ctx, model).GenerateOCR(ctx, model, imageData)
+}
+
+// GenerateOCRWithLayout performs OCR like GenerateOCR, also returning the page layout
+// when the client in use recognizes it
+func (f *FallbackVisionClient) GenerateOCRWithLayout(ctx context.Context, model string, imageData string) ([]ollama.OCRWord, *ollama.StructuredOCRResponse, error) {
+	client := f.chooseForRequest(ctx, model)
+	if lc, ok := client.(LayoutVisionClient); ok {
+		return lc.GenerateOCRWithLayout(ctx, model, imageData)
+	}
+	words, err := client.GenerateOCR(ctx, model, imageData)
+	return words, nil, err
+}
+
+// HealthCheck passes if either the primary or the fallback client is available
+func (f *FallbackVisionClient) HealthCheck(ctx context.Context, model string) error {
+	client := f.choose(ctx, model)
+	if client == f.primary {
+		return nil
+	}
+	if err := f.fallback.HealthCheck(ctx, model); err != nil {
+		return errors.Join(errors.New("primary and fallback OCR providers are unavailable"), err)
+	}
+	return nil
+}
+
+// Name returns the name of the client in use, or the primary's before the first check
+func (f *FallbackVisionClient) Name() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.active != nil {
+		return f.active.Name()
+	}
+	return f.primary.Name()
+}
+
+// SupportedModels returns the primary client's models
+func (f *FallbackVisionClient) SupportedModels() []string {
+	return f.primary.SupportedModels()
+}
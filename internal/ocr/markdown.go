@@ -0,0 +1,116 @@
+package ocr
+
+import (
+	"strings"
+
+	"github.com/platinummonkey/legible/internal/ollama"
+)
+
+// Markdown renders the document's OCR results as a Markdown transcript
+//
+// Pages with a recognized layout become paragraphs for text lines, Markdown tables for
+// tables, and bullet lists for diagram labels; other pages become a single paragraph of
+// their text. Pages are separated by horizontal rules, and blank pages are omitted.
+func (d *DocumentOCR) Markdown(title string) string {
+	var pages []string
+	for i := range d.Pages {
+		if page := pageMarkdown(&d.Pages[i]); page != "" {
+			pages = append(pages, page)
+		}
+	}
+
+	body := strings.Join(pages, "\n---\n\n")
+	if title == "" {
+		return body
+	}
+	return "# " + singleLine(title) + "\n\n" + body
+}
+
+// pageMarkdown renders one page as Markdown blocks separated by blank lines
+func pageMarkdown(page *PageOCR) string {
+	var blocks []string
+	if page.Layout == nil {
+		if text := singleLine(page.Text); text != "" {
+			blocks = append(blocks, text)
+		}
+	} else {
+		for _, line := range page.Layout.Lines {
+			if block := lineMarkdown(line); block != "" {
+				blocks = append(blocks, block)
+			}
+		}
+	}
+
+	if len(blocks) == 0 {
+		return ""
+	}
+	return strings.Join(blocks, "\n\n") + "\n"
+}
+
+// lineMarkdown renders one recognized line of a page layout
+func lineMarkdown(line ollama.OCRLine) string {
+	switch line.Type {
+	case "text":
+		return singleLine(line.Content)
+	case "table":
+		return tableMarkdown(line.Headers, line.Rows)
+	case "diagram":
+		var items []string
+		for _, block := range line.DiagramBlocks {
+			if text := singleLine(block.Text); text != "" {
+				items = append(items, "- "+text)
+			}
+		}
+		return strings.Join(items, "\n")
+	default:
+		return ""
+	}
+}
+
+// tableMarkdown renders a table, padding short rows to the widest row
+// Tables without headers use their first row as the header row.
+func tableMarkdown(headers []string, rows [][]string) string {
+	if len(headers) == 0 {
+		if len(rows) == 0 {
+			return ""
+		}
+		headers, rows = rows[0], rows[1:]
+	}
+
+	columns := len(headers)
+	for _, row := range rows {
+		if len(row) > columns {
+			columns = len(row)
+		}
+	}
+	if columns == 0 {
+		return ""
+	}
+
+	separator := make([]string, columns)
+	for i := range separator {
+		separator[i] = "---"
+	}
+
+	lines := []string{tableRow(headers, columns), "| " + strings.Join(separator, " | ") + " |"}
+	for _, row := range rows {
+		lines = append(lines, tableRow(row, columns))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// tableRow renders the cells of a table row, escaping pipes
+func tableRow(cells []string, columns int) string {
+	escaped := make([]string, columns)
+	for i := range escaped {
+		if i < len(cells) {
+			escaped[i] = strings.ReplaceAll(singleLine(cells[i]), "|", `\|`)
+		}
+	}
+	return "| " + strings.Join(escaped, " | ") + " |"
+}
+
+// singleLine collapses whitespace, including newlines, so text stays on one Markdown line
+func singleLine(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
@@ -0,0 +1,84 @@
+package ocr
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/platinummonkey/legible/internal/ollama"
+)
+
+func TestDocumentOCR_Markdown_StructuredResponse(t *testing.T) {
+	structured := `{"lines":[
+		{"bbox":[10,10,400,40],"type":"text","content":"Meeting notes"},
+		{"bbox":[10,50,400,80],"type":"text","content":"Discussed the  roadmap\nand budget"},
+		{"bbox":[10,90,400,200],"type":"table","headers":["Task","Owner"],"rows":[["Draft plan","Ana"],["Review | sign off","Ben"],["Ship"]]}
+	]}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"model":      "llava",
+			"response":   structured,
+			"done":       true,
+			"created_at": time.Now().Format(time.RFC3339),
+		})
+	}))
+	defer server.Close()
+
+	processor, err := New(&Config{OllamaEndpoint: server.URL, Model: "llava"})
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	page, err := processor.ProcessImage(createTestImage(t, 200, 100), 1)
+	if err != nil {
+		t.Fatalf("ProcessImage() error = %v", err)
+	}
+	if page.Layout == nil || len(page.Layout.Lines) != 3 {
+		t.Fatalf("Layout = %+v, want the 3 structured lines", page.Layout)
+	}
+
+	doc := NewDocumentOCR("doc", "eng")
+	doc.AddPage(*page)
+	got := doc.Markdown("Notes")
+
+	want := "# Notes\n\n" +
+		"Meeting notes\n\n" +
+		"Discussed the roadmap and budget\n\n" +
+		"| Task | Owner |\n" +
+		"| --- | --- |\n" +
+		"| Draft plan | Ana |\n" +
+		"| Review \\| sign off | Ben |\n" +
+		"| Ship |  |\n"
+	if got != want {
+		t.Errorf("Markdown() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestDocumentOCR_Markdown_Pages(t *testing.T) {
+	plain := NewPageOCR(1, 100, 100, "eng")
+	plain.Text = "words without a layout"
+
+	blank := NewPageOCR(2, 100, 100, "eng")
+
+	layout := NewPageOCR(3, 100, 100, "eng")
+	layout.Layout = &ollama.StructuredOCRResponse{Lines: []ollama.OCRLine{
+		{Type: "table", Rows: [][]string{{"a", "b"}, {"1", "2"}}},
+		{Type: "diagram", DiagramBlocks: []ollama.DiagramBlock{{Text: "Start"}, {Text: ""}, {Text: "End"}}},
+	}}
+
+	doc := NewDocumentOCR("doc", "eng")
+	doc.AddPage(*plain)
+	doc.AddPage(*blank)
+	doc.AddPage(*layout)
+
+	// The blank page is omitted, without a page break of its own
+	got := doc.Markdown("")
+	want := "words without a layout\n" +
+		"\n---\n\n" +
+		"| a | b |\n| --- | --- |\n| 1 | 2 |\n\n" +
+		"- Start\n- End\n"
+	if got != want {
+		t.Errorf("Markdown() =\n%s\nwant\n%s", got, want)
+	}
+}
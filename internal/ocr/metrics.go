@@ -0,0 +1,67 @@
+package ocr
+
+import "sync/atomic"
+
+// Metrics counts how a processor's OCR requests were served, so operators can see how
+// effective the OCR cache is and how often the fallback engine is used
+//
+// The counters only grow; a nil *Metrics ignores updates and reads as zero.
+type Metrics struct {
+	cacheHits   atomic.Int64
+	cacheMisses atomic.Int64
+	fallbacks   atomic.Int64
+}
+
+// MetricsSnapshot is the value of a processor's OCR counters at one point in time
+type MetricsSnapshot struct {
+	CacheHits   int64 `json:"cache_hits"`   // pages whose OCR result was read from the cache
+	CacheMisses int64 `json:"cache_misses"` // pages looked up in the cache and OCR'd
+	Fallbacks   int64 `json:"fallbacks"`    // OCR requests served by the fallback engine
+}
+
+// RecordCacheHit counts a page whose OCR result was read from the OCR cache
+func (m *Metrics) RecordCacheHit() {
+	if m != nil {
+		m.cacheHits.Add(1)
+	}
+}
+
+// RecordCacheMiss counts a page that wasn't in the OCR cache and was OCR'd
+func (m *Metrics) RecordCacheMiss() {
+	if m != nil {
+		m.cacheMisses.Add(1)
+	}
+}
+
+// RecordFallback counts an OCR request served by the fallback engine
+func (m *Metrics) RecordFallback() {
+	if m != nil {
+		m.fallbacks.Add(1)
+	}
+}
+
+// Snapshot returns the current value of the counters
+func (m *Metrics) Snapshot() MetricsSnapshot {
+	if m == nil {
+		return MetricsSnapshot{}
+	}
+	return MetricsSnapshot{
+		CacheHits:   m.cacheHits.Load(),
+		CacheMisses: m.cacheMisses.Load(),
+		Fallbacks:   m.fallbacks.Load(),
+	}
+}
+
+// Sub returns the counts since an earlier snapshot
+func (s MetricsSnapshot) Sub(earlier MetricsSnapshot) MetricsSnapshot {
+	return MetricsSnapshot{
+		CacheHits:   s.CacheHits - earlier.CacheHits,
+		CacheMisses: s.CacheMisses - earlier.CacheMisses,
+		Fallbacks:   s.Fallbacks - earlier.Fallbacks,
+	}
+}
+
+// IsZero reports whether all counts are zero
+func (s MetricsSnapshot) IsZero() bool {
+	return s == MetricsSnapshot{}
+}
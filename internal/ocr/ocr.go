@@ -2,11 +2,15 @@
 package ocr
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"image"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/platinummonkey/legible/internal/logger"
@@ -41,13 +45,33 @@ Rules:
 - Return {"words": []} if no text found
 `
 
+// Engine selects how a Processor performs OCR
+type Engine string
+
+const (
+	// EngineOllama performs OCR with the vision client: Ollama by default, or the
+	// configured vision LLM provider
+	EngineOllama Engine = "ollama"
+
+	// EngineTesseract performs OCR locally with the tesseract CLI
+	EngineTesseract Engine = "tesseract"
+
+	// EngineAuto uses the vision client, falling back to Tesseract while its health
+	// check fails
+	EngineAuto Engine = "auto"
+)
+
 // Processor handles OCR processing using a vision client
 type Processor struct {
 	logger         *logger.Logger
 	visionClient   VisionClient
 	model          string
 	promptTemplate string
+	promptVariant  string              // selects the vision client's prompt, e.g. its languages
 	imageDimCache  map[int]image.Point // cache image dimensions by page number
+	dimMu          sync.Mutex          // guards imageDimCache for concurrent page OCR
+	preprocessing  preprocessing       // how images are prepared before OCR
+	metrics        *Metrics            // counts cached and fallback OCR requests
 }
 
 // Config holds configuration for the OCR processor
@@ -59,8 +83,22 @@ type Config struct {
 	Model          string  // default: "llava"
 	Temperature    float64 // default: 0.0 for deterministic output
 	MaxRetries     int     // default: 3
+	PromptPath     string  // YAML prompt file for structured OCR (default: the embedded prompts)
 	// New unified configuration
 	VisionConfig *VisionClientConfig // Vision client configuration (preferred)
+	// Engine selects the vision client (EngineOllama, the default), Tesseract, or the
+	// vision client with Tesseract as a fallback (EngineAuto)
+	Engine Engine
+	// TesseractPath is the tesseract binary (default: DefaultTesseractPath)
+	TesseractPath string
+	// TesseractLanguages are Tesseract language codes joined with "+" (default: "eng")
+	TesseractLanguages string
+
+	// Image preprocessing, applied before an image is sent for OCR; the returned
+	// bounding boxes are still in the original image's coordinates
+	Grayscale         bool    // convert images to grayscale
+	MaxImageDimension int     // downscale images whose longest side is larger (0: never)
+	ContrastFactor    float64 // multiply the contrast of images (0 or 1: unchanged)
 }
 
 // New creates a new OCR processor with a vision client
@@ -70,9 +108,21 @@ func New(cfg *Config) (*Processor, error) {
 		log = logger.Get()
 	}
 
+	switch cfg.Engine {
+	case "", EngineOllama, EngineAuto:
+	case EngineTesseract:
+		// Tesseract needs no vision client or model
+		client := NewTesseractClient(cfg.TesseractPath, cfg.TesseractLanguages, log)
+		log.WithFields("languages", client.languages).Info("Using Tesseract for OCR")
+		return newProcessor(log, client, client.Name(), cfg), nil
+	default:
+		return nil, fmt.Errorf("unknown OCR engine %q (want %s, %s, or %s)", cfg.Engine, EngineOllama, EngineTesseract, EngineAuto)
+	}
+
 	// Determine the vision client to use
 	var visionClient VisionClient
 	var model string
+	var variant string // identifies an Ollama client's prompt in cache keys
 
 	// Priority 1: Use pre-configured vision client if provided
 	if cfg.VisionClient != nil {
@@ -84,6 +134,13 @@ func New(cfg *Config) (*Processor, error) {
 		log.WithFields("provider", visionClient.Name()).Info("Using pre-configured vision client")
 	} else if cfg.VisionConfig != nil {
 		// Priority 2: Use VisionConfig to create client
+		if cfg.VisionConfig.Provider == ProviderOllama {
+			v, err := ollamaPromptVariant(cfg.VisionConfig.Languages, cfg.VisionConfig.PromptPath)
+			if err != nil {
+				return nil, err
+			}
+			variant = v
+		}
 		ctx := context.Background()
 		client, err := NewVisionClient(ctx, cfg.VisionConfig, log)
 		if err != nil {
@@ -106,21 +163,86 @@ func New(cfg *Config) (*Processor, error) {
 		if maxRetries == 0 {
 			maxRetries = 3
 		}
-		visionClient = NewOllamaVisionClient(endpoint, maxRetries, log)
+		v, err := ollamaPromptVariant("", cfg.PromptPath)
+		if err != nil {
+			return nil, err
+		}
+		variant = v
+		visionClient = NewOllamaVisionClient(endpoint, maxRetries, log, ollama.WithPromptFile(cfg.PromptPath))
 		log.WithFields("endpoint", endpoint, "model", model).Info("Using legacy Ollama configuration")
 	}
 
+	if cfg.Engine == EngineAuto {
+		tesseract := NewTesseractClient(cfg.TesseractPath, cfg.TesseractLanguages, log)
+		visionClient = NewFallbackVisionClient(visionClient, tesseract, log)
+		log.WithFields("fallback", tesseract.Name()).Info("Falling back to Tesseract when the vision client is unavailable")
+	}
+
+	proc := newProcessor(log, visionClient, model, cfg)
+	proc.promptVariant = variant
+	return proc, nil
+}
+
+// newProcessor creates a processor that performs OCR with visionClient and model,
+// preprocessing images as configured by cfg
+func newProcessor(log *logger.Logger, visionClient VisionClient, model string, cfg *Config) *Processor {
+	metrics := &Metrics{}
+	if fallback, ok := visionClient.(*FallbackVisionClient); ok {
+		fallback.metrics = metrics
+	}
+
 	return &Processor{
 		logger:         log,
 		visionClient:   visionClient,
 		model:          model,
 		promptTemplate: ocrPromptTemplate,
 		imageDimCache:  make(map[int]image.Point),
-	}, nil
+		preprocessing: preprocessing{
+			grayscale:         cfg.Grayscale,
+			maxImageDimension: cfg.MaxImageDimension,
+			contrastFactor:    cfg.ContrastFactor,
+		},
+		metrics: metrics,
+	}
+}
+
+// ollamaPromptVariant returns what, beyond the model, selects the structured OCR
+// prompt of an Ollama client with languages and promptPath, or an empty string for the
+// default prompt; it fails if the prompt file doesn't load or has no prompt
+func ollamaPromptVariant(languages, promptPath string) (string, error) {
+	if promptPath != "" {
+		config, err := ollama.LoadPromptConfig(promptPath)
+		if err != nil {
+			return "", fmt.Errorf("failed to load OCR prompt file %s: %w", promptPath, err)
+		}
+		if strings.TrimSpace(config.Prompt) == "" {
+			return "", fmt.Errorf("OCR prompt file %s has no prompt", promptPath)
+		}
+		sum := sha256.Sum256([]byte(config.Model + "\x00" + config.System + "\x00" + config.Prompt))
+		return "prompt=" + hex.EncodeToString(sum[:]), nil
+	}
+
+	config, err := ollama.LoadLanguagePromptConfig(languages)
+	if err != nil {
+		return "", fmt.Errorf("failed to load OCR prompt: %w", err)
+	}
+	if config.Language == "" {
+		return "", nil
+	}
+	return "language=" + config.Language, nil
 }
 
 // ProcessImage performs OCR on an image and returns structured results
 func (p *Processor) ProcessImage(imageData []byte, pageNumber int) (*PageOCR, error) {
+	return p.ProcessImageContext(context.Background(), imageData, pageNumber)
+}
+
+// ProcessImageContext performs OCR on an image, aborting the vision request if ctx is cancelled
+func (p *Processor) ProcessImageContext(ctx context.Context, imageData []byte, pageNumber int) (*PageOCR, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	p.logger.WithFields("page", pageNumber, "image_size", len(imageData), "provider", p.visionClient.Name()).Debug("Processing image with OCR")
 
 	startTime := time.Now()
@@ -137,26 +259,61 @@ func (p *Processor) ProcessImage(imageData []byte, pageNumber int) (*PageOCR, er
 		bounds := img.Bounds()
 		width = bounds.Dx()
 		height = bounds.Dy()
+		p.dimMu.Lock()
 		p.imageDimCache[pageNumber] = image.Point{X: width, Y: height}
+		p.dimMu.Unlock()
 		p.logger.WithFields("page", pageNumber, "width", width, "height", height, "format", format).Debug("Image dimensions")
-	} else if cached, ok := p.imageDimCache[pageNumber]; ok {
+	} else {
 		// Use cached dimensions if available
-		width = cached.X
-		height = cached.Y
+		p.dimMu.Lock()
+		cached, ok := p.imageDimCache[pageNumber]
+		p.dimMu.Unlock()
+		if ok {
+			width = cached.X
+			height = cached.Y
+		}
+	}
+
+	// Preprocess the image, remembering how much it was scaled to map the bounding
+	// boxes back
+	sendData, scaleX, scaleY := imageData, 1.0, 1.0
+	if img != nil && p.preprocessing.enabled() {
+		data, err := p.preprocessing.apply(img)
+		if err != nil {
+			return nil, err
+		}
+		processed, _, err := image.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read preprocessed image: %w", err)
+		}
+		sendData = data
+		scaleX = float64(width) / float64(processed.Width)
+		scaleY = float64(height) / float64(processed.Height)
+		p.logger.WithFields("page", pageNumber, "width", processed.Width, "height", processed.Height, "image_size", len(data)).
+			Debug("Preprocessed image")
 	}
 
 	// Encode image to base64
-	base64Image := ollama.EncodeBytesToBase64(imageData)
+	base64Image := ollama.EncodeBytesToBase64(sendData)
 
-	// Call vision client OCR API
-	ctx := context.Background()
-	words, err := p.visionClient.GenerateOCR(ctx, p.model, base64Image)
+	// Call vision client OCR API, keeping the page layout if the client recognizes it
+	var words []ollama.OCRWord
+	var layout *ollama.StructuredOCRResponse
+	if lc, ok := p.visionClient.(LayoutVisionClient); ok {
+		words, layout, err = lc.GenerateOCRWithLayout(ctx, p.model, base64Image)
+	} else {
+		words, err = p.visionClient.GenerateOCR(ctx, p.model, base64Image)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate OCR with %s: %w", p.visionClient.Name(), err)
 	}
+	if scaleX != 1 || scaleY != 1 {
+		rescaleResults(words, layout, scaleX, scaleY)
+	}
 
 	// Convert response to PageOCR
 	pageOCR := NewPageOCR(pageNumber, width, height, p.model)
+	pageOCR.Layout = layout
 	for _, oWord := range words {
 		if len(oWord.BBox) < 4 {
 			p.logger.WithFields("word", oWord.Text, "bbox", oWord.BBox).Warn("Invalid bounding box, skipping word")
@@ -204,10 +361,9 @@ func (p *Processor) ProcessImageWithCustomPrompt(imageData []byte, pageNumber in
 	return p.ProcessImage(imageData, pageNumber)
 }
 
-// HealthCheck verifies that the vision client is accessible and the model is available
-func (p *Processor) HealthCheck() error {
-	ctx := context.Background()
-
+// HealthCheck verifies that the vision client is accessible and the model is available,
+// giving up when ctx is done
+func (p *Processor) HealthCheck(ctx context.Context) error {
 	// Use the vision client's health check
 	if err := p.visionClient.HealthCheck(ctx, p.model); err != nil {
 		return fmt.Errorf("%s health check failed: %w", p.visionClient.Name(), err)
@@ -217,6 +373,11 @@ func (p *Processor) HealthCheck() error {
 	return nil
 }
 
+// Metrics returns the processor's OCR counters
+func (p *Processor) Metrics() *Metrics {
+	return p.metrics
+}
+
 // Model returns the configured model name
 func (p *Processor) Model() string {
 	return p.model
@@ -1,7 +1,9 @@
 package ocr
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"image"
 	"image/color"
 	"image/png"
@@ -14,6 +16,7 @@ import (
 	"time"
 
 	"github.com/platinummonkey/legible/internal/logger"
+	"github.com/platinummonkey/legible/internal/ollama"
 )
 
 func TestNew(t *testing.T) {
@@ -388,7 +391,7 @@ func TestHealthCheck_Success(t *testing.T) {
 		t.Fatalf("New() error: %v", err)
 	}
 
-	err = processor.HealthCheck()
+	err = processor.HealthCheck(context.Background())
 	if err != nil {
 		t.Errorf("HealthCheck() error = %v", err)
 	}
@@ -405,12 +408,46 @@ func TestHealthCheck_OllamaDown(t *testing.T) {
 		t.Fatalf("New() error: %v", err)
 	}
 
-	err = processor.HealthCheck()
+	err = processor.HealthCheck(context.Background())
 	if err == nil {
 		t.Error("HealthCheck() should error when Ollama is down")
 	}
 }
 
+func TestHealthCheck_ContextTimeout(t *testing.T) {
+	// An Ollama that accepts connections but never answers
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	processor, err := New(&Config{
+		OllamaEndpoint: server.URL,
+		Model:          "llava",
+		MaxRetries:     0,
+	})
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err = processor.HealthCheck(ctx)
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("HealthCheck() took %v after its context timed out", elapsed)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("HealthCheck() error = %v, want context.DeadlineExceeded", err)
+	}
+}
+
 func TestHealthCheck_ModelNotFound(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path == "" || r.URL.Path == "/" {
@@ -446,7 +483,7 @@ func TestHealthCheck_ModelNotFound(t *testing.T) {
 		t.Fatalf("New() error: %v", err)
 	}
 
-	err = processor.HealthCheck()
+	err = processor.HealthCheck(context.Background())
 	if err == nil {
 		t.Error("HealthCheck() should error when model cannot be pulled")
 	}
@@ -456,6 +493,42 @@ func TestHealthCheck_ModelNotFound(t *testing.T) {
 	}
 }
 
+func TestHealthCheck_NotVisionCapable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "", "/":
+			w.WriteHeader(http.StatusOK)
+		case "/api/tags":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"models": []map[string]interface{}{{"name": "llama2:latest"}},
+			})
+		case "/api/show":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"details":      map[string]interface{}{"family": "llama"},
+				"capabilities": []string{"completion"},
+			})
+		}
+	}))
+	defer server.Close()
+
+	processor, err := New(&Config{
+		OllamaEndpoint: server.URL,
+		Model:          "llama2",
+		MaxRetries:     0,
+	})
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	err = processor.HealthCheck(context.Background())
+	if !errors.Is(err, ollama.ErrNotVisionCapable) {
+		t.Fatalf("HealthCheck() error = %v, want ErrNotVisionCapable", err)
+	}
+	if !strings.Contains(err.Error(), "not vision-capable") || !strings.Contains(err.Error(), "llama2") {
+		t.Errorf("error should name the model and say it is not vision-capable, got: %v", err)
+	}
+}
+
 func TestModel(t *testing.T) {
 	processor, err := New(&Config{
 		Model: "test-model",
@@ -646,3 +719,91 @@ func (w *writerAdapter) Write(p []byte) (n int, err error) {
 	*w.buf = append(*w.buf, p...)
 	return len(p), nil
 }
+
+// promptEchoServer returns an Ollama server that replies to structured OCR with one
+// text line holding the prompt it was sent
+func promptEchoServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ollama.GenerateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+			return
+		}
+		layout := ollama.StructuredOCRResponse{Lines: []ollama.OCRLine{
+			{Type: "text", BBox: []int{0, 0, 100, 10}, Content: req.Prompt},
+		}}
+		response, _ := json.Marshal(layout)
+		_ = json.NewEncoder(w).Encode(ollama.GenerateResponse{Model: req.Model, Response: string(response), Done: true})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestNew_PromptFile(t *testing.T) {
+	server := promptEchoServer(t)
+	path := filepath.Join(t.TempDir(), "prompt.yaml")
+	if err := os.WriteFile(path, []byte("model: llava\nsystem: Read carefully\nprompt: Transcribe this page\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	configs := map[string]*Config{
+		"legacy": {OllamaEndpoint: server.URL, MaxRetries: 1, PromptPath: path},
+		"vision config": {VisionConfig: &VisionClientConfig{
+			Provider: ProviderOllama, Model: "llava", Endpoint: server.URL, MaxRetries: 1, PromptPath: path,
+		}},
+	}
+	for name, cfg := range configs {
+		t.Run(name, func(t *testing.T) {
+			processor, err := New(cfg)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			page, err := processor.ProcessImage(encodePNG(t, 200, 100), 1)
+			if err != nil {
+				t.Fatalf("ProcessImage() error = %v", err)
+			}
+			if got := page.Text; got != "Read carefully Transcribe this page" {
+				t.Errorf("text = %q, want the prompt file's prompt echoed", got)
+			}
+
+			// Changing the prompt misses the cache
+			embedded, err := New(&Config{OllamaEndpoint: server.URL})
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			if processor.CacheKey([]byte("page")) == embedded.CacheKey([]byte("page")) {
+				t.Error("CacheKey() is the same with and without the prompt file")
+			}
+		})
+	}
+}
+
+func TestNew_InvalidPromptFile(t *testing.T) {
+	dir := t.TempDir()
+	noPrompt := filepath.Join(dir, "no-prompt.yaml")
+	if err := os.WriteFile(noPrompt, []byte("model: llava\nsystem: Read carefully\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	invalid := filepath.Join(dir, "invalid.yaml")
+	if err := os.WriteFile(invalid, []byte("prompt: [unclosed\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		path    string
+		wantErr string
+	}{
+		"missing":   {path: filepath.Join(dir, "missing.yaml"), wantErr: "failed to load OCR prompt file"},
+		"invalid":   {path: invalid, wantErr: "failed to load OCR prompt file"},
+		"no prompt": {path: noPrompt, wantErr: "has no prompt"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := New(&Config{PromptPath: tt.path})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("New() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -13,8 +13,8 @@ type OllamaVisionClient struct {
 	logger *logger.Logger
 }
 
-// NewOllamaVisionClient creates a new Ollama vision client
-func NewOllamaVisionClient(endpoint string, maxRetries int, log *logger.Logger) *OllamaVisionClient {
+// NewOllamaVisionClient creates a new Ollama vision client, further configured by opts
+func NewOllamaVisionClient(endpoint string, maxRetries int, log *logger.Logger, opts ...ollama.ClientOption) *OllamaVisionClient {
 	if log == nil {
 		log = logger.Get()
 	}
@@ -31,6 +31,8 @@ func NewOllamaVisionClient(endpoint string, maxRetries int, log *logger.Logger)
 		clientOpts = append(clientOpts, ollama.WithMaxRetries(maxRetries))
 	}
 
+	clientOpts = append(clientOpts, opts...)
+
 	return &OllamaVisionClient{
 		client: ollama.NewClient(clientOpts...),
 		logger: log,
@@ -42,7 +44,14 @@ func (o *OllamaVisionClient) GenerateOCR(ctx context.Context, model string, imag
 	return o.client.GenerateOCR(ctx, model, imageData)
 }
 
-// HealthCheck verifies that Ollama is accessible and the model is available
+// GenerateOCRWithLayout performs OCR like GenerateOCR, also returning the page layout
+// recognized by Ollama's structured prompt
+func (o *OllamaVisionClient) GenerateOCRWithLayout(ctx context.Context, model string, imageData string) ([]ollama.OCRWord, *ollama.StructuredOCRResponse, error) {
+	return o.client.GenerateOCRWithLayout(ctx, model, imageData)
+}
+
+// HealthCheck verifies that Ollama is accessible and the model is available and can
+// read images
 func (o *OllamaVisionClient) HealthCheck(ctx context.Context, model string) error {
 	// Check if Ollama is running
 	if err := o.client.HealthCheck(ctx); err != nil {
@@ -70,7 +79,7 @@ func (o *OllamaVisionClient) HealthCheck(ctx context.Context, model string) erro
 		}
 	}
 
-	return nil
+	return o.client.EnsureVisionModel(ctx, model)
 }
 
 // Name returns the provider name
@@ -0,0 +1,137 @@
+package ocr
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+
+	"golang.org/x/image/draw"
+
+	"github.com/platinummonkey/legible/internal/ollama"
+)
+
+// preprocessing holds the options for preparing page images before OCR
+type preprocessing struct {
+	grayscale         bool
+	maxImageDimension int
+	contrastFactor    float64
+}
+
+// enabled reports whether any option changes the image
+func (pp preprocessing) enabled() bool {
+	return pp.grayscale || pp.maxImageDimension > 0 || (pp.contrastFactor > 0 && pp.contrastFactor != 1)
+}
+
+// String describes the options, as part of the OCR cache key
+func (pp preprocessing) String() string {
+	if !pp.enabled() {
+		return ""
+	}
+	return fmt.Sprintf("grayscale=%t max=%d contrast=%g", pp.grayscale, pp.maxImageDimension, pp.contrastFactor)
+}
+
+// scaledSize returns the size of an image of width x height whose longest side is at
+// most maxDim, keeping its aspect ratio
+func scaledSize(width, height, maxDim int) (int, int) {
+	if maxDim <= 0 || (width <= maxDim && height <= maxDim) {
+		return width, height
+	}
+	if width >= height {
+		return maxDim, max(1, height*maxDim/width)
+	}
+	return max(1, width*maxDim/height), maxDim
+}
+
+// apply returns img downscaled, converted to grayscale, and with its contrast adjusted
+// as configured, encoded as PNG
+func (pp preprocessing) apply(img image.Image) ([]byte, error) {
+	bounds := img.Bounds()
+	width, height := scaledSize(bounds.Dx(), bounds.Dy(), pp.maxImageDimension)
+
+	var out draw.Image
+	if pp.grayscale {
+		out = image.NewGray(image.Rect(0, 0, width, height))
+	} else {
+		out = image.NewRGBA(image.Rect(0, 0, width, height))
+	}
+	if width != bounds.Dx() || height != bounds.Dy() {
+		draw.CatmullRom.Scale(out, out.Bounds(), img, bounds, draw.Src, nil)
+	} else {
+		draw.Draw(out, out.Bounds(), img, bounds.Min, draw.Src)
+	}
+
+	if pp.contrastFactor > 0 && pp.contrastFactor != 1 {
+		adjustContrast(out, pp.contrastFactor)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, out); err != nil {
+		return nil, fmt.Errorf("failed to encode preprocessed image: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// adjustContrast scales each channel's distance from mid-gray by factor
+func adjustContrast(img draw.Image, factor float64) {
+	adjust := func(v uint8) uint8 {
+		return uint8(min(max((float64(v)-128)*factor+128, 0), 255))
+	}
+	switch img := img.(type) {
+	case *image.Gray:
+		for i, v := range img.Pix {
+			img.Pix[i] = adjust(v)
+		}
+	case *image.RGBA:
+		for i, v := range img.Pix {
+			if i%4 != 3 { // leave alpha alone
+				img.Pix[i] = adjust(v)
+			}
+		}
+	default:
+		bounds := img.Bounds()
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				c := color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)
+				img.Set(x, y, color.RGBA{R: adjust(c.R), G: adjust(c.G), B: adjust(c.B), A: c.A})
+			}
+		}
+	}
+}
+
+// scaleBox returns the coordinates of a bounding box scaled by sx horizontally and sy
+// vertically; boxes are [x, y, width, height] or [x1, y1, x2, y2], so even indexes
+// are horizontal
+func scaleBox(bbox []int, sx, sy float64) []int {
+	scaled := make([]int, len(bbox))
+	for i, v := range bbox {
+		s := sx
+		if i%2 == 1 {
+			s = sy
+		}
+		scaled[i] = int(float64(v)*s + 0.5)
+	}
+	return scaled
+}
+
+// rescaleResults scales the boxes of words and layout, recognized in a downscaled
+// image, by sx and sy back to the original page
+//
+// Boxes are replaced rather than scaled in place, as words converted from the layout
+// share its boxes.
+func rescaleResults(words []ollama.OCRWord, layout *ollama.StructuredOCRResponse, sx, sy float64) {
+	for i := range words {
+		words[i].BBox = scaleBox(words[i].BBox, sx, sy)
+	}
+	if layout == nil {
+		return
+	}
+	for i := range layout.Lines {
+		line := &layout.Lines[i]
+		line.BBox = scaleBox(line.BBox, sx, sy)
+		for j := range line.DiagramBlocks {
+			line.DiagramBlocks[j].BBox = scaleBox(line.DiagramBlocks[j].BBox, sx, sy)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package ocr
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"image"
+	"image/color"
+	"image/png"
+	"reflect"
+	"testing"
+
+	"github.com/platinummonkey/legible/internal/ollama"
+)
+
+func TestScaledSize(t *testing.T) {
+	tests := []struct {
+		width, height, maxDim int
+		wantW, wantH          int
+	}{
+		{1404, 1872, 0, 1404, 1872},
+		{1404, 1872, 2000, 1404, 1872},
+		{1404, 1872, 936, 702, 936},
+		{2000, 1000, 500, 500, 250},
+	}
+	for _, tt := range tests {
+		if w, h := scaledSize(tt.width, tt.height, tt.maxDim); w != tt.wantW || h != tt.wantH {
+			t.Errorf("scaledSize(%d, %d, %d) = %d, %d, want %d, %d", tt.width, tt.height, tt.maxDim, w, h, tt.wantW, tt.wantH)
+		}
+	}
+}
+
+// encodePNG encodes a width x height image with a light gray background and a dark
+// gray square in its top-left quarter
+func encodePNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := color.RGBA{R: 160, G: 170, B: 180, A: 255}
+			if x < width/2 && y < height/2 {
+				c = color.RGBA{R: 90, G: 100, B: 110, A: 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestPreprocessing_Apply(t *testing.T) {
+	img, err := png.Decode(bytes.NewReader(encodePNG(t, 2000, 1000)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := preprocessing{grayscale: true, maxImageDimension: 500, contrastFactor: 2}.apply(img)
+	if err != nil {
+		t.Fatalf("apply() error = %v", err)
+	}
+	out, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if out.Bounds().Dx() != 500 || out.Bounds().Dy() != 250 {
+		t.Errorf("preprocessed size = %v, want 500x250", out.Bounds().Size())
+	}
+	gray, ok := out.(*image.Gray)
+	if !ok {
+		t.Fatalf("preprocessed image is %T, want *image.Gray", out)
+	}
+	// The contrast doubles the distance of both grays from mid-gray
+	if dark, light := gray.GrayAt(50, 50).Y, gray.GrayAt(400, 200).Y; dark > 80 || light < 200 {
+		t.Errorf("grays after contrast = %d, %d, want below 80 and above 200", dark, light)
+	}
+}
+
+// recordingClient is a VisionClient that records the image it receives and returns
+// one word and a matching layout line
+type recordingClient struct {
+	image []byte
+}
+
+func (r *recordingClient) GenerateOCR(ctx context.Context, model string, imageData string) ([]ollama.OCRWord, error) {
+	words, _, err := r.GenerateOCRWithLayout(ctx, model, imageData)
+	return words, err
+}
+
+func (r *recordingClient) GenerateOCRWithLayout(_ context.Context, _ string, imageData string) ([]ollama.OCRWord, *ollama.StructuredOCRResponse, error) {
+	data, err := base64.StdEncoding.DecodeString(imageData)
+	if err != nil {
+		return nil, nil, err
+	}
+	r.image = data
+	layout := &ollama.StructuredOCRResponse{Lines: []ollama.OCRLine{{Type: "table", BBox: []int{10, 20, 40, 60}, Rows: [][]string{{"a", "b"}}}}}
+	return ollama.ConvertStructuredToWords(layout), layout, nil
+}
+
+func (r *recordingClient) HealthCheck(_ context.Context, _ string) error { return nil }
+
+func (r *recordingClient) Name() string { return "recording" }
+
+func (r *recordingClient) SupportedModels() []string { return nil }
+
+func TestProcessImage_Preprocessing(t *testing.T) {
+	client := &recordingClient{}
+	processor, err := New(&Config{VisionClient: client, MaxImageDimension: 500})
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	page, err := processor.ProcessImage(encodePNG(t, 2000, 1000), 1)
+	if err != nil {
+		t.Fatalf("ProcessImage() error = %v", err)
+	}
+
+	sent, err := png.DecodeConfig(bytes.NewReader(client.image))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent.Width != 500 || sent.Height != 250 {
+		t.Errorf("sent a %dx%d image, want 500x250", sent.Width, sent.Height)
+	}
+
+	// The boxes, in the 500x250 image, are scaled back to the 2000x1000 page, each
+	// once
+	if page.Width != 2000 || page.Height != 1000 {
+		t.Errorf("page size = %dx%d, want 2000x1000", page.Width, page.Height)
+	}
+	if len(page.Words) != 2 || page.Words[0].BoundingBox != NewRectangle(40, 80, 60, 160) ||
+		page.Words[1].BoundingBox != NewRectangle(100, 80, 60, 160) {
+		t.Errorf("words = %+v, want cells at (40, 80, 60, 160) and (100, 80, 60, 160)", page.Words)
+	}
+	if got := page.Layout.Lines[0].BBox; !reflect.DeepEqual(got, []int{40, 80, 160, 240}) {
+		t.Errorf("layout bbox = %v, want [40 80 160 240]", got)
+	}
+}
+
+func TestProcessImage_NoPreprocessing(t *testing.T) {
+	client := &recordingClient{}
+	processor, err := New(&Config{VisionClient: client})
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	imageData := encodePNG(t, 200, 100)
+	page, err := processor.ProcessImage(imageData, 1)
+	if err != nil {
+		t.Fatalf("ProcessImage() error = %v", err)
+	}
+	if !bytes.Equal(client.image, imageData) {
+		t.Error("the image was changed without any preprocessing configured")
+	}
+	if page.Words[0].BoundingBox != NewRectangle(10, 20, 15, 40) {
+		t.Errorf("bbox = %+v, want it unchanged", page.Words[0].BoundingBox)
+	}
+}
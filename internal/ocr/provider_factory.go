@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/platinummonkey/legible/internal/logger"
+	"github.com/platinummonkey/legible/internal/ollama"
 )
 
 // NewVisionClient creates a vision client based on the provider configuration
@@ -15,7 +16,12 @@ func NewVisionClient(ctx context.Context, cfg *VisionClientConfig, log *logger.L
 
 	switch cfg.Provider {
 	case ProviderOllama:
-		return NewOllamaVisionClient(cfg.Endpoint, cfg.MaxRetries, log), nil
+		return NewOllamaVisionClient(cfg.Endpoint, cfg.MaxRetries, log,
+			ollama.WithStructuredImageEncoding(cfg.StructuredImage),
+			ollama.WithSimpleImageEncoding(cfg.SimpleImage),
+			ollama.WithLanguages(cfg.Languages),
+			ollama.WithPromptFile(cfg.PromptPath),
+		), nil
 
 	case ProviderOpenAI:
 		if cfg.APIKey == "" {
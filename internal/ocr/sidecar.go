@@ -0,0 +1,119 @@
+package ocr
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"strings"
+)
+
+// JSON renders the document's OCR results, with every word's bounding box and
+// confidence, as indented JSON
+func (d *DocumentOCR) JSON() ([]byte, error) {
+	data, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal OCR results: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// PlainText renders the document's OCR text, one page after another
+//
+// Each page ends with a form feed, as pdftotext separates pages, so indexers can
+// still tell where pages begin.
+func (d *DocumentOCR) PlainText() string {
+	var b strings.Builder
+	for _, page := range d.Pages {
+		if page.Text != "" {
+			b.WriteString(page.Text)
+			b.WriteString("\n")
+		}
+		b.WriteString("\f")
+	}
+	return b.String()
+}
+
+// HOCR renders the document's OCR results as an hOCR (XHTML) document
+//
+// Each page is an ocr_page and each word an ocrx_word with its bounding box and
+// confidence. Words are grouped into ocr_line elements by their vertical position, as
+// the vision models report words rather than lines.
+func (d *DocumentOCR) HOCR(title string) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	b.WriteString(`<!DOCTYPE html PUBLIC "-//W3C//DTD XHTML 1.0 Transitional//EN" "http://www.w3.org/TR/xhtml1/DTD/xhtml1-transitional.dtd">` + "\n")
+	fmt.Fprintf(&b, `<html xmlns="http://www.w3.org/1999/xhtml" xml:lang="%s" lang="%s">`+"\n",
+		html.EscapeString(hocrLanguage(d.Language)), html.EscapeString(hocrLanguage(d.Language)))
+	b.WriteString(" <head>\n")
+	fmt.Fprintf(&b, "  <title>%s</title>\n", html.EscapeString(title))
+	b.WriteString(`  <meta http-equiv="Content-Type" content="text/html;charset=utf-8"/>` + "\n")
+	b.WriteString(`  <meta name="ocr-system" content="legible"/>` + "\n")
+	b.WriteString(`  <meta name="ocr-capabilities" content="ocr_page ocr_line ocrx_word"/>` + "\n")
+	b.WriteString(" </head>\n <body>\n")
+
+	for _, page := range d.Pages {
+		fmt.Fprintf(&b, `  <div class="ocr_page" id="page_%d" title="bbox 0 0 %d %d; ppageno %d">`+"\n",
+			page.PageNumber, page.Width, page.Height, page.PageNumber-1)
+		for i, line := range hocrLines(page.Words) {
+			fmt.Fprintf(&b, `   <span class="ocr_line" id="line_%d_%d" title="%s">`,
+				page.PageNumber, i+1, hocrBBox(lineBounds(line)))
+			for j, word := range line {
+				if j > 0 {
+					b.WriteString(" ")
+				}
+				fmt.Fprintf(&b, `<span class="ocrx_word" id="word_%d_%d_%d" title="%s; x_wconf %d">%s</span>`,
+					page.PageNumber, i+1, j+1, hocrBBox(word.BoundingBox), int(word.Confidence+0.5),
+					html.EscapeString(word.Text))
+			}
+			b.WriteString("</span>\n")
+		}
+		b.WriteString("  </div>\n")
+	}
+
+	b.WriteString(" </body>\n</html>\n")
+	return b.String()
+}
+
+// hocrLines groups words into lines: a word starts a new line when its vertical center
+// falls outside the current line's box
+func hocrLines(words []Word) [][]Word {
+	var lines [][]Word
+	for _, word := range words {
+		if n := len(lines); n > 0 {
+			box := lineBounds(lines[n-1])
+			center := word.BoundingBox.Y + word.BoundingBox.Height/2
+			if center >= box.Y && center <= box.Bottom() {
+				lines[n-1] = append(lines[n-1], word)
+				continue
+			}
+		}
+		lines = append(lines, []Word{word})
+	}
+	return lines
+}
+
+// lineBounds returns the box enclosing a line's words
+func lineBounds(line []Word) Rectangle {
+	box := line[0].BoundingBox
+	x0, y0, x1, y1 := box.X, box.Y, box.Right(), box.Bottom()
+	for _, word := range line[1:] {
+		box := word.BoundingBox
+		x0, y0 = min(x0, box.X), min(y0, box.Y)
+		x1, y1 = max(x1, box.Right()), max(y1, box.Bottom())
+	}
+	return NewRectangle(x0, y0, x1-x0, y1-y0)
+}
+
+// hocrBBox formats a rectangle as an hOCR bbox property (left top right bottom)
+func hocrBBox(r Rectangle) string {
+	return fmt.Sprintf("bbox %d %d %d %d", r.X, r.Y, r.Right(), r.Bottom())
+}
+
+// hocrLanguage returns the document's first OCR language, e.g. "eng" for "eng+deu"
+func hocrLanguage(language string) string {
+	first, _, _ := strings.Cut(language, "+")
+	if first == "" {
+		return "und"
+	}
+	return first
+}
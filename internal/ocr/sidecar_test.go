@@ -0,0 +1,117 @@
+package ocr
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// sidecarDocument returns a two-page OCR result: two lines of words on page 1, and a
+// blank page 2
+func sidecarDocument() *DocumentOCR {
+	doc := NewDocumentOCR("doc-1", "eng+deu")
+	page := NewPageOCR(1, 612, 792, "llava")
+	page.AddWord(NewWord("Fish", NewRectangle(10, 20, 40, 12), 91.6))
+	page.AddWord(NewWord("&", NewRectangle(55, 22, 10, 10), 80))
+	page.AddWord(NewWord("<chips>", NewRectangle(70, 20, 50, 12), 75))
+	page.AddWord(NewWord("Tuesday", NewRectangle(10, 60, 60, 14), 88))
+	page.BuildText()
+	page.CalculateConfidence()
+	doc.AddPage(*page)
+	doc.AddPage(*NewPageOCR(2, 612, 792, "llava"))
+	doc.Finalize()
+	return doc
+}
+
+func TestDocumentOCR_JSON(t *testing.T) {
+	doc := sidecarDocument()
+	data, err := doc.JSON()
+	if err != nil {
+		t.Fatalf("JSON() error = %v", err)
+	}
+
+	var decoded DocumentOCR
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("sidecar is not valid JSON: %v", err)
+	}
+	if !reflect.DeepEqual(&decoded, doc) {
+		t.Errorf("decoded = %+v, want %+v", decoded, *doc)
+	}
+	if !strings.Contains(string(data), `"bbox": {`) {
+		t.Errorf("JSON() = %s, want snake_case keys with word bboxes", data)
+	}
+}
+
+func TestDocumentOCR_PlainText(t *testing.T) {
+	want := "Fish & <chips> Tuesday\n\f\f"
+	if got := sidecarDocument().PlainText(); got != want {
+		t.Errorf("PlainText() = %q, want %q", got, want)
+	}
+}
+
+// hocrNode is an element of an hOCR document
+type hocrNode struct {
+	Class    string     `xml:"class,attr"`
+	Title    string     `xml:"title,attr"`
+	Text     string     `xml:",chardata"`
+	Children []hocrNode `xml:",any"`
+}
+
+// find returns the elements of class under n, in document order
+func (n hocrNode) find(class string) []hocrNode {
+	var found []hocrNode
+	for _, child := range n.Children {
+		if child.Class == class {
+			found = append(found, child)
+		}
+		found = append(found, child.find(class)...)
+	}
+	return found
+}
+
+func TestDocumentOCR_HOCR(t *testing.T) {
+	out := sidecarDocument().HOCR("Shopping & Notes")
+
+	var root hocrNode
+	if err := xml.Unmarshal([]byte(out), &root); err != nil {
+		t.Fatalf("hOCR is not well-formed XML: %v\n%s", err, out)
+	}
+
+	pages := root.find("ocr_page")
+	if len(pages) != 2 {
+		t.Fatalf("got %d ocr_page elements, want 2", len(pages))
+	}
+	if want := "bbox 0 0 612 792; ppageno 0"; pages[0].Title != want {
+		t.Errorf("page title = %q, want %q", pages[0].Title, want)
+	}
+
+	lines := pages[0].find("ocr_line")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines on page 1, want 2", len(lines))
+	}
+	if want := "bbox 10 20 120 32"; lines[0].Title != want {
+		t.Errorf("line title = %q, want %q", lines[0].Title, want)
+	}
+
+	var words []string
+	for _, word := range pages[0].find("ocrx_word") {
+		words = append(words, word.Text+" | "+word.Title)
+	}
+	want := []string{
+		"Fish | bbox 10 20 50 32; x_wconf 92",
+		"& | bbox 55 22 65 32; x_wconf 80",
+		"<chips> | bbox 70 20 120 32; x_wconf 75",
+		"Tuesday | bbox 10 60 70 74; x_wconf 88",
+	}
+	if !reflect.DeepEqual(words, want) {
+		t.Errorf("words = %q, want %q", words, want)
+	}
+	if len(pages[1].find("ocrx_word")) != 0 {
+		t.Error("blank page has words")
+	}
+	if !strings.Contains(out, `lang="eng"`) || !strings.Contains(out, "<title>Shopping &amp; Notes</title>") {
+		t.Errorf("hOCR head is missing the language or title:\n%s", out)
+	}
+}
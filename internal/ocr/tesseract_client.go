@@ -0,0 +1,134 @@
+package ocr
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/platinummonkey/legible/internal/logger"
+	"github.com/platinummonkey/legible/internal/ollama"
+)
+
+const (
+	// DefaultTesseractPath is the tesseract binary run when none is configured, found
+	// on the PATH
+	DefaultTesseractPath = "tesseract"
+
+	// tesseractWordLevel is the TSV level of word rows
+	tesseractWordLevel = 5
+)
+
+// TesseractClient is a VisionClient that runs OCR locally with the tesseract CLI
+//
+// It needs no server or model, which makes it a fallback for when the vision LLM is
+// unavailable, though it reads handwriting far less accurately.
+type TesseractClient struct {
+	path      string
+	languages string
+	logger    *logger.Logger
+}
+
+// NewTesseractClient creates a Tesseract client running the binary at path (default:
+// DefaultTesseractPath) for languages, Tesseract codes joined with "+" (default: "eng")
+func NewTesseractClient(path, languages string, log *logger.Logger) *TesseractClient {
+	if log == nil {
+		log = logger.Get()
+	}
+	if path == "" {
+		path = DefaultTesseractPath
+	}
+	if languages == "" {
+		languages = "eng"
+	}
+	return &TesseractClient{path: path, languages: languages, logger: log}
+}
+
+// GenerateOCR performs OCR on a base64-encoded image and returns the recognized words
+// The model is ignored; Tesseract uses its trained data for the configured languages.
+func (t *TesseractClient) GenerateOCR(ctx context.Context, _ string, imageData string) ([]ollama.OCRWord, error) {
+	image, err := base64.StdEncoding.DecodeString(imageData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, t.path, "stdin", "stdout", "-l", t.languages, "tsv")
+	cmd.Stdin = bytes.NewReader(image)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("tesseract failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	return parseTesseractTSV(out)
+}
+
+// parseTesseractTSV returns the words of tesseract's TSV output, skipping the rows for
+// pages, blocks, and lines, and empty words
+func parseTesseractTSV(data []byte) ([]ollama.OCRWord, error) {
+	var words []ollama.OCRWord
+	for i, line := range strings.Split(strings.TrimRight(string(data), "\n"), "\n") {
+		// level page_num block_num par_num line_num word_num left top width height conf text
+		fields := strings.Split(strings.TrimRight(line, "\r"), "\t")
+		if i == 0 || len(fields) < 12 || fields[0] != strconv.Itoa(tesseractWordLevel) {
+			continue
+		}
+		text := strings.TrimSpace(fields[11])
+		if text == "" {
+			continue
+		}
+
+		var box [4]int
+		for j := range box {
+			n, err := strconv.Atoi(fields[6+j])
+			if err != nil {
+				return nil, fmt.Errorf("invalid tesseract output on line %d: %w", i+1, err)
+			}
+			box[j] = n
+		}
+		conf, err := strconv.ParseFloat(fields[10], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tesseract output on line %d: %w", i+1, err)
+		}
+
+		words = append(words, ollama.OCRWord{
+			Text:       text,
+			BBox:       box[:],
+			Confidence: max(conf, 0) / 100,
+		})
+	}
+	return words, nil
+}
+
+// HealthCheck verifies that tesseract runs and has trained data for every language
+func (t *TesseractClient) HealthCheck(ctx context.Context, _ string) error {
+	out, err := exec.CommandContext(ctx, t.path, "--list-langs").CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("tesseract is not available: %w", err)
+	}
+
+	installed := make(map[string]bool)
+	for _, line := range strings.Split(string(out), "\n") {
+		installed[strings.TrimSpace(line)] = true
+	}
+	for _, lang := range strings.Split(t.languages, "+") {
+		if !installed[lang] {
+			return fmt.Errorf("tesseract has no trained data for language %q", lang)
+		}
+	}
+	return nil
+}
+
+// Name returns the name of the provider
+func (t *TesseractClient) Name() string {
+	return "tesseract"
+}
+
+// SupportedModels returns nil; Tesseract has no models to choose from
+func (t *TesseractClient) SupportedModels() []string {
+	return nil
+}
@@ -0,0 +1,152 @@
+package ocr
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/platinummonkey/legible/internal/ollama"
+)
+
+// tesseractTSV is tesseract's TSV output for a page with two words on one line
+const tesseractTSV = "level\tpage_num\tblock_num\tpar_num\tline_num\tword_num\tleft\ttop\twidth\theight\tconf\ttext\n" +
+	"1\t1\t0\t0\t0\t0\t0\t0\t200\t100\t-1\t\n" +
+	"4\t1\t1\t1\t1\t0\t10\t20\t110\t15\t-1\t\n" +
+	"5\t1\t1\t1\t1\t1\t10\t20\t50\t15\t91.5\tHello\n" +
+	"5\t1\t1\t1\t1\t2\t70\t21\t50\t14\t64\tworld\n" +
+	"5\t1\t1\t1\t1\t3\t125\t21\t5\t14\t95\t \n"
+
+// fakeTesseract writes a tesseract stand-in script that lists the eng and deu
+// languages and recognizes tesseractTSV, recording its arguments in the returned file
+func fakeTesseract(t *testing.T) (path, argsFile string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake tesseract is a shell script")
+	}
+
+	dir := t.TempDir()
+	path = filepath.Join(dir, "tesseract")
+	argsFile = filepath.Join(dir, "args")
+	script := "#!/bin/sh\n" +
+		"if [ \"$1\" = --list-langs ]; then printf 'List of available languages (2):\\neng\\ndeu\\n'; exit 0; fi\n" +
+		"echo \"$@\" > " + argsFile + "\n" +
+		"cat > /dev/null\n" +
+		"printf '" + strings.ReplaceAll(strings.ReplaceAll(tesseractTSV, "\t", "\\t"), "\n", "\\n") + "'\n"
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path, argsFile
+}
+
+func TestParseTesseractTSV(t *testing.T) {
+	words, err := parseTesseractTSV([]byte(tesseractTSV))
+	if err != nil {
+		t.Fatalf("parseTesseractTSV() error = %v", err)
+	}
+
+	want := []ollama.OCRWord{
+		{Text: "Hello", BBox: []int{10, 20, 50, 15}, Confidence: 0.915},
+		{Text: "world", BBox: []int{70, 21, 50, 14}, Confidence: 0.64},
+	}
+	if !reflect.DeepEqual(words, want) {
+		t.Errorf("parseTesseractTSV() = %+v, want %+v", words, want)
+	}
+
+	if _, err := parseTesseractTSV([]byte("header\n5\t1\t1\t1\t1\t1\tx\t20\t50\t15\t90\tbad\n")); err == nil {
+		t.Error("parseTesseractTSV() should error on a malformed box")
+	}
+}
+
+func TestProcessor_AutoFallsBackToTesseract(t *testing.T) {
+	tesseract, argsFile := fakeTesseract(t)
+
+	// An Ollama server that has gone away
+	down := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	down.Close()
+
+	processor, err := New(&Config{
+		OllamaEndpoint:     down.URL,
+		MaxRetries:         1,
+		Engine:             EngineAuto,
+		TesseractPath:      tesseract,
+		TesseractLanguages: "eng+deu",
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := processor.HealthCheck(context.Background()); err != nil {
+		t.Errorf("HealthCheck() error = %v, want the Tesseract fallback to pass", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	page, err := processor.ProcessImageContext(ctx, createTestImage(t, 200, 100), 1)
+	if err != nil {
+		t.Fatalf("ProcessImageContext() error = %v", err)
+	}
+	if page.Text != "Hello world" || len(page.Words) != 2 {
+		t.Fatalf("page = %+v, want Tesseract's words", page)
+	}
+	if page.Words[0].Confidence != 91.5 || page.Words[0].BoundingBox != NewRectangle(10, 20, 50, 15) {
+		t.Errorf("first word = %+v, want Tesseract's box and confidence", page.Words[0])
+	}
+
+	args, err := os.ReadFile(argsFile)
+	if err != nil {
+		t.Fatalf("tesseract was not run: %v", err)
+	}
+	if got := strings.TrimSpace(string(args)); got != "stdin stdout -l eng+deu tsv" {
+		t.Errorf("tesseract arguments = %q, want the configured languages", got)
+	}
+}
+
+// staticClient is an available vision client that recognizes one word
+type staticClient struct{}
+
+func (staticClient) GenerateOCR(_ context.Context, _ string, _ string) ([]ollama.OCRWord, error) {
+	return []ollama.OCRWord{{Text: "primary", BBox: []int{0, 0, 10, 10}, Confidence: 0.9}}, nil
+}
+
+func (staticClient) HealthCheck(_ context.Context, _ string) error { return nil }
+
+func (staticClient) Name() string { return "static" }
+
+func (staticClient) SupportedModels() []string { return nil }
+
+func TestFallbackVisionClient_PrefersPrimary(t *testing.T) {
+	tesseract, argsFile := fakeTesseract(t)
+	fallback := NewFallbackVisionClient(staticClient{}, NewTesseractClient(tesseract, "", nil), nil)
+
+	words, err := fallback.GenerateOCR(context.Background(), "llava", "")
+	if err != nil || len(words) != 1 || words[0].Text != "primary" {
+		t.Fatalf("GenerateOCR() = %+v, %v, want the primary's words", words, err)
+	}
+	if _, err := os.Stat(argsFile); !os.IsNotExist(err) {
+		t.Error("tesseract ran while the primary was available")
+	}
+	if fallback.Name() != "static" {
+		t.Errorf("Name() = %q, want the primary's", fallback.Name())
+	}
+}
+
+func TestNew_Engine(t *testing.T) {
+	processor, err := New(&Config{Engine: EngineTesseract})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if _, ok := processor.visionClient.(*TesseractClient); !ok {
+		t.Errorf("vision client = %T, want *TesseractClient", processor.visionClient)
+	}
+
+	if _, err := New(&Config{Engine: "easyocr"}); err == nil {
+		t.Error("New() should error for an unknown engine")
+	}
+}
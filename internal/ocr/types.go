@@ -1,63 +1,69 @@
 package ocr
 
+import "github.com/platinummonkey/legible/internal/ollama"
+
 // PageOCR represents OCR results for a single page
 type PageOCR struct {
 	// PageNumber is the page number (1-indexed)
-	PageNumber int
+	PageNumber int `json:"page"`
 
 	// Words contains all recognized words on the page with their positions
-	Words []Word
+	Words []Word `json:"words"`
 
 	// Text is the full text content of the page (for convenience)
-	Text string
+	Text string `json:"text"`
 
 	// Confidence is the overall confidence score for the page (0-100)
-	Confidence float64
+	Confidence float64 `json:"confidence"`
 
 	// Width is the page width in pixels
-	Width int
+	Width int `json:"width"`
 
 	// Height is the page height in pixels
-	Height int
+	Height int `json:"height"`
 
 	// Language is the detected or configured language
-	Language string
+	Language string `json:"language"`
+
+	// Layout is the page's text lines, tables, and diagrams in reading order, when the
+	// vision client recognizes them (see LayoutVisionClient)
+	Layout *ollama.StructuredOCRResponse `json:"layout,omitempty"`
 }
 
 // Word represents a single recognized word with its bounding box
 type Word struct {
 	// Text is the recognized text content
-	Text string
+	Text string `json:"text"`
 
 	// BoundingBox is the position and size of the word on the page
-	BoundingBox Rectangle
+	BoundingBox Rectangle `json:"bbox"`
 
 	// Confidence is the recognition confidence score (0-100)
-	Confidence float64
+	Confidence float64 `json:"confidence"`
 
 	// FontSize is the estimated font size in points
-	FontSize float64
+	FontSize float64 `json:"font_size,omitempty"`
 
 	// Bold indicates if the word appears to be bold
-	Bold bool
+	Bold bool `json:"bold,omitempty"`
 
 	// Italic indicates if the word appears to be italic
-	Italic bool
+	Italic bool `json:"italic,omitempty"`
 }
 
 // Rectangle represents a rectangular bounding box
 type Rectangle struct {
 	// X is the left coordinate (pixels from left edge)
-	X int
+	X int `json:"x"`
 
 	// Y is the top coordinate (pixels from top edge)
-	Y int
+	Y int `json:"y"`
 
 	// Width is the width of the rectangle in pixels
-	Width int
+	Width int `json:"width"`
 
 	// Height is the height of the rectangle in pixels
-	Height int
+	Height int `json:"height"`
 }
 
 // Line represents a line of text (multiple words)
@@ -93,25 +99,25 @@ type Paragraph struct {
 // DocumentOCR represents OCR results for an entire document
 type DocumentOCR struct {
 	// DocumentID is the unique identifier for the document
-	DocumentID string
+	DocumentID string `json:"document_id,omitempty"`
 
 	// Pages contains OCR results for each page
-	Pages []PageOCR
+	Pages []PageOCR `json:"pages"`
 
 	// TotalPages is the total number of pages processed
-	TotalPages int
+	TotalPages int `json:"total_pages"`
 
 	// TotalWords is the total number of words recognized
-	TotalWords int
+	TotalWords int `json:"total_words"`
 
 	// AverageConfidence is the average confidence across all pages
-	AverageConfidence float64
+	AverageConfidence float64 `json:"average_confidence"`
 
 	// ProcessingTime is the time taken to process the document (in seconds)
-	ProcessingTime float64
+	ProcessingTime float64 `json:"processing_time"`
 
 	// Language is the OCR language(s) used
-	Language string
+	Language string `json:"language"`
 }
 
 // Result represents the result of an OCR operation
@@ -22,6 +22,16 @@ type VisionClient interface {
 	SupportedModels() []string
 }
 
+// LayoutVisionClient is a VisionClient that also recognizes the layout of a page: its
+// text lines, tables, and diagrams
+type LayoutVisionClient interface {
+	VisionClient
+
+	// GenerateOCRWithLayout performs OCR like GenerateOCR, also returning the page layout,
+	// or nil if none was recognized
+	GenerateOCRWithLayout(ctx context.Context, model string, imageData string) ([]ollama.OCRWord, *ollama.StructuredOCRResponse, error)
+}
+
 // ProviderType represents the type of LLM provider
 type ProviderType string
 
@@ -58,4 +68,18 @@ type VisionClientConfig struct {
 
 	// Temperature controls randomness (0.0 = deterministic, recommended for OCR)
 	Temperature float64
+
+	// StructuredImage and SimpleImage are the encodings of images sent to Ollama for
+	// structured and simple OCR; structured OCR recognizes tables and diagrams, and may
+	// benefit from higher fidelity (Ollama only; default: as rendered)
+	StructuredImage ollama.ImageEncoding
+	SimpleImage     ollama.ImageEncoding
+
+	// Languages are the language codes of the handwriting, joined with "+" as in
+	// "eng+deu", selecting a language-specific prompt (Ollama only; default: English)
+	Languages string
+
+	// PromptPath is a YAML prompt file (see ollama.LoadPromptConfig) used for
+	// structured OCR in place of the embedded prompts (Ollama only)
+	PromptPath string
 }
@@ -0,0 +1,255 @@
+// Package ocrbench measures OCR throughput of the configured vision model on bundled handwriting pages.
+package ocrbench
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/platinummonkey/legible/internal/logger"
+	"github.com/platinummonkey/legible/internal/ocr"
+	"github.com/platinummonkey/legible/internal/pdfrender"
+	"github.com/platinummonkey/legible/internal/rmparse"
+	"github.com/signintech/gopdf"
+)
+
+// DefaultPages is the number of pages benchmarked when Config.Pages is unset
+const DefaultPages = 4
+
+// fixtures holds handwritten reMarkable 2 pages; benchmarks cycle through them
+//
+//go:embed fixtures/*.rm
+var fixtures embed.FS
+
+// Config holds configuration for a benchmark
+type Config struct {
+	Logger *logger.Logger
+
+	// Processor runs OCR for each page (required)
+	Processor *ocr.Processor
+
+	// Pages is the number of pages to OCR (default: DefaultPages)
+	Pages int
+
+	// DPI is the resolution pages are rendered at (default: pdfrender.DefaultDPI)
+	DPI int
+}
+
+// Benchmark renders the bundled fixture and times OCR of each page
+type Benchmark struct {
+	logger    *logger.Logger
+	processor *ocr.Processor
+	pages     int
+	dpi       int
+}
+
+// LatencyStats summarizes per-page OCR latency
+type LatencyStats struct {
+	Min  time.Duration
+	Mean time.Duration
+	P50  time.Duration
+	P90  time.Duration
+	Max  time.Duration
+}
+
+// Result reports the outcome of a benchmark run
+type Result struct {
+	// Model is the vision model that processed the pages
+	Model string
+
+	// DPI is the resolution the pages were rendered at
+	DPI int
+
+	// Pages is the number of pages processed successfully; Failed pages are excluded
+	// from every other statistic
+	Pages  int
+	Failed int
+
+	// Words is the total number of words recognized
+	Words int
+
+	// Duration is the wall-clock OCR time for all pages, excluding rendering
+	Duration time.Duration
+
+	// PagesPerMinute is the OCR throughput over Duration
+	PagesPerMinute float64
+
+	// AverageConfidence is the mean page confidence (0-100)
+	AverageConfidence float64
+
+	// Latency summarizes how long each successful page took
+	Latency LatencyStats
+}
+
+// New creates a benchmark
+func New(cfg *Config) (*Benchmark, error) {
+	if cfg == nil || cfg.Processor == nil {
+		return nil, fmt.Errorf("OCR processor is required")
+	}
+
+	log := cfg.Logger
+	if log == nil {
+		log = logger.Get()
+	}
+
+	pages := cfg.Pages
+	if pages == 0 {
+		pages = DefaultPages
+	}
+	if pages < 0 {
+		return nil, fmt.Errorf("pages must be positive, got %d", pages)
+	}
+
+	dpi := cfg.DPI
+	if dpi == 0 {
+		dpi = pdfrender.DefaultDPI
+	}
+	if dpi < 0 {
+		return nil, fmt.Errorf("dpi must be positive, got %d", dpi)
+	}
+
+	return &Benchmark{
+		logger:    log,
+		processor: cfg.Processor,
+		pages:     pages,
+		dpi:       dpi,
+	}, nil
+}
+
+// Run renders the fixture pages and OCRs them one at a time
+//
+// Pages that fail OCR are counted and logged rather than aborting the run; an
+// error is returned only if rendering fails, ctx is cancelled, or every page fails.
+func (b *Benchmark) Run(ctx context.Context) (*Result, error) {
+	tmpDir, err := os.MkdirTemp("", "legible-ocr-bench-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	pdfPath := filepath.Join(tmpDir, "bench.pdf")
+	if err := writeFixturePDF(pdfPath, b.pages); err != nil {
+		return nil, err
+	}
+
+	images, err := pdfrender.New(&pdfrender.Config{Logger: b.logger}).RenderAll(ctx, pdfPath, b.dpi)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render fixture pages: %w", err)
+	}
+
+	result := &Result{Model: b.processor.Model(), DPI: b.dpi}
+	var latencies []time.Duration
+	var confidence float64
+
+	b.logger.WithFields("pages", len(images), "dpi", b.dpi, "model", result.Model).Info("Starting OCR benchmark")
+
+	start := time.Now()
+	for i, img := range images {
+		data, err := pdfrender.EncodePNG(img)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode page %d: %w", i+1, err)
+		}
+
+		pageStart := time.Now()
+		page, err := b.processor.ProcessImageContext(ctx, data, i+1)
+		latency := time.Since(pageStart)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			b.logger.WithFields("page", i+1, "error", err).Warn("OCR failed for benchmark page")
+			result.Failed++
+			continue
+		}
+
+		latencies = append(latencies, latency)
+		confidence += page.Confidence
+		result.Words += len(page.Words)
+		result.Pages++
+	}
+	result.Duration = time.Since(start)
+
+	if result.Pages == 0 {
+		return nil, fmt.Errorf("OCR failed for all %d pages", result.Failed)
+	}
+
+	result.AverageConfidence = confidence / float64(result.Pages)
+	result.PagesPerMinute = float64(result.Pages) / result.Duration.Minutes()
+	result.Latency = summarizeLatencies(latencies)
+
+	return result, nil
+}
+
+// writeFixturePDF renders pages fixture pages, cycling through the bundled files, to a PDF
+func writeFixturePDF(outputPath string, pages int) error {
+	names, err := fs.Glob(fixtures, "fixtures/*.rm")
+	if err != nil || len(names) == 0 {
+		return fmt.Errorf("no benchmark fixtures found")
+	}
+	sort.Strings(names)
+
+	var rmFiles []*rmparse.RMFile
+	for _, name := range names {
+		data, err := fixtures.ReadFile(name)
+		if err != nil {
+			return fmt.Errorf("failed to read fixture %s: %w", name, err)
+		}
+		rmFile, err := rmparse.ParseRMData(data)
+		if err != nil {
+			return fmt.Errorf("failed to parse fixture %s: %w", name, err)
+		}
+		rmFiles = append(rmFiles, rmFile)
+	}
+
+	size := rmparse.RM2PageSize
+	pdf := gopdf.GoPdf{}
+	pdf.Start(gopdf.Config{PageSize: gopdf.Rect{W: size.PDFWidth, H: size.PDFHeight}})
+	for i := 0; i < pages; i++ {
+		pdf.AddPage()
+		if err := rmparse.RenderToPageWithSize(&pdf, rmFiles[i%len(rmFiles)], size); err != nil {
+			return fmt.Errorf("failed to render fixture page %d: %w", i+1, err)
+		}
+	}
+
+	if err := pdf.WritePdf(outputPath); err != nil {
+		return fmt.Errorf("failed to write fixture PDF: %w", err)
+	}
+	return nil
+}
+
+// summarizeLatencies computes latency statistics using nearest-rank percentiles
+func summarizeLatencies(latencies []time.Duration) LatencyStats {
+	if len(latencies) == 0 {
+		return LatencyStats{}
+	}
+
+	sorted := append([]time.Duration(nil), latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var total time.Duration
+	for _, l := range sorted {
+		total += l
+	}
+
+	percentile := func(p float64) time.Duration {
+		rank := int(math.Ceil(p * float64(len(sorted))))
+		if rank < 1 {
+			rank = 1
+		}
+		return sorted[rank-1]
+	}
+
+	return LatencyStats{
+		Min:  sorted[0],
+		Mean: total / time.Duration(len(sorted)),
+		P50:  percentile(0.5),
+		P90:  percentile(0.9),
+		Max:  sorted[len(sorted)-1],
+	}
+}
@@ -0,0 +1,148 @@
+package ocrbench
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/platinummonkey/legible/internal/ocr"
+	"github.com/platinummonkey/legible/internal/ollama"
+)
+
+// newMockOllama returns a server that recognizes three words per page, failing
+// the request numbers in failOn with an unparseable response
+func newMockOllama(t *testing.T, failOn ...int32) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := requests.Add(1)
+
+		var req ollama.GenerateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.Images) != 1 {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+
+		response := `{"lines":[{"bbox":[0,0,40,10],"type":"text","content":"alpha beta gamma"}]}`
+		for _, f := range failOn {
+			if n == f {
+				response = "not json"
+			}
+		}
+		_ = json.NewEncoder(w).Encode(ollama.GenerateResponse{Model: req.Model, Response: response, Done: true})
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &requests
+}
+
+func TestRun(t *testing.T) {
+	server, requests := newMockOllama(t)
+
+	proc, err := ocr.New(&ocr.Config{OllamaEndpoint: server.URL, Model: "test-model", MaxRetries: 1})
+	if err != nil {
+		t.Fatalf("ocr.New() error = %v", err)
+	}
+
+	bench, err := New(&Config{Processor: proc, Pages: 3, DPI: 36})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	result, err := bench.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if result.Pages != 3 || result.Failed != 0 {
+		t.Errorf("Pages = %d, Failed = %d, want 3 and 0", result.Pages, result.Failed)
+	}
+	if requests.Load() != 3 {
+		t.Errorf("server received %d requests, want 3", requests.Load())
+	}
+	if result.Model != "test-model" || result.DPI != 36 {
+		t.Errorf("Model = %q, DPI = %d, want test-model and 36", result.Model, result.DPI)
+	}
+	if result.Words == 0 || result.AverageConfidence <= 0 {
+		t.Errorf("Words = %d, AverageConfidence = %v, want both > 0", result.Words, result.AverageConfidence)
+	}
+	if result.PagesPerMinute <= 0 {
+		t.Errorf("PagesPerMinute = %v, want > 0", result.PagesPerMinute)
+	}
+	if result.Latency.Min > result.Latency.P50 || result.Latency.P50 > result.Latency.Max {
+		t.Errorf("latency stats out of order: %+v", result.Latency)
+	}
+}
+
+func TestRun_PageFailure(t *testing.T) {
+	// Requests 2 and 3 are page 2's structured request and its simple-OCR fallback
+	server, _ := newMockOllama(t, 2, 3)
+
+	proc, err := ocr.New(&ocr.Config{OllamaEndpoint: server.URL, MaxRetries: 1})
+	if err != nil {
+		t.Fatalf("ocr.New() error = %v", err)
+	}
+
+	bench, err := New(&Config{Processor: proc, Pages: 3, DPI: 36})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	result, err := bench.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if result.Pages != 2 || result.Failed != 1 {
+		t.Errorf("Pages = %d, Failed = %d, want 2 and 1", result.Pages, result.Failed)
+	}
+}
+
+func TestNew_Validation(t *testing.T) {
+	proc, err := ocr.New(&ocr.Config{})
+	if err != nil {
+		t.Fatalf("ocr.New() error = %v", err)
+	}
+
+	if _, err := New(&Config{}); err == nil {
+		t.Error("New() should require a processor")
+	}
+	if _, err := New(&Config{Processor: proc, Pages: -1}); err == nil {
+		t.Error("New() should reject negative pages")
+	}
+
+	bench, err := New(&Config{Processor: proc})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if bench.pages != DefaultPages {
+		t.Errorf("pages = %d, want %d", bench.pages, DefaultPages)
+	}
+}
+
+func TestSummarizeLatencies(t *testing.T) {
+	var latencies []time.Duration
+	for i := 10; i >= 1; i-- {
+		latencies = append(latencies, time.Duration(i)*time.Millisecond)
+	}
+
+	stats := summarizeLatencies(latencies)
+	want := LatencyStats{
+		Min:  1 * time.Millisecond,
+		Mean: 5500 * time.Microsecond,
+		P50:  5 * time.Millisecond,
+		P90:  9 * time.Millisecond,
+		Max:  10 * time.Millisecond,
+	}
+	if stats != want {
+		t.Errorf("summarizeLatencies() = %+v, want %+v", stats, want)
+	}
+
+	if empty := summarizeLatencies(nil); empty != (LatencyStats{}) {
+		t.Errorf("summarizeLatencies(nil) = %+v, want zero", empty)
+	}
+}
@@ -17,6 +17,7 @@ import (
 	"os"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/platinummonkey/legible/internal/logger"
 	"gopkg.in/yaml.v3"
@@ -37,6 +38,9 @@ const (
 
 	// DefaultPromptPath is the path to the default OCR prompt YAML
 	DefaultPromptPath = "example-prompt.yaml"
+
+	// DefaultJPEGQuality is the quality of images encoded as JPEG when none is set
+	DefaultJPEGQuality = 90
 )
 
 //go:embed example-prompt.yaml
@@ -57,6 +61,22 @@ Example format:
 
 If no text is found, return an empty array: []`
 
+// OCRBatchPrompt is the prompt template for OCR of several images in one request
+const OCRBatchPrompt = `Extract all handwritten text from each of these %d images of reMarkable tablet notes.
+Return ONLY a JSON object with no additional text or explanation.
+The object must have a "pages" field holding one array per image, in the order the images were given.
+Each array contains objects with:
+- "text": the extracted text
+- "bbox": bounding box as [x, y, width, height] in pixels from top-left origin of that image
+
+Example format for two images:
+{"pages": [
+  [{"text": "Hello", "bbox": [120, 45, 85, 32]}],
+  [{"text": "World", "bbox": [210, 45, 78, 32]}]
+]}
+
+If an image has no text, use an empty array for it: []`
+
 // Client is an HTTP client for the Ollama API
 type Client struct {
 	endpoint     string
@@ -65,6 +85,14 @@ type Client struct {
 	maxRetries   int
 	retryDelay   time.Duration
 	useSimpleOCR bool // If true, skip structured OCR and use simple format
+
+	// Image encodings for structured and simple OCR; the zero value sends images as given
+	structuredImage ImageEncoding
+	simpleImage     ImageEncoding
+
+	// OCR prompt selection: promptPath overrides the prompt for languages
+	languages  []string
+	promptPath string
 }
 
 // ClientOption is a function that configures a Client
@@ -112,6 +140,36 @@ func WithSimpleOCR(useSimple bool) ClientOption {
 	}
 }
 
+// WithStructuredImageEncoding sets the encoding of images sent for structured OCR
+func WithStructuredImageEncoding(enc ImageEncoding) ClientOption {
+	return func(c *Client) {
+		c.structuredImage = enc
+	}
+}
+
+// WithSimpleImageEncoding sets the encoding of images sent for simple OCR
+func WithSimpleImageEncoding(enc ImageEncoding) ClientOption {
+	return func(c *Client) {
+		c.simpleImage = enc
+	}
+}
+
+// WithLanguages sets the languages of the handwriting to OCR, selecting a
+// language-specific prompt (see LoadLanguagePromptConfig)
+func WithLanguages(languages ...string) ClientOption {
+	return func(c *Client) {
+		c.languages = languages
+	}
+}
+
+// WithPromptFile sets a YAML prompt file (see LoadPromptConfig) used for structured OCR
+// in place of the embedded prompts
+func WithPromptFile(path string) ClientOption {
+	return func(c *Client) {
+		c.promptPath = path
+	}
+}
+
 // NewClient creates a new Ollama client
 func NewClient(opts ...ClientOption) *Client {
 	// Create default logger
@@ -147,15 +205,57 @@ func NewClient(opts ...ClientOption) *Client {
 }
 
 // doRequest performs an HTTP request with retry logic
-//
-//nolint:gocyclo // Retry logic and error handling requires branching
 func (c *Client) doRequest(ctx context.Context, method, path string, body interface{}, response interface{}) error {
+	jsonData, err := marshalBody(body)
+	if err != nil {
+		return err
+	}
+
+	return c.withRetries(ctx, func() (bool, error) {
+		resp, retryable, err := c.send(ctx, method, path, jsonData)
+		if err != nil {
+			return retryable, err
+		}
+		defer func() { _ = resp.Body.Close() }()
+
+		respBody, err := io.ReadAll(resp.Body)
+		if err != nil {
+			err = fmt.Errorf("failed to read response body: %w", err)
+			c.logger.Debugf("Failed to read response: %v", err)
+			return true, err
+		}
+
+		// Parse response
+		if response != nil {
+			if err := decodeJSON(respBody, response); err != nil {
+				return false, fmt.Errorf("failed to unmarshal response: %w", err)
+			}
+		}
+		return false, nil
+	})
+}
+
+// marshalBody encodes a request body as JSON, or returns nil for a nil body
+func marshalBody(body interface{}) ([]byte, error) {
+	if body == nil {
+		return nil, nil
+	}
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request body: %w", err)
+	}
+	return jsonData, nil
+}
+
+// withRetries calls attempt until it succeeds or returns an error that isn't
+// retryable, retrying up to maxRetries times with exponential backoff
+func (c *Client) withRetries(ctx context.Context, attempt func() (retryable bool, err error)) error {
 	var lastErr error
 
-	for attempt := 0; attempt <= c.maxRetries; attempt++ {
-		if attempt > 0 {
-			delay := c.retryDelay * time.Duration(1<<uint(attempt-1)) // exponential backoff
-			c.logger.Debugf("Retrying request (attempt %d/%d) after %v", attempt, c.maxRetries, delay)
+	for i := 0; i <= c.maxRetries; i++ {
+		if i > 0 {
+			delay := c.retryDelay * time.Duration(1<<uint(i-1)) // exponential backoff
+			c.logger.Debugf("Retrying request (attempt %d/%d) after %v", i, c.maxRetries, delay)
 			select {
 			case <-ctx.Done():
 				return ctx.Err()
@@ -163,80 +263,156 @@ func (c *Client) doRequest(ctx context.Context, method, path string, body interf
 			}
 		}
 
-		var reqBody io.Reader
-		if body != nil {
-			jsonData, err := json.Marshal(body)
-			if err != nil {
-				return fmt.Errorf("failed to marshal request body: %w", err)
-			}
-			reqBody = bytes.NewReader(jsonData)
+		retryable, err := attempt()
+		if err == nil {
+			return nil
 		}
-
-		req, err := http.NewRequestWithContext(ctx, method, c.endpoint+path, reqBody)
-		if err != nil {
-			return fmt.Errorf("failed to create request: %w", err)
+		if !retryable {
+			return err
 		}
+		lastErr = err
+	}
 
-		if body != nil {
-			req.Header.Set("Content-Type", "application/json")
-		}
+	return fmt.Errorf("request failed after %d attempts: %w", c.maxRetries+1, lastErr)
+}
 
-		resp, err := c.httpClient.Do(req)
-		if err != nil {
-			lastErr = fmt.Errorf("failed to execute request: %w", err)
-			c.logger.Debugf("Request failed: %v", lastErr)
-			continue
-		}
-		defer func() { _ = resp.Body.Close() }()
+// send performs a single HTTP request, returning the response with its body unread if
+// the request succeeded
+//
+// Errors are retryable if the request couldn't be sent or the server returned a 5xx
+// status; 4xx client errors are returned as an *APIError that isn't.
+func (c *Client) send(ctx context.Context, method, path string, jsonData []byte) (*http.Response, bool, error) {
+	var reqBody io.Reader
+	if jsonData != nil {
+		reqBody = bytes.NewReader(jsonData)
+	}
 
+	req, err := http.NewRequestWithContext(ctx, method, c.endpoint+path, reqBody)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if jsonData != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		err = fmt.Errorf("failed to execute request: %w", err)
+		c.logger.Debugf("Request failed: %v", err)
+		return nil, true, err
+	}
+
+	// Check for HTTP errors
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer func() { _ = resp.Body.Close() }()
 		respBody, err := io.ReadAll(resp.Body)
 		if err != nil {
-			lastErr = fmt.Errorf("failed to read response body: %w", err)
-			c.logger.Debugf("Failed to read response: %v", lastErr)
-			continue
+			err = fmt.Errorf("failed to read response body: %w", err)
+			c.logger.Debugf("Failed to read response: %v", err)
+			return nil, true, err
 		}
 
-		// Check for HTTP errors
-		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-			var errResp ErrorResponse
-			var errMsg string
-			if err := json.Unmarshal(respBody, &errResp); err == nil && errResp.Error != "" {
-				errMsg = fmt.Sprintf("ollama API error (status %d): %s", resp.StatusCode, errResp.Error)
-			} else {
-				errMsg = fmt.Sprintf("ollama API error (status %d): %s", resp.StatusCode, string(respBody))
-			}
-
-			// For 5xx server errors, retry. For 4xx client errors, return immediately
-			if resp.StatusCode >= 500 {
-				lastErr = errors.New(errMsg)
-				c.logger.Debugf("Server error: %v", lastErr)
-				continue
-			}
-			return errors.New(errMsg)
+		apiErr := &APIError{StatusCode: resp.StatusCode, Message: string(respBody)}
+		var errResp ErrorResponse
+		if err := decodeJSON(respBody, &errResp); err == nil && errResp.Error != "" {
+			apiErr.Message = errResp.Error
 		}
 
-		// Parse response
-		if response != nil {
-			if err := json.Unmarshal(respBody, response); err != nil {
-				return fmt.Errorf("failed to unmarshal response: %w", err)
-			}
+		// For 5xx server errors, retry. For 4xx client errors, return immediately
+		if resp.StatusCode >= 500 {
+			c.logger.Debugf("Server error: %v", apiErr)
+			return nil, true, apiErr
 		}
-
-		return nil
+		return nil, false, apiErr
 	}
 
-	return fmt.Errorf("request failed after %d attempts: %w", c.maxRetries+1, lastErr)
+	return resp, false, nil
+}
+
+// decodeJSON decodes the first JSON value in data into v, ignoring any trailing content.
+// Models sometimes follow the JSON object with whitespace, commentary or a second object.
+func decodeJSON(data []byte, v interface{}) error {
+	return json.NewDecoder(bytes.NewReader(data)).Decode(v)
 }
 
-// Generate sends a text generation request to Ollama
+// Generate sends a text generation request to Ollama and waits for the whole response
+// See GenerateStream to receive the response as it is generated.
 func (c *Client) Generate(ctx context.Context, req *GenerateRequest) (*GenerateResponse, error) {
+	nonStreaming := *req
+	nonStreaming.Stream = false
+
 	var resp GenerateResponse
-	if err := c.doRequest(ctx, http.MethodPost, "/api/generate", req, &resp); err != nil {
+	if err := c.doRequest(ctx, http.MethodPost, "/api/generate", &nonStreaming, &resp); err != nil {
 		return nil, err
 	}
 	return &resp, nil
 }
 
+// GenerateStream sends a text generation request to Ollama with streaming enabled,
+// calling fn with each chunk of the response as it arrives
+//
+// Ollama streams newline-delimited JSON objects, each holding the next piece of the
+// response; the last has Done set. The returned response holds the whole text and the
+// final chunk's metadata. An error from fn stops the stream and is returned as is.
+// The request is retried like Generate until the first chunk arrives, but not after,
+// as fn has already seen part of the response.
+func (c *Client) GenerateStream(ctx context.Context, req *GenerateRequest, fn func(chunk GenerateResponse) error) (*GenerateResponse, error) {
+	streamReq := *req
+	streamReq.Stream = true
+	jsonData, err := marshalBody(&streamReq)
+	if err != nil {
+		return nil, err
+	}
+
+	var result *GenerateResponse
+	err = c.withRetries(ctx, func() (bool, error) {
+		resp, retryable, err := c.send(ctx, http.MethodPost, "/api/generate", jsonData)
+		if err != nil {
+			return retryable, err
+		}
+		defer func() { _ = resp.Body.Close() }()
+
+		var text strings.Builder
+		chunks := 0
+		decoder := json.NewDecoder(resp.Body)
+		for {
+			var chunk struct {
+				GenerateResponse
+				Error string `json:"error"`
+			}
+			if err := decoder.Decode(&chunk); err == io.EOF {
+				break
+			} else if err != nil {
+				return chunks == 0, fmt.Errorf("failed to read response stream: %w", err)
+			}
+			if chunk.Error != "" {
+				return false, &APIError{StatusCode: resp.StatusCode, Message: chunk.Error}
+			}
+
+			chunks++
+			text.WriteString(chunk.Response)
+			if err := fn(chunk.GenerateResponse); err != nil {
+				return false, err
+			}
+			if chunk.Done {
+				result = &chunk.GenerateResponse
+				break
+			}
+		}
+
+		if result == nil {
+			return chunks == 0, fmt.Errorf("response stream ended before the response was done")
+		}
+		result.Response = text.String()
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
 // GenerateWithVision sends a vision model inference request with image input
 func (c *Client) GenerateWithVision(ctx context.Context, model string, prompt string, images []string) (*GenerateResponse, error) {
 	req := &GenerateRequest{
@@ -252,23 +428,33 @@ func (c *Client) GenerateWithVision(ctx context.Context, model string, prompt st
 // GenerateOCR performs OCR on an image using a vision model
 // Now uses the advanced structured prompt by default for better recognition
 func (c *Client) GenerateOCR(ctx context.Context, model string, imageData string) ([]OCRWord, error) {
+	words, _, err := c.GenerateOCRWithLayout(ctx, model, imageData)
+	return words, err
+}
+
+// GenerateOCRWithLayout performs OCR like GenerateOCR, also returning the structured
+// layout of the page, or nil if OCR fell back to the simple word-level prompt
+func (c *Client) GenerateOCRWithLayout(ctx context.Context, model string, imageData string) ([]OCRWord, *StructuredOCRResponse, error) {
 	// If simple OCR is forced (e.g., for testing), use it directly
 	if c.useSimpleOCR {
-		return c.generateSimpleOCR(ctx, model, imageData)
+		words, err := c.generateSimpleOCR(ctx, model, imageData)
+		return words, nil, err
 	}
 
 	// Try loading the advanced prompt configuration
-	promptConfig, err := LoadPromptConfig("")
+	promptConfig, err := c.PromptConfig()
 	if err != nil {
 		c.logger.WithError(err).Warn("Failed to load structured prompt, falling back to simple OCR")
-		return c.generateSimpleOCR(ctx, model, imageData)
+		words, err := c.generateSimpleOCR(ctx, model, imageData)
+		return words, nil, err
 	}
 
 	// Use structured OCR for better recognition
 	structured, err := c.GenerateStructuredOCR(ctx, imageData, promptConfig)
 	if err != nil {
 		c.logger.WithError(err).Warn("Structured OCR failed, falling back to simple OCR")
-		return c.generateSimpleOCR(ctx, model, imageData)
+		words, err := c.generateSimpleOCR(ctx, model, imageData)
+		return words, nil, err
 	}
 
 	// Convert structured output to word-level format
@@ -276,19 +462,42 @@ func (c *Client) GenerateOCR(ctx context.Context, model string, imageData string
 	c.logger.WithFields("lines", len(structured.Lines), "words", len(words)).
 		Debug("Converted structured OCR to word format")
 
-	return words, nil
+	return words, structured, nil
+}
+
+// PromptConfig returns the structured OCR prompt configuration: the prompt file set
+// with WithPromptFile, or else the prompt for the languages set with WithLanguages
+func (c *Client) PromptConfig() (*PromptConfig, error) {
+	if c.promptPath != "" {
+		return LoadPromptConfig(c.promptPath)
+	}
+	return LoadLanguagePromptConfig(c.languages...)
+}
+
+// languageNote returns a sentence telling simple OCR the language of the handwriting,
+// or an empty string for the default language
+func (c *Client) languageNote() string {
+	if len(c.languages) == 0 {
+		return ""
+	}
+	config, err := LoadLanguagePromptConfig(c.languages...)
+	if err != nil || config.Language == "" {
+		return ""
+	}
+	return fmt.Sprintf("\n\nThe handwriting is in %s; transcribe it as written, without translating it.", config.Language)
 }
 
 // generateSimpleOCR is the original simple OCR implementation (fallback)
 func (c *Client) generateSimpleOCR(ctx context.Context, model string, imageData string) ([]OCRWord, error) {
-	resp, err := c.GenerateWithVision(ctx, model, OCRPrompt, []string{imageData})
+	imageData = c.encodeImage(c.simpleImage, imageData)
+	resp, err := c.GenerateWithVision(ctx, model, OCRPrompt+c.languageNote(), []string{imageData})
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate OCR: %w", err)
 	}
 
 	// Try parsing as array first (expected format)
 	var words []OCRWord
-	if err := json.Unmarshal([]byte(resp.Response), &words); err == nil {
+	if err := decodeJSON([]byte(resp.Response), &words); err == nil {
 		return words, nil
 	}
 
@@ -297,7 +506,7 @@ func (c *Client) generateSimpleOCR(ctx context.Context, model string, imageData
 	var wrappedResponse struct {
 		Words []OCRWord `json:"words"`
 	}
-	if err := json.Unmarshal([]byte(resp.Response), &wrappedResponse); err != nil {
+	if err := decodeJSON([]byte(resp.Response), &wrappedResponse); err != nil {
 		// Log the actual response for debugging
 		c.logger.WithFields("response", resp.Response).Debug("Failed to parse OCR response in any format")
 		return nil, fmt.Errorf("failed to parse OCR response as array or object: %w", err)
@@ -306,6 +515,63 @@ func (c *Client) generateSimpleOCR(ctx context.Context, model string, imageData
 	return wrappedResponse.Words, nil
 }
 
+// GenerateOCRBatch performs OCR on several images, returning one word slice per image in input order.
+// The images are submitted together in a single request; if the model's reply can't be split
+// into one result per image, each image is processed with GenerateOCR instead.
+func (c *Client) GenerateOCRBatch(ctx context.Context, model string, images []string) ([][]OCRWord, error) {
+	if len(images) == 0 {
+		return [][]OCRWord{}, nil
+	}
+
+	if len(images) > 1 {
+		results, err := c.generateBatchOCR(ctx, model, images)
+		if err == nil {
+			return results, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		c.logger.WithFields("images", len(images)).WithError(err).Warn("Batch OCR failed, falling back to sequential requests")
+	}
+
+	results := make([][]OCRWord, len(images))
+	for i, imageData := range images {
+		words, err := c.GenerateOCR(ctx, model, imageData)
+		if err != nil {
+			return nil, fmt.Errorf("failed to OCR image %d: %w", i+1, err)
+		}
+		results[i] = words
+	}
+
+	return results, nil
+}
+
+// generateBatchOCR submits all images in one request and splits the reply per image
+func (c *Client) generateBatchOCR(ctx context.Context, model string, images []string) ([][]OCRWord, error) {
+	resp, err := c.GenerateWithVision(ctx, model, fmt.Sprintf(OCRBatchPrompt, len(images))+c.languageNote(), images)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate batch OCR: %w", err)
+	}
+
+	// Accept the requested {"pages": [...]} object or a bare array of arrays
+	var pages [][]OCRWord
+	var wrapped struct {
+		Pages [][]OCRWord `json:"pages"`
+	}
+	if err := decodeJSON([]byte(resp.Response), &wrapped); err == nil && wrapped.Pages != nil {
+		pages = wrapped.Pages
+	} else if err := decodeJSON([]byte(resp.Response), &pages); err != nil {
+		c.logger.WithFields("response", resp.Response).Debug("Failed to parse batch OCR response")
+		return nil, fmt.Errorf("failed to parse batch OCR response: %w", err)
+	}
+
+	if len(pages) != len(images) {
+		return nil, fmt.Errorf("batch OCR returned %d results for %d images", len(pages), len(images))
+	}
+
+	return pages, nil
+}
+
 // ListModels lists available models
 func (c *Client) ListModels(ctx context.Context) (*ListModelsResponse, error) {
 	var resp ListModelsResponse
@@ -329,6 +595,94 @@ func (c *Client) PullModel(ctx context.Context, modelName string) error {
 	return nil
 }
 
+// ShowModel returns the metadata of a model
+func (c *Client) ShowModel(ctx context.Context, modelName string) (*ShowResponse, error) {
+	var resp ShowResponse
+	if err := c.doRequest(ctx, http.MethodPost, "/api/show", &ShowRequest{Model: modelName}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// knownVisionModels are the names of vision model families in the Ollama library,
+// used when a model's metadata doesn't say whether it reads images
+var knownVisionModels = map[string]bool{
+	"bakllava":          true,
+	"gemma3":            true,
+	"granite3.2-vision": true,
+	"llama3.2-vision":   true,
+	"llama4":            true,
+	"llava":             true,
+	"llava-llama3":      true,
+	"llava-phi3":        true,
+	"minicpm-v":         true,
+	"mistral-small3.1":  true,
+	"moondream":         true,
+	"qwen2.5vl":         true,
+}
+
+// IsKnownVisionModel reports whether a model name, with or without a tag or
+// namespace, is one of the known vision model families
+func IsKnownVisionModel(modelName string) bool {
+	name := modelName[strings.LastIndex(modelName, "/")+1:]
+	if i := strings.Index(name, ":"); i >= 0 {
+		name = name[:i]
+	}
+	return knownVisionModels[strings.ToLower(name)]
+}
+
+// isVisionModel reports whether a model's metadata describes a vision model, and
+// whether the metadata could tell
+func isVisionModel(show *ShowResponse) (vision, known bool) {
+	if len(show.Capabilities) > 0 {
+		for _, capability := range show.Capabilities {
+			if capability == "vision" {
+				return true, true
+			}
+		}
+		return false, true
+	}
+	if len(show.ProjectorInfo) > 0 {
+		return true, true
+	}
+	for _, family := range show.Details.Families {
+		if family == "clip" || family == "mllama" {
+			return true, true
+		}
+	}
+	return false, false
+}
+
+// EnsureVisionModel verifies that a model can read images, returning an error
+// matching ErrNotVisionCapable if it can't
+//
+// The model's capabilities are read from Ollama; when they aren't reported (older
+// Ollama versions) or can't be read, the model name is checked against the known
+// vision models instead.
+func (c *Client) EnsureVisionModel(ctx context.Context, modelName string) error {
+	show, err := c.ShowModel(ctx, modelName)
+	if errors.Is(err, ErrModelNotFound) {
+		return err
+	}
+
+	vision, known := false, false
+	if err != nil {
+		c.logger.WithFields("model", modelName).WithError(err).
+			Warn("Failed to read model metadata, checking the model name instead")
+	} else {
+		vision, known = isVisionModel(show)
+	}
+	if !known {
+		vision = IsKnownVisionModel(modelName)
+	}
+
+	if !vision {
+		return fmt.Errorf("%w: %q cannot read images, configure a vision model such as llava or llama3.2-vision",
+			ErrNotVisionCapable, modelName)
+	}
+	return nil
+}
+
 // HealthCheck verifies that Ollama is running and accessible
 func (c *Client) HealthCheck(ctx context.Context) error {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.endpoint, nil)
@@ -351,6 +705,12 @@ func (c *Client) HealthCheck(ctx context.Context) error {
 
 // EncodeImageToBase64 encodes an image to base64 string
 func EncodeImageToBase64(img image.Image, format string) (string, error) {
+	return EncodeImageToBase64WithQuality(img, format, DefaultJPEGQuality)
+}
+
+// EncodeImageToBase64WithQuality encodes an image to base64 string, as a JPEG of the
+// given quality (1-100) if format is JPEG
+func EncodeImageToBase64WithQuality(img image.Image, format string, quality int) (string, error) {
 	var buf bytes.Buffer
 
 	switch format {
@@ -359,7 +719,7 @@ func EncodeImageToBase64(img image.Image, format string) (string, error) {
 			return "", fmt.Errorf("failed to encode PNG: %w", err)
 		}
 	case "jpeg", "jpg", "JPEG", "JPG":
-		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 90}); err != nil {
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
 			return "", fmt.Errorf("failed to encode JPEG: %w", err)
 		}
 	default:
@@ -369,6 +729,43 @@ func EncodeImageToBase64(img image.Image, format string) (string, error) {
 	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
 }
 
+// encodeImage returns a base64-encoded image re-encoded as enc asks, or as given if
+// enc is the zero value, already matches it, or the image can't be re-encoded
+func (c *Client) encodeImage(enc ImageEncoding, imageData string) string {
+	format := strings.ToLower(enc.Format)
+	if format == "" {
+		return imageData
+	}
+
+	data, err := base64.StdEncoding.DecodeString(imageData)
+	if err != nil {
+		c.logger.WithError(err).Warn("Failed to decode image for re-encoding, sending it as given")
+		return imageData
+	}
+	img, current, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		c.logger.WithError(err).Warn("Failed to decode image for re-encoding, sending it as given")
+		return imageData
+	}
+	// PNG is lossless, so re-encoding one gains nothing
+	if current == "png" && format == "png" {
+		return imageData
+	}
+
+	quality := enc.Quality
+	if quality <= 0 {
+		quality = DefaultJPEGQuality
+	}
+	encoded, err := EncodeImageToBase64WithQuality(img, format, quality)
+	if err != nil {
+		c.logger.WithError(err).Warn("Failed to re-encode image, sending it as given")
+		return imageData
+	}
+	c.logger.WithFields("format", format, "quality", quality, "size", len(encoded), "original_size", len(imageData)).
+		Debug("Re-encoded image for OCR")
+	return encoded
+}
+
 // EncodeBytesToBase64 encodes raw bytes to base64 string
 func EncodeBytesToBase64(data []byte) string {
 	return base64.StdEncoding.EncodeToString(data)
@@ -417,6 +814,7 @@ func (c *Client) GenerateStructuredOCR(ctx context.Context, imageData string, pr
 		fullPrompt = promptConfig.System + "\n\n" + promptConfig.Prompt
 	}
 
+	imageData = c.encodeImage(c.structuredImage, imageData)
 	resp, err := c.GenerateWithVision(ctx, model, fullPrompt, []string{imageData})
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate structured OCR: %w", err)
@@ -424,7 +822,7 @@ func (c *Client) GenerateStructuredOCR(ctx context.Context, imageData string, pr
 
 	// Parse structured response
 	var structuredResp StructuredOCRResponse
-	if err := json.Unmarshal([]byte(resp.Response), &structuredResp); err != nil {
+	if err := decodeJSON([]byte(resp.Response), &structuredResp); err != nil {
 		c.logger.WithFields("response", resp.Response).Debug("Failed to parse structured OCR response")
 		return nil, fmt.Errorf("failed to parse structured OCR response: %w", err)
 	}
@@ -434,69 +832,44 @@ func (c *Client) GenerateStructuredOCR(ctx context.Context, imageData string, pr
 
 // ConvertStructuredToWords converts structured OCR response to word-level format
 // This allows using the advanced prompt while maintaining compatibility with existing PDF text layer code
+//
+// Text lines are split into words whose widths follow their character counts. Tables
+// are split into one word per cell, dividing the table's box evenly into rows and
+// columns. Diagram blocks keep their own boxes. Word boxes are [x, y, width, height].
 func ConvertStructuredToWords(structured *StructuredOCRResponse) []OCRWord {
 	var words []OCRWord
 
 	for _, line := range structured.Lines {
 		switch line.Type {
 		case "text":
-			// Split text content into words and estimate bounding boxes
-			if line.Content == "" {
-				continue
-			}
-
-			// For text lines, we have line-level bbox [x1, y1, x2, y2]
-			// Convert to word-level by splitting the content
-			textWords := strings.Fields(line.Content)
-			if len(textWords) == 0 {
-				continue
-			}
-
-			// Estimate word positions by dividing the line width
-			lineWidth := line.BBox[2] - line.BBox[0]
-			lineHeight := line.BBox[3] - line.BBox[1]
-			wordWidth := lineWidth / len(textWords)
-
-			for i, text := range textWords {
-				// Estimate word bbox: [x, y, width, height]
-				x := line.BBox[0] + (i * wordWidth)
-				y := line.BBox[1]
-				words = append(words, OCRWord{
-					Text:       text,
-					BBox:       []int{x, y, wordWidth, lineHeight},
-					Confidence: 0.85, // Default confidence for structured output
-				})
-			}
+			words = append(words, lineWords(line.Content, line.BBox)...)
 
 		case "table":
-			// Convert table to text representation
-			// Headers
+			// Headers and rows each take an equal share of the table's height
+			var rows [][]string
 			if len(line.Headers) > 0 {
-				headerText := strings.Join(line.Headers, " | ")
-				words = append(words, OCRWord{
-					Text:       headerText,
-					BBox:       line.BBox,
-					Confidence: 0.85,
-				})
+				rows = append(rows, line.Headers)
+			}
+			rows = append(rows, line.Rows...)
+			if len(rows) == 0 || len(line.BBox) < 4 {
+				continue
 			}
 
-			// Rows
-			for _, row := range line.Rows {
-				rowText := strings.Join(row, " | ")
-				words = append(words, OCRWord{
-					Text:       rowText,
-					BBox:       line.BBox,
-					Confidence: 0.85,
-				})
+			tableHeight := line.BBox[3] - line.BBox[1]
+			for r, row := range rows {
+				y1 := line.BBox[1] + r*tableHeight/len(rows)
+				y2 := line.BBox[1] + (r+1)*tableHeight/len(rows)
+				words = append(words, rowWords(row, []int{line.BBox[0], y1, line.BBox[2], y2})...)
 			}
 
 		case "diagram":
 			// Extract text from diagram blocks
 			for _, block := range line.DiagramBlocks {
-				if block.Text != "" {
+				if block.Text != "" && len(block.BBox) >= 4 {
+					// Block boxes are [x1, y1, x2, y2], word boxes [x, y, width, height]
 					words = append(words, OCRWord{
 						Text:       block.Text,
-						BBox:       block.BBox,
+						BBox:       []int{block.BBox[0], block.BBox[1], block.BBox[2] - block.BBox[0], block.BBox[3] - block.BBox[1]},
 						Confidence: 0.80, // Slightly lower for diagrams
 					})
 				}
@@ -506,3 +879,60 @@ func ConvertStructuredToWords(structured *StructuredOCRResponse) []OCRWord {
 
 	return words
 }
+
+// lineWords splits the content of a text line into words, estimating each word's box
+// [x, y, width, height] from the line box [x1, y1, x2, y2]
+//
+// A word's share of the line width follows its character count, with one character's
+// width between words, so long words get wider boxes than short ones.
+func lineWords(content string, bbox []int) []OCRWord {
+	textWords := strings.Fields(content)
+	if len(textWords) == 0 || len(bbox) < 4 {
+		return nil
+	}
+
+	// Total width in characters, counting one space between each pair of words
+	totalChars := len(textWords) - 1
+	for _, text := range textWords {
+		totalChars += utf8.RuneCountInString(text)
+	}
+
+	lineWidth := bbox[2] - bbox[0]
+	lineHeight := bbox[3] - bbox[1]
+	words := make([]OCRWord, 0, len(textWords))
+	offset := 0
+	for _, text := range textWords {
+		n := utf8.RuneCountInString(text)
+		x1 := bbox[0] + offset*lineWidth/totalChars
+		x2 := bbox[0] + (offset+n)*lineWidth/totalChars
+		words = append(words, OCRWord{
+			Text:       text,
+			BBox:       []int{x1, bbox[1], x2 - x1, lineHeight},
+			Confidence: 0.85, // Default confidence for structured output
+		})
+		offset += n + 1
+	}
+	return words
+}
+
+// rowWords returns one word per non-empty cell of a table row, dividing the row box
+// [x1, y1, x2, y2] evenly between its columns
+func rowWords(row []string, bbox []int) []OCRWord {
+	rowHeight := bbox[3] - bbox[1]
+	rowWidth := bbox[2] - bbox[0]
+	var words []OCRWord
+	for c, cell := range row {
+		cell = strings.TrimSpace(cell)
+		if cell == "" {
+			continue
+		}
+		x1 := bbox[0] + c*rowWidth/len(row)
+		x2 := bbox[0] + (c+1)*rowWidth/len(row)
+		words = append(words, OCRWord{
+			Text:       cell,
+			BBox:       []int{x1, bbox[1], x2 - x1, rowHeight},
+			Confidence: 0.85,
+		})
+	}
+	return words
+}
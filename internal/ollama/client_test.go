@@ -3,10 +3,14 @@ package ollama
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"image"
 	"image/color"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -99,6 +103,24 @@ func TestClient_Generate(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "trailing data after response",
+			request: &GenerateRequest{
+				Model:  "llama2",
+				Prompt: "Hello",
+			},
+			mockStatus: http.StatusOK,
+			mockBody:   "{\"model\": \"llama2\", \"response\": \"Hi there!\", \"done\": true}\n\n{\"done\": false} trailing garbage",
+			wantErr:    false,
+			checkResp: func(t *testing.T, resp *GenerateResponse) {
+				if resp.Response != "Hi there!" {
+					t.Errorf("response = %v, want 'Hi there!'", resp.Response)
+				}
+				if !resp.Done {
+					t.Error("expected done from the first object to be kept")
+				}
+			},
+		},
 		{
 			name: "server error",
 			request: &GenerateRequest{
@@ -156,11 +178,97 @@ func TestClient_Generate(t *testing.T) {
 	}
 }
 
+func TestClient_GenerateStream(t *testing.T) {
+	chunks := []string{
+		`{"model":"llama2","response":"Hand","done":false}`,
+		`{"model":"llama2","response":"written ","done":false}`,
+		`{"model":"llama2","response":"notes","done":false}`,
+		`{"model":"llama2","response":"","done":true,"context":[1,2,3],"created_at":"2025-12-31T12:00:00Z"}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req GenerateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || !req.Stream {
+			t.Errorf("request stream = %v (%v), want true", req.Stream, err)
+		}
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		for _, chunk := range chunks {
+			_, _ = fmt.Fprintln(w, chunk)
+			w.(http.Flusher).Flush()
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(WithEndpoint(server.URL), WithMaxRetries(0))
+	var received []string
+	resp, err := client.GenerateStream(context.Background(), &GenerateRequest{Model: "llama2", Prompt: "Read this"},
+		func(chunk GenerateResponse) error {
+			received = append(received, chunk.Response)
+			return nil
+		})
+	if err != nil {
+		t.Fatalf("GenerateStream() error = %v", err)
+	}
+
+	if len(received) != len(chunks) || received[1] != "written " {
+		t.Errorf("callback received %q, want one call per chunk", received)
+	}
+	if resp.Response != "Handwritten notes" {
+		t.Errorf("response = %q, want the chunks assembled", resp.Response)
+	}
+	if !resp.Done || resp.Model != "llama2" || len(resp.Context) != 3 || resp.CreatedAt.IsZero() {
+		t.Errorf("response = %+v, want the final chunk's metadata", resp)
+	}
+}
+
+func TestClient_GenerateStream_Errors(t *testing.T) {
+	// The "crash" model fails mid-stream; others close the connection before the
+	// final chunk
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req GenerateRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		_, _ = fmt.Fprintln(w, `{"model":"llama2","response":"partial","done":false}`)
+		if req.Model == "crash" {
+			_, _ = fmt.Fprintln(w, `{"error":"model crashed"}`)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(WithEndpoint(server.URL), WithMaxRetries(2), WithRetryDelay(time.Millisecond))
+	noop := func(GenerateResponse) error { return nil }
+
+	// An error object in the stream is returned without retrying
+	calls := 0
+	_, err := client.GenerateStream(context.Background(), &GenerateRequest{Model: "crash"}, func(GenerateResponse) error {
+		calls++
+		return nil
+	})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Message != "model crashed" {
+		t.Errorf("GenerateStream() error = %v, want the streamed error", err)
+	}
+	if calls != 1 {
+		t.Errorf("callback called %d times, want once, without retrying", calls)
+	}
+
+	// A stream that ends early is an error
+	if _, err := client.GenerateStream(context.Background(), &GenerateRequest{Model: "llama2"}, noop); err == nil {
+		t.Error("GenerateStream() should fail when the stream ends before it's done")
+	}
+
+	// An error from the callback stops the stream
+	stop := errors.New("stop")
+	if _, err := client.GenerateStream(context.Background(), &GenerateRequest{Model: "llama2"}, func(GenerateResponse) error {
+		return stop
+	}); !errors.Is(err, stop) {
+		t.Errorf("GenerateStream() error = %v, want the callback's error", err)
+	}
+}
+
 func TestClient_GenerateWithVision(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		var req GenerateRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			t.Fatalf("failed to decode request: %v", err)
+			t.Errorf("failed to decode request: %v", err)
 		}
 
 		if len(req.Images) == 0 {
@@ -232,6 +340,17 @@ func TestClient_GenerateOCR(t *testing.T) {
 			wantWords: 2,
 			wantErr:   false,
 		},
+		{
+			name: "trailing data after JSON",
+			mockBody: `{
+				"model": "llava",
+				"response": "[{\"text\":\"Hello\",\"bbox\":[10,20,50,30]}]\n\nI found one word. {\"note\": \"done\"}",
+				"done": true,
+				"created_at": "2025-12-31T12:00:00Z"
+			}`,
+			wantWords: 1,
+			wantErr:   false,
+		},
 		{
 			name: "invalid JSON response",
 			mockBody: `{
@@ -325,7 +444,7 @@ func TestClient_PullModel(t *testing.T) {
 
 		var req PullRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			t.Fatalf("failed to decode request: %v", err)
+			t.Errorf("failed to decode request: %v", err)
 		}
 		if req.Name != "llama2" {
 			t.Errorf("model name = %v, want llama2", req.Name)
@@ -520,3 +639,467 @@ func TestEncodeBytesToBase64(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_GenerateOCRBatch(t *testing.T) {
+	var requests []GenerateRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req GenerateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		requests = append(requests, req)
+
+		response := `{"pages": [[{"text": "first", "bbox": [1, 2, 3, 4]}], [], [{"text": "third", "bbox": [5, 6, 7, 8]}, {"text": "page", "bbox": [9, 10, 11, 12]}]]}`
+		body, _ := json.Marshal(GenerateResponse{Model: req.Model, Response: response, Done: true})
+		_, _ = w.Write(body)
+	}))
+	defer server.Close()
+
+	client := NewClient(WithEndpoint(server.URL), WithSimpleOCR(true))
+	results, err := client.GenerateOCRBatch(context.Background(), "llava", []string{"img1", "img2", "img3"})
+	if err != nil {
+		t.Fatalf("GenerateOCRBatch() error = %v", err)
+	}
+
+	if len(requests) != 1 {
+		t.Fatalf("expected a single batch request, got %d", len(requests))
+	}
+	if got := requests[0].Images; len(got) != 3 || got[0] != "img1" || got[1] != "img2" || got[2] != "img3" {
+		t.Errorf("request images = %v, want [img1 img2 img3]", got)
+	}
+
+	if len(results) != 3 {
+		t.Fatalf("got %d results, want 3", len(results))
+	}
+	if len(results[0]) != 1 || results[0][0].Text != "first" {
+		t.Errorf("results[0] = %+v, want [first]", results[0])
+	}
+	if len(results[1]) != 0 {
+		t.Errorf("results[1] = %+v, want empty", results[1])
+	}
+	if len(results[2]) != 2 || results[2][0].Text != "third" || results[2][1].Text != "page" {
+		t.Errorf("results[2] = %+v, want [third page]", results[2])
+	}
+}
+
+func TestClient_GenerateOCRBatch_SequentialFallback(t *testing.T) {
+	var requests []GenerateRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req GenerateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		requests = append(requests, req)
+
+		// Batch requests get a single un-split array, forcing the fallback
+		response := `[{"text": "merged", "bbox": [0, 0, 1, 1]}]`
+		if len(req.Images) == 1 {
+			response = fmt.Sprintf(`[{"text": %q, "bbox": [0, 0, 1, 1]}]`, req.Images[0])
+		}
+		body, _ := json.Marshal(GenerateResponse{Model: req.Model, Response: response, Done: true})
+		_, _ = w.Write(body)
+	}))
+	defer server.Close()
+
+	client := NewClient(WithEndpoint(server.URL), WithSimpleOCR(true))
+	results, err := client.GenerateOCRBatch(context.Background(), "llava", []string{"img1", "img2"})
+	if err != nil {
+		t.Fatalf("GenerateOCRBatch() error = %v", err)
+	}
+
+	// One failed batch request followed by one request per image
+	if len(requests) != 3 {
+		t.Fatalf("expected 3 requests, got %d", len(requests))
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+	for i, want := range []string{"img1", "img2"} {
+		if len(results[i]) != 1 || results[i][0].Text != want {
+			t.Errorf("results[%d] = %+v, want [%s]", i, results[i], want)
+		}
+	}
+}
+
+func TestClient_ModelNotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error": "model \"llava\" not found, try pulling it first"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithEndpoint(server.URL))
+	_, err := client.Generate(context.Background(), &GenerateRequest{Model: "llava", Prompt: "test"})
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Generate() error = %v, want an *APIError", err)
+	}
+	if apiErr.StatusCode != http.StatusNotFound || apiErr.Message != `model "llava" not found, try pulling it first` {
+		t.Errorf("APIError = %+v", apiErr)
+	}
+	if !errors.Is(err, ErrModelNotFound) {
+		t.Error("errors.Is(err, ErrModelNotFound) = false, want true")
+	}
+	if errors.Is(&APIError{StatusCode: http.StatusInternalServerError}, ErrModelNotFound) {
+		t.Error("a server error should not match ErrModelNotFound")
+	}
+}
+
+func TestClient_EnsureVisionModel(t *testing.T) {
+	// The show API answers with the metadata for each model
+	metadata := map[string]string{
+		"llama3.2-vision": `{"details":{"family":"mllama"},"capabilities":["completion","vision"]}`,
+		"llama2":          `{"details":{"family":"llama"},"capabilities":["completion"]}`,
+		"old-llava":       `{"details":{"family":"llama","families":["llama","clip"]}}`,
+		"old-llama2":      `{"details":{"family":"llama","families":["llama"]}}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/show" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		var req ShowRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		switch {
+		case req.Model == "missing":
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":"model 'missing' not found"}`))
+		case metadata[req.Model] != "":
+			_, _ = w.Write([]byte(metadata[req.Model]))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"unsupported"}`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(WithEndpoint(server.URL), WithMaxRetries(0))
+	tests := []struct {
+		model   string
+		wantErr error
+	}{
+		{"llama3.2-vision", nil},
+		{"llama2", ErrNotVisionCapable},
+		{"old-llava", nil},
+		// Without capabilities or a vision family, the name decides
+		{"old-llama2", ErrNotVisionCapable},
+		// When the metadata can't be read, so does the name
+		{"llava:13b", nil},
+		{"mistral", ErrNotVisionCapable},
+		{"missing", ErrModelNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			err := client.EnsureVisionModel(context.Background(), tt.model)
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil) != (err == nil) {
+				t.Fatalf("EnsureVisionModel() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == ErrNotVisionCapable && !strings.Contains(err.Error(), "not vision-capable") {
+				t.Errorf("error %q should say the model is not vision-capable", err)
+			}
+		})
+	}
+}
+
+func TestIsKnownVisionModel(t *testing.T) {
+	for name, want := range map[string]bool{
+		"llava":                   true,
+		"llava:34b":               true,
+		"library/Moondream:1.8b":  true,
+		"llama3.2-vision:11b":     true,
+		"llama2":                  false,
+		"llama3.2":                false,
+		"someone/llava-finetuned": false,
+	} {
+		if got := IsKnownVisionModel(name); got != want {
+			t.Errorf("IsKnownVisionModel(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestClient_OCRImageEncoding(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 64, 32))
+	for x := 0; x < 64; x++ {
+		img.Set(x, x/2, color.RGBA{R: uint8(x * 4), A: 255})
+	}
+	pngImage, err := EncodeImageToBase64(img, "png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantStructured, err := EncodeImageToBase64WithQuality(img, "jpeg", 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Structured OCR fails, so each image is sent once for structured and once for
+	// simple OCR
+	sent := make(map[string]string)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req GenerateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.Images) != 1 {
+			t.Errorf("unexpected request: %+v, %v", req, err)
+			return
+		}
+		mode, response := "structured", "not json"
+		if req.Prompt == OCRPrompt {
+			mode, response = "simple", "[]"
+		}
+		sent[mode] = req.Images[0]
+		_ = json.NewEncoder(w).Encode(GenerateResponse{Model: req.Model, Response: response, Done: true})
+	}))
+	defer server.Close()
+
+	client := NewClient(
+		WithEndpoint(server.URL),
+		WithStructuredImageEncoding(ImageEncoding{Format: "jpeg", Quality: 50}),
+		WithSimpleImageEncoding(ImageEncoding{Format: "png"}),
+	)
+	if _, err := client.GenerateOCR(context.Background(), "llava", pngImage); err != nil {
+		t.Fatalf("GenerateOCR() error = %v", err)
+	}
+
+	if sent["structured"] != wantStructured {
+		t.Error("structured OCR was not sent the image as a JPEG of quality 50")
+	}
+	// The image is already a PNG, so simple OCR is sent it unchanged
+	if sent["simple"] != pngImage {
+		t.Error("simple OCR was not sent the PNG as given")
+	}
+}
+
+func TestConvertStructuredToWords_TableRow(t *testing.T) {
+	structured := &StructuredOCRResponse{Lines: []OCRLine{{
+		Type: "table",
+		BBox: []int{100, 200, 400, 230},
+		Rows: [][]string{{"Name", "Qty", "Price"}},
+	}}}
+
+	words := ConvertStructuredToWords(structured)
+	if len(words) != 3 {
+		t.Fatalf("ConvertStructuredToWords() returned %d words, want 3: %+v", len(words), words)
+	}
+	for i, want := range []string{"Name", "Qty", "Price"} {
+		if words[i].Text != want {
+			t.Errorf("word %d = %q, want %q", i, words[i].Text, want)
+		}
+		if words[i].BBox[1] != 200 || words[i].BBox[3] != 30 {
+			t.Errorf("word %d box %v doesn't span the row's height", i, words[i].BBox)
+		}
+	}
+	for i := 1; i < len(words); i++ {
+		prevEnd := words[i-1].BBox[0] + words[i-1].BBox[2]
+		if words[i].BBox[0] < prevEnd {
+			t.Errorf("word %d box %v overlaps word %d box %v", i, words[i].BBox, i-1, words[i-1].BBox)
+		}
+	}
+	if words[0].BBox[0] != 100 || words[2].BBox[0]+words[2].BBox[2] != 400 {
+		t.Errorf("cells %v don't span the row from 100 to 400", words)
+	}
+}
+
+func TestConvertStructuredToWords_TableRows(t *testing.T) {
+	structured := &StructuredOCRResponse{Lines: []OCRLine{{
+		Type:    "table",
+		BBox:    []int{0, 0, 200, 90},
+		Headers: []string{"A", "B"},
+		Rows:    [][]string{{"1", ""}, {"3", "4"}},
+	}}}
+
+	words := ConvertStructuredToWords(structured)
+	// The empty cell is skipped
+	want := []OCRWord{
+		{Text: "A", BBox: []int{0, 0, 100, 30}},
+		{Text: "B", BBox: []int{100, 0, 100, 30}},
+		{Text: "1", BBox: []int{0, 30, 100, 30}},
+		{Text: "3", BBox: []int{0, 60, 100, 30}},
+		{Text: "4", BBox: []int{100, 60, 100, 30}},
+	}
+	if len(words) != len(want) {
+		t.Fatalf("ConvertStructuredToWords() returned %d words, want %d: %+v", len(words), len(want), words)
+	}
+	for i := range want {
+		if words[i].Text != want[i].Text || fmt.Sprint(words[i].BBox) != fmt.Sprint(want[i].BBox) {
+			t.Errorf("word %d = %q %v, want %q %v", i, words[i].Text, words[i].BBox, want[i].Text, want[i].BBox)
+		}
+	}
+}
+
+func TestConvertStructuredToWords_TextWeightedByLength(t *testing.T) {
+	structured := &StructuredOCRResponse{Lines: []OCRLine{{
+		Type:    "text",
+		BBox:    []int{0, 10, 130, 30},
+		Content: "a exceptional",
+	}}}
+
+	words := ConvertStructuredToWords(structured)
+	if len(words) != 2 {
+		t.Fatalf("ConvertStructuredToWords() returned %d words, want 2: %+v", len(words), words)
+	}
+	// 1 + 1 (space) + 11 characters over 130 points is 10 points per character
+	if got := fmt.Sprint(words[0].BBox); got != "[0 10 10 20]" {
+		t.Errorf("word %q box = %s, want [0 10 10 20]", words[0].Text, got)
+	}
+	if got := fmt.Sprint(words[1].BBox); got != "[20 10 110 20]" {
+		t.Errorf("word %q box = %s, want [20 10 110 20]", words[1].Text, got)
+	}
+}
+
+func TestConvertStructuredToWords_Diagram(t *testing.T) {
+	structured := &StructuredOCRResponse{Lines: []OCRLine{{
+		Type: "diagram",
+		BBox: []int{0, 0, 500, 500},
+		DiagramBlocks: []DiagramBlock{
+			{Type: "block", Text: "Start", BBox: []int{10, 10, 60, 40}},
+			{Type: "arrow", BBox: []int{60, 20, 100, 30}},
+			{Type: "block", Text: "End", BBox: []int{100, 10, 150, 40}},
+		},
+	}}}
+
+	words := ConvertStructuredToWords(structured)
+	if len(words) != 2 {
+		t.Fatalf("ConvertStructuredToWords() returned %d words, want 2: %+v", len(words), words)
+	}
+	if fmt.Sprint(words[0].BBox) != "[10 10 50 30]" || fmt.Sprint(words[1].BBox) != "[100 10 50 30]" {
+		t.Errorf("diagram words = %+v, want each block's own box", words)
+	}
+}
+
+func TestLoadLanguagePromptConfig(t *testing.T) {
+	defaultConfig, err := LoadPromptConfig("")
+	if err != nil {
+		t.Fatalf("LoadPromptConfig() error = %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		languages    []string
+		wantLanguage string
+	}{
+		{name: "no languages", languages: nil, wantLanguage: ""},
+		{name: "english", languages: []string{"eng"}, wantLanguage: ""},
+		{name: "unknown", languages: []string{"xyz"}, wantLanguage: ""},
+		{name: "german", languages: []string{"deu"}, wantLanguage: "German"},
+		{name: "french", languages: []string{"fra"}, wantLanguage: "French"},
+		{name: "japanese", languages: []string{"jpn"}, wantLanguage: "Japanese"},
+		{name: "spanish", languages: []string{"spa"}, wantLanguage: "Spanish"},
+		{name: "joined with english", languages: []string{"eng+deu"}, wantLanguage: "German"},
+		{name: "first with a prompt", languages: []string{"eng", "fra+deu"}, wantLanguage: "French"},
+		{name: "iso 639-1 code", languages: []string{"ja"}, wantLanguage: "Japanese"},
+		{name: "upper case", languages: []string{"DEU"}, wantLanguage: "German"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := LoadLanguagePromptConfig(tt.languages...)
+			if err != nil {
+				t.Fatalf("LoadLanguagePromptConfig() error = %v", err)
+			}
+			if config.Language != tt.wantLanguage {
+				t.Errorf("Language = %q, want %q", config.Language, tt.wantLanguage)
+			}
+
+			// Language prompts replace the system message and keep the default's
+			// model and prompt
+			if config.Model != defaultConfig.Model || config.Prompt != defaultConfig.Prompt {
+				t.Error("language prompt didn't keep the default model and prompt")
+			}
+			if tt.wantLanguage == "" {
+				if config.System != defaultConfig.System {
+					t.Errorf("System = %q, want the default", config.System)
+				}
+			} else if !strings.Contains(config.System, tt.wantLanguage+" handwriting") {
+				t.Errorf("System = %q, want it to mention %s handwriting", config.System, tt.wantLanguage)
+			}
+		})
+	}
+}
+
+func TestPromptLanguages(t *testing.T) {
+	got := strings.Join(PromptLanguages(), ",")
+	if got != "deu,fra,jpn,spa" {
+		t.Errorf("PromptLanguages() = %s, want deu,fra,jpn,spa", got)
+	}
+}
+
+// promptRecordingServer returns a server that records the prompt of each request and
+// replies to structured OCR with one line and to simple OCR with one word
+func promptRecordingServer(t *testing.T, prompts *[]string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req GenerateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+			return
+		}
+		*prompts = append(*prompts, req.Prompt)
+		response := `{"lines":[{"bbox":[0,0,40,10],"type":"text","content":"Hallo"}]}`
+		if strings.HasPrefix(req.Prompt, OCRPrompt) {
+			response = `[{"text":"Hallo","bbox":[0,0,40,10]}]`
+		}
+		_ = json.NewEncoder(w).Encode(GenerateResponse{Model: req.Model, Response: response, Done: true})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestClient_GenerateOCR_LanguagePrompt(t *testing.T) {
+	german, err := LoadLanguagePromptConfig("deu")
+	if err != nil {
+		t.Fatalf("LoadLanguagePromptConfig() error = %v", err)
+	}
+
+	var prompts []string
+	server := promptRecordingServer(t, &prompts)
+
+	client := NewClient(WithEndpoint(server.URL), WithLanguages("eng+deu"))
+	if _, err := client.GenerateOCR(context.Background(), "llava", "aW1hZ2U="); err != nil {
+		t.Fatalf("GenerateOCR() error = %v", err)
+	}
+	if len(prompts) != 1 || prompts[0] != german.System+"\n\n"+german.Prompt {
+		t.Errorf("structured OCR prompts = %q, want the German prompt", prompts)
+	}
+
+	prompts = nil
+	simple := NewClient(WithEndpoint(server.URL), WithLanguages("deu"), WithSimpleOCR(true))
+	if _, err := simple.GenerateOCR(context.Background(), "llava", "aW1hZ2U="); err != nil {
+		t.Fatalf("GenerateOCR() error = %v", err)
+	}
+	if len(prompts) != 1 || !strings.HasPrefix(prompts[0], OCRPrompt) || !strings.Contains(prompts[0], "The handwriting is in German") {
+		t.Errorf("simple OCR prompts = %q, want the simple prompt noting German handwriting", prompts)
+	}
+
+	// English keeps the prompts unchanged
+	prompts = nil
+	english := NewClient(WithEndpoint(server.URL), WithLanguages("eng"), WithSimpleOCR(true))
+	if _, err := english.GenerateOCR(context.Background(), "llava", "aW1hZ2U="); err != nil {
+		t.Fatalf("GenerateOCR() error = %v", err)
+	}
+	if len(prompts) != 1 || prompts[0] != OCRPrompt {
+		t.Errorf("simple OCR prompts = %q, want the simple prompt unchanged", prompts)
+	}
+}
+
+func TestClient_PromptFile(t *testing.T) {
+	path := t.TempDir() + "/prompt.yaml"
+	if err := os.WriteFile(path, []byte("model: llava\nsystem: Read carefully\nprompt: Transcribe this page\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var prompts []string
+	server := promptRecordingServer(t, &prompts)
+
+	// The prompt file takes precedence over the language prompt
+	client := NewClient(WithEndpoint(server.URL), WithLanguages("deu"), WithPromptFile(path))
+	if _, err := client.GenerateOCR(context.Background(), "llava", "aW1hZ2U="); err != nil {
+		t.Fatalf("GenerateOCR() error = %v", err)
+	}
+	if len(prompts) != 1 || prompts[0] != "Read carefully\n\nTranscribe this page" {
+		t.Errorf("prompts = %q, want the prompt file's", prompts)
+	}
+
+	missing := NewClient(WithPromptFile(t.TempDir() + "/missing.yaml"))
+	if _, err := missing.PromptConfig(); err == nil {
+		t.Error("PromptConfig() succeeded for a missing prompt file")
+	}
+}
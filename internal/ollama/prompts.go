@@ -0,0 +1,85 @@
+package ollama
+
+import (
+	"embed"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed prompts/*.yaml
+var languagePromptFS embed.FS
+
+// languageAliases maps other codes for a language to the Tesseract (ISO 639-2) code
+// that names its prompt
+var languageAliases = map[string]string{
+	"de":  "deu",
+	"ger": "deu",
+	"fr":  "fra",
+	"fre": "fra",
+	"es":  "spa",
+	"ja":  "jpn",
+}
+
+// PromptLanguages returns the language codes that have an embedded prompt, sorted
+func PromptLanguages() []string {
+	entries, err := languagePromptFS.ReadDir("prompts")
+	if err != nil {
+		return nil
+	}
+	var languages []string
+	for _, entry := range entries {
+		languages = append(languages, strings.TrimSuffix(entry.Name(), ".yaml"))
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// splitLanguages splits language codes, each a single code or several joined with
+// "+" as in "eng+deu", into lowercase Tesseract codes
+func splitLanguages(languages []string) []string {
+	var codes []string
+	for _, langs := range languages {
+		for _, code := range strings.Split(langs, "+") {
+			code = strings.ToLower(strings.TrimSpace(code))
+			if code == "" {
+				continue
+			}
+			if alias, ok := languageAliases[code]; ok {
+				code = alias
+			}
+			codes = append(codes, code)
+		}
+	}
+	return codes
+}
+
+// LoadLanguagePromptConfig loads the OCR prompt configuration for the first of
+// languages that has an embedded prompt, or the default prompt if none has one
+//
+// Languages are Tesseract codes such as "deu", or several joined with "+" as in
+// "eng+deu"; ISO 639-1 codes such as "de" are also accepted. English uses the default
+// prompt, so "eng+deu" selects the German one. A language prompt sets Language and
+// replaces the fields of the default prompt it sets.
+func LoadLanguagePromptConfig(languages ...string) (*PromptConfig, error) {
+	config, err := LoadPromptConfig("")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, code := range splitLanguages(languages) {
+		data, err := languagePromptFS.ReadFile(path.Join("prompts", code+".yaml"))
+		if err != nil {
+			continue
+		}
+		if err := yaml.Unmarshal(data, config); err != nil {
+			return nil, fmt.Errorf("failed to parse %s prompt YAML: %w", code, err)
+		}
+		return config, nil
+	}
+
+	return config, nil
+}
@@ -1,6 +1,11 @@
 package ollama
 
-import "time"
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
 
 // GenerateRequest represents a request to the Ollama generate API
 type GenerateRequest struct {
@@ -21,6 +26,14 @@ type GenerateResponse struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
+// ImageEncoding is the format images are re-encoded to before they are sent for OCR
+type ImageEncoding struct {
+	// Format is "png" or "jpeg"; empty sends images as given
+	Format string
+	// Quality is the JPEG quality, 1-100 (default: DefaultJPEGQuality)
+	Quality int
+}
+
 // OCRWord represents extracted text with bounding box information
 type OCRWord struct {
 	Text       string  `json:"text"`
@@ -33,6 +46,9 @@ type PromptConfig struct {
 	Model  string `yaml:"model"`
 	System string `yaml:"system"`
 	Prompt string `yaml:"prompt"`
+	// Language is the name of the language the prompt reads, e.g. "German", or empty
+	// for the default prompt; simple OCR is told the handwriting is in it
+	Language string `yaml:"language,omitempty"`
 }
 
 // StructuredOCRResponse represents the structured response from advanced OCR prompt
@@ -59,17 +75,20 @@ type DiagramBlock struct {
 
 // Model represents an Ollama model
 type Model struct {
-	Name       string    `json:"name"`
-	ModifiedAt time.Time `json:"modified_at"`
-	Size       int64     `json:"size"`
-	Digest     string    `json:"digest"`
-	Details    struct {
-		Format            string   `json:"format"`
-		Family            string   `json:"family"`
-		Families          []string `json:"families"`
-		ParameterSize     string   `json:"parameter_size"`
-		QuantizationLevel string   `json:"quantization_level"`
-	} `json:"details"`
+	Name       string       `json:"name"`
+	ModifiedAt time.Time    `json:"modified_at"`
+	Size       int64        `json:"size"`
+	Digest     string       `json:"digest"`
+	Details    ModelDetails `json:"details"`
+}
+
+// ModelDetails describes the architecture of an Ollama model
+type ModelDetails struct {
+	Format            string   `json:"format"`
+	Family            string   `json:"family"`
+	Families          []string `json:"families"`
+	ParameterSize     string   `json:"parameter_size"`
+	QuantizationLevel string   `json:"quantization_level"`
 }
 
 // ListModelsResponse represents a response from the list models API
@@ -91,7 +110,45 @@ type PullResponse struct {
 	Completed int64  `json:"completed,omitempty"`
 }
 
+// ShowRequest represents a request for a model's metadata
+type ShowRequest struct {
+	Model string `json:"model"`
+}
+
+// ShowResponse represents a response from the show model API
+//
+// Capabilities is only reported by Ollama 0.6.4 and later; older versions describe
+// vision models by their "clip" family or a projector instead.
+type ShowResponse struct {
+	Details       ModelDetails           `json:"details"`
+	Capabilities  []string               `json:"capabilities,omitempty"`
+	ProjectorInfo map[string]interface{} `json:"projector_info,omitempty"`
+}
+
 // ErrorResponse represents an error response from Ollama
 type ErrorResponse struct {
 	Error string `json:"error"`
 }
+
+// ErrModelNotFound matches API errors for a model that hasn't been pulled
+var ErrModelNotFound = errors.New("model not found")
+
+// ErrNotVisionCapable is returned for a model that cannot read images
+var ErrNotVisionCapable = errors.New("model is not vision-capable")
+
+// APIError is an error status returned by the Ollama API
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+// Error formats the status and message
+func (e *APIError) Error() string {
+	return fmt.Sprintf("ollama API error (status %d): %s", e.StatusCode, e.Message)
+}
+
+// Is reports whether the error is ErrModelNotFound: Ollama answers requests for a
+// missing model with 404 Not Found
+func (e *APIError) Is(target error) bool {
+	return target == ErrModelNotFound && e.StatusCode == http.StatusNotFound
+}
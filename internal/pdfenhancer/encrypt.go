@@ -0,0 +1,54 @@
+package pdfenhancer
+
+import (
+	"fmt"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+)
+
+// DefaultEncryptKeyLength is the AES key length in bits EncryptPDF uses when given 0
+const DefaultEncryptKeyLength = 256
+
+// EncryptPDF writes inputPath encrypted with AES to outputPath, which may be the same
+// file
+//
+// userPassword is needed to open the PDF; an empty one lets anyone open it, while
+// ownerPassword still guards its permissions. An empty ownerPassword uses
+// userPassword. keyLength is 40, 128, or 256 bits, or 0 for DefaultEncryptKeyLength.
+func (pe *PDFEnhancer) EncryptPDF(inputPath, outputPath, userPassword, ownerPassword string, keyLength int) error {
+	if userPassword == "" && ownerPassword == "" {
+		return fmt.Errorf("a user or owner password is required to encrypt a PDF")
+	}
+	if ownerPassword == "" {
+		ownerPassword = userPassword
+	}
+	if keyLength == 0 {
+		keyLength = DefaultEncryptKeyLength
+	}
+	if keyLength != 40 && keyLength != 128 && keyLength != 256 {
+		return fmt.Errorf("invalid key length %d, must be 40, 128, or 256", keyLength)
+	}
+
+	pe.logger.WithFields("input", inputPath, "output", outputPath, "key_length", keyLength).Debug("Encrypting PDF")
+
+	conf := model.NewAESConfiguration(userPassword, ownerPassword, keyLength)
+	if err := api.EncryptFile(inputPath, outputPath, conf); err != nil {
+		return fmt.Errorf("failed to encrypt PDF: %w", err)
+	}
+	return nil
+}
+
+// DecryptPDF writes inputPath, encrypted by EncryptPDF or otherwise, decrypted to
+// outputPath, which may be the same file; password is its user or owner password
+func (pe *PDFEnhancer) DecryptPDF(inputPath, outputPath, password string) error {
+	pe.logger.WithFields("input", inputPath, "output", outputPath).Debug("Decrypting PDF")
+
+	conf := model.NewDefaultConfiguration()
+	conf.UserPW = password
+	conf.OwnerPW = password
+	if err := api.DecryptFile(inputPath, outputPath, conf); err != nil {
+		return fmt.Errorf("failed to decrypt PDF: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package pdfenhancer
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestPDFEnhancer_EncryptPDF(t *testing.T) {
+	tmpDir := t.TempDir()
+	plainPath := filepath.Join(tmpDir, "plain.pdf")
+	encryptedPath := filepath.Join(tmpDir, "encrypted.pdf")
+	createTestPDF(t, plainPath, 1)
+
+	enhancer := New(&Config{})
+	if err := enhancer.EncryptPDF(plainPath, encryptedPath, "secret", "owner", 0); err != nil {
+		t.Fatalf("EncryptPDF() error = %v", err)
+	}
+
+	info, err := enhancer.GetPDFInfoWithPassword(encryptedPath, "secret")
+	if err != nil {
+		t.Fatalf("GetPDFInfoWithPassword() error = %v", err)
+	}
+	if !info.Encrypted || info.PageCount != 1 {
+		t.Errorf("encrypted PDF info = %+v, want an encrypted PDF of 1 page", info)
+	}
+
+	// Without the password, or with the wrong one, the PDF can't be read
+	if _, err := enhancer.GetPDFInfo(encryptedPath); err == nil {
+		t.Error("GetPDFInfo() read an encrypted PDF without its password")
+	}
+	if _, err := enhancer.GetPDFInfoWithPassword(encryptedPath, "wrong"); err == nil {
+		t.Error("GetPDFInfoWithPassword() read an encrypted PDF with the wrong password")
+	}
+	if err := enhancer.DecryptPDF(encryptedPath, filepath.Join(tmpDir, "wrong.pdf"), "wrong"); err == nil {
+		t.Error("DecryptPDF() decrypted a PDF with the wrong password")
+	}
+
+	// Either password decrypts it
+	for _, password := range []string{"secret", "owner"} {
+		decryptedPath := filepath.Join(tmpDir, password+".pdf")
+		if err := enhancer.DecryptPDF(encryptedPath, decryptedPath, password); err != nil {
+			t.Fatalf("DecryptPDF() with the %s password error = %v", password, err)
+		}
+		info, err := enhancer.GetPDFInfo(decryptedPath)
+		if err != nil {
+			t.Fatalf("GetPDFInfo() of the decrypted PDF error = %v", err)
+		}
+		if info.Encrypted || info.PageCount != 1 {
+			t.Errorf("decrypted PDF info = %+v, want an unencrypted PDF of 1 page", info)
+		}
+	}
+
+	plain, err := enhancer.GetPDFInfo(plainPath)
+	if err != nil {
+		t.Fatalf("GetPDFInfo() error = %v", err)
+	}
+	if plain.Encrypted {
+		t.Error("GetPDFInfo() reports an unencrypted PDF as encrypted")
+	}
+}
+
+func TestPDFEnhancer_EncryptPDF_InvalidOptions(t *testing.T) {
+	tmpDir := t.TempDir()
+	plainPath := filepath.Join(tmpDir, "plain.pdf")
+	createTestPDF(t, plainPath, 1)
+
+	enhancer := New(&Config{})
+	if err := enhancer.EncryptPDF(plainPath, filepath.Join(tmpDir, "out.pdf"), "", "", 0); err == nil {
+		t.Error("EncryptPDF() should require a password")
+	}
+	if err := enhancer.EncryptPDF(plainPath, filepath.Join(tmpDir, "out.pdf"), "secret", "", 64); err == nil {
+		t.Error("EncryptPDF() should reject a 64 bit key")
+	}
+
+	// Encrypting in place with only a user password uses it as the owner password
+	if err := enhancer.EncryptPDF(plainPath, plainPath, "secret", "", 128); err != nil {
+		t.Fatalf("EncryptPDF() in place error = %v", err)
+	}
+	if info, err := enhancer.GetPDFInfoWithPassword(plainPath, "secret"); err != nil || !info.Encrypted {
+		t.Errorf("GetPDFInfoWithPassword() = %+v, %v, want an encrypted PDF", info, err)
+	}
+}
@@ -5,7 +5,11 @@ import (
 	"bytes"
 	"fmt"
 	"os"
+	"runtime"
+	"strconv"
 	"strings"
+	"sync"
+	"unicode/utf8"
 
 	"github.com/pdfcpu/pdfcpu/pkg/api"
 	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
@@ -16,7 +20,8 @@ import (
 
 // PDFEnhancer provides utilities for reading and enhancing PDF files
 type PDFEnhancer struct {
-	logger *logger.Logger
+	logger           *logger.Logger
+	splitConcurrency int // Max parallel workers for SplitPDF, one per CPU
 }
 
 // Config holds configuration for the PDF enhancer
@@ -32,7 +37,8 @@ func New(cfg *Config) *PDFEnhancer {
 	}
 
 	return &PDFEnhancer{
-		logger: log,
+		logger:           log,
+		splitConcurrency: runtime.NumCPU(),
 	}
 }
 
@@ -76,6 +82,31 @@ func (pe *PDFEnhancer) ValidatePDF(pdfPath string) error {
 func (pe *PDFEnhancer) AddTextLayer(inputPath, outputPath string, ocrResults *ocr.DocumentOCR) error {
 	pe.logger.WithFields("input", inputPath, "output", outputPath).Info("Adding text layer to PDF")
 
+	if err := pe.writeTextLayer(inputPath, outputPath, ocrResults, false); err != nil {
+		return err
+	}
+
+	pe.logger.WithFields("output", outputPath).Info("Successfully added text layer to PDF")
+	return nil
+}
+
+// WriteTranscript writes a copy of a PDF whose pages are blank apart from their OCR
+// text, drawn visibly where each word was written
+// The original page content, such as handwriting and templates, is discarded.
+func (pe *PDFEnhancer) WriteTranscript(inputPath, outputPath string, ocrResults *ocr.DocumentOCR) error {
+	pe.logger.WithFields("input", inputPath, "output", outputPath).Info("Writing transcript PDF")
+
+	if err := pe.writeTextLayer(inputPath, outputPath, ocrResults, true); err != nil {
+		return err
+	}
+
+	pe.logger.WithFields("output", outputPath).Info("Successfully wrote transcript PDF")
+	return nil
+}
+
+// writeTextLayer adds OCR text to each page of a PDF, replacing the page content with
+// visible text if transcript is set
+func (pe *PDFEnhancer) writeTextLayer(inputPath, outputPath string, ocrResults *ocr.DocumentOCR, transcript bool) error {
 	if ocrResults == nil {
 		return fmt.Errorf("OCR results cannot be nil")
 	}
@@ -93,26 +124,30 @@ func (pe *PDFEnhancer) AddTextLayer(inputPath, outputPath string, ocrResults *oc
 	}
 
 	// Add text layer to each page
+	fonts := newTextLayerFonts()
 	for i, pageOCR := range ocrResults.Pages {
 		pageNum := i + 1
 		pe.logger.WithFields("page", pageNum).Debug("Adding text layer to page")
 
-		if err := pe.addTextToPage(ctx, pageNum, &pageOCR); err != nil {
+		if err := pe.addTextToPage(ctx, pageNum, &pageOCR, transcript, fonts); err != nil {
 			return fmt.Errorf("failed to add text to page %d: %w", pageNum, err)
 		}
 	}
+	if err := fonts.finish(ctx); err != nil {
+		return err
+	}
 
 	// Write the enhanced PDF
 	if err := api.WriteContextFile(ctx, outputPath); err != nil {
 		return fmt.Errorf("failed to write output PDF: %w", err)
 	}
 
-	pe.logger.WithFields("output", outputPath).Info("Successfully added text layer to PDF")
 	return nil
 }
 
-// addTextToPage adds OCR text to a specific page
-func (pe *PDFEnhancer) addTextToPage(ctx *model.Context, pageNum int, pageOCR *ocr.PageOCR) error {
+// addTextToPage adds OCR text to a specific page, shown with fonts, first removing the
+// page's content if transcript is set
+func (pe *PDFEnhancer) addTextToPage(ctx *model.Context, pageNum int, pageOCR *ocr.PageOCR, transcript bool, fonts *textLayerFonts) error {
 	// Get the page dictionary and inherited attributes
 	pageDict, _, inheritedAttrs, err := ctx.PageDict(pageNum, false)
 	if err != nil {
@@ -130,23 +165,29 @@ func (pe *PDFEnhancer) addTextToPage(ctx *model.Context, pageNum int, pageOCR *o
 	pdfPageWidth := inheritedAttrs.MediaBox.Width()
 	pdfPageHeight := inheritedAttrs.MediaBox.Height()
 
+	// A transcript page starts blank, even if it has no words
+	if transcript {
+		pageDict.Delete("Contents")
+	}
+
 	// Skip if no words to add
 	if len(pageOCR.Words) == 0 {
 		pe.logger.WithFields("page", pageNum).Debug("No OCR words to add, skipping")
 		return nil
 	}
 
-	// Ensure page has font resources
-	if err := pe.ensurePageFonts(ctx, pageDict); err != nil {
-		return fmt.Errorf("failed to ensure page fonts: %w", err)
-	}
-
-	// Create content stream with invisible text
-	contentStream, err := pe.createTextContentStream(pageOCR, pdfPageWidth, pdfPageHeight)
+	// Create content stream with the text, invisible unless writing a transcript
+	contentStream, err := pe.createTextContentStream(pageOCR, pdfPageWidth, pdfPageHeight, transcript, fonts)
 	if err != nil {
 		return fmt.Errorf("failed to create content stream: %w", err)
 	}
 
+	// Ensure page has the font resources the text uses
+	usesUnicodeFont := bytes.Contains(contentStream, []byte("/"+unicodeFontName+" "))
+	if err := pe.ensurePageFonts(ctx, pageDict, fonts, usesUnicodeFont); err != nil {
+		return fmt.Errorf("failed to ensure page fonts: %w", err)
+	}
+
 	// Add content stream to page
 	if err := pe.appendContentStream(ctx, pageDict, contentStream); err != nil {
 		return fmt.Errorf("failed to append content stream: %w", err)
@@ -158,8 +199,9 @@ func (pe *PDFEnhancer) addTextToPage(ctx *model.Context, pageNum int, pageOCR *o
 	return nil
 }
 
-// createTextContentStream generates a PDF content stream with invisible text
-func (pe *PDFEnhancer) createTextContentStream(pageOCR *ocr.PageOCR, pdfPageWidth, pdfPageHeight float64) ([]byte, error) {
+// createTextContentStream generates a PDF content stream with the page's OCR text,
+// encoded for fonts and drawn in black if visible is set and invisible otherwise
+func (pe *PDFEnhancer) createTextContentStream(pageOCR *ocr.PageOCR, pdfPageWidth, pdfPageHeight float64, visible bool, fonts *textLayerFonts) ([]byte, error) {
 	var buf bytes.Buffer
 
 	// Calculate scaling factors between OCR coordinates and PDF coordinates
@@ -182,8 +224,13 @@ func (pe *PDFEnhancer) createTextContentStream(pageOCR *ocr.PageOCR, pdfPageWidt
 	// Begin text object
 	buf.WriteString("BT\n")
 
-	// Set text rendering mode to invisible (Tr 3 = no fill, no stroke)
-	buf.WriteString("3 Tr\n")
+	if visible {
+		// Fill text in black (Tr 0 = fill)
+		buf.WriteString("0 g\n0 Tr\n")
+	} else {
+		// Set text rendering mode to invisible (Tr 3 = no fill, no stroke)
+		buf.WriteString("3 Tr\n")
+	}
 
 	// Add each word with its position and scaling
 	for _, word := range pageOCR.Words {
@@ -206,16 +253,21 @@ func (pe *PDFEnhancer) createTextContentStream(pageOCR *ocr.PageOCR, pdfPageWidt
 			fontSize = 1.0 // Minimum font size
 		}
 
-		// Set font with calculated size
-		fmt.Fprintf(&buf, "/Helvetica %.2f Tf\n", fontSize)
+		// Encode the text for the font that can show it: Helvetica with WinAnsiEncoding,
+		// or the Unicode font for other scripts
+		font, encodedText, err := fonts.encode(word.Text)
+		if err != nil {
+			return nil, err
+		}
 
-		// Escape text for PDF string
-		escapedText := pe.escapePDFString(word.Text)
+		// Set font with calculated size
+		fmt.Fprintf(&buf, "/%s %.2f Tf\n", font, fontSize)
 
 		// Calculate horizontal scaling to fit text width to bounding box
-		// Helvetica at fontSize has approximately fontSize * 0.5 per character width
+		// Helvetica at fontSize has approximately fontSize * 0.5 per character width,
+		// as does every glyph of the Unicode font
 		// This is a rough estimate; actual width depends on characters
-		estimatedTextWidth := float64(len(word.Text)) * fontSize * 0.5
+		estimatedTextWidth := float64(utf8.RuneCountInString(word.Text)) * fontSize * 0.5
 		boxWidth := float64(word.BoundingBox.Width) * scaleX
 		horizontalScale := 1.0
 		if estimatedTextWidth > 0 {
@@ -237,7 +289,7 @@ func (pe *PDFEnhancer) createTextContentStream(pageOCR *ocr.PageOCR, pdfPageWidt
 		fmt.Fprintf(&buf, "%.3f 0 0 1 %.2f %.2f Tm\n", horizontalScale, pdfX, pdfY)
 
 		// Show text using Tj operator
-		fmt.Fprintf(&buf, "(%s) Tj\n", escapedText)
+		fmt.Fprintf(&buf, "%s Tj\n", encodedText)
 	}
 
 	// End text object
@@ -249,40 +301,11 @@ func (pe *PDFEnhancer) createTextContentStream(pageOCR *ocr.PageOCR, pdfPageWidt
 	return buf.Bytes(), nil
 }
 
-// escapePDFString escapes special characters in a PDF string literal
-func (pe *PDFEnhancer) escapePDFString(s string) string {
-	// Escape backslash, parentheses, and other special characters
-	s = strings.ReplaceAll(s, "\\", "\\\\")
-	s = strings.ReplaceAll(s, "(", "\\(")
-	s = strings.ReplaceAll(s, ")", "\\)")
-	s = strings.ReplaceAll(s, "\n", "\\n")
-	s = strings.ReplaceAll(s, "\r", "\\r")
-	s = strings.ReplaceAll(s, "\t", "\\t")
-	return s
-}
-
 // appendContentStream adds a content stream to an existing page
 func (pe *PDFEnhancer) appendContentStream(ctx *model.Context, pageDict types.Dict, contentData []byte) error {
-	// Create a new stream dictionary for our content
-	streamDict := types.NewDict()
-	streamDict.Insert("Length", types.Integer(len(contentData)))
-
-	// Create stream object with properly initialized fields
-	streamLength := int64(len(contentData))
-	sd := types.NewStreamDict(
-		streamDict,
-		0,             // streamOffset (will be set during write)
-		&streamLength, // streamLength
-		nil,           // streamLengthObjNr (not using indirect length)
-		nil,           // filterPipeline (no compression)
-	)
-	sd.Content = contentData
-	sd.Raw = contentData // Set raw data as well
-
-	// Add stream to context and get indirect reference
-	indRef, err := ctx.IndRefForNewObject(sd)
+	indRef, err := newStreamObject(ctx, contentData)
 	if err != nil {
-		return fmt.Errorf("failed to create indirect reference: %w", err)
+		return err
 	}
 
 	// Get existing Contents entry
@@ -312,6 +335,32 @@ func (pe *PDFEnhancer) appendContentStream(ctx *model.Context, pageDict types.Di
 	return nil
 }
 
+// newStreamObject adds an uncompressed stream with data to the PDF
+func newStreamObject(ctx *model.Context, data []byte) (*types.IndirectRef, error) {
+	// Create a new stream dictionary for our content
+	streamDict := types.NewDict()
+	streamDict.Insert("Length", types.Integer(len(data)))
+
+	// Create stream object with properly initialized fields
+	streamLength := int64(len(data))
+	sd := types.NewStreamDict(
+		streamDict,
+		0,             // streamOffset (will be set during write)
+		&streamLength, // streamLength
+		nil,           // streamLengthObjNr (not using indirect length)
+		nil,           // filterPipeline (no compression)
+	)
+	sd.Content = data
+	sd.Raw = data // Set raw data as well
+
+	// Add stream to context and get indirect reference
+	indRef, err := ctx.IndRefForNewObject(sd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create indirect reference: %w", err)
+	}
+	return indRef, nil
+}
+
 // OptimizePDF optimizes a PDF file by compressing and removing unnecessary data
 func (pe *PDFEnhancer) OptimizePDF(inputPath, outputPath string) error {
 	pe.logger.WithFields("input", inputPath, "output", outputPath).Info("Optimizing PDF")
@@ -387,7 +436,73 @@ func (pe *PDFEnhancer) MergePDFs(inputPaths []string, outputPath string) error {
 	return nil
 }
 
+// ReorderPages writes the pages of inputPath to outputPath in the given order
+//
+// order holds 1-based page numbers of the input; output page i is a copy of input
+// page order[i], including any OCR text layer it carries.
+func (pe *PDFEnhancer) ReorderPages(inputPath, outputPath string, order []int) error {
+	if len(order) == 0 {
+		return fmt.Errorf("no pages provided")
+	}
+
+	pages := make([]string, len(order))
+	for i, page := range order {
+		if page < 1 {
+			return fmt.Errorf("invalid page number %d", page)
+		}
+		pages[i] = strconv.Itoa(page)
+	}
+
+	pe.logger.WithFields("input", inputPath, "output", outputPath, "order", order).Debug("Reordering PDF pages")
+
+	conf := model.NewDefaultConfiguration()
+	if err := api.CollectFile(inputPath, outputPath, pages, conf); err != nil {
+		return fmt.Errorf("failed to reorder pages: %w", err)
+	}
+
+	return nil
+}
+
+// RotatePages writes inputPath to outputPath, which may be the same file, with pages
+// rotated clockwise by rotation degrees, a multiple of 90
+//
+// pages holds 1-based page numbers of the pages to rotate; empty rotates every page.
+// The rotation is added to any the pages already have.
+func (pe *PDFEnhancer) RotatePages(inputPath, outputPath string, rotation int, pages []int) error {
+	if rotation%90 != 0 {
+		return fmt.Errorf("invalid rotation %d, must be a multiple of 90", rotation)
+	}
+
+	var selected []string
+	if len(pages) > 0 {
+		pageCount, err := pe.GetPageCount(inputPath)
+		if err != nil {
+			return err
+		}
+		selected = make([]string, len(pages))
+		for i, page := range pages {
+			if page < 1 || page > pageCount {
+				return fmt.Errorf("invalid page number %d (PDF has %d pages)", page, pageCount)
+			}
+			selected[i] = strconv.Itoa(page)
+		}
+	}
+
+	pe.logger.WithFields("input", inputPath, "output", outputPath, "rotation", rotation, "pages", pages).Debug("Rotating PDF pages")
+
+	conf := model.NewDefaultConfiguration()
+	if err := api.RotateFile(inputPath, outputPath, rotation, selected, conf); err != nil {
+		return fmt.Errorf("failed to rotate pages: %w", err)
+	}
+
+	return nil
+}
+
 // SplitPDF splits a PDF into individual pages
+//
+// Pages are divided into contiguous ranges that are extracted in parallel, with at most
+// one worker per CPU each parsing the input once. Output files are named
+// <inputBasename>_page_N.pdf regardless of the concurrency used.
 func (pe *PDFEnhancer) SplitPDF(inputPath, outputDir string) error {
 	pe.logger.WithFields("input", inputPath, "output_dir", outputDir).Info("Splitting PDF")
 
@@ -402,29 +517,89 @@ func (pe *PDFEnhancer) SplitPDF(inputPath, outputDir string) error {
 		return fmt.Errorf("failed to get page count: %w", err)
 	}
 
-	// Split into individual pages
-	// ExtractPagesFile will create files named <inputBasename>_page_N.pdf in outputDir
-	conf := model.NewDefaultConfiguration()
+	chunks := splitPageRanges(pageCount, pe.splitConcurrency)
 
-	// Build page list (all pages)
-	pages := make([]string, pageCount)
-	for i := 1; i <= pageCount; i++ {
-		pages[i-1] = fmt.Sprintf("%d", i)
+	// Extract each chunk of pages in its own worker
+	// ExtractPagesFile will create files named <inputBasename>_page_N.pdf in outputDir
+	errs := make([]error, len(chunks))
+	var wg sync.WaitGroup
+	for i, pages := range chunks {
+		wg.Add(1)
+		go func(i int, pages []string) {
+			defer wg.Done()
+			conf := model.NewDefaultConfiguration()
+			if err := api.ExtractPagesFile(inputPath, outputDir, pages, conf); err != nil {
+				errs[i] = fmt.Errorf("failed to extract pages %s-%s: %w", pages[0], pages[len(pages)-1], err)
+			}
+		}(i, pages)
 	}
+	wg.Wait()
 
-	if err := api.ExtractPagesFile(inputPath, outputDir, pages, conf); err != nil {
-		return fmt.Errorf("failed to extract pages: %w", err)
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
 	}
 
-	pe.logger.WithFields("page_count", pageCount).Info("PDF split successful")
+	pe.logger.WithFields("page_count", pageCount, "workers", len(chunks)).Info("PDF split successful")
 	return nil
 }
 
+// splitPageRanges divides pages 1..pageCount into at most workers contiguous ranges
+func splitPageRanges(pageCount, workers int) [][]string {
+	if pageCount <= 0 {
+		return nil
+	}
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > pageCount {
+		workers = pageCount
+	}
+
+	chunks := make([][]string, 0, workers)
+	chunkSize := pageCount / workers
+	remainder := pageCount % workers
+
+	page := 1
+	for w := 0; w < workers; w++ {
+		size := chunkSize
+		if w < remainder {
+			size++
+		}
+
+		pages := make([]string, size)
+		for i := range pages {
+			pages[i] = strconv.Itoa(page)
+			page++
+		}
+		chunks = append(chunks, pages)
+	}
+
+	return chunks
+}
+
 // GetPDFInfo returns basic information about a PDF file
+// A PDF encrypted with a user password can't be read; see GetPDFInfoWithPassword.
 func (pe *PDFEnhancer) GetPDFInfo(pdfPath string) (*PDFInfo, error) {
+	return pe.GetPDFInfoWithPassword(pdfPath, "")
+}
+
+// GetPDFInfoWithPassword returns basic information about a PDF file, opening it with
+// password if it is encrypted
+func (pe *PDFEnhancer) GetPDFInfoWithPassword(pdfPath, password string) (*PDFInfo, error) {
 	pe.logger.WithFields("pdf_path", pdfPath).Debug("Getting PDF info")
 
-	ctx, err := api.ReadContextFile(pdfPath)
+	f, err := os.Open(pdfPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PDF: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	conf := model.NewDefaultConfiguration()
+	conf.UserPW = password
+	conf.OwnerPW = password
+	ctx, err := api.ReadAndValidate(f, conf)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read PDF: %w", err)
 	}
@@ -463,73 +638,61 @@ type PDFInfo struct {
 	Linearized bool
 }
 
-// ensurePageFonts ensures that standard PDF fonts are available in the page resources
-func (pe *PDFEnhancer) ensurePageFonts(ctx *model.Context, pageDict types.Dict) error {
-	// Get or create Resources dictionary
-	var resourcesDict types.Dict
-	resourcesEntry, found := pageDict.Find("Resources")
-	if !found || resourcesEntry == nil {
-		// Create new Resources dictionary
-		resourcesDict = types.NewDict()
-		pageDict.Update("Resources", resourcesDict)
-	} else {
-		// Use existing Resources
-		switch res := resourcesEntry.(type) {
-		case types.Dict:
-			resourcesDict = res
-		case types.IndirectRef:
-			// Dereference
-			obj, err := ctx.Dereference(res)
-			if err != nil {
-				return fmt.Errorf("failed to dereference Resources: %w", err)
-			}
-			dict, ok := obj.(types.Dict)
-			if !ok {
-				return fmt.Errorf("resources is not a dictionary")
-			}
-			resourcesDict = dict
-		default:
-			return fmt.Errorf("unexpected Resources type: %T", res)
-		}
+// ensureSubDict returns the dictionary under key in dict, dereferencing it if needed,
+// or adds an empty one if there is none
+func ensureSubDict(ctx *model.Context, dict types.Dict, key string) (types.Dict, error) {
+	entry, found := dict.Find(key)
+	if !found || entry == nil {
+		sub := types.NewDict()
+		dict.Update(key, sub)
+		return sub, nil
 	}
 
-	// Get or create Font dictionary
-	var fontDict types.Dict
-	fontEntry, found := resourcesDict.Find("Font")
-	if !found || fontEntry == nil {
-		// Create new Font dictionary
-		fontDict = types.NewDict()
-		resourcesDict.Update("Font", fontDict)
-	} else {
-		// Use existing Font dictionary
-		switch f := fontEntry.(type) {
-		case types.Dict:
-			fontDict = f
-		case types.IndirectRef:
-			obj, err := ctx.Dereference(f)
-			if err != nil {
-				return fmt.Errorf("failed to dereference Font: %w", err)
-			}
-			dict, ok := obj.(types.Dict)
-			if !ok {
-				return fmt.Errorf("font is not a dictionary")
-			}
-			fontDict = dict
-		default:
-			return fmt.Errorf("unexpected Font type: %T", f)
+	switch e := entry.(type) {
+	case types.Dict:
+		return e, nil
+	case types.IndirectRef:
+		obj, err := ctx.Dereference(e)
+		if err != nil {
+			return nil, fmt.Errorf("failed to dereference %s: %w", key, err)
 		}
+		sub, ok := obj.(types.Dict)
+		if !ok {
+			return nil, fmt.Errorf("%s is not a dictionary", strings.ToLower(key))
+		}
+		return sub, nil
+	default:
+		return nil, fmt.Errorf("unexpected %s type: %T", key, e)
+	}
+}
+
+// ensurePageFonts ensures that the fonts of the text layer are available in the page
+// resources: Helvetica, and the Unicode font of fonts if withUnicode is set
+func (pe *PDFEnhancer) ensurePageFonts(ctx *model.Context, pageDict types.Dict, fonts *textLayerFonts, withUnicode bool) error {
+	resourcesDict, err := ensureSubDict(ctx, pageDict, "Resources")
+	if err != nil {
+		return err
+	}
+	fontDict, err := ensureSubDict(ctx, resourcesDict, "Font")
+	if err != nil {
+		return err
 	}
 
 	// Check if Helvetica is already defined
-	if _, found := fontDict.Find("Helvetica"); !found {
-		// Add Helvetica font (Type 1 standard font)
-		helveticaDict := types.NewDict()
-		helveticaDict.Insert("Type", types.Name("Font"))
-		helveticaDict.Insert("Subtype", types.Name("Type1"))
-		helveticaDict.Insert("BaseFont", types.Name("Helvetica"))
-
-		// Add font to font dictionary
-		fontDict.Update("Helvetica", helveticaDict)
+	if _, found := fontDict.Find(helveticaFontName); !found {
+		ref, err := fonts.helveticaFontRef(ctx)
+		if err != nil {
+			return err
+		}
+		fontDict.Update(helveticaFontName, *ref)
+	}
+
+	if withUnicode {
+		ref, err := fonts.unicodeFontRef(ctx)
+		if err != nil {
+			return err
+		}
+		fontDict.Update(unicodeFontName, *ref)
 	}
 
 	return nil
@@ -4,10 +4,14 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
 
+	"github.com/pdfcpu/pdfcpu/pkg/api"
 	"github.com/platinummonkey/legible/internal/logger"
 	"github.com/platinummonkey/legible/internal/ocr"
+	"github.com/signintech/gopdf"
 )
 
 // createTestPDF creates a simple test PDF file
@@ -362,6 +366,109 @@ func TestPDFEnhancer_MergePDFs_SingleFile(t *testing.T) {
 	}
 }
 
+// createSizedPDF creates a PDF with one page per width, so pages can be told apart by size
+func createSizedPDF(t *testing.T, path string, widths ...float64) {
+	t.Helper()
+
+	pdf := gopdf.GoPdf{}
+	pdf.Start(gopdf.Config{PageSize: *gopdf.PageSizeA4})
+	for _, w := range widths {
+		pdf.AddPageWithOption(gopdf.PageOption{PageSize: &gopdf.Rect{W: w, H: 500}})
+	}
+	if err := pdf.WritePdf(path); err != nil {
+		t.Fatalf("failed to write PDF: %v", err)
+	}
+}
+
+func TestPDFEnhancer_ReorderPages(t *testing.T) {
+	enhancer := New(&Config{})
+	tmpDir := t.TempDir()
+	inputPath := filepath.Join(tmpDir, "input.pdf")
+	outputPath := filepath.Join(tmpDir, "output.pdf")
+	createSizedPDF(t, inputPath, 100, 200, 300)
+
+	if err := enhancer.ReorderPages(inputPath, outputPath, []int{3, 1, 2}); err != nil {
+		t.Fatalf("ReorderPages() error = %v", err)
+	}
+
+	dims, err := api.PageDimsFile(outputPath)
+	if err != nil {
+		t.Fatalf("failed to read page sizes: %v", err)
+	}
+	var widths []float64
+	for _, d := range dims {
+		widths = append(widths, d.Width)
+	}
+	if fmt.Sprint(widths) != "[300 100 200]" {
+		t.Errorf("page widths = %v, want [300 100 200]", widths)
+	}
+
+	if err := enhancer.ReorderPages(inputPath, outputPath, []int{0}); err == nil {
+		t.Error("ReorderPages() should reject page 0")
+	}
+	if err := enhancer.ReorderPages(inputPath, outputPath, nil); err == nil {
+		t.Error("ReorderPages() should reject an empty order")
+	}
+}
+
+// pageRotations returns the /Rotate entry of each page of the PDF at path
+func pageRotations(t *testing.T, path string) []int {
+	t.Helper()
+
+	ctx, err := api.ReadContextFile(path)
+	if err != nil {
+		t.Fatalf("failed to read PDF: %v", err)
+	}
+	rotations := make([]int, ctx.PageCount)
+	for i := range rotations {
+		pageDict, _, _, err := ctx.PageDict(i+1, false)
+		if err != nil {
+			t.Fatalf("failed to read page %d: %v", i+1, err)
+		}
+		if rotate := pageDict.IntEntry("Rotate"); rotate != nil {
+			rotations[i] = *rotate
+		}
+	}
+	return rotations
+}
+
+func TestPDFEnhancer_RotatePages(t *testing.T) {
+	enhancer := New(&Config{})
+	tmpDir := t.TempDir()
+	inputPath := filepath.Join(tmpDir, "input.pdf")
+	outputPath := filepath.Join(tmpDir, "output.pdf")
+	createSizedPDF(t, inputPath, 100, 200, 300)
+
+	// Only the selected page is rotated
+	if err := enhancer.RotatePages(inputPath, outputPath, 90, []int{2}); err != nil {
+		t.Fatalf("RotatePages() error = %v", err)
+	}
+	if got := fmt.Sprint(pageRotations(t, outputPath)); got != "[0 90 0]" {
+		t.Errorf("page rotations = %s, want [0 90 0]", got)
+	}
+
+	// No pages rotates every page, adding to their rotation, in place
+	if err := enhancer.RotatePages(outputPath, outputPath, 180, nil); err != nil {
+		t.Fatalf("RotatePages() of every page error = %v", err)
+	}
+	if got := fmt.Sprint(pageRotations(t, outputPath)); got != "[180 270 180]" {
+		t.Errorf("page rotations = %s, want [180 270 180]", got)
+	}
+
+	for _, tt := range []struct {
+		rotation int
+		pages    []int
+	}{
+		{rotation: 45},
+		{rotation: 90, pages: []int{0}},
+		{rotation: 90, pages: []int{4}},
+	} {
+		if err := enhancer.RotatePages(inputPath, outputPath, tt.rotation, tt.pages); err == nil {
+			t.Errorf("RotatePages(%d, %v) should fail", tt.rotation, tt.pages)
+		}
+	}
+}
+
 func TestPDFEnhancer_SplitPDF(t *testing.T) {
 	tmpDir := t.TempDir()
 	inputPath := filepath.Join(tmpDir, "input.pdf")
@@ -384,6 +491,149 @@ func TestPDFEnhancer_SplitPDF(t *testing.T) {
 	}
 }
 
+// createMultiPageTestPDF creates a PDF whose page N is 200+N points wide, so split pages can be identified
+func createMultiPageTestPDF(tb testing.TB, path string, pageCount int) {
+	tb.Helper()
+
+	var buf strings.Builder
+	var offsets []int
+	writeObj := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n")
+	writeObj("<< /Type /Catalog /Pages 2 0 R >>")
+
+	kids := make([]string, pageCount)
+	for i := range kids {
+		kids[i] = fmt.Sprintf("%d 0 R", i+3)
+	}
+	writeObj(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), pageCount))
+
+	for i := 1; i <= pageCount; i++ {
+		writeObj(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d 792] /Resources << >> >>", 200+i))
+	}
+
+	xrefOffset := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xrefOffset)
+
+	if err := os.WriteFile(path, []byte(buf.String()), 0644); err != nil {
+		tb.Fatalf("failed to write test PDF: %v", err)
+	}
+}
+
+// splitPageWidths splits a PDF and returns the page width of each output file keyed by file name
+func splitPageWidths(t *testing.T, inputPath, outputDir string, concurrency int) map[string]int {
+	t.Helper()
+
+	enhancer := New(&Config{})
+	enhancer.splitConcurrency = concurrency
+	if err := enhancer.SplitPDF(inputPath, outputDir); err != nil {
+		t.Fatalf("SplitPDF() with concurrency %d error = %v", concurrency, err)
+	}
+
+	entries, err := os.ReadDir(outputDir)
+	if err != nil {
+		t.Fatalf("failed to read output dir: %v", err)
+	}
+
+	widths := make(map[string]int)
+	for _, entry := range entries {
+		path := filepath.Join(outputDir, entry.Name())
+
+		pageCount, err := enhancer.GetPageCount(path)
+		if err != nil {
+			t.Fatalf("GetPageCount(%s) error = %v", entry.Name(), err)
+		}
+		if pageCount != 1 {
+			t.Errorf("%s has %d pages, want 1", entry.Name(), pageCount)
+		}
+
+		info, err := enhancer.ExtractPageInfo(path, 1)
+		if err != nil {
+			t.Fatalf("ExtractPageInfo(%s) error = %v", entry.Name(), err)
+		}
+		widths[entry.Name()] = info.Width
+	}
+
+	return widths
+}
+
+func TestPDFEnhancer_SplitPDF_ConcurrentMatchesSerial(t *testing.T) {
+	const pageCount = 7
+
+	tmpDir := t.TempDir()
+	inputPath := filepath.Join(tmpDir, "input.pdf")
+	createMultiPageTestPDF(t, inputPath, pageCount)
+
+	serial := splitPageWidths(t, inputPath, filepath.Join(tmpDir, "serial"), 1)
+	concurrent := splitPageWidths(t, inputPath, filepath.Join(tmpDir, "concurrent"), 3)
+
+	if len(serial) != pageCount {
+		t.Fatalf("serial split produced %d files, want %d", len(serial), pageCount)
+	}
+	if len(concurrent) != len(serial) {
+		t.Fatalf("concurrent split produced %d files, serial produced %d", len(concurrent), len(serial))
+	}
+
+	for i := 1; i <= pageCount; i++ {
+		name := fmt.Sprintf("input_page_%d.pdf", i)
+		if serial[name] != 200+i {
+			t.Errorf("serial %s width = %d, want %d", name, serial[name], 200+i)
+		}
+		if concurrent[name] != serial[name] {
+			t.Errorf("concurrent %s width = %d, serial width = %d", name, concurrent[name], serial[name])
+		}
+	}
+}
+
+func TestSplitPageRanges(t *testing.T) {
+	tests := []struct {
+		name      string
+		pageCount int
+		workers   int
+		want      [][]string
+	}{
+		{name: "no pages", pageCount: 0, workers: 4, want: nil},
+		{name: "serial", pageCount: 3, workers: 1, want: [][]string{{"1", "2", "3"}}},
+		{name: "uneven", pageCount: 5, workers: 2, want: [][]string{{"1", "2", "3"}, {"4", "5"}}},
+		{name: "more workers than pages", pageCount: 2, workers: 8, want: [][]string{{"1"}, {"2"}}},
+		{name: "invalid workers", pageCount: 2, workers: 0, want: [][]string{{"1", "2"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := splitPageRanges(tt.pageCount, tt.workers)
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("splitPageRanges(%d, %d) = %v, want %v", tt.pageCount, tt.workers, got, tt.want)
+			}
+		})
+	}
+}
+
+func BenchmarkSplitPDF(b *testing.B) {
+	inputPath := filepath.Join(b.TempDir(), "input.pdf")
+	createMultiPageTestPDF(b, inputPath, 200)
+
+	for _, concurrency := range []int{1, 4, runtime.NumCPU()} {
+		b.Run(fmt.Sprintf("concurrency=%d", concurrency), func(b *testing.B) {
+			enhancer := New(&Config{Logger: logger.Get()})
+			enhancer.splitConcurrency = concurrency
+			for i := 0; i < b.N; i++ {
+				outputDir := filepath.Join(b.TempDir(), "split")
+				if err := enhancer.SplitPDF(inputPath, outputDir); err != nil {
+					b.Fatalf("SplitPDF() error = %v", err)
+				}
+			}
+		})
+	}
+}
+
 func TestPDFEnhancer_CompareCoordinateSystems(t *testing.T) {
 	enhancer := New(&Config{})
 
@@ -402,9 +652,7 @@ func contains(s, substr string) bool {
 	return len(s) > 0 && len(substr) > 0 && (s == substr || len(s) >= len(substr) && (s[:len(substr)] == substr || contains(s[1:], substr)))
 }
 
-func TestPDFEnhancer_EscapePDFString(t *testing.T) {
-	enhancer := New(&Config{})
-
+func TestEscapePDFBytes(t *testing.T) {
 	tests := []struct {
 		name  string
 		input string
@@ -435,13 +683,18 @@ func TestPDFEnhancer_EscapePDFString(t *testing.T) {
 			input: "line1\nline2\ttab",
 			want:  "line1\\nline2\\ttab",
 		},
+		{
+			name:  "bytes outside ASCII",
+			input: "caf\xe9\x80",
+			want:  "caf\\351\\200",
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := enhancer.escapePDFString(tt.input)
+			got := escapePDFBytes([]byte(tt.input))
 			if got != tt.want {
-				t.Errorf("escapePDFString() = %q, want %q", got, tt.want)
+				t.Errorf("escapePDFBytes() = %q, want %q", got, tt.want)
 			}
 		})
 	}
@@ -455,7 +708,7 @@ func TestPDFEnhancer_CreateTextContentStream(t *testing.T) {
 	pageOCR.AddWord(ocr.NewWord("Hello", ocr.NewRectangle(100, 100, 50, 20), 95.0))
 	pageOCR.AddWord(ocr.NewWord("World", ocr.NewRectangle(160, 100, 50, 20), 92.0))
 
-	stream, err := enhancer.createTextContentStream(pageOCR, 612.0, 792.0)
+	stream, err := enhancer.createTextContentStream(pageOCR, 612.0, 792.0, false, newTextLayerFonts())
 	if err != nil {
 		t.Fatalf("createTextContentStream() error = %v", err)
 	}
@@ -492,7 +745,7 @@ func TestPDFEnhancer_CreateTextContentStream_EmptyWords(t *testing.T) {
 	pageOCR.AddWord(ocr.NewWord("   ", ocr.NewRectangle(160, 100, 50, 20), 92.0))
 	pageOCR.AddWord(ocr.NewWord("Valid", ocr.NewRectangle(220, 100, 50, 20), 90.0))
 
-	stream, err := enhancer.createTextContentStream(pageOCR, 612.0, 792.0)
+	stream, err := enhancer.createTextContentStream(pageOCR, 612.0, 792.0, false, newTextLayerFonts())
 	if err != nil {
 		t.Fatalf("createTextContentStream() error = %v", err)
 	}
@@ -533,7 +786,7 @@ func TestPDFEnhancer_CreateTextContentStream_CoordinateConversion(t *testing.T)
 	pageOCR := ocr.NewPageOCR(1, int(pageWidth), int(pageHeight), "eng")
 	pageOCR.AddWord(ocr.NewWord("Test", ocr.NewRectangle(ocrX, ocrY, 50, ocrHeight), 95.0))
 
-	stream, err := enhancer.createTextContentStream(pageOCR, pageWidth, pageHeight)
+	stream, err := enhancer.createTextContentStream(pageOCR, pageWidth, pageHeight, false, newTextLayerFonts())
 	if err != nil {
 		t.Fatalf("createTextContentStream() error = %v", err)
 	}
@@ -653,3 +906,49 @@ func TestPDFEnhancer_AddTextLayer_NoOCRWords(t *testing.T) {
 		t.Errorf("output PDF should be valid: %v", err)
 	}
 }
+
+func TestPDFEnhancer_WriteTranscript(t *testing.T) {
+	tmpDir := t.TempDir()
+	inputPath := filepath.Join(tmpDir, "input.pdf")
+	outputPath := filepath.Join(tmpDir, "transcript.pdf")
+
+	createTestPDF(t, inputPath, 1)
+
+	ocrResults := ocr.NewDocumentOCR("test-doc", "eng")
+	page := ocr.NewPageOCR(1, 612, 792, "eng")
+	page.AddWord(ocr.NewWord("typed", ocr.NewRectangle(100, 100, 60, 20), 95.0))
+	page.AddWord(ocr.NewWord("up", ocr.NewRectangle(170, 100, 30, 20), 95.0))
+	ocrResults.AddPage(*page)
+	ocrResults.Finalize()
+
+	enhancer := New(&Config{})
+	if err := enhancer.WriteTranscript(inputPath, outputPath, ocrResults); err != nil {
+		t.Fatalf("WriteTranscript() error = %v", err)
+	}
+
+	contentDir := t.TempDir()
+	if err := api.ExtractContentFile(outputPath, contentDir, nil, nil); err != nil {
+		t.Fatalf("ExtractContentFile() error = %v", err)
+	}
+	files, _ := filepath.Glob(filepath.Join(contentDir, "*"))
+	if len(files) != 1 {
+		t.Fatalf("got %d content files, want 1", len(files))
+	}
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(data)
+
+	// The words are drawn visibly, and the original page content is gone
+	for _, want := range []string{"0 Tr", "(typed) Tj", "(up) Tj"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("transcript content should contain %q:\n%s", want, content)
+		}
+	}
+	for _, unwanted := range []string{"3 Tr", "(Test PDF) Tj"} {
+		if strings.Contains(content, unwanted) {
+			t.Errorf("transcript content should not contain %q:\n%s", unwanted, content)
+		}
+	}
+}
@@ -0,0 +1,463 @@
+package pdfenhancer
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// tjSpaceThreshold is the TJ adjustment, in thousandths of a text space unit, at or
+// beyond which the gap between two strings is read as a space between words
+const tjSpaceThreshold = -200
+
+// ExtractText returns the text shown on each page of a PDF, such as the text layer
+// added by AddTextLayer
+//
+// Strings shown by separate text operators are joined with spaces. Pages without text
+// are empty strings. Strings are decoded with their font's ToUnicode CMap, or as
+// WinAnsiEncoding for fonts using it; other strings are read as UTF-8, or as Latin-1
+// where they aren't valid UTF-8.
+func (pe *PDFEnhancer) ExtractText(pdfPath string) ([]string, error) {
+	pe.logger.WithFields("pdf_path", pdfPath).Debug("Extracting text")
+
+	ctx, err := api.ReadContextFile(pdfPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PDF: %w", err)
+	}
+
+	pages := make([]string, ctx.PageCount)
+	for i := range pages {
+		r, err := pdfcpu.ExtractPageContent(ctx, i+1)
+		if err != nil {
+			return nil, fmt.Errorf("failed to extract content of page %d: %w", i+1, err)
+		}
+		content, err := io.ReadAll(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read content of page %d: %w", i+1, err)
+		}
+		fonts, err := pageTextDecoders(ctx, i+1)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read fonts of page %d: %w", i+1, err)
+		}
+		pages[i] = contentText(content, fonts)
+	}
+	return pages, nil
+}
+
+// textDecoder decodes the bytes of a string shown with a font
+type textDecoder func([]byte) string
+
+// pageTextDecoders returns the decoders of the fonts of a page that don't show UTF-8
+// or Latin-1 strings, keyed by resource name
+func pageTextDecoders(ctx *model.Context, pageNr int) (map[string]textDecoder, error) {
+	fonts, err := pageFonts(ctx, pageNr)
+	if err != nil || fonts == nil {
+		return nil, err
+	}
+
+	decoders := make(map[string]textDecoder)
+	for name, obj := range fonts {
+		font, err := ctx.DereferenceDict(obj)
+		if err != nil || font == nil {
+			continue
+		}
+		if decode := fontTextDecoder(ctx, font); decode != nil {
+			decoders[name] = decode
+		}
+	}
+	return decoders, nil
+}
+
+// pageFonts returns the font dictionary of a page's resources, which may be inherited,
+// or nil if the page has none
+func pageFonts(ctx *model.Context, pageNr int) (types.Dict, error) {
+	pageDict, _, inherited, err := ctx.PageDict(pageNr, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var resources types.Dict
+	if obj, found := pageDict.Find("Resources"); found {
+		if resources, err = ctx.DereferenceDict(obj); err != nil {
+			return nil, err
+		}
+	}
+	if resources == nil && inherited != nil {
+		resources = inherited.Resources
+	}
+	if resources == nil {
+		return nil, nil
+	}
+	fontsObj, found := resources.Find("Font")
+	if !found {
+		return nil, nil
+	}
+	return ctx.DereferenceDict(fontsObj)
+}
+
+// fontTextDecoder returns the decoder of a font's ToUnicode CMap, or of WinAnsiEncoding
+// for fonts using it, or nil if the font has neither or its CMap can't be read
+func fontTextDecoder(ctx *model.Context, font types.Dict) textDecoder {
+	if toUnicode, found := font.Find("ToUnicode"); found {
+		sd, _, err := ctx.DereferenceStreamDict(toUnicode)
+		if err != nil || sd == nil {
+			return nil
+		}
+		if err := sd.Decode(); err != nil {
+			return nil
+		}
+		return parseToUnicode(sd.Content).decode
+	}
+	if encoding := font.NameEntry("Encoding"); encoding != nil && *encoding == "WinAnsiEncoding" {
+		return decodeWinAnsi
+	}
+	return nil
+}
+
+// contentText returns the strings shown by the text operators (Tj, TJ, ', and ") of a
+// page content stream, joined with spaces, decoded with the decoders of their fonts
+func contentText(content []byte, fonts map[string]textDecoder) string {
+	e := &textExtractor{scanner: &contentScanner{data: content}, fonts: fonts, decode: decodePDFText}
+	for {
+		token, value, ok := e.scanner.next()
+		if !ok {
+			break
+		}
+		if token == tokenOperator {
+			e.operator(string(value))
+		} else {
+			e.operand(token, value)
+		}
+	}
+
+	var nonEmpty []string
+	for _, text := range e.shown {
+		if strings.TrimSpace(text) != "" {
+			nonEmpty = append(nonEmpty, text)
+		}
+	}
+	return strings.Join(nonEmpty, " ")
+}
+
+// textExtractor collects the strings shown by the text operators of a content stream
+type textExtractor struct {
+	scanner  *contentScanner
+	fonts    map[string]textDecoder
+	decode   textDecoder // decoder of the current font
+	shown    []string
+	operands []any // []byte strings, float64 numbers, []any arrays, string names, nil otherwise
+	array    []any
+	inArray  bool
+}
+
+// operand records an operand token for the next operator
+func (e *textExtractor) operand(token contentToken, value []byte) {
+	var operand any
+	switch token {
+	case tokenString:
+		operand = value
+	case tokenNumber:
+		operand, _ = strconv.ParseFloat(string(value), 64)
+	case tokenName:
+		operand = string(value)
+	case tokenArrayStart:
+		e.inArray, e.array = true, nil
+		return
+	case tokenArrayEnd:
+		e.inArray = false
+		e.operands = append(e.operands, e.array)
+		return
+	}
+
+	if e.inArray {
+		e.array = append(e.array, operand)
+	} else {
+		e.operands = append(e.operands, operand)
+	}
+}
+
+// operator applies an operator to the operands recorded since the previous one
+func (e *textExtractor) operator(op string) {
+	defer func() { e.operands = e.operands[:0] }()
+
+	var last any
+	if len(e.operands) > 0 {
+		last = e.operands[len(e.operands)-1]
+	}
+	switch op {
+	case "Tf":
+		e.decode = decodePDFText
+		if len(e.operands) == 2 {
+			if name, ok := e.operands[0].(string); ok && e.fonts[name] != nil {
+				e.decode = e.fonts[name]
+			}
+		}
+	case "Tj", "'", "\"":
+		if str, ok := last.([]byte); ok {
+			e.shown = append(e.shown, e.decode(str))
+		}
+	case "TJ":
+		if elements, ok := last.([]any); ok {
+			e.shown = append(e.shown, tjText(elements, e.decode))
+		}
+	case "ID":
+		e.scanner.skipInlineImage()
+	}
+}
+
+// tjText returns the text of a TJ array, reading large negative adjustments as spaces
+func tjText(elements []any, decode textDecoder) string {
+	var sb strings.Builder
+	for _, element := range elements {
+		switch e := element.(type) {
+		case []byte:
+			sb.WriteString(decode(e))
+		case float64:
+			if e <= tjSpaceThreshold && sb.Len() > 0 {
+				sb.WriteByte(' ')
+			}
+		}
+	}
+	return sb.String()
+}
+
+// decodePDFText decodes the bytes of a shown string, as UTF-8 if they are valid UTF-8
+// and as Latin-1 otherwise
+func decodePDFText(b []byte) string {
+	if utf8.Valid(b) {
+		return string(b)
+	}
+	runes := make([]rune, len(b))
+	for i, c := range b {
+		runes[i] = rune(c)
+	}
+	return string(runes)
+}
+
+// contentToken is the kind of a token in a content stream
+type contentToken int
+
+const (
+	tokenString     contentToken = iota // literal or hex string, value is its bytes
+	tokenNumber                         // integer or real number
+	tokenOperator                       // operator such as Tj
+	tokenArrayStart                     // [
+	tokenArrayEnd                       // ]
+	tokenName                           // name, value is the name without its slash
+	tokenOther                          // dictionary delimiter or other operand
+)
+
+// contentScanner splits a content stream into tokens
+type contentScanner struct {
+	data []byte
+	pos  int
+}
+
+// isPDFWhitespace reports whether c is a PDF whitespace character
+func isPDFWhitespace(c byte) bool {
+	return c == ' ' || c == '\n' || c == '\r' || c == '\t' || c == '\f' || c == 0
+}
+
+// isPDFDelimiter reports whether c is a PDF delimiter character
+func isPDFDelimiter(c byte) bool {
+	return strings.IndexByte("()<>[]{}/%", c) >= 0
+}
+
+// next returns the next token and its value, or false at the end of the stream
+func (s *contentScanner) next() (contentToken, []byte, bool) {
+	for s.pos < len(s.data) {
+		c := s.data[s.pos]
+		switch {
+		case isPDFWhitespace(c):
+			s.pos++
+		case c == '%':
+			s.skipComment()
+		case isPDFDelimiter(c):
+			token, value := s.delimited()
+			return token, value, true
+		default:
+			word := s.regular()
+			if word[0] == '+' || word[0] == '-' || word[0] == '.' || (word[0] >= '0' && word[0] <= '9') {
+				return tokenNumber, word, true
+			}
+			return tokenOperator, word, true
+		}
+	}
+	return 0, nil, false
+}
+
+// skipComment skips a comment up to the end of its line
+func (s *contentScanner) skipComment() {
+	for s.pos < len(s.data) && s.data[s.pos] != '\n' && s.data[s.pos] != '\r' {
+		s.pos++
+	}
+}
+
+// delimited reads the token starting with the delimiter character at the current
+// position, other than a comment
+func (s *contentScanner) delimited() (contentToken, []byte) {
+	c := s.data[s.pos]
+	s.pos++
+	switch c {
+	case '(':
+		return tokenString, s.literalString()
+	case '<':
+		if s.pos < len(s.data) && s.data[s.pos] == '<' {
+			s.pos++
+			return tokenOther, nil
+		}
+		return tokenString, s.hexString()
+	case '>':
+		if s.pos < len(s.data) && s.data[s.pos] == '>' {
+			s.pos++
+		}
+		return tokenOther, nil
+	case '[':
+		return tokenArrayStart, nil
+	case ']':
+		return tokenArrayEnd, nil
+	case '/':
+		return tokenName, s.name()
+	}
+	// Dictionary braces and unbalanced closing parentheses
+	return tokenOther, nil
+}
+
+// regular reads a run of regular (non-whitespace, non-delimiter) characters
+func (s *contentScanner) regular() []byte {
+	start := s.pos
+	for s.pos < len(s.data) && !isPDFWhitespace(s.data[s.pos]) && !isPDFDelimiter(s.data[s.pos]) {
+		s.pos++
+	}
+	if s.pos == start {
+		s.pos++ // never stall on an unexpected character
+	}
+	return s.data[start:s.pos]
+}
+
+// name reads the characters of a name after its slash, which may be none
+func (s *contentScanner) name() []byte {
+	start := s.pos
+	for s.pos < len(s.data) && !isPDFWhitespace(s.data[s.pos]) && !isPDFDelimiter(s.data[s.pos]) {
+		s.pos++
+	}
+	return s.data[start:s.pos]
+}
+
+// literalString reads a literal string after its opening parenthesis, decoding escapes
+func (s *contentScanner) literalString() []byte {
+	var out []byte
+	depth := 1
+	for s.pos < len(s.data) {
+		c := s.data[s.pos]
+		s.pos++
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return out
+			}
+		case '\\':
+			if s.pos >= len(s.data) {
+				return out
+			}
+			var ok bool
+			if c, ok = s.escape(); !ok {
+				continue
+			}
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+// literalEscapes maps the characters of the named escapes of literal strings to the
+// characters they stand for
+var literalEscapes = map[byte]byte{'n': '\n', 'r': '\r', 't': '\t', 'b': '\b', 'f': '\f'}
+
+// escape reads an escape sequence of a literal string after its backslash, returning
+// the character it stands for, or false for a line continuation
+func (s *contentScanner) escape() (byte, bool) {
+	e := s.data[s.pos]
+	s.pos++
+	if c, ok := literalEscapes[e]; ok {
+		return c, true
+	}
+	switch {
+	case e == '\r':
+		// A line continuation, also swallowing the \n of \r\n
+		if s.pos < len(s.data) && s.data[s.pos] == '\n' {
+			s.pos++
+		}
+		return 0, false
+	case e == '\n':
+		return 0, false
+	case isOctalDigit(e):
+		n := int(e - '0')
+		for i := 0; i < 2 && s.pos < len(s.data) && isOctalDigit(s.data[s.pos]); i++ {
+			n = n*8 + int(s.data[s.pos]-'0')
+			s.pos++
+		}
+		return byte(n), true
+	}
+	return e, true // \\, \(, \), and unknown escapes are the character itself
+}
+
+// isOctalDigit reports whether c is an octal digit
+func isOctalDigit(c byte) bool {
+	return c >= '0' && c <= '7'
+}
+
+// hexString reads a hex string after its opening angle bracket
+func (s *contentScanner) hexString() []byte {
+	var digits []byte
+	for s.pos < len(s.data) && s.data[s.pos] != '>' {
+		if c := s.data[s.pos]; !isPDFWhitespace(c) {
+			digits = append(digits, c)
+		}
+		s.pos++
+	}
+	s.pos++ // the closing >
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+
+	out := make([]byte, 0, len(digits)/2)
+	for i := 0; i < len(digits); i += 2 {
+		b, err := strconv.ParseUint(string(digits[i:i+2]), 16, 8)
+		if err != nil {
+			continue
+		}
+		out = append(out, byte(b))
+	}
+	return out
+}
+
+// skipInlineImage skips the binary data of an inline image after its ID operator, up
+// to and including the EI operator that ends it
+func (s *contentScanner) skipInlineImage() {
+	for s.pos+2 <= len(s.data) {
+		i := bytes.Index(s.data[s.pos:], []byte("EI"))
+		if i < 0 {
+			break
+		}
+		end := s.pos + i
+		s.pos = end + 2
+		before := end == 0 || isPDFWhitespace(s.data[end-1])
+		after := s.pos == len(s.data) || isPDFWhitespace(s.data[s.pos])
+		if before && after {
+			return
+		}
+	}
+	s.pos = len(s.data)
+}
@@ -0,0 +1,180 @@
+package pdfenhancer
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/platinummonkey/legible/internal/ocr"
+)
+
+func TestPDFEnhancer_ExtractText_TextLayer(t *testing.T) {
+	tmpDir := t.TempDir()
+	inputPath := filepath.Join(tmpDir, "input.pdf")
+	outputPath := filepath.Join(tmpDir, "output.pdf")
+	createSizedPDF(t, inputPath, 400, 400, 400)
+
+	// Pages 1 and 3 get words, page 2 has none
+	pageWords := [][]string{{"hello", "world"}, nil, {"café", "(notes)"}}
+	ocrResults := ocr.NewDocumentOCR("test-doc", "eng")
+	for i, words := range pageWords {
+		page := ocr.NewPageOCR(i+1, 400, 500, "eng")
+		for j, word := range words {
+			page.AddWord(ocr.NewWord(word, ocr.NewRectangle(20+j*100, 50, 80, 20), 95.0))
+		}
+		page.BuildText()
+		ocrResults.AddPage(*page)
+	}
+
+	enhancer := New(&Config{})
+	if err := enhancer.AddTextLayer(inputPath, outputPath, ocrResults); err != nil {
+		t.Fatalf("AddTextLayer() error = %v", err)
+	}
+
+	pages, err := enhancer.ExtractText(outputPath)
+	if err != nil {
+		t.Fatalf("ExtractText() error = %v", err)
+	}
+	if len(pages) != len(pageWords) {
+		t.Fatalf("ExtractText() returned %d pages, want %d", len(pages), len(pageWords))
+	}
+	for i, words := range pageWords {
+		if len(words) == 0 && pages[i] != "" {
+			t.Errorf("page %d text = %q, want none", i+1, pages[i])
+		}
+		for _, word := range words {
+			if !strings.Contains(pages[i], word) {
+				t.Errorf("page %d text = %q, want it to contain %q", i+1, pages[i], word)
+			}
+		}
+	}
+}
+
+func TestPDFEnhancer_ExtractText_InvalidFile(t *testing.T) {
+	enhancer := New(&Config{})
+	if _, err := enhancer.ExtractText(filepath.Join(t.TempDir(), "missing.pdf")); err == nil {
+		t.Error("ExtractText() should error for a missing file")
+	}
+}
+
+func TestContentText(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"Tj", "BT /F1 12 Tf 10 10 Td (Hello) Tj ET", "Hello"},
+		{"words", "BT (one) Tj (two) Tj ET", "one two"},
+		{"escapes", `BT (a \(b\) c\\d) Tj ET`, `a (b) c\d`},
+		{"nested parentheses", "BT (f(x)) Tj ET", "f(x)"},
+		{"octal escape", `BT (caf\351) Tj ET`, "café"},
+		{"hex string", "BT <48656C6C6F> Tj ET", "Hello"},
+		{"TJ kerning and spaces", "BT [(Hel) 20 (lo) -500 (there)] TJ ET", "Hello there"},
+		{"quote operators", "BT (first) ' 1 2 (second) \" ET", "first second"},
+		{"no text", "q 1 0 0 1 0 0 cm 0 0 10 10 re f Q", ""},
+		{"dictionary operands", "/P <</MCID 0>> BDC BT (marked) Tj ET EMC", "marked"},
+		{"inline image", "BI /W 1 /H 1 /BPC 8 /CS /G ID \x00(Tj)\xff EI BT (after) Tj ET", "after"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := contentText([]byte(tt.content), nil); got != tt.want {
+				t.Errorf("contentText(%q) = %q, want %q", tt.content, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPDFEnhancer_ExtractText_UnicodeRoundTrip(t *testing.T) {
+	pageWords := [][]string{
+		{"café", "naïve", "Größe", "“quoted”", "€5", "Œuvre"},
+		{"λόγος", "привет", "日本語", "中文", "한국어"},
+		{"mixed", "déjà", "東京", "𝄞", "😀"},
+	}
+
+	for _, transcript := range []bool{false, true} {
+		tmpDir := t.TempDir()
+		inputPath := filepath.Join(tmpDir, "input.pdf")
+		outputPath := filepath.Join(tmpDir, "output.pdf")
+		createSizedPDF(t, inputPath, 400, 400, 400)
+
+		ocrResults := ocr.NewDocumentOCR("test-doc", "und")
+		for i, words := range pageWords {
+			page := ocr.NewPageOCR(i+1, 400, 500, "und")
+			for j, word := range words {
+				page.AddWord(ocr.NewWord(word, ocr.NewRectangle(10+j*60, 50, 55, 20), 95.0))
+			}
+			ocrResults.AddPage(*page)
+		}
+
+		enhancer := New(&Config{})
+		write := enhancer.AddTextLayer
+		if transcript {
+			write = enhancer.WriteTranscript
+		}
+		if err := write(inputPath, outputPath, ocrResults); err != nil {
+			t.Fatalf("transcript %t: writing the text layer error = %v", transcript, err)
+		}
+		if err := enhancer.ValidatePDF(outputPath); err != nil {
+			t.Errorf("transcript %t: output PDF should be valid: %v", transcript, err)
+		}
+
+		pages, err := enhancer.ExtractText(outputPath)
+		if err != nil {
+			t.Fatalf("transcript %t: ExtractText() error = %v", transcript, err)
+		}
+		for i, words := range pageWords {
+			if want := strings.Join(words, " "); pages[i] != want {
+				t.Errorf("transcript %t: page %d text = %q, want %q", transcript, i+1, pages[i], want)
+			}
+		}
+	}
+}
+
+func TestWinAnsiEncoding(t *testing.T) {
+	encoded, ok := encodeWinAnsi("café “€” Ÿ")
+	if !ok {
+		t.Fatal("encodeWinAnsi() can't encode Latin text")
+	}
+	if want := []byte("caf\xe9 \x93\x80\x94 \x9f"); string(encoded) != string(want) {
+		t.Errorf("encodeWinAnsi() = %q, want %q", encoded, want)
+	}
+	if got := decodeWinAnsi(encoded); got != "café “€” Ÿ" {
+		t.Errorf("decodeWinAnsi() = %q, want the original text", got)
+	}
+
+	for _, s := range []string{"λόγος", "日本", "x\u0081"} {
+		if _, ok := encodeWinAnsi(s); ok {
+			t.Errorf("encodeWinAnsi(%q) should report characters WinAnsiEncoding lacks", s)
+		}
+	}
+}
+
+func TestParseToUnicode(t *testing.T) {
+	cmap := `/CIDInit /ProcSet findresource begin
+12 dict begin
+begincmap
+1 begincodespacerange
+<0000> <FFFF>
+endcodespacerange
+2 beginbfchar
+<0001> <0041>
+<0002> <D834DD1E>
+endbfchar
+2 beginbfrange
+<0010> <0012> <0061>
+<0020> <0021> [<65E5> <672C>]
+endbfrange
+endcmap
+end
+end`
+
+	m := parseToUnicode([]byte(cmap))
+	if m.codeBytes != 2 {
+		t.Errorf("codeBytes = %d, want 2", m.codeBytes)
+	}
+	got := m.decode([]byte{0x00, 0x01, 0x00, 0x02, 0x00, 0x10, 0x00, 0x12, 0x00, 0x20, 0x00, 0x21})
+	if want := "A𝄞ac日本"; got != want {
+		t.Errorf("decode() = %q, want %q", got, want)
+	}
+}
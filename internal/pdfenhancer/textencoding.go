@@ -0,0 +1,398 @@
+package pdfenhancer
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+const (
+	// helveticaFontName is the resource name of the Helvetica font showing the words
+	// WinAnsiEncoding can encode
+	helveticaFontName = "Helvetica"
+
+	// unicodeFontName is the resource name, and base font name, of the Type0 font
+	// showing the words WinAnsiEncoding can't encode, such as Greek or CJK words
+	unicodeFontName = "LegibleUnicode"
+
+	// unicodeFontWidth is the width of every glyph of the Unicode font, in thousandths
+	// of the font size, matching the width the text layer assumes when scaling words
+	unicodeFontWidth = 500
+
+	// cmapBlockSize is the most entries a bfchar block of a CMap may hold
+	cmapBlockSize = 100
+)
+
+// winAnsiSpecials are the characters WinAnsiEncoding encodes in 0x80-0x9F, where
+// Latin-1 has control characters; the other codes are the same as Latin-1
+var winAnsiSpecials = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87, 'ˆ': 0x88,
+	'‰': 0x89, 'Š': 0x8A, '‹': 0x8B, 'Œ': 0x8C, 'Ž': 0x8E, '‘': 0x91, '’': 0x92, '“': 0x93,
+	'”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '˜': 0x98, '™': 0x99, 'š': 0x9A, '›': 0x9B,
+	'œ': 0x9C, 'ž': 0x9E, 'Ÿ': 0x9F,
+}
+
+// winAnsiDecoding maps the codes of winAnsiSpecials back to their characters
+var winAnsiDecoding = func() map[byte]rune {
+	m := make(map[byte]rune, len(winAnsiSpecials))
+	for r, b := range winAnsiSpecials {
+		m[b] = r
+	}
+	return m
+}()
+
+// encodeWinAnsi encodes s in WinAnsiEncoding, reporting false if a character of s
+// isn't in it
+func encodeWinAnsi(s string) ([]byte, bool) {
+	encoded := make([]byte, 0, len(s))
+	for _, r := range s {
+		switch {
+		case r < 0x80 || (r >= 0xA0 && r <= 0xFF):
+			encoded = append(encoded, byte(r))
+		case winAnsiSpecials[r] != 0:
+			encoded = append(encoded, winAnsiSpecials[r])
+		default:
+			return nil, false
+		}
+	}
+	return encoded, true
+}
+
+// decodeWinAnsi decodes text encoded in WinAnsiEncoding
+func decodeWinAnsi(b []byte) string {
+	runes := make([]rune, len(b))
+	for i, c := range b {
+		if r, ok := winAnsiDecoding[c]; ok {
+			runes[i] = r
+		} else {
+			runes[i] = rune(c)
+		}
+	}
+	return string(runes)
+}
+
+// textLayerFonts encodes the words of a text layer for the fonts that show them, and
+// adds those fonts to the PDF
+//
+// Words WinAnsiEncoding can encode are shown with Helvetica. Other words are shown with
+// a Type0 font shared by every page of the document, whose character IDs are assigned
+// to characters as they are first used and mapped back to Unicode by its ToUnicode
+// CMap, so the text can be searched and extracted. Its glyphs aren't embedded: the text
+// layer is invisible, and transcripts show these words with the viewer's fallback font.
+type textLayerFonts struct {
+	cids  map[rune]uint16
+	runes []rune // the character of each character ID, from 1
+
+	helveticaRef *types.IndirectRef
+	unicodeFont  types.Dict // the Type0 font dictionary, once a page uses it
+	unicodeRef   *types.IndirectRef
+}
+
+// newTextLayerFonts creates the fonts of a document's text layer
+func newTextLayerFonts() *textLayerFonts {
+	return &textLayerFonts{cids: make(map[rune]uint16)}
+}
+
+// encode returns the font a word is shown with and the PDF string that shows it
+func (tf *textLayerFonts) encode(word string) (font, str string, err error) {
+	if encoded, ok := encodeWinAnsi(word); ok {
+		return helveticaFontName, "(" + escapePDFBytes(encoded) + ")", nil
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('<')
+	for _, r := range word {
+		cid, ok := tf.cids[r]
+		if !ok {
+			if len(tf.runes) >= 0xFFFF {
+				return "", "", fmt.Errorf("text layer uses more than %d distinct characters", 0xFFFF)
+			}
+			tf.runes = append(tf.runes, r)
+			cid = uint16(len(tf.runes))
+			tf.cids[r] = cid
+		}
+		fmt.Fprintf(&buf, "%04X", cid)
+	}
+	buf.WriteByte('>')
+	return unicodeFontName, buf.String(), nil
+}
+
+// helveticaFontRef returns the Helvetica font shared by the pages, adding it to the PDF
+// on first use
+//
+// Fonts are added as indirect objects: pdfcpu doesn't write font dictionaries nested in
+// a page's resources.
+func (tf *textLayerFonts) helveticaFontRef(ctx *model.Context) (*types.IndirectRef, error) {
+	if tf.helveticaRef != nil {
+		return tf.helveticaRef, nil
+	}
+
+	// Helvetica is a Type 1 standard font, reading text as WinAnsiEncoding
+	helvetica := types.Dict{
+		"Type":     types.Name("Font"),
+		"Subtype":  types.Name("Type1"),
+		"BaseFont": types.Name("Helvetica"),
+		"Encoding": types.Name("WinAnsiEncoding"),
+	}
+	ref, err := ctx.IndRefForNewObject(helvetica)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add Helvetica font: %w", err)
+	}
+	tf.helveticaRef = ref
+	return ref, nil
+}
+
+// unicodeFontRef returns the Type0 font shared by the pages, adding it to the PDF on
+// first use; its ToUnicode CMap is added by finish
+func (tf *textLayerFonts) unicodeFontRef(ctx *model.Context) (*types.IndirectRef, error) {
+	if tf.unicodeRef != nil {
+		return tf.unicodeRef, nil
+	}
+
+	descriptor := types.Dict{
+		"Type":        types.Name("FontDescriptor"),
+		"FontName":    types.Name(unicodeFontName),
+		"Flags":       types.Integer(32), // nonsymbolic
+		"FontBBox":    types.NewNumberArray(0, -200, 1000, 800),
+		"ItalicAngle": types.Integer(0),
+		"Ascent":      types.Integer(800),
+		"Descent":     types.Integer(-200),
+		"CapHeight":   types.Integer(700),
+		"StemV":       types.Integer(80),
+	}
+	descriptorRef, err := ctx.IndRefForNewObject(descriptor)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add font descriptor: %w", err)
+	}
+
+	cidFont := types.Dict{
+		"Type":     types.Name("Font"),
+		"Subtype":  types.Name("CIDFontType2"),
+		"BaseFont": types.Name(unicodeFontName),
+		"CIDSystemInfo": types.Dict{
+			"Registry":   types.StringLiteral("Adobe"),
+			"Ordering":   types.StringLiteral("Identity"),
+			"Supplement": types.Integer(0),
+		},
+		"FontDescriptor": *descriptorRef,
+		"DW":             types.Integer(unicodeFontWidth),
+		"CIDToGIDMap":    types.Name("Identity"),
+	}
+	cidFontRef, err := ctx.IndRefForNewObject(cidFont)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add CID font: %w", err)
+	}
+
+	tf.unicodeFont = types.Dict{
+		"Type":            types.Name("Font"),
+		"Subtype":         types.Name("Type0"),
+		"BaseFont":        types.Name(unicodeFontName),
+		"Encoding":        types.Name("Identity-H"),
+		"DescendantFonts": types.Array{*cidFontRef},
+	}
+	tf.unicodeRef, err = ctx.IndRefForNewObject(tf.unicodeFont)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add Unicode font: %w", err)
+	}
+	return tf.unicodeRef, nil
+}
+
+// finish adds the ToUnicode CMap of the characters the pages used to the Type0 font,
+// if any page used it
+func (tf *textLayerFonts) finish(ctx *model.Context) error {
+	if tf.unicodeFont == nil {
+		return nil
+	}
+	ref, err := newStreamObject(ctx, tf.toUnicodeCMap())
+	if err != nil {
+		return fmt.Errorf("failed to add ToUnicode CMap: %w", err)
+	}
+	tf.unicodeFont.Update("ToUnicode", *ref)
+	return nil
+}
+
+// toUnicodeCMap returns a CMap mapping each character ID to its character
+func (tf *textLayerFonts) toUnicodeCMap() []byte {
+	var buf bytes.Buffer
+	buf.WriteString("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n")
+	buf.WriteString("/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n")
+	buf.WriteString("/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n")
+	buf.WriteString("1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n")
+	for start := 0; start < len(tf.runes); start += cmapBlockSize {
+		block := tf.runes[start:min(start+cmapBlockSize, len(tf.runes))]
+		fmt.Fprintf(&buf, "%d beginbfchar\n", len(block))
+		for i, r := range block {
+			fmt.Fprintf(&buf, "<%04X> <", start+i+1)
+			for _, unit := range utf16.Encode([]rune{r}) {
+				fmt.Fprintf(&buf, "%04X", unit)
+			}
+			buf.WriteString(">\n")
+		}
+		buf.WriteString("endbfchar\n")
+	}
+	buf.WriteString("endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend\n")
+	return buf.Bytes()
+}
+
+// escapePDFBytes escapes bytes for a PDF string literal, writing the characters with
+// a special meaning as escapes and bytes outside printable ASCII as octal escapes
+func escapePDFBytes(b []byte) string {
+	var buf bytes.Buffer
+	for _, c := range b {
+		switch c {
+		case '\\', '(', ')':
+			buf.WriteByte('\\')
+			buf.WriteByte(c)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if c < 0x20 || c >= 0x7F {
+				fmt.Fprintf(&buf, `\%03o`, c)
+			} else {
+				buf.WriteByte(c)
+			}
+		}
+	}
+	return buf.String()
+}
+
+// toUnicodeMap maps character codes of a font to text, read from its ToUnicode CMap
+type toUnicodeMap struct {
+	codeBytes int               // bytes per character code
+	chars     map[uint32]string // text of each code
+}
+
+// parseToUnicode reads the code space and the bfchar and bfrange mappings of a
+// ToUnicode CMap
+func parseToUnicode(cmap []byte) *toUnicodeMap {
+	p := &toUnicodeParser{m: &toUnicodeMap{codeBytes: 1, chars: make(map[uint32]string)}}
+	s := &contentScanner{data: cmap}
+	for {
+		token, value, ok := s.next()
+		if !ok {
+			break
+		}
+
+		switch token {
+		case tokenString:
+			if p.inArray {
+				p.array = append(p.array, value)
+			} else {
+				p.operands = append(p.operands, value)
+				p.entry()
+			}
+		case tokenArrayStart:
+			p.inArray, p.array = true, nil
+		case tokenArrayEnd:
+			p.inArray = false
+			p.bfrangeArray()
+		case tokenOperator:
+			p.section = ""
+			if op := string(value); strings.HasPrefix(op, "begin") {
+				p.section = strings.TrimPrefix(op, "begin")
+			}
+			p.operands = p.operands[:0]
+		}
+	}
+	return p.m
+}
+
+// toUnicodeParser holds the state of parseToUnicode
+type toUnicodeParser struct {
+	m        *toUnicodeMap
+	section  string   // name of the current begin...end section
+	operands [][]byte // hex strings of the current entry
+	array    [][]byte // destinations of a bfrange entry mapping to an array
+	inArray  bool
+}
+
+// entry adds the mapping of the current entry of the section once all its strings are read
+func (p *toUnicodeParser) entry() {
+	switch {
+	case p.section == "codespacerange" && len(p.operands) == 2:
+		p.m.codeBytes = max(len(p.operands[0]), 1)
+	case p.section == "bfchar" && len(p.operands) == 2:
+		p.bfchar()
+	case p.section == "bfrange" && len(p.operands) == 3:
+		p.bfrange()
+	default:
+		return
+	}
+	p.operands = p.operands[:0]
+}
+
+// bfchar maps a single code to the text of its destination
+func (p *toUnicodeParser) bfchar() {
+	code, _ := cmapCode(p.operands[0])
+	p.m.chars[code] = utf16BEText(p.operands[1])
+}
+
+// bfrange maps a range of codes to consecutive text, starting with the destination
+func (p *toUnicodeParser) bfrange() {
+	first, _ := cmapCode(p.operands[0])
+	last, _ := cmapCode(p.operands[1])
+	dst := utf16BEText(p.operands[2])
+	for code := first; code <= last && code-first < 0x10000; code++ {
+		p.m.chars[code] = offsetLastRune(dst, int(code-first))
+	}
+}
+
+// bfrangeArray maps a range of codes to the destinations of an array, one per code
+func (p *toUnicodeParser) bfrangeArray() {
+	if p.section == "bfrange" && len(p.operands) == 2 {
+		first, _ := cmapCode(p.operands[0])
+		for i, dst := range p.array {
+			p.m.chars[first+uint32(i)] = utf16BEText(dst)
+		}
+	}
+	p.operands = p.operands[:0]
+}
+
+// offsetLastRune returns s with its last character advanced by n, as successive codes
+// of a bfrange map to successive characters
+func offsetLastRune(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) == 0 {
+		return s
+	}
+	runes[len(runes)-1] += rune(n)
+	return string(runes)
+}
+
+// cmapCode returns the character code of a CMap hex string and its length in bytes
+func cmapCode(b []byte) (uint32, int) {
+	var code uint32
+	for _, c := range b {
+		code = code<<8 | uint32(c)
+	}
+	return code, len(b)
+}
+
+// utf16BEText decodes the UTF-16BE destination of a CMap mapping
+func utf16BEText(b []byte) string {
+	units := make([]uint16, len(b)/2)
+	for i := range units {
+		units[i] = uint16(b[2*i])<<8 | uint16(b[2*i+1])
+	}
+	return string(utf16.Decode(units))
+}
+
+// decode returns the text of a string shown with the font
+func (m *toUnicodeMap) decode(b []byte) string {
+	var buf bytes.Buffer
+	for i := 0; i+m.codeBytes <= len(b); i += m.codeBytes {
+		code, _ := cmapCode(b[i : i+m.codeBytes])
+		if text, ok := m.chars[code]; ok {
+			buf.WriteString(text)
+		}
+	}
+	return buf.String()
+}
@@ -0,0 +1,175 @@
+// Package pdfrender renders PDF pages to raster images for OCR and previews.
+package pdfrender
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+
+	"github.com/platinummonkey/legible/internal/logger"
+	"github.com/unidoc/unipdf/v3/common"
+	unipdf "github.com/unidoc/unipdf/v3/model"
+	"github.com/unidoc/unipdf/v3/render"
+)
+
+// DefaultDPI is the rendering resolution used for OCR (good accuracy at reasonable size)
+const DefaultDPI = 300
+
+// init sets up unidoc licensing (metered mode for free usage)
+func init() {
+	// Use metered mode for free usage with rate limits
+	// For production, set a license key via: common.SetLicenseKey()
+	common.SetLogger(common.NewConsoleLogger(common.LogLevelError))
+}
+
+// Renderer renders PDF pages to images
+type Renderer struct {
+	logger *logger.Logger
+}
+
+// Config holds configuration for the renderer
+type Config struct {
+	Logger *logger.Logger
+}
+
+// New creates a new PDF page renderer
+func New(cfg *Config) *Renderer {
+	if cfg == nil {
+		cfg = &Config{}
+	}
+
+	log := cfg.Logger
+	if log == nil {
+		log = logger.Get()
+	}
+
+	return &Renderer{
+		logger: log,
+	}
+}
+
+// RenderPage renders a single PDF page (1-based) to an image at the specified DPI
+func (r *Renderer) RenderPage(pdfPath string, pageNum, dpi int) (image.Image, error) {
+	r.logger.WithFields("pdf", pdfPath, "page", pageNum, "dpi", dpi).Debug("Rendering PDF page to image")
+
+	f, pdfReader, err := openPDF(pdfPath)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	numPages, err := pdfReader.GetNumPages()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get page count: %w", err)
+	}
+
+	if pageNum < 1 || pageNum > numPages {
+		return nil, fmt.Errorf("invalid page number %d (PDF has %d pages)", pageNum, numPages)
+	}
+
+	return r.renderPage(pdfReader, pageNum, dpi)
+}
+
+// RenderAll renders every page of a PDF to images at the specified DPI
+//
+// The PDF is parsed once and ctx is checked between pages.
+func (r *Renderer) RenderAll(ctx context.Context, pdfPath string, dpi int) ([]image.Image, error) {
+	r.logger.WithFields("pdf", pdfPath, "dpi", dpi).Debug("Rendering all PDF pages to images")
+	return r.RenderAllWithDPI(ctx, pdfPath, func(int) int { return dpi })
+}
+
+// RenderAllWithDPI renders every page of a PDF, choosing each page's DPI with dpiForPage
+//
+// dpiForPage receives the 1-based page number.
+func (r *Renderer) RenderAllWithDPI(ctx context.Context, pdfPath string, dpiForPage func(page int) int) ([]image.Image, error) {
+
+	f, pdfReader, err := openPDF(pdfPath)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	pageCount, err := pdfReader.GetNumPages()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get page count: %w", err)
+	}
+
+	images := make([]image.Image, pageCount)
+	for i := 1; i <= pageCount; i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		dpi := dpiForPage(i)
+		img, err := r.renderPage(pdfReader, i, dpi)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render page %d: %w", i, err)
+		}
+		images[i-1] = img
+		r.logger.WithFields("page", i, "total", pageCount, "dpi", dpi).Debug("Rendered page")
+	}
+
+	r.logger.WithFields("page_count", pageCount).Info("Successfully rendered all pages")
+	return images, nil
+}
+
+// EncodePNG encodes an image as PNG bytes
+func EncodePNG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode image: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// openPDF opens a PDF file for lazy reading; the caller must close the returned file
+func openPDF(pdfPath string) (*os.File, *unipdf.PdfReader, error) {
+	f, err := os.Open(pdfPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open PDF: %w", err)
+	}
+
+	pdfReader, err := unipdf.NewPdfReaderLazy(f)
+	if err != nil {
+		_ = f.Close()
+		return nil, nil, fmt.Errorf("failed to create PDF reader: %w", err)
+	}
+
+	return f, pdfReader, nil
+}
+
+// renderPage renders a page from an open reader at the specified DPI
+func (r *Renderer) renderPage(pdfReader *unipdf.PdfReader, pageNum, dpi int) (image.Image, error) {
+	page, err := pdfReader.GetPage(pageNum)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get page %d: %w", pageNum, err)
+	}
+
+	// Calculate dimensions based on page size and DPI
+	// PDF points are 1/72 inch, so we convert to pixels at target DPI
+	mediaBox, err := page.GetMediaBox()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get media box: %w", err)
+	}
+
+	pageWidth := mediaBox.Urx - mediaBox.Llx
+
+	// Convert PDF points to pixels at target DPI
+	// pixels = points * DPI / 72
+	device := render.NewImageDevice()
+
+	// Set output width - height will be calculated automatically to maintain aspect ratio
+	device.OutputWidth = int(float64(pageWidth) * float64(dpi) / 72.0)
+
+	img, err := device.Render(page)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render page: %w", err)
+	}
+
+	bounds := img.Bounds()
+	r.logger.WithFields("page", pageNum, "width", bounds.Dx(), "height", bounds.Dy()).Debug("Successfully rendered page to image")
+	return img, nil
+}
@@ -0,0 +1,178 @@
+package pdfrender
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// createFixturePDF writes a PDF whose pages are 144x72 points with the left half filled black
+func createFixturePDF(t *testing.T, path string, pageCount int) {
+	t.Helper()
+
+	var buf strings.Builder
+	var offsets []int
+	writeObj := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	const content = "0 0 0 rg 0 0 72 72 re f"
+
+	buf.WriteString("%PDF-1.4\n")
+	writeObj("<< /Type /Catalog /Pages 3 0 R >>")
+	writeObj(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content))
+
+	kids := make([]string, pageCount)
+	for i := range kids {
+		kids[i] = fmt.Sprintf("%d 0 R", i+4)
+	}
+	writeObj(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), pageCount))
+	for i := 0; i < pageCount; i++ {
+		writeObj("<< /Type /Page /Parent 3 0 R /MediaBox [0 0 144 72] /Contents 2 0 R /Resources << >> >>")
+	}
+
+	xrefOffset := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xrefOffset)
+
+	if err := os.WriteFile(path, []byte(buf.String()), 0644); err != nil {
+		t.Fatalf("failed to write fixture PDF: %v", err)
+	}
+}
+
+func TestNew(t *testing.T) {
+	r := New(nil)
+	if r == nil {
+		t.Fatal("New() returned nil")
+	}
+	if r.logger == nil {
+		t.Error("logger not initialized")
+	}
+}
+
+func TestRenderPage(t *testing.T) {
+	pdfPath := filepath.Join(t.TempDir(), "fixture.pdf")
+	createFixturePDF(t, pdfPath, 2)
+
+	r := New(nil)
+	img, err := r.RenderPage(pdfPath, 2, 144)
+	if err != nil {
+		t.Fatalf("RenderPage() error = %v", err)
+	}
+
+	// 144x72 points at 144 DPI is 288x144 pixels
+	bounds := img.Bounds()
+	if bounds.Dx() != 288 || bounds.Dy() != 144 {
+		t.Errorf("RenderPage() size = %dx%d, want 288x144", bounds.Dx(), bounds.Dy())
+	}
+
+	if r, _, _, _ := img.At(bounds.Min.X+20, bounds.Min.Y+72).RGBA(); r > 0x4000 {
+		t.Errorf("left half pixel red = %#x, want dark", r)
+	}
+	if r, _, _, _ := img.At(bounds.Max.X-20, bounds.Min.Y+72).RGBA(); r < 0xc000 {
+		t.Errorf("right half pixel red = %#x, want light", r)
+	}
+}
+
+func TestRenderPage_InvalidPage(t *testing.T) {
+	pdfPath := filepath.Join(t.TempDir(), "fixture.pdf")
+	createFixturePDF(t, pdfPath, 1)
+
+	r := New(nil)
+	for _, page := range []int{0, 2} {
+		if _, err := r.RenderPage(pdfPath, page, 72); err == nil {
+			t.Errorf("RenderPage(page=%d) expected error", page)
+		}
+	}
+}
+
+func TestRenderPage_FileNotFound(t *testing.T) {
+	r := New(nil)
+	if _, err := r.RenderPage(filepath.Join(t.TempDir(), "missing.pdf"), 1, 72); err == nil {
+		t.Error("RenderPage() expected error for missing file")
+	}
+}
+
+func TestRenderAll(t *testing.T) {
+	pdfPath := filepath.Join(t.TempDir(), "fixture.pdf")
+	createFixturePDF(t, pdfPath, 3)
+
+	r := New(nil)
+	images, err := r.RenderAll(context.Background(), pdfPath, 72)
+	if err != nil {
+		t.Fatalf("RenderAll() error = %v", err)
+	}
+
+	if len(images) != 3 {
+		t.Fatalf("RenderAll() returned %d images, want 3", len(images))
+	}
+	for i, img := range images {
+		if bounds := img.Bounds(); bounds.Dx() != 144 || bounds.Dy() != 72 {
+			t.Errorf("page %d size = %dx%d, want 144x72", i+1, bounds.Dx(), bounds.Dy())
+		}
+	}
+}
+
+func TestRenderAllWithDPI(t *testing.T) {
+	pdfPath := filepath.Join(t.TempDir(), "fixture.pdf")
+	createFixturePDF(t, pdfPath, 2)
+
+	r := New(nil)
+	images, err := r.RenderAllWithDPI(context.Background(), pdfPath, func(page int) int { return 72 * page })
+	if err != nil {
+		t.Fatalf("RenderAllWithDPI() error = %v", err)
+	}
+
+	// Page 1 at 72 DPI, page 2 at 144 DPI
+	for i, want := range []int{144, 288} {
+		if got := images[i].Bounds().Dx(); got != want {
+			t.Errorf("page %d width = %d, want %d", i+1, got, want)
+		}
+	}
+}
+
+func TestRenderAll_Cancelled(t *testing.T) {
+	pdfPath := filepath.Join(t.TempDir(), "fixture.pdf")
+	createFixturePDF(t, pdfPath, 2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	r := New(nil)
+	if _, err := r.RenderAll(ctx, pdfPath, 72); !errors.Is(err, context.Canceled) {
+		t.Errorf("RenderAll() error = %v, want context.Canceled", err)
+	}
+}
+
+func TestEncodePNG(t *testing.T) {
+	pdfPath := filepath.Join(t.TempDir(), "fixture.pdf")
+	createFixturePDF(t, pdfPath, 1)
+
+	img, err := New(nil).RenderPage(pdfPath, 1, 72)
+	if err != nil {
+		t.Fatalf("RenderPage() error = %v", err)
+	}
+
+	data, err := EncodePNG(img)
+	if err != nil {
+		t.Fatalf("EncodePNG() error = %v", err)
+	}
+
+	decoded, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("encoded data is not a valid PNG: %v", err)
+	}
+	if decoded.Bounds() != img.Bounds() {
+		t.Errorf("decoded bounds = %v, want %v", decoded.Bounds(), img.Bounds())
+	}
+}
@@ -0,0 +1,56 @@
+package rmclient
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/juruen/rmapi/api"
+)
+
+// Account describes the reMarkable account a user token was issued to
+type Account struct {
+	UserID      string    `json:"user_id"`
+	Email       string    `json:"email,omitempty"`
+	SyncVersion string    `json:"sync_version"`
+	Scopes      []string  `json:"scopes"`
+	ExpiresAt   time.Time `json:"expires_at"` // zero if the token doesn't expire
+}
+
+// ParseAccount reads the account from a user token without verifying its signature
+// An expired token is still read, so its expiry can be reported, but rmapi only
+// determines the sync version of a token that hasn't expired, so it is "unknown" then.
+func ParseAccount(userToken string) (*Account, error) {
+	claims := api.UserToken{}
+	if _, _, err := (&jwt.Parser{}).ParseUnverified(userToken, &claims); err != nil {
+		return nil, fmt.Errorf("failed to parse user token: %w", err)
+	}
+
+	account := &Account{
+		UserID:      claims.Auth0.UserID,
+		Email:       claims.Auth0.Email,
+		SyncVersion: "unknown",
+		Scopes:      strings.Fields(claims.Scopes),
+	}
+	if claims.StandardClaims != nil && claims.ExpiresAt != 0 {
+		account.ExpiresAt = time.Unix(claims.ExpiresAt, 0)
+	}
+	if info, err := api.ParseToken(userToken); err == nil {
+		account.SyncVersion = info.SyncVersion.String()
+	}
+	return account, nil
+}
+
+// Account returns the account the client is authenticated as, from its current user token
+func (c *Client) Account() (*Account, error) {
+	if !c.IsAuthenticated() {
+		return nil, ErrNotAuthenticated
+	}
+
+	if c.httpCtx == nil {
+		return nil, fmt.Errorf("API client not initialized, call Authenticate() first")
+	}
+
+	return ParseAccount(c.httpCtx.Tokens.UserToken)
+}
@@ -0,0 +1,72 @@
+package rmclient
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// testUserToken returns a user token for the account, expiring at expires
+func testUserToken(t *testing.T, expires time.Time) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"auth0-profile": map[string]interface{}{"UserID": "auth0|1234", "Email": "reader@example.com"},
+		"scopes":        "intgr screenshare hwcmail:-1 mail:-1 sync:tortoise",
+		"exp":           expires.Unix(),
+	}).SignedString([]byte("test"))
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	return token
+}
+
+func TestParseAccount(t *testing.T) {
+	expires := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+
+	account, err := ParseAccount(testUserToken(t, expires))
+	if err != nil {
+		t.Fatalf("ParseAccount() error = %v", err)
+	}
+	if account.UserID != "auth0|1234" || account.Email != "reader@example.com" {
+		t.Errorf("account user = %q <%s>, want auth0|1234 <reader@example.com>", account.UserID, account.Email)
+	}
+	if account.SyncVersion != "1.5" {
+		t.Errorf("SyncVersion = %q, want 1.5", account.SyncVersion)
+	}
+	if strings.Join(account.Scopes, " ") != "intgr screenshare hwcmail:-1 mail:-1 sync:tortoise" {
+		t.Errorf("Scopes = %v", account.Scopes)
+	}
+	if !account.ExpiresAt.Equal(expires) {
+		t.Errorf("ExpiresAt = %v, want %v", account.ExpiresAt, expires)
+	}
+}
+
+func TestParseAccount_Expired(t *testing.T) {
+	expires := time.Now().Add(-time.Hour).Truncate(time.Second)
+
+	account, err := ParseAccount(testUserToken(t, expires))
+	if err != nil {
+		t.Fatalf("ParseAccount() error = %v", err)
+	}
+	if account.UserID != "auth0|1234" || !account.ExpiresAt.Equal(expires) || account.SyncVersion != "unknown" {
+		t.Errorf("account = %+v, want the user and expiry with an unknown sync version", account)
+	}
+}
+
+func TestParseAccount_Invalid(t *testing.T) {
+	if _, err := ParseAccount("not-a-token"); err == nil {
+		t.Error("ParseAccount() expected error for a malformed token")
+	}
+}
+
+func TestClient_Account_NotAuthenticated(t *testing.T) {
+	client, err := NewClient(&Config{TokenPath: t.TempDir() + "/token.json"})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	if _, err := client.Account(); err != ErrNotAuthenticated {
+		t.Errorf("Account() error = %v, want ErrNotAuthenticated", err)
+	}
+}
@@ -0,0 +1,113 @@
+package rmclient
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/juruen/rmapi/config"
+)
+
+// Default reMarkable API base URLs, as rmapi configures them; rmapi reads its
+// RMAPI_AUTH, RMAPI_DOC, and RMAPI_HOST environment variables to change them
+var (
+	// DefaultAuthURL serves device registration and token renewal
+	DefaultAuthURL = strings.TrimSuffix(config.NewTokenDevice, authAPIPath+"json/2/device/new")
+
+	// DefaultStorageURL serves the legacy document storage API
+	DefaultStorageURL = strings.TrimSuffix(config.ListDocs, storageAPIPath+"json/2/docs")
+
+	// DefaultSyncURL serves the sync API that documents are listed and downloaded with
+	DefaultSyncURL = strings.TrimSuffix(config.RootGet, syncAPIPath+"v4/root")
+)
+
+// Paths each API's requests are under, relative to its base URL
+const (
+	authAPIPath    = "/token/"
+	storageAPIPath = "/document-storage/"
+	syncAPIPath    = "/sync/"
+)
+
+// baseURLRule sends requests for one API from its default base URL to another
+type baseURLRule struct {
+	from    *url.URL
+	to      *url.URL
+	apiPath string
+}
+
+// baseURLRewriter sends API requests to configured base URLs instead of the defaults
+//
+// Requests are matched by the default base URL and the API's paths, so the rules
+// still tell the APIs apart when they share a host.
+type baseURLRewriter struct {
+	rules []baseURLRule
+}
+
+// newBaseURLRewriter builds a rewriter for the given base URLs; an empty URL, or the
+// default, leaves that API's requests alone
+func newBaseURLRewriter(authURL, storageURL, syncURL string) (*baseURLRewriter, error) {
+	r := &baseURLRewriter{}
+	for _, api := range []struct {
+		name, base, def, apiPath string
+	}{
+		{name: "auth", base: authURL, def: DefaultAuthURL, apiPath: authAPIPath},
+		{name: "storage", base: storageURL, def: DefaultStorageURL, apiPath: storageAPIPath},
+		{name: "sync", base: syncURL, def: DefaultSyncURL, apiPath: syncAPIPath},
+	} {
+		base := strings.TrimSuffix(strings.TrimSpace(api.base), "/")
+		if base == "" || base == api.def {
+			continue
+		}
+
+		to, err := parseBaseURL(base)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s API base URL %q: %w", api.name, api.base, err)
+		}
+		from, err := parseBaseURL(api.def)
+		if err != nil {
+			return nil, fmt.Errorf("invalid default %s API base URL %q: %w", api.name, api.def, err)
+		}
+		r.rules = append(r.rules, baseURLRule{from: from, to: to, apiPath: api.apiPath})
+	}
+	return r, nil
+}
+
+// parseBaseURL parses an http or https base URL without a query or fragment
+func parseBaseURL(base string) (*url.URL, error) {
+	u, err := url.Parse(base)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("scheme must be http or https")
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("missing host")
+	}
+	if u.RawQuery != "" || u.Fragment != "" {
+		return nil, fmt.Errorf("must not have a query or fragment")
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	u.RawPath = ""
+	return u, nil
+}
+
+// rewrite returns the URL to send a request for u to
+func (r *baseURLRewriter) rewrite(u *url.URL) (*url.URL, bool) {
+	for _, rule := range r.rules {
+		if u.Scheme != rule.from.Scheme || !strings.EqualFold(u.Host, rule.from.Host) ||
+			!strings.HasPrefix(u.Path, rule.from.Path+rule.apiPath) {
+			continue
+		}
+
+		rewritten := *u
+		rewritten.Scheme = rule.to.Scheme
+		rewritten.Host = rule.to.Host
+		rewritten.Path = rule.to.Path + strings.TrimPrefix(u.Path, rule.from.Path)
+		if u.RawPath != "" {
+			rewritten.RawPath = rule.to.Path + strings.TrimPrefix(u.RawPath, rule.from.Path)
+		}
+		return &rewritten, true
+	}
+	return u, false
+}
@@ -0,0 +1,113 @@
+package rmclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/juruen/rmapi/config"
+)
+
+func TestBaseURLRewriter_Rewrite(t *testing.T) {
+	rewriter, err := newBaseURLRewriter("http://localhost:9000", "https://storage.example.com/", "https://example.com/remarkable")
+	if err != nil {
+		t.Fatalf("newBaseURLRewriter() error: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		url       string
+		want      string
+		rewritten bool
+	}{
+		{
+			name:      "auth",
+			url:       config.NewUserDevice,
+			want:      "http://localhost:9000/token/json/2/user/new",
+			rewritten: true,
+		},
+		{
+			name:      "storage",
+			url:       config.ListDocs,
+			want:      "https://storage.example.com/document-storage/json/2/docs",
+			rewritten: true,
+		},
+		{
+			name:      "sync keeps the base URL's path and the query",
+			url:       config.BlobUrl + "abc123?x=1",
+			want:      "https://example.com/remarkable/sync/v3/files/abc123?x=1",
+			rewritten: true,
+		},
+		{
+			name: "other paths on an API host untouched",
+			url:  DefaultAuthURL + "/health",
+			want: DefaultAuthURL + "/health",
+		},
+		{
+			name: "other hosts untouched",
+			url:  "https://storage.googleapis.com/blob",
+			want: "https://storage.googleapis.com/blob",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := url.Parse(tt.url)
+			if err != nil {
+				t.Fatalf("failed to parse %q: %v", tt.url, err)
+			}
+			got, ok := rewriter.rewrite(u)
+			if got.String() != tt.want || ok != tt.rewritten {
+				t.Errorf("rewrite(%q) = (%q, %v), want (%q, %v)", tt.url, got, ok, tt.want, tt.rewritten)
+			}
+		})
+	}
+}
+
+func TestNewBaseURLRewriter_Defaults(t *testing.T) {
+	rewriter, err := newBaseURLRewriter("", DefaultStorageURL+"/", " ")
+	if err != nil {
+		t.Fatalf("newBaseURLRewriter() error: %v", err)
+	}
+	if len(rewriter.rules) != 0 {
+		t.Errorf("rules = %+v, want none for empty and default base URLs", rewriter.rules)
+	}
+}
+
+func TestNewClient_InvalidBaseURL(t *testing.T) {
+	for _, cfg := range []*Config{
+		{AuthURL: "localhost:9000"},
+		{StorageURL: "ftp://example.com"},
+		{SyncURL: "https://example.com/?token=1"},
+	} {
+		if _, err := NewClient(cfg); err == nil {
+			t.Errorf("NewClient(%+v) expected an error", cfg)
+		}
+	}
+}
+
+func TestClient_RequestsUseConfiguredBaseURL(t *testing.T) {
+	var gotPath string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		_, _ = w.Write([]byte("device-token"))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{TokenPath: t.TempDir() + "/token.json", AuthURL: server.URL + "/mock"})
+	if err != nil {
+		t.Fatalf("NewClient() error: %v", err)
+	}
+
+	token, err := client.registerDevice("abcdefgh")
+	if err != nil {
+		t.Fatalf("registerDevice() error: %v", err)
+	}
+	if token != "device-token" {
+		t.Errorf("token = %q, want the mock server's", token)
+	}
+	if gotPath != "/mock/token/json/2/device/new" {
+		t.Errorf("mock server got a request for %q, want /mock/token/json/2/device/new", gotPath)
+	}
+}
@@ -4,57 +4,32 @@ package rmclient
 import (
 	"bufio"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/golang-jwt/jwt"
 	"github.com/google/uuid"
 	"github.com/juruen/rmapi/api"
+	"github.com/juruen/rmapi/api/sync15"
 	"github.com/juruen/rmapi/config"
+	"github.com/juruen/rmapi/filetree"
 	"github.com/juruen/rmapi/model"
 	"github.com/juruen/rmapi/transport"
 	"github.com/platinummonkey/legible/internal/logger"
 )
 
-// urlFixingRoundTripper is a custom HTTP transport that fixes URLs containing doesnotexist.remarkable.com
-// The reMarkable API sometimes returns URLs with this invalid hostname, so we replace it with my.remarkable.com
-type urlFixingRoundTripper struct {
-	base http.RoundTripper
-}
-
-// RoundTrip implements http.RoundTripper
-func (u *urlFixingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
-	// Fix the URL if it contains doesnotexist.remarkable.com
-	if strings.Contains(req.URL.Host, "doesnotexist.remarkable.com") {
-		fixedURL := *req.URL
-		fixedURL.Host = strings.Replace(fixedURL.Host, "doesnotexist.remarkable.com", "my.remarkable.com", 1)
-		req.URL = &fixedURL
-	}
-
-	// Call the base transport
-	if u.base == nil {
-		return http.DefaultTransport.RoundTrip(req)
-	}
-	return u.base.RoundTrip(req)
-}
-
-// wrapHTTPClient wraps an HTTP client with URL fixing middleware
-func wrapHTTPClient(client *http.Client) *http.Client {
-	if client == nil {
-		client = &http.Client{}
-	}
-
-	// Wrap the transport with our URL fixer
-	client.Transport = &urlFixingRoundTripper{
-		base: client.Transport,
-	}
+// ErrNotAuthenticated is returned when the client is used before Authenticate succeeds
+var ErrNotAuthenticated = errors.New("client not authenticated")
 
-	return client
-}
+// ErrTokenRenewal is returned when a user token can't be obtained from the device token,
+// usually because the device token was revoked and the device must be registered again
+var ErrTokenRenewal = errors.New("failed to renew user token")
 
 // maskToken masks a token for safe logging (shows first/last 4 chars)
 func maskToken(token string) string {
@@ -143,7 +118,16 @@ type Client struct {
 	logger       *logger.Logger
 	token        string
 	apiCtx       api.ApiCtx
+	httpCtx      *transport.HttpClientCtx // authenticated transport of apiCtx
 	tokenMonitor *TokenMonitor
+	hosts        *hostRewriter
+	baseURLs     *baseURLRewriter
+	labelMatch   LabelMatch
+	downloads    *downloadTracker
+
+	foldersMu   sync.Mutex
+	folders     *folderPathResolver
+	foldersTree *filetree.FileTreeCtx
 }
 
 // Config holds configuration for the reMarkable client
@@ -159,6 +143,20 @@ type Config struct {
 
 	// TokenStatsFile is the path to save token statistics (optional)
 	TokenStatsFile string
+
+	// HostRewrites overrides the built-in rewrites for known-bad API hosts (optional).
+	// Keys are hostnames to rewrite, values their replacement; an empty value disables a default rule.
+	HostRewrites map[string]string
+
+	// LabelMatch controls whether ListDocuments requires any (default) or all of the requested labels
+	LabelMatch LabelMatch
+
+	// AuthURL, StorageURL, and SyncURL replace the base URLs of the reMarkable auth,
+	// document storage, and sync APIs, such as for a self-hosted server (optional).
+	// Empty values use DefaultAuthURL, DefaultStorageURL, and DefaultSyncURL.
+	AuthURL    string
+	StorageURL string
+	SyncURL    string
 }
 
 // jsonTokenStore stores tokens in JSON format
@@ -241,9 +239,23 @@ func NewClient(cfg *Config) (*Client, error) {
 		log = logger.Get()
 	}
 
+	labelMatch, err := ParseLabelMatch(string(cfg.LabelMatch))
+	if err != nil {
+		return nil, err
+	}
+
+	baseURLs, err := newBaseURLRewriter(cfg.AuthURL, cfg.StorageURL, cfg.SyncURL)
+	if err != nil {
+		return nil, err
+	}
+
 	client := &Client{
-		tokenPath: tokenPath,
-		logger:    log,
+		tokenPath:  tokenPath,
+		logger:     log,
+		hosts:      newHostRewriter(cfg.HostRewrites),
+		baseURLs:   baseURLs,
+		downloads:  newDownloadTracker(),
+		labelMatch: labelMatch,
 	}
 
 	// Initialize token monitor if enabled
@@ -356,9 +368,7 @@ func (c *Client) registerDevice(code string) (string, error) {
 
 	// Create HTTP context for device registration (no auth required)
 	httpCtx := &transport.HttpClientCtx{
-		Client: wrapHTTPClient(&http.Client{
-			Timeout: 60 * time.Second,
-		}),
+		Client: c.apiHTTPClient(),
 		Tokens: model.AuthTokens{},
 	}
 
@@ -392,9 +402,7 @@ func (c *Client) renewUserToken(deviceToken string) (string, error) {
 
 	// Create HTTP context with device token
 	httpCtx := &transport.HttpClientCtx{
-		Client: wrapHTTPClient(&http.Client{
-			Timeout: 60 * time.Second,
-		}),
+		Client: c.apiHTTPClient(),
 		Tokens: model.AuthTokens{
 			DeviceToken: deviceToken,
 		},
@@ -406,7 +414,7 @@ func (c *Client) renewUserToken(deviceToken string) (string, error) {
 	).Info("Calling user token renewal API")
 
 	// Use config.NewUserDevice which uses webapp-prod.cloud.remarkable.engineering
-	// instead of the hardcoded my.remarkable.com that causes redirects
+	// instead of the hardcoded my.remarkable.com that causes redirects (see hosts.go)
 	resp := transport.BodyString{}
 	err := httpCtx.Post(transport.DeviceBearer, config.NewUserDevice, nil, &resp)
 	if err != nil {
@@ -414,7 +422,7 @@ func (c *Client) renewUserToken(deviceToken string) (string, error) {
 			"endpoint", config.NewUserDevice,
 			"device_token_preview", maskedDeviceToken,
 		).Error("User token renewal API call failed")
-		return "", fmt.Errorf("failed to renew user token: %w", err)
+		return "", fmt.Errorf("%w: %w", ErrTokenRenewal, err)
 	}
 
 	maskedUserToken := maskToken(resp.Content)
@@ -448,51 +456,10 @@ func (c *Client) initializeAPIClient() error {
 		"has_user_token", tokens.UserToken != "",
 	).Debug("Loaded tokens")
 
-	// Check if we need to renew the user token (empty or expired)
-	userToken := tokens.UserToken
-	needsRenewal := false
-	if userToken == "" {
-		c.logger.Info("No user token found, need to renew from device token")
-		needsRenewal = true
-	} else if isTokenExpired(userToken) {
-		expTime := getTokenExpiration(userToken)
-		c.logger.WithFields(
-			"expiration", expTime,
-			"time_until_expiry", time.Until(expTime),
-		).Info("User token is expired or about to expire, need to renew")
-		needsRenewal = true
-	} else {
-		expTime := getTokenExpiration(userToken)
-		c.logger.WithFields(
-			"expiration", expTime,
-			"time_until_expiry", time.Until(expTime),
-		).Debug("Using existing valid user token from file")
-	}
-
-	if needsRenewal {
-		userToken, err = c.renewUserToken(tokens.DeviceToken)
-		if err != nil {
-			return fmt.Errorf("failed to renew user token: %w", err)
-		}
-
-		// Record token renewal if monitoring is enabled
-		expTime := getTokenExpiration(userToken)
-		if c.tokenMonitor != nil {
-			validFor := time.Until(expTime)
-			c.tokenMonitor.RecordRenewal("user", validFor)
-		}
-
-		// Save the new user token
-		c.logger.Debug("Saving renewed user token to file")
-		tokens.UserToken = userToken
-		if err := tokenStore.Save(*tokens); err != nil {
-			c.logger.WithError(err).Warn("Failed to save user token to file")
-		} else {
-			c.logger.WithFields(
-				"expiration", expTime,
-				"valid_for", time.Until(expTime),
-			).Info("User token renewed and saved successfully")
-		}
+	// Renew the user token first if it is missing or expired
+	userToken, _, err := c.refreshUserToken(tokenStore, tokens)
+	if err != nil {
+		return err
 	}
 
 	// Parse token to get user info and sync version
@@ -510,9 +477,7 @@ func (c *Client) initializeAPIClient() error {
 
 	// Create HTTP client with URL fixing
 	c.logger.Debug("Creating HTTP client with URL fixing middleware")
-	httpClient := wrapHTTPClient(&http.Client{
-		Timeout: 60 * time.Second,
-	})
+	httpClient := c.apiHTTPClient()
 
 	// Create HTTP context
 	c.logger.Debug("Creating HTTP context with tokens")
@@ -533,10 +498,56 @@ func (c *Client) initializeAPIClient() error {
 	}
 
 	c.apiCtx = apiCtx
+	c.httpCtx = httpCtx
 	c.logger.Info("API client initialized successfully")
 	return nil
 }
 
+// refreshUserToken returns the stored user token, first renewing it from the device
+// token and saving the new one when it is missing, can't be read, or expires within
+// tokenExpirationBuffer; renewed reports whether it was renewed
+func (c *Client) refreshUserToken(tokenStore *jsonTokenStore, tokens *model.AuthTokens) (userToken string, renewed bool, err error) {
+	if tokens.UserToken == "" {
+		c.logger.Info("No user token found, need to renew from device token")
+	} else if expTime := getTokenExpiration(tokens.UserToken); isTokenExpired(tokens.UserToken) {
+		c.logger.WithFields(
+			"expiration", expTime,
+			"time_until_expiry", time.Until(expTime),
+		).Info("User token is expired or about to expire, need to renew")
+	} else {
+		c.logger.WithFields(
+			"expiration", expTime,
+			"time_until_expiry", time.Until(expTime),
+		).Debug("Using existing valid user token from file")
+		return tokens.UserToken, false, nil
+	}
+
+	userToken, err = c.renewUserToken(tokens.DeviceToken)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to renew user token: %w", err)
+	}
+
+	// Record token renewal if monitoring is enabled
+	expTime := getTokenExpiration(userToken)
+	if c.tokenMonitor != nil {
+		c.tokenMonitor.RecordRenewal("user", time.Until(expTime))
+	}
+
+	// Save the new user token
+	c.logger.Debug("Saving renewed user token to file")
+	tokens.UserToken = userToken
+	if err := tokenStore.Save(*tokens); err != nil {
+		c.logger.WithError(err).Warn("Failed to save user token to file")
+	} else {
+		c.logger.WithFields(
+			"expiration", expTime,
+			"valid_for", time.Until(expTime),
+		).Info("User token renewed and saved successfully")
+	}
+
+	return userToken, true, nil
+}
+
 // loadToken loads an existing authentication token from disk
 func (c *Client) loadToken() error {
 	c.logger.WithFields("path", c.tokenPath).Debug("Loading token from file")
@@ -618,33 +629,15 @@ func (c *Client) ensureValidToken() error {
 		return fmt.Errorf("failed to load tokens: %w", err)
 	}
 
-	// Check if user token needs renewal
-	if tokens.UserToken == "" || isTokenExpired(tokens.UserToken) {
-		c.logger.Info("User token expired or missing, renewing before API call")
-
-		userToken, err := c.renewUserToken(tokens.DeviceToken)
-		if err != nil {
-			return fmt.Errorf("failed to renew user token: %w", err)
-		}
-
-		// Record token renewal if monitoring is enabled
-		if c.tokenMonitor != nil {
-			expTime := getTokenExpiration(userToken)
-			validFor := time.Until(expTime)
-			c.tokenMonitor.RecordRenewal("user", validFor)
-		}
-
-		// Update tokens
-		tokens.UserToken = userToken
-		if err := tokenStore.Save(*tokens); err != nil {
-			c.logger.WithError(err).Warn("Failed to save renewed token")
-		}
+	userToken, renewed, err := c.refreshUserToken(tokenStore, tokens)
+	if err != nil {
+		return err
+	}
 
+	if renewed {
 		// Update API context with new token
 		// We need to recreate the HTTP context with the new token
-		httpClient := wrapHTTPClient(&http.Client{
-			Timeout: 60 * time.Second,
-		})
+		httpClient := c.apiHTTPClient()
 
 		httpCtx := &transport.HttpClientCtx{
 			Client: httpClient,
@@ -667,17 +660,67 @@ func (c *Client) ensureValidToken() error {
 		}
 
 		c.apiCtx = apiCtx
+		c.httpCtx = httpCtx
 		c.logger.Info("User token renewed successfully")
 	}
 
 	return nil
 }
 
+// CloudVersion returns the version of the account's documents in the reMarkable cloud,
+// which changes whenever a document or folder is added, edited, moved, or deleted
+// It fetches only the root index, so it is cheap enough to poll for changes.
+func (c *Client) CloudVersion() (string, error) {
+	if !c.IsAuthenticated() {
+		return "", ErrNotAuthenticated
+	}
+
+	if c.apiCtx == nil {
+		return "", fmt.Errorf("API client not initialized, call Authenticate() first")
+	}
+
+	if err := c.ensureValidToken(); err != nil {
+		return "", fmt.Errorf("failed to ensure valid token: %w", err)
+	}
+
+	hash, generation, err := sync15.NewBlobStorage(c.httpCtx).GetRootIndex()
+	if err != nil && !errors.Is(err, transport.ErrNotFound) { // an empty account has no root index
+		return "", fmt.Errorf("failed to get cloud version: %w", err)
+	}
+	return fmt.Sprintf("%d-%s", generation, hash), nil
+}
+
+// Refresh reloads the document tree if documents changed in the cloud since it was
+// loaded, so later listings include the changes
+func (c *Client) Refresh() error {
+	if !c.IsAuthenticated() {
+		return ErrNotAuthenticated
+	}
+
+	if c.apiCtx == nil {
+		return fmt.Errorf("API client not initialized, call Authenticate() first")
+	}
+
+	if err := c.ensureValidToken(); err != nil {
+		return fmt.Errorf("failed to ensure valid token: %w", err)
+	}
+
+	hash, generation, err := c.apiCtx.Refresh()
+	if err != nil {
+		return fmt.Errorf("failed to refresh document tree: %w", err)
+	}
+
+	c.logger.WithFields("generation", generation, "hash", hash).Debug("Refreshed document tree")
+	return nil
+}
+
 // ListDocuments lists all documents, optionally filtered by labels
+// A document matches a label if it or one of its folders is tagged with it; whether any or all
+// labels are required is set by Config.LabelMatch.
 // Returns a list of Document objects representing documents in the reMarkable cloud
 func (c *Client) ListDocuments(labels []string) ([]Document, error) {
 	if !c.IsAuthenticated() {
-		return nil, fmt.Errorf("client not authenticated")
+		return nil, ErrNotAuthenticated
 	}
 
 	if c.apiCtx == nil {
@@ -689,7 +732,7 @@ func (c *Client) ListDocuments(labels []string) ([]Document, error) {
 		return nil, fmt.Errorf("failed to ensure valid token: %w", err)
 	}
 
-	c.logger.WithFields("labels", labels).Debug("Listing documents")
+	c.logger.WithFields("labels", labels, "match", c.labelMatch).Debug("Listing documents")
 
 	// Get the file tree
 	tree := c.apiCtx.Filetree()
@@ -699,12 +742,50 @@ func (c *Client) ListDocuments(labels []string) ([]Document, error) {
 
 	// Collect all documents from the tree
 	var documents []Document
-	c.collectDocuments(tree.Root(), labels, &documents)
+	c.collectDocuments(tree.Root(), newLabelFilter(labels, c.labelMatch), &documents)
 
 	c.logger.WithFields("count", len(documents)).Info("Listed documents")
 	return documents, nil
 }
 
+// ListDocumentsInFolder lists documents in the named folder and its subfolders,
+// optionally filtered by labels as in ListDocuments
+// The folder is given by name or as a "/"-separated path from the root, such as "Work/Journal".
+// Returns an error if the folder does not exist or its name is ambiguous
+func (c *Client) ListDocumentsInFolder(folder string, labels []string) ([]Document, error) {
+	if !c.IsAuthenticated() {
+		return nil, ErrNotAuthenticated
+	}
+
+	if c.apiCtx == nil {
+		return nil, fmt.Errorf("API client not initialized, call Authenticate() first")
+	}
+
+	// Ensure token is valid before making API call
+	if err := c.ensureValidToken(); err != nil {
+		return nil, fmt.Errorf("failed to ensure valid token: %w", err)
+	}
+
+	c.logger.WithFields("folder", folder, "labels", labels, "match", c.labelMatch).Debug("Listing documents in folder")
+
+	// Get the file tree
+	tree := c.apiCtx.Filetree()
+	if tree == nil {
+		return nil, fmt.Errorf("failed to get file tree")
+	}
+
+	node, err := findFolder(tree.Root(), folder)
+	if err != nil {
+		return nil, err
+	}
+
+	var documents []Document
+	c.collectDocuments(node, newLabelFilter(labels, c.labelMatch), &documents)
+
+	c.logger.WithFields("folder", folder, "count", len(documents)).Info("Listed documents in folder")
+	return documents, nil
+}
+
 // parseTime parses a timestamp string from rmapi to time.Time
 // rmapi returns timestamps as RFC3339 strings
 func parseTime(timeStr string) time.Time {
@@ -728,61 +809,39 @@ func parseTime(timeStr string) time.Time {
 	return time.Time{}
 }
 
-// collectDocuments recursively collects documents from the file tree
-func (c *Client) collectDocuments(node *model.Node, labels []string, documents *[]Document) {
+// collectDocuments recursively collects documents from the file tree that pass the label filter
+func (c *Client) collectDocuments(node *model.Node, filter *labelFilter, documents *[]Document) {
 	if node == nil {
 		return
 	}
 
 	// Process current node if it's a document (not a directory)
-	if node.IsFile() && node.Document != nil {
+	if node.Document != nil && node.IsFile() {
 		doc := node.Document
 
-		// If label filters are specified, check if document has matching tags
-		if len(labels) > 0 {
-			hasMatchingTag := false
-			for _, label := range labels {
-				for _, tag := range doc.Tags {
-					if tag == label {
-						hasMatchingTag = true
-						break
-					}
-				}
-				if hasMatchingTag {
-					break
-				}
-			}
-
-			// Skip this document if it doesn't have any matching tags
-			if !hasMatchingTag {
-				c.logger.WithFields("id", doc.ID, "name", doc.Name, "tags", doc.Tags).
-					Debug("Skipping document without matching tags")
-				// Still process children (for collections)
-				for _, child := range node.Children {
-					c.collectDocuments(child, labels, documents)
-				}
-				return
-			}
+		if filter.matches(node) {
+			c.logger.WithFields("id", doc.ID, "name", doc.Name, "tags", doc.Tags).
+				Debug("Including document")
+
+			*documents = append(*documents, Document{
+				ID:             doc.ID,
+				Name:           doc.Name,
+				Type:           doc.Type,
+				Version:        doc.Version,
+				ModifiedClient: parseTime(doc.ModifiedClient),
+				Parent:         doc.Parent,
+				CurrentPage:    doc.CurrentPage,
+				Tags:           doc.Tags,
+			})
+		} else {
+			c.logger.WithFields("id", doc.ID, "name", doc.Name, "tags", doc.Tags).
+				Debug("Skipping document without matching tags")
 		}
-
-		c.logger.WithFields("id", doc.ID, "name", doc.Name, "tags", doc.Tags).
-			Debug("Including document")
-
-		*documents = append(*documents, Document{
-			ID:             doc.ID,
-			Name:           doc.Name,
-			Type:           doc.Type,
-			Version:        doc.Version,
-			ModifiedClient: parseTime(doc.ModifiedClient),
-			Parent:         doc.Parent,
-			CurrentPage:    doc.CurrentPage,
-			Tags:           doc.Tags,
-		})
 	}
 
 	// Recursively process children
 	for _, child := range node.Children {
-		c.collectDocuments(child, labels, documents)
+		c.collectDocuments(child, filter, documents)
 	}
 }
 
@@ -790,7 +849,7 @@ func (c *Client) collectDocuments(node *model.Node, labels []string, documents *
 // Returns a Document with full information from the reMarkable cloud
 func (c *Client) GetDocumentMetadata(id string) (*Document, error) {
 	if !c.IsAuthenticated() {
-		return nil, fmt.Errorf("client not authenticated")
+		return nil, ErrNotAuthenticated
 	}
 
 	if c.apiCtx == nil {
@@ -834,10 +893,26 @@ func (c *Client) GetDocumentMetadata(id string) (*Document, error) {
 	}, nil
 }
 
+// apiHTTPClient returns the HTTP client for API requests, which rewrites broken hosts
+// and reports the progress of document downloads
+func (c *Client) apiHTTPClient() *http.Client {
+	client := wrapHTTPClient(&http.Client{
+		Timeout: 60 * time.Second,
+	}, c.hosts, c.baseURLs)
+	client.Transport = &progressRoundTripper{base: client.Transport, tracker: c.downloads}
+	return client
+}
+
 // DownloadDocument downloads a document to the specified path
 func (c *Client) DownloadDocument(id, outputPath string) error {
+	return c.DownloadDocumentWithProgress(id, outputPath, nil)
+}
+
+// DownloadDocumentWithProgress downloads a document to the specified path, reporting
+// the bytes received to progress as the document's files download
+func (c *Client) DownloadDocumentWithProgress(id, outputPath string, progress ProgressFunc) error {
 	if !c.IsAuthenticated() {
-		return fmt.Errorf("client not authenticated")
+		return ErrNotAuthenticated
 	}
 
 	if c.apiCtx == nil {
@@ -857,6 +932,11 @@ func (c *Client) DownloadDocument(id, outputPath string) error {
 		return fmt.Errorf("failed to create output directory: %w", err)
 	}
 
+	if progress != nil {
+		stop := c.downloads.track(id, progress)
+		defer stop()
+	}
+
 	// Use rmapi to fetch the document
 	// FetchDocument downloads the document as a .zip file
 	if err := c.apiCtx.FetchDocument(id, outputPath); err != nil {
@@ -881,11 +961,12 @@ func (c *Client) SetToken(token string) error {
 }
 
 // GetFolderPath returns the full folder path for a document by traversing its parent chain
+// Folder names are sanitized and joined with "/"; resolved paths are cached per file tree
 // Returns an empty string if the document is in the root folder
 // Returns an error if the document is not found or if there's a circular reference
 func (c *Client) GetFolderPath(documentID string) (string, error) {
 	if !c.IsAuthenticated() {
-		return "", fmt.Errorf("client not authenticated")
+		return "", ErrNotAuthenticated
 	}
 
 	if c.apiCtx == nil {
@@ -903,52 +984,20 @@ func (c *Client) GetFolderPath(documentID string) (string, error) {
 		return "", fmt.Errorf("failed to get file tree")
 	}
 
-	// Find the node by ID
-	node := tree.NodeById(documentID)
-	if node == nil {
-		return "", fmt.Errorf("document not found: %s", documentID)
-	}
-
-	// Build the folder path by traversing parents
-	var pathParts []string
-	visited := make(map[string]bool)
-	currentNode := node
-
-	// Traverse up to the root, collecting folder names
-	for currentNode != nil && currentNode.Parent != nil {
-		parentID := ""
-		if currentNode.Document != nil {
-			parentID = currentNode.Document.Parent
-		}
-
-		// Check for circular references
-		if visited[parentID] {
-			return "", fmt.Errorf("circular reference detected in folder hierarchy")
-		}
-		visited[parentID] = true
-
-		// Get parent node
-		parentNode := tree.NodeById(parentID)
-		if parentNode == nil {
-			// Parent not found, assume we've reached root
-			break
-		}
+	return c.folderResolver(tree).documentPath(documentID)
+}
 
-		// Add parent folder name to path (if it's a collection)
-		if parentNode.Document != nil && parentNode.Document.Type == CollectionType {
-			sanitized := sanitizeFolderName(parentNode.Document.Name)
-			// Only add if the folder name is valid (not empty and not just special chars)
-			if isValidFolderName(sanitized) {
-				// Prepend to build path from root to document
-				pathParts = append([]string{sanitized}, pathParts...)
-			}
-		}
+// folderResolver returns the folder path resolver for tree, replacing the cached
+// resolver when the API has loaded a new file tree
+func (c *Client) folderResolver(tree *filetree.FileTreeCtx) *folderPathResolver {
+	c.foldersMu.Lock()
+	defer c.foldersMu.Unlock()
 
-		currentNode = parentNode
+	if c.folders == nil || c.foldersTree != tree {
+		c.folders = newFolderPathResolver(tree.NodeById)
+		c.foldersTree = tree
 	}
-
-	// Join path parts with forward slash
-	return filepath.Join(pathParts...), nil
+	return c.folders
 }
 
 // sanitizeFolderName removes or replaces characters that are invalid in folder names
@@ -3,6 +3,9 @@ package rmclient
 import (
 	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
@@ -379,6 +382,20 @@ func TestClient_ListDocuments_NotAuthenticated(t *testing.T) {
 	}
 }
 
+func TestClient_CloudVersion_NotAuthenticated(t *testing.T) {
+	client, err := NewClient(&Config{TokenPath: filepath.Join(t.TempDir(), "token.json")})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	if _, err := client.CloudVersion(); !errors.Is(err, ErrNotAuthenticated) {
+		t.Errorf("CloudVersion() error = %v, want ErrNotAuthenticated", err)
+	}
+	if err := client.Refresh(); !errors.Is(err, ErrNotAuthenticated) {
+		t.Errorf("Refresh() error = %v, want ErrNotAuthenticated", err)
+	}
+}
+
 func TestClient_GetDocumentMetadata_NotAuthenticated(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -863,16 +880,6 @@ func TestIsValidFolderName(t *testing.T) {
 	}
 }
 
-// Note: Testing GetFolderPath with actual folder hierarchy requires mocking the rmapi library
-// or integration tests with a real reMarkable account. The following scenarios would be tested
-// with proper mocks:
-// 1. Document in root folder (empty parent) -> returns ""
-// 2. Document in single folder -> returns "FolderName"
-// 3. Document in nested folders -> returns "Parent/Child/Grandchild"
-// 4. Circular reference detection -> returns error
-// 5. Document not found -> returns error
-// These would be covered by integration tests or with a proper mock of api.ApiCtx and model.Filetree
-
 func TestIsTokenExpired(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -990,3 +997,136 @@ func createTestJWT(t *testing.T, expiration time.Time) string {
 	// For testing, we don't need a real signature
 	return headerB64 + "." + payloadB64 + "."
 }
+
+// userTokenServer returns an auth server that renews user tokens for deviceToken with
+// newToken, counting the renewals in *renewals
+func userTokenServer(t *testing.T, deviceToken, newToken string, renewals *int) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/token/json/2/user/new" {
+			http.NotFound(w, r)
+			return
+		}
+		*renewals++
+		if r.Header.Get("Authorization") != "Bearer "+deviceToken {
+			http.Error(w, "unknown device", http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(newToken))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// writeTokens writes a token file holding deviceToken and userToken
+func writeTokens(t *testing.T, path, deviceToken, userToken string) {
+	t.Helper()
+	data, err := json.Marshal(map[string]string{"device_token": deviceToken, "user_token": userToken})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestClient_RefreshUserToken(t *testing.T) {
+	fresh := createTestJWT(t, time.Now().Add(24*time.Hour))
+
+	tests := []struct {
+		name        string
+		userToken   string
+		wantRenewed bool
+	}{
+		{name: "expired", userToken: createTestJWT(t, time.Now().Add(-time.Hour)), wantRenewed: true},
+		{name: "within refresh window", userToken: createTestJWT(t, time.Now().Add(time.Minute)), wantRenewed: true},
+		{name: "missing", userToken: "", wantRenewed: true},
+		{name: "unreadable", userToken: "not-a-jwt", wantRenewed: true},
+		{name: "valid", userToken: createTestJWT(t, time.Now().Add(time.Hour)), wantRenewed: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var renewals int
+			server := userTokenServer(t, "device-token-12345", fresh, &renewals)
+			tokenPath := filepath.Join(t.TempDir(), "token.json")
+			writeTokens(t, tokenPath, "device-token-12345", tt.userToken)
+
+			client, err := NewClient(&Config{TokenPath: tokenPath, AuthURL: server.URL, EnableTokenMonitoring: true})
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+			store := &jsonTokenStore{tokenPath: tokenPath}
+			tokens, err := store.Load()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			userToken, renewed, err := client.refreshUserToken(store, tokens)
+			if err != nil {
+				t.Fatalf("refreshUserToken() error = %v", err)
+			}
+			if renewed != tt.wantRenewed {
+				t.Errorf("renewed = %v, want %v", renewed, tt.wantRenewed)
+			}
+
+			want := tt.userToken
+			wantRenewals := 0
+			if tt.wantRenewed {
+				want, wantRenewals = fresh, 1
+			}
+			if userToken != want {
+				t.Errorf("user token = %q, want %q", userToken, want)
+			}
+			if renewals != wantRenewals {
+				t.Errorf("renewal requests = %d, want %d", renewals, wantRenewals)
+			}
+
+			// The renewed token is saved alongside the device token
+			saved, err := store.Load()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if saved.UserToken != want || saved.DeviceToken != "device-token-12345" {
+				t.Errorf("saved tokens = %+v, want user token %q and the device token", saved, want)
+			}
+			if tt.wantRenewed && client.GetTokenMonitor().GetStatistics().RenewalCount != 1 {
+				t.Error("renewal wasn't recorded by the token monitor")
+			}
+		})
+	}
+}
+
+func TestClient_RefreshUserToken_RenewalFails(t *testing.T) {
+	var renewals int
+	server := userTokenServer(t, "other-device", "unused", &renewals)
+	tokenPath := filepath.Join(t.TempDir(), "token.json")
+	expired := createTestJWT(t, time.Now().Add(-time.Hour))
+	writeTokens(t, tokenPath, "revoked-device-token", expired)
+
+	client, err := NewClient(&Config{TokenPath: tokenPath, AuthURL: server.URL})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	store := &jsonTokenStore{tokenPath: tokenPath}
+	tokens, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := client.refreshUserToken(store, tokens); !errors.Is(err, ErrTokenRenewal) {
+		t.Errorf("refreshUserToken() error = %v, want ErrTokenRenewal", err)
+	}
+	if renewals != 1 {
+		t.Errorf("renewal requests = %d, want 1", renewals)
+	}
+
+	// The stale token is left for the next attempt
+	saved, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.UserToken != expired {
+		t.Error("the token file changed after a failed renewal")
+	}
+}
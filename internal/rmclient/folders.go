@@ -0,0 +1,140 @@
+package rmclient
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/juruen/rmapi/model"
+)
+
+// folderPathResolver maps nodes to their sanitized folder paths within one file tree
+//
+// Resolved folder paths are cached by folder ID, so siblings and nested documents
+// reuse the work done for their ancestors. A resolver must be discarded when the
+// file tree it reads from is replaced.
+type folderPathResolver struct {
+	lookup func(id string) *model.Node
+	cache  map[string]string
+}
+
+// newFolderPathResolver creates a resolver that finds nodes with lookup
+func newFolderPathResolver(lookup func(id string) *model.Node) *folderPathResolver {
+	return &folderPathResolver{
+		lookup: lookup,
+		cache:  make(map[string]string),
+	}
+}
+
+// documentPath returns the folder path containing the document with the given ID
+func (r *folderPathResolver) documentPath(documentID string) (string, error) {
+	node := r.lookup(documentID)
+	if node == nil || node.Document == nil {
+		return "", fmt.Errorf("document not found: %s", documentID)
+	}
+
+	return r.folderPath(node.Document.Parent)
+}
+
+// folderPath returns the path of the folder with the given ID, or "" for the root
+//
+// Parents that cannot be found are treated as the root. Folder names that sanitize to
+// nothing usable are skipped rather than producing empty path segments.
+func (r *folderPathResolver) folderPath(folderID string) (string, error) {
+	var chain []*model.Node
+	visited := make(map[string]bool)
+	base := ""
+
+	// Walk up until the root, a missing parent, or an already resolved folder
+	for id := folderID; id != ""; {
+		if cached, ok := r.cache[id]; ok {
+			base = cached
+			break
+		}
+
+		if visited[id] {
+			return "", fmt.Errorf("circular reference detected in folder hierarchy at %s", id)
+		}
+		visited[id] = true
+
+		node := r.lookup(id)
+		if node == nil || node.Document == nil {
+			break
+		}
+
+		chain = append(chain, node)
+		id = node.Document.Parent
+	}
+
+	// Build paths from the top down, caching each folder on the way
+	for i := len(chain) - 1; i >= 0; i-- {
+		doc := chain[i].Document
+		if doc.Type == CollectionType {
+			if name := sanitizeFolderName(doc.Name); isValidFolderName(name) {
+				base = path.Join(base, name)
+			}
+		}
+		r.cache[doc.ID] = base
+	}
+
+	return base, nil
+}
+
+// findFolder returns the folder under root at folderPath, a "/"-separated path of
+// folder names such as "Work/Journal"
+//
+// Each segment matches a child folder's name exactly, falling back to a
+// case-insensitive match. A segment matching more than one sibling is an error, since
+// the reMarkable allows duplicate folder names and either choice could be wrong.
+func findFolder(root *model.Node, folderPath string) (*model.Node, error) {
+	node := root
+	found := false
+	for _, segment := range strings.Split(folderPath, "/") {
+		segment = strings.TrimSpace(segment)
+		if segment == "" {
+			continue
+		}
+
+		child, err := findChildFolder(node, segment)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve folder %q: %w", folderPath, err)
+		}
+		node = child
+		found = true
+	}
+
+	if !found {
+		return nil, fmt.Errorf("folder path is empty")
+	}
+	return node, nil
+}
+
+// findChildFolder returns the child folder of parent called name
+func findChildFolder(parent *model.Node, name string) (*model.Node, error) {
+	var exact, folded []*model.Node
+	for _, child := range parent.Children {
+		if child.Document == nil || child.Document.Type != CollectionType {
+			continue
+		}
+		switch {
+		case child.Document.Name == name:
+			exact = append(exact, child)
+		case strings.EqualFold(child.Document.Name, name):
+			folded = append(folded, child)
+		}
+	}
+
+	matches := exact
+	if len(matches) == 0 {
+		matches = folded
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("folder %q not found", name)
+	case 1:
+		return matches[0], nil
+	default:
+		return nil, fmt.Errorf("folder name %q is ambiguous, %d folders match", name, len(matches))
+	}
+}
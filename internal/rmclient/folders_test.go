@@ -0,0 +1,206 @@
+package rmclient
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/juruen/rmapi/model"
+)
+
+// stubLookup indexes a stub file tree by ID and counts lookups
+type stubLookup struct {
+	nodes map[string]*model.Node
+	calls int
+}
+
+func newStubLookup(root *model.Node) *stubLookup {
+	l := &stubLookup{nodes: make(map[string]*model.Node)}
+	var index func(*model.Node)
+	index = func(n *model.Node) {
+		l.nodes[n.Id()] = n
+		for _, child := range n.Children {
+			index(child)
+		}
+	}
+	index(root)
+	return l
+}
+
+func (l *stubLookup) lookup(id string) *model.Node {
+	l.calls++
+	return l.nodes[id]
+}
+
+// folderStubTree builds:
+//
+//	root
+//	├── doc-root
+//	└── Work/
+//	    ├── doc-work
+//	    └── Projects: 2024/
+//	        └── Q1/
+//	            ├── doc-q1
+//	            └── doc-q1-b
+func folderStubTree() *model.Node {
+	root := model.CreateNode(model.Document{ID: "", Type: model.DirectoryType})
+	addNode(&root, "doc-root", model.DocumentType)
+
+	work := addNode(&root, "work", model.DirectoryType)
+	work.Document.Name = "Work"
+	addNode(work, "doc-work", model.DocumentType)
+
+	projects := addNode(work, "projects", model.DirectoryType)
+	projects.Document.Name = "Projects: 2024"
+
+	q1 := addNode(projects, "q1", model.DirectoryType)
+	q1.Document.Name = "Q1"
+	addNode(q1, "doc-q1", model.DocumentType)
+	addNode(q1, "doc-q1-b", model.DocumentType)
+
+	return &root
+}
+
+func TestFolderPathResolver_DocumentPath(t *testing.T) {
+	tests := []struct {
+		id   string
+		want string
+	}{
+		{"doc-root", ""},
+		{"doc-work", "Work"},
+		{"doc-q1", "Work/Projects- 2024/Q1"},
+	}
+
+	resolver := newFolderPathResolver(newStubLookup(folderStubTree()).lookup)
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			got, err := resolver.documentPath(tt.id)
+			if err != nil {
+				t.Fatalf("documentPath() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("documentPath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFolderPathResolver_NotFound(t *testing.T) {
+	resolver := newFolderPathResolver(newStubLookup(folderStubTree()).lookup)
+	if _, err := resolver.documentPath("missing"); err == nil {
+		t.Error("documentPath() should error for unknown document")
+	}
+}
+
+func TestFolderPathResolver_Caches(t *testing.T) {
+	stub := newStubLookup(folderStubTree())
+	resolver := newFolderPathResolver(stub.lookup)
+
+	if _, err := resolver.documentPath("doc-q1"); err != nil {
+		t.Fatalf("documentPath() error = %v", err)
+	}
+	// Document plus its three folders
+	if stub.calls != 4 {
+		t.Errorf("first resolution made %d lookups, want 4", stub.calls)
+	}
+
+	stub.calls = 0
+	got, err := resolver.documentPath("doc-q1-b")
+	if err != nil {
+		t.Fatalf("documentPath() error = %v", err)
+	}
+	if got != "Work/Projects- 2024/Q1" {
+		t.Errorf("documentPath() = %q, want %q", got, "Work/Projects- 2024/Q1")
+	}
+	// Only the document itself; the folder chain is cached
+	if stub.calls != 1 {
+		t.Errorf("cached resolution made %d lookups, want 1", stub.calls)
+	}
+}
+
+func TestFolderPathResolver_CircularReference(t *testing.T) {
+	root := folderStubTree()
+	stub := newStubLookup(root)
+	// Make Work a child of Q1, closing a loop
+	stub.nodes["work"].Document.Parent = "q1"
+
+	resolver := newFolderPathResolver(stub.lookup)
+	_, err := resolver.documentPath("doc-q1")
+	if err == nil || !strings.Contains(err.Error(), "circular reference") {
+		t.Errorf("documentPath() error = %v, want circular reference error", err)
+	}
+}
+
+func TestFindFolder(t *testing.T) {
+	tests := []struct {
+		path    string
+		wantID  string
+		wantErr bool
+	}{
+		{path: "Work", wantID: "work"},
+		{path: "Work/Projects: 2024/Q1", wantID: "q1"},
+		{path: "/work/projects: 2024/", wantID: "projects"},
+		{path: "Q1", wantErr: true},
+		{path: "Work/Missing", wantErr: true},
+		{path: "Work/doc-work", wantErr: true},
+		{path: "", wantErr: true},
+	}
+
+	root := folderStubTree()
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			node, err := findFolder(root, tt.path)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("findFolder() = %s, want error", node.Id())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("findFolder() error = %v", err)
+			}
+			if node.Id() != tt.wantID {
+				t.Errorf("findFolder() = %s, want %s", node.Id(), tt.wantID)
+			}
+		})
+	}
+}
+
+func TestFindFolder_Ambiguous(t *testing.T) {
+	root := folderStubTree()
+	other := addNode(root, "work-2", model.DirectoryType)
+	other.Document.Name = "Work"
+
+	_, err := findFolder(root, "Work/Q1")
+	if err == nil || !strings.Contains(err.Error(), "ambiguous") {
+		t.Errorf("findFolder() error = %v, want ambiguous folder error", err)
+	}
+}
+
+func TestCollectDocuments_Folder(t *testing.T) {
+	client, err := NewClient(&Config{TokenPath: filepath.Join(t.TempDir(), "token.json")})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	folder, err := findFolder(folderStubTree(), "Work/Projects: 2024")
+	if err != nil {
+		t.Fatalf("findFolder() error = %v", err)
+	}
+
+	var docs []Document
+	client.collectDocuments(folder, newLabelFilter(nil, MatchAny), &docs)
+
+	ids := make([]string, 0, len(docs))
+	for _, doc := range docs {
+		ids = append(ids, doc.ID)
+	}
+	sort.Strings(ids)
+
+	// Nested documents are included; documents in Work itself and the root are not
+	want := []string{"doc-q1", "doc-q1-b"}
+	if strings.Join(ids, ",") != strings.Join(want, ",") {
+		t.Errorf("documents = %v, want %v", ids, want)
+	}
+}
@@ -0,0 +1,117 @@
+package rmclient
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// Known reMarkable cloud hosts
+const (
+	// invalidHost is a placeholder hostname the API sometimes returns in blob and document URLs
+	invalidHost = "doesnotexist.remarkable.com"
+
+	// webAppHost serves the web app and accepts requests for the invalid placeholder host.
+	// Token renewal must not be sent here since it redirects; rmapi's config.NewUserDevice
+	// already targets webapp-prod.cloud.remarkable.engineering for that call.
+	webAppHost = "my.remarkable.com"
+)
+
+// defaultHostRewrites maps hosts known to be broken to the host requests should go to
+var defaultHostRewrites = map[string]string{
+	invalidHost: webAppHost,
+}
+
+// hostRewriter rewrites request hostnames according to a fixed set of rules
+type hostRewriter struct {
+	rules map[string]string
+}
+
+// newHostRewriter builds a rewriter from the default rules plus overrides.
+// Override keys replace or add rules; an empty target disables the rule for that host.
+func newHostRewriter(overrides map[string]string) *hostRewriter {
+	rules := make(map[string]string, len(defaultHostRewrites)+len(overrides))
+	for from, to := range defaultHostRewrites {
+		rules[from] = to
+	}
+	for from, to := range overrides {
+		from = strings.ToLower(strings.TrimSpace(from))
+		to = strings.ToLower(strings.TrimSpace(to))
+		if to == "" {
+			delete(rules, from)
+			continue
+		}
+		rules[from] = to
+	}
+	return &hostRewriter{rules: rules}
+}
+
+// rewrite returns the host to use for host, preserving any port
+func (h *hostRewriter) rewrite(host string) (string, bool) {
+	name, port, err := net.SplitHostPort(host)
+	if err != nil {
+		name, port = host, ""
+	}
+
+	target, ok := h.rules[strings.ToLower(name)]
+	if !ok || strings.EqualFold(target, name) {
+		return host, false
+	}
+
+	if port != "" {
+		return net.JoinHostPort(target, port), true
+	}
+	return target, true
+}
+
+// urlFixingRoundTripper is a custom HTTP transport that rewrites known-bad reMarkable hosts
+// The reMarkable API sometimes returns URLs with invalid hostnames, so every request
+// made by this package goes through the same rewrite rules
+type urlFixingRoundTripper struct {
+	base     http.RoundTripper
+	rewriter *hostRewriter
+	baseURLs *baseURLRewriter // optional
+}
+
+// RoundTrip implements http.RoundTripper
+func (u *urlFixingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if host, ok := u.rewriter.rewrite(req.URL.Host); ok {
+		// RoundTrippers must not modify the caller's request
+		req = req.Clone(req.Context())
+		req.URL.Host = host
+		req.Host = ""
+	}
+	if u.baseURLs != nil {
+		if target, ok := u.baseURLs.rewrite(req.URL); ok {
+			req = req.Clone(req.Context())
+			req.URL = target
+			req.Host = ""
+		}
+	}
+
+	// Call the base transport
+	if u.base == nil {
+		return http.DefaultTransport.RoundTrip(req)
+	}
+	return u.base.RoundTrip(req)
+}
+
+// wrapHTTPClient wraps an HTTP client with URL fixing middleware, which also sends
+// requests to baseURLs, if not nil
+func wrapHTTPClient(client *http.Client, rewriter *hostRewriter, baseURLs *baseURLRewriter) *http.Client {
+	if client == nil {
+		client = &http.Client{}
+	}
+	if rewriter == nil {
+		rewriter = newHostRewriter(nil)
+	}
+
+	// Wrap the transport with our URL fixer
+	client.Transport = &urlFixingRoundTripper{
+		base:     client.Transport,
+		rewriter: rewriter,
+		baseURLs: baseURLs,
+	}
+
+	return client
+}
@@ -0,0 +1,128 @@
+package rmclient
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHostRewriter_Rewrite(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides map[string]string
+		host      string
+		want      string
+		rewritten bool
+	}{
+		{
+			name:      "invalid placeholder host",
+			host:      "doesnotexist.remarkable.com",
+			want:      "my.remarkable.com",
+			rewritten: true,
+		},
+		{
+			name:      "invalid placeholder host with port",
+			host:      "doesnotexist.remarkable.com:443",
+			want:      "my.remarkable.com:443",
+			rewritten: true,
+		},
+		{
+			name:      "case insensitive",
+			host:      "DoesNotExist.reMarkable.com",
+			want:      "my.remarkable.com",
+			rewritten: true,
+		},
+		{
+			name: "auth host untouched",
+			host: "webapp-prod.cloud.remarkable.engineering",
+			want: "webapp-prod.cloud.remarkable.engineering",
+		},
+		{
+			name: "web app host untouched",
+			host: "my.remarkable.com",
+			want: "my.remarkable.com",
+		},
+		{
+			name: "subdomain of bad host untouched",
+			host: "eu.doesnotexist.remarkable.com",
+			want: "eu.doesnotexist.remarkable.com",
+		},
+		{
+			name:      "override replaces default target",
+			overrides: map[string]string{"doesnotexist.remarkable.com": "internal.cloud.remarkable.com"},
+			host:      "doesnotexist.remarkable.com",
+			want:      "internal.cloud.remarkable.com",
+			rewritten: true,
+		},
+		{
+			name:      "override adds rule",
+			overrides: map[string]string{" My.reMarkable.com ": "webapp-prod.cloud.remarkable.engineering"},
+			host:      "my.remarkable.com",
+			want:      "webapp-prod.cloud.remarkable.engineering",
+			rewritten: true,
+		},
+		{
+			name:      "empty override disables default",
+			overrides: map[string]string{"doesnotexist.remarkable.com": ""},
+			host:      "doesnotexist.remarkable.com",
+			want:      "doesnotexist.remarkable.com",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := newHostRewriter(tt.overrides).rewrite(tt.host)
+			if got != tt.want || ok != tt.rewritten {
+				t.Errorf("rewrite(%q) = (%q, %v), want (%q, %v)", tt.host, got, ok, tt.want, tt.rewritten)
+			}
+		})
+	}
+}
+
+func TestNewHostRewriter_DoesNotModifyDefaults(t *testing.T) {
+	newHostRewriter(map[string]string{invalidHost: ""})
+
+	if defaultHostRewrites[invalidHost] != webAppHost {
+		t.Errorf("default rule for %s = %q, want %q", invalidHost, defaultHostRewrites[invalidHost], webAppHost)
+	}
+}
+
+func TestURLFixingRoundTripper(t *testing.T) {
+	var gotHost string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHost = r.Host
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// Route the placeholder host to the test server
+	serverHost := server.Listener.Addr().String()
+	client := wrapHTTPClient(server.Client(), newHostRewriter(map[string]string{
+		invalidHost: "127.0.0.1",
+	}), nil)
+
+	_, port, err := net.SplitHostPort(serverHost)
+	if err != nil {
+		t.Fatalf("failed to parse server address: %v", err)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, "http://"+invalidHost+":"+port+"/doc", nil)
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	originalURL := req.URL.String()
+
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	if gotHost != serverHost {
+		t.Errorf("server saw host %q, want %q", gotHost, serverHost)
+	}
+	if req.URL.String() != originalURL {
+		t.Errorf("caller's request URL modified: %s", req.URL)
+	}
+}
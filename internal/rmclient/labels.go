@@ -0,0 +1,114 @@
+package rmclient
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/juruen/rmapi/model"
+)
+
+// LabelMatch controls how documents are matched against requested labels
+type LabelMatch string
+
+const (
+	// MatchAny selects documents carrying at least one of the requested labels
+	MatchAny LabelMatch = "any"
+
+	// MatchAll selects documents carrying every requested label
+	MatchAll LabelMatch = "all"
+)
+
+// ParseLabelMatch parses a label match mode, treating an empty string as MatchAny
+func ParseLabelMatch(s string) (LabelMatch, error) {
+	switch LabelMatch(strings.ToLower(strings.TrimSpace(s))) {
+	case "", MatchAny:
+		return MatchAny, nil
+	case MatchAll:
+		return MatchAll, nil
+	default:
+		return "", fmt.Errorf("invalid label match mode %q, must be one of: %s, %s", s, MatchAny, MatchAll)
+	}
+}
+
+// labelFilter selects documents by label during a single ListDocuments call
+//
+// A document's labels are its own tags plus the tags of the folders containing it,
+// so tagging a folder selects everything inside it. Folder labels are cached by
+// folder ID, so each folder chain is resolved once per listing.
+type labelFilter struct {
+	labels       []string
+	mode         LabelMatch
+	folderLabels map[string]map[string]bool
+}
+
+// newLabelFilter creates a filter for the requested labels; no labels matches every document
+func newLabelFilter(labels []string, mode LabelMatch) *labelFilter {
+	if mode == "" {
+		mode = MatchAny
+	}
+	return &labelFilter{
+		labels:       labels,
+		mode:         mode,
+		folderLabels: make(map[string]map[string]bool),
+	}
+}
+
+// matches reports whether a document node satisfies the filter
+func (f *labelFilter) matches(node *model.Node) bool {
+	if len(f.labels) == 0 {
+		return true
+	}
+
+	have := f.documentLabels(node)
+	for _, label := range f.labels {
+		if have[label] {
+			if f.mode == MatchAny {
+				return true
+			}
+		} else if f.mode == MatchAll {
+			return false
+		}
+	}
+
+	return f.mode == MatchAll
+}
+
+// documentLabels returns the labels of a document node, including those inherited from its folders
+func (f *labelFilter) documentLabels(node *model.Node) map[string]bool {
+	inherited := f.inheritedLabels(node.Parent)
+	if len(node.Document.Tags) == 0 {
+		return inherited
+	}
+
+	labels := make(map[string]bool, len(inherited)+len(node.Document.Tags))
+	for label := range inherited {
+		labels[label] = true
+	}
+	for _, tag := range node.Document.Tags {
+		labels[tag] = true
+	}
+	return labels
+}
+
+// inheritedLabels returns the tags of a folder and all of its ancestors
+func (f *labelFilter) inheritedLabels(folder *model.Node) map[string]bool {
+	if folder == nil || folder.Document == nil || folder.IsRoot() {
+		return nil
+	}
+
+	if labels, ok := f.folderLabels[folder.Id()]; ok {
+		return labels
+	}
+
+	parentLabels := f.inheritedLabels(folder.Parent)
+	labels := make(map[string]bool, len(parentLabels)+len(folder.Document.Tags))
+	for label := range parentLabels {
+		labels[label] = true
+	}
+	for _, tag := range folder.Document.Tags {
+		labels[tag] = true
+	}
+
+	f.folderLabels[folder.Id()] = labels
+	return labels
+}
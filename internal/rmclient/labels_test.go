@@ -0,0 +1,182 @@
+package rmclient
+
+import (
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/juruen/rmapi/model"
+)
+
+// addNode attaches a document or folder node to parent in a stub file tree
+func addNode(parent *model.Node, id, docType string, tags ...string) *model.Node {
+	node := model.CreateNode(model.Document{
+		ID:     id,
+		Name:   id,
+		Type:   docType,
+		Parent: parent.Id(),
+		Tags:   tags,
+	})
+	node.Parent = parent
+	parent.Children[id] = &node
+	return &node
+}
+
+// stubTree builds:
+//
+//	root
+//	├── doc-work           [work]
+//	├── doc-both           [work, personal]
+//	├── doc-none
+//	└── projects/          [work]
+//	    ├── doc-inherited  [personal]
+//	    └── archive/
+//	        └── doc-deep
+func stubTree() *model.Node {
+	root := model.CreateNode(model.Document{ID: "", Type: model.DirectoryType})
+
+	addNode(&root, "doc-work", model.DocumentType, "work")
+	addNode(&root, "doc-both", model.DocumentType, "work", "personal")
+	addNode(&root, "doc-none", model.DocumentType)
+
+	projects := addNode(&root, "projects", model.DirectoryType, "work")
+	addNode(projects, "doc-inherited", model.DocumentType, "personal")
+	archive := addNode(projects, "archive", model.DirectoryType)
+	addNode(archive, "doc-deep", model.DocumentType)
+
+	return &root
+}
+
+func listStubDocuments(t *testing.T, labels []string, mode LabelMatch) []string {
+	t.Helper()
+
+	client, err := NewClient(&Config{TokenPath: filepath.Join(t.TempDir(), "token.json"), LabelMatch: mode})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	var docs []Document
+	client.collectDocuments(stubTree(), newLabelFilter(labels, client.labelMatch), &docs)
+
+	ids := make([]string, 0, len(docs))
+	for _, doc := range docs {
+		ids = append(ids, doc.ID)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func TestCollectDocuments_LabelFiltering(t *testing.T) {
+	tests := []struct {
+		name   string
+		labels []string
+		mode   LabelMatch
+		want   []string
+	}{
+		{
+			name:   "no labels returns everything",
+			labels: nil,
+			mode:   MatchAll,
+			want:   []string{"doc-both", "doc-deep", "doc-inherited", "doc-none", "doc-work"},
+		},
+		{
+			name:   "match any single label includes folder contents",
+			labels: []string{"work"},
+			mode:   MatchAny,
+			want:   []string{"doc-both", "doc-deep", "doc-inherited", "doc-work"},
+		},
+		{
+			name:   "match any of several labels",
+			labels: []string{"personal", "missing"},
+			mode:   MatchAny,
+			want:   []string{"doc-both", "doc-inherited"},
+		},
+		{
+			name:   "match all requires every label",
+			labels: []string{"work", "personal"},
+			mode:   MatchAll,
+			want:   []string{"doc-both", "doc-inherited"},
+		},
+		{
+			name:   "match all with unknown label",
+			labels: []string{"work", "missing"},
+			mode:   MatchAll,
+			want:   []string{},
+		},
+		{
+			name:   "default mode is any",
+			labels: []string{"work", "personal"},
+			mode:   "",
+			want:   []string{"doc-both", "doc-deep", "doc-inherited", "doc-work"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := listStubDocuments(t, tt.labels, tt.mode)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("got %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestLabelFilter_CachesFolderLabels(t *testing.T) {
+	root := stubTree()
+	filter := newLabelFilter([]string{"work"}, MatchAny)
+
+	archive := root.Children["projects"].Children["archive"]
+	if !filter.matches(archive.Children["doc-deep"]) {
+		t.Fatal("document in tagged folder should match")
+	}
+
+	// Both folders on the path are resolved once and reused
+	if len(filter.folderLabels) != 2 {
+		t.Errorf("cached %d folders, want 2", len(filter.folderLabels))
+	}
+	if !filter.folderLabels["archive"]["work"] {
+		t.Error("archive folder should inherit the work label")
+	}
+
+	// Cached entries are served without walking the tree again
+	filter.folderLabels["archive"] = map[string]bool{"other": true}
+	if filter.matches(archive.Children["doc-deep"]) {
+		t.Error("cached folder labels were not used")
+	}
+}
+
+func TestParseLabelMatch(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    LabelMatch
+		wantErr bool
+	}{
+		{in: "", want: MatchAny},
+		{in: "any", want: MatchAny},
+		{in: "ALL", want: MatchAll},
+		{in: "some", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseLabelMatch(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseLabelMatch(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseLabelMatch(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNewClient_InvalidLabelMatch(t *testing.T) {
+	_, err := NewClient(&Config{TokenPath: filepath.Join(t.TempDir(), "token.json"), LabelMatch: "some"})
+	if err == nil {
+		t.Error("NewClient() should reject an invalid label match mode")
+	}
+}
@@ -0,0 +1,150 @@
+package rmclient
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/juruen/rmapi/transport"
+)
+
+// ProgressFunc reports a document download's progress: the bytes received so far, and
+// the total size of the document's files requested so far
+//
+// rmapi requests a document's files one after another, so total grows as each file
+// starts downloading; it is final once the last file has started.
+type ProgressFunc func(downloaded, total int64)
+
+// downloadTracker attributes blob downloads to the documents being downloaded
+//
+// rmapi doesn't report download progress, but it names each blob it fetches in the
+// rm-filename header as "<document ID>.<ext>" or "<document ID>/<file>", so responses
+// can be matched to the document they belong to and their bodies counted as read.
+type downloadTracker struct {
+	mu        sync.Mutex
+	downloads map[string]*trackedDownload
+}
+
+// trackedDownload is the progress of one document download
+type trackedDownload struct {
+	progress   ProgressFunc
+	downloaded int64
+	total      int64
+}
+
+func newDownloadTracker() *downloadTracker {
+	return &downloadTracker{downloads: make(map[string]*trackedDownload)}
+}
+
+// track reports the progress of the blobs of document id to progress until the
+// returned function is called
+func (t *downloadTracker) track(id string, progress ProgressFunc) (stop func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	download := &trackedDownload{progress: progress}
+	t.downloads[id] = download
+	return func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		if t.downloads[id] == download {
+			delete(t.downloads, id)
+		}
+	}
+}
+
+// start returns the download a blob response belongs to, adding its size to the total,
+// or nil if the blob's document isn't being tracked
+func (t *downloadTracker) start(req *http.Request, resp *http.Response) *trackedDownload {
+	id := blobDocumentID(req)
+	if id == "" {
+		return nil
+	}
+
+	t.mu.Lock()
+	download, ok := t.downloads[id]
+	if !ok {
+		t.mu.Unlock()
+		return nil
+	}
+	if resp.ContentLength > 0 {
+		download.total += resp.ContentLength
+	}
+	downloaded, total := download.downloaded, download.total
+	t.mu.Unlock()
+
+	download.progress(downloaded, total)
+	return download
+}
+
+// add records n more bytes received for download and reports the progress
+func (t *downloadTracker) add(download *trackedDownload, n int) {
+	t.mu.Lock()
+	download.downloaded += int64(n)
+	downloaded, total := download.downloaded, download.total
+	t.mu.Unlock()
+
+	download.progress(downloaded, total)
+}
+
+// blobDocumentID returns the ID of the document a blob request is for, or "" if the
+// request isn't for a named blob
+func blobDocumentID(req *http.Request) string {
+	if req.Method != http.MethodGet {
+		return ""
+	}
+
+	// rmapi sets the header with its lowercase name, bypassing canonicalization
+	name := ""
+	if values := req.Header[transport.RmFileNameHeader]; len(values) > 0 {
+		name = values[0]
+	} else {
+		name = req.Header.Get(transport.RmFileNameHeader)
+	}
+
+	if i := strings.IndexAny(name, "./"); i > 0 {
+		return name[:i]
+	}
+	return ""
+}
+
+// progressRoundTripper counts the response bodies of tracked document downloads
+type progressRoundTripper struct {
+	base    http.RoundTripper
+	tracker *downloadTracker
+}
+
+// RoundTrip implements http.RoundTripper
+func (p *progressRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := p.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	resp, err := base.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return resp, err
+	}
+
+	if download := p.tracker.start(req, resp); download != nil {
+		resp.Body = &progressReader{ReadCloser: resp.Body, tracker: p.tracker, download: download}
+	}
+	return resp, nil
+}
+
+// progressReader reports the bytes read from a blob body
+type progressReader struct {
+	io.ReadCloser
+	tracker  *downloadTracker
+	download *trackedDownload
+}
+
+// Read implements io.Reader
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 {
+		r.tracker.add(r.download, n)
+	}
+	return n, err
+}
@@ -0,0 +1,130 @@
+package rmclient
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/juruen/rmapi/transport"
+)
+
+// streamingBlobServer serves a body of size bytes in chunks, flushing each so the
+// client receives it over several reads
+func streamingBlobServer(t *testing.T, size, chunk int) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Length", strconv.Itoa(size))
+		w.WriteHeader(http.StatusOK)
+		data := make([]byte, chunk)
+		for sent := 0; sent < size; sent += chunk {
+			_, _ = w.Write(data[:min(chunk, size-sent)])
+			w.(http.Flusher).Flush()
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// getBlob fetches url as rmapi fetches a document's blob named name
+func getBlob(t *testing.T, client *http.Client, url, name string) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header[transport.RmFileNameHeader] = []string{name}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("GET %s error = %v", name, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if _, err := io.Copy(io.Discard, resp.Body); err != nil {
+		t.Fatalf("reading %s error = %v", name, err)
+	}
+}
+
+func TestDownloadProgress_ReportsIncreasingBytes(t *testing.T) {
+	const size = 256 << 10
+	server := streamingBlobServer(t, size, 16<<10)
+
+	client, err := NewClient(&Config{TokenPath: filepath.Join(t.TempDir(), "token.json")})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	httpClient := client.apiHTTPClient()
+
+	type report struct{ downloaded, total int64 }
+	var mu sync.Mutex
+	var reports []report
+	stop := client.downloads.track("doc-1", func(downloaded, total int64) {
+		mu.Lock()
+		defer mu.Unlock()
+		reports = append(reports, report{downloaded, total})
+	})
+
+	getBlob(t, httpClient, server.URL, "doc-1.content")
+	getBlob(t, httpClient, server.URL, "doc-1/page.rm")
+
+	// Blobs of other documents, and blobs fetched after tracking stops, aren't reported
+	getBlob(t, httpClient, server.URL, "doc-2/page.rm")
+	stop()
+	getBlob(t, httpClient, server.URL, "doc-1/late.rm")
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(reports) < 4 {
+		t.Fatalf("got %d progress reports, want several per blob", len(reports))
+	}
+	for i := 1; i < len(reports); i++ {
+		prev, cur := reports[i-1], reports[i]
+		if cur.downloaded < prev.downloaded || cur.total < prev.total {
+			t.Fatalf("report %d = %+v went backwards from %+v", i, cur, prev)
+		}
+		if cur.downloaded > cur.total {
+			t.Fatalf("report %d = %+v exceeds its total", i, cur)
+		}
+	}
+	if last := reports[len(reports)-1]; last.downloaded != 2*size || last.total != 2*size {
+		t.Errorf("final report = %+v, want %d of %d bytes", last, 2*size, 2*size)
+	}
+
+	// Both blobs were read over several reports each
+	increases := 0
+	for i := 1; i < len(reports); i++ {
+		if reports[i].downloaded > reports[i-1].downloaded {
+			increases++
+		}
+	}
+	if increases < 4 {
+		t.Errorf("byte count increased %d times, want several per blob", increases)
+	}
+}
+
+func TestBlobDocumentID(t *testing.T) {
+	tests := []struct {
+		method string
+		name   string
+		want   string
+	}{
+		{method: http.MethodGet, name: "doc-1.content", want: "doc-1"},
+		{method: http.MethodGet, name: "doc-1/3f2a.rm", want: "doc-1"},
+		{method: http.MethodGet, name: "root", want: ""},
+		{method: http.MethodGet, name: "", want: ""},
+		{method: http.MethodPut, name: "doc-1.content", want: ""},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, "/blob", nil)
+		if tt.name != "" {
+			req.Header[transport.RmFileNameHeader] = []string{tt.name}
+		}
+		if got := blobDocumentID(req); got != tt.want {
+			t.Errorf("blobDocumentID(%s %q) = %q, want %q", tt.method, tt.name, got, tt.want)
+		}
+	}
+}
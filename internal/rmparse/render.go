@@ -3,6 +3,7 @@ package rmparse
 
 import (
 	"fmt"
+	"math"
 
 	"github.com/signintech/gopdf"
 )
@@ -20,8 +21,75 @@ const (
 	// Scale factor from reMarkable coordinates to PDF points
 	ScaleX = PDFWidth / RMWidth
 	ScaleY = PDFHeight / RMHeight
+
+	// reMarkable Paper Pro screen: 1620 x 2160 pixels at 229 DPI
+	PaperProWidth  = 1620.0
+	PaperProHeight = 2160.0
+	PaperProDPI    = 229.0
+)
+
+// FormatVersionPaperPro is the .content formatVersion of documents written on the
+// reMarkable Paper Pro. Earlier versions (1 and 2) use the reMarkable 1/2 screen size.
+const FormatVersionPaperPro = 3
+
+// PageSize describes a device's native coordinate space and the PDF page it renders to
+type PageSize struct {
+	// Native screen size in device pixels
+	NativeWidth  float64
+	NativeHeight float64
+
+	// Rendered PDF size in points; this is the whole page unless the size was fitted to a larger page
+	PDFWidth  float64
+	PDFHeight float64
+
+	// Position of the rendered area on the PDF page in points (zero unless fitted)
+	OffsetX float64
+	OffsetY float64
+}
+
+// FitToPage returns a copy of s scaled uniformly to fit a width x height point page and
+// centered on it, so strokes keep their aspect ratio on pages of a different shape
+func (s PageSize) FitToPage(width, height float64) PageSize {
+	scale := math.Min(width/s.NativeWidth, height/s.NativeHeight)
+
+	fitted := s
+	fitted.PDFWidth = s.NativeWidth * scale
+	fitted.PDFHeight = s.NativeHeight * scale
+	fitted.OffsetX = (width - fitted.PDFWidth) / 2
+	fitted.OffsetY = (height - fitted.PDFHeight) / 2
+	return fitted
+}
+
+var (
+	// RM2PageSize is the reMarkable 1/2 screen rendered to an A5 page
+	RM2PageSize = PageSize{
+		NativeWidth:  RMWidth,
+		NativeHeight: RMHeight,
+		PDFWidth:     PDFWidth,
+		PDFHeight:    PDFHeight,
+	}
+
+	// PaperProPageSize is the reMarkable Paper Pro screen rendered at its physical size
+	PaperProPageSize = PageSize{
+		NativeWidth:  PaperProWidth,
+		NativeHeight: PaperProHeight,
+		PDFWidth:     PaperProWidth * 72.0 / PaperProDPI,
+		PDFHeight:    PaperProHeight * 72.0 / PaperProDPI,
+	}
 )
 
+// PageSizeForFormatVersion returns the page size implied by a .content formatVersion
+//
+// Unknown versions fall back to the reMarkable 1/2 page size.
+func PageSizeForFormatVersion(formatVersion int) PageSize {
+	switch formatVersion {
+	case FormatVersionPaperPro:
+		return PaperProPageSize
+	default:
+		return RM2PageSize
+	}
+}
+
 // PenColor maps pen color values to RGB
 type PenColor struct {
 	R, G, B uint8
@@ -48,7 +116,7 @@ func RenderToPDF(rmFile *RMFile, outputPath string) error {
 	// Render each layer
 	for _, layer := range rmFile.Layers {
 		for _, line := range layer.Lines {
-			if err := renderLine(&pdf, line); err != nil {
+			if err := renderLine(&pdf, line, RM2PageSize); err != nil {
 				return fmt.Errorf("failed to render line: %w", err)
 			}
 		}
@@ -64,10 +132,16 @@ func RenderToPDF(rmFile *RMFile, outputPath string) error {
 
 // RenderToPage renders an RMFile to an existing PDF page
 func RenderToPage(pdf *gopdf.GoPdf, rmFile *RMFile) error {
+	return RenderToPageWithSize(pdf, rmFile, RM2PageSize)
+}
+
+// RenderToPageWithSize renders an RMFile to an existing PDF page, mapping
+// coordinates from the given device's native size to its PDF page size
+func RenderToPageWithSize(pdf *gopdf.GoPdf, rmFile *RMFile, size PageSize) error {
 	// Render each layer
 	for _, layer := range rmFile.Layers {
 		for _, line := range layer.Lines {
-			if err := renderLine(pdf, line); err != nil {
+			if err := renderLine(pdf, line, size); err != nil {
 				return fmt.Errorf("failed to render line: %w", err)
 			}
 		}
@@ -76,7 +150,7 @@ func RenderToPage(pdf *gopdf.GoPdf, rmFile *RMFile) error {
 }
 
 // renderLine renders a single line (stroke) to the PDF
-func renderLine(pdf *gopdf.GoPdf, line Line) error {
+func renderLine(pdf *gopdf.GoPdf, line Line, size PageSize) error {
 	if len(line.Points) < 2 {
 		return nil // Need at least 2 points to draw a line
 	}
@@ -98,11 +172,11 @@ func renderLine(pdf *gopdf.GoPdf, line Line) error {
 
 	// Draw the stroke as a series of line segments
 	firstPoint := line.Points[0]
-	x1, y1 := transformPoint(firstPoint.X, firstPoint.Y)
+	x1, y1 := transformPoint(firstPoint.X, firstPoint.Y, size)
 
 	for i := 1; i < len(line.Points); i++ {
 		point := line.Points[i]
-		x2, y2 := transformPoint(point.X, point.Y)
+		x2, y2 := transformPoint(point.X, point.Y, size)
 
 		// Draw line segment
 		pdf.Line(x1, y1, x2, y2)
@@ -115,25 +189,25 @@ func renderLine(pdf *gopdf.GoPdf, line Line) error {
 }
 
 // transformPoint converts reMarkable coordinates to PDF coordinates
-func transformPoint(x, y float32) (float64, float64) {
+func transformPoint(x, y float32, size PageSize) (float64, float64) {
 	// reMarkable coordinates (from rmv6 spec):
-	//   X: origin at CENTER of page, ranges approximately -702 to +702 (1404 pixels / 2)
-	//   Y: origin at TOP of page, ranges 0 to 1872
+	//   X: origin at CENTER of page, ranges -NativeWidth/2 to +NativeWidth/2 (±702 on reMarkable 2)
+	//   Y: origin at TOP of page, ranges 0 to NativeHeight
 	//
 	// PDF coordinates:
 	//   Origin at top-left corner
-	//   X: ranges 0 to PDFWidth (420 pts)
-	//   Y: ranges 0 to PDFHeight (595 pts)
+	//   X: ranges 0 to PDFWidth (420 pts on reMarkable 2)
+	//   Y: ranges 0 to PDFHeight (595 pts on reMarkable 2)
 
-	// Convert X from centered (±702) to left-aligned (0 to 1404)
-	rmX := float64(x) + (RMWidth / 2)
+	// Convert X from centered to left-aligned (0 to NativeWidth)
+	rmX := float64(x) + (size.NativeWidth / 2)
 
 	// Y is already top-aligned, just use as-is
 	rmY := float64(y)
 
-	// Scale to PDF dimensions
-	pdfX := rmX * ScaleX
-	pdfY := rmY * ScaleY
+	// Scale to PDF dimensions and place on the page
+	pdfX := size.OffsetX + rmX*size.PDFWidth/size.NativeWidth
+	pdfY := size.OffsetY + rmY*size.PDFHeight/size.NativeHeight
 
 	return pdfX, pdfY
 }
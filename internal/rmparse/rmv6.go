@@ -57,6 +57,11 @@ func ParseRM(filename string) (*RMFile, error) {
 		return nil, fmt.Errorf("failed to read file: %w", err)
 	}
 
+	return ParseRMData(data)
+}
+
+// ParseRMData parses the contents of a reMarkable v6 .rm file
+func ParseRMData(data []byte) (*RMFile, error) {
 	if len(data) < 43 {
 		return nil, fmt.Errorf("file too small")
 	}
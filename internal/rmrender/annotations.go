@@ -0,0 +1,149 @@
+package rmrender
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Annotations is the JSON export of a parsed document's strokes
+//
+// Coordinates are in reMarkable screen space (Width x Height pixels), so consumers
+// can map them onto the PDF page or the original document as needed.
+type Annotations struct {
+	Version string            `json:"version"`
+	Width   int               `json:"width"`
+	Height  int               `json:"height"`
+	Layers  []AnnotationLayer `json:"layers"`
+}
+
+// AnnotationLayer holds the strokes of a single layer
+type AnnotationLayer struct {
+	Index   int                `json:"index"`
+	Name    string             `json:"name,omitempty"`
+	Strokes []AnnotationStroke `json:"strokes"`
+}
+
+// AnnotationStroke is a single stroke with its style, bounds and points
+type AnnotationStroke struct {
+	Tool      string            `json:"tool"`
+	Highlight bool              `json:"highlight,omitempty"`
+	Eraser    bool              `json:"eraser,omitempty"`
+	Color     string            `json:"color"`
+	RGB       [3]uint8          `json:"rgb"`
+	BrushSize float32           `json:"brush_size"`
+	Bounds    AnnotationBounds  `json:"bounds"`
+	Points    []AnnotationPoint `json:"points"`
+}
+
+// AnnotationBounds is the bounding box of a stroke
+type AnnotationBounds struct {
+	MinX float32 `json:"min_x"`
+	MinY float32 `json:"min_y"`
+	MaxX float32 `json:"max_x"`
+	MaxY float32 `json:"max_y"`
+}
+
+// AnnotationPoint is a single stroke point
+type AnnotationPoint struct {
+	X        float32 `json:"x"`
+	Y        float32 `json:"y"`
+	Pressure float32 `json:"pressure"`
+	Width    float32 `json:"width"`
+}
+
+// ExportAnnotations converts a parsed document into its annotation export
+func ExportAnnotations(doc *Document) *Annotations {
+	if doc == nil {
+		return nil
+	}
+
+	export := &Annotations{
+		Version: doc.Version.String(),
+		Width:   Width,
+		Height:  Height,
+		Layers:  make([]AnnotationLayer, 0, len(doc.Layers)),
+	}
+
+	for i, layer := range doc.Layers {
+		exported := AnnotationLayer{
+			Index:   i,
+			Name:    layer.Name,
+			Strokes: make([]AnnotationStroke, 0, len(layer.Lines)),
+		}
+		for _, line := range layer.Lines {
+			exported.Strokes = append(exported.Strokes, exportStroke(line))
+		}
+		export.Layers = append(export.Layers, exported)
+	}
+
+	return export
+}
+
+// exportStroke converts a parsed line into an annotation stroke
+func exportStroke(line Line) AnnotationStroke {
+	r, g, b := line.Color.RGB()
+	stroke := AnnotationStroke{
+		Tool:      line.BrushType.String(),
+		Highlight: line.BrushType == BrushHighlighter,
+		Eraser:    line.BrushType == BrushEraser || line.BrushType == BrushEraseSection,
+		Color:     line.Color.String(),
+		RGB:       [3]uint8{r, g, b},
+		BrushSize: line.BrushSize,
+		Points:    make([]AnnotationPoint, 0, len(line.Points)),
+	}
+
+	for i, p := range line.Points {
+		stroke.Points = append(stroke.Points, AnnotationPoint{
+			X:        p.X,
+			Y:        p.Y,
+			Pressure: p.Pressure,
+			Width:    p.Width,
+		})
+
+		if i == 0 {
+			stroke.Bounds = AnnotationBounds{MinX: p.X, MinY: p.Y, MaxX: p.X, MaxY: p.Y}
+			continue
+		}
+		stroke.Bounds.MinX = min(stroke.Bounds.MinX, p.X)
+		stroke.Bounds.MinY = min(stroke.Bounds.MinY, p.Y)
+		stroke.Bounds.MaxX = max(stroke.Bounds.MaxX, p.X)
+		stroke.Bounds.MaxY = max(stroke.Bounds.MaxY, p.Y)
+	}
+
+	return stroke
+}
+
+// MarshalAnnotations returns the annotation export of a document as indented JSON
+func MarshalAnnotations(doc *Document) ([]byte, error) {
+	if doc == nil {
+		return nil, fmt.Errorf("document is nil")
+	}
+
+	data, err := json.MarshalIndent(ExportAnnotations(doc), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal annotations: %w", err)
+	}
+	return data, nil
+}
+
+// AnnotationsPath returns the path of the annotations JSON written alongside a PDF
+// (e.g. "notes.pdf" -> "notes.annotations.json")
+func AnnotationsPath(pdfPath string) string {
+	return strings.TrimSuffix(pdfPath, filepath.Ext(pdfPath)) + ".annotations.json"
+}
+
+// WriteAnnotations writes the annotation export of a document to path
+func WriteAnnotations(doc *Document, path string) error {
+	data, err := MarshalAnnotations(doc)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write annotations: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package rmrender
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func annotationFixture() *Document {
+	return &Document{
+		Version: Version6,
+		Layers: []Layer{
+			{
+				Lines: []Line{
+					{
+						BrushType: BrushFineliner,
+						Color:     ColorBlack,
+						BrushSize: 2,
+						Points: []Point{
+							{X: 10, Y: 20, Pressure: 0.5, Width: 2},
+							{X: 30, Y: 5, Pressure: 0.6, Width: 2},
+							{X: 25, Y: 40, Pressure: 0.7, Width: 2},
+						},
+					},
+					{
+						BrushType: BrushEraser,
+						Color:     ColorWhite,
+						BrushSize: 5,
+						Points:    []Point{{X: 1, Y: 1}, {X: 2, Y: 2}},
+					},
+				},
+			},
+			{
+				Lines: []Line{
+					{
+						BrushType: BrushHighlighter,
+						Color:     ColorYellow,
+						BrushSize: 15,
+						Points:    []Point{{X: 100, Y: 200}, {X: 300, Y: 200}},
+					},
+				},
+			},
+		},
+	}
+}
+
+//nolint:gocyclo // Test function with multiple validation steps
+func TestWriteAnnotations(t *testing.T) {
+	path := AnnotationsPath(filepath.Join(t.TempDir(), "notes.pdf"))
+	if filepath.Base(path) != "notes.annotations.json" {
+		t.Errorf("AnnotationsPath() = %s, want notes.annotations.json", filepath.Base(path))
+	}
+
+	if err := WriteAnnotations(annotationFixture(), path); err != nil {
+		t.Fatalf("WriteAnnotations() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read annotations: %v", err)
+	}
+
+	var got Annotations
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("annotations are not valid JSON: %v", err)
+	}
+
+	if got.Version != "v6" || got.Width != Width || got.Height != Height {
+		t.Errorf("header = %s %dx%d, want v6 %dx%d", got.Version, got.Width, got.Height, Width, Height)
+	}
+
+	if len(got.Layers) != 2 {
+		t.Fatalf("got %d layers, want 2", len(got.Layers))
+	}
+
+	wantStrokes := []int{2, 1}
+	wantPoints := [][]int{{3, 2}, {2}}
+	for i, layer := range got.Layers {
+		if layer.Index != i {
+			t.Errorf("layer %d index = %d", i, layer.Index)
+		}
+		if len(layer.Strokes) != wantStrokes[i] {
+			t.Fatalf("layer %d has %d strokes, want %d", i, len(layer.Strokes), wantStrokes[i])
+		}
+		for j, stroke := range layer.Strokes {
+			if len(stroke.Points) != wantPoints[i][j] {
+				t.Errorf("layer %d stroke %d has %d points, want %d", i, j, len(stroke.Points), wantPoints[i][j])
+			}
+		}
+	}
+
+	pen := got.Layers[0].Strokes[0]
+	if pen.Tool != "Fineliner" || pen.Color != "Black" || pen.Highlight || pen.Eraser {
+		t.Errorf("pen stroke = %+v, want black fineliner", pen)
+	}
+	if want := (AnnotationBounds{MinX: 10, MinY: 5, MaxX: 30, MaxY: 40}); pen.Bounds != want {
+		t.Errorf("pen bounds = %+v, want %+v", pen.Bounds, want)
+	}
+
+	if !got.Layers[0].Strokes[1].Eraser {
+		t.Error("eraser stroke not flagged")
+	}
+
+	highlight := got.Layers[1].Strokes[0]
+	if !highlight.Highlight || highlight.Color != "Yellow" || highlight.RGB != [3]uint8{255, 242, 0} {
+		t.Errorf("highlight stroke = %+v, want yellow highlight", highlight)
+	}
+}
+
+func TestExportAnnotations_ExampleFile(t *testing.T) {
+	doc, err := ParseFile("../../example/b68e57f6-4fc9-4a71-b300-e0fa100ef8d7/aefd8acc-a17d-4e24-a76c-66a3ee15b4ba.rm")
+	if err != nil {
+		t.Fatalf("Failed to parse example file: %v", err)
+	}
+
+	export := ExportAnnotations(doc)
+	if len(export.Layers) != len(doc.Layers) {
+		t.Fatalf("got %d layers, want %d", len(export.Layers), len(doc.Layers))
+	}
+
+	points := 0
+	for i, layer := range export.Layers {
+		if len(layer.Strokes) != len(doc.Layers[i].Lines) {
+			t.Errorf("layer %d has %d strokes, want %d", i, len(layer.Strokes), len(doc.Layers[i].Lines))
+		}
+		for _, stroke := range layer.Strokes {
+			points += len(stroke.Points)
+		}
+	}
+
+	if points != EstimateComplexity(doc) {
+		t.Errorf("exported %d points, want %d", points, EstimateComplexity(doc))
+	}
+}
+
+func TestMarshalAnnotations_NilDocument(t *testing.T) {
+	if _, err := MarshalAnnotations(nil); err == nil {
+		t.Error("MarshalAnnotations(nil) expected error")
+	}
+}
@@ -0,0 +1,147 @@
+package rmrender
+
+import (
+	"math"
+
+	"github.com/signintech/gopdf"
+)
+
+// eraserSizeScale converts an eraser's brush size to a width in device pixels for
+// points that don't record their own width
+const eraserSizeScale = 10.0
+
+// eraserWidth returns the width in device pixels the eraser covers at point p
+func eraserWidth(p Point, brushSize float32) float64 {
+	if p.Width > 0 {
+		return float64(p.Width)
+	}
+	return float64(brushSize) * eraserSizeScale
+}
+
+// renderEraser draws an eraser stroke in the background color so it covers earlier ink
+func (r *Renderer) renderEraser(pdf *gopdf.GoPdf, stroke Line) error {
+	if len(stroke.Points) < 2 {
+		return nil
+	}
+
+	red, green, blue := r.options.BackgroundColor.RGB()
+	pdf.SetStrokeColor(red, green, blue)
+
+	for i := 0; i < len(stroke.Points)-1; i++ {
+		p1, p2 := stroke.Points[i], stroke.Points[i+1]
+
+		x1, y1 := r.transformCoordinateToPDF(p1.X, p1.Y)
+		x2, y2 := r.transformCoordinateToPDF(p2.X, p2.Y)
+
+		pdf.SetLineWidth(eraserWidth(p1, stroke.BrushSize) * 72.0 / float64(DPI))
+		pdf.Line(x1, y1, x2, y2)
+	}
+
+	return nil
+}
+
+// clipErasedStrokes removes the parts of strokes that later eraser strokes in the
+// same layer pass over
+//
+// A segment of ink is removed when it comes within half the eraser's width of any
+// eraser segment; the remaining runs of segments become separate strokes. Eraser
+// strokes themselves are dropped from the result.
+func clipErasedStrokes(lines []Line) []Line {
+	var result []Line
+	for _, line := range lines {
+		if !line.BrushType.IsEraser() {
+			result = append(result, line)
+			continue
+		}
+
+		var clipped []Line
+		for _, ink := range result {
+			clipped = append(clipped, eraseFromStroke(ink, line)...)
+		}
+		result = clipped
+	}
+	return result
+}
+
+// eraseFromStroke returns the pieces of ink left after eraser passes over it
+func eraseFromStroke(ink, eraser Line) []Line {
+	if len(ink.Points) < 2 || len(eraser.Points) == 0 {
+		return []Line{ink}
+	}
+
+	var pieces []Line
+	var current []Point
+	flush := func() {
+		if len(current) >= 2 {
+			piece := ink
+			piece.Points = current
+			pieces = append(pieces, piece)
+		}
+		current = nil
+	}
+
+	for i := 0; i < len(ink.Points)-1; i++ {
+		a, b := ink.Points[i], ink.Points[i+1]
+		if segmentErased(a, b, eraser) {
+			flush()
+			continue
+		}
+		if len(current) == 0 {
+			current = append(current, a)
+		}
+		current = append(current, b)
+	}
+	flush()
+
+	return pieces
+}
+
+// segmentErased reports whether the ink segment a-b passes under the eraser
+func segmentErased(a, b Point, eraser Line) bool {
+	if len(eraser.Points) == 1 {
+		p := eraser.Points[0]
+		return pointSegmentDistance(p, a, b) < eraserWidth(p, eraser.BrushSize)/2
+	}
+
+	for i := 0; i < len(eraser.Points)-1; i++ {
+		e1, e2 := eraser.Points[i], eraser.Points[i+1]
+		if segmentDistance(a, b, e1, e2) < eraserWidth(e1, eraser.BrushSize)/2 {
+			return true
+		}
+	}
+	return false
+}
+
+// segmentDistance returns the shortest distance between segments a-b and c-d
+func segmentDistance(a, b, c, d Point) float64 {
+	if segmentsIntersect(a, b, c, d) {
+		return 0
+	}
+	return math.Min(
+		math.Min(pointSegmentDistance(a, c, d), pointSegmentDistance(b, c, d)),
+		math.Min(pointSegmentDistance(c, a, b), pointSegmentDistance(d, a, b)),
+	)
+}
+
+// pointSegmentDistance returns the distance from p to segment a-b
+func pointSegmentDistance(p, a, b Point) float64 {
+	px, py := float64(p.X), float64(p.Y)
+	ax, ay := float64(a.X), float64(a.Y)
+	dx, dy := float64(b.X)-ax, float64(b.Y)-ay
+
+	t := 0.0
+	if lengthSq := dx*dx + dy*dy; lengthSq > 0 {
+		t = math.Max(0, math.Min(1, ((px-ax)*dx+(py-ay)*dy)/lengthSq))
+	}
+	return math.Hypot(px-(ax+t*dx), py-(ay+t*dy))
+}
+
+// segmentsIntersect reports whether segments a-b and c-d cross
+func segmentsIntersect(a, b, c, d Point) bool {
+	cross := func(o, p, q Point) float64 {
+		return float64(p.X-o.X)*float64(q.Y-o.Y) - float64(p.Y-o.Y)*float64(q.X-o.X)
+	}
+	d1, d2 := cross(c, d, a), cross(c, d, b)
+	d3, d4 := cross(a, b, c), cross(a, b, d)
+	return ((d1 > 0 && d2 < 0) || (d1 < 0 && d2 > 0)) && ((d3 > 0 && d4 < 0) || (d3 < 0 && d4 > 0))
+}
@@ -0,0 +1,122 @@
+package rmrender
+
+import (
+	"image"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/platinummonkey/legible/internal/pdfrender"
+)
+
+// lineThrough returns a stroke with evenly spaced points from (x1, y1) to (x2, y2)
+func lineThrough(brush BrushType, x1, y1, x2, y2 float32, width float32) Line {
+	line := Line{BrushType: brush, Color: ColorBlack, BrushSize: 2}
+	for i := 0; i <= 10; i++ {
+		t := float32(i) / 10
+		line.Points = append(line.Points, Point{
+			X:        x1 + (x2-x1)*t,
+			Y:        y1 + (y2-y1)*t,
+			Pressure: 1,
+			Width:    width,
+		})
+	}
+	return line
+}
+
+// renderToImage renders doc with the given eraser mode and rasterizes it at 144 DPI
+func renderToImage(t *testing.T, doc *Document, mode EraserMode) image.Image {
+	t.Helper()
+
+	opts := DefaultRenderOptions()
+	opts.EraserMode = mode
+	data, err := NewRendererWithOptions(opts).RenderToPDF(doc)
+	if err != nil {
+		t.Fatalf("RenderToPDF() error = %v", err)
+	}
+
+	pdfPath := filepath.Join(t.TempDir(), "eraser.pdf")
+	if err := os.WriteFile(pdfPath, data, 0644); err != nil {
+		t.Fatalf("failed to write PDF: %v", err)
+	}
+
+	img, err := pdfrender.New(nil).RenderPage(pdfPath, 1, 144)
+	if err != nil {
+		t.Fatalf("RenderPage() error = %v", err)
+	}
+	return img
+}
+
+// isDark reports whether the pixel at device coordinates (x, y) is closer to black than white
+func isDark(img image.Image, x, y float64) bool {
+	scale := 144.0 / float64(DPI)
+	r, g, b, _ := img.At(int(x*scale), int(y*scale)).RGBA()
+	return (r+g+b)/3 < 0x8000
+}
+
+func TestRenderEraser_Modes(t *testing.T) {
+	// A black horizontal line crossed by a 40px-wide vertical eraser stroke drawn after it
+	doc := &Document{
+		Version: Version6,
+		Layers: []Layer{{Lines: []Line{
+			lineThrough(BrushBallpoint, 200, 900, 1200, 900, 0),
+			lineThrough(BrushEraser, 700, 700, 700, 1100, 40),
+		}}},
+	}
+
+	tests := []struct {
+		mode             EraserMode
+		wantIntersection bool // whether ink remains where the eraser crosses
+	}{
+		{mode: EraserIgnore, wantIntersection: true},
+		{mode: EraserCover, wantIntersection: false},
+		{mode: EraserClip, wantIntersection: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode.String(), func(t *testing.T) {
+			img := renderToImage(t, doc, tt.mode)
+
+			if !isDark(img, 400, 900) {
+				t.Error("ink away from the eraser should remain")
+			}
+			if got := isDark(img, 700, 900); got != tt.wantIntersection {
+				t.Errorf("ink at intersection = %v, want %v", got, tt.wantIntersection)
+			}
+			if isDark(img, 700, 1000) {
+				t.Error("eraser stroke should not draw ink")
+			}
+		})
+	}
+}
+
+func TestClipErasedStrokes(t *testing.T) {
+	ink := lineThrough(BrushBallpoint, 0, 100, 1000, 100, 0)
+	eraser := lineThrough(BrushEraser, 500, 0, 500, 200, 40)
+
+	// The eraser splits the earlier line into two pieces and is itself dropped
+	lines := clipErasedStrokes([]Line{ink, eraser})
+	if len(lines) != 2 {
+		t.Fatalf("got %d strokes, want 2", len(lines))
+	}
+	for _, line := range lines {
+		if line.BrushType != BrushBallpoint {
+			t.Errorf("unexpected stroke %v in result", line.BrushType)
+		}
+		for _, p := range line.Points {
+			if p.X > 480 && p.X < 520 {
+				t.Errorf("point %+v should have been erased", p)
+			}
+		}
+	}
+	if lines[0].Points[len(lines[0].Points)-1].X != 400 || lines[1].Points[0].X != 600 {
+		t.Errorf("pieces end at %v and start at %v, want 400 and 600",
+			lines[0].Points[len(lines[0].Points)-1].X, lines[1].Points[0].X)
+	}
+
+	// Ink drawn after the eraser is untouched
+	lines = clipErasedStrokes([]Line{eraser, ink})
+	if len(lines) != 1 || len(lines[0].Points) != len(ink.Points) {
+		t.Errorf("stroke drawn after the eraser should be kept whole, got %d strokes", len(lines))
+	}
+}
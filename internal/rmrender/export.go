@@ -0,0 +1,266 @@
+package rmrender
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"strconv"
+
+	"github.com/signintech/gopdf"
+	"golang.org/x/image/vector"
+)
+
+// RenderToPNG renders a parsed Document to a PNG image at the given resolution
+//
+// The image covers the whole page, so at the reMarkable's native 226 DPI it is
+// 1404x1872 pixels. Strokes are anti-aliased and semi-transparent brushes are
+// composited as in PDF output, including the highlighter blend mode.
+func (r *Renderer) RenderToPNG(doc *Document, dpi int) ([]byte, error) {
+	img, err := r.RenderToImage(doc, dpi)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode PNG: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// RenderToImage renders a parsed Document to an image at the given resolution
+//
+// The image is the same as the one encoded by RenderToPNG.
+func (r *Renderer) RenderToImage(doc *Document, dpi int) (*image.RGBA, error) {
+	if doc == nil {
+		return nil, fmt.Errorf("document cannot be nil")
+	}
+	if dpi <= 0 {
+		return nil, fmt.Errorf("dpi must be positive, got %d", dpi)
+	}
+
+	return r.rasterize(doc, float64(dpi)/float64(DPI)), nil
+}
+
+// RenderToSVG renders a parsed Document to an SVG image
+//
+// Each stroke becomes one <path> element in reMarkable pixel coordinates. SVG paths
+// have a single width, so pressure-sensitive strokes use their average width.
+func (r *Renderer) RenderToSVG(doc *Document) ([]byte, error) {
+	if doc == nil {
+		return nil, fmt.Errorf("document cannot be nil")
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+		Width, Height, Width, Height)
+	fmt.Fprintf(&buf, `<rect width="100%%" height="100%%" fill="%s"/>`+"\n", hexColor(r.options.BackgroundColor.RGB()))
+
+	for _, stroke := range r.strokesToDraw(doc) {
+		if len(stroke.Points) < 2 {
+			continue
+		}
+
+		red, green, blue, alpha := r.strokeStyle(stroke)
+
+		var d bytes.Buffer
+		var totalWidth float64
+		for i, p := range stroke.Points {
+			if i == 0 {
+				d.WriteString("M")
+			} else {
+				d.WriteString(" L")
+				totalWidth += r.strokeWidthPixels(stroke, stroke.Points[i-1])
+			}
+			fmt.Fprintf(&d, "%s %s", svgNumber(float64(p.X)), svgNumber(float64(p.Y)))
+		}
+		width := totalWidth / float64(len(stroke.Points)-1)
+
+		fmt.Fprintf(&buf, `<path d="%s" fill="none" stroke="%s" stroke-width="%s" stroke-linecap="round" stroke-linejoin="round"`,
+			d.String(), hexColor(red, green, blue), svgNumber(width))
+		if alpha < 1 {
+			fmt.Fprintf(&buf, ` stroke-opacity="%s"`, svgNumber(float64(alpha)))
+			if r.blendMode(stroke.BrushType) == gopdf.Multiply {
+				buf.WriteString(` style="mix-blend-mode:multiply"`)
+			}
+		}
+		buf.WriteString("/>\n")
+	}
+
+	buf.WriteString("</svg>\n")
+	return buf.Bytes(), nil
+}
+
+// strokeStyle returns the color and opacity a stroke is drawn with; erasers draw
+// opaquely in the background color
+func (r *Renderer) strokeStyle(stroke Line) (red, green, blue uint8, alpha float32) {
+	if stroke.BrushType.IsEraser() {
+		red, green, blue = r.options.BackgroundColor.RGB()
+		return red, green, blue, 1
+	}
+	return r.applyBrushStyle(stroke.BrushType, stroke.Color)
+}
+
+// strokeWidthPixels returns the width of stroke at p in reMarkable pixels
+func (r *Renderer) strokeWidthPixels(stroke Line, p Point) float64 {
+	if stroke.BrushType.IsEraser() {
+		return eraserWidth(p, stroke.BrushSize)
+	}
+	// calculateStrokeWidth returns PDF points
+	return float64(r.calculateStrokeWidth(p, stroke.BrushSize, stroke.BrushType)) * float64(DPI) / 72.0
+}
+
+// rasterize draws doc onto a new image, scaling reMarkable pixels by scale
+func (r *Renderer) rasterize(doc *Document, scale float64) *image.RGBA {
+	bounds := image.Rect(0, 0, int(math.Round(Width*scale)), int(math.Round(Height*scale)))
+	img := image.NewRGBA(bounds)
+
+	red, green, blue := r.options.BackgroundColor.RGB()
+	draw.Draw(img, bounds, image.NewUniform(color.RGBA{red, green, blue, 255}), image.Point{}, draw.Src)
+
+	for _, stroke := range r.strokesToDraw(doc) {
+		r.rasterizeStroke(img, stroke, scale)
+	}
+
+	return img
+}
+
+// rasterizeStroke draws one stroke onto img as segments joined by round caps
+//
+// The stroke's coverage is built in a mask first so that overlapping segments of a
+// semi-transparent stroke don't darken each other.
+func (r *Renderer) rasterizeStroke(img *image.RGBA, stroke Line, scale float64) {
+	if len(stroke.Points) < 2 {
+		return
+	}
+
+	// Scaled points and the width of the segment starting at each point
+	n := len(stroke.Points)
+	xs, ys, widths := make([]float64, n), make([]float64, n), make([]float64, n)
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	maxWidth := 0.0
+	for i, p := range stroke.Points {
+		xs[i], ys[i] = float64(p.X)*scale, float64(p.Y)*scale
+		if i < n-1 {
+			widths[i] = r.strokeWidthPixels(stroke, p) * scale
+		} else {
+			widths[i] = widths[i-1]
+		}
+		minX, maxX = math.Min(minX, xs[i]), math.Max(maxX, xs[i])
+		minY, maxY = math.Min(minY, ys[i]), math.Max(maxY, ys[i])
+		maxWidth = math.Max(maxWidth, widths[i])
+	}
+
+	// Rasterize only the stroke's bounding box
+	pad := maxWidth/2 + 1
+	box := image.Rect(
+		int(math.Floor(minX-pad)), int(math.Floor(minY-pad)),
+		int(math.Ceil(maxX+pad)), int(math.Ceil(maxY+pad)),
+	).Intersect(img.Bounds())
+	if box.Empty() {
+		return
+	}
+
+	z := vector.NewRasterizer(box.Dx(), box.Dy())
+	ox, oy := float64(box.Min.X), float64(box.Min.Y)
+	for i := 0; i < n; i++ {
+		x, y, half := xs[i]-ox, ys[i]-oy, widths[i]/2
+		addCircle(z, x, y, half)
+
+		if i == n-1 {
+			continue
+		}
+		dx, dy := xs[i+1]-xs[i], ys[i+1]-ys[i]
+		length := math.Hypot(dx, dy)
+		if length == 0 {
+			continue
+		}
+		nx, ny := -dy/length*half, dx/length*half
+		x2, y2 := xs[i+1]-ox, ys[i+1]-oy
+		addPolygon(z, [][2]float64{
+			{x + nx, y + ny}, {x2 + nx, y2 + ny}, {x2 - nx, y2 - ny}, {x - nx, y - ny},
+		})
+	}
+
+	mask := image.NewAlpha(image.Rect(0, 0, box.Dx(), box.Dy()))
+	z.Draw(mask, mask.Bounds(), image.Opaque, image.Point{})
+
+	red, green, blue, alpha := r.strokeStyle(stroke)
+	multiply := alpha < 1 && r.blendMode(stroke.BrushType) == gopdf.Multiply
+	src := [3]float64{float64(red), float64(green), float64(blue)}
+
+	for y := 0; y < box.Dy(); y++ {
+		for x := 0; x < box.Dx(); x++ {
+			coverage := mask.AlphaAt(x, y).A
+			if coverage == 0 {
+				continue
+			}
+			a := float64(alpha) * float64(coverage) / 255
+
+			px, py := box.Min.X+x, box.Min.Y+y
+			dst := img.RGBAAt(px, py)
+			channels := [3]*uint8{&dst.R, &dst.G, &dst.B}
+			for c, ch := range channels {
+				target := src[c]
+				if multiply {
+					target = src[c] * float64(*ch) / 255
+				}
+				*ch = uint8(math.Round(float64(*ch) + (target-float64(*ch))*a))
+			}
+			img.SetRGBA(px, py, dst)
+		}
+	}
+}
+
+// addCircle adds a polygonal circle to z
+func addCircle(z *vector.Rasterizer, cx, cy, radius float64) {
+	if radius <= 0 {
+		return
+	}
+	sides := int(math.Max(8, math.Min(32, math.Ceil(radius*2))))
+	points := make([][2]float64, sides)
+	for i := range points {
+		angle := 2 * math.Pi * float64(i) / float64(sides)
+		points[i] = [2]float64{cx + radius*math.Cos(angle), cy + radius*math.Sin(angle)}
+	}
+	addPolygon(z, points)
+}
+
+// addPolygon adds a closed polygon to z, always with the same winding
+//
+// The rasterizer sums signed coverage, so overlapping shapes wound in opposite
+// directions would cancel out instead of merging.
+func addPolygon(z *vector.Rasterizer, points [][2]float64) {
+	area := 0.0
+	for i, p := range points {
+		q := points[(i+1)%len(points)]
+		area += p[0]*q[1] - q[0]*p[1]
+	}
+	if area < 0 {
+		for i, j := 0, len(points)-1; i < j; i, j = i+1, j-1 {
+			points[i], points[j] = points[j], points[i]
+		}
+	}
+
+	z.MoveTo(float32(points[0][0]), float32(points[0][1]))
+	for _, p := range points[1:] {
+		z.LineTo(float32(p[0]), float32(p[1]))
+	}
+	z.ClosePath()
+}
+
+// hexColor formats an RGB color as #rrggbb
+func hexColor(red, green, blue uint8) string {
+	return fmt.Sprintf("#%02x%02x%02x", red, green, blue)
+}
+
+// svgNumber formats v with at most two decimal places
+func svgNumber(v float64) string {
+	return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+}
@@ -0,0 +1,133 @@
+package rmrender
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+// exportTestDocument has two ink strokes in layer 0 and a gray stroke in layer 1
+func exportTestDocument() *Document {
+	gray := lineThrough(BrushFineliner, 200, 1500, 1200, 1500, 0)
+	gray.Color = ColorGray
+
+	return &Document{
+		Version: Version6,
+		Layers: []Layer{
+			{Lines: []Line{
+				lineThrough(BrushBallpoint, 200, 300, 1200, 300, 0),
+				lineThrough(BrushMarker, 700, 500, 700, 1000, 0),
+			}},
+			{Lines: []Line{gray}},
+		},
+	}
+}
+
+func TestRenderToPNG(t *testing.T) {
+	tests := []struct {
+		name       string
+		layers     []int
+		wantLayer0 bool
+	}{
+		{name: "all layers", wantLayer0: true},
+		{name: "layer 1 only", layers: []int{1}, wantLayer0: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultRenderOptions()
+			opts.RenderLayers = tt.layers
+
+			data, err := NewRendererWithOptions(opts).RenderToPNG(exportTestDocument(), 113)
+			if err != nil {
+				t.Fatalf("RenderToPNG() error = %v", err)
+			}
+
+			img, err := png.Decode(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("failed to decode PNG: %v", err)
+			}
+			if got := img.Bounds().Size(); got.X != 702 || got.Y != 936 {
+				t.Fatalf("image size = %v, want 702x936", got)
+			}
+
+			// Count pixels that differ from the white background
+			ink := 0
+			for y := 0; y < 936; y++ {
+				for x := 0; x < 702; x++ {
+					if r, g, b, _ := img.At(x, y).RGBA(); r != 0xffff || g != 0xffff || b != 0xffff {
+						ink++
+					}
+				}
+			}
+			if ink == 0 {
+				t.Fatal("rendered image has no ink")
+			}
+
+			// Coordinates are in reMarkable pixels, halved at 113 DPI
+			dark := func(x, y int) bool {
+				r, g, b, _ := img.At(x/2, y/2).RGBA()
+				return (r+g+b)/3 < 0xc000
+			}
+			if got := dark(400, 300); got != tt.wantLayer0 {
+				t.Errorf("layer 0 ink drawn = %v, want %v", got, tt.wantLayer0)
+			}
+			if !dark(400, 1500) {
+				t.Error("layer 1 ink should be drawn")
+			}
+			if dark(400, 1000) {
+				t.Error("page away from strokes should be background")
+			}
+		})
+	}
+}
+
+func TestRenderToPNG_InvalidInput(t *testing.T) {
+	r := NewRenderer()
+	if _, err := r.RenderToPNG(nil, 100); err == nil {
+		t.Error("RenderToPNG() should reject a nil document")
+	}
+	if _, err := r.RenderToPNG(exportTestDocument(), 0); err == nil {
+		t.Error("RenderToPNG() should reject a non-positive DPI")
+	}
+}
+
+func TestRenderToSVG(t *testing.T) {
+	data, err := NewRenderer().RenderToSVG(exportTestDocument())
+	if err != nil {
+		t.Fatalf("RenderToSVG() error = %v", err)
+	}
+	svg := string(data)
+
+	if !strings.HasPrefix(svg, "<svg ") || !strings.HasSuffix(svg, "</svg>\n") {
+		t.Errorf("output is not an SVG document:\n%s", svg)
+	}
+	if got := strings.Count(svg, "<path "); got != 3 {
+		t.Errorf("SVG has %d paths, want 3", got)
+	}
+	if !strings.Contains(svg, `d="M200 300 L300 300`) {
+		t.Error("SVG path should use reMarkable coordinates")
+	}
+	if !strings.Contains(svg, `stroke="#7d7d7d"`) {
+		t.Error("gray stroke color not preserved")
+	}
+	if !strings.Contains(svg, `stroke-opacity="0.8"`) {
+		t.Error("marker opacity not preserved")
+	}
+
+	// Fineliner at brush size 2: 2*2*0.7 = 2.8 points, or 8.79 pixels at 226 DPI
+	if !strings.Contains(svg, `stroke-width="8.79"`) {
+		t.Error("fineliner width not preserved")
+	}
+
+	opts := DefaultRenderOptions()
+	opts.RenderLayers = []int{0}
+	data, err = NewRendererWithOptions(opts).RenderToSVG(exportTestDocument())
+	if err != nil {
+		t.Fatalf("RenderToSVG() error = %v", err)
+	}
+	if got := strings.Count(string(data), "<path "); got != 2 {
+		t.Errorf("SVG of layer 0 has %d paths, want 2", got)
+	}
+}
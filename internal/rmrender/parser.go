@@ -4,19 +4,38 @@ package rmrender
 import (
 	"bytes"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"io"
+	"math"
 	"os"
 )
 
+// maxBrushSize is the largest brush size accepted from parsed stroke data
+const maxBrushSize = 100.0
+
 // Parser handles parsing of .rm binary files
 type Parser struct {
 	version Version
+	options *RenderOptions
 }
 
-// NewParser creates a new .rm file parser
+// NewParser creates a new .rm file parser with default fallback stroke styling
 func NewParser() *Parser {
-	return &Parser{}
+	return &Parser{
+		options: DefaultRenderOptions(),
+	}
+}
+
+// NewParserWithOptions creates a parser that uses the fallback brush, color,
+// and size from opts for strokes whose styling cannot be determined
+func NewParserWithOptions(opts *RenderOptions) *Parser {
+	if opts == nil {
+		opts = DefaultRenderOptions()
+	}
+	return &Parser{
+		options: opts,
+	}
 }
 
 // Parse parses a .rm file from bytes and returns a Document
@@ -75,133 +94,158 @@ func (p *Parser) parseHeader(reader io.Reader) (Version, error) {
 }
 
 // parseV3 parses a version 3 .rm file
-//
-// Version 3 format is documented and can use ddvk/rmapi/encoding/rm
-// as a reference implementation.
-func (p *Parser) parseV3(_ io.Reader) (*Document, error) {
-	// TODO: Implement v3 parsing
-	// Can reference ddvk/rmapi/encoding/rm for implementation
-	return nil, fmt.Errorf("version 3 parsing not yet implemented")
+func (p *Parser) parseV3(reader io.Reader) (*Document, error) {
+	return p.parseLegacy(reader, Version3)
 }
 
 // parseV5 parses a version 5 .rm file
-func (p *Parser) parseV5(_ io.Reader) (*Document, error) {
-	// TODO: Implement v5 parsing
-	// Format is similar to v6 but with some differences
-	return nil, fmt.Errorf("version 5 parsing not yet implemented")
+func (p *Parser) parseV5(reader io.Reader) (*Document, error) {
+	return p.parseLegacy(reader, Version5)
+}
+
+// parseLegacy parses the fixed-layout records shared by versions 3 and 5
+//
+// After the header: layer count (u32), then per layer a line count (u32), then per line
+// brush type, color, padding (u32), brush size (f32), an extra unknown f32 in version 5,
+// and a point count (u32) followed by 24-byte points (x, y, speed, direction, width,
+// pressure as f32). See ddvk/rmapi/encoding/rm for the reference implementation.
+func (p *Parser) parseLegacy(reader io.Reader, version Version) (*Document, error) {
+	doc := &Document{
+		Version: version,
+		Layers:  []Layer{},
+	}
+
+	numLayers, err := readUint32(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read layer count: %w", err)
+	}
+
+	// Counts are untrusted, so records are appended as read rather than preallocated
+	for i := uint32(0); i < numLayers; i++ {
+		numLines, err := readUint32(reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read line count for layer %d: %w", i, err)
+		}
+
+		layer := Layer{Lines: []Line{}}
+		for j := uint32(0); j < numLines; j++ {
+			line, err := p.readLegacyLine(reader, version)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read line %d of layer %d: %w", j, i, err)
+			}
+			layer.Lines = append(layer.Lines, line)
+		}
+		doc.Layers = append(doc.Layers, layer)
+	}
+
+	return doc, nil
+}
+
+// readLegacyLine reads a single v3/v5 line record and its points
+func (p *Parser) readLegacyLine(reader io.Reader, version Version) (Line, error) {
+	header := make([]byte, 16)
+	if version == Version5 {
+		header = make([]byte, 20)
+	}
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return Line{}, err
+	}
+
+	brushType := binary.LittleEndian.Uint32(header[0:])
+	color := binary.LittleEndian.Uint32(header[4:])
+	brushSize := math.Float32frombits(binary.LittleEndian.Uint32(header[12:]))
+
+	numPoints, err := readUint32(reader)
+	if err != nil {
+		return Line{}, err
+	}
+
+	// Prefer the stored styling, filling in anything unrecognized from the fallback
+	line := p.fallbackLine()
+	if brush := normalizeBrush(brushType); brush.IsKnown() {
+		line.BrushType = brush
+	}
+	if c := Color(color); c.IsKnown() {
+		line.Color = c
+	}
+	if validBrushSize(brushSize) {
+		line.BrushSize = brushSize
+	}
+
+	line.Points = []Point{}
+	point := make([]byte, 24)
+	for k := uint32(0); k < numPoints; k++ {
+		if _, err := io.ReadFull(reader, point); err != nil {
+			return Line{}, fmt.Errorf("failed to read point %d: %w", k, err)
+		}
+		line.Points = append(line.Points, decodeFloatPoint(point))
+	}
+
+	return line, nil
 }
 
 // parseV6 parses a version 6 .rm file
 //
 // Version 6 is the current format used by reMarkable tablets (as of Dec 2025).
-// Format uses a tag-based system with magic delimiter bytes.
+// After the header the file is a sequence of blocks, each holding tagged values.
+// Layers are scene tree groups beneath the root node; their names come from tree
+// node blocks, and each line item block names the layer it belongs to.
 //
-// This is a simplified parser that scans for recognizable stroke patterns.
-// For a complete implementation, see:
-// - https://github.com/YakBarber/remarkable_file_format (Kaitai Struct spec)
-// - https://github.com/ricklupton/rmscene (Python implementation)
+// See https://github.com/ricklupton/rmscene for the reference implementation.
 func (p *Parser) parseV6(reader io.Reader) (*Document, error) {
 	doc := &Document{
 		Version: Version6,
 		Layers:  []Layer{},
 	}
 
-	// Read all remaining data into memory for scanning
-	data, err := io.ReadAll(reader)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read file data: %w", err)
-	}
-
-	// For now, create a single layer and scan for stroke patterns
-	layer := Layer{Lines: []Line{}}
-
-	// Scan through the data looking for stroke patterns
-	// Strokes typically have: coordinates (floats), pressure data, etc.
-	// This is a simplified approach - a full parser would use the tag structure
+	layerIndex := make(map[string]int)
+	names := make(map[string]string)
 
-	// Look for sequences that resemble coordinate data:
-	// - Floats in range 0-1500 for X (reMarkable width is 1404)
-	// - Floats in range 0-2000 for Y (reMarkable height is 1872)
+	// layerFor returns the index of a layer, adding it in first-seen order
+	layerFor := func(id string) int {
+		if i, ok := layerIndex[id]; ok {
+			return i
+		}
+		layerIndex[id] = len(doc.Layers)
+		doc.Layers = append(doc.Layers, Layer{ID: id, Lines: []Line{}})
+		return layerIndex[id]
+	}
 
-	i := 0
-	for i < len(data)-14 { // Need at least 14 bytes for a point
-		// Try to read potential point data
-		if i+13 >= len(data) {
+	for {
+		block, err := p.readV6Block(reader)
+		if err == io.EOF {
 			break
 		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read block: %w", err)
+		}
 
-		pointReader := bytes.NewReader(data[i : i+14])
-		x, _ := readFloat32(pointReader)
-		y, _ := readFloat32(pointReader)
-
-		// Check if this looks like a valid coordinate
-		if x >= 0 && x <= 1500 && y >= 0 && y <= 2000 {
-			// This might be a point - try to read the rest
-			speed, _ := readUint8(pointReader)
-			width, _ := readUint8(pointReader)
-			direction, _ := readUint8(pointReader)
-			pressure, _ := readUint8(pointReader)
-
-			// Collect points that form a stroke
-			// For simplicity, we'll group consecutive valid points
-			points := []Point{
-				{
-					X:         x,
-					Y:         y,
-					Speed:     float32(speed) / 255.0,
-					Width:     float32(width),
-					Direction: float32(direction) / 255.0 * 360.0,
-					Pressure:  float32(pressure) / 255.0,
-				},
+		switch block.Type {
+		case v6BlockTreeNode:
+			id, name, err := parseV6TreeNode(block.Body)
+			if err == nil {
+				names[id] = name
 			}
 
-			// Try to read more points
-			j := i + 14
-			for j < len(data)-14 && len(points) < 1000 {
-				pointReader := bytes.NewReader(data[j : j+14])
-				nextX, _ := readFloat32(pointReader)
-				nextY, _ := readFloat32(pointReader)
-
-				if nextX >= 0 && nextX <= 1500 && nextY >= 0 && nextY <= 2000 {
-					speed, _ := readUint8(pointReader)
-					width, _ := readUint8(pointReader)
-					direction, _ := readUint8(pointReader)
-					pressure, _ := readUint8(pointReader)
-
-					points = append(points, Point{
-						X:         nextX,
-						Y:         nextY,
-						Speed:     float32(speed) / 255.0,
-						Width:     float32(width),
-						Direction: float32(direction) / 255.0 * 360.0,
-						Pressure:  float32(pressure) / 255.0,
-					})
-					j += 14
-				} else {
-					break
-				}
+		case v6BlockGroupItem:
+			// Deleted or malformed groups don't define a layer
+			if layerID, err := p.extractLayerID(block.Body); err == nil {
+				layerFor(layerID)
 			}
 
-			// If we have at least 2 points, create a stroke
-			if len(points) >= 2 {
-				line := Line{
-					BrushType: BrushBallpoint, // Default brush
-					Color:     ColorBlack,     // Default color
-					BrushSize: 2.0,            // Default size
-					Points:    points,
-				}
-				layer.Lines = append(layer.Lines, line)
-				i = j
+		case v6BlockLineItem:
+			// Deleted or malformed lines are skipped
+			line, layerID, err := p.parseV6Line(block.Body, block.CurrentVersion)
+			if err != nil {
 				continue
 			}
+			i := layerFor(layerID)
+			doc.Layers[i].Lines = append(doc.Layers[i].Lines, line)
 		}
-
-		i++
 	}
 
-	// Add layer to document if it has strokes
-	if len(layer.Lines) > 0 {
-		doc.Layers = append(doc.Layers, layer)
+	for i := range doc.Layers {
+		doc.Layers[i].Name = names[doc.Layers[i].ID]
 	}
 
 	return doc, nil
@@ -237,54 +281,51 @@ func readInt32(reader io.Reader) (int32, error) {
 	return value, err
 }
 
-// v6Block represents a single block in the v6 format
-type v6Block struct {
-	LenBody uint32 // Size of the body in bytes
-	Flag    uint32 // Block type identifier
-	Body    []byte // Block payload
-}
+// v6 block types that carry drawing data; other blocks are skipped
+const (
+	v6BlockTreeNode  uint8 = 0x02
+	v6BlockGroupItem uint8 = 0x04
+	v6BlockLineItem  uint8 = 0x05
+)
 
-// skipV6Frontmatter skips the variable-length frontmatter section
-// The v6 format actually uses a tag-based system with magic delimiter bytes
-// For now, we'll read all remaining data and parse it as a whole
-func (p *Parser) skipV6Frontmatter(reader io.Reader) error {
-	// The frontmatter is complex and variable-length
-	// Instead of skipping, we'll just read a byte to check if there's data
-	var test [1]byte
-	_, err := reader.Read(test[:])
-	if err != nil {
-		return err
-	}
+// v6 scene item value types
+const (
+	v6ItemGroup uint8 = 0x02
+	v6ItemLine  uint8 = 0x03
+)
 
-	// Seek back one byte since we just did a test read
-	if seeker, ok := reader.(io.Seeker); ok {
-		_, err = seeker.Seek(-1, io.SeekCurrent)
-		return err
-	}
+// errV6ItemDeleted is returned for scene items that have been deleted and carry no value
+var errV6ItemDeleted = errors.New("scene item deleted")
 
-	return nil
+// v6Block represents a single block in the v6 format
+type v6Block struct {
+	Type           uint8  // Block type identifier
+	MinVersion     uint8  // Oldest reader version able to parse the block
+	CurrentVersion uint8  // Version the block was written with
+	Body           []byte // Block payload
 }
 
 // readV6Block reads a single block from the v6 format
+//
+// Header: len_body (u32) + unknown (u8) + min_version (u8) + current_version (u8) + block_type (u8)
+// Returns io.EOF when there are no more blocks.
 func (p *Parser) readV6Block(reader io.Reader) (*v6Block, error) {
-	block := &v6Block{}
-
-	// Read block header: len_body (u4) + flag (u4)
-	lenBody, err := readUint32(reader)
-	if err != nil {
+	header := make([]byte, 8)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return nil, fmt.Errorf("truncated block header: %w", err)
+		}
 		return nil, err
 	}
 
-	flag, err := readUint32(reader)
-	if err != nil {
-		return nil, err
+	block := &v6Block{
+		MinVersion:     header[5],
+		CurrentVersion: header[6],
+		Type:           header[7],
 	}
 
-	block.LenBody = lenBody
-	block.Flag = flag
-
 	// Read block body
-	if lenBody > 0 {
+	if lenBody := binary.LittleEndian.Uint32(header[:4]); lenBody > 0 {
 		block.Body = make([]byte, lenBody)
 		if _, err := io.ReadFull(reader, block.Body); err != nil {
 			return nil, fmt.Errorf("failed to read block body: %w", err)
@@ -294,126 +335,222 @@ func (p *Parser) readV6Block(reader io.Reader) (*v6Block, error) {
 	return block, nil
 }
 
-// extractLayerID extracts the layer ID from a layer definition block
-func (p *Parser) extractLayerID(body []byte) string {
-	// Layer definition blocks contain magic delimiters and the layer ID
-	// Format: magic bytes + layer_id (null-terminated string) + metadata
-	// We'll scan for printable characters between magic delimiters
+// readV6SceneItem reads the header shared by scene item blocks and returns the
+// item's parent node, its value type, and a reader positioned at the value
+func readV6SceneItem(body []byte) (crdtID, uint8, *tagReader, error) {
+	r := newTagReader(body)
 
-	if len(body) < 10 {
-		return ""
+	parentID, err := r.readID(1)
+	if err != nil {
+		return crdtID{}, 0, nil, err
 	}
-
-	// Look for null-terminated string
-	for i := 0; i < len(body)-1; i++ {
-		if body[i] >= 0x20 && body[i] <= 0x7E { // Printable ASCII
-			// Found start of potential ID
-			end := i
-			for end < len(body) && body[end] != 0x00 && body[end] >= 0x20 && body[end] <= 0x7E {
-				end++
-			}
-			if end > i {
-				return string(body[i:end])
-			}
+	for index := uint64(2); index <= 4; index++ { // item, left and right IDs
+		if _, err := r.readID(index); err != nil {
+			return crdtID{}, 0, nil, err
 		}
 	}
+	if _, err := r.readUint32(5); err != nil { // deleted_length
+		return crdtID{}, 0, nil, err
+	}
+
+	if !r.hasTag(6, tagLength4) {
+		return parentID, 0, nil, errV6ItemDeleted
+	}
+	value, err := r.readSubblock(6)
+	if err != nil {
+		return crdtID{}, 0, nil, err
+	}
+	itemType, err := value.readByte()
+	if err != nil {
+		return crdtID{}, 0, nil, err
+	}
 
-	return ""
+	return parentID, itemType, value, nil
 }
 
-// parseV6Line parses a line/stroke block
-// Returns: Line, layerID, error
-func (p *Parser) parseV6Line(body []byte) (Line, string, error) {
-	line := Line{}
-	reader := bytes.NewReader(body)
-
-	// Parse line header
-	// Format (approximate, needs adjustment based on actual data):
-	// - Magic delimiters
-	// - layer_id (null-terminated string)
-	// - line_id
-	// - last_line_id
-	// - done_flag (u4) - should be 0 for actual stroke data
-	// - pen_type (u4)
-	// - color (u4)
-	// - brush_size (f4)
-	// - len_point_array (u4)
-
-	// Skip magic bytes and extract layer ID
-	layerID := ""
-	for i := 0; i < len(body)-20; i++ {
-		if body[i] >= 0x20 && body[i] <= 0x7E {
-			end := i
-			for end < len(body) && body[end] != 0x00 && body[end] >= 0x20 && body[end] <= 0x7E {
-				end++
-			}
-			if end > i {
-				layerID = string(body[i:end])
-				// Seek past this string (best effort)
-				_, _ = reader.Seek(int64(end+1), io.SeekStart)
-				break
-			}
-		}
+// parseV6TreeNode reads a tree node block, returning the node ID and its label
+func parseV6TreeNode(body []byte) (string, string, error) {
+	r := newTagReader(body)
+
+	nodeID, err := r.readID(1)
+	if err != nil {
+		return "", "", err
 	}
 
-	// Look for line header pattern
-	// We need to find: pen_type (u4), color (u4), brush_size (f4), len_point_array (u4)
-	// This is tricky without exact offsets, so we'll use heuristics
+	// The label is a last-write-wins value: timestamp ID, then the string
+	label, err := r.readSubblock(2)
+	if err != nil {
+		return "", "", err
+	}
+	if _, err := label.readID(1); err != nil {
+		return "", "", err
+	}
+	name, err := label.readString(2)
+	if err != nil {
+		return "", "", err
+	}
+
+	return nodeID.String(), name, nil
+}
+
+// extractLayerID extracts the layer ID from a group item block
+//
+// Group items beneath the root node place a layer in the scene; the value is the
+// ID of the tree node that line items reference as their parent.
+func (p *Parser) extractLayerID(body []byte) (string, error) {
+	_, itemType, value, err := readV6SceneItem(body)
+	if err != nil {
+		return "", err
+	}
+	if itemType != v6ItemGroup {
+		return "", fmt.Errorf("unexpected group item type 0x%02x", itemType)
+	}
+
+	nodeID, err := value.readID(2)
+	if err != nil {
+		return "", err
+	}
+	return nodeID.String(), nil
+}
+
+// parseV6Line parses a line item block written with the given block version
+// Returns: Line, layerID, error
+//
+// Line value: tool (u32), color (u32), thickness scale (f64), starting length (f32),
+// then a point array subblock. Version 1 points are six f32 values (24 bytes);
+// later versions pack x, y (f32), speed, width (u16), direction, pressure (u8) in 14 bytes.
+func (p *Parser) parseV6Line(body []byte, version uint8) (Line, string, error) {
+	parentID, itemType, r, err := readV6SceneItem(body)
+	if err != nil {
+		return Line{}, "", err
+	}
+	if itemType != v6ItemLine {
+		return Line{}, "", fmt.Errorf("unexpected line item type 0x%02x", itemType)
+	}
 
-	// For now, use simplified parsing
-	// Skip to a reasonable offset where header data starts
-	if _, err := reader.Seek(20, io.SeekCurrent); err != nil {
-		return line, layerID, err
+	tool, err := r.readUint32(1)
+	if err != nil {
+		return Line{}, "", err
+	}
+	color, err := r.readUint32(2)
+	if err != nil {
+		return Line{}, "", err
+	}
+	thickness, err := r.readFloat64(3)
+	if err != nil {
+		return Line{}, "", err
+	}
+	if _, err := r.readFloat32(4); err != nil { // starting length
+		return Line{}, "", err
+	}
+	points, err := r.readSubblock(5)
+	if err != nil {
+		return Line{}, "", err
 	}
 
-	// Try to read pen type, color, brush size
-	penType, _ := readUint32(reader)
-	color, _ := readUint32(reader)
-	brushSize, _ := readFloat32(reader)
-	lenPointArray, _ := readUint32(reader)
+	// Prefer the stored styling, filling in anything unrecognized from the fallback
+	line := p.fallbackLine()
+	if brush := normalizeBrush(tool); brush.IsKnown() {
+		line.BrushType = brush
+	}
+	if c := Color(color); c.IsKnown() {
+		line.Color = c
+	}
+	if size := float32(thickness); validBrushSize(size) {
+		line.BrushSize = size
+	}
 
-	line.BrushType = BrushType(penType)
-	line.Color = Color(color)
-	line.BrushSize = brushSize
+	pointSize := 14
+	if version == 1 {
+		pointSize = 24
+	}
+	line.Points = make([]Point, 0, points.remaining()/pointSize)
+	for points.remaining() >= pointSize {
+		b, _ := points.readBytes(pointSize)
+		line.Points = append(line.Points, decodeV6Point(b, version))
+	}
 
-	// Parse points - each point is 14 bytes
-	// x (f4) + y (f4) + speed (u1) + width (u1) + direction (u1) + pressure (u1) + padding (2 bytes)
-	numPoints := lenPointArray / 14
-	if numPoints > 0 && numPoints < 10000 { // Sanity check
-		line.Points = make([]Point, 0, numPoints)
+	return line, parentID.String(), nil
+}
 
-		for i := uint32(0); i < numPoints; i++ {
-			point := Point{}
+// decodeV6Point decodes a single point, normalizing pressure to 0-1 and direction to degrees
+//
+// v6 stores X relative to the horizontal center of the page; it is shifted so X runs
+// from 0 to Width like the other formats.
+func decodeV6Point(b []byte, version uint8) Point {
+	if version == 1 {
+		point := decodeFloatPoint(b)
+		point.X += Width / 2
+		return point
+	}
 
-			x, err := readFloat32(reader)
-			if err != nil {
-				break
-			}
-			y, err := readFloat32(reader)
-			if err != nil {
-				break
-			}
+	return Point{
+		X:         math.Float32frombits(binary.LittleEndian.Uint32(b[0:])) + Width/2,
+		Y:         math.Float32frombits(binary.LittleEndian.Uint32(b[4:])),
+		Speed:     float32(binary.LittleEndian.Uint16(b[8:])) / 4,
+		Width:     float32(binary.LittleEndian.Uint16(b[10:])) / 4,
+		Direction: float32(b[12]) / 255.0 * 360.0,
+		Pressure:  float32(b[13]) / 255.0,
+	}
+}
 
-			speed, _ := readUint8(reader)
-			width, _ := readUint8(reader)
-			direction, _ := readUint8(reader)
-			pressure, _ := readUint8(reader)
+// decodeFloatPoint decodes a 24-byte point of six f32 values: x, y, speed,
+// direction (radians, converted to degrees), width, and pressure (0-1)
+func decodeFloatPoint(b []byte) Point {
+	f32 := func(offset int) float32 {
+		return math.Float32frombits(binary.LittleEndian.Uint32(b[offset:]))
+	}
 
-			// Skip padding (2 bytes)
-			_, _ = reader.Seek(2, io.SeekCurrent)
+	return Point{
+		X:         f32(0),
+		Y:         f32(4),
+		Speed:     f32(8),
+		Direction: f32(12) / (2 * math.Pi) * 360.0,
+		Width:     f32(16),
+		Pressure:  f32(20),
+	}
+}
 
-			point.X = x
-			point.Y = y
-			point.Speed = float32(speed) / 255.0
-			point.Width = float32(width)
-			point.Direction = float32(direction) / 255.0 * 360.0 // Convert to degrees
-			point.Pressure = float32(pressure) / 255.0
+// normalizeBrush maps the second-generation tool IDs written by newer firmware
+// onto the equivalent original brush types
+func normalizeBrush(tool uint32) BrushType {
+	switch tool {
+	case 12:
+		return BrushBrush
+	case 13:
+		return BrushSharpPencil
+	case 14:
+		return BrushTiltPencil
+	case 15:
+		return BrushBallpoint
+	case 16:
+		return BrushMarker
+	case 17:
+		return BrushFineliner
+	case 18:
+		return BrushHighlighter
+	default:
+		return BrushType(tool)
+	}
+}
 
-			line.Points = append(line.Points, point)
-		}
+// fallbackLine returns an empty line styled with the configured fallback brush, color, and size
+func (p *Parser) fallbackLine() Line {
+	opts := p.options
+	if opts == nil {
+		opts = DefaultRenderOptions()
 	}
+	return Line{
+		BrushType: opts.FallbackBrush,
+		Color:     opts.FallbackColor,
+		BrushSize: opts.FallbackBrushSize,
+	}
+}
 
-	return line, layerID, nil
+// validBrushSize reports whether a parsed brush size is plausible
+func validBrushSize(size float32) bool {
+	f := float64(size)
+	return !math.IsNaN(f) && f > 0 && f <= maxBrushSize
 }
 
 // readUint8 reads an 8-bit unsigned integer
@@ -2,6 +2,8 @@ package rmrender
 
 import (
 	"bytes"
+	"encoding/binary"
+	"os"
 	"testing"
 )
 
@@ -93,6 +95,57 @@ func TestParseV6_SecondExampleFile(t *testing.T) {
 	t.Logf("Total: %d strokes, %d points", totalStrokes, totalPoints)
 }
 
+func TestParseV6_Golden(t *testing.T) {
+	tests := []struct {
+		file       string
+		wantLayers []string
+		wantLines  int
+		wantPoints int
+	}{
+		{"7ac5c320-e3e5-4c6c-8adc-204662ee929a.rm", []string{"Layer 1"}, 3, 272},
+		{"aefd8acc-a17d-4e24-a76c-66a3ee15b4ba.rm", []string{"Layer 1"}, 33, 1177},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			doc, err := ParseFile("../../example/b68e57f6-4fc9-4a71-b300-e0fa100ef8d7/" + tt.file)
+			if err != nil {
+				t.Fatalf("ParseFile() error = %v", err)
+			}
+
+			if len(doc.Layers) != len(tt.wantLayers) {
+				t.Fatalf("got %d layers, want %d", len(doc.Layers), len(tt.wantLayers))
+			}
+
+			lines, points := 0, 0
+			for i, layer := range doc.Layers {
+				if layer.Name != tt.wantLayers[i] {
+					t.Errorf("layer %d name = %q, want %q", i, layer.Name, tt.wantLayers[i])
+				}
+				lines += len(layer.Lines)
+				for _, line := range layer.Lines {
+					if line.BrushType != BrushBallpoint || line.Color != ColorBlack {
+						t.Errorf("line style = %s/%s, want Ballpoint/Black", line.BrushType, line.Color)
+					}
+					points += len(line.Points)
+					for _, p := range line.Points {
+						if p.X < 0 || p.X > Width || p.Y < 0 || p.Y > Height {
+							t.Fatalf("point (%.1f, %.1f) outside the page", p.X, p.Y)
+						}
+					}
+				}
+			}
+
+			if lines != tt.wantLines {
+				t.Errorf("got %d strokes, want %d", lines, tt.wantLines)
+			}
+			if points != tt.wantPoints {
+				t.Errorf("got %d points, want %d", points, tt.wantPoints)
+			}
+		})
+	}
+}
+
 func TestParseHeader(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -237,3 +290,376 @@ func TestExportMetadata(t *testing.T) {
 		t.Errorf("Expected 30 points, got %v", metadata["point_count"])
 	}
 }
+
+// v6Tag encodes a tag for a field index and type
+func v6Tag(buf *bytes.Buffer, index uint64, tagType byte) {
+	tag := index<<4 | uint64(tagType)
+	for tag >= 0x80 {
+		buf.WriteByte(byte(tag) | 0x80)
+		tag >>= 7
+	}
+	buf.WriteByte(byte(tag))
+}
+
+// v6ID encodes a tagged CRDT ID with a single-byte counter
+func v6ID(buf *bytes.Buffer, index uint64, id crdtID) {
+	v6Tag(buf, index, tagID)
+	buf.WriteByte(id.Author)
+	buf.WriteByte(byte(id.Counter))
+}
+
+// v6Subblock encodes a tagged length-prefixed subblock
+func v6Subblock(buf *bytes.Buffer, index uint64, body []byte) {
+	v6Tag(buf, index, tagLength4)
+	_ = binary.Write(buf, binary.LittleEndian, uint32(len(body)))
+	buf.Write(body)
+}
+
+// v6Item encodes a scene item block body with the given value (nil for a deleted item)
+func v6Item(parent, item crdtID, itemType byte, value []byte) []byte {
+	var body bytes.Buffer
+	v6ID(&body, 1, parent)
+	v6ID(&body, 2, item)
+	v6ID(&body, 3, crdtID{})
+	v6ID(&body, 4, crdtID{})
+	v6Tag(&body, 5, tagByte4)
+	if value == nil {
+		_ = binary.Write(&body, binary.LittleEndian, uint32(1))
+		return body.Bytes()
+	}
+	_ = binary.Write(&body, binary.LittleEndian, uint32(0))
+	v6Subblock(&body, 6, append([]byte{itemType}, value...))
+	return body.Bytes()
+}
+
+// v6LineBody encodes a line item block body with version 2 (14-byte) points
+func v6LineBody(layer crdtID, tool, color uint32, thickness float64, points ...[2]float32) []byte {
+	var value bytes.Buffer
+	v6Tag(&value, 1, tagByte4)
+	_ = binary.Write(&value, binary.LittleEndian, tool)
+	v6Tag(&value, 2, tagByte4)
+	_ = binary.Write(&value, binary.LittleEndian, color)
+	v6Tag(&value, 3, tagByte8)
+	_ = binary.Write(&value, binary.LittleEndian, thickness)
+	v6Tag(&value, 4, tagByte4)
+	_ = binary.Write(&value, binary.LittleEndian, float32(0))
+
+	var pts bytes.Buffer
+	for _, pt := range points {
+		_ = binary.Write(&pts, binary.LittleEndian, pt)
+		_ = binary.Write(&pts, binary.LittleEndian, uint16(8)) // speed
+		_ = binary.Write(&pts, binary.LittleEndian, uint16(8)) // width
+		pts.Write([]byte{0, 255})                              // direction, pressure
+	}
+	v6Subblock(&value, 5, pts.Bytes())
+	v6ID(&value, 6, crdtID{})
+
+	return v6Item(layer, crdtID{Author: 1, Counter: 20}, v6ItemLine, value.Bytes())
+}
+
+// v6File assembles a v6 .rm file from block types and bodies
+func v6File(blocks ...v6Block) []byte {
+	var data bytes.Buffer
+	data.WriteString("reMarkable .lines file, version=6          ")
+	for _, block := range blocks {
+		_ = binary.Write(&data, binary.LittleEndian, uint32(len(block.Body)))
+		data.Write([]byte{0, block.MinVersion, block.CurrentVersion, block.Type})
+		data.Write(block.Body)
+	}
+	return data.Bytes()
+}
+
+func TestParseV6_LayersAndStrokes(t *testing.T) {
+	layer1 := crdtID{Counter: 11}
+	layer2 := crdtID{Counter: 12}
+
+	var treeNode bytes.Buffer
+	v6ID(&treeNode, 1, layer2)
+	var label bytes.Buffer
+	v6ID(&label, 1, crdtID{})
+	v6Subblock(&label, 2, append([]byte{7, 1}, "Sketch!"...))
+	v6Subblock(&treeNode, 2, label.Bytes())
+
+	group := func(id crdtID) []byte {
+		var value bytes.Buffer
+		v6ID(&value, 2, id)
+		return v6Item(rootNodeID, crdtID{Counter: 30 + id.Counter}, v6ItemGroup, value.Bytes())
+	}
+
+	data := v6File(
+		v6Block{Type: 0x09, MinVersion: 1, CurrentVersion: 1, Body: []byte{0x0c, 0, 0, 0, 0}}, // author IDs, skipped
+		v6Block{Type: v6BlockTreeNode, MinVersion: 1, CurrentVersion: 2, Body: treeNode.Bytes()},
+		v6Block{Type: v6BlockGroupItem, MinVersion: 1, CurrentVersion: 1, Body: group(layer1)},
+		v6Block{Type: v6BlockGroupItem, MinVersion: 1, CurrentVersion: 1, Body: group(layer2)},
+		v6Block{Type: v6BlockLineItem, MinVersion: 2, CurrentVersion: 2,
+			Body: v6LineBody(layer2, uint32(BrushHighlighter), uint32(ColorYellow), 3, [2]float32{-100, 50}, [2]float32{100, 60})},
+		v6Block{Type: v6BlockLineItem, MinVersion: 2, CurrentVersion: 2,
+			Body: v6LineBody(layer1, 17, uint32(ColorRed), 2, [2]float32{0, 0}, [2]float32{1, 1}, [2]float32{2, 2})},
+		v6Block{Type: v6BlockLineItem, MinVersion: 2, CurrentVersion: 2,
+			Body: v6Item(layer1, crdtID{Counter: 40}, v6ItemLine, nil)}, // deleted
+	)
+
+	doc, err := NewParser().Parse(data)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if len(doc.Layers) != 2 {
+		t.Fatalf("got %d layers, want 2", len(doc.Layers))
+	}
+	if doc.Layers[0].ID != "0:11" || doc.Layers[1].ID != "0:12" {
+		t.Errorf("layer IDs = %q, %q, want 0:11, 0:12 in declaration order", doc.Layers[0].ID, doc.Layers[1].ID)
+	}
+	if doc.Layers[1].Name != "Sketch!" {
+		t.Errorf("layer 2 name = %q, want Sketch!", doc.Layers[1].Name)
+	}
+
+	if n := len(doc.Layers[0].Lines); n != 1 {
+		t.Fatalf("layer 1 has %d lines, want 1 (deleted line skipped)", n)
+	}
+	fineliner := doc.Layers[0].Lines[0]
+	if fineliner.BrushType != BrushFineliner || fineliner.Color != ColorRed || len(fineliner.Points) != 3 {
+		t.Errorf("layer 1 line = %s/%s with %d points, want Fineliner/Red with 3", fineliner.BrushType, fineliner.Color, len(fineliner.Points))
+	}
+
+	if n := len(doc.Layers[1].Lines); n != 1 {
+		t.Fatalf("layer 2 has %d lines, want 1", n)
+	}
+	highlight := doc.Layers[1].Lines[0]
+	if highlight.BrushType != BrushHighlighter || highlight.Color != ColorYellow || highlight.BrushSize != 3 {
+		t.Errorf("layer 2 line = %s/%s size %.1f, want Highlighter/Yellow size 3", highlight.BrushType, highlight.Color, highlight.BrushSize)
+	}
+	// X is shifted from page-centered to left-aligned
+	want := Point{X: Width/2 - 100, Y: 50, Speed: 2, Width: 2, Pressure: 1}
+	if highlight.Points[0] != want {
+		t.Errorf("first point = %+v, want %+v", highlight.Points[0], want)
+	}
+}
+
+func TestParseV6_TruncatedBlock(t *testing.T) {
+	data := v6File(v6Block{Type: v6BlockLineItem, MinVersion: 2, CurrentVersion: 2,
+		Body: v6LineBody(crdtID{Counter: 11}, 2, 0, 2, [2]float32{0, 0}, [2]float32{1, 1})})
+
+	if _, err := NewParser().Parse(data[:len(data)-5]); err == nil {
+		t.Error("Parse() should error on a truncated block")
+	}
+}
+
+func TestParseV6_FallbackStyleApplied(t *testing.T) {
+	// A stroke whose tool, color, and thickness aren't recognized
+	data := v6File(v6Block{Type: v6BlockLineItem, MinVersion: 2, CurrentVersion: 2,
+		Body: v6LineBody(crdtID{Counter: 11}, 99, 42, -1, [2]float32{100, 200}, [2]float32{110, 210})})
+
+	opts := DefaultRenderOptions()
+	opts.FallbackBrush = BrushHighlighter
+	opts.FallbackColor = ColorYellow
+	opts.FallbackBrushSize = 5.0
+
+	doc, err := NewParserWithOptions(opts).Parse(data)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(doc.Layers) == 0 || len(doc.Layers[0].Lines) == 0 {
+		t.Fatal("expected at least one stroke")
+	}
+
+	line := doc.Layers[0].Lines[0]
+	if line.BrushType != BrushHighlighter {
+		t.Errorf("BrushType = %s, want %s", line.BrushType, BrushHighlighter)
+	}
+	if line.Color != ColorYellow {
+		t.Errorf("Color = %s, want %s", line.Color, ColorYellow)
+	}
+	if line.BrushSize != 5.0 {
+		t.Errorf("BrushSize = %.2f, want 5.00", line.BrushSize)
+	}
+}
+
+func TestParseV6Line_PrefersExtractedStyle(t *testing.T) {
+	newBody := func(penType, color uint32, brushSize float64) []byte {
+		return v6LineBody(crdtID{Counter: 11}, penType, color, brushSize)
+	}
+
+	opts := DefaultRenderOptions()
+	opts.FallbackBrush = BrushFineliner
+	opts.FallbackColor = ColorBlue
+	opts.FallbackBrushSize = 1.5
+	p := NewParserWithOptions(opts)
+
+	tests := []struct {
+		name      string
+		body      []byte
+		wantBrush BrushType
+		wantColor Color
+		wantSize  float32
+	}{
+		{
+			name:      "unknown brush uses fallback",
+			body:      newBody(99, uint32(ColorRed), 3.0),
+			wantBrush: BrushFineliner,
+			wantColor: ColorRed,
+			wantSize:  3.0,
+		},
+		{
+			name:      "unknown color and invalid size use fallback",
+			body:      newBody(uint32(BrushMarker), 42, -1),
+			wantBrush: BrushMarker,
+			wantColor: ColorBlue,
+			wantSize:  1.5,
+		},
+		{
+			name:      "fully extracted style is kept",
+			body:      newBody(uint32(BrushHighlighter), uint32(ColorYellow), 2.5),
+			wantBrush: BrushHighlighter,
+			wantColor: ColorYellow,
+			wantSize:  2.5,
+		},
+		{
+			name:      "second-generation tool ID is normalized",
+			body:      newBody(15, uint32(ColorBlack), 2.0),
+			wantBrush: BrushBallpoint,
+			wantColor: ColorBlack,
+			wantSize:  2.0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line, layerID, err := p.parseV6Line(tt.body, 2)
+			if err != nil {
+				t.Fatalf("parseV6Line() error = %v", err)
+			}
+			if layerID != "0:11" {
+				t.Errorf("layerID = %q, want 0:11", layerID)
+			}
+			if line.BrushType != tt.wantBrush {
+				t.Errorf("BrushType = %s, want %s", line.BrushType, tt.wantBrush)
+			}
+			if line.Color != tt.wantColor {
+				t.Errorf("Color = %s, want %s", line.Color, tt.wantColor)
+			}
+			if line.BrushSize != tt.wantSize {
+				t.Errorf("BrushSize = %.2f, want %.2f", line.BrushSize, tt.wantSize)
+			}
+		})
+	}
+}
+
+//nolint:gocyclo // Test function with multiple validation steps
+func TestParseLegacy_SampleFiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		version Version
+		lines   []int // lines per layer
+		points  int
+		brushes []BrushType
+		colors  []Color
+	}{
+		{
+			name:    "version 3",
+			file:    "testdata/sample_v3.rm",
+			version: Version3,
+			lines:   []int{3},
+			points:  15,
+			brushes: []BrushType{BrushBallpoint, BrushFineliner, BrushMarker},
+			colors:  []Color{ColorBlack, ColorGray, ColorWhite},
+		},
+		{
+			name:    "version 5",
+			file:    "testdata/sample_v5.rm",
+			version: Version5,
+			lines:   []int{2, 1},
+			points:  9,
+			brushes: []BrushType{BrushBallpoint, BrushFineliner, BrushHighlighter},
+			colors:  []Color{ColorBlack, ColorGray, ColorBlack},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := ParseFile(tt.file)
+			if err != nil {
+				t.Fatalf("ParseFile() error = %v", err)
+			}
+
+			if doc.Version != tt.version {
+				t.Errorf("Version = %d, want %d", doc.Version, tt.version)
+			}
+			if len(doc.Layers) != len(tt.lines) {
+				t.Fatalf("got %d layers, want %d", len(doc.Layers), len(tt.lines))
+			}
+
+			var brushes []BrushType
+			var colors []Color
+			points := 0
+			for i, layer := range doc.Layers {
+				if len(layer.Lines) != tt.lines[i] {
+					t.Errorf("layer %d has %d lines, want %d", i, len(layer.Lines), tt.lines[i])
+				}
+				for _, line := range layer.Lines {
+					brushes = append(brushes, line.BrushType)
+					colors = append(colors, line.Color)
+					points += len(line.Points)
+					for _, pt := range line.Points {
+						if pt.X < 0 || pt.X > Width || pt.Y < 0 || pt.Y > Height {
+							t.Errorf("point %+v outside page", pt)
+						}
+						if pt.Direction != 90 || pt.Pressure != 0.5 || pt.Width != 2 {
+							t.Errorf("point %+v not decoded as expected", pt)
+						}
+					}
+				}
+			}
+
+			if points != tt.points {
+				t.Errorf("got %d points, want %d", points, tt.points)
+			}
+			for i := range tt.brushes {
+				if i >= len(brushes) || brushes[i] != tt.brushes[i] || colors[i] != tt.colors[i] {
+					t.Errorf("line styles = %v/%v, want %v/%v", brushes, colors, tt.brushes, tt.colors)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestParseLegacy_FallbackStyleApplied(t *testing.T) {
+	var buf bytes.Buffer
+	buf.WriteString("reMarkable .lines file, version=5          ")
+	for _, v := range []uint32{1, 1, 99, 42, 0} { // layers, lines, brush, color, padding
+		binary.Write(&buf, binary.LittleEndian, v)
+	}
+	binary.Write(&buf, binary.LittleEndian, float32(-1)) // brush size
+	binary.Write(&buf, binary.LittleEndian, float32(0))  // unknown
+	binary.Write(&buf, binary.LittleEndian, uint32(0))   // points
+
+	opts := DefaultRenderOptions()
+	opts.FallbackBrush = BrushMarker
+	opts.FallbackColor = ColorGray
+	opts.FallbackBrushSize = 3
+
+	doc, err := NewParserWithOptions(opts).Parse(buf.Bytes())
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	line := doc.Layers[0].Lines[0]
+	if line.BrushType != BrushMarker || line.Color != ColorGray || line.BrushSize != 3 {
+		t.Errorf("line style = %v/%v/%v, want fallback", line.BrushType, line.Color, line.BrushSize)
+	}
+}
+
+func TestParseLegacy_Truncated(t *testing.T) {
+	for _, file := range []string{"testdata/sample_v3.rm", "testdata/sample_v5.rm"} {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("ReadFile() error = %v", err)
+		}
+
+		if _, err := NewParser().Parse(data[:len(data)-10]); err == nil {
+			t.Errorf("Parse(%s) should error on a truncated file", file)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package rmrender
 import (
 	"bytes"
 	"fmt"
+	"math"
 
 	"github.com/pdfcpu/pdfcpu/pkg/api"
 	"github.com/signintech/gopdf"
@@ -32,6 +33,11 @@ func NewRendererWithOptions(opts *RenderOptions) *Renderer {
 // This creates a single-page PDF with all strokes rendered as vector graphics.
 // The PDF will use the reMarkable dimensions (1404x1872 pixels at 226 DPI).
 func (r *Renderer) RenderToPDF(doc *Document) ([]byte, error) {
+	return r.render(doc, "")
+}
+
+// render renders doc to a single-page PDF, drawing the named template (if any) beneath the strokes
+func (r *Renderer) render(doc *Document, template string) ([]byte, error) {
 	if doc == nil {
 		return nil, fmt.Errorf("document cannot be nil")
 	}
@@ -56,7 +62,43 @@ func (r *Renderer) RenderToPDF(doc *Document) ([]byte, error) {
 	pdf.SetFillColor(red, green, blue)
 	pdf.RectFromUpperLeftWithStyle(0, 0, widthPt, heightPt, "F")
 
-	// Render each layer
+	if template != "" {
+		if err := DrawTemplate(&pdf, template, DefaultTemplateArea()); err != nil {
+			return nil, fmt.Errorf("failed to draw template: %w", err)
+		}
+	}
+
+	// Render strokes, covering earlier ink with eraser strokes when configured
+	for _, stroke := range r.strokesToDraw(doc) {
+		var err error
+		if stroke.BrushType.IsEraser() {
+			err = r.renderEraser(&pdf, stroke)
+		} else {
+			err = r.renderStrokeToPDF(&pdf, stroke)
+		}
+		if err != nil {
+			// Log error but continue with other strokes
+			continue
+		}
+	}
+
+	// Get PDF bytes
+	var buf bytes.Buffer
+	if _, err := pdf.WriteTo(&buf); err != nil {
+		return nil, fmt.Errorf("failed to write PDF: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// strokesToDraw returns the strokes of doc in drawing order
+//
+// Only layers selected by RenderOptions.RenderLayers are included, and erasers are
+// handled per RenderOptions.EraserMode: the result contains eraser strokes only in
+// EraserCover mode, where they are drawn in the background color. Strokes longer than
+// RenderOptions.MaxPointsPerStroke are split into segments.
+func (r *Renderer) strokesToDraw(doc *Document) []Line {
+	var strokes []Line
 	for layerIdx, layer := range doc.Layers {
 		// Check if we should render this layer
 		if r.options.RenderLayers != nil {
@@ -72,22 +114,41 @@ func (r *Renderer) RenderToPDF(doc *Document) ([]byte, error) {
 			}
 		}
 
-		// Render strokes in this layer
-		for _, stroke := range layer.Lines {
-			if err := r.renderStrokeToPDF(&pdf, stroke); err != nil {
-				// Log error but continue with other strokes
+		lines := layer.Lines
+		if r.options.EraserMode == EraserClip {
+			lines = clipErasedStrokes(lines)
+		}
+		for _, stroke := range lines {
+			if stroke.BrushType.IsEraser() && r.options.EraserMode != EraserCover {
 				continue
 			}
+			strokes = append(strokes, splitStroke(stroke, r.options.MaxPointsPerStroke)...)
 		}
 	}
+	return strokes
+}
 
-	// Get PDF bytes
-	var buf bytes.Buffer
-	if _, err := pdf.WriteTo(&buf); err != nil {
-		return nil, fmt.Errorf("failed to write PDF: %w", err)
+// splitStroke splits a stroke into consecutive segments of at most maxPoints points
+//
+// Each segment starts at the last point of the one before, so segments join without
+// a gap and every point is drawn. Strokes are left whole when maxPoints is below 2.
+func splitStroke(stroke Line, maxPoints int) []Line {
+	if maxPoints < 2 || len(stroke.Points) <= maxPoints {
+		return []Line{stroke}
 	}
 
-	return buf.Bytes(), nil
+	var segments []Line
+	for start := 0; start < len(stroke.Points)-1; start += maxPoints - 1 {
+		end := start + maxPoints
+		if end > len(stroke.Points) {
+			end = len(stroke.Points)
+		}
+
+		segment := stroke
+		segment.Points = stroke.Points[start:end]
+		segments = append(segments, segment)
+	}
+	return segments
 }
 
 // RenderPage renders a single page with the given strokes
@@ -120,22 +181,22 @@ func (r *Renderer) transformCoordinateToPDF(x, y float32) (float64, float64) {
 }
 
 // calculateStrokeWidth calculates the actual stroke width based on pressure and brush size
+//
+// Brush-specific multipliers apply whether or not pressure is enabled. Highlighters
+// keep a constant width, as on the tablet.
 func (r *Renderer) calculateStrokeWidth(point Point, baseBrushSize float32, brushType BrushType) float32 {
-	if !r.options.EnablePressure {
-		return baseBrushSize
-	}
-
 	// Base width from brush size
-	baseWidth := baseBrushSize * 2.0 // Scale factor for visibility
+	width := baseBrushSize * 2.0 // Scale factor for visibility
 
 	// Apply pressure
-	pressure := point.Pressure
-	if pressure < 0.1 {
-		pressure = 0.1 // Minimum pressure
+	if r.options.EnablePressure && brushType != BrushHighlighter {
+		pressure := point.Pressure
+		if pressure < 0.1 {
+			pressure = 0.1 // Minimum pressure
+		}
+		width *= pressure
 	}
 
-	width := baseWidth * pressure
-
 	// Brush-specific adjustments
 	switch brushType {
 	case BrushHighlighter:
@@ -158,13 +219,23 @@ func (r *Renderer) renderStrokeToPDF(pdf *gopdf.GoPdf, stroke Line) error {
 		return nil
 	}
 
-	// Get color for this brush/color combination
-	red, green, blue, _ := r.applyBrushStyle(stroke.BrushType, stroke.Color)
-	// Note: gopdf doesn't support alpha, so we ignore it
+	// Get color and opacity for this brush/color combination
+	red, green, blue, alpha := r.applyBrushStyle(stroke.BrushType, stroke.Color)
 
 	// Set stroke color
 	pdf.SetStrokeColor(red, green, blue)
 
+	// Semi-transparent brushes draw through an extended graphics state
+	if alpha < 1 {
+		if err := pdf.SetTransparency(gopdf.Transparency{
+			Alpha:         float64(alpha),
+			BlendModeType: r.blendMode(stroke.BrushType),
+		}); err != nil {
+			return fmt.Errorf("failed to set transparency: %w", err)
+		}
+		defer pdf.ClearTransparency()
+	}
+
 	// Draw lines connecting consecutive points
 	for i := 0; i < len(stroke.Points)-1; i++ {
 		p1 := stroke.Points[i]
@@ -187,17 +258,12 @@ func (r *Renderer) renderStrokeToPDF(pdf *gopdf.GoPdf, stroke Line) error {
 	return nil
 }
 
-// renderEraser processes eraser strokes
-//
-// Eraser strokes need special handling as they remove underlying content
-// rather than adding new content.
-func (r *Renderer) renderEraser(_ *gopdf.GoPdf, _ Line) error {
-	// TODO: Implement eraser logic
-	// Options:
-	// 1. Use white ink on white background
-	// 2. Use clipping paths
-	// 3. Pre-process document to remove intersecting strokes
-	return fmt.Errorf("eraser rendering not yet implemented")
+// blendMode returns the PDF blend mode for a semi-transparent brush
+func (r *Renderer) blendMode(brushType BrushType) gopdf.BlendModeType {
+	if brushType == BrushHighlighter && r.options.HighlighterBlend == BlendMultiply {
+		return gopdf.Multiply
+	}
+	return gopdf.NormalBlendMode
 }
 
 // applyBrushStyle applies brush-specific styling to a stroke
@@ -212,9 +278,32 @@ func (r *Renderer) applyBrushStyle(brushType BrushType, color Color) (red, green
 		alpha = 0.8 // Markers are slightly transparent
 	}
 
+	if r.options.PrintContrast && brushType != BrushHighlighter && color != ColorWhite {
+		red, green, blue = darken(red, green, blue, r.printContrastIntensity())
+	}
+
 	return red, green, blue, alpha
 }
 
+// printContrastIntensity returns the configured PrintContrast intensity clamped to 0-1
+func (r *Renderer) printContrastIntensity() float32 {
+	intensity := r.options.PrintContrastIntensity
+	switch {
+	case intensity <= 0:
+		return DefaultPrintContrastIntensity
+	case intensity > 1:
+		return 1
+	}
+	return intensity
+}
+
+// darken moves a color toward black by intensity (0-1), keeping its hue
+func darken(red, green, blue uint8, intensity float32) (uint8, uint8, uint8) {
+	scale := 1 - float64(intensity)
+	scaled := func(c uint8) uint8 { return uint8(math.Round(float64(c) * scale)) }
+	return scaled(red), scaled(green), scaled(blue)
+}
+
 // renderBackground renders the page background
 // This is now handled directly in RenderToPDF
 func (r *Renderer) renderBackground(_ *gopdf.GoPdf) error {
@@ -222,15 +311,12 @@ func (r *Renderer) renderBackground(_ *gopdf.GoPdf) error {
 	return nil
 }
 
-// RenderWithTemplate renders a document with a template background
+// RenderWithTemplate renders a document over a template background
 //
-// Templates include: Blank, Lined, Grid, Dots, etc.
-func (r *Renderer) RenderWithTemplate(_ *Document, _ string) ([]byte, error) {
-	// TODO: Implement template rendering
-	// Templates need to be:
-	// 1. Generated programmatically (preferred)
-	// 2. Or loaded from template images/PDFs
-	return nil, fmt.Errorf("template rendering not yet implemented")
+// template is a page template name as stored in the .content file, e.g. "P Grid small";
+// see ParseTemplate for the supported templates.
+func (r *Renderer) RenderWithTemplate(doc *Document, template string) ([]byte, error) {
+	return r.render(doc, template)
 }
 
 // Helper function to get reMarkable page dimensions in points
@@ -1,7 +1,13 @@
 package rmrender
 
 import (
+	"bytes"
+	"compress/zlib"
+	"image/png"
+	"io"
 	"os"
+	"regexp"
+	"strings"
 	"testing"
 )
 
@@ -139,3 +145,202 @@ func TestRenderNilDocument(t *testing.T) {
 
 	t.Logf("Correctly returned error for nil document: %v", err)
 }
+
+// pdfContent returns the decompressed content streams of a rendered PDF joined together
+func pdfContent(t *testing.T, pdfData []byte) string {
+	t.Helper()
+
+	var content strings.Builder
+	streams := regexp.MustCompile(`(?s)stream\r?\n(.*?)\r?\nendstream`).FindAllSubmatch(pdfData, -1)
+	for _, m := range streams {
+		zr, err := zlib.NewReader(bytes.NewReader(m[1]))
+		if err != nil {
+			content.Write(m[1])
+			continue
+		}
+		data, _ := io.ReadAll(zr)
+		content.Write(data)
+	}
+	return content.String()
+}
+
+func TestRenderHighlighterTransparency(t *testing.T) {
+	stroke := func(brush BrushType, color Color) Line {
+		return Line{BrushType: brush, Color: color, BrushSize: 2, Points: []Point{
+			{X: 100, Y: 100, Pressure: 1},
+			{X: 500, Y: 100, Pressure: 1},
+		}}
+	}
+
+	tests := []struct {
+		name      string
+		line      Line
+		blend     BlendMode
+		wantAlpha string // expected stroke alpha (/CA), empty for opaque strokes
+		wantBlend string
+	}{
+		{name: "highlighter multiply", line: stroke(BrushHighlighter, ColorYellow), blend: BlendMultiply, wantAlpha: "0.300", wantBlend: "/Multiply"},
+		{name: "highlighter normal", line: stroke(BrushHighlighter, ColorYellow), blend: BlendNormal, wantAlpha: "0.300", wantBlend: "/Normal"},
+		{name: "marker", line: stroke(BrushMarker, ColorBlack), blend: BlendMultiply, wantAlpha: "0.800", wantBlend: "/Normal"},
+		{name: "ballpoint", line: stroke(BrushBallpoint, ColorBlack), blend: BlendMultiply},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultRenderOptions()
+			opts.HighlighterBlend = tt.blend
+			doc := &Document{Version: Version6, Layers: []Layer{{Lines: []Line{tt.line}}}}
+
+			pdfData, err := NewRendererWithOptions(opts).RenderToPDF(doc)
+			if err != nil {
+				t.Fatalf("RenderToPDF() error = %v", err)
+			}
+
+			content := pdfContent(t, pdfData)
+			usesGState := regexp.MustCompile(`/GS\d+ gs`).MatchString(content)
+
+			if tt.wantAlpha == "" {
+				if usesGState {
+					t.Error("opaque stroke should not use a transparency graphics state")
+				}
+				return
+			}
+
+			if !usesGState {
+				t.Errorf("content stream should set a graphics state for transparency, got: %s", content)
+			}
+			if !strings.Contains(string(pdfData), "/CA "+tt.wantAlpha) {
+				t.Errorf("PDF should define stroke alpha %s", tt.wantAlpha)
+			}
+			if !strings.Contains(string(pdfData), "/BM "+tt.wantBlend) {
+				t.Errorf("PDF should use blend mode %s", tt.wantBlend)
+			}
+		})
+	}
+}
+
+func TestCalculateStrokeWidth(t *testing.T) {
+	renderer := NewRenderer()
+	light := Point{Pressure: 0.5}
+
+	ballpoint := renderer.calculateStrokeWidth(light, 2, BrushBallpoint)
+	highlighter := renderer.calculateStrokeWidth(light, 2, BrushHighlighter)
+	if highlighter <= ballpoint*3 {
+		t.Errorf("highlighter width %v should be over 3x ballpoint width %v", highlighter, ballpoint)
+	}
+
+	// Brush multipliers still apply without pressure
+	opts := DefaultRenderOptions()
+	opts.EnablePressure = false
+	renderer = NewRendererWithOptions(opts)
+	if got := renderer.calculateStrokeWidth(light, 2, BrushHighlighter); got != 12 {
+		t.Errorf("highlighter width without pressure = %v, want 12", got)
+	}
+	if got := renderer.calculateStrokeWidth(light, 2, BrushFineliner); got < 2.79 || got > 2.81 {
+		t.Errorf("fineliner width without pressure = %v, want 2.8", got)
+	}
+}
+
+func TestPrintContrast(t *testing.T) {
+	gray := lineThrough(BrushBallpoint, 200, 900, 1200, 900, 0)
+	gray.Color = ColorGray
+	doc := &Document{Version: Version6, Layers: []Layer{{Lines: []Line{gray}}}}
+
+	// grayLevel renders doc and returns the red channel at the middle of the stroke
+	grayLevel := func(opts *RenderOptions) uint32 {
+		data, err := NewRendererWithOptions(opts).RenderToPNG(doc, DPI)
+		if err != nil {
+			t.Fatalf("RenderToPNG() error = %v", err)
+		}
+		img, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("failed to decode PNG: %v", err)
+		}
+		r, _, _, _ := img.At(700, 900).RGBA()
+		return r >> 8
+	}
+
+	opts := DefaultRenderOptions()
+	normal := grayLevel(opts)
+
+	opts.PrintContrast = true
+	boosted := grayLevel(opts)
+	if boosted >= normal {
+		t.Errorf("gray with PrintContrast = %d, want darker than %d", boosted, normal)
+	}
+	if boosted != 50 {
+		t.Errorf("gray with default intensity = %d, want 50", boosted)
+	}
+
+	opts.PrintContrastIntensity = 1
+	if got := grayLevel(opts); got != 0 {
+		t.Errorf("gray at full intensity = %d, want 0", got)
+	}
+
+	// Highlighters keep their color
+	renderer := NewRendererWithOptions(opts)
+	if r, g, b, _ := renderer.applyBrushStyle(BrushHighlighter, ColorYellow); r != 255 || g != 242 || b != 0 {
+		t.Errorf("highlighter color = %d,%d,%d, want unchanged yellow", r, g, b)
+	}
+}
+
+func TestSplitLongStrokes(t *testing.T) {
+	// A 5000-point scribble zigzagging down the page
+	stroke := Line{BrushType: BrushBallpoint, Color: ColorBlack, BrushSize: 2}
+	for i := 0; i < 5000; i++ {
+		stroke.Points = append(stroke.Points, Point{
+			X:        float32(200 + (i%2)*1000),
+			Y:        float32(100 + i/3),
+			Pressure: 1,
+		})
+	}
+	doc := &Document{Version: Version6, Layers: []Layer{{Lines: []Line{stroke}}}}
+
+	r := NewRenderer()
+	segments := r.strokesToDraw(doc)
+	if len(segments) != 6 {
+		t.Fatalf("got %d segments, want 6", len(segments))
+	}
+
+	// Segments share their end points, so together they hold every point in order
+	var points []Point
+	for i, segment := range segments {
+		if len(segment.Points) > DefaultMaxPointsPerStroke {
+			t.Errorf("segment %d has %d points, want at most %d", i, len(segment.Points), DefaultMaxPointsPerStroke)
+		}
+		if i > 0 {
+			if segment.Points[0] != points[len(points)-1] {
+				t.Errorf("segment %d does not start where segment %d ends", i, i-1)
+			}
+			segment.Points = segment.Points[1:]
+		}
+		points = append(points, segment.Points...)
+	}
+	if len(points) != len(stroke.Points) {
+		t.Fatalf("segments hold %d points, want %d", len(points), len(stroke.Points))
+	}
+	for i := range points {
+		if points[i] != stroke.Points[i] {
+			t.Fatalf("point %d = %+v, want %+v", i, points[i], stroke.Points[i])
+		}
+	}
+
+	// Each segment is drawn
+	svg, err := r.RenderToSVG(doc)
+	if err != nil {
+		t.Fatalf("RenderToSVG() error = %v", err)
+	}
+	if got := strings.Count(string(svg), "<path "); got != 6 {
+		t.Errorf("SVG has %d paths, want 6", got)
+	}
+	if _, err := r.RenderToPDF(doc); err != nil {
+		t.Errorf("RenderToPDF() error = %v", err)
+	}
+
+	// Without a cap the stroke is drawn whole
+	opts := DefaultRenderOptions()
+	opts.MaxPointsPerStroke = 0
+	if got := len(NewRendererWithOptions(opts).strokesToDraw(doc)); got != 1 {
+		t.Errorf("uncapped stroke split into %d segments, want 1", got)
+	}
+}
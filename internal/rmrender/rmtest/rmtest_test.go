@@ -0,0 +1,99 @@
+package rmtest
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/platinummonkey/legible/internal/rmrender"
+)
+
+// fixture returns a three-layer document whose point values survive every format's
+// precision, so parsing it back should reproduce it exactly
+func fixture() *rmrender.Document {
+	pen := Stroke(rmrender.BrushFineliner, rmrender.ColorBlue, 2, [2]float32{100, 200}, [2]float32{150, 250}, [2]float32{200, 300})
+	pen.Points[1].Speed = 1.5
+	pen.Points[1].Direction = 360
+
+	return &rmrender.Document{
+		Layers: []rmrender.Layer{
+			{Name: "Layer 1", Lines: []rmrender.Line{
+				pen,
+				Stroke(rmrender.BrushHighlighter, rmrender.ColorYellow, 3, [2]float32{10, 20}, [2]float32{1000, 20}),
+			}},
+			{Name: "Sketch", Lines: []rmrender.Line{
+				Stroke(rmrender.BrushMarker, rmrender.ColorRed, 1, [2]float32{0, 0}),
+			}},
+			{Name: "Empty", Lines: []rmrender.Line{}},
+		},
+	}
+}
+
+func TestEncode_RoundTrip(t *testing.T) {
+	tests := []struct {
+		version   rmrender.Version
+		wantNames []string
+		wantIDs   []string
+	}{
+		{rmrender.Version3, []string{"", "", ""}, []string{"", "", ""}},
+		{rmrender.Version5, []string{"", "", ""}, []string{"", "", ""}},
+		{rmrender.Version6, []string{"Layer 1", "Sketch", "Empty"}, []string{"0:11", "0:12", "0:13"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version.String(), func(t *testing.T) {
+			want := fixture()
+			path := filepath.Join(t.TempDir(), "page.rm")
+			if err := WriteFile(path, want, tt.version); err != nil {
+				t.Fatalf("WriteFile() error = %v", err)
+			}
+
+			got, err := rmrender.ParseFile(path)
+			if err != nil {
+				t.Fatalf("ParseFile() error = %v", err)
+			}
+
+			if got.Version != tt.version {
+				t.Errorf("Version = %s, want %s", got.Version, tt.version)
+			}
+			if len(got.Layers) != len(want.Layers) {
+				t.Fatalf("got %d layers, want %d", len(got.Layers), len(want.Layers))
+			}
+			for i, layer := range got.Layers {
+				if layer.Name != tt.wantNames[i] || layer.ID != tt.wantIDs[i] {
+					t.Errorf("layer %d = %q (ID %q), want %q (ID %q)", i, layer.Name, layer.ID, tt.wantNames[i], tt.wantIDs[i])
+				}
+				if !reflect.DeepEqual(layer.Lines, want.Layers[i].Lines) {
+					t.Errorf("layer %d lines = %+v, want %+v", i, layer.Lines, want.Layers[i].Lines)
+				}
+			}
+		})
+	}
+}
+
+func TestEncode_Page(t *testing.T) {
+	data, err := Encode(Page(Stroke(rmrender.BrushBallpoint, rmrender.ColorBlack, 2, [2]float32{1, 2}, [2]float32{3, 4})), rmrender.Version6)
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	doc, err := rmrender.NewParser().Parse(data)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(doc.Layers) != 1 || doc.Layers[0].Name != "Layer 1" || len(doc.Layers[0].Lines) != 1 {
+		t.Fatalf("parsed %+v, want one layer with one line", doc.Layers)
+	}
+	if n := len(doc.Layers[0].Lines[0].Points); n != 2 {
+		t.Errorf("got %d points, want 2", n)
+	}
+}
+
+func TestEncode_Invalid(t *testing.T) {
+	if _, err := Encode(nil, rmrender.Version6); err == nil {
+		t.Error("Encode() should error on a nil document")
+	}
+	if _, err := Encode(Page(), rmrender.Version(4)); err == nil {
+		t.Error("Encode() should error on an unsupported version")
+	}
+}
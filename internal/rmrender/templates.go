@@ -0,0 +1,194 @@
+package rmrender
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/signintech/gopdf"
+)
+
+// TemplateKind identifies the pattern drawn by a page template
+type TemplateKind int
+
+const (
+	// TemplateBlank draws nothing
+	TemplateBlank TemplateKind = iota
+
+	// TemplateLines draws horizontal ruled lines below a header band
+	TemplateLines
+
+	// TemplateGrid draws a square grid
+	TemplateGrid
+
+	// TemplateDots draws a dot at each grid intersection
+	TemplateDots
+
+	// TemplateCheckered draws a fine square grid
+	TemplateCheckered
+)
+
+// Template spacing in device pixels at 226 DPI, following the tablet's small,
+// medium, and large variants (about 5, 7, and 9 mm)
+const (
+	templateSpacingSmall  = 45.0
+	templateSpacingMedium = 62.0
+	templateSpacingLarge  = 80.0
+
+	// templateLinesTop is the header band left empty at the top of ruled templates
+	templateLinesTop = 160.0
+
+	// templateDotRadius is the radius of a dot in device pixels
+	templateDotRadius = 2.5
+
+	// templateLineWidth is the width of template lines in points
+	templateLineWidth = 0.4
+)
+
+// templateGray is the color of template lines and dots
+var templateGray = [3]uint8{200, 200, 200}
+
+// TemplateSpec describes how to draw a page template
+type TemplateSpec struct {
+	Kind TemplateKind
+
+	// Spacing between lines or dots in device pixels
+	Spacing float64
+}
+
+// TemplateArea places a device page on a PDF page for template drawing
+type TemplateArea struct {
+	// Native page size in device pixels
+	NativeWidth  float64
+	NativeHeight float64
+
+	// Position and size on the PDF page in points
+	X      float64
+	Y      float64
+	Width  float64
+	Height float64
+}
+
+// DefaultTemplateArea covers a whole page rendered by Renderer
+func DefaultTemplateArea() TemplateArea {
+	width, height := getRemarkablePageDimensions()
+	return TemplateArea{
+		NativeWidth:  Width,
+		NativeHeight: Height,
+		Width:        width,
+		Height:       height,
+	}
+}
+
+// ParseTemplate maps a template name from a page's .content entry to a spec
+//
+// Names such as "Blank", "P Lines medium", "LS Grid small", "P Dots S", and
+// "P Checkered" are recognized by keyword; the size defaults to medium. Empty
+// names are blank. Unrecognized names return an error.
+func ParseTemplate(name string) (TemplateSpec, error) {
+	words := strings.Fields(strings.ToLower(name))
+
+	spec := TemplateSpec{Kind: TemplateBlank, Spacing: templateSpacingMedium}
+	found := len(words) == 0
+	for _, word := range words {
+		switch word {
+		case "blank":
+			found = true
+		case "lines", "lined", "ruled":
+			spec.Kind, found = TemplateLines, true
+		case "grid":
+			spec.Kind, found = TemplateGrid, true
+		case "dots", "dotted":
+			spec.Kind, found = TemplateDots, true
+		case "checkered":
+			spec.Kind, found = TemplateCheckered, true
+		case "small", "s":
+			spec.Spacing = templateSpacingSmall
+		case "large", "l":
+			spec.Spacing = templateSpacingLarge
+		}
+	}
+
+	if !found {
+		return TemplateSpec{}, fmt.Errorf("unsupported template %q", name)
+	}
+	if spec.Kind == TemplateCheckered {
+		spec.Spacing = templateSpacingSmall
+	}
+	return spec, nil
+}
+
+// templateLine is a line segment in device pixels
+type templateLine struct {
+	X1, Y1, X2, Y2 float64
+}
+
+// templateShapes returns the lines and dot centers for spec on a width x height device page
+//
+// Grid lines and dots sit at every multiple of the spacing strictly inside the page.
+func templateShapes(spec TemplateSpec, width, height float64) ([]templateLine, [][2]float64) {
+	if spec.Spacing <= 0 {
+		return nil, nil
+	}
+
+	var lines []templateLine
+	var dots [][2]float64
+
+	switch spec.Kind {
+	case TemplateLines:
+		for y := templateLinesTop; y < height; y += spec.Spacing {
+			lines = append(lines, templateLine{X1: 0, Y1: y, X2: width, Y2: y})
+		}
+	case TemplateGrid, TemplateCheckered:
+		for x := spec.Spacing; x < width; x += spec.Spacing {
+			lines = append(lines, templateLine{X1: x, Y1: 0, X2: x, Y2: height})
+		}
+		for y := spec.Spacing; y < height; y += spec.Spacing {
+			lines = append(lines, templateLine{X1: 0, Y1: y, X2: width, Y2: y})
+		}
+	case TemplateDots:
+		for y := spec.Spacing; y < height; y += spec.Spacing {
+			for x := spec.Spacing; x < width; x += spec.Spacing {
+				dots = append(dots, [2]float64{x, y})
+			}
+		}
+	}
+
+	return lines, dots
+}
+
+// DrawTemplate draws the named template onto the current page of pdf within area
+//
+// It should be called before strokes are rendered so the template sits beneath them.
+// Unrecognized names draw nothing and return an error.
+func DrawTemplate(pdf *gopdf.GoPdf, name string, area TemplateArea) error {
+	spec, err := ParseTemplate(name)
+	if err != nil {
+		return err
+	}
+	if spec.Kind == TemplateBlank {
+		return nil
+	}
+	if area.NativeWidth <= 0 || area.NativeHeight <= 0 {
+		return fmt.Errorf("invalid template area %.0fx%.0f", area.NativeWidth, area.NativeHeight)
+	}
+
+	scaleX := area.Width / area.NativeWidth
+	scaleY := area.Height / area.NativeHeight
+	lines, dots := templateShapes(spec, area.NativeWidth, area.NativeHeight)
+
+	pdf.SetStrokeColor(templateGray[0], templateGray[1], templateGray[2])
+	pdf.SetFillColor(templateGray[0], templateGray[1], templateGray[2])
+	pdf.SetLineWidth(templateLineWidth)
+
+	for _, l := range lines {
+		pdf.Line(area.X+l.X1*scaleX, area.Y+l.Y1*scaleY, area.X+l.X2*scaleX, area.Y+l.Y2*scaleY)
+	}
+
+	radius := templateDotRadius * scaleX
+	for _, d := range dots {
+		x, y := area.X+d[0]*scaleX, area.Y+d[1]*scaleY
+		pdf.RectFromUpperLeftWithStyle(x-radius, y-radius, 2*radius, 2*radius, "F")
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package rmrender
+
+import (
+	"testing"
+)
+
+func TestParseTemplate(t *testing.T) {
+	tests := []struct {
+		name        string
+		template    string
+		wantKind    TemplateKind
+		wantSpacing float64
+		wantErr     bool
+	}{
+		{name: "empty", template: "", wantKind: TemplateBlank, wantSpacing: templateSpacingMedium},
+		{name: "blank", template: "Blank", wantKind: TemplateBlank, wantSpacing: templateSpacingMedium},
+		{name: "lines medium", template: "P Lines medium", wantKind: TemplateLines, wantSpacing: templateSpacingMedium},
+		{name: "lines small", template: "P Lines small", wantKind: TemplateLines, wantSpacing: templateSpacingSmall},
+		{name: "landscape grid large", template: "LS Grid large", wantKind: TemplateGrid, wantSpacing: templateSpacingLarge},
+		{name: "dots short size", template: "P Dots S", wantKind: TemplateDots, wantSpacing: templateSpacingSmall},
+		{name: "checkered", template: "P Checkered", wantKind: TemplateCheckered, wantSpacing: templateSpacingSmall},
+		{name: "unknown", template: "P Calendar", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec, err := ParseTemplate(tt.template)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTemplate(%q) error = %v, wantErr %v", tt.template, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if spec.Kind != tt.wantKind || spec.Spacing != tt.wantSpacing {
+				t.Errorf("ParseTemplate(%q) = %+v, want kind %d spacing %v", tt.template, spec, tt.wantKind, tt.wantSpacing)
+			}
+		})
+	}
+}
+
+func TestTemplateShapes_Grid(t *testing.T) {
+	spec, err := ParseTemplate("P Grid medium")
+	if err != nil {
+		t.Fatalf("ParseTemplate() error = %v", err)
+	}
+
+	lines, dots := templateShapes(spec, Width, Height)
+
+	horizontal, vertical := 0, 0
+	for _, l := range lines {
+		switch {
+		case l.Y1 == l.Y2:
+			horizontal++
+		case l.X1 == l.X2:
+			vertical++
+		}
+	}
+
+	// Lines at every multiple of 62px strictly inside a 1404 x 1872 page
+	if vertical != 22 {
+		t.Errorf("got %d vertical lines, want 22", vertical)
+	}
+	if horizontal != 30 {
+		t.Errorf("got %d horizontal lines, want 30", horizontal)
+	}
+	if len(dots) != 0 {
+		t.Errorf("grid should draw no dots, got %d", len(dots))
+	}
+}
+
+func TestTemplateShapes_LinesAndDots(t *testing.T) {
+	lines, _ := templateShapes(TemplateSpec{Kind: TemplateLines, Spacing: 100}, Width, Height)
+	// 160, 260, ..., 1860
+	if len(lines) != 18 {
+		t.Errorf("got %d ruled lines, want 18", len(lines))
+	}
+	for _, l := range lines {
+		if l.Y1 != l.Y2 || l.Y1 < templateLinesTop {
+			t.Errorf("ruled line %+v should be horizontal and below the header", l)
+		}
+	}
+
+	lines, dots := templateShapes(TemplateSpec{Kind: TemplateDots, Spacing: 100}, Width, Height)
+	if len(lines) != 0 || len(dots) != 14*18 {
+		t.Errorf("got %d lines and %d dots, want 0 and %d", len(lines), len(dots), 14*18)
+	}
+
+	lines, dots = templateShapes(TemplateSpec{Kind: TemplateBlank, Spacing: 100}, Width, Height)
+	if len(lines) != 0 || len(dots) != 0 {
+		t.Errorf("blank template should draw nothing, got %d lines and %d dots", len(lines), len(dots))
+	}
+}
+
+func TestRenderWithTemplate(t *testing.T) {
+	doc := &Document{Version: Version6}
+	renderer := NewRenderer()
+
+	plain, err := renderer.RenderToPDF(doc)
+	if err != nil {
+		t.Fatalf("RenderToPDF() error = %v", err)
+	}
+
+	grid, err := renderer.RenderWithTemplate(doc, "P Grid small")
+	if err != nil {
+		t.Fatalf("RenderWithTemplate() error = %v", err)
+	}
+	if string(grid[:4]) != "%PDF" {
+		t.Errorf("RenderWithTemplate() did not produce a PDF")
+	}
+	if len(grid) <= len(plain) {
+		t.Errorf("grid page (%d bytes) should be larger than a plain page (%d bytes)", len(grid), len(plain))
+	}
+
+	if _, err := renderer.RenderWithTemplate(doc, "P Calendar"); err == nil {
+		t.Error("RenderWithTemplate() should error for an unsupported template")
+	}
+}
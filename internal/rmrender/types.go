@@ -54,6 +54,12 @@ type Document struct {
 // Layer represents a drawing layer in the document
 // The reMarkable supports multiple layers that can be toggled on/off
 type Layer struct {
+	// ID identifies the layer within the file (empty when the format has no layer IDs)
+	ID string
+
+	// Name is the layer name shown on the tablet, e.g. "Layer 1"
+	Name string
+
 	// Lines contains all the strokes in this layer
 	Lines []Line
 }
@@ -161,6 +167,16 @@ func (b BrushType) String() string {
 	}
 }
 
+// IsKnown reports whether the brush type is one of the recognized reMarkable tools
+func (b BrushType) IsKnown() bool {
+	return b.String() != "Unknown"
+}
+
+// IsEraser reports whether strokes of this brush remove ink rather than add it
+func (b BrushType) IsEraser() bool {
+	return b == BrushEraser || b == BrushEraseSection
+}
+
 // Color represents stroke colors
 type Color int
 
@@ -245,6 +261,64 @@ func (c Color) String() string {
 	}
 }
 
+// IsKnown reports whether the color is one of the recognized reMarkable colors
+func (c Color) IsKnown() bool {
+	return c >= ColorBlack && c <= ColorGrayOverlay
+}
+
+// EraserMode controls how eraser strokes are rendered
+type EraserMode int
+
+const (
+	// EraserIgnore skips eraser strokes, leaving erased ink visible
+	EraserIgnore EraserMode = iota
+
+	// EraserCover draws eraser strokes in the background color over earlier ink
+	EraserCover
+
+	// EraserClip removes the segments of earlier strokes in the same layer that an
+	// eraser stroke passes over, so erased ink is absent from the output
+	EraserClip
+)
+
+// String returns the eraser mode name
+func (m EraserMode) String() string {
+	switch m {
+	case EraserIgnore:
+		return "ignore"
+	case EraserCover:
+		return "cover"
+	case EraserClip:
+		return "clip"
+	default:
+		return "unknown"
+	}
+}
+
+// BlendMode controls how semi-transparent strokes combine with the content beneath them
+type BlendMode int
+
+const (
+	// BlendNormal paints the stroke color over content at the stroke's opacity
+	BlendNormal BlendMode = iota
+
+	// BlendMultiply darkens content like real highlighter ink, keeping dark text beneath
+	// the stroke fully legible
+	BlendMultiply
+)
+
+// String returns the blend mode name
+func (m BlendMode) String() string {
+	switch m {
+	case BlendNormal:
+		return "normal"
+	case BlendMultiply:
+		return "multiply"
+	default:
+		return "unknown"
+	}
+}
+
 // ParseResult contains metadata about the parsing operation
 type ParseResult struct {
 	Version     Version
@@ -271,8 +345,42 @@ type RenderOptions struct {
 
 	// StrokeQuality controls rendering quality (higher = more points, smoother)
 	StrokeQuality int // 1-10, default 5
+
+	// FallbackBrush is used for strokes whose brush type cannot be determined
+	FallbackBrush BrushType
+
+	// FallbackColor is used for strokes whose color cannot be determined
+	FallbackColor Color
+
+	// FallbackBrushSize is used for strokes whose brush size cannot be determined
+	FallbackBrushSize float32
+
+	// EraserMode controls how eraser strokes are rendered
+	EraserMode EraserMode
+
+	// HighlighterBlend controls how highlighter strokes blend with content beneath them
+	HighlighterBlend BlendMode
+
+	// PrintContrast darkens light ink (such as gray) toward black so it stays legible
+	// when printed; highlighters and white ink are left unchanged
+	PrintContrast bool
+
+	// PrintContrastIntensity is how far PrintContrast moves colors toward black, from
+	// 0 (unchanged) to 1 (black); 0 uses DefaultPrintContrastIntensity
+	PrintContrastIntensity float32
+
+	// MaxPointsPerStroke splits strokes with more points into consecutive segments
+	// that are drawn separately, keeping very long strokes (such as continuous
+	// scribbles) to a manageable path size; 0 leaves strokes whole
+	MaxPointsPerStroke int
 }
 
+// DefaultPrintContrastIntensity darkens reMarkable gray (125) to 50 when PrintContrast is enabled
+const DefaultPrintContrastIntensity = 0.6
+
+// DefaultMaxPointsPerStroke is the default RenderOptions.MaxPointsPerStroke
+const DefaultMaxPointsPerStroke = 1000
+
 // DefaultRenderOptions returns sensible default rendering options
 func DefaultRenderOptions() *RenderOptions {
 	return &RenderOptions{
@@ -281,5 +389,12 @@ func DefaultRenderOptions() *RenderOptions {
 		EnablePressure:     true,
 		EnableAntialiasing: true,
 		StrokeQuality:      5,
+		FallbackBrush:      BrushBallpoint,
+		FallbackColor:      ColorBlack,
+		FallbackBrushSize:  2.0,
+		EraserMode:         EraserCover,
+		HighlighterBlend:   BlendMultiply,
+		PrintContrast:      false,
+		MaxPointsPerStroke: DefaultMaxPointsPerStroke,
 	}
 }
@@ -0,0 +1,194 @@
+package rmrender
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// v6 tag types, stored in the low nibble of each tag (the high bits hold the field index)
+const (
+	tagByte1   byte = 0x1
+	tagByte4   byte = 0x4
+	tagByte8   byte = 0x8
+	tagLength4 byte = 0xC
+	tagID      byte = 0xF
+)
+
+// crdtID identifies a node or item in a v6 scene: an author index and a per-author counter
+type crdtID struct {
+	Author  uint8
+	Counter uint64
+}
+
+// String formats the ID as "author:counter"
+func (id crdtID) String() string {
+	return fmt.Sprintf("%d:%d", id.Author, id.Counter)
+}
+
+// rootNodeID is the scene tree root; layers are the groups directly beneath it
+var rootNodeID = crdtID{Author: 0, Counter: 1}
+
+// tagReader decodes the tagged values that make up a v6 block body
+type tagReader struct {
+	data []byte
+	pos  int
+}
+
+// newTagReader creates a reader over a block body or subblock
+func newTagReader(data []byte) *tagReader {
+	return &tagReader{data: data}
+}
+
+// remaining returns the number of unread bytes
+func (r *tagReader) remaining() int {
+	return len(r.data) - r.pos
+}
+
+// readBytes reads the next n raw bytes
+func (r *tagReader) readBytes(n int) ([]byte, error) {
+	if n < 0 || r.remaining() < n {
+		return nil, fmt.Errorf("unexpected end of block at offset %d (need %d bytes)", r.pos, n)
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b, nil
+}
+
+// readByte reads a single raw byte
+func (r *tagReader) readByte() (byte, error) {
+	b, err := r.readBytes(1)
+	if err != nil {
+		return 0, err
+	}
+	return b[0], nil
+}
+
+// readVaruint reads an unsigned LEB128 integer
+func (r *tagReader) readVaruint() (uint64, error) {
+	var value uint64
+	for shift := uint(0); shift < 64; shift += 7 {
+		b, err := r.readByte()
+		if err != nil {
+			return 0, err
+		}
+		value |= uint64(b&0x7f) << shift
+		if b&0x80 == 0 {
+			return value, nil
+		}
+	}
+	return 0, fmt.Errorf("varuint too long at offset %d", r.pos)
+}
+
+// hasTag reports whether the next value carries the given index and type, without consuming it
+func (r *tagReader) hasTag(index uint64, tagType byte) bool {
+	pos := r.pos
+	defer func() { r.pos = pos }()
+
+	tag, err := r.readVaruint()
+	return err == nil && tag>>4 == index && byte(tag&0xf) == tagType
+}
+
+// expectTag consumes a tag, failing if it doesn't match the given index and type
+func (r *tagReader) expectTag(index uint64, tagType byte) error {
+	pos := r.pos
+	tag, err := r.readVaruint()
+	if err != nil {
+		return err
+	}
+	if tag>>4 != index || byte(tag&0xf) != tagType {
+		return fmt.Errorf("expected tag %d/0x%x at offset %d, got %d/0x%x", index, tagType, pos, tag>>4, tag&0xf)
+	}
+	return nil
+}
+
+// readID reads a tagged CRDT ID
+func (r *tagReader) readID(index uint64) (crdtID, error) {
+	if err := r.expectTag(index, tagID); err != nil {
+		return crdtID{}, err
+	}
+	author, err := r.readByte()
+	if err != nil {
+		return crdtID{}, err
+	}
+	counter, err := r.readVaruint()
+	if err != nil {
+		return crdtID{}, err
+	}
+	return crdtID{Author: author, Counter: counter}, nil
+}
+
+// readBool reads a tagged single-byte boolean
+func (r *tagReader) readBool(index uint64) (bool, error) {
+	if err := r.expectTag(index, tagByte1); err != nil {
+		return false, err
+	}
+	b, err := r.readByte()
+	return b != 0, err
+}
+
+// readUint32 reads a tagged 4-byte integer
+func (r *tagReader) readUint32(index uint64) (uint32, error) {
+	if err := r.expectTag(index, tagByte4); err != nil {
+		return 0, err
+	}
+	b, err := r.readBytes(4)
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint32(b), nil
+}
+
+// readFloat32 reads a tagged 4-byte float
+func (r *tagReader) readFloat32(index uint64) (float32, error) {
+	bits, err := r.readUint32(index)
+	return math.Float32frombits(bits), err
+}
+
+// readFloat64 reads a tagged 8-byte float
+func (r *tagReader) readFloat64(index uint64) (float64, error) {
+	if err := r.expectTag(index, tagByte8); err != nil {
+		return 0, err
+	}
+	b, err := r.readBytes(8)
+	if err != nil {
+		return 0, err
+	}
+	return math.Float64frombits(binary.LittleEndian.Uint64(b)), nil
+}
+
+// readSubblock reads a tagged length-prefixed subblock and returns a reader over its contents
+func (r *tagReader) readSubblock(index uint64) (*tagReader, error) {
+	if err := r.expectTag(index, tagLength4); err != nil {
+		return nil, err
+	}
+	lenBytes, err := r.readBytes(4)
+	if err != nil {
+		return nil, err
+	}
+	body, err := r.readBytes(int(binary.LittleEndian.Uint32(lenBytes)))
+	if err != nil {
+		return nil, err
+	}
+	return newTagReader(body), nil
+}
+
+// readString reads a tagged string subblock (varuint length, ASCII flag, bytes)
+func (r *tagReader) readString(index uint64) (string, error) {
+	sub, err := r.readSubblock(index)
+	if err != nil {
+		return "", err
+	}
+	length, err := sub.readVaruint()
+	if err != nil {
+		return "", err
+	}
+	if _, err := sub.readByte(); err != nil { // is_ascii flag
+		return "", err
+	}
+	b, err := sub.readBytes(int(length))
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
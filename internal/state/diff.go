@@ -0,0 +1,130 @@
+package state
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+)
+
+// Diff describes the differences between two sync states
+type Diff struct {
+	// Added are documents present only in the new state
+	Added []*DocumentState `json:"added"`
+
+	// Removed are documents present only in the old state
+	Removed []*DocumentState `json:"removed"`
+
+	// Changed are documents present in both states whose tracked fields differ
+	Changed []DocumentChange `json:"changed"`
+}
+
+// DocumentChange lists the field differences of a single document
+type DocumentChange struct {
+	ID      string        `json:"id"`
+	Name    string        `json:"name"`
+	Changes []FieldChange `json:"changes"`
+}
+
+// FieldChange is a single field that differs between two states
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// IsEmpty reports whether the two states had no differences
+func (d *Diff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// DiffStates compares two sync states and reports added, removed, and changed documents
+//
+// Documents are matched by ID; the name, version, conversion status, and local path are compared.
+// Results are sorted by document ID. Nil states are treated as empty, and nil
+// document entries (e.g. "null" in a hand-edited state file) as absent.
+func DiffStates(oldState, newState *SyncState) *Diff {
+	if oldState == nil {
+		oldState = NewSyncState()
+	}
+	if newState == nil {
+		newState = NewSyncState()
+	}
+
+	diff := &Diff{
+		Added:   []*DocumentState{},
+		Removed: []*DocumentState{},
+		Changed: []DocumentChange{},
+	}
+
+	for id, oldDoc := range oldState.Documents {
+		if oldDoc == nil {
+			continue
+		}
+
+		newDoc := newState.Documents[id]
+		if newDoc == nil {
+			diff.Removed = append(diff.Removed, oldDoc)
+			continue
+		}
+
+		if changes := diffDocument(oldDoc, newDoc); len(changes) > 0 {
+			diff.Changed = append(diff.Changed, DocumentChange{
+				ID:      id,
+				Name:    newDoc.Name,
+				Changes: changes,
+			})
+		}
+	}
+
+	for id, newDoc := range newState.Documents {
+		if newDoc != nil && oldState.Documents[id] == nil {
+			diff.Added = append(diff.Added, newDoc)
+		}
+	}
+
+	sort.Slice(diff.Added, func(i, j int) bool { return diff.Added[i].ID < diff.Added[j].ID })
+	sort.Slice(diff.Removed, func(i, j int) bool { return diff.Removed[i].ID < diff.Removed[j].ID })
+	sort.Slice(diff.Changed, func(i, j int) bool { return diff.Changed[i].ID < diff.Changed[j].ID })
+
+	return diff
+}
+
+// diffDocument returns the tracked fields that differ between two versions of a document
+func diffDocument(oldDoc, newDoc *DocumentState) []FieldChange {
+	var changes []FieldChange
+	add := func(field, oldValue, newValue string) {
+		if oldValue != newValue {
+			changes = append(changes, FieldChange{Field: field, Old: oldValue, New: newValue})
+		}
+	}
+
+	add("name", oldDoc.Name, newDoc.Name)
+	add("version", strconv.Itoa(oldDoc.Version), strconv.Itoa(newDoc.Version))
+	add("conversion_status", string(oldDoc.ConversionStatus), string(newDoc.ConversionStatus))
+	add("local_path", oldDoc.LocalPath, newDoc.LocalPath)
+
+	return changes
+}
+
+// LoadStateFile reads a state file or SQLite state database without creating it
+func LoadStateFile(path string) (*SyncState, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("failed to read state file: %w", err)
+	}
+
+	// State databases are read without writing to them, like state files below
+	if isSQLite, err := isSQLiteFile(path); err != nil {
+		return nil, fmt.Errorf("failed to read state file: %w", err)
+	} else if isSQLite {
+		return readSQLiteState(path)
+	}
+
+	// Diffing a backup in place of an unreadable file would silently compare the wrong snapshot
+	manager := NewManager(path)
+	manager.readOnly = true
+	if err := manager.Load(); err != nil {
+		return nil, err
+	}
+	return manager.GetState(), nil
+}
@@ -0,0 +1,146 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDiffStates(t *testing.T) {
+	oldState := NewSyncState()
+	newState := NewSyncState()
+
+	unchanged := &DocumentState{ID: "doc-same", Name: "Same", Version: 1, LocalPath: "/out/Same.pdf", ConversionStatus: ConversionStatusCompleted}
+	oldState.AddDocument(unchanged)
+	newState.AddDocument(unchanged)
+
+	oldState.AddDocument(&DocumentState{ID: "doc-removed", Name: "Removed", Version: 2})
+	newState.AddDocument(&DocumentState{ID: "doc-added", Name: "Added", Version: 1})
+
+	oldState.AddDocument(&DocumentState{
+		ID:               "doc-changed",
+		Name:             "Notes",
+		Version:          3,
+		LocalPath:        "/out/Notes.pdf",
+		ConversionStatus: ConversionStatusFailed,
+	})
+	newState.AddDocument(&DocumentState{
+		ID:               "doc-changed",
+		Name:             "Notes",
+		Version:          4,
+		LocalPath:        "/out/Work/Notes.pdf",
+		ConversionStatus: ConversionStatusCompleted,
+	})
+
+	diff := DiffStates(oldState, newState)
+
+	if len(diff.Added) != 1 || diff.Added[0].ID != "doc-added" {
+		t.Errorf("Added = %v, want [doc-added]", diff.Added)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0].ID != "doc-removed" {
+		t.Errorf("Removed = %v, want [doc-removed]", diff.Removed)
+	}
+	if len(diff.Changed) != 1 || diff.Changed[0].ID != "doc-changed" {
+		t.Fatalf("Changed = %v, want [doc-changed]", diff.Changed)
+	}
+
+	want := []FieldChange{
+		{Field: "version", Old: "3", New: "4"},
+		{Field: "conversion_status", Old: "failed", New: "completed"},
+		{Field: "local_path", Old: "/out/Notes.pdf", New: "/out/Work/Notes.pdf"},
+	}
+	got := diff.Changed[0].Changes
+	if len(got) != len(want) {
+		t.Fatalf("Changes = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Changes[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+
+	if diff.IsEmpty() {
+		t.Error("IsEmpty() = true, want false")
+	}
+}
+
+func TestDiffStates_Identical(t *testing.T) {
+	state := NewSyncState()
+	state.AddDocument(&DocumentState{ID: "doc1", Name: "Doc", Version: 1})
+
+	if diff := DiffStates(state, state); !diff.IsEmpty() {
+		t.Errorf("DiffStates() of identical states = %+v, want empty", diff)
+	}
+	if diff := DiffStates(nil, nil); !diff.IsEmpty() {
+		t.Errorf("DiffStates(nil, nil) = %+v, want empty", diff)
+	}
+}
+
+func TestDiffStates_NilDocuments(t *testing.T) {
+	oldState := NewSyncState()
+	oldState.Documents["doc-null"] = nil
+	oldState.Documents["doc-filled"] = nil
+	oldState.AddDocument(&DocumentState{ID: "doc-emptied", Name: "Emptied", Version: 1})
+
+	newState := NewSyncState()
+	newState.Documents["doc-null"] = nil
+	newState.Documents["doc-emptied"] = nil
+	newState.AddDocument(&DocumentState{ID: "doc-filled", Name: "Filled", Version: 1})
+
+	diff := DiffStates(oldState, newState)
+
+	if len(diff.Added) != 1 || diff.Added[0].ID != "doc-filled" {
+		t.Errorf("Added = %v, want [doc-filled]", diff.Added)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0].ID != "doc-emptied" {
+		t.Errorf("Removed = %v, want [doc-emptied]", diff.Removed)
+	}
+	if len(diff.Changed) != 0 {
+		t.Errorf("Changed = %v, want none", diff.Changed)
+	}
+}
+
+func TestLoadStateFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "state.json")
+
+	if _, err := LoadStateFile(path); err == nil {
+		t.Error("LoadStateFile() should fail for a missing file")
+	}
+
+	manager := NewManager(path)
+	manager.AddDocument(&DocumentState{ID: "doc1", Name: "Doc", Version: 2})
+	if err := manager.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := LoadStateFile(path)
+	if err != nil {
+		t.Fatalf("LoadStateFile() error = %v", err)
+	}
+	if doc := loaded.GetDocument("doc1"); doc == nil || doc.Version != 2 {
+		t.Errorf("loaded document = %+v, want version 2", doc)
+	}
+}
+
+func TestLoadStateFile_CorruptIgnoresBackup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	// Two saves leave a readable backup
+	manager := NewManager(path)
+	manager.AddDocument(&DocumentState{ID: "doc1", Name: "Doc", Version: 2})
+	if err := manager.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err := manager.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"version": 2, "documents": {`), 0644); err != nil {
+		t.Fatalf("failed to corrupt state file: %v", err)
+	}
+
+	if _, err := LoadStateFile(path); err == nil {
+		t.Error("LoadStateFile() should fail for a corrupt file instead of reading the backup")
+	}
+}
@@ -0,0 +1,106 @@
+package state
+
+import "time"
+
+// IndexEntry is a compact summary of a document's last synced state
+type IndexEntry struct {
+	Version   int
+	Modified  int64 // ModifiedClient in Unix seconds
+	Hash      string
+	LocalPath string
+	Size      int64          // .rmdoc size at the last sync (0 if unknown)
+	Synced    bool           // false if the document has never been synced
+	NeedsOCR  bool           // converted, but not OCR'd since it was last synced
+	Deferred  DeferredReason // work queued for a later sync ("" if none)
+
+	RetryCount    int // failed attempts to sync FailedVersion
+	FailedVersion int
+}
+
+// Index is a read-only snapshot of document sync state keyed by document ID
+//
+// It is built once per sync run so change detection for each remote document is a
+// single map lookup against precomputed values, without locking the Manager.
+type Index struct {
+	entries map[string]*IndexEntry
+}
+
+// NewIndex builds an index from a sync state
+func NewIndex(ss *SyncState) *Index {
+	idx := &Index{}
+	if ss == nil {
+		idx.entries = make(map[string]*IndexEntry)
+		return idx
+	}
+
+	idx.entries = make(map[string]*IndexEntry, len(ss.Documents))
+	for id, doc := range ss.Documents {
+		if doc == nil {
+			continue
+		}
+		idx.entries[id] = &IndexEntry{
+			Version:   doc.Version,
+			Modified:  doc.ModifiedClient.Unix(),
+			Hash:      doc.Hash,
+			LocalPath: doc.LocalPath,
+			Size:      doc.Size,
+			Synced:    !doc.LastSynced.IsZero(),
+			NeedsOCR:  doc.NeedsOCR(),
+			Deferred:  doc.deferredReason(),
+
+			RetryCount:    doc.RetryCount,
+			FailedVersion: doc.FailedVersion,
+		}
+	}
+
+	return idx
+}
+
+// Lookup returns the index entry for a document ID
+func (idx *Index) Lookup(id string) (IndexEntry, bool) {
+	entry, ok := idx.entries[id]
+	if !ok {
+		return IndexEntry{}, false
+	}
+	return *entry, true
+}
+
+// NeedsSync reports whether a remote document is new or has changed since it was last synced
+//
+// Uses the same rules as DocumentState.NeedsSync.
+func (idx *Index) NeedsSync(id string, remoteVersion int, remoteModified time.Time) bool {
+	entry, ok := idx.entries[id]
+	if !ok || !entry.Synced {
+		return true
+	}
+
+	if remoteVersion > entry.Version {
+		return true
+	}
+
+	// Compare at one second resolution, matching DocumentState.NeedsSync
+	return remoteModified.Unix() > entry.Modified
+}
+
+// RetriesExhausted reports whether a remote document version has failed to sync more
+// than maxRetries times
+//
+// Uses the same rules as DocumentState.RetriesExhausted.
+func (idx *Index) RetriesExhausted(id string, remoteVersion, maxRetries int) bool {
+	entry, ok := idx.entries[id]
+	return ok && entry.FailedVersion == remoteVersion && entry.RetryCount > maxRetries
+}
+
+// Len returns the number of indexed documents
+func (idx *Index) Len() int {
+	return len(idx.entries)
+}
+
+// BuildIndex returns a snapshot index of the current state
+//
+// The index does not reflect changes made after it is built.
+func (m *memoryState) BuildIndex() *Index {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return NewIndex(m.state)
+}
@@ -0,0 +1,180 @@
+package state
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestIndex_NeedsSyncMatchesDocumentState(t *testing.T) {
+	base := time.Date(2025, 6, 1, 12, 0, 0, 500_000_000, time.UTC)
+
+	synced := NewDocumentState("synced", "Synced", "DocumentType", "")
+	synced.MarkSynced(3, base, "/out/synced.pdf", "abc123")
+
+	neverSynced := NewDocumentState("never-synced", "Never Synced", "DocumentType", "")
+
+	ss := NewSyncState()
+	ss.AddDocument(synced)
+	ss.AddDocument(neverSynced)
+
+	idx := NewIndex(ss)
+
+	tests := []struct {
+		name           string
+		id             string
+		remoteVersion  int
+		remoteModified time.Time
+		want           bool
+	}{
+		{name: "unchanged", id: "synced", remoteVersion: 3, remoteModified: base, want: false},
+		{name: "sub-second modification is ignored", id: "synced", remoteVersion: 3, remoteModified: base.Add(400 * time.Millisecond), want: false},
+		{name: "newer version", id: "synced", remoteVersion: 4, remoteModified: base, want: true},
+		{name: "older version", id: "synced", remoteVersion: 2, remoteModified: base, want: false},
+		{name: "newer modification", id: "synced", remoteVersion: 3, remoteModified: base.Add(2 * time.Second), want: true},
+		{name: "never synced", id: "never-synced", remoteVersion: 1, remoteModified: base, want: true},
+		{name: "unknown document", id: "missing", remoteVersion: 1, remoteModified: base, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := idx.NeedsSync(tt.id, tt.remoteVersion, tt.remoteModified)
+			if got != tt.want {
+				t.Errorf("NeedsSync() = %v, want %v", got, tt.want)
+			}
+
+			// The index must agree with the state it was built from
+			if doc := ss.GetDocument(tt.id); doc != nil {
+				if stateGot := doc.NeedsSync(tt.remoteVersion, tt.remoteModified); stateGot != got {
+					t.Errorf("index NeedsSync() = %v, DocumentState.NeedsSync() = %v", got, stateGot)
+				}
+			}
+		})
+	}
+}
+
+func TestManager_BuildIndex(t *testing.T) {
+	manager := NewManager(t.TempDir() + "/state.json")
+
+	doc := NewDocumentState("doc-1", "Doc", "DocumentType", "")
+	doc.MarkSynced(2, time.Now(), "/out/doc.pdf", "hash-1")
+	manager.AddDocument(doc)
+
+	idx := manager.BuildIndex()
+	if idx.Len() != 1 {
+		t.Fatalf("Len() = %d, want 1", idx.Len())
+	}
+
+	entry, ok := idx.Lookup("doc-1")
+	if !ok {
+		t.Fatal("Lookup() did not find doc-1")
+	}
+	if entry.Version != 2 || entry.Hash != "hash-1" || entry.LocalPath != "/out/doc.pdf" || !entry.Synced {
+		t.Errorf("Lookup() = %+v, unexpected entry", entry)
+	}
+
+	// The index is a snapshot and is unaffected by later changes
+	manager.RemoveDocument("doc-1")
+	if _, ok := idx.Lookup("doc-1"); !ok {
+		t.Error("snapshot index should still contain doc-1 after removal from state")
+	}
+	if manager.BuildIndex().Len() != 0 {
+		t.Error("rebuilt index should be empty after removal")
+	}
+}
+
+func TestIndex_NeedsOCR(t *testing.T) {
+	doc := NewDocumentState("doc-1", "Doc", "DocumentType", "")
+	doc.MarkSynced(1, time.Now(), "/out/doc.pdf", "")
+	doc.SetConversionStatus(ConversionStatusCompleted)
+
+	ss := NewSyncState()
+	ss.AddDocument(doc)
+	if entry, _ := NewIndex(ss).Lookup("doc-1"); !entry.NeedsOCR {
+		t.Error("NeedsOCR = false for a document synced without OCR")
+	}
+
+	doc.MarkOCRComplete()
+	if entry, _ := NewIndex(ss).Lookup("doc-1"); entry.NeedsOCR {
+		t.Error("NeedsOCR = true after OCR completed")
+	}
+}
+
+func TestIndex_RetriesExhausted(t *testing.T) {
+	failing := NewDocumentState("failing", "Failing", "DocumentType", "")
+	for i := 0; i < 3; i++ {
+		failing.MarkFailed(2, fmt.Errorf("attempt %d failed", i+1))
+	}
+
+	ss := NewSyncState()
+	ss.AddDocument(failing)
+	idx := NewIndex(ss)
+
+	if !idx.RetriesExhausted("failing", 2, 2) {
+		t.Error("RetriesExhausted() should be true after 3 failures with 2 retries")
+	}
+	if idx.RetriesExhausted("failing", 2, 3) {
+		t.Error("RetriesExhausted() should be false while retries remain")
+	}
+	if idx.RetriesExhausted("failing", 3, 2) {
+		t.Error("RetriesExhausted() should be false for a new remote version")
+	}
+	if idx.RetriesExhausted("missing", 2, 0) {
+		t.Error("RetriesExhausted() should be false for unknown documents")
+	}
+}
+
+func TestNewIndex_NilState(t *testing.T) {
+	idx := NewIndex(nil)
+	if idx.Len() != 0 {
+		t.Errorf("Len() = %d, want 0", idx.Len())
+	}
+	if !idx.NeedsSync("doc", 1, time.Now()) {
+		t.Error("NeedsSync() should be true for an empty index")
+	}
+}
+
+// benchmarkLibrary builds a manager with n synced documents
+func benchmarkLibrary(b *testing.B, n int) (*Manager, []string, time.Time) {
+	b.Helper()
+
+	base := time.Now().Add(-time.Hour)
+	manager := NewManager(b.TempDir() + "/state.json")
+	ids := make([]string, n)
+	for i := 0; i < n; i++ {
+		ids[i] = fmt.Sprintf("doc-%06d", i)
+		doc := NewDocumentState(ids[i], ids[i], "DocumentType", "")
+		doc.MarkSynced(1, base, "/out/"+ids[i]+".pdf", "")
+		manager.AddDocument(doc)
+	}
+
+	return manager, ids, base
+}
+
+func BenchmarkNeedsSync_ManagerLookup(b *testing.B) {
+	manager, ids, base := benchmarkLibrary(b, 100_000)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, id := range ids {
+			doc := manager.GetDocument(id)
+			if doc == nil || doc.NeedsSync(1, base) {
+				b.Fatalf("unexpected sync needed for %s", id)
+			}
+		}
+	}
+}
+
+func BenchmarkNeedsSync_Index(b *testing.B) {
+	manager, ids, base := benchmarkLibrary(b, 100_000)
+	idx := manager.BuildIndex()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, id := range ids {
+			if idx.NeedsSync(id, 1, base) {
+				b.Fatalf("unexpected sync needed for %s", id)
+			}
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// lockRetryInterval is how often a held lock is retried while waiting for it
+const lockRetryInterval = 50 * time.Millisecond
+
+// ErrLocked is returned when the state lock is held by another process for longer
+// than the lock timeout
+var ErrLocked = errors.New("state is locked by another legible process")
+
+// FileLock is an advisory lock on a state file, held in a sidecar .lock file
+//
+// The lock only guards processes that take it: the daemon and the CLI take it around
+// each load, update, and save of the state, so their saves can't interleave.
+type FileLock struct {
+	file *os.File
+}
+
+// LockPath returns the path of the lock file guarding the state file at path
+func LockPath(path string) string {
+	return path + ".lock"
+}
+
+// LockFile takes the exclusive lock on the state file at path, waiting up to timeout
+// for another process to release it
+// A timeout of 0 tries once. The error wraps ErrLocked if the lock stayed held.
+func LockFile(path string, timeout time.Duration) (*FileLock, error) {
+	lockPath := LockPath(path)
+	if err := os.MkdirAll(filepath.Dir(lockPath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	file, err := os.OpenFile(lockPath, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open state lock: %w", err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		locked, err := tryLock(file)
+		if err != nil {
+			_ = file.Close()
+			return nil, fmt.Errorf("failed to lock state: %w", err)
+		}
+		if locked {
+			return &FileLock{file: file}, nil
+		}
+		if !time.Now().Before(deadline) {
+			_ = file.Close()
+			return nil, fmt.Errorf("%w: gave up on %s after %s (is the daemon syncing?)", ErrLocked, lockPath, timeout)
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
+
+// Unlock releases the lock
+func (l *FileLock) Unlock() error {
+	if err := unlock(l.file); err != nil {
+		_ = l.file.Close()
+		return fmt.Errorf("failed to unlock state: %w", err)
+	}
+	return l.file.Close()
+}
@@ -0,0 +1,156 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLockFile_TimesOutWhileHeld(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	held, err := LockFile(path, 0)
+	if err != nil {
+		t.Fatalf("LockFile() error = %v", err)
+	}
+	defer func() { _ = held.Unlock() }()
+
+	start := time.Now()
+	_, err = LockFile(path, 200*time.Millisecond)
+	if !errors.Is(err, ErrLocked) {
+		t.Fatalf("LockFile() error = %v, want ErrLocked", err)
+	}
+	if waited := time.Since(start); waited < 200*time.Millisecond {
+		t.Errorf("LockFile() gave up after %s, want the full timeout", waited)
+	}
+}
+
+func TestLockFile_WaitsForRelease(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	held, err := LockFile(path, 0)
+	if err != nil {
+		t.Fatalf("LockFile() error = %v", err)
+	}
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		_ = held.Unlock()
+	}()
+
+	lock, err := LockFile(path, 5*time.Second)
+	if err != nil {
+		t.Fatalf("LockFile() error = %v, want the lock once released", err)
+	}
+	if err := lock.Unlock(); err != nil {
+		t.Errorf("Unlock() error = %v", err)
+	}
+}
+
+// lockHelperEnv runs the test binary as a lock contender instead of running tests
+const lockHelperEnv = "LEGIBLE_STATE_LOCK_HELPER"
+
+// lockContenderSaves is the number of documents each contender adds, one save at a time
+const lockContenderSaves = 15
+
+func TestMain(m *testing.M) {
+	if name := os.Getenv(lockHelperEnv); name != "" {
+		if err := contendForLock(name, os.Getenv("LEGIBLE_STATE_BACKEND"), os.Getenv("LEGIBLE_STATE_FILE")); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// contendForLock adds documents to the store at path one at a time, each in a locked
+// load, update, and save, as concurrent syncs do
+func contendForLock(name, backend, path string) error {
+	store, err := Open(backend, path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	for i := 0; i < lockContenderSaves; i++ {
+		lock, err := store.Lock(30 * time.Second)
+		if err != nil {
+			return err
+		}
+		if err := store.Load(); err != nil {
+			return err
+		}
+		store.AddDocument(syncedDocument(name + "-" + strconv.Itoa(i)))
+		// Widen the window between load and save that the lock has to protect
+		time.Sleep(time.Millisecond)
+		if err := store.Save(); err != nil {
+			return err
+		}
+		if err := lock.Unlock(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestStore_LockSerializesProcesses(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, store Store, open func() Store) {
+		path := ""
+		switch s := store.(type) {
+		case *Manager:
+			path = s.filePath
+		case *SQLiteStore:
+			path = s.path
+		}
+
+		// Each contender is a separate process, as the daemon and the CLI are
+		contenders := []string{"daemon", "cli"}
+		var wg sync.WaitGroup
+		errs := make([]error, len(contenders))
+		for i, name := range contenders {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				cmd := exec.Command(os.Args[0], "-test.run=^$")
+				cmd.Env = append(os.Environ(),
+					lockHelperEnv+"="+name,
+					"LEGIBLE_STATE_BACKEND="+backendOf(store),
+					"LEGIBLE_STATE_FILE="+path)
+				if out, err := cmd.CombinedOutput(); err != nil {
+					errs[i] = fmt.Errorf("%s: %w: %s", name, err, out)
+				}
+			}()
+		}
+		wg.Wait()
+		for _, err := range errs {
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		// No contender's save overwrote another's
+		reloaded := open()
+		if got, want := reloaded.Count(), len(contenders)*lockContenderSaves; got != want {
+			t.Fatalf("Count() = %d, want %d documents from every save", got, want)
+		}
+		for _, name := range contenders {
+			for i := 0; i < lockContenderSaves; i++ {
+				if id := name + "-" + strconv.Itoa(i); reloaded.GetDocument(id) == nil {
+					t.Errorf("document %s was lost", id)
+				}
+			}
+		}
+	})
+}
+
+// backendOf returns the backend name of store
+func backendOf(store Store) string {
+	if _, ok := store.(*SQLiteStore); ok {
+		return BackendSQLite
+	}
+	return BackendJSON
+}
@@ -0,0 +1,78 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrUnsupportedVersion is returned when a state file's version can't be read by
+// this legible, typically because a newer legible wrote it
+var ErrUnsupportedVersion = errors.New("unsupported state file version")
+
+// migration upgrades a decoded state file by one version, in place
+type migration func(state map[string]any) error
+
+// migrations upgrade older state files; migrations[v] upgrades version v to v+1
+// Bumping StateFileVersion requires adding the migration from the previous version.
+var migrations = map[int]migration{
+	1: migrateV1ToV2,
+}
+
+// migrateState upgrades the JSON of a state file to StateFileVersion, reporting
+// whether it was changed
+//
+// Migrations run on the decoded JSON rather than SyncState so they can rename or
+// restructure fields. Versions newer than StateFileVersion are an error, since
+// downgrading could lose state written by a newer legible.
+func migrateState(data []byte) ([]byte, bool, error) {
+	var header struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, false, err
+	}
+	if header.Version == StateFileVersion {
+		return data, false, nil
+	}
+	if header.Version < 1 || header.Version > StateFileVersion {
+		return nil, false, fmt.Errorf("%w %d (expected %d or earlier)", ErrUnsupportedVersion, header.Version, StateFileVersion)
+	}
+
+	var state map[string]any
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, false, err
+	}
+	for v := header.Version; v < StateFileVersion; v++ {
+		migrate, ok := migrations[v]
+		if !ok {
+			return nil, false, fmt.Errorf("no migration from state file version %d", v)
+		}
+		if err := migrate(state); err != nil {
+			return nil, false, fmt.Errorf("failed to migrate state file from version %d: %w", v, err)
+		}
+		state["version"] = v + 1
+	}
+
+	migrated, err := json.Marshal(state)
+	if err != nil {
+		return nil, false, err
+	}
+	return migrated, true, nil
+}
+
+// migrateV1ToV2 adds first_synced to each document
+// Version 1 didn't record the first sync, so the last sync is the best estimate.
+func migrateV1ToV2(state map[string]any) error {
+	docs, _ := state["documents"].(map[string]any)
+	for id, value := range docs {
+		doc, ok := value.(map[string]any)
+		if !ok {
+			return fmt.Errorf("document %s is not an object", id)
+		}
+		if _, ok := doc["first_synced"]; !ok {
+			doc["first_synced"] = doc["last_synced"]
+		}
+	}
+	return nil
+}
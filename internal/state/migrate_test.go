@@ -0,0 +1,231 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// v1StateFile is a state file written before first_synced was tracked
+const v1StateFile = `{
+  "last_sync": "2025-03-01T10:00:00Z",
+  "documents": {
+    "doc-1": {
+      "id": "doc-1",
+      "name": "Journal",
+      "version": 4,
+      "modified_client": "2025-02-28T09:00:00Z",
+      "last_synced": "2025-03-01T10:00:00Z",
+      "local_path": "/out/Journal.pdf",
+      "hash": "abc",
+      "type": "DocumentType",
+      "parent": "",
+      "ocr_processed": true,
+      "conversion_status": "completed",
+      "retry_count": 0
+    }
+  },
+  "version": 1
+}`
+
+func TestManager_Load_MigratesV1(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "state.json")
+	if err := os.WriteFile(filePath, []byte(v1StateFile), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	manager := NewManager(filePath)
+	if err := manager.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	wantSynced := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	if got := manager.GetState().Version; got != StateFileVersion {
+		t.Errorf("Version = %d, want %d", got, StateFileVersion)
+	}
+	doc := manager.GetDocument("doc-1")
+	if doc == nil {
+		t.Fatal("document lost in migration")
+	}
+	if !doc.FirstSynced.Equal(wantSynced) {
+		t.Errorf("FirstSynced = %v, want the last sync %v", doc.FirstSynced, wantSynced)
+	}
+	if doc.Version != 4 || doc.LocalPath != "/out/Journal.pdf" || !doc.OCRProcessed {
+		t.Errorf("existing fields changed in migration: %+v", doc)
+	}
+
+	// The upgraded state is saved, keeping the version 1 file as the backup
+	var saved SyncState
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatal(err)
+	}
+	if saved.Version != StateFileVersion || !saved.Documents["doc-1"].FirstSynced.Equal(wantSynced) {
+		t.Errorf("saved state = version %d, document %+v; want upgraded", saved.Version, saved.Documents["doc-1"])
+	}
+	backup, err := os.ReadFile(manager.backupPath())
+	if err != nil {
+		t.Fatalf("no backup of the version 1 file: %v", err)
+	}
+	if string(backup) != v1StateFile {
+		t.Error("backup is not the version 1 file")
+	}
+}
+
+func TestManager_Load_NewerVersion(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "state.json")
+	data := strings.Replace(v1StateFile, `"version": 1`, `"version": 99`, 1)
+	if err := os.WriteFile(filePath, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := NewManager(filePath).Load()
+	if err == nil || !strings.Contains(err.Error(), "unsupported state file version 99") {
+		t.Errorf("Load() error = %v, want unsupported version 99", err)
+	}
+
+	// The newer file is left alone
+	if after, _ := os.ReadFile(filePath); string(after) != data {
+		t.Error("state file from a newer version was modified")
+	}
+}
+
+func TestManager_Load_NewerVersionWithBackup(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "state.json")
+	data := strings.Replace(v1StateFile, `"version": 1`, `"version": 99`, 1)
+	if err := os.WriteFile(filePath, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filePath+".bak", []byte(v1StateFile), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := NewManager(filePath).Load()
+	if !errors.Is(err, ErrUnsupportedVersion) {
+		t.Errorf("Load() error = %v, want ErrUnsupportedVersion", err)
+	}
+
+	// Neither the newer file nor its backup is touched
+	if after, _ := os.ReadFile(filePath); string(after) != data {
+		t.Error("state file from a newer version was replaced by its backup")
+	}
+	if backup, _ := os.ReadFile(filePath + ".bak"); string(backup) != v1StateFile {
+		t.Error("state backup was modified")
+	}
+}
+
+func TestLoadStateFile_DoesNotSaveMigration(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "state.json")
+	if err := os.WriteFile(filePath, []byte(v1StateFile), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadStateFile(filePath)
+	if err != nil {
+		t.Fatalf("LoadStateFile() error = %v", err)
+	}
+	if loaded.Version != StateFileVersion {
+		t.Errorf("Version = %d, want %d", loaded.Version, StateFileVersion)
+	}
+	if after, _ := os.ReadFile(filePath); string(after) != v1StateFile {
+		t.Error("LoadStateFile() rewrote the state file")
+	}
+}
+
+// writeV1SQLite stores the document of v1StateFile in a SQLite state database as
+// version 1, before first_synced was tracked
+func writeV1SQLite(t *testing.T, store *SQLiteStore) {
+	t.Helper()
+	var v1 SyncState
+	if err := json.Unmarshal([]byte(v1StateFile), &v1); err != nil {
+		t.Fatal(err)
+	}
+	doc, err := json.Marshal(v1.Documents["doc-1"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc = []byte(strings.Replace(string(doc), `"first_synced":"0001-01-01T00:00:00Z",`, "", 1))
+	if _, err := store.db.Exec("INSERT INTO meta (key, value) VALUES ('version', '1')"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.db.Exec("INSERT INTO documents (id, data) VALUES (?, ?)", "doc-1", string(doc)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSQLiteStore_Load_MigratesV1(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.db")
+	store, err := OpenSQLite(path)
+	if err != nil {
+		t.Fatalf("OpenSQLite() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+	writeV1SQLite(t, store)
+
+	if err := store.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got := store.GetDocument("doc-1"); got == nil || !got.FirstSynced.Equal(got.LastSynced) || got.FirstSynced.IsZero() {
+		t.Errorf("document = %+v, want FirstSynced set from LastSynced", got)
+	}
+
+	var version, data string
+	if err := store.db.QueryRow("SELECT value FROM meta WHERE key = 'version'").Scan(&version); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.db.QueryRow("SELECT data FROM documents WHERE id = 'doc-1'").Scan(&data); err != nil {
+		t.Fatal(err)
+	}
+	if version != strconv.Itoa(StateFileVersion) || !strings.Contains(data, `"first_synced":"2025-03-01T10:00:00Z"`) {
+		t.Errorf("saved version %s, document %s; want upgraded", version, data)
+	}
+}
+
+func TestLoadStateFile_SQLiteDoesNotSaveMigration(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.db")
+	store, err := OpenSQLite(path)
+	if err != nil {
+		t.Fatalf("OpenSQLite() error = %v", err)
+	}
+	writeV1SQLite(t, store)
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Backdate the database, so any write would show in its modification time
+	modTime := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+	before, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadStateFile(path)
+	if err != nil {
+		t.Fatalf("LoadStateFile() error = %v", err)
+	}
+	if doc := loaded.GetDocument("doc-1"); doc == nil || doc.FirstSynced.IsZero() {
+		t.Errorf("document = %+v, want it upgraded in memory", doc)
+	}
+
+	after, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(after) != string(before) {
+		t.Error("LoadStateFile() rewrote the state database")
+	}
+	if info, err := os.Stat(path); err != nil || !info.ModTime().Equal(modTime) {
+		t.Errorf("state database modified at %v, want %v unchanged", info.ModTime(), modTime)
+	}
+}
@@ -0,0 +1,372 @@
+package state
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	_ "modernc.org/sqlite" // registers the "sqlite" database/sql driver
+
+	"github.com/platinummonkey/legible/internal/logger"
+)
+
+// sqliteSchema creates the tables of a SQLite state database
+// Documents are stored as the same JSON objects written to the state file.
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS meta (
+	key   TEXT PRIMARY KEY,
+	value TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS documents (
+	id   TEXT PRIMARY KEY,
+	data TEXT NOT NULL
+);`
+
+// sqliteHeader is the magic string at the start of every SQLite database file
+const sqliteHeader = "SQLite format 3\x00"
+
+// SQLiteStore is a Store that keeps the sync state in a SQLite database
+//
+// Save only writes the documents added or changed since the last Load or Save, and
+// deletes the ones removed, in a single transaction, so saving after each document
+// doesn't rewrite the whole state as the library grows.
+type SQLiteStore struct {
+	memoryState
+	db   *sql.DB
+	path string
+
+	// saveMu serializes Load and Save and guards saved
+	saveMu sync.Mutex
+	// saved holds each document's JSON as last loaded or saved
+	saved map[string][]byte
+}
+
+// OpenSQLite opens the SQLite state database at path, creating it if it doesn't exist,
+// and loads its state
+func OpenSQLite(path string) (*SQLiteStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	dsn := "file:" + (&url.URL{Path: path}).EscapedPath() +
+		"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open state database: %w", err)
+	}
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to create state database schema: %w", err)
+	}
+
+	s := &SQLiteStore{
+		memoryState: memoryState{state: NewSyncState()},
+		db:          db,
+		path:        path,
+		saved:       make(map[string][]byte),
+	}
+	if err := s.Load(); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// readSQLiteState reads the sync state of the SQLite state database at path without
+// writing to it
+//
+// The database is opened read-only, so unlike OpenSQLite no schema is created, the
+// journal mode is left alone, and an older database is only upgraded in memory.
+func readSQLiteState(path string) (*SyncState, error) {
+	dsn := "file:" + (&url.URL{Path: path}).EscapedPath() + "?mode=ro&_pragma=busy_timeout(5000)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open state database: %w", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	state, _, _, err := (&SQLiteStore{db: db, path: path}).readState()
+	return state, err
+}
+
+// Load reads the sync state from the database, discarding unsaved changes
+// A database from an older version is upgraded and saved at StateFileVersion.
+func (s *SQLiteStore) Load() error {
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+
+	state, saved, migrated, err := s.readState()
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.state = state
+	s.mu.Unlock()
+	s.saved = saved
+
+	// Upgraded databases are fully rewritten
+	if migrated {
+		logger.WithFields("version", StateFileVersion).Info("Upgraded state database")
+		if err := s.save(); err != nil {
+			return fmt.Errorf("failed to save upgraded state database: %w", err)
+		}
+	}
+	return nil
+}
+
+// readState reads the sync state from the database, upgrading the documents of an
+// older database by the state file migrations
+//
+// It also returns the JSON of each document as stored, which is empty for an upgraded
+// database, and whether the database was upgraded.
+func (s *SQLiteStore) readState() (*SyncState, map[string][]byte, bool, error) {
+	state := NewSyncState()
+
+	meta, err := s.readMeta()
+	if err != nil {
+		return nil, nil, false, err
+	}
+	version := StateFileVersion
+	if v, ok := meta["version"]; ok {
+		if version, err = strconv.Atoi(v); err != nil {
+			return nil, nil, false, fmt.Errorf("invalid state database version %q: %w", v, err)
+		}
+	}
+	if v, ok := meta["last_sync"]; ok {
+		if state.LastSync, err = time.Parse(time.RFC3339Nano, v); err != nil {
+			return nil, nil, false, fmt.Errorf("failed to parse last sync time: %w", err)
+		}
+	}
+	if v, ok := meta["history"]; ok {
+		if err := json.Unmarshal([]byte(v), &state.History); err != nil {
+			return nil, nil, false, fmt.Errorf("failed to parse sync history: %w", err)
+		}
+	}
+
+	docs, err := s.readDocuments()
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	migrated := version != StateFileVersion
+	if migrated {
+		if docs, err = migrateDocuments(version, docs); err != nil {
+			return nil, nil, false, err
+		}
+	}
+
+	saved := make(map[string][]byte, len(docs))
+	for id, data := range docs {
+		var doc DocumentState
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, nil, false, fmt.Errorf("failed to parse document %s: %w", id, err)
+		}
+		state.Documents[id] = &doc
+		if !migrated {
+			saved[id] = data
+		}
+	}
+	return state, saved, migrated, nil
+}
+
+// readDocuments returns the JSON of every document in the database by ID
+func (s *SQLiteStore) readDocuments() (map[string][]byte, error) {
+	rows, err := s.db.Query("SELECT id, data FROM documents")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read documents: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	docs := make(map[string][]byte)
+	for rows.Next() {
+		var id string
+		var data []byte
+		if err := rows.Scan(&id, &data); err != nil {
+			return nil, fmt.Errorf("failed to read document: %w", err)
+		}
+		docs[id] = data
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read documents: %w", err)
+	}
+	return docs, nil
+}
+
+// migrateDocuments upgrades documents stored at version to StateFileVersion by running
+// the state file migrations over them
+func migrateDocuments(version int, docs map[string][]byte) (map[string][]byte, error) {
+	raw := make(map[string]json.RawMessage, len(docs))
+	for id, data := range docs {
+		raw[id] = data
+	}
+	data, err := json.Marshal(map[string]any{"version": version, "documents": raw})
+	if err != nil {
+		return nil, err
+	}
+	if data, _, err = migrateState(data); err != nil {
+		return nil, err
+	}
+
+	var migrated struct {
+		Documents map[string]json.RawMessage `json:"documents"`
+	}
+	if err := json.Unmarshal(data, &migrated); err != nil {
+		return nil, err
+	}
+	docs = make(map[string][]byte, len(migrated.Documents))
+	for id, doc := range migrated.Documents {
+		docs[id] = doc
+	}
+	return docs, nil
+}
+
+// readMeta returns the key/value pairs of the meta table
+func (s *SQLiteStore) readMeta() (map[string]string, error) {
+	rows, err := s.db.Query("SELECT key, value FROM meta")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state metadata: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	meta := make(map[string]string)
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, fmt.Errorf("failed to read state metadata: %w", err)
+		}
+		meta[key] = value
+	}
+	return meta, rows.Err()
+}
+
+// Save writes the documents that changed since the last Load or Save, and deletes the
+// documents that were removed, in one transaction
+func (s *SQLiteStore) Save() error {
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+	return s.save()
+}
+
+// save writes the changed documents with saveMu held
+func (s *SQLiteStore) save() error {
+	s.mu.RLock()
+	lastSync := s.state.LastSync
+	history, err := json.Marshal(s.state.History)
+	if err != nil {
+		s.mu.RUnlock()
+		return fmt.Errorf("failed to marshal sync history: %w", err)
+	}
+	changed := make(map[string][]byte)
+	current := make(map[string]bool, len(s.state.Documents))
+	for id, doc := range s.state.Documents {
+		current[id] = true
+		data, err := json.Marshal(doc)
+		if err != nil {
+			s.mu.RUnlock()
+			return fmt.Errorf("failed to marshal document %s: %w", id, err)
+		}
+		if !bytes.Equal(data, s.saved[id]) {
+			changed[id] = data
+		}
+	}
+	s.mu.RUnlock()
+
+	var removed []string
+	for id := range s.saved {
+		if !current[id] {
+			removed = append(removed, id)
+		}
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin state transaction: %w", err)
+	}
+	if err := writeChanges(tx, lastSync, history, changed, removed); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit state: %w", err)
+	}
+
+	for id, data := range changed {
+		s.saved[id] = data
+	}
+	for _, id := range removed {
+		delete(s.saved, id)
+	}
+	return nil
+}
+
+// writeChanges upserts the changed documents and deletes the removed ones within tx
+func writeChanges(tx *sql.Tx, lastSync time.Time, history []byte, changed map[string][]byte, removed []string) error {
+	const upsertMeta = "INSERT INTO meta (key, value) VALUES (?, ?) ON CONFLICT(key) DO UPDATE SET value = excluded.value"
+	if _, err := tx.Exec(upsertMeta, "version", strconv.Itoa(StateFileVersion)); err != nil {
+		return fmt.Errorf("failed to write state version: %w", err)
+	}
+	if _, err := tx.Exec(upsertMeta, "last_sync", lastSync.Format(time.RFC3339Nano)); err != nil {
+		return fmt.Errorf("failed to write last sync time: %w", err)
+	}
+	if _, err := tx.Exec(upsertMeta, "history", string(history)); err != nil {
+		return fmt.Errorf("failed to write sync history: %w", err)
+	}
+
+	if len(changed) > 0 {
+		upsert, err := tx.Prepare("INSERT INTO documents (id, data) VALUES (?, ?) ON CONFLICT(id) DO UPDATE SET data = excluded.data")
+		if err != nil {
+			return fmt.Errorf("failed to prepare document upsert: %w", err)
+		}
+		defer func() { _ = upsert.Close() }()
+		for id, data := range changed {
+			if _, err := upsert.Exec(id, string(data)); err != nil {
+				return fmt.Errorf("failed to write document %s: %w", id, err)
+			}
+		}
+	}
+
+	for _, id := range removed {
+		if _, err := tx.Exec("DELETE FROM documents WHERE id = ?", id); err != nil {
+			return fmt.Errorf("failed to delete document %s: %w", id, err)
+		}
+	}
+	return nil
+}
+
+// Lock takes the lock on the state database, waiting up to timeout
+func (s *SQLiteStore) Lock(timeout time.Duration) (*FileLock, error) {
+	return LockFile(s.path, timeout)
+}
+
+// Close closes the database
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+// isSQLiteFile reports whether the file at path is a SQLite database
+func isSQLiteFile(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = f.Close() }()
+
+	header := make([]byte, len(sqliteHeader))
+	if _, err := io.ReadFull(f, header); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return false, nil
+		}
+		return false, err
+	}
+	return string(header) == sqliteHeader, nil
+}
@@ -3,29 +3,36 @@ package state
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
-	"sync"
+	"time"
+
+	"github.com/platinummonkey/legible/internal/logger"
 )
 
-// Manager handles sync state persistence and operations
+// Manager is the default Store, persisting the sync state to a JSON file
 type Manager struct {
-	state    *SyncState
+	memoryState
 	filePath string
-	mu       sync.RWMutex
+
+	// readOnly skips saving state files upgraded by Load and recovering from the backup
+	readOnly bool
 }
 
 // NewManager creates a new state manager
 func NewManager(filePath string) *Manager {
 	return &Manager{
-		state:    NewSyncState(),
-		filePath: filePath,
+		memoryState: memoryState{state: NewSyncState()},
+		filePath:    filePath,
 	}
 }
 
 // Load reads the sync state from the JSON file
 // If the file doesn't exist, returns a new empty state (not an error)
+// If the file can't be parsed, the backup from the previous Save is loaded instead
+// A state file from an older version is upgraded and saved at StateFileVersion.
 func (m *Manager) Load() error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -43,18 +50,100 @@ func (m *Manager) Load() error {
 		return fmt.Errorf("failed to read state file: %w", err)
 	}
 
-	// Unmarshal JSON
+	state, migrated, err := parseState(data)
+	if errors.Is(err, ErrUnsupportedVersion) {
+		// Never fall back or save over state written by a newer legible
+		return err
+	}
+	if err != nil {
+		// Only a torn or corrupted write falls back to the last good copy; any other
+		// failure would let the next Save replace a file that is valid but unreadable here
+		if !isCorruptState(err) || m.readOnly {
+			return fmt.Errorf("failed to parse state file: %w", err)
+		}
+
+		recovered, recoveredMigrated, backupErr := m.loadBackup()
+		if backupErr != nil {
+			logger.WithFields("path", m.filePath, "backup_error", backupErr).Debug("No usable state backup")
+			return fmt.Errorf("failed to parse state file: %w", err)
+		}
+
+		logger.WithFields("path", m.filePath, "backup", m.backupPath(), "error", err).
+			Warn("State file unreadable, recovered from backup")
+		state, migrated = recovered, recoveredMigrated
+	}
+
+	m.state = state
+	if migrated && !m.readOnly {
+		logger.WithFields("path", m.filePath, "version", StateFileVersion).Info("Upgraded state file")
+		if err := m.save(); err != nil {
+			return fmt.Errorf("failed to save upgraded state file: %w", err)
+		}
+	}
+	return nil
+}
+
+// parseState decodes the contents of a state file, upgrading older versions, and
+// reports whether it was upgraded
+func parseState(data []byte) (*SyncState, bool, error) {
+	data, migrated, err := migrateState(data)
+	if err != nil {
+		return nil, false, err
+	}
+
 	var state SyncState
 	if err := json.Unmarshal(data, &state); err != nil {
-		return fmt.Errorf("failed to parse state file: %w", err)
+		return nil, false, err
 	}
+	return &state, migrated, nil
+}
+
+// isCorruptState reports whether a parseState error means the file is damaged,
+// rather than well-formed state this version can't read
+func isCorruptState(err error) bool {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	return errors.As(err, &syntaxErr) || errors.As(err, &typeErr)
+}
 
-	// Validate version
-	if state.Version != StateFileVersion {
-		return fmt.Errorf("unsupported state file version %d (expected %d)", state.Version, StateFileVersion)
+// backupPath returns the path of the backup kept alongside the state file
+func (m *Manager) backupPath() string {
+	return m.filePath + ".bak"
+}
+
+// loadBackup reads the state backup written by the most recent Save
+func (m *Manager) loadBackup() (*SyncState, bool, error) {
+	data, err := os.ReadFile(m.backupPath())
+	if err != nil {
+		return nil, false, err
+	}
+	return parseState(data)
+}
+
+// backupCurrent copies the existing state file to the backup path before it is replaced
+// Files that don't parse are not backed up, so a corrupt state never overwrites a good backup.
+func (m *Manager) backupCurrent() error {
+	data, err := os.ReadFile(m.filePath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, _, err := parseState(data); err != nil {
+		return nil
+	}
+
+	tmpFile := m.backupPath() + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpFile, m.backupPath()); err != nil {
+		_ = os.Remove(tmpFile)
+		return err
 	}
 
-	m.state = &state
 	return nil
 }
 
@@ -62,7 +151,11 @@ func (m *Manager) Load() error {
 func (m *Manager) Save() error {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
+	return m.save()
+}
 
+// save writes the sync state with m.mu held
+func (m *Manager) save() error {
 	// Marshal to JSON with indentation for readability
 	data, err := json.MarshalIndent(m.state, "", "  ")
 	if err != nil {
@@ -75,9 +168,27 @@ func (m *Manager) Save() error {
 		return fmt.Errorf("failed to create state directory: %w", err)
 	}
 
+	// Keep the previous state as a backup for recovery in Load
+	if err := m.backupCurrent(); err != nil {
+		return fmt.Errorf("failed to back up state file: %w", err)
+	}
+
 	// Write atomically: write to temp file, then rename
-	tmpFile := m.filePath + ".tmp"
-	if err := os.WriteFile(tmpFile, data, 0644); err != nil {
+	// The temp file is unique so a process saving without the lock can't rename another's.
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(m.filePath)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temp state file: %w", err)
+	}
+	tmpFile := tmp.Name()
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmpFile, 0644)
+	}
+	if err != nil {
+		_ = os.Remove(tmpFile)
 		return fmt.Errorf("failed to write temp state file: %w", err)
 	}
 
@@ -90,62 +201,6 @@ func (m *Manager) Save() error {
 	return nil
 }
 
-// GetState returns a copy of the current sync state
-func (m *Manager) GetState() *SyncState {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
-	return m.state
-}
-
-// GetDocument returns the document state for a specific document ID
-func (m *Manager) GetDocument(id string) *DocumentState {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
-	return m.state.GetDocument(id)
-}
-
-// AddDocument adds or updates a document in the state
-func (m *Manager) AddDocument(doc *DocumentState) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-	m.state.AddDocument(doc)
-}
-
-// RemoveDocument removes a document from the state
-func (m *Manager) RemoveDocument(id string) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-	m.state.RemoveDocument(id)
-}
-
-// UpdateLastSync updates the last sync timestamp
-func (m *Manager) UpdateLastSync() {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-	m.state.UpdateLastSync()
-}
-
-// GetDocumentsByLabel returns all documents with a specific label
-func (m *Manager) GetDocumentsByLabel(label string) []*DocumentState {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
-	return m.state.GetDocumentsByLabel(label)
-}
-
-// GetDocumentsByStatus returns all documents with a specific conversion status
-func (m *Manager) GetDocumentsByStatus(status ConversionStatus) []*DocumentState {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
-	return m.state.GetDocumentsByStatus(status)
-}
-
-// GetDocumentsNeedingOCR returns all documents that need OCR processing
-func (m *Manager) GetDocumentsNeedingOCR() []*DocumentState {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
-	return m.state.GetDocumentsNeedingOCR()
-}
-
 // LoadOrCreate loads an existing state file or creates a new one if it doesn't exist
 // This is a convenience function that combines Load with automatic Save on new state
 func LoadOrCreate(filePath string) (*Manager, error) {
@@ -157,7 +212,7 @@ func LoadOrCreate(filePath string) (*Manager, error) {
 
 	// If this is a new state (no documents), save it to create the file
 	if len(manager.state.Documents) == 0 && manager.state.LastSync.IsZero() {
-		if err := manager.Save(); err != nil {
+		if err := manager.createInitial(); err != nil {
 			return nil, fmt.Errorf("failed to save initial state: %w", err)
 		}
 	}
@@ -165,16 +220,33 @@ func LoadOrCreate(filePath string) (*Manager, error) {
 	return manager, nil
 }
 
-// Reset clears all state and creates a fresh empty state
-func (m *Manager) Reset() {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-	m.state = NewSyncState()
+// createInitial saves the empty state under the state lock, unless another process
+// holds the lock or saved documents first, since it is about to write the state itself
+func (m *Manager) createInitial() error {
+	lock, err := m.Lock(0)
+	if errors.Is(err, ErrLocked) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer func() { _ = lock.Unlock() }()
+
+	if err := m.Load(); err != nil {
+		return err
+	}
+	if len(m.state.Documents) > 0 || !m.state.LastSync.IsZero() {
+		return nil
+	}
+	return m.Save()
 }
 
-// Count returns the total number of documents in the state
-func (m *Manager) Count() int {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
-	return len(m.state.Documents)
+// Close does nothing; the state file is only open while loading or saving
+func (m *Manager) Close() error {
+	return nil
+}
+
+// Lock takes the lock on the state file, waiting up to timeout
+func (m *Manager) Lock(timeout time.Duration) (*FileLock, error) {
+	return LockFile(m.filePath, timeout)
 }
@@ -97,6 +97,65 @@ func TestManager_Load_InvalidJSON(t *testing.T) {
 	}
 }
 
+func TestManager_Load_RecoversFromBackup(t *testing.T) {
+	tmpDir := t.TempDir()
+	filePath := filepath.Join(tmpDir, "state.json")
+
+	// Two saves leave the first state in the backup
+	manager := NewManager(filePath)
+	manager.AddDocument(NewDocumentState("doc-1", "First", "DocumentType", ""))
+	if err := manager.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	manager.AddDocument(NewDocumentState("doc-2", "Second", "DocumentType", ""))
+	if err := manager.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	// Corrupt the primary state file
+	if err := os.WriteFile(filePath, []byte(`{"version": 1, "documents": {`), 0644); err != nil {
+		t.Fatalf("failed to corrupt state file: %v", err)
+	}
+
+	recovered := NewManager(filePath)
+	if err := recovered.Load(); err != nil {
+		t.Fatalf("Load() error = %v, want recovery from backup", err)
+	}
+
+	if recovered.Count() != 1 || recovered.GetDocument("doc-1") == nil {
+		t.Errorf("recovered %d documents, want doc-1 from backup", recovered.Count())
+	}
+
+	// Saving the recovered state must not replace the good backup with the corrupt file
+	if err := recovered.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	backup, _, err := recovered.loadBackup()
+	if err != nil {
+		t.Fatalf("backup unreadable after save: %v", err)
+	}
+	if len(backup.Documents) != 1 {
+		t.Errorf("backup has %d documents, want 1", len(backup.Documents))
+	}
+}
+
+func TestManager_Load_CorruptBackup(t *testing.T) {
+	tmpDir := t.TempDir()
+	filePath := filepath.Join(tmpDir, "state.json")
+
+	if err := os.WriteFile(filePath, []byte("invalid json"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	if err := os.WriteFile(filePath+".bak", []byte("also invalid"), 0644); err != nil {
+		t.Fatalf("failed to write backup file: %v", err)
+	}
+
+	manager := NewManager(filePath)
+	if err := manager.Load(); err == nil {
+		t.Error("Load() should error when both state and backup are invalid")
+	}
+}
+
 func TestManager_Load_UnsupportedVersion(t *testing.T) {
 	tmpDir := t.TempDir()
 	filePath := filepath.Join(tmpDir, "state.json")
@@ -122,6 +181,35 @@ func TestManager_Load_UnsupportedVersion(t *testing.T) {
 	}
 }
 
+func TestManager_Load_VersionMismatchIgnoresBackup(t *testing.T) {
+	tmpDir := t.TempDir()
+	filePath := filepath.Join(tmpDir, "state.json")
+
+	// Two saves leave a readable backup
+	manager := NewManager(filePath)
+	manager.AddDocument(NewDocumentState("doc-1", "First", "DocumentType", ""))
+	if err := manager.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err := manager.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	// A well-formed state file from a newer version isn't corrupt
+	data := []byte(`{"version": 999, "documents": {}}`)
+	if err := os.WriteFile(filePath, data, 0644); err != nil {
+		t.Fatalf("failed to write test state file: %v", err)
+	}
+
+	if err := NewManager(filePath).Load(); err == nil {
+		t.Error("Load() should error rather than recover a version mismatch from backup")
+	}
+
+	if after, _ := os.ReadFile(filePath); string(after) != string(data) {
+		t.Error("state file with a version mismatch was modified")
+	}
+}
+
 func TestManager_Save(t *testing.T) {
 	tmpDir := t.TempDir()
 	filePath := filepath.Join(tmpDir, "state.json")
@@ -258,6 +346,30 @@ func TestManager_RemoveDocument(t *testing.T) {
 	}
 }
 
+func TestManager_RemoveDocumentsNotIn(t *testing.T) {
+	manager := NewManager("/tmp/test.json")
+	for _, id := range []string{"doc-3", "doc-1", "doc-2"} {
+		manager.AddDocument(NewDocumentState(id, id, "DocumentType", ""))
+	}
+	cloud := map[string]bool{"doc-2": true, "doc-4": true}
+
+	preview := manager.DocumentsNotIn(cloud)
+	if len(preview) != 2 || preview[0].ID != "doc-1" || preview[1].ID != "doc-3" {
+		t.Fatalf("DocumentsNotIn() = %v, want doc-1 and doc-3", preview)
+	}
+	if manager.Count() != 3 {
+		t.Errorf("DocumentsNotIn() removed documents, count = %d", manager.Count())
+	}
+
+	removed := manager.RemoveDocumentsNotIn(cloud)
+	if len(removed) != 2 || removed[0].ID != "doc-1" || removed[1].ID != "doc-3" {
+		t.Fatalf("RemoveDocumentsNotIn() = %v, want doc-1 and doc-3", removed)
+	}
+	if manager.Count() != 1 || manager.GetDocument("doc-2") == nil {
+		t.Errorf("expected only doc-2 to remain, got %d documents", manager.Count())
+	}
+}
+
 func TestManager_UpdateLastSync(t *testing.T) {
 	manager := NewManager("/tmp/test.json")
 
@@ -0,0 +1,177 @@
+package state
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// BackendJSON keeps the state in a JSON file (the default)
+	BackendJSON = "json"
+	// BackendSQLite keeps the state in a SQLite database
+	BackendSQLite = "sqlite"
+)
+
+// Store loads, queries, and saves the sync state
+//
+// Changes made through a Store are held in memory until Save. Manager keeps the state
+// in a JSON file that is rewritten on every Save; SQLiteStore keeps it in a SQLite
+// database and only writes the documents that changed.
+type Store interface {
+	// Load replaces the in-memory state with the persisted state
+	Load() error
+	// Save persists the in-memory state
+	Save() error
+	// Close releases the store's resources
+	Close() error
+	// Lock takes the cross-process lock on the store, waiting up to timeout for it;
+	// hold it from Load through Save so other processes' saves can't interleave
+	Lock(timeout time.Duration) (*FileLock, error)
+
+	GetState() *SyncState
+	GetDocument(id string) *DocumentState
+	AddDocument(doc *DocumentState)
+	RemoveDocument(id string)
+	DocumentsNotIn(ids map[string]bool) []*DocumentState
+	RemoveDocumentsNotIn(ids map[string]bool) []*DocumentState
+	UpdateLastSync()
+	RecordSyncRun(run SyncRun)
+	GetDocumentsByLabel(label string) []*DocumentState
+	GetDocumentsByStatus(status ConversionStatus) []*DocumentState
+	GetDocumentsNeedingOCR() []*DocumentState
+	GetDeferredDocuments() []*DocumentState
+	BuildIndex() *Index
+	Reset()
+	Count() int
+}
+
+// Open opens the state store of the given backend at path, creating it if needed
+// An empty backend selects the JSON file.
+func Open(backend, path string) (Store, error) {
+	switch backend {
+	case "", BackendJSON:
+		manager, err := LoadOrCreate(path)
+		if err != nil {
+			return nil, err
+		}
+		return manager, nil
+	case BackendSQLite:
+		store, err := OpenSQLite(path)
+		if err != nil {
+			return nil, err
+		}
+		return store, nil
+	default:
+		return nil, fmt.Errorf("unknown state backend %q", backend)
+	}
+}
+
+// memoryState is the in-memory sync state shared by the Store implementations
+type memoryState struct {
+	state *SyncState
+	mu    sync.RWMutex
+}
+
+// GetState returns a copy of the current sync state
+func (m *memoryState) GetState() *SyncState {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.state
+}
+
+// GetDocument returns the document state for a specific document ID
+func (m *memoryState) GetDocument(id string) *DocumentState {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.state.GetDocument(id)
+}
+
+// AddDocument adds or updates a document in the state
+func (m *memoryState) AddDocument(doc *DocumentState) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.state.AddDocument(doc)
+}
+
+// RemoveDocument removes a document from the state
+func (m *memoryState) RemoveDocument(id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.state.RemoveDocument(id)
+}
+
+// DocumentsNotIn returns the documents whose IDs are not in ids, sorted by ID
+func (m *memoryState) DocumentsNotIn(ids map[string]bool) []*DocumentState {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.state.documentsNotIn(ids)
+}
+
+// RemoveDocumentsNotIn removes every document whose ID is not in ids, such as
+// documents no longer present in the cloud, and returns them sorted by ID
+func (m *memoryState) RemoveDocumentsNotIn(ids map[string]bool) []*DocumentState {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	removed := m.state.documentsNotIn(ids)
+	for _, doc := range removed {
+		m.state.RemoveDocument(doc.ID)
+	}
+	return removed
+}
+
+// UpdateLastSync updates the last sync timestamp
+func (m *memoryState) UpdateLastSync() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.state.UpdateLastSync()
+}
+
+// RecordSyncRun adds a sync to the history, coalescing consecutive no-op syncs
+func (m *memoryState) RecordSyncRun(run SyncRun) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.state.RecordSyncRun(run)
+}
+
+// GetDocumentsByLabel returns all documents with a specific label
+func (m *memoryState) GetDocumentsByLabel(label string) []*DocumentState {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.state.GetDocumentsByLabel(label)
+}
+
+// GetDocumentsByStatus returns all documents with a specific conversion status
+func (m *memoryState) GetDocumentsByStatus(status ConversionStatus) []*DocumentState {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.state.GetDocumentsByStatus(status)
+}
+
+// GetDocumentsNeedingOCR returns all documents that need OCR processing
+func (m *memoryState) GetDocumentsNeedingOCR() []*DocumentState {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.state.GetDocumentsNeedingOCR()
+}
+
+// GetDeferredDocuments returns the documents in the reprocessing queue, oldest first
+func (m *memoryState) GetDeferredDocuments() []*DocumentState {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.state.GetDeferredDocuments()
+}
+
+// Reset clears all state and creates a fresh empty state
+func (m *memoryState) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.state = NewSyncState()
+}
+
+// Count returns the total number of documents in the state
+func (m *memoryState) Count() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.state.Documents)
+}
@@ -0,0 +1,343 @@
+package state
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// storeBackends opens a store of each backend in a fresh directory
+var storeBackends = []struct {
+	name string
+	file string
+}{
+	{name: BackendJSON, file: "state.json"},
+	{name: BackendSQLite, file: "state.db"},
+}
+
+// forEachBackend runs test against a store of each backend
+// open reopens the store from disk, as a later run would.
+func forEachBackend(t *testing.T, test func(t *testing.T, store Store, open func() Store)) {
+	for _, backend := range storeBackends {
+		t.Run(backend.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), backend.file)
+			open := func() Store {
+				t.Helper()
+				store, err := Open(backend.name, path)
+				if err != nil {
+					t.Fatalf("Open(%s) error = %v", backend.name, err)
+				}
+				t.Cleanup(func() { _ = store.Close() })
+				return store
+			}
+			test(t, open(), open)
+		})
+	}
+}
+
+// syncedDocument returns a document synced at version 1 with OCR complete
+func syncedDocument(id string, labels ...string) *DocumentState {
+	doc := NewDocumentState(id, "Doc "+id, "DocumentType", "parent")
+	doc.MarkSynced(1, time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC), "/out/"+id+".pdf", "hash-"+id)
+	doc.SetConversionStatus(ConversionStatusCompleted)
+	doc.MarkOCRComplete()
+	doc.Labels = labels
+	return doc
+}
+
+func TestOpen_UnknownBackend(t *testing.T) {
+	if _, err := Open("postgres", filepath.Join(t.TempDir(), "state")); err == nil {
+		t.Error("Open() with an unknown backend should error")
+	}
+}
+
+func TestStore_SaveAndReload(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, store Store, open func() Store) {
+		doc := syncedDocument("doc-1", "work")
+		doc.PageHashes = []string{"a", "b"}
+		store.AddDocument(doc)
+		store.AddDocument(syncedDocument("doc-2"))
+		store.UpdateLastSync()
+		if err := store.Save(); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+
+		reloaded := open()
+		if reloaded.Count() != 2 {
+			t.Fatalf("Count() = %d, want 2", reloaded.Count())
+		}
+		got, _ := json.Marshal(reloaded.GetDocument("doc-1"))
+		want, _ := json.Marshal(store.GetDocument("doc-1"))
+		if string(got) != string(want) {
+			t.Errorf("reloaded document = %s, want %s", got, want)
+		}
+		if got, want := reloaded.GetState().LastSync, store.GetState().LastSync; !got.Equal(want) {
+			t.Errorf("LastSync = %v, want %v", got, want)
+		}
+	})
+}
+
+func TestStore_ChangesPersist(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, store Store, open func() Store) {
+		store.AddDocument(syncedDocument("kept"))
+		store.AddDocument(syncedDocument("changed"))
+		store.AddDocument(syncedDocument("removed"))
+		if err := store.Save(); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+
+		store.GetDocument("changed").MarkSynced(2, time.Now().UTC(), "/out/changed-v2.pdf", "hash-2")
+		store.RemoveDocument("removed")
+		store.AddDocument(syncedDocument("added"))
+		if err := store.Save(); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+
+		reloaded := open()
+		if reloaded.GetDocument("removed") != nil {
+			t.Error("removed document was reloaded")
+		}
+		for _, id := range []string{"kept", "added"} {
+			if reloaded.GetDocument(id) == nil {
+				t.Errorf("document %s was not reloaded", id)
+			}
+		}
+		if got := reloaded.GetDocument("changed"); got == nil || got.Version != 2 || got.LocalPath != "/out/changed-v2.pdf" {
+			t.Errorf("changed document = %+v, want version 2", got)
+		}
+	})
+}
+
+func TestStore_DeferredQueuePersists(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, store Store, open func() Store) {
+		older := syncedDocument("older")
+		older.Defer(DeferredOCR, "OCR failed on all 2 pages")
+		older.Deferred.Since = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+		newer := syncedDocument("newer")
+		newer.Defer(DeferredOCR, "connection refused")
+		newer.Defer(DeferredOCR, "connection refused")
+		store.AddDocument(newer)
+		store.AddDocument(older)
+		store.AddDocument(syncedDocument("done"))
+		if err := store.Save(); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+
+		// The queue survives a restart, oldest first
+		reloaded := open()
+		queue := reloaded.GetDeferredDocuments()
+		if len(queue) != 2 || queue[0].ID != "older" || queue[1].ID != "newer" {
+			t.Fatalf("GetDeferredDocuments() = %+v, want older then newer", queue)
+		}
+		if got := queue[1].Deferred; got.Reason != DeferredOCR || got.Attempts != 2 || got.Error != "connection refused" {
+			t.Errorf("reloaded deferred work = %+v, want OCR deferred twice", got)
+		}
+		if entry, _ := reloaded.BuildIndex().Lookup("older"); entry.Deferred != DeferredOCR {
+			t.Errorf("index entry Deferred = %q, want %q", entry.Deferred, DeferredOCR)
+		}
+
+		// Clearing the work removes the document from the queue
+		queue[0].ClearDeferred()
+		if err := reloaded.Save(); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+		if queue := open().GetDeferredDocuments(); len(queue) != 1 || queue[0].ID != "newer" {
+			t.Errorf("GetDeferredDocuments() after clearing = %+v, want only newer", queue)
+		}
+	})
+}
+
+func TestStore_SyncHistoryPersists(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, store Store, open func() Store) {
+		at := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+		store.RecordSyncRun(SyncRun{Started: at, Finished: at, Processed: 1, Succeeded: 1})
+		store.RecordSyncRun(SyncRun{Started: at, Finished: at})
+		store.RecordSyncRun(SyncRun{Started: at, Finished: at})
+		if err := store.Save(); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+
+		history := open().GetState().History
+		if len(history) != 2 || history[0].Succeeded != 1 || history[1].Repeats != 1 || !history[1].Finished.Equal(at) {
+			t.Errorf("reloaded history = %+v, want the sync and one coalesced no-op", history)
+		}
+	})
+}
+
+func TestStore_LoadDiscardsUnsavedChanges(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, store Store, _ func() Store) {
+		store.AddDocument(syncedDocument("saved"))
+		if err := store.Save(); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+
+		store.AddDocument(syncedDocument("unsaved"))
+		store.RemoveDocument("saved")
+		if err := store.Load(); err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+
+		if store.GetDocument("saved") == nil || store.GetDocument("unsaved") != nil {
+			t.Errorf("documents after Load = %v, want only the saved document", store.GetState().Documents)
+		}
+	})
+}
+
+func TestStore_ResetPersists(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, store Store, open func() Store) {
+		store.AddDocument(syncedDocument("doc-1"))
+		store.AddDocument(syncedDocument("doc-2"))
+		if err := store.Save(); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+
+		store.Reset()
+		if store.Count() != 0 {
+			t.Errorf("Count() after Reset = %d, want 0", store.Count())
+		}
+		if err := store.Save(); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+
+		if reloaded := open(); reloaded.Count() != 0 {
+			t.Errorf("reloaded Count() = %d, want 0", reloaded.Count())
+		}
+	})
+}
+
+func TestStore_Queries(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, store Store, open func() Store) {
+		store.AddDocument(syncedDocument("work", "work"))
+		store.AddDocument(syncedDocument("personal", "personal"))
+
+		pending := NewDocumentState("pending", "Pending", "DocumentType", "")
+		store.AddDocument(pending)
+
+		needsOCR := syncedDocument("needs-ocr")
+		needsOCR.OCRProcessed = false
+		store.AddDocument(needsOCR)
+
+		if err := store.Save(); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+
+		// Queries give the same answers before and after reloading
+		for name, s := range map[string]Store{"memory": store, "reloaded": open()} {
+			if got := ids(s.GetDocumentsByLabel("work")); !reflect.DeepEqual(got, []string{"work"}) {
+				t.Errorf("%s: GetDocumentsByLabel(work) = %v", name, got)
+			}
+			if got := ids(s.GetDocumentsByStatus(ConversionStatusPending)); !reflect.DeepEqual(got, []string{"pending"}) {
+				t.Errorf("%s: GetDocumentsByStatus(pending) = %v", name, got)
+			}
+			if got := ids(s.GetDocumentsNeedingOCR()); !reflect.DeepEqual(got, []string{"needs-ocr"}) {
+				t.Errorf("%s: GetDocumentsNeedingOCR() = %v", name, got)
+			}
+			keep := map[string]bool{"work": true, "personal": true}
+			if got := ids(s.DocumentsNotIn(keep)); !reflect.DeepEqual(got, []string{"needs-ocr", "pending"}) {
+				t.Errorf("%s: DocumentsNotIn() = %v", name, got)
+			}
+			if entry, ok := s.BuildIndex().Lookup("work"); !ok || entry.Version != 1 {
+				t.Errorf("%s: BuildIndex().Lookup(work) = %+v, %t", name, entry, ok)
+			}
+		}
+	})
+}
+
+func TestStore_RemoveDocumentsNotInPersists(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, store Store, open func() Store) {
+		for _, id := range []string{"a", "b", "c"} {
+			store.AddDocument(syncedDocument(id))
+		}
+		if err := store.Save(); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+
+		removed := store.RemoveDocumentsNotIn(map[string]bool{"b": true})
+		if got := ids(removed); !reflect.DeepEqual(got, []string{"a", "c"}) {
+			t.Errorf("RemoveDocumentsNotIn() = %v, want [a c]", got)
+		}
+		if err := store.Save(); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+
+		if got := ids(open().DocumentsNotIn(nil)); !reflect.DeepEqual(got, []string{"b"}) {
+			t.Errorf("reloaded documents = %v, want [b]", got)
+		}
+	})
+}
+
+func TestSQLiteStore_SavesOnlyChangedDocuments(t *testing.T) {
+	store, err := OpenSQLite(filepath.Join(t.TempDir(), "state.db"))
+	if err != nil {
+		t.Fatalf("OpenSQLite() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	store.AddDocument(syncedDocument("unchanged"))
+	store.AddDocument(syncedDocument("changed"))
+	if err := store.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	// Rewrite the unchanged row behind the store's back; a full rewrite would restore it
+	const marker = `{"id":"unchanged","name":"written elsewhere"}`
+	if _, err := store.db.Exec("UPDATE documents SET data = ? WHERE id = ?", marker, "unchanged"); err != nil {
+		t.Fatal(err)
+	}
+
+	store.GetDocument("changed").MarkSynced(2, time.Now().UTC(), "/out/changed.pdf", "hash-2")
+	if err := store.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	var data string
+	if err := store.db.QueryRow("SELECT data FROM documents WHERE id = ?", "unchanged").Scan(&data); err != nil {
+		t.Fatal(err)
+	}
+	if data != marker {
+		t.Errorf("unchanged document was rewritten: %s", data)
+	}
+	if err := store.db.QueryRow("SELECT data FROM documents WHERE id = ?", "changed").Scan(&data); err != nil {
+		t.Fatal(err)
+	}
+	if data == marker || !strings.Contains(data, `"version":2`) {
+		t.Errorf("changed document was not written: %s", data)
+	}
+}
+
+func TestLoadStateFile_SQLite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.db")
+	store, err := OpenSQLite(path)
+	if err != nil {
+		t.Fatalf("OpenSQLite() error = %v", err)
+	}
+	store.AddDocument(syncedDocument("doc-1"))
+	if err := store.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadStateFile(path)
+	if err != nil {
+		t.Fatalf("LoadStateFile() error = %v", err)
+	}
+	if loaded.GetDocument("doc-1") == nil {
+		t.Error("LoadStateFile() did not read the SQLite state")
+	}
+}
+
+// ids returns the IDs of docs, sorted
+func ids(docs []*DocumentState) []string {
+	out := make([]string, 0, len(docs))
+	for _, doc := range docs {
+		out = append(out, doc.ID)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -1,6 +1,9 @@
 package state
 
-import "time"
+import (
+	"sort"
+	"time"
+)
 
 // SyncState represents the overall synchronization state for the application
 type SyncState struct {
@@ -12,6 +15,41 @@ type SyncState struct {
 
 	// Version is the state file format version
 	Version int `json:"version"`
+
+	// History summarizes the most recent syncs, oldest first, up to MaxSyncHistory
+	History []SyncRun `json:"history,omitempty"`
+}
+
+// MaxSyncHistory is the number of syncs kept in the state's history
+const MaxSyncHistory = 100
+
+// SyncRun summarizes one sync, or a series of consecutive no-op syncs
+type SyncRun struct {
+	// Started is when the sync started
+	Started time.Time `json:"started"`
+
+	// Finished is when the sync finished; for coalesced no-op syncs, the last one
+	Finished time.Time `json:"finished"`
+
+	// Processed is the number of documents the sync downloaded or converted
+	Processed int `json:"processed"`
+
+	// Succeeded is the number of processed documents that synced successfully
+	Succeeded int `json:"succeeded"`
+
+	// Failed is the number of processed documents that failed
+	Failed int `json:"failed"`
+
+	// Pruned is the number of deleted documents removed from the state
+	Pruned int `json:"pruned,omitempty"`
+
+	// Repeats is the number of further no-op syncs coalesced into this one
+	Repeats int `json:"repeats,omitempty"`
+}
+
+// NoOp reports whether the sync changed nothing: no documents were processed or pruned
+func (r SyncRun) NoOp() bool {
+	return r.Processed == 0 && r.Pruned == 0
 }
 
 // DocumentState represents the sync state for a single document
@@ -31,12 +69,22 @@ type DocumentState struct {
 	// LastSynced is when this document was last synced
 	LastSynced time.Time `json:"last_synced"`
 
+	// FirstSynced is when this document was first synced
+	FirstSynced time.Time `json:"first_synced,omitempty"`
+
 	// LocalPath is the path to the local copy of the document
 	LocalPath string `json:"local_path"`
 
 	// Hash is the SHA256 hash of the downloaded content for change detection
 	Hash string `json:"hash"`
 
+	// PageHashes are per-page content hashes in page order, used to detect when a new
+	// version only reorders pages
+	PageHashes []string `json:"page_hashes,omitempty"`
+
+	// Size is the size in bytes of the .rmdoc downloaded at the last sync (0 if unknown)
+	Size int64 `json:"size,omitempty"`
+
 	// Type is the document type (DocumentType or CollectionType)
 	Type string `json:"type"`
 
@@ -55,11 +103,41 @@ type DocumentState struct {
 	// Error contains any error message from the last sync attempt
 	Error string `json:"error,omitempty"`
 
-	// RetryCount is the number of sync retry attempts
+	// RetryCount is the number of failed sync attempts of FailedVersion
 	RetryCount int `json:"retry_count"`
 
+	// FailedVersion is the remote version whose sync last failed
+	FailedVersion int `json:"failed_version,omitempty"`
+
 	// Labels are the reMarkable labels/tags associated with this document
 	Labels []string `json:"labels,omitempty"`
+
+	// Deferred is work left for a later sync, such as OCR that failed while the PDF
+	// was saved without its text layer; nil when nothing is queued
+	Deferred *DeferredWork `json:"deferred,omitempty"`
+}
+
+// DeferredReason is the kind of work deferred to a later sync
+type DeferredReason string
+
+const (
+	// DeferredOCR is a document saved without its OCR text layer because OCR failed
+	DeferredOCR DeferredReason = "ocr"
+)
+
+// DeferredWork is a document's entry in the reprocessing queue
+type DeferredWork struct {
+	// Reason is the work to redo
+	Reason DeferredReason `json:"reason"`
+
+	// Since is when the work was first deferred, which orders the queue
+	Since time.Time `json:"since"`
+
+	// Attempts is the number of syncs that deferred the work
+	Attempts int `json:"attempts"`
+
+	// Error is why the work was last deferred
+	Error string `json:"error,omitempty"`
 }
 
 // ConversionStatus represents the status of document conversion
@@ -83,7 +161,8 @@ const (
 )
 
 // StateFileVersion is the current version of the state file format
-const StateFileVersion = 1
+// Older versions are upgraded on load by the migrations in migrate.go.
+const StateFileVersion = 2
 
 // NewSyncState creates a new empty SyncState
 func NewSyncState() *SyncState {
@@ -150,10 +229,37 @@ func (ds *DocumentState) MarkSynced(version int, modifiedClient time.Time, local
 	ds.Version = version
 	ds.ModifiedClient = modifiedClient
 	ds.LastSynced = time.Now()
+	if ds.FirstSynced.IsZero() {
+		ds.FirstSynced = ds.LastSynced
+	}
 	ds.LocalPath = localPath
 	ds.Hash = hash
 	ds.Error = ""
 	ds.RetryCount = 0
+	ds.FailedVersion = 0
+}
+
+// Defer queues work on the document for a later sync, counting another attempt if
+// the same work is already queued
+func (ds *DocumentState) Defer(reason DeferredReason, cause string) {
+	if ds.Deferred == nil || ds.Deferred.Reason != reason {
+		ds.Deferred = &DeferredWork{Reason: reason, Since: time.Now()}
+	}
+	ds.Deferred.Attempts++
+	ds.Deferred.Error = cause
+}
+
+// deferredReason returns the reason of the document's deferred work, or "" if none
+func (ds *DocumentState) deferredReason() DeferredReason {
+	if ds.Deferred == nil {
+		return ""
+	}
+	return ds.Deferred.Reason
+}
+
+// ClearDeferred removes the document from the reprocessing queue
+func (ds *DocumentState) ClearDeferred() {
+	ds.Deferred = nil
 }
 
 // MarkError records an error during sync
@@ -162,6 +268,24 @@ func (ds *DocumentState) MarkError(err error) {
 	ds.RetryCount++
 }
 
+// MarkFailed records a failed attempt to sync a remote version
+//
+// RetryCount counts failures of the same version, so it restarts when a new version
+// fails.
+func (ds *DocumentState) MarkFailed(remoteVersion int, err error) {
+	if ds.FailedVersion != remoteVersion {
+		ds.FailedVersion = remoteVersion
+		ds.RetryCount = 0
+	}
+	ds.MarkError(err)
+	ds.ConversionStatus = ConversionStatusFailed
+}
+
+// RetriesExhausted returns true if remoteVersion has failed more than maxRetries times
+func (ds *DocumentState) RetriesExhausted(remoteVersion, maxRetries int) bool {
+	return ds.FailedVersion == remoteVersion && ds.RetryCount > maxRetries
+}
+
 // MarkOCRComplete updates the document state after successful OCR
 func (ds *DocumentState) MarkOCRComplete() {
 	ds.OCRProcessed = true
@@ -188,6 +312,18 @@ func (ss *SyncState) RemoveDocument(id string) {
 	delete(ss.Documents, id)
 }
 
+// documentsNotIn returns the documents whose IDs are not in ids, sorted by ID
+func (ss *SyncState) documentsNotIn(ids map[string]bool) []*DocumentState {
+	var docs []*DocumentState
+	for id, doc := range ss.Documents {
+		if !ids[id] {
+			docs = append(docs, doc)
+		}
+	}
+	sort.Slice(docs, func(i, j int) bool { return docs[i].ID < docs[j].ID })
+	return docs
+}
+
 // UpdateLastSync updates the last sync timestamp
 func (ss *SyncState) UpdateLastSync() {
 	ss.LastSync = time.Now()
@@ -218,6 +354,42 @@ func (ss *SyncState) GetDocumentsByStatus(status ConversionStatus) []*DocumentSt
 	return docs
 }
 
+// RecordSyncRun adds a sync to the history, dropping the oldest beyond MaxSyncHistory
+//
+// A no-op sync following another is coalesced into it, counted in its Repeats, so a
+// daemon polling an unchanged library doesn't grow the history.
+func (ss *SyncState) RecordSyncRun(run SyncRun) {
+	if n := len(ss.History); n > 0 && run.NoOp() && ss.History[n-1].NoOp() {
+		last := &ss.History[n-1]
+		last.Finished = run.Finished
+		last.Repeats += run.Repeats + 1
+		return
+	}
+
+	ss.History = append(ss.History, run)
+	if extra := len(ss.History) - MaxSyncHistory; extra > 0 {
+		ss.History = append([]SyncRun(nil), ss.History[extra:]...)
+	}
+}
+
+// GetDeferredDocuments returns the reprocessing queue: the documents with deferred
+// work, oldest first
+func (ss *SyncState) GetDeferredDocuments() []*DocumentState {
+	var docs []*DocumentState
+	for _, doc := range ss.Documents {
+		if doc.Deferred != nil {
+			docs = append(docs, doc)
+		}
+	}
+	sort.Slice(docs, func(i, j int) bool {
+		if !docs[i].Deferred.Since.Equal(docs[j].Deferred.Since) {
+			return docs[i].Deferred.Since.Before(docs[j].Deferred.Since)
+		}
+		return docs[i].ID < docs[j].ID
+	})
+	return docs
+}
+
 // GetDocumentsNeedingOCR returns all documents that need OCR processing
 func (ss *SyncState) GetDocumentsNeedingOCR() []*DocumentState {
 	var docs []*DocumentState
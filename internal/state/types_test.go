@@ -1,6 +1,7 @@
 package state
 
 import (
+	"errors"
 	"testing"
 	"time"
 )
@@ -188,6 +189,56 @@ func TestDocumentState_MarkSynced(t *testing.T) {
 	if doc.RetryCount != 0 {
 		t.Errorf("expected retry count 0, got %d", doc.RetryCount)
 	}
+
+	if !doc.FirstSynced.Equal(doc.LastSynced) {
+		t.Errorf("expected FirstSynced %v, got %v", doc.LastSynced, doc.FirstSynced)
+	}
+
+	// Later syncs keep the first sync time
+	firstSynced := doc.FirstSynced
+	doc.MarkSynced(6, modifiedTime, "/path/to/doc", "hash456")
+	if !doc.FirstSynced.Equal(firstSynced) {
+		t.Errorf("FirstSynced changed on resync: %v, want %v", doc.FirstSynced, firstSynced)
+	}
+}
+
+func TestDocumentState_MarkFailed(t *testing.T) {
+	doc := NewDocumentState("doc-123", "Test", "DocumentType", "")
+
+	doc.MarkFailed(4, errors.New("conversion failed"))
+	doc.MarkFailed(4, errors.New("conversion failed again"))
+
+	if doc.RetryCount != 2 || doc.FailedVersion != 4 {
+		t.Errorf("expected 2 failures of version 4, got %d of version %d", doc.RetryCount, doc.FailedVersion)
+	}
+	if doc.Error != "conversion failed again" {
+		t.Errorf("expected last error recorded, got %q", doc.Error)
+	}
+	if doc.ConversionStatus != ConversionStatusFailed {
+		t.Errorf("expected status failed, got %s", doc.ConversionStatus)
+	}
+
+	if doc.RetriesExhausted(4, 2) {
+		t.Error("2 failures should not exhaust 2 retries")
+	}
+	doc.MarkFailed(4, errors.New("third failure"))
+	if !doc.RetriesExhausted(4, 2) {
+		t.Error("3 failures should exhaust 2 retries")
+	}
+	if doc.RetriesExhausted(5, 2) {
+		t.Error("a new version should not be affected by failures of the previous one")
+	}
+
+	// A new version starts counting again
+	doc.MarkFailed(5, errors.New("new version failed"))
+	if doc.RetryCount != 1 || doc.FailedVersion != 5 {
+		t.Errorf("expected 1 failure of version 5, got %d of version %d", doc.RetryCount, doc.FailedVersion)
+	}
+
+	doc.MarkSynced(5, time.Now(), "/path/to/doc", "")
+	if doc.RetryCount != 0 || doc.FailedVersion != 0 {
+		t.Errorf("expected failures cleared after sync, got %d of version %d", doc.RetryCount, doc.FailedVersion)
+	}
 }
 
 func TestSyncState_AddDocument(t *testing.T) {
@@ -303,3 +354,48 @@ func TestSyncState_GetDocumentsNeedingOCR(t *testing.T) {
 		t.Errorf("expected doc-1 to need OCR, got %s", needingOCR[0].ID)
 	}
 }
+
+func TestSyncState_RecordSyncRun(t *testing.T) {
+	ss := NewSyncState()
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	noop := func(i int) SyncRun {
+		at := start.Add(time.Duration(i) * time.Minute)
+		return SyncRun{Started: at, Finished: at.Add(time.Second)}
+	}
+
+	// Consecutive no-op syncs are coalesced into the first
+	for i := 0; i < 5; i++ {
+		ss.RecordSyncRun(noop(i))
+	}
+	if len(ss.History) != 1 {
+		t.Fatalf("history has %d entries after 5 no-op syncs, want 1", len(ss.History))
+	}
+	if got := ss.History[0]; got.Repeats != 4 || !got.Started.Equal(start) || !got.Finished.Equal(noop(4).Finished) {
+		t.Errorf("coalesced run = %+v, want 4 repeats spanning every sync", got)
+	}
+
+	// A sync that did work starts a new entry, and the no-ops after it another
+	ss.RecordSyncRun(SyncRun{Started: start, Finished: start, Processed: 2, Succeeded: 2})
+	ss.RecordSyncRun(noop(6))
+	ss.RecordSyncRun(noop(7))
+	if len(ss.History) != 3 || ss.History[1].Processed != 2 || ss.History[2].Repeats != 1 {
+		t.Errorf("history = %+v, want the no-ops, the sync, then the later no-ops", ss.History)
+	}
+
+	// Pruning counts as work
+	ss.RecordSyncRun(SyncRun{Pruned: 1})
+	if len(ss.History) != 4 {
+		t.Errorf("history has %d entries, want a pruning sync recorded on its own", len(ss.History))
+	}
+
+	// The history is bounded
+	for i := 0; i < MaxSyncHistory+10; i++ {
+		ss.RecordSyncRun(SyncRun{Processed: 1, Succeeded: i})
+	}
+	if len(ss.History) != MaxSyncHistory {
+		t.Fatalf("history has %d entries, want %d", len(ss.History), MaxSyncHistory)
+	}
+	if got := ss.History[len(ss.History)-1].Succeeded; got != MaxSyncHistory+9 {
+		t.Errorf("newest run Succeeded = %d, want %d", got, MaxSyncHistory+9)
+	}
+}
@@ -0,0 +1,151 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/platinummonkey/legible/internal/config"
+	"github.com/platinummonkey/legible/internal/converter"
+	"github.com/platinummonkey/legible/internal/pdfenhancer"
+	"github.com/platinummonkey/legible/internal/rmclient"
+	"github.com/platinummonkey/legible/internal/state"
+)
+
+// parallelConverter blocks each conversion until want conversions are running at once
+// (or a timeout passes), recording the most seen in flight
+type parallelConverter struct {
+	want int
+	fail map[string]bool // document IDs whose conversion fails
+
+	mu          sync.Mutex
+	inFlight    int
+	maxInFlight int
+	release     chan struct{}
+	released    bool
+}
+
+func (c *parallelConverter) ConvertRmdocContext(_ context.Context, rmdocPath, outputPath string) (*converter.ConversionResult, error) {
+	c.mu.Lock()
+	c.inFlight++
+	if c.inFlight > c.maxInFlight {
+		c.maxInFlight = c.inFlight
+	}
+	if c.inFlight == c.want && !c.released {
+		close(c.release)
+		c.released = true
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-c.release:
+	case <-time.After(2 * time.Second):
+	}
+
+	c.mu.Lock()
+	c.inFlight--
+	c.mu.Unlock()
+
+	// Cached downloads are named <id>-v<version>.rmdoc
+	docID := strings.Split(filepath.Base(rmdocPath), "-v")[0]
+	if c.fail[docID] {
+		return nil, errors.New("simulated conversion failure")
+	}
+	if err := os.WriteFile(outputPath, []byte("%PDF-1.4 stub"), 0644); err != nil {
+		return nil, err
+	}
+	result := converter.NewConversionResult()
+	result.PageCount = 1
+	return result, nil
+}
+
+//nolint:gocyclo // Test function with multiple validation steps
+func TestProcessDocuments_Concurrent(t *testing.T) {
+	var docs []rmclient.Document
+	for i := 1; i <= 8; i++ {
+		docs = append(docs, rmclient.Document{
+			ID:      fmt.Sprintf("doc%d", i),
+			Name:    fmt.Sprintf("Notes %d", i),
+			Type:    rmclient.DocumentType,
+			Version: 1,
+		})
+	}
+
+	conv := &parallelConverter{
+		want:    4,
+		fail:    map[string]bool{"doc3": true, "doc6": true},
+		release: make(chan struct{}),
+	}
+	orch, store := newRetryTestOrchestrator(t, conv, 0, docs...)
+	orch.concurrency = 4
+
+	result := NewResult()
+	orch.processDocuments(context.Background(), docs, result)
+
+	if conv.maxInFlight != 4 {
+		t.Errorf("at most %d documents converted at once, want 4", conv.maxInFlight)
+	}
+
+	if result.SuccessCount != 6 || len(result.Successes) != 6 {
+		t.Errorf("successes = %d (%d results), want 6", result.SuccessCount, len(result.Successes))
+	}
+	if result.FailureCount != 2 || len(result.Failures) != 2 {
+		t.Errorf("failures = %d (%d results), want 2", result.FailureCount, len(result.Failures))
+	}
+	if stats := result.ByFolder[""]; stats == nil || stats.Total() != 8 {
+		t.Errorf("root folder stats = %+v, want 8 documents", stats)
+	}
+	for _, success := range result.Successes {
+		if success.Duration <= 0 {
+			t.Errorf("%s has no duration", success.DocumentID)
+		}
+	}
+
+	// Every document's outcome is in the saved state
+	reloaded, err := state.LoadOrCreate(filepath.Join(filepath.Dir(orch.config.OutputDir), "state.json"))
+	if err != nil {
+		t.Fatalf("LoadOrCreate() error = %v", err)
+	}
+	for _, doc := range docs {
+		docState := reloaded.GetDocument(doc.ID)
+		if docState == nil {
+			t.Errorf("%s missing from saved state", doc.ID)
+			continue
+		}
+		want := state.ConversionStatusCompleted
+		if conv.fail[doc.ID] {
+			want = state.ConversionStatusFailed
+		}
+		if docState.ConversionStatus != want {
+			t.Errorf("%s status = %s, want %s", doc.ID, docState.ConversionStatus, want)
+		}
+	}
+	if store.Count() != 8 {
+		t.Errorf("state has %d documents, want 8", store.Count())
+	}
+}
+
+func TestNew_Concurrency(t *testing.T) {
+	for _, tt := range []struct{ concurrency, want int }{{0, 1}, {-2, 1}, {4, 4}} {
+		orch, err := New(&Config{
+			Config:      &config.Config{OutputDir: t.TempDir()},
+			RMClient:    &rmclient.Client{},
+			StateStore:  &state.Manager{},
+			Converter:   &converter.Converter{},
+			PDFEnhancer: &pdfenhancer.PDFEnhancer{},
+			Concurrency: tt.concurrency,
+		})
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		if orch.concurrency != tt.want {
+			t.Errorf("Concurrency %d: concurrency = %d, want %d", tt.concurrency, orch.concurrency, tt.want)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package sync
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/platinummonkey/legible/internal/converter"
+	"github.com/platinummonkey/legible/internal/rmclient"
+	"github.com/platinummonkey/legible/internal/state"
+)
+
+//nolint:gocyclo // Test function with multiple validation steps
+func TestProcessDocument_ContentHash(t *testing.T) {
+	rmdoc, err := os.ReadFile("../../example/Test.rmdoc")
+	if os.IsNotExist(err) {
+		t.Skip("Test file not found: ../../example/Test.rmdoc")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		sameContent   bool
+		wantConverted bool
+	}{
+		{name: "version bumped with the same content is skipped", sameContent: true},
+		{name: "version bumped with new content is converted", wantConverted: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := rmclient.Document{ID: "doc-1", Name: "Notes", Type: rmclient.DocumentType, Version: 2}
+			conv := &flakyConverter{docID: doc.ID}
+			orch, store := newRetryTestOrchestrator(t, conv, 0, doc)
+			conv.store = store
+
+			rmdocPath := orch.downloads.path(doc.ID, doc.Version)
+			if err := os.WriteFile(rmdocPath, rmdoc, 0644); err != nil {
+				t.Fatal(err)
+			}
+			hash, err := converter.ContentHash(rmdocPath)
+			if err != nil {
+				t.Fatalf("ContentHash() error = %v", err)
+			}
+			if !tt.sameContent {
+				hash = "hash-of-an-earlier-edit"
+			}
+
+			// Version 1 was synced to Notes.pdf with the stored hash
+			output := filepath.Join(orch.config.OutputDir, "Notes.pdf")
+			if err := os.MkdirAll(orch.config.OutputDir, 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(output, []byte("version 1"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			synced := state.NewDocumentState(doc.ID, doc.Name, doc.Type, "")
+			synced.MarkSynced(1, doc.ModifiedClient, output, hash)
+			synced.SetConversionStatus(state.ConversionStatusCompleted)
+			store.AddDocument(synced)
+
+			result := NewResult()
+			orch.syncDocument(context.Background(), doc, 1, 1, result)
+			if result.SuccessCount != 1 {
+				t.Fatalf("sync failed: %+v", result.Failures)
+			}
+
+			if converted := conv.calls > 0; converted != tt.wantConverted {
+				t.Errorf("converter called %d times, want converted = %t", conv.calls, tt.wantConverted)
+			}
+			if result.Successes[0].Skipped == tt.wantConverted {
+				t.Errorf("Skipped = %t, want %t", result.Successes[0].Skipped, !tt.wantConverted)
+			}
+			data, err := os.ReadFile(output)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if kept := string(data) == "version 1"; kept == tt.wantConverted {
+				t.Errorf("output = %q, want it replaced only when converted", data)
+			}
+
+			// Either way the new version and its content hash are recorded
+			docState := store.GetDocument(doc.ID)
+			want, _ := converter.ContentHash(rmdocPath)
+			if docState.Version != 2 || docState.Hash != want || docState.LocalPath != output {
+				t.Errorf("state = %+v, want version 2 with hash %s at %s", docState, want, output)
+			}
+		})
+	}
+}
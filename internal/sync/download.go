@@ -0,0 +1,122 @@
+package sync
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/platinummonkey/legible/internal/rmclient"
+)
+
+// DownloadProgress is the progress of a document download
+type DownloadProgress struct {
+	DocumentID string
+	Title      string
+	// Downloaded is the number of bytes received so far
+	Downloaded int64
+	// Total is the size of the document's files requested so far; it grows as each
+	// file starts downloading
+	Total int64
+	// Done is set on the final report, once the download finished or failed
+	Done bool
+}
+
+// documentDownloader downloads documents from the reMarkable cloud; rmclient.Client
+// implements it
+type documentDownloader interface {
+	DownloadDocument(id, outputPath string) error
+	DownloadDocumentWithProgress(id, outputPath string, progress rmclient.ProgressFunc) error
+}
+
+// OnDownloadProgress registers fn to receive the progress of document downloads
+// fn is called from the sync workers, so it must be safe for concurrent use; it must be
+// registered before Sync is called.
+func (o *Orchestrator) OnDownloadProgress(fn func(DownloadProgress)) {
+	o.onProgress = fn
+}
+
+// fetchDocument downloads a document to dst, waiting for a download slot when downloads
+// are limited and reporting progress to the registered callback
+func (o *Orchestrator) fetchDocument(doc rmclient.Document, dst string) error {
+	if o.downloadSlots != nil {
+		o.downloadSlots <- struct{}{}
+		defer func() { <-o.downloadSlots }()
+	}
+
+	var downloader documentDownloader = o.rmClient
+	if o.downloader != nil {
+		downloader = o.downloader
+	}
+
+	report := o.onProgress
+	if report == nil {
+		return downloader.DownloadDocument(doc.ID, dst)
+	}
+
+	progress := DownloadProgress{DocumentID: doc.ID, Title: doc.Name}
+	err := downloader.DownloadDocumentWithProgress(doc.ID, dst, func(downloaded, total int64) {
+		progress.Downloaded, progress.Total = downloaded, total
+		report(progress)
+	})
+	progress.Done = true
+	report(progress)
+	return err
+}
+
+// downloadCache keeps downloaded .rmdoc files in a persistent directory keyed by
+// document ID and version, so a failed conversion can be retried without re-downloading
+type downloadCache struct {
+	dir string
+}
+
+// newDownloadCache creates a download cache rooted at dir
+func newDownloadCache(dir string) *downloadCache {
+	return &downloadCache{dir: dir}
+}
+
+// path returns the cache location for a document version
+func (d *downloadCache) path(docID string, version int) string {
+	return filepath.Join(d.dir, fmt.Sprintf("%s-v%d.rmdoc", docID, version))
+}
+
+// fetch returns the cached .rmdoc for a document version, calling download to fill the cache on a miss.
+// The returned bool reports whether an existing download was reused.
+func (d *downloadCache) fetch(docID string, version int, download func(dst string) error) (string, bool, error) {
+	cachedPath := d.path(docID, version)
+	if info, err := os.Stat(cachedPath); err == nil && info.Mode().IsRegular() && info.Size() > 0 {
+		return cachedPath, true, nil
+	}
+
+	if err := os.MkdirAll(d.dir, 0755); err != nil {
+		return "", false, fmt.Errorf("failed to create download directory: %w", err)
+	}
+
+	// Download next to the final path and rename, so an interrupted download is never reused
+	partialPath := cachedPath + ".partial"
+	if err := download(partialPath); err != nil {
+		_ = os.Remove(partialPath)
+		return "", false, err
+	}
+	if err := os.Rename(partialPath, cachedPath); err != nil {
+		_ = os.Remove(partialPath)
+		return "", false, fmt.Errorf("failed to store download: %w", err)
+	}
+
+	d.pruneStale(docID, version)
+	return cachedPath, false, nil
+}
+
+// pruneStale removes cached downloads of other versions of a document
+func (d *downloadCache) pruneStale(docID string, version int) {
+	matches, err := filepath.Glob(filepath.Join(d.dir, docID+"-v*.rmdoc"))
+	if err != nil {
+		return
+	}
+
+	current := d.path(docID, version)
+	for _, match := range matches {
+		if match != current {
+			_ = os.Remove(match)
+		}
+	}
+}
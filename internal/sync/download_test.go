@@ -0,0 +1,181 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/platinummonkey/legible/internal/rmclient"
+)
+
+// countingCloud is a reMarkable cloud holding docs that counts the downloads
+type countingCloud struct {
+	docs      []rmclient.Document
+	downloads atomic.Int32
+}
+
+func (c *countingCloud) Refresh() error {
+	return nil
+}
+
+func (c *countingCloud) ListDocuments(_ []string) ([]rmclient.Document, error) {
+	return c.docs, nil
+}
+
+func (c *countingCloud) ListDocumentsInFolder(_ string, labels []string) ([]rmclient.Document, error) {
+	return c.ListDocuments(labels)
+}
+
+func (c *countingCloud) GetFolderPath(_ string) (string, error) {
+	return "", nil
+}
+
+func (c *countingCloud) DownloadDocument(_, outputPath string) error {
+	c.downloads.Add(1)
+	return os.WriteFile(outputPath, []byte("not a real rmdoc"), 0644)
+}
+
+func (c *countingCloud) DownloadDocumentWithProgress(id, outputPath string, _ rmclient.ProgressFunc) error {
+	return c.DownloadDocument(id, outputPath)
+}
+
+func TestDownloadCache_ReusesDownload(t *testing.T) {
+	cache := newDownloadCache(filepath.Join(t.TempDir(), "downloads"))
+
+	calls := 0
+	download := func(dst string) error {
+		calls++
+		return os.WriteFile(dst, []byte("rmdoc"), 0644)
+	}
+
+	first, cached, err := cache.fetch("doc1", 3, download)
+	if err != nil {
+		t.Fatalf("first fetch failed: %v", err)
+	}
+	if cached {
+		t.Error("first fetch should not be served from cache")
+	}
+
+	// A retry of the same version must not download again
+	second, cached, err := cache.fetch("doc1", 3, download)
+	if err != nil {
+		t.Fatalf("second fetch failed: %v", err)
+	}
+	if !cached {
+		t.Error("second fetch should be served from cache")
+	}
+	if second != first {
+		t.Errorf("second fetch path = %s, want %s", second, first)
+	}
+	if calls != 1 {
+		t.Errorf("download called %d times, want 1", calls)
+	}
+}
+
+func TestDownloadCache_NewVersionReplacesOld(t *testing.T) {
+	cache := newDownloadCache(t.TempDir())
+
+	download := func(dst string) error {
+		return os.WriteFile(dst, []byte("rmdoc"), 0644)
+	}
+
+	oldPath, _, err := cache.fetch("doc1", 1, download)
+	if err != nil {
+		t.Fatalf("fetch v1 failed: %v", err)
+	}
+	otherPath, _, err := cache.fetch("doc2", 1, download)
+	if err != nil {
+		t.Fatalf("fetch doc2 failed: %v", err)
+	}
+
+	newPath, cached, err := cache.fetch("doc1", 2, download)
+	if err != nil {
+		t.Fatalf("fetch v2 failed: %v", err)
+	}
+	if cached {
+		t.Error("new version should be downloaded")
+	}
+
+	if _, err := os.Stat(oldPath); !os.IsNotExist(err) {
+		t.Error("stale version should be removed")
+	}
+	if _, err := os.Stat(newPath); err != nil {
+		t.Errorf("new version missing: %v", err)
+	}
+	if _, err := os.Stat(otherPath); err != nil {
+		t.Errorf("other document's download should be kept: %v", err)
+	}
+}
+
+func TestDownloadCache_FailedDownloadNotCached(t *testing.T) {
+	cache := newDownloadCache(t.TempDir())
+
+	_, _, err := cache.fetch("doc1", 1, func(dst string) error {
+		_ = os.WriteFile(dst, []byte("trunc"), 0644)
+		return errors.New("connection reset")
+	})
+	if err == nil {
+		t.Fatal("expected download error")
+	}
+
+	calls := 0
+	_, cached, err := cache.fetch("doc1", 1, func(dst string) error {
+		calls++
+		return os.WriteFile(dst, []byte("rmdoc"), 0644)
+	})
+	if err != nil {
+		t.Fatalf("retry failed: %v", err)
+	}
+	if cached || calls != 1 {
+		t.Errorf("retry after failed download should download again (cached=%v, calls=%d)", cached, calls)
+	}
+}
+
+func TestSync_ReusesCachedDownload(t *testing.T) {
+	doc := rmclient.Document{ID: "doc-1", Name: "Notes", Type: rmclient.DocumentType, Version: 1}
+	conv := &flakyConverter{docID: doc.ID}
+	orch, store := newRetryTestOrchestrator(t, conv, 0)
+	conv.store = store
+	cloud := &countingCloud{docs: []rmclient.Document{doc}}
+	orch.rmClient = cloud
+
+	runSync := func() {
+		t.Helper()
+		if _, err := orch.Sync(context.Background()); err != nil {
+			t.Fatalf("Sync() error = %v", err)
+		}
+	}
+
+	runSync()
+	if got := cloud.downloads.Load(); got != 1 {
+		t.Fatalf("first sync downloaded %d times, want 1", got)
+	}
+
+	// Losing the output syncs the same version again, from the cached download
+	if err := os.Remove(store.GetDocument(doc.ID).LocalPath); err != nil {
+		t.Fatal(err)
+	}
+	runSync()
+	if got := cloud.downloads.Load(); got != 1 {
+		t.Errorf("second sync of version 1 downloaded %d times in total, want the cached download reused", got)
+	}
+	if conv.calls != 2 {
+		t.Errorf("converter called %d times, want 2", conv.calls)
+	}
+
+	// A new version is downloaded, replacing the cached older version
+	cloud.docs[0].Version = 2
+	runSync()
+	if got := cloud.downloads.Load(); got != 2 {
+		t.Errorf("sync of version 2 downloaded %d times in total, want 2", got)
+	}
+	if _, err := os.Stat(orch.downloads.path(doc.ID, 1)); !os.IsNotExist(err) {
+		t.Error("cached download of version 1 should be removed")
+	}
+	if _, err := os.Stat(orch.downloads.path(doc.ID, 2)); err != nil {
+		t.Errorf("cached download of version 2 missing: %v", err)
+	}
+}
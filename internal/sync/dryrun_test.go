@@ -0,0 +1,137 @@
+package sync
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/platinummonkey/legible/internal/converter"
+	"github.com/platinummonkey/legible/internal/rmclient"
+	"github.com/platinummonkey/legible/internal/state"
+)
+
+// failingConverter fails the test if a document is converted
+type failingConverter struct {
+	t *testing.T
+}
+
+func (c failingConverter) ConvertRmdocContext(_ context.Context, rmdocPath, _ string) (*converter.ConversionResult, error) {
+	c.t.Errorf("dry run converted %s", rmdocPath)
+	return nil, os.ErrInvalid
+}
+
+//nolint:gocyclo // Test function with multiple validation steps
+func TestSyncDocuments_DryRun(t *testing.T) {
+	orch, store := newRetryTestOrchestrator(t, failingConverter{t: t}, 3)
+	orch.dryRun = true
+	orch.config.OCREnabled = true
+
+	modified := time.Now().Add(-time.Hour).Truncate(time.Second)
+	outputDir := orch.config.OutputDir
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// synced seeds a document synced at version 1, with its output written if present
+	synced := func(id string, present, ocr bool) {
+		doc := state.NewDocumentState(id, id, rmclient.DocumentType, "")
+		output := filepath.Join(outputDir, id+".pdf")
+		doc.MarkSynced(1, modified, output, "")
+		doc.SetConversionStatus(state.ConversionStatusCompleted)
+		if ocr {
+			doc.MarkOCRComplete()
+		}
+		store.AddDocument(doc)
+		if present {
+			if err := os.WriteFile(output, []byte("%PDF-1.4 stub"), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	synced("unchanged", true, true)
+	synced("changed", true, true)
+	synced("removed", false, true)
+	synced("no-ocr", true, false)
+	if err := store.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	statePath := filepath.Join(filepath.Dir(orch.downloads.dir), "state.json")
+	stateBefore, err := os.ReadFile(statePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	docs := []rmclient.Document{
+		{ID: "unchanged", Name: "Unchanged", Version: 1, ModifiedClient: modified},
+		{ID: "new", Name: "New", Version: 1, ModifiedClient: modified},
+		{ID: "changed", Name: "Changed", Version: 2, ModifiedClient: modified},
+		{ID: "removed", Name: "Removed", Version: 1, ModifiedClient: modified},
+		{ID: "no-ocr", Name: "No OCR", Version: 1, ModifiedClient: modified},
+	}
+
+	result := orch.syncDocuments(context.Background(), docs, nil, time.Now())
+
+	if !result.DryRun {
+		t.Error("DryRun = false, want true")
+	}
+	want := []PlannedDocument{
+		{DocumentID: "new", Title: "New", Version: 1, Reason: SyncReasonNew},
+		{DocumentID: "changed", Title: "Changed", Version: 2, Reason: SyncReasonVersionChanged},
+		{DocumentID: "removed", Title: "Removed", Version: 1, Reason: SyncReasonOutputMissing},
+		{DocumentID: "no-ocr", Title: "No OCR", Version: 1, Reason: SyncReasonNeedsOCR},
+	}
+	if len(result.Planned) != len(want) {
+		t.Fatalf("Planned = %+v, want %+v", result.Planned, want)
+	}
+	for i := range want {
+		if result.Planned[i] != want[i] {
+			t.Errorf("Planned[%d] = %+v, want %+v", i, result.Planned[i], want[i])
+		}
+	}
+	if result.TotalDocuments != 5 || result.ProcessedDocuments != 0 || result.SuccessCount != 0 || result.FailureCount != 0 {
+		t.Errorf("result = %+v, want 5 documents and nothing processed", result)
+	}
+
+	// Nothing was downloaded, written, or saved
+	stateAfter, err := os.ReadFile(statePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(stateBefore, stateAfter) {
+		t.Error("dry run modified the state file")
+	}
+	if entries, _ := os.ReadDir(orch.downloads.dir); len(entries) != 0 {
+		t.Errorf("download dir has %d files, want 0", len(entries))
+	}
+	if entries, _ := os.ReadDir(outputDir); len(entries) != 3 {
+		t.Errorf("output dir has %d files, want the 3 seeded PDFs", len(entries))
+	}
+}
+
+func TestSyncDocuments_NeedsOCRIgnoredWithoutOCR(t *testing.T) {
+	orch, store := newRetryTestOrchestrator(t, failingConverter{t: t}, 3)
+	orch.dryRun = true
+
+	output := filepath.Join(t.TempDir(), "doc.pdf")
+	if err := os.WriteFile(output, []byte("%PDF-1.4 stub"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	modified := time.Now().Add(-time.Hour).Truncate(time.Second)
+	doc := state.NewDocumentState("doc", "Doc", rmclient.DocumentType, "")
+	doc.MarkSynced(1, modified, output, "")
+	doc.SetConversionStatus(state.ConversionStatusCompleted)
+	store.AddDocument(doc)
+	if err := store.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	result := orch.syncDocuments(context.Background(),
+		[]rmclient.Document{{ID: "doc", Name: "Doc", Version: 1, ModifiedClient: modified}}, nil, time.Now())
+	if len(result.Planned) != 0 {
+		t.Errorf("Planned = %+v, want nothing to sync with OCR disabled", result.Planned)
+	}
+}
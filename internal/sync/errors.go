@@ -0,0 +1,71 @@
+package sync
+
+import (
+	"errors"
+	"syscall"
+
+	"github.com/platinummonkey/legible/internal/ollama"
+	"github.com/platinummonkey/legible/internal/rmclient"
+	"github.com/platinummonkey/legible/internal/state"
+)
+
+// ErrorKind classifies a sync failure by what the user can do about it
+type ErrorKind string
+
+const (
+	// ErrorKindUnknown is a failure with no known remediation
+	ErrorKindUnknown ErrorKind = "unknown"
+	// ErrorKindAuth is a missing or expired reMarkable authentication
+	ErrorKindAuth ErrorKind = "auth"
+	// ErrorKindOllamaUnavailable is an Ollama server that refused the connection
+	ErrorKindOllamaUnavailable ErrorKind = "ollama-unavailable"
+	// ErrorKindModelMissing is an OCR model that hasn't been pulled into Ollama
+	ErrorKindModelMissing ErrorKind = "model-missing"
+	// ErrorKindDiskFull is a write that failed because the disk is full
+	ErrorKindDiskFull ErrorKind = "disk-full"
+	// ErrorKindStateLocked is a sync state held by another legible process
+	ErrorKindStateLocked ErrorKind = "state-locked"
+)
+
+// ClassifyError returns the kind of a sync failure from the typed errors it wraps
+func ClassifyError(err error) ErrorKind {
+	switch {
+	case err == nil:
+		return ErrorKindUnknown
+	case errors.Is(err, rmclient.ErrNotAuthenticated), errors.Is(err, rmclient.ErrTokenRenewal):
+		return ErrorKindAuth
+	case errors.Is(err, ollama.ErrModelNotFound):
+		return ErrorKindModelMissing
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return ErrorKindOllamaUnavailable
+	case errors.Is(err, syscall.ENOSPC):
+		return ErrorKindDiskFull
+	case errors.Is(err, state.ErrLocked):
+		return ErrorKindStateLocked
+	default:
+		return ErrorKindUnknown
+	}
+}
+
+// Remediation returns a hint for resolving failures of this kind, or "" if there is none
+func (k ErrorKind) Remediation() string {
+	switch k {
+	case ErrorKindAuth:
+		return "run 'legible auth register' to re-authenticate with the reMarkable cloud"
+	case ErrorKindOllamaUnavailable:
+		return "start Ollama with 'ollama serve' or check the configured LLM endpoint"
+	case ErrorKindModelMissing:
+		return "pull the configured model with 'ollama pull <model>'"
+	case ErrorKindDiskFull:
+		return "free up disk space on the output and download volumes"
+	case ErrorKindStateLocked:
+		return "wait for the running sync to finish, or raise state-lock-timeout"
+	default:
+		return ""
+	}
+}
+
+// RemediationHint returns the remediation hint for err, or "" if there is none
+func RemediationHint(err error) string {
+	return ClassifyError(err).Remediation()
+}
@@ -0,0 +1,113 @@
+package sync
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/platinummonkey/legible/internal/ollama"
+	"github.com/platinummonkey/legible/internal/rmclient"
+	"github.com/platinummonkey/legible/internal/state"
+)
+
+func TestClassifyError(t *testing.T) {
+	connRefused := &url.Error{Op: "Post", URL: "http://localhost:11434/api/generate", Err: &net.OpError{
+		Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED),
+	}}
+
+	tests := []struct {
+		name     string
+		err      error
+		wantKind ErrorKind
+		wantHint string
+	}{
+		{
+			name:     "not authenticated",
+			err:      fmt.Errorf("failed to list documents: %w", rmclient.ErrNotAuthenticated),
+			wantKind: ErrorKindAuth,
+			wantHint: "run 'legible auth register' to re-authenticate with the reMarkable cloud",
+		},
+		{
+			name:     "token expired",
+			err:      fmt.Errorf("%w: %w", rmclient.ErrTokenRenewal, errors.New("401 Unauthorized")),
+			wantKind: ErrorKindAuth,
+			wantHint: "run 'legible auth register' to re-authenticate with the reMarkable cloud",
+		},
+		{
+			name:     "ollama down",
+			err:      fmt.Errorf("failed to generate OCR: request failed after 4 attempts: failed to execute request: %w", connRefused),
+			wantKind: ErrorKindOllamaUnavailable,
+			wantHint: "start Ollama with 'ollama serve' or check the configured LLM endpoint",
+		},
+		{
+			name:     "model missing",
+			err:      fmt.Errorf("failed to generate OCR: %w", &ollama.APIError{StatusCode: http.StatusNotFound, Message: `model "llava" not found`}),
+			wantKind: ErrorKindModelMissing,
+			wantHint: "pull the configured model with 'ollama pull <model>'",
+		},
+		{
+			name:     "disk full",
+			err:      fmt.Errorf("failed to copy output: %w", &os.PathError{Op: "write", Path: "/out/Notes.pdf", Err: syscall.ENOSPC}),
+			wantKind: ErrorKindDiskFull,
+			wantHint: "free up disk space on the output and download volumes",
+		},
+		{
+			name:     "state locked",
+			err:      fmt.Errorf("failed to lock sync state: %w", state.ErrLocked),
+			wantKind: ErrorKindStateLocked,
+			wantHint: "wait for the running sync to finish, or raise state-lock-timeout",
+		},
+		{
+			name:     "ollama server error",
+			err:      &ollama.APIError{StatusCode: http.StatusInternalServerError, Message: "out of memory"},
+			wantKind: ErrorKindUnknown,
+		},
+		{
+			name:     "other",
+			err:      errors.New("conversion failed: invalid rmdoc"),
+			wantKind: ErrorKindUnknown,
+		},
+		{
+			name:     "nil",
+			wantKind: ErrorKindUnknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassifyError(tt.err); got != tt.wantKind {
+				t.Errorf("ClassifyError() = %s, want %s", got, tt.wantKind)
+			}
+			if got := RemediationHint(tt.err); got != tt.wantHint {
+				t.Errorf("RemediationHint() = %q, want %q", got, tt.wantHint)
+			}
+		})
+	}
+}
+
+func TestResult_AddFailure_Hint(t *testing.T) {
+	result := NewResult()
+	result.AddError("doc-1", "Notes", fmt.Errorf("download failed: %w", rmclient.ErrNotAuthenticated))
+	result.AddError("doc-2", "Sketch", errors.New("conversion failed"))
+
+	if got := result.Failures[0]; got.Kind != ErrorKindAuth || got.Hint != ErrorKindAuth.Remediation() {
+		t.Errorf("failure = %+v, want the auth hint", got)
+	}
+	if got := result.Failures[1]; got.Kind != ErrorKindUnknown || got.Hint != "" {
+		t.Errorf("failure = %+v, want no hint", got)
+	}
+
+	summary := result.Summary()
+	if !strings.Contains(summary, "    Hint: "+ErrorKindAuth.Remediation()+"\n") {
+		t.Errorf("Summary() missing the remediation hint:\n%s", summary)
+	}
+	if strings.Count(summary, "Hint:") != 1 {
+		t.Errorf("Summary() should only hint at failures with a known remediation:\n%s", summary)
+	}
+}
@@ -0,0 +1,75 @@
+package sync
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/platinummonkey/legible/internal/config"
+	"github.com/platinummonkey/legible/internal/rmclient"
+)
+
+func TestProcessDocument_ExistingOutput(t *testing.T) {
+	modified := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name     string
+		policy   string
+		offset   time.Duration // output modification time relative to the document's
+		wantKept bool
+	}{
+		{name: "skip-newer keeps a newer output", policy: config.ExistingOutputSkipNewer, offset: 30 * time.Minute, wantKept: true},
+		{name: "skip-newer replaces an older output", policy: config.ExistingOutputSkipNewer, offset: -time.Hour},
+		{name: "overwrite replaces a newer output", policy: config.ExistingOutputOverwrite, offset: 30 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := rmclient.Document{ID: "doc-1", Name: "Notes", Type: rmclient.DocumentType, Version: 2, ModifiedClient: modified}
+			conv := &flakyConverter{docID: doc.ID}
+			orch, store := newRetryTestOrchestrator(t, conv, 0, doc)
+			conv.store = store
+			orch.config.ExistingOutput = tt.policy
+
+			output := filepath.Join(orch.config.OutputDir, "Notes.pdf")
+			if err := os.MkdirAll(orch.config.OutputDir, 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(output, []byte("edited locally"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			outputTime := modified.Add(tt.offset)
+			if err := os.Chtimes(output, outputTime, outputTime); err != nil {
+				t.Fatal(err)
+			}
+
+			result := NewResult()
+			orch.syncDocument(context.Background(), doc, 1, 1, result)
+			if result.SuccessCount != 1 {
+				t.Fatalf("sync failed: %+v", result.Failures)
+			}
+
+			data, err := os.ReadFile(output)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if kept := string(data) == "edited locally"; kept != tt.wantKept {
+				t.Errorf("output kept = %t, want %t", kept, tt.wantKept)
+			}
+			if converted := conv.calls > 0; converted == tt.wantKept {
+				t.Errorf("converter called %d times, want conversion only when the output is replaced", conv.calls)
+			}
+			if result.Successes[0].Skipped != tt.wantKept {
+				t.Errorf("Skipped = %t, want %t", result.Successes[0].Skipped, tt.wantKept)
+			}
+
+			// Either way the document is recorded as synced, so it isn't retried
+			docState := store.GetDocument(doc.ID)
+			if docState == nil || docState.Version != 2 || docState.LocalPath != output {
+				t.Errorf("state = %+v, want version 2 synced to %s", docState, output)
+			}
+		})
+	}
+}
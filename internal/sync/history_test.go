@@ -0,0 +1,49 @@
+package sync
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/platinummonkey/legible/internal/rmclient"
+	"github.com/platinummonkey/legible/internal/state"
+)
+
+func TestSyncDocuments_NoOpSyncsDontGrowHistory(t *testing.T) {
+	doc := rmclient.Document{ID: "doc-1", Name: "Notes", Type: rmclient.DocumentType, Version: 1}
+	conv := &flakyConverter{docID: doc.ID}
+	orch, store := newRetryTestOrchestrator(t, conv, 0, doc)
+	conv.store = store
+	docs := []rmclient.Document{doc}
+
+	if result := orch.syncDocuments(context.Background(), docs, nil, time.Now()); result.SuccessCount != 1 {
+		t.Fatalf("first sync failed: %+v", result.Failures)
+	}
+
+	// Nothing changed, so these syncs process nothing
+	for i := 0; i < 5; i++ {
+		if result := orch.syncDocuments(context.Background(), docs, nil, time.Now()); result.ProcessedDocuments != 0 {
+			t.Fatalf("no-op sync %d processed %d documents", i+1, result.ProcessedDocuments)
+		}
+	}
+
+	// The history survives a restart: the first sync, then the no-ops as one entry
+	reloaded, err := state.LoadOrCreate(filepath.Join(filepath.Dir(orch.config.OutputDir), "state.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	history := reloaded.GetState().History
+	if len(history) != 2 {
+		t.Fatalf("history has %d entries, want 2: %+v", len(history), history)
+	}
+	if history[0].Processed != 1 || history[0].Succeeded != 1 {
+		t.Errorf("first run = %+v, want one document synced", history[0])
+	}
+	if !history[1].NoOp() || history[1].Repeats != 4 {
+		t.Errorf("no-op run = %+v, want 5 no-op syncs coalesced", history[1])
+	}
+	if conv.calls != 1 {
+		t.Errorf("converter called %d times, want once", conv.calls)
+	}
+}
@@ -0,0 +1,176 @@
+package sync
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/platinummonkey/legible/internal/rmclient"
+	"github.com/platinummonkey/legible/internal/state"
+)
+
+// ImportLocal converts a local .rmdoc file and records it in the sync state
+//
+// Local imports go through the same converter and state store as cloud syncs,
+// so documents dropped into a watch directory are tracked alongside cloud documents.
+// Returns a nil result (and nil error) if the file was already imported with identical content.
+func (o *Orchestrator) ImportLocal(ctx context.Context, rmdocPath string) (*DocumentResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	hash, err := hashFile(rmdocPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash file: %w", err)
+	}
+
+	info, err := os.Stat(rmdocPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat file: %w", err)
+	}
+
+	name := strings.TrimSuffix(filepath.Base(rmdocPath), filepath.Ext(rmdocPath))
+	docID := localDocumentID(rmdocPath, hash)
+
+	// Pick up saves made by other processes, and keep them from saving until this
+	// import is recorded
+	unlock, err := o.lockState()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	if err := o.stateStore.Load(); err != nil {
+		return nil, fmt.Errorf("failed to load state: %w", err)
+	}
+
+	// Skip files whose content has already been imported
+	if o.alreadyImported(docID, hash) {
+		return nil, nil
+	}
+
+	result := &DocumentResult{
+		DocumentID: docID,
+		Title:      name,
+		StartTime:  time.Now(),
+	}
+
+	// Create temporary directory for processing
+	tmpDir, err := os.MkdirTemp("", fmt.Sprintf("rmsync-local-%s-*", docID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer func() {
+		_ = os.RemoveAll(tmpDir)
+	}()
+
+	pdfPath := filepath.Join(tmpDir, fmt.Sprintf("%s.pdf", docID))
+	sidecars, converted, err := o.convertDocument(ctx, rmdocPath, pdfPath, result)
+	if err != nil {
+		return nil, err
+	}
+	if !converted {
+		return result, nil
+	}
+
+	// Local imports have no cloud folder, so they are saved to the root output directory
+	if err := os.MkdirAll(o.config.OutputDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	// Name the output like a synced document, so it doesn't overwrite the output of a
+	// cloud document or another import with the same name
+	names := newOutputNamer(o.config.DuplicateNames, o.stateStore.GetState())
+	outputPath := names.claim(o.config.OutputDir, name, docID)
+	if err := copyOutputs(pdfPath, outputPath, sidecars); err != nil {
+		return nil, err
+	}
+
+	result.OutputPath = outputPath
+	result.Hash = hash
+	result.Duration = time.Since(result.StartTime)
+	o.recordImport(name, info.ModTime(), result)
+
+	o.logger.WithFields(
+		"id", docID,
+		"input", rmdocPath,
+		"output", outputPath,
+		"duration", result.Duration,
+	).Info("Local document import completed")
+
+	return result, nil
+}
+
+// alreadyImported reports whether a local document with the given content has been
+// imported, and its output is still there
+func (o *Orchestrator) alreadyImported(docID, hash string) bool {
+	existing := o.stateStore.GetDocument(docID)
+	if existing == nil || existing.Hash != hash || existing.ConversionStatus != state.ConversionStatusCompleted {
+		return false
+	}
+	_, err := os.Stat(existing.LocalPath)
+	return err == nil
+}
+
+// recordImport marks an imported local document, modified at modTime, as synced in the
+// shared sync state and saves it
+func (o *Orchestrator) recordImport(name string, modTime time.Time, result *DocumentResult) {
+	docState := o.stateStore.GetDocument(result.DocumentID)
+	if docState == nil {
+		docState = state.NewDocumentState(result.DocumentID, name, rmclient.DocumentType, "")
+	}
+	docState.MarkSynced(docState.Version, modTime, result.OutputPath, result.Hash)
+	docState.SetConversionStatus(state.ConversionStatusCompleted)
+	recordOCRState(docState, result)
+	o.stateStore.AddDocument(docState)
+
+	if err := o.stateStore.Save(); err != nil {
+		o.logger.WithFields("error", err).Warn("Failed to save state")
+	}
+}
+
+// localIDPrefix starts the IDs of local imports that have no cloud document ID
+const localIDPrefix = "local-"
+
+// localDocumentID determines the document ID for a local .rmdoc file
+//
+// The ID is derived from the .metadata entry inside the archive, so an updated copy of
+// the same document replaces its earlier import, and falls back to a content-derived ID.
+// Local IDs always carry localIDPrefix: a local copy of a cloud document gets its own
+// state entry, leaving the cloud document's output and pruning alone.
+func localDocumentID(rmdocPath, hash string) string {
+	r, err := zip.OpenReader(rmdocPath)
+	if err == nil {
+		defer func() { _ = r.Close() }()
+		for _, f := range r.File {
+			if path.Dir(f.Name) == "." && strings.HasSuffix(f.Name, ".metadata") {
+				return localIDPrefix + strings.TrimSuffix(f.Name, ".metadata")
+			}
+		}
+	}
+
+	return localIDPrefix + hash[:12]
+}
+
+// hashFile returns the hex-encoded SHA256 hash of a file's contents
+func hashFile(filePath string) (string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
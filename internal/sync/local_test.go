@@ -0,0 +1,126 @@
+package sync
+
+import (
+	"archive/zip"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/platinummonkey/legible/internal/converter"
+	"github.com/platinummonkey/legible/internal/ocr"
+	"github.com/platinummonkey/legible/internal/state"
+)
+
+// writeLocalRmdoc writes a .rmdoc holding only the .metadata entry of document id
+func writeLocalRmdoc(t *testing.T, path, id string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	w, err := zw.Create(id + ".metadata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte(`{"visibleName": "Notes"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestImportLocal_CopyOfCloudDocument(t *testing.T) {
+	conv := &flakyConverter{}
+	orch, store := newRetryTestOrchestrator(t, conv, 0)
+	conv.store = store
+
+	// The cloud document was synced into a folder of the output directory
+	cloudPath := filepath.Join(orch.config.OutputDir, "Work", "Notes.pdf")
+	cloud := state.NewDocumentState("cloud-1", "Notes", "DocumentType", "")
+	cloud.MarkSynced(3, time.Now(), cloudPath, "cloud-hash")
+	cloud.SetConversionStatus(state.ConversionStatusCompleted)
+	store.AddDocument(cloud)
+	// Another cloud document owns Notes.pdf in the root output directory
+	rootPath := filepath.Join(orch.config.OutputDir, "Notes.pdf")
+	root := state.NewDocumentState("cloud-2", "Notes", "DocumentType", "")
+	root.MarkSynced(1, time.Now(), rootPath, "root-hash")
+	store.AddDocument(root)
+	if err := store.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	dropDir := t.TempDir()
+	rmdocPath := filepath.Join(dropDir, "Notes.rmdoc")
+	writeLocalRmdoc(t, rmdocPath, "cloud-1")
+
+	result, err := orch.ImportLocal(context.Background(), rmdocPath)
+	if err != nil {
+		t.Fatalf("ImportLocal() error = %v", err)
+	}
+
+	// The copy gets its own entry and output, leaving the cloud entries alone
+	if result.DocumentID != localIDPrefix+"cloud-1" {
+		t.Errorf("DocumentID = %q, want %q", result.DocumentID, localIDPrefix+"cloud-1")
+	}
+	if got := store.GetDocument("cloud-1"); got.LocalPath != cloudPath || got.Hash != "cloud-hash" {
+		t.Errorf("cloud entry = %s (%s), want it unchanged", got.LocalPath, got.Hash)
+	}
+	if result.OutputPath == rootPath || !strings.HasPrefix(filepath.Base(result.OutputPath), "Notes (") {
+		t.Errorf("OutputPath = %s, want a suffixed name beside %s", result.OutputPath, rootPath)
+	}
+	if local := store.GetDocument(result.DocumentID); local == nil || local.LocalPath != result.OutputPath {
+		t.Errorf("local entry = %+v, want output %s", local, result.OutputPath)
+	}
+}
+
+func TestImportLocal_CopiesSidecars(t *testing.T) {
+	rmdoc, err := os.ReadFile("../../example/Test.rmdoc")
+	if os.IsNotExist(err) {
+		t.Skip("Test file not found: ../../example/Test.rmdoc")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ocrProc, err := ocr.New(&ocr.Config{VisionClient: staticVisionClient{}})
+	if err != nil {
+		t.Fatalf("ocr.New() error = %v", err)
+	}
+	conv, err := converter.New(&converter.Config{
+		EnableOCR:        true,
+		OCRProcessor:     ocrProc,
+		OCRSidecarFormat: converter.OCRSidecarText,
+	})
+	if err != nil {
+		t.Fatalf("converter.New() error = %v", err)
+	}
+	orch, store := newRetryTestOrchestrator(t, conv, 0)
+	orch.ocrProc = ocrProc
+
+	rmdocPath := filepath.Join(t.TempDir(), "Notes.rmdoc")
+	if err := os.WriteFile(rmdocPath, rmdoc, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := orch.ImportLocal(context.Background(), rmdocPath)
+	if err != nil {
+		t.Fatalf("ImportLocal() error = %v", err)
+	}
+
+	// The text sidecar is copied next to the output PDF, named after it
+	sidecar := filepath.Join(orch.config.OutputDir, "Notes.txt")
+	if data, err := os.ReadFile(sidecar); err != nil || !strings.Contains(string(data), "handwritten notes") {
+		t.Errorf("sidecar %s = %q, %v, want the OCR text", sidecar, data, err)
+	}
+	if doc := store.GetDocument(result.DocumentID); doc == nil || !doc.OCRProcessed || doc.Deferred != nil {
+		t.Errorf("local entry = %+v, want OCR recorded", doc)
+	}
+}
@@ -0,0 +1,74 @@
+package sync
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/platinummonkey/legible/internal/config"
+	"github.com/platinummonkey/legible/internal/state"
+)
+
+// idSuffixLength is the number of document ID characters appended to colliding names
+const idSuffixLength = 8
+
+// outputNamer assigns output paths so that documents sharing a name in the same
+// folder don't overwrite each other
+//
+// Paths already recorded in the sync state stay with the document that owns them,
+// so a document keeps the same output path across runs.
+type outputNamer struct {
+	mu       sync.Mutex
+	strategy string
+	claimed  map[string]string // normalized output path -> document ID
+}
+
+// newOutputNamer creates a namer seeded with the output paths recorded in the sync state
+func newOutputNamer(strategy string, ss *state.SyncState) *outputNamer {
+	n := &outputNamer{
+		strategy: strategy,
+		claimed:  make(map[string]string),
+	}
+
+	if ss != nil {
+		for id, doc := range ss.Documents {
+			if doc != nil && doc.LocalPath != "" {
+				n.claimed[pathKey(doc.LocalPath)] = id
+			}
+		}
+	}
+
+	return n
+}
+
+// claim returns the output path for a document in outputDir and reserves it for the document
+func (n *outputNamer) claim(outputDir, name, docID string) string {
+	outputPath := filepath.Join(outputDir, fmt.Sprintf("%s.pdf", sanitizeFilename(name)))
+	if n == nil || n.strategy == config.DuplicateNamesOverwrite {
+		return outputPath
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if owner, ok := n.claimed[pathKey(outputPath)]; ok && owner != docID {
+		// Local imports all share their ID prefix, so it wouldn't tell them apart
+		suffix := strings.TrimPrefix(docID, localIDPrefix)
+		if len(suffix) > idSuffixLength {
+			suffix = suffix[:idSuffixLength]
+		}
+		outputPath = filepath.Join(outputDir, fmt.Sprintf("%s (%s).pdf", sanitizeFilename(name), suffix))
+	}
+
+	n.claimed[pathKey(outputPath)] = docID
+	return outputPath
+}
+
+// pathKey normalizes a path for collision checks
+//
+// Paths are compared case-insensitively since the default macOS and Windows
+// filesystems treat names differing only in case as the same file.
+func pathKey(path string) string {
+	return strings.ToLower(filepath.Clean(path))
+}
@@ -0,0 +1,92 @@
+package sync
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/platinummonkey/legible/internal/config"
+	"github.com/platinummonkey/legible/internal/state"
+)
+
+func TestOutputNamer_SameNameInFolder(t *testing.T) {
+	outputDir := filepath.Join(t.TempDir(), "Work")
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		t.Fatalf("failed to create output dir: %v", err)
+	}
+
+	srcPath := filepath.Join(t.TempDir(), "src.pdf")
+	namer := newOutputNamer(config.DuplicateNamesSuffix, state.NewSyncState())
+
+	docs := []struct{ id, content string }{
+		{id: "aaaaaaaa-1111-2222-3333-444444444444", content: "first"},
+		{id: "bbbbbbbb-5555-6666-7777-888888888888", content: "second"},
+	}
+
+	paths := make([]string, len(docs))
+	for i, doc := range docs {
+		if err := os.WriteFile(srcPath, []byte(doc.content), 0644); err != nil {
+			t.Fatalf("failed to write source: %v", err)
+		}
+		paths[i] = namer.claim(outputDir, "Meeting Notes", doc.id)
+		if err := copyFile(srcPath, paths[i]); err != nil {
+			t.Fatalf("copyFile() error = %v", err)
+		}
+	}
+
+	if paths[0] == paths[1] {
+		t.Fatalf("both documents were assigned %s", paths[0])
+	}
+	if want := filepath.Join(outputDir, "Meeting Notes.pdf"); paths[0] != want {
+		t.Errorf("first document path = %s, want %s", paths[0], want)
+	}
+	if want := filepath.Join(outputDir, "Meeting Notes (bbbbbbbb).pdf"); paths[1] != want {
+		t.Errorf("second document path = %s, want %s", paths[1], want)
+	}
+
+	// Both outputs exist with their own contents
+	for i, doc := range docs {
+		data, err := os.ReadFile(paths[i])
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", paths[i], err)
+		}
+		if string(data) != doc.content {
+			t.Errorf("%s content = %q, want %q", paths[i], data, doc.content)
+		}
+	}
+
+	// A document claiming its name again keeps the same path
+	if again := namer.claim(outputDir, "Meeting Notes", docs[1].id); again != paths[1] {
+		t.Errorf("re-claim path = %s, want %s", again, paths[1])
+	}
+}
+
+func TestOutputNamer_RespectsExistingState(t *testing.T) {
+	outputDir := t.TempDir()
+
+	// The second document already owns the plain name from a previous run
+	ss := state.NewSyncState()
+	owner := state.NewDocumentState("owner-id", "Notes", "DocumentType", "")
+	owner.LocalPath = filepath.Join(outputDir, "Notes.pdf")
+	ss.AddDocument(owner)
+
+	namer := newOutputNamer(config.DuplicateNamesSuffix, ss)
+
+	if got := namer.claim(outputDir, "Notes", "newcomer-id"); got != filepath.Join(outputDir, "Notes (newcomer).pdf") {
+		t.Errorf("newcomer path = %s, want suffixed name", got)
+	}
+	if got := namer.claim(outputDir, "Notes", "owner-id"); got != owner.LocalPath {
+		t.Errorf("owner path = %s, want %s", got, owner.LocalPath)
+	}
+}
+
+func TestOutputNamer_Overwrite(t *testing.T) {
+	outputDir := t.TempDir()
+	namer := newOutputNamer(config.DuplicateNamesOverwrite, state.NewSyncState())
+
+	first := namer.claim(outputDir, "Notes", "doc-1")
+	second := namer.claim(outputDir, "Notes", "doc-2")
+	if first != second {
+		t.Errorf("overwrite strategy paths differ: %s vs %s", first, second)
+	}
+}
@@ -0,0 +1,210 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+
+	"github.com/platinummonkey/legible/internal/converter"
+	"github.com/platinummonkey/legible/internal/ocr"
+	"github.com/platinummonkey/legible/internal/ollama"
+	"github.com/platinummonkey/legible/internal/pdfenhancer"
+	"github.com/platinummonkey/legible/internal/rmclient"
+	"github.com/platinummonkey/legible/internal/state"
+)
+
+// staticVisionClient recognizes the same words on every page
+type staticVisionClient struct{}
+
+func (staticVisionClient) GenerateOCR(_ context.Context, _ string, _ string) ([]ollama.OCRWord, error) {
+	return []ollama.OCRWord{
+		{Text: "handwritten", BBox: []int{100, 100, 200, 40}, Confidence: 0.9},
+		{Text: "notes", BBox: []int{320, 100, 120, 40}, Confidence: 0.9},
+	}, nil
+}
+
+func (staticVisionClient) HealthCheck(_ context.Context, _ string) error { return nil }
+
+func (staticVisionClient) Name() string { return "static" }
+
+func (staticVisionClient) SupportedModels() []string { return nil }
+
+// pageContents returns the content streams of each page of a PDF
+func pageContents(t *testing.T, pdfPath string) []string {
+	t.Helper()
+	outDir := t.TempDir()
+	if err := api.ExtractContentFile(pdfPath, outDir, nil, nil); err != nil {
+		t.Fatalf("ExtractContentFile(%s) error = %v", pdfPath, err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(outDir, "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var contents []string
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		contents = append(contents, string(data))
+	}
+	return contents
+}
+
+func TestProcessDocument_OCRTextLayer(t *testing.T) {
+	rmdocPath := "../../example/Test.rmdoc"
+	rmdoc, err := os.ReadFile(rmdocPath)
+	if os.IsNotExist(err) {
+		t.Skipf("Test file not found: %s", rmdocPath)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ocrProc, err := ocr.New(&ocr.Config{VisionClient: staticVisionClient{}})
+	if err != nil {
+		t.Fatalf("ocr.New() error = %v", err)
+	}
+	enhancer := pdfenhancer.New(&pdfenhancer.Config{})
+	conv, err := converter.New(&converter.Config{
+		EnableOCR:    true,
+		OCRLanguages: []string{"eng"},
+		OCRProcessor: ocrProc,
+		PDFEnhancer:  enhancer,
+	})
+	if err != nil {
+		t.Fatalf("converter.New() error = %v", err)
+	}
+
+	doc := rmclient.Document{ID: "doc-1", Name: "Notes", Type: rmclient.DocumentType, Version: 1}
+	orch, store := newRetryTestOrchestrator(t, conv, 0, doc)
+	orch.ocrProc = ocrProc
+	orch.pdfEnhancer = enhancer
+	if err := os.WriteFile(orch.downloads.path(doc.ID, doc.Version), rmdoc, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result := NewResult()
+	orch.syncDocument(context.Background(), doc, 1, 1, result)
+	if result.SuccessCount != 1 {
+		t.Fatalf("sync failed: %+v", result.Failures)
+	}
+
+	if !store.GetDocument(doc.ID).OCRProcessed {
+		t.Error("state does not record the document as OCR'd")
+	}
+
+	synced := pageContents(t, store.GetDocument(doc.ID).LocalPath)
+	if len(synced) != 2 {
+		t.Fatalf("synced PDF has %d pages, want 2", len(synced))
+	}
+	for i, content := range synced {
+		if !strings.Contains(content, "(handwritten) Tj") || !strings.Contains(content, "(notes) Tj") {
+			t.Errorf("page %d of the synced PDF has no OCR text layer", i+1)
+		}
+	}
+
+	// Converting the same document directly produces the same pages
+	standalonePath := filepath.Join(t.TempDir(), "standalone.pdf")
+	if _, err := conv.ConvertRmdoc(rmdocPath, standalonePath); err != nil {
+		t.Fatalf("ConvertRmdoc() error = %v", err)
+	}
+	standalone := pageContents(t, standalonePath)
+	if len(standalone) != len(synced) {
+		t.Fatalf("standalone PDF has %d pages, synced PDF has %d", len(standalone), len(synced))
+	}
+	for i := range synced {
+		if synced[i] != standalone[i] {
+			t.Errorf("page %d differs between the synced and standalone PDFs", i+1)
+		}
+	}
+}
+
+// switchableVisionClient recognizes words like staticVisionClient, or fails every
+// request while down
+type switchableVisionClient struct {
+	staticVisionClient
+	down *bool
+}
+
+func (c switchableVisionClient) GenerateOCR(ctx context.Context, model, image string) ([]ollama.OCRWord, error) {
+	if *c.down {
+		return nil, errors.New("connection refused")
+	}
+	return c.staticVisionClient.GenerateOCR(ctx, model, image)
+}
+
+//nolint:gocyclo // Test function with multiple validation steps
+func TestSync_DeferredOCRResumesAfterRestart(t *testing.T) {
+	rmdoc, err := os.ReadFile("../../example/Test.rmdoc")
+	if os.IsNotExist(err) {
+		t.Skip("Test file not found: ../../example/Test.rmdoc")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	down := true
+	ocrProc, err := ocr.New(&ocr.Config{VisionClient: switchableVisionClient{down: &down}})
+	if err != nil {
+		t.Fatalf("ocr.New() error = %v", err)
+	}
+	conv, err := converter.New(&converter.Config{EnableOCR: true, OCRProcessor: ocrProc})
+	if err != nil {
+		t.Fatalf("converter.New() error = %v", err)
+	}
+
+	doc := rmclient.Document{ID: "doc-1", Name: "Notes", Type: rmclient.DocumentType, Version: 1}
+	orch, store := newRetryTestOrchestrator(t, conv, 0, doc)
+	orch.config.OCREnabled = true
+	if err := os.WriteFile(orch.downloads.path(doc.ID, doc.Version), rmdoc, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// With OCR unavailable the PDF is saved without its text layer, and OCR is queued
+	result := NewResult()
+	orch.syncDocument(context.Background(), doc, 1, 1, result)
+	if result.SuccessCount != 1 {
+		t.Fatalf("sync failed: %+v", result.Failures)
+	}
+	if _, err := os.Stat(store.GetDocument(doc.ID).LocalPath); err != nil {
+		t.Fatalf("PDF without text layer not saved: %v", err)
+	}
+
+	// After a restart, the queue is loaded from the state file
+	restarted, err := state.LoadOrCreate(filepath.Join(filepath.Dir(orch.config.OutputDir), "state.json"))
+	if err != nil {
+		t.Fatalf("LoadOrCreate() error = %v", err)
+	}
+	queue := restarted.GetDeferredDocuments()
+	if len(queue) != 1 || queue[0].ID != doc.ID || queue[0].Deferred.Reason != state.DeferredOCR {
+		t.Fatalf("GetDeferredDocuments() = %+v, want the document with deferred OCR", queue)
+	}
+	orch.stateStore = restarted
+
+	// The next run picks the unchanged document up again
+	planned := orch.planSync([]rmclient.Document{doc}, restarted.BuildIndex())
+	if len(planned) != 1 || planned[0].reason != SyncReasonDeferred {
+		t.Fatalf("planSync() = %+v, want the document planned as deferred", planned)
+	}
+
+	down = false
+	result = NewResult()
+	orch.syncDocument(context.Background(), doc, 1, 1, result)
+	if result.SuccessCount != 1 {
+		t.Fatalf("sync failed: %+v", result.Failures)
+	}
+	synced := restarted.GetDocument(doc.ID)
+	if synced.Deferred != nil || !synced.OCRProcessed {
+		t.Errorf("state = %+v, want OCR done and the document out of the queue", synced)
+	}
+	if planned := orch.planSync([]rmclient.Document{doc}, restarted.BuildIndex()); len(planned) != 0 {
+		t.Errorf("planSync() = %+v, want nothing left to sync", planned)
+	}
+}
@@ -0,0 +1,46 @@
+package sync
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/platinummonkey/legible/internal/config"
+	"github.com/platinummonkey/legible/internal/state"
+)
+
+// orderPlan sorts the planned documents into the configured processing order
+//
+// The sort is stable, so documents that compare equal keep the order the cloud listed
+// them in. Sizes come from the state, as the cloud doesn't report them; documents
+// without a recorded size sort after those with one.
+func (o *Orchestrator) orderPlan(plan []plannedSync, index *state.Index) {
+	var less func(a, b plannedSync) bool
+	switch o.config.ProcessOrder {
+	case config.ProcessOrderModified:
+		less = func(a, b plannedSync) bool {
+			return a.doc.ModifiedClient.After(b.doc.ModifiedClient)
+		}
+	case config.ProcessOrderName:
+		less = func(a, b plannedSync) bool {
+			return strings.ToLower(a.doc.Name) < strings.ToLower(b.doc.Name)
+		}
+	case config.ProcessOrderSize:
+		size := func(p plannedSync) int64 {
+			if entry, ok := index.Lookup(p.doc.ID); ok {
+				return entry.Size
+			}
+			return 0
+		}
+		less = func(a, b plannedSync) bool {
+			sa, sb := size(a), size(b)
+			if sa == 0 || sb == 0 {
+				return sb == 0 && sa != 0
+			}
+			return sa < sb
+		}
+	default:
+		return
+	}
+
+	sort.SliceStable(plan, func(i, j int) bool { return less(plan[i], plan[j]) })
+}
@@ -0,0 +1,72 @@
+package sync
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/platinummonkey/legible/internal/config"
+	"github.com/platinummonkey/legible/internal/rmclient"
+	"github.com/platinummonkey/legible/internal/state"
+)
+
+func TestSyncDocuments_ProcessOrder(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	// Listed in filetree order; each was synced before except "new", and has a new version
+	docs := []rmclient.Document{
+		{ID: "b", Name: "beta", Type: rmclient.DocumentType, Version: 2, ModifiedClient: base.Add(2 * time.Hour)},
+		{ID: "new", Name: "Gamma", Type: rmclient.DocumentType, Version: 1, ModifiedClient: base.Add(3 * time.Hour)},
+		{ID: "a", Name: "Alpha", Type: rmclient.DocumentType, Version: 2, ModifiedClient: base},
+		{ID: "c", Name: "charlie", Type: rmclient.DocumentType, Version: 2, ModifiedClient: base.Add(time.Hour)},
+	}
+	sizes := map[string]int64{"a": 3000, "b": 2000, "c": 1000}
+
+	tests := []struct {
+		order string
+		want  []string
+	}{
+		{order: config.ProcessOrderFiletree, want: []string{"b", "new", "a", "c"}},
+		{order: config.ProcessOrderModified, want: []string{"new", "b", "c", "a"}},
+		{order: config.ProcessOrderName, want: []string{"a", "b", "c", "new"}},
+		{order: config.ProcessOrderSize, want: []string{"c", "b", "a", "new"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.order, func(t *testing.T) {
+			conv := &flakyConverter{}
+			orch, store := newRetryTestOrchestrator(t, conv, 0, docs...)
+			conv.store = store
+			orch.config.ProcessOrder = tt.order
+			for id, size := range sizes {
+				doc := state.NewDocumentState(id, id, rmclient.DocumentType, "")
+				doc.MarkSynced(1, base, "", "")
+				doc.Size = size
+				store.AddDocument(doc)
+			}
+			if err := store.Save(); err != nil {
+				t.Fatal(err)
+			}
+
+			result := orch.syncDocuments(context.Background(), docs, nil, time.Now())
+			if result.SuccessCount != len(docs) {
+				t.Fatalf("sync failed: %+v", result.Failures)
+			}
+			var got []string
+			for _, success := range result.Successes {
+				got = append(got, success.DocumentID)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("processed %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("processed %v, want %v", got, tt.want)
+				}
+			}
+
+			// The download size is recorded for ordering later syncs
+			if got := store.GetDocument("new").Size; got != int64(len("not a real rmdoc")) {
+				t.Errorf("recorded Size = %d, want the downloaded .rmdoc's size", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package sync
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/platinummonkey/legible/internal/config"
+	"github.com/platinummonkey/legible/internal/converter"
+	"github.com/platinummonkey/legible/internal/rmclient"
+)
+
+// pngExportDPI renders PNG pages at the reMarkable's native resolution (1404x1872)
+const pngExportDPI = 226
+
+// pngPagePattern matches the page images written by converter.ExportPNG
+const pngPagePattern = "page-*.png"
+
+// outputFormat returns the output format of a document in folderPath: the format of the
+// first rule matching one of its labels or its folder, or PDF
+func (o *Orchestrator) outputFormat(doc rmclient.Document, folderPath string) string {
+	for _, rule := range o.config.OutputFormats {
+		if rule.Label != "" && hasLabel(doc.Tags, rule.Label) ||
+			rule.Folder != "" && inFolder(folderPath, rule.Folder) {
+			return rule.Format
+		}
+	}
+	return config.OutputFormatPDF
+}
+
+// hasLabel reports whether labels contains label, ignoring case
+func hasLabel(labels []string, label string) bool {
+	for _, l := range labels {
+		if strings.EqualFold(l, label) {
+			return true
+		}
+	}
+	return false
+}
+
+// inFolder reports whether folderPath is folder or one of its subfolders, ignoring case
+func inFolder(folderPath, folder string) bool {
+	folderPath, folder = strings.ToLower(folderPath), strings.ToLower(folder)
+	return folderPath == folder || strings.HasPrefix(folderPath, folder+"/")
+}
+
+// exportPNG writes a PNG of each page of a document to the directory at outputPath,
+// removing the images of pages the document no longer has
+func (o *Orchestrator) exportPNG(rmdocPath, outputPath string, result *DocumentResult) (*DocumentResult, error) {
+	pages, err := converter.ExportPNG(rmdocPath, outputPath, pngExportDPI)
+	if err != nil {
+		return nil, fmt.Errorf("PNG export failed: %w", err)
+	}
+
+	written := make(map[string]bool, len(pages))
+	for _, page := range pages {
+		written[page] = true
+	}
+	stale, _ := filepath.Glob(filepath.Join(outputPath, pngPagePattern))
+	for _, page := range stale {
+		if !written[page] {
+			_ = os.Remove(page)
+		}
+	}
+
+	result.PageCount = len(pages)
+	result.OutputPath = outputPath
+	result.Duration = time.Since(result.StartTime)
+	return result, nil
+}
+
+// removeOutput deletes a document's output: a PDF, or the page images of a PNG export
+// and then their directory, unless other files were added to it
+func removeOutput(path string) error {
+	info, err := os.Stat(path)
+	if err != nil || !info.IsDir() {
+		return os.Remove(path)
+	}
+
+	pages, err := filepath.Glob(filepath.Join(path, pngPagePattern))
+	if err != nil {
+		return err
+	}
+	for _, page := range pages {
+		if err := os.Remove(page); err != nil {
+			return err
+		}
+	}
+	if entries, err := os.ReadDir(path); err == nil && len(entries) == 0 {
+		return os.Remove(path)
+	}
+	return nil
+}
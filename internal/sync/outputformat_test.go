@@ -0,0 +1,104 @@
+package sync
+
+import (
+	"context"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/platinummonkey/legible/internal/config"
+	"github.com/platinummonkey/legible/internal/rmclient"
+)
+
+func TestProcessDocument_OutputFormatByLabel(t *testing.T) {
+	rmdoc, err := os.ReadFile("../../example/Test.rmdoc")
+	if os.IsNotExist(err) {
+		t.Skip("Test file not found: ../../example/Test.rmdoc")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sketch := rmclient.Document{ID: "sketch-1", Name: "Sketch", Type: rmclient.DocumentType, Version: 1, Tags: []string{"Sketch"}}
+	journal := rmclient.Document{ID: "journal-1", Name: "Journal", Type: rmclient.DocumentType, Version: 1, Tags: []string{"journal"}}
+
+	conv := &flakyConverter{}
+	orch, store := newRetryTestOrchestrator(t, conv, 0, sketch, journal)
+	conv.store = store
+	orch.config.OutputFormats = []config.OutputFormatRule{{Label: "sketch", Format: config.OutputFormatPNG}}
+	for _, doc := range []rmclient.Document{sketch, journal} {
+		if err := os.WriteFile(orch.downloads.path(doc.ID, doc.Version), rmdoc, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result := NewResult()
+	orch.syncDocument(context.Background(), sketch, 1, 2, result)
+	orch.syncDocument(context.Background(), journal, 2, 2, result)
+	if result.SuccessCount != 2 {
+		t.Fatalf("sync failed: %+v", result.Failures)
+	}
+
+	// The labelled document is exported as a PNG per page, without converting it
+	pngDir := filepath.Join(orch.config.OutputDir, "Sketch")
+	if got := store.GetDocument(sketch.ID).LocalPath; got != pngDir {
+		t.Errorf("sketch LocalPath = %s, want %s", got, pngDir)
+	}
+	for _, page := range []string{"page-001.png", "page-002.png"} {
+		f, err := os.Open(filepath.Join(pngDir, page))
+		if err != nil {
+			t.Fatalf("PNG page missing: %v", err)
+		}
+		_, err = png.DecodeConfig(f)
+		_ = f.Close()
+		if err != nil {
+			t.Errorf("%s is not a PNG: %v", page, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(orch.config.OutputDir, "Sketch.pdf")); !os.IsNotExist(err) {
+		t.Error("the PNG-format document was also written as a PDF")
+	}
+
+	// Other documents are converted to PDF
+	pdfPath := filepath.Join(orch.config.OutputDir, "Journal.pdf")
+	if got := store.GetDocument(journal.ID).LocalPath; got != pdfPath {
+		t.Errorf("journal LocalPath = %s, want %s", got, pdfPath)
+	}
+	if data, err := os.ReadFile(pdfPath); err != nil || string(data) != "%PDF-1.4 stub" {
+		t.Errorf("journal PDF = %q, %v", data, err)
+	}
+	if conv.calls != 1 {
+		t.Errorf("converter called %d times, want once for the PDF document", conv.calls)
+	}
+}
+
+func TestOutputFormat(t *testing.T) {
+	orch := &Orchestrator{config: &config.Config{OutputFormats: []config.OutputFormatRule{
+		{Folder: "Journals", Format: config.OutputFormatPDF},
+		{Label: "sketch", Format: config.OutputFormatPNG},
+		{Folder: "Art/Pads", Format: config.OutputFormatPNG},
+	}}}
+
+	tests := []struct {
+		name   string
+		labels []string
+		folder string
+		want   string
+	}{
+		{name: "no rule", folder: "Work", want: config.OutputFormatPDF},
+		{name: "label", labels: []string{"SKETCH"}, want: config.OutputFormatPNG},
+		{name: "folder", folder: "Art/Pads", want: config.OutputFormatPNG},
+		{name: "subfolder", folder: "art/pads/2025", want: config.OutputFormatPNG},
+		{name: "folder prefix is not a parent", folder: "Art/Padsworth", want: config.OutputFormatPDF},
+		{name: "first rule wins", labels: []string{"sketch"}, folder: "Journals/Daily", want: config.OutputFormatPDF},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := rmclient.Document{ID: "doc", Tags: tt.labels}
+			if got := orch.outputFormat(doc, tt.folder); got != tt.want {
+				t.Errorf("outputFormat() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/platinummonkey/legible/internal/rmclient"
+)
+
+// prefetchedDownload is a document downloaded by the pipeline's download stage
+type prefetchedDownload struct {
+	index     int    // position of the document in the sync
+	rmdocPath string // the downloaded .rmdoc, when err is nil
+	tmpDir    string // holds rmdocPath unless it is in the download cache
+	err       error
+}
+
+// cleanup removes the download, unless it is in the download cache
+func (p *prefetchedDownload) cleanup() {
+	if p.tmpDir != "" {
+		_ = os.RemoveAll(p.tmpDir)
+	}
+}
+
+// processPipelined syncs docs with a download stage feeding the conversion workers
+//
+// The download stage downloads documents one after another, in order, while up to
+// o.concurrency workers convert those already downloaded, so the next document downloads
+// while the last one converts. At most o.pipeline downloaded documents wait for a
+// worker, which bounds the disk space used by downloads. A document that turns out not
+// to need converting, such as one whose output is newer, is still downloaded.
+func (o *Orchestrator) processPipelined(ctx context.Context, docs []rmclient.Document, result *Result) {
+	downloaded := make(chan *prefetchedDownload, o.pipeline)
+	go func() {
+		defer close(downloaded)
+		for i, doc := range docs {
+			downloaded <- o.prefetch(ctx, i, doc, len(docs))
+		}
+	}()
+
+	workers := min(max(o.concurrency, 1), len(docs))
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for download := range downloaded {
+				doc := docs[download.index]
+				o.syncDownloaded(ctx, doc, download.index+1, len(docs), download, result)
+				download.cleanup()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// prefetch downloads a document for the pipeline's download stage
+// A download that fails, or isn't attempted because the sync was cancelled, is passed
+// on with its error, so the document's failure is recorded like any other.
+func (o *Orchestrator) prefetch(ctx context.Context, index int, doc rmclient.Document, totalDocs int) *prefetchedDownload {
+	download := &prefetchedDownload{index: index}
+	if download.err = ctx.Err(); download.err != nil {
+		return download
+	}
+
+	o.logger.WithFields("document", index+1, "total", totalDocs, "id", doc.ID).
+		Info("Downloading document")
+
+	// Without a download cache, the document is downloaded to a temporary directory
+	if o.downloads == nil {
+		tmpDir, err := os.MkdirTemp("", fmt.Sprintf("rmsync-download-%s-*", doc.ID))
+		if err != nil {
+			download.err = fmt.Errorf("failed to create temp directory: %w", err)
+			return download
+		}
+		download.tmpDir = tmpDir
+	}
+
+	download.rmdocPath, download.err = o.downloadDocument(doc, download.tmpDir)
+	return download
+}
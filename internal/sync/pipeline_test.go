@@ -0,0 +1,145 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/platinummonkey/legible/internal/converter"
+	"github.com/platinummonkey/legible/internal/rmclient"
+)
+
+// stageSpan is when a stage worked on a document
+type stageSpan struct {
+	stage      string
+	docID      string
+	start, end time.Time
+}
+
+// stageRecorder downloads and converts documents, taking a fixed time for each, and
+// records when each stage ran
+type stageRecorder struct {
+	delay time.Duration
+
+	mu    sync.Mutex
+	spans []stageSpan
+}
+
+func (r *stageRecorder) record(stage, docID string) {
+	start := time.Now()
+	time.Sleep(r.delay)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans = append(r.spans, stageSpan{stage: stage, docID: docID, start: start, end: time.Now()})
+}
+
+func (r *stageRecorder) DownloadDocument(id, outputPath string) error {
+	r.record("download", id)
+	return os.WriteFile(outputPath, []byte("not a real rmdoc"), 0644)
+}
+
+func (r *stageRecorder) DownloadDocumentWithProgress(id, outputPath string, _ rmclient.ProgressFunc) error {
+	return r.DownloadDocument(id, outputPath)
+}
+
+func (r *stageRecorder) ConvertRmdocContext(_ context.Context, rmdocPath, outputPath string) (*converter.ConversionResult, error) {
+	// Cached downloads are named <id>-v<version>.rmdoc
+	r.record("convert", strings.Split(filepath.Base(rmdocPath), "-v")[0])
+	if err := os.WriteFile(outputPath, []byte("%PDF-1.4 stub"), 0644); err != nil {
+		return nil, err
+	}
+	result := converter.NewConversionResult()
+	result.PageCount = 1
+	return result, nil
+}
+
+// overlaps reports whether any download ran at the same time as a conversion
+func (r *stageRecorder) overlaps() bool {
+	for _, d := range r.spans {
+		for _, c := range r.spans {
+			if d.stage == "download" && c.stage == "convert" && d.start.Before(c.end) && c.start.Before(d.end) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func TestProcessDocuments_Pipelined(t *testing.T) {
+	var docs []rmclient.Document
+	for i := 1; i <= 4; i++ {
+		docs = append(docs, rmclient.Document{
+			ID:      fmt.Sprintf("doc%d", i),
+			Name:    fmt.Sprintf("Notes %d", i),
+			Type:    rmclient.DocumentType,
+			Version: 1,
+		})
+	}
+
+	for _, tt := range []struct {
+		name         string
+		pipeline     int
+		wantOverlaps bool
+	}{
+		{name: "pipelined", pipeline: 2, wantOverlaps: true},
+		{name: "not pipelined", pipeline: 0, wantOverlaps: false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			stages := &stageRecorder{delay: 30 * time.Millisecond}
+			// No downloads are cached, so every document is downloaded
+			orch, _ := newRetryTestOrchestrator(t, stages, 0)
+			orch.downloader = stages
+			orch.pipeline = tt.pipeline
+
+			result := NewResult()
+			orch.processDocuments(context.Background(), docs, result)
+
+			if result.SuccessCount != len(docs) {
+				t.Fatalf("successes = %d, want %d (failures: %+v)", result.SuccessCount, len(docs), result.Failures)
+			}
+			if got := stages.overlaps(); got != tt.wantOverlaps {
+				t.Errorf("downloads overlapped conversions = %t, want %t (spans: %+v)", got, tt.wantOverlaps, stages.spans)
+			}
+
+			// Each document is downloaded once, in order, and converted
+			var downloads []string
+			converted := 0
+			for _, span := range stages.spans {
+				if span.stage == "download" {
+					downloads = append(downloads, span.docID)
+				} else {
+					converted++
+				}
+			}
+			if strings.Join(downloads, ",") != "doc1,doc2,doc3,doc4" || converted != len(docs) {
+				t.Errorf("downloads = %v and %d conversions, want each document downloaded in order and converted", downloads, converted)
+			}
+		})
+	}
+}
+
+func TestProcessDocuments_PipelinedCancelled(t *testing.T) {
+	docs := []rmclient.Document{
+		{ID: "doc1", Name: "Notes 1", Type: rmclient.DocumentType, Version: 1},
+		{ID: "doc2", Name: "Notes 2", Type: rmclient.DocumentType, Version: 1},
+	}
+	stages := &stageRecorder{}
+	orch, _ := newRetryTestOrchestrator(t, stages, 0)
+	orch.downloader = stages
+	orch.pipeline = 1
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result := NewResult()
+	orch.processDocuments(ctx, docs, result)
+
+	// Documents not downloaded because of the cancellation are still reported
+	if result.FailureCount != len(docs) || len(stages.spans) != 0 {
+		t.Errorf("failures = %d with %d stage runs, want every document failed without running", result.FailureCount, len(stages.spans))
+	}
+}
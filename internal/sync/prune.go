@@ -0,0 +1,118 @@
+package sync
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/platinummonkey/legible/internal/rmclient"
+)
+
+// pruneOptions controls the removal of documents deleted from the cloud
+type pruneOptions struct {
+	enabled     bool // remove state entries for documents no longer in the cloud
+	outputFiles bool // also delete the pruned documents' local PDFs
+	dryRun      bool // report what would be pruned without removing anything
+}
+
+// cloudDocumentIDs returns the IDs of every document in the cloud
+//
+// docs is reused when it is the unfiltered listing; otherwise all documents are listed
+// again, so documents outside the folder or labels being synced aren't pruned. labels
+// are the labels docs were listed with.
+func (o *Orchestrator) cloudDocumentIDs(docs []rmclient.Document, labels []string) (map[string]bool, error) {
+	if o.config.Folder != "" || len(labels) > 0 {
+		all, err := o.rmClient.ListDocuments(nil)
+		if err != nil {
+			return nil, err
+		}
+		docs = all
+	}
+
+	ids := make(map[string]bool, len(docs))
+	for _, doc := range docs {
+		ids[doc.ID] = true
+	}
+	return ids, nil
+}
+
+// pruneDeleted removes the state entries of documents whose IDs are not in cloudIDs,
+// and their local PDFs if configured, returning the documents pruned
+//
+// Local imports without a cloud ID are never pruned. A document whose PDF can't be
+// deleted keeps its state entry so the next sync tries again, and PDFs outside the
+// output directory are left alone. An empty cloud listing prunes nothing, since it's
+// more likely a listing problem than every document having been deleted.
+func (o *Orchestrator) pruneDeleted(cloudIDs map[string]bool) []PrunedDocument {
+	stale := o.stateStore.DocumentsNotIn(cloudIDs)
+	if len(stale) > 0 && len(cloudIDs) == 0 {
+		o.logger.WithFields("documents", len(stale)).
+			Warn("Cloud listing is empty, not pruning any documents")
+		return nil
+	}
+
+	keep := make(map[string]bool, len(cloudIDs))
+	for id := range cloudIDs {
+		keep[id] = true
+	}
+
+	pruned := make([]PrunedDocument, 0, len(stale))
+	for _, doc := range stale {
+		if strings.HasPrefix(doc.ID, localIDPrefix) {
+			keep[doc.ID] = true
+			continue
+		}
+
+		entry := PrunedDocument{DocumentID: doc.ID, Title: doc.Name, LocalPath: doc.LocalPath}
+		if o.prune.outputFiles && doc.LocalPath != "" {
+			deleted, err := o.deletePrunedFile(doc.LocalPath)
+			if err != nil {
+				o.logger.WithFields("id", doc.ID, "path", doc.LocalPath, "error", err).
+					Warn("Failed to delete PDF of deleted document, keeping it in state")
+				keep[doc.ID] = true
+				continue
+			}
+			entry.FileDeleted = deleted
+		}
+		pruned = append(pruned, entry)
+	}
+
+	if o.prune.dryRun {
+		o.logger.WithFields("count", len(pruned)).Info("Dry run, documents deleted from the cloud were not pruned")
+		return pruned
+	}
+
+	if removed := o.stateStore.RemoveDocumentsNotIn(keep); len(removed) > 0 {
+		if err := o.stateStore.Save(); err != nil {
+			o.logger.WithFields("error", err).Warn("Failed to save state")
+		}
+	}
+	o.logger.WithFields("count", len(pruned)).Info("Pruned documents deleted from the cloud")
+	return pruned
+}
+
+// deletePrunedFile deletes the PDF of a pruned document, or in a dry run checks that
+// it would be, and reports whether there was a file to delete
+func (o *Orchestrator) deletePrunedFile(path string) (bool, error) {
+	if !o.inOutputDir(path) {
+		o.logger.WithFields("path", path).Warn("Not deleting PDF outside the output directory")
+		return false, nil
+	}
+
+	var err error
+	if o.prune.dryRun {
+		_, err = os.Stat(path)
+	} else {
+		err = removeOutput(path)
+	}
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// inOutputDir reports whether path is inside the output directory
+func (o *Orchestrator) inOutputDir(path string) bool {
+	rel, err := filepath.Rel(o.config.OutputDir, path)
+	return err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
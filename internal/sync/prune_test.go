@@ -0,0 +1,153 @@
+package sync
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/platinummonkey/legible/internal/rmclient"
+	"github.com/platinummonkey/legible/internal/state"
+)
+
+// seedSynced records a synced document in the state with its PDF in the output directory
+func seedSynced(t *testing.T, orch *Orchestrator, store *state.Manager, id string) string {
+	t.Helper()
+	if err := os.MkdirAll(orch.config.OutputDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	output := filepath.Join(orch.config.OutputDir, id+".pdf")
+	if err := os.WriteFile(output, []byte("%PDF-1.4 stub"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	doc := state.NewDocumentState(id, id, rmclient.DocumentType, "")
+	doc.MarkSynced(1, time.Now().Add(-time.Hour).Truncate(time.Second), output, "")
+	doc.SetConversionStatus(state.ConversionStatusCompleted)
+	store.AddDocument(doc)
+	return output
+}
+
+//nolint:gocyclo // Test function with multiple validation steps
+func TestSyncDocuments_Prune(t *testing.T) {
+	tests := []struct {
+		name        string
+		prune       pruneOptions
+		wantRemoved bool // the deleted document's state entry is removed
+		wantDeleted bool // the deleted document's PDF is removed
+	}{
+		{name: "disabled"},
+		{name: "state only", prune: pruneOptions{enabled: true}, wantRemoved: true},
+		{name: "state and files", prune: pruneOptions{enabled: true, outputFiles: true}, wantRemoved: true, wantDeleted: true},
+		{name: "dry run", prune: pruneOptions{enabled: true, outputFiles: true, dryRun: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orch, store := newRetryTestOrchestrator(t, failingConverter{t: t}, 0)
+			orch.prune = tt.prune
+
+			keptPDF := seedSynced(t, orch, store, "kept")
+			deletedPDF := seedSynced(t, orch, store, "deleted")
+			localPDF := seedSynced(t, orch, store, localIDPrefix+"0123456789ab")
+			if err := store.Save(); err != nil {
+				t.Fatal(err)
+			}
+
+			// "kept" is unchanged in the cloud; "deleted" and the local import are not listed
+			docs := []rmclient.Document{{ID: "kept", Name: "kept", Version: 1,
+				ModifiedClient: store.GetDocument("kept").ModifiedClient}}
+			var cloudIDs map[string]bool
+			if tt.prune.enabled {
+				var err error
+				if cloudIDs, err = orch.cloudDocumentIDs(docs, orch.config.Labels); err != nil {
+					t.Fatalf("cloudDocumentIDs() error = %v", err)
+				}
+			}
+
+			result := orch.syncDocuments(context.Background(), docs, cloudIDs, time.Now())
+
+			if got := store.GetDocument("deleted") == nil; got != tt.wantRemoved {
+				t.Errorf("state entry removed = %t, want %t", got, tt.wantRemoved)
+			}
+			if _, err := os.Stat(deletedPDF); os.IsNotExist(err) != tt.wantDeleted {
+				t.Errorf("PDF deleted = %t, want %t", os.IsNotExist(err), tt.wantDeleted)
+			}
+
+			// Documents still in the cloud, and local imports, are never pruned
+			for _, id := range []string{"kept", localIDPrefix + "0123456789ab"} {
+				if store.GetDocument(id) == nil {
+					t.Errorf("state entry %s was removed", id)
+				}
+			}
+			for _, pdf := range []string{keptPDF, localPDF} {
+				if _, err := os.Stat(pdf); err != nil {
+					t.Errorf("PDF %s was removed: %v", pdf, err)
+				}
+			}
+
+			// The removal is saved, and the dry run leaves the state file alone
+			reloaded, err := state.LoadOrCreate(filepath.Join(filepath.Dir(orch.downloads.dir), "state.json"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := reloaded.GetDocument("deleted") == nil; got != tt.wantRemoved {
+				t.Errorf("saved state entry removed = %t, want %t", got, tt.wantRemoved)
+			}
+
+			if !tt.prune.enabled {
+				if len(result.Pruned) != 0 {
+					t.Errorf("Pruned = %+v, want none", result.Pruned)
+				}
+				return
+			}
+			if len(result.Pruned) != 1 || result.Pruned[0].DocumentID != "deleted" {
+				t.Fatalf("Pruned = %+v, want the deleted document", result.Pruned)
+			}
+			if got := result.Pruned[0].FileDeleted; got != tt.prune.outputFiles {
+				t.Errorf("FileDeleted = %t, want %t", got, tt.prune.outputFiles)
+			}
+			if result.PruneDryRun != tt.prune.dryRun {
+				t.Errorf("PruneDryRun = %t, want %t", result.PruneDryRun, tt.prune.dryRun)
+			}
+		})
+	}
+}
+
+func TestPruneDeleted_EmptyCloudListing(t *testing.T) {
+	orch, store := newRetryTestOrchestrator(t, failingConverter{t: t}, 0)
+	orch.prune = pruneOptions{enabled: true, outputFiles: true}
+	output := seedSynced(t, orch, store, "doc")
+
+	if pruned := orch.pruneDeleted(map[string]bool{}); len(pruned) != 0 {
+		t.Errorf("pruneDeleted() = %+v, want nothing pruned", pruned)
+	}
+	if store.GetDocument("doc") == nil {
+		t.Error("state entry was removed")
+	}
+	if _, err := os.Stat(output); err != nil {
+		t.Errorf("PDF was removed: %v", err)
+	}
+}
+
+func TestPruneDeleted_OutsideOutputDir(t *testing.T) {
+	orch, store := newRetryTestOrchestrator(t, failingConverter{t: t}, 0)
+	orch.prune = pruneOptions{enabled: true, outputFiles: true}
+	seedSynced(t, orch, store, "kept")
+
+	output := filepath.Join(t.TempDir(), "elsewhere.pdf")
+	if err := os.WriteFile(output, []byte("%PDF-1.4 stub"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	doc := state.NewDocumentState("moved", "Moved", rmclient.DocumentType, "")
+	doc.MarkSynced(1, time.Now(), output, "")
+	store.AddDocument(doc)
+
+	pruned := orch.pruneDeleted(map[string]bool{"kept": true})
+	if len(pruned) != 1 || pruned[0].FileDeleted {
+		t.Errorf("pruneDeleted() = %+v, want the entry pruned and its file kept", pruned)
+	}
+	if _, err := os.Stat(output); err != nil {
+		t.Errorf("PDF outside the output directory was removed: %v", err)
+	}
+}
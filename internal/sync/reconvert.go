@@ -0,0 +1,176 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/platinummonkey/legible/internal/converter"
+	"github.com/platinummonkey/legible/internal/rmclient"
+	"github.com/platinummonkey/legible/internal/state"
+)
+
+// ReconvertResult contains the results of reconverting synced documents from their
+// cached downloads
+type ReconvertResult struct {
+	Reconverted []DocumentResult
+	Failures    []DocumentFailure
+	// Uncached lists the documents skipped because no download of their synced
+	// version is cached
+	Uncached []DocumentResult
+	Duration time.Duration
+}
+
+// Summary returns a human-readable summary of the reconversion
+func (rr *ReconvertResult) Summary() string {
+	var sb strings.Builder
+
+	sb.WriteString("Reconvert Summary:\n")
+	fmt.Fprintf(&sb, "  Reconverted: %d\n", len(rr.Reconverted))
+	fmt.Fprintf(&sb, "  Failed: %d\n", len(rr.Failures))
+	fmt.Fprintf(&sb, "  Skipped (no cached download): %d\n", len(rr.Uncached))
+	fmt.Fprintf(&sb, "  Duration: %v\n", rr.Duration)
+
+	if len(rr.Failures) > 0 {
+		sb.WriteString("\nFailures:\n")
+		for _, failure := range rr.Failures {
+			fmt.Fprintf(&sb, "  - %s (%s): %v\n", failure.Title, failure.DocumentID, failure.Error)
+		}
+	}
+
+	return sb.String()
+}
+
+// Reconvert converts every synced document again from its cached download, overwriting
+// its output, without contacting the reMarkable cloud
+//
+// Each document's output is rewritten at the path it was synced to, in the same format.
+// Documents whose synced version isn't in the download cache, such as those synced
+// before download-dir was set or local imports, are skipped.
+func (o *Orchestrator) Reconvert(ctx context.Context) (*ReconvertResult, error) {
+	if o.downloads == nil {
+		return nil, fmt.Errorf("reconverting requires a download cache, set download-dir")
+	}
+
+	startTime := time.Now()
+
+	unlock, err := o.lockState()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	if err := o.stateStore.Load(); err != nil {
+		return nil, fmt.Errorf("failed to load state: %w", err)
+	}
+
+	var docs []*state.DocumentState
+	for _, doc := range o.stateStore.GetState().Documents {
+		if doc.Type != rmclient.CollectionType && doc.LocalPath != "" {
+			docs = append(docs, doc)
+		}
+	}
+	sort.Slice(docs, func(i, j int) bool { return docs[i].LocalPath < docs[j].LocalPath })
+
+	result := &ReconvertResult{}
+	for i, doc := range docs {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		rmdocPath := o.downloads.path(doc.ID, doc.Version)
+		if info, err := os.Stat(rmdocPath); err != nil || !info.Mode().IsRegular() {
+			o.logger.WithFields("id", doc.ID, "version", doc.Version).Debug("No cached download, skipping")
+			result.Uncached = append(result.Uncached, DocumentResult{DocumentID: doc.ID, Title: doc.Name, OutputPath: doc.LocalPath})
+			continue
+		}
+
+		o.logger.WithFields("document", i+1, "total", len(docs), "id", doc.ID, "output", doc.LocalPath).
+			Info("Reconverting document")
+		docResult, err := o.reconvertDocument(ctx, doc, rmdocPath)
+		if err != nil {
+			o.logger.WithFields("id", doc.ID, "error", err).Error("Failed to reconvert document")
+			result.Failures = append(result.Failures, DocumentFailure{
+				DocumentID: doc.ID,
+				Title:      doc.Name,
+				Labels:     doc.Labels,
+				Error:      err,
+			})
+			continue
+		}
+
+		doc.PageHashes = docResult.PageHashes
+		if docResult.OCRProcessed {
+			doc.MarkOCRComplete()
+		} else {
+			doc.OCRProcessed = false
+		}
+		if docResult.OCRDeferred != "" {
+			doc.Defer(state.DeferredOCR, docResult.OCRDeferred)
+		} else {
+			doc.ClearDeferred()
+		}
+		o.stateStore.AddDocument(doc)
+		if err := o.stateStore.Save(); err != nil {
+			o.logger.WithFields("error", err).Warn("Failed to save state")
+		}
+		result.Reconverted = append(result.Reconverted, *docResult)
+	}
+
+	result.Duration = time.Since(startTime)
+	return result, nil
+}
+
+// reconvertDocument converts a document's cached download to its recorded output
+func (o *Orchestrator) reconvertDocument(ctx context.Context, doc *state.DocumentState, rmdocPath string) (*DocumentResult, error) {
+	result := &DocumentResult{
+		DocumentID: doc.ID,
+		Title:      doc.Name,
+		Labels:     doc.Labels,
+		Hash:       doc.Hash,
+		StartTime:  time.Now(),
+	}
+
+	pageHashes, err := converter.PageHashes(rmdocPath)
+	if err != nil {
+		o.logger.WithFields("id", doc.ID, "error", err).Warn("Failed to hash pages")
+	}
+	result.PageHashes = pageHashes
+
+	// PDFs are synced to a .pdf file, and PNG exports to a directory of page images
+	if !strings.EqualFold(filepath.Ext(doc.LocalPath), ".pdf") {
+		return o.exportPNG(rmdocPath, doc.LocalPath, result)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(doc.LocalPath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	tmpDir, err := os.MkdirTemp("", fmt.Sprintf("rmsync-%s-*", doc.ID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer func() {
+		_ = os.RemoveAll(tmpDir)
+	}()
+
+	pdfPath := filepath.Join(tmpDir, fmt.Sprintf("%s.pdf", doc.ID))
+	sidecars, converted, err := o.convertDocument(ctx, rmdocPath, pdfPath, result)
+	if err != nil {
+		return nil, err
+	}
+	if !converted {
+		return nil, fmt.Errorf("no page of the document could be parsed, keeping the existing output")
+	}
+
+	if err := copyOutputs(pdfPath, doc.LocalPath, sidecars); err != nil {
+		return nil, err
+	}
+
+	result.OutputPath = doc.LocalPath
+	result.Duration = time.Since(result.StartTime)
+	return result, nil
+}
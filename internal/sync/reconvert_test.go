@@ -0,0 +1,78 @@
+package sync
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/platinummonkey/legible/internal/rmclient"
+	"github.com/platinummonkey/legible/internal/state"
+)
+
+//nolint:gocyclo // Test function with multiple validation steps
+func TestReconvert(t *testing.T) {
+	cached := rmclient.Document{ID: "doc-1", Name: "Cached", Type: rmclient.DocumentType, Version: 2}
+	uncached := rmclient.Document{ID: "doc-2", Name: "Uncached", Type: rmclient.DocumentType, Version: 5}
+	conv := &flakyConverter{docID: cached.ID}
+	orch, store := newRetryTestOrchestrator(t, conv, 0, cached)
+	conv.store = store
+
+	// Both documents were synced earlier, but only the first's download is cached
+	outputs := make(map[string]string)
+	for _, doc := range []rmclient.Document{cached, uncached} {
+		outputs[doc.ID] = filepath.Join(orch.config.OutputDir, doc.Name+".pdf")
+		if err := os.MkdirAll(orch.config.OutputDir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(outputs[doc.ID], []byte("old render"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		docState := state.NewDocumentState(doc.ID, doc.Name, doc.Type, "")
+		docState.MarkSynced(doc.Version, time.Now(), outputs[doc.ID], "hash-"+doc.ID)
+		docState.SetConversionStatus(state.ConversionStatusCompleted)
+		store.AddDocument(docState)
+	}
+	if err := store.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := orch.Reconvert(context.Background())
+	if err != nil {
+		t.Fatalf("Reconvert() error = %v", err)
+	}
+
+	if conv.calls != 1 {
+		t.Errorf("converter called %d times, want 1", conv.calls)
+	}
+	if len(result.Reconverted) != 1 || result.Reconverted[0].DocumentID != cached.ID || len(result.Failures) != 0 {
+		t.Errorf("Reconverted = %+v, Failures = %+v, want only %s reconverted", result.Reconverted, result.Failures, cached.ID)
+	}
+	if len(result.Uncached) != 1 || result.Uncached[0].DocumentID != uncached.ID {
+		t.Errorf("Uncached = %+v, want %s", result.Uncached, uncached.ID)
+	}
+
+	if data, _ := os.ReadFile(outputs[cached.ID]); string(data) != "%PDF-1.4 stub" {
+		t.Errorf("cached document's output = %q, want it reconverted", data)
+	}
+	if data, _ := os.ReadFile(outputs[uncached.ID]); string(data) != "old render" {
+		t.Errorf("uncached document's output = %q, want it untouched", data)
+	}
+	if docState := store.GetDocument(cached.ID); docState.LocalPath != outputs[cached.ID] || docState.Version != cached.Version {
+		t.Errorf("state = %+v, want the document still recorded at its output and version", docState)
+	}
+	if !strings.Contains(result.Summary(), "Skipped (no cached download): 1") {
+		t.Errorf("Summary() = %q, want the skipped document counted", result.Summary())
+	}
+}
+
+func TestReconvert_RequiresDownloadCache(t *testing.T) {
+	orch, _ := newRetryTestOrchestrator(t, &flakyConverter{}, 0)
+	orch.downloads = nil
+
+	if _, err := orch.Reconvert(context.Background()); err == nil || !strings.Contains(err.Error(), "download-dir") {
+		t.Errorf("Reconvert() error = %v, want one naming download-dir", err)
+	}
+}
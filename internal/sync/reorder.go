@@ -0,0 +1,66 @@
+package sync
+
+// pageOrder reports whether current holds the same pages as previous in a different
+// order, returning for each current page its 1-based position in previous
+//
+// Pages are compared by content hash. Duplicate pages (such as blank pages sharing a
+// template) are matched in their original order.
+func pageOrder(previous, current []string) ([]int, bool) {
+	if len(previous) == 0 || len(previous) != len(current) {
+		return nil, false
+	}
+
+	positions := make(map[string][]int, len(previous))
+	for i, hash := range previous {
+		positions[hash] = append(positions[hash], i+1)
+	}
+
+	order := make([]int, len(current))
+	moved := false
+	for i, hash := range current {
+		candidates := positions[hash]
+		if len(candidates) == 0 {
+			return nil, false
+		}
+		order[i] = candidates[0]
+		positions[hash] = candidates[1:]
+
+		if order[i] != i+1 {
+			moved = true
+		}
+	}
+
+	return order, moved
+}
+
+// reuseReorderedPages writes the previously synced PDF of docID to pdfPath with its
+// pages rearranged, if the document's pages were only reordered since the last sync
+//
+// Returns false, leaving the document to be converted in full, if the pages changed,
+// the previous PDF is missing or has a different page count, or reassembly fails.
+func (o *Orchestrator) reuseReorderedPages(docID string, pageHashes []string, pdfPath string) bool {
+	existing := o.stateStore.GetDocument(docID)
+	if existing == nil || existing.LocalPath == "" {
+		return false
+	}
+
+	order, ok := pageOrder(existing.PageHashes, pageHashes)
+	if !ok {
+		return false
+	}
+
+	count, err := o.pdfEnhancer.GetPageCount(existing.LocalPath)
+	if err != nil || count != len(order) {
+		o.logger.WithFields("id", docID, "path", existing.LocalPath, "pages", count, "error", err).
+			Debug("Previous PDF does not match previous pages, converting in full")
+		return false
+	}
+
+	if err := o.pdfEnhancer.ReorderPages(existing.LocalPath, pdfPath, order); err != nil {
+		o.logger.WithFields("id", docID, "error", err).Warn("Failed to reorder existing PDF, converting in full")
+		return false
+	}
+
+	o.logger.WithFields("id", docID, "order", order).Info("Pages only reordered, reusing existing PDF pages")
+	return true
+}
@@ -0,0 +1,104 @@
+package sync
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/platinummonkey/legible/internal/logger"
+	"github.com/platinummonkey/legible/internal/pdfenhancer"
+	"github.com/platinummonkey/legible/internal/state"
+	"github.com/signintech/gopdf"
+)
+
+func TestPageOrder(t *testing.T) {
+	tests := []struct {
+		name      string
+		previous  []string
+		current   []string
+		wantOrder []int
+		wantOK    bool
+	}{
+		{name: "reordered", previous: []string{"a", "b", "c"}, current: []string{"c", "a", "b"}, wantOrder: []int{3, 1, 2}, wantOK: true},
+		{name: "duplicates keep order", previous: []string{"x", "a", "x"}, current: []string{"a", "x", "x"}, wantOrder: []int{2, 1, 3}, wantOK: true},
+		{name: "unchanged", previous: []string{"a", "b"}, current: []string{"a", "b"}},
+		{name: "page edited", previous: []string{"a", "b"}, current: []string{"b", "z"}},
+		{name: "page added", previous: []string{"a", "b"}, current: []string{"b", "a", "c"}},
+		{name: "no previous hashes", current: []string{"a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order, ok := pageOrder(tt.previous, tt.current)
+			if ok != tt.wantOK {
+				t.Fatalf("pageOrder() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && fmt.Sprint(order) != fmt.Sprint(tt.wantOrder) {
+				t.Errorf("pageOrder() = %v, want %v", order, tt.wantOrder)
+			}
+		})
+	}
+}
+
+func TestReuseReorderedPages(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// The previous sync produced a 3-page PDF; page widths identify the pages
+	previousPDF := filepath.Join(tmpDir, "Notebook.pdf")
+	pdf := gopdf.GoPdf{}
+	pdf.Start(gopdf.Config{PageSize: *gopdf.PageSizeA4})
+	for _, w := range []float64{100, 200, 300} {
+		pdf.AddPageWithOption(gopdf.PageOption{PageSize: &gopdf.Rect{W: w, H: 500}})
+	}
+	if err := pdf.WritePdf(previousPDF); err != nil {
+		t.Fatalf("failed to write PDF: %v", err)
+	}
+
+	store, err := state.LoadOrCreate(filepath.Join(tmpDir, "state.json"))
+	if err != nil {
+		t.Fatalf("LoadOrCreate() error = %v", err)
+	}
+	docState := state.NewDocumentState("doc-1", "Notebook", "DocumentType", "")
+	docState.LocalPath = previousPDF
+	docState.PageHashes = []string{"page-a", "page-b", "page-c"}
+	store.AddDocument(docState)
+
+	orch := &Orchestrator{
+		logger:      logger.Get(),
+		stateStore:  store,
+		pdfEnhancer: pdfenhancer.New(&pdfenhancer.Config{}),
+	}
+
+	// Pages moved: the existing pages are reassembled in the new order
+	pdfPath := filepath.Join(tmpDir, "reordered.pdf")
+	if !orch.reuseReorderedPages("doc-1", []string{"page-c", "page-a", "page-b"}, pdfPath) {
+		t.Fatal("reuseReorderedPages() = false, want true for reordered pages")
+	}
+
+	dims, err := api.PageDimsFile(pdfPath)
+	if err != nil {
+		t.Fatalf("failed to read reordered PDF: %v", err)
+	}
+	var widths []float64
+	for _, d := range dims {
+		widths = append(widths, d.Width)
+	}
+	if fmt.Sprint(widths) != "[300 100 200]" {
+		t.Errorf("page widths = %v, want [300 100 200]", widths)
+	}
+
+	// Edited pages and unknown documents need a full conversion
+	if orch.reuseReorderedPages("doc-1", []string{"page-c", "page-a", "page-new"}, pdfPath) {
+		t.Error("reuseReorderedPages() = true for edited pages")
+	}
+	if orch.reuseReorderedPages("doc-2", []string{"page-a"}, pdfPath) {
+		t.Error("reuseReorderedPages() = true for a document never synced")
+	}
+
+	// A previous PDF whose page count no longer matches its hashes is not reused
+	docState.PageHashes = []string{"page-a", "page-b"}
+	if orch.reuseReorderedPages("doc-1", []string{"page-b", "page-a"}, pdfPath) {
+		t.Error("reuseReorderedPages() = true when the previous PDF has a different page count")
+	}
+}
@@ -3,8 +3,11 @@ package sync
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 	"time"
+
+	"github.com/platinummonkey/legible/internal/ocr"
 )
 
 // Result contains the results of a complete sync operation
@@ -16,23 +19,95 @@ type Result struct {
 	Duration           time.Duration
 	Successes          []DocumentResult
 	Failures           []DocumentFailure
+	ByFolder           map[string]*GroupStats // keyed by folder path ("" is the root folder)
+	ByLabel            map[string]*GroupStats // keyed by label name
+
+	// DryRun is set when the sync only planned its work; Planned lists the documents
+	// it would have synced, and nothing was downloaded, converted, or saved
+	DryRun  bool
+	Planned []PlannedDocument
+
+	// Pruned lists the documents deleted from the cloud that were removed from the
+	// state, or with PruneDryRun set, that would have been
+	Pruned      []PrunedDocument
+	PruneDryRun bool
+
+	// OCR counts the pages OCR'd during the sync that were read from the OCR cache,
+	// and the OCR requests served by the fallback engine
+	OCR ocr.MetricsSnapshot
 }
 
-// DocumentResult contains the results of processing a single document
-type DocumentResult struct {
+// SyncReason explains why a document needs to be synced
+type SyncReason string
+
+const (
+	// SyncReasonNew is a document that has never been synced
+	SyncReasonNew SyncReason = "new"
+	// SyncReasonVersionChanged is a document whose version or modification time changed
+	SyncReasonVersionChanged SyncReason = "version-changed"
+	// SyncReasonOutputMissing is an unchanged document whose synced PDF was removed
+	SyncReasonOutputMissing SyncReason = "output-missing"
+	// SyncReasonNeedsOCR is an unchanged document that was synced without OCR
+	SyncReasonNeedsOCR SyncReason = "needs-ocr"
+	// SyncReasonDeferred is an unchanged document in the reprocessing queue, such as one
+	// whose OCR failed during an earlier sync
+	SyncReasonDeferred SyncReason = "deferred"
+)
+
+// PlannedDocument is a document a sync would process, and why
+type PlannedDocument struct {
 	DocumentID string
 	Title      string
-	PageCount  int
-	OutputPath string
-	StartTime  time.Time
-	Duration   time.Duration
+	Version    int
+	Reason     SyncReason
+}
+
+// PrunedDocument is a document deleted from the cloud that was pruned from the state
+type PrunedDocument struct {
+	DocumentID  string
+	Title       string
+	LocalPath   string // Synced PDF ("" if none was recorded)
+	FileDeleted bool   // Whether LocalPath was (or in a dry run, would be) deleted
+}
+
+// GroupStats contains sync counts for a single folder or label
+type GroupStats struct {
+	SuccessCount int
+	FailureCount int
+}
+
+// Total returns the number of documents attributed to the group
+func (gs *GroupStats) Total() int {
+	return gs.SuccessCount + gs.FailureCount
+}
+
+// DocumentResult contains the results of processing a single document
+type DocumentResult struct {
+	DocumentID   string
+	Title        string
+	PageCount    int
+	OutputPath   string
+	PageHashes   []string // Per-page content hashes, in page order (nil if unavailable)
+	OCRProcessed bool     // Whether the PDF has an OCR text layer
+	OCRDeferred  string   // Why OCR failed and was queued for the next sync ("" if it wasn't)
+	Hash         string   // Content hash of the downloaded .rmdoc ("" if unavailable)
+	Size         int64    // Size of the downloaded .rmdoc in bytes (0 if not downloaded)
+	Skipped      bool     // Whether the existing output was kept instead of converting
+	Folder       string   // Folder path on the reMarkable ("" for root)
+	Labels       []string // Labels applied to the document
+	StartTime    time.Time
+	Duration     time.Duration
 }
 
 // DocumentFailure contains information about a failed document
 type DocumentFailure struct {
 	DocumentID string
 	Title      string
+	Folder     string   // Folder path on the reMarkable ("" for root or unknown)
+	Labels     []string // Labels applied to the document
 	Error      error
+	Kind       ErrorKind // Classification of Error, set by AddFailure if empty
+	Hint       string    // Remediation hint for Kind ("" if there is none)
 }
 
 // NewResult creates a new sync result
@@ -40,6 +115,9 @@ func NewResult() *Result {
 	return &Result{
 		Successes: make([]DocumentResult, 0),
 		Failures:  make([]DocumentFailure, 0),
+		ByFolder:  make(map[string]*GroupStats),
+		ByLabel:   make(map[string]*GroupStats),
+		Planned:   make([]PlannedDocument, 0),
 	}
 }
 
@@ -47,16 +125,66 @@ func NewResult() *Result {
 func (sr *Result) AddSuccess(result *DocumentResult) {
 	sr.Successes = append(sr.Successes, *result)
 	sr.SuccessCount++
+
+	for _, stats := range sr.groupsFor(result.Folder, result.Labels) {
+		stats.SuccessCount++
+	}
 }
 
-// AddError adds a failed document
+// AddError adds a failed document with no folder or label information
 func (sr *Result) AddError(docID, title string, err error) {
-	sr.Failures = append(sr.Failures, DocumentFailure{
+	sr.AddFailure(DocumentFailure{
 		DocumentID: docID,
 		Title:      title,
 		Error:      err,
 	})
+}
+
+// AddFailure adds a failed document, attributing it to its folder and labels
+func (sr *Result) AddFailure(failure DocumentFailure) {
+	if failure.Kind == "" {
+		failure.Kind = ClassifyError(failure.Error)
+		failure.Hint = failure.Kind.Remediation()
+	}
+	sr.Failures = append(sr.Failures, failure)
 	sr.FailureCount++
+
+	for _, stats := range sr.groupsFor(failure.Folder, failure.Labels) {
+		stats.FailureCount++
+	}
+}
+
+// groupsFor returns the folder and label groups a document belongs to, creating them as needed
+func (sr *Result) groupsFor(folder string, labels []string) []*GroupStats {
+	if sr.ByFolder == nil {
+		sr.ByFolder = make(map[string]*GroupStats)
+	}
+	if sr.ByLabel == nil {
+		sr.ByLabel = make(map[string]*GroupStats)
+	}
+
+	groups := []*GroupStats{groupStats(sr.ByFolder, folder)}
+
+	seen := make(map[string]bool)
+	for _, label := range labels {
+		if label == "" || seen[label] {
+			continue
+		}
+		seen[label] = true
+		groups = append(groups, groupStats(sr.ByLabel, label))
+	}
+
+	return groups
+}
+
+// groupStats returns the stats for key, adding an empty entry if it doesn't exist
+func groupStats(groups map[string]*GroupStats, key string) *GroupStats {
+	stats, ok := groups[key]
+	if !ok {
+		stats = &GroupStats{}
+		groups[key] = stats
+	}
+	return stats
 }
 
 // HasFailures returns true if there were any failures
@@ -68,6 +196,17 @@ func (sr *Result) HasFailures() bool {
 func (sr *Result) Summary() string {
 	var sb strings.Builder
 
+	if sr.DryRun {
+		sb.WriteString("Sync Plan (dry run):\n")
+		fmt.Fprintf(&sb, "  Total Documents: %d\n", sr.TotalDocuments)
+		fmt.Fprintf(&sb, "  Would Sync: %d\n", len(sr.Planned))
+		for _, doc := range sr.Planned {
+			fmt.Fprintf(&sb, "  - %s (%s): %s\n", doc.Title, doc.DocumentID, doc.Reason)
+		}
+		sr.writePruneSummary(&sb)
+		return sb.String()
+	}
+
 	sb.WriteString("Sync Summary:\n")
 	fmt.Fprintf(&sb, "  Total Documents: %d\n", sr.TotalDocuments)
 	fmt.Fprintf(&sb, "  Processed: %d\n", sr.ProcessedDocuments)
@@ -75,17 +214,80 @@ func (sr *Result) Summary() string {
 	fmt.Fprintf(&sb, "  Failed: %d\n", sr.FailureCount)
 	fmt.Fprintf(&sb, "  Duration: %v\n", sr.Duration)
 
+	if len(sr.ByFolder) > 0 {
+		sb.WriteString("\nBy Folder:\n")
+		writeGroupSummary(&sb, sr.ByFolder, "(root)")
+	}
+
+	if len(sr.ByLabel) > 0 {
+		sb.WriteString("\nBy Label:\n")
+		writeGroupSummary(&sb, sr.ByLabel, "")
+	}
+
+	if !sr.OCR.IsZero() {
+		sb.WriteString("\nOCR:\n")
+		fmt.Fprintf(&sb, "  Cache: %d hits, %d misses\n", sr.OCR.CacheHits, sr.OCR.CacheMisses)
+		fmt.Fprintf(&sb, "  Fallback: %d requests\n", sr.OCR.Fallbacks)
+	}
+
+	sr.writePruneSummary(&sb)
+
 	if sr.HasFailures() {
 		sb.WriteString("\nFailures:\n")
 		for _, failure := range sr.Failures {
 			fmt.Fprintf(&sb, "  - %s (%s): %v\n",
 				failure.Title, failure.DocumentID, failure.Error)
+			if failure.Hint != "" {
+				fmt.Fprintf(&sb, "    Hint: %s\n", failure.Hint)
+			}
 		}
 	}
 
 	return sb.String()
 }
 
+// writePruneSummary writes the documents pruned, or that would be, if any
+func (sr *Result) writePruneSummary(sb *strings.Builder) {
+	if len(sr.Pruned) == 0 {
+		return
+	}
+
+	if sr.PruneDryRun {
+		fmt.Fprintf(sb, "\nWould Prune (deleted from the cloud): %d\n", len(sr.Pruned))
+	} else {
+		fmt.Fprintf(sb, "\nPruned (deleted from the cloud): %d\n", len(sr.Pruned))
+	}
+	for _, doc := range sr.Pruned {
+		switch {
+		case doc.FileDeleted && sr.PruneDryRun:
+			fmt.Fprintf(sb, "  - %s (%s), would delete %s\n", doc.Title, doc.DocumentID, doc.LocalPath)
+		case doc.FileDeleted:
+			fmt.Fprintf(sb, "  - %s (%s), deleted %s\n", doc.Title, doc.DocumentID, doc.LocalPath)
+		default:
+			fmt.Fprintf(sb, "  - %s (%s)\n", doc.Title, doc.DocumentID)
+		}
+	}
+}
+
+// writeGroupSummary writes per-group counts sorted by name
+func writeGroupSummary(sb *strings.Builder, groups map[string]*GroupStats, emptyName string) {
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		stats := groups[name]
+		displayName := name
+		if displayName == "" {
+			displayName = emptyName
+		}
+		fmt.Fprintf(sb, "  %s: %d successful, %d failed\n",
+			displayName, stats.SuccessCount, stats.FailureCount)
+	}
+}
+
 // String returns a string representation of the sync result
 func (sr *Result) String() string {
 	return sr.Summary()
@@ -5,6 +5,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/platinummonkey/legible/internal/ocr"
 )
 
 func TestNewResult(t *testing.T) {
@@ -85,6 +87,68 @@ func TestResult_AddError(t *testing.T) {
 	}
 }
 
+func TestResult_GroupedCounts(t *testing.T) {
+	result := NewResult()
+
+	result.AddSuccess(&DocumentResult{DocumentID: "doc-1", Folder: "Work", Labels: []string{"urgent", "meetings"}})
+	result.AddSuccess(&DocumentResult{DocumentID: "doc-2", Folder: "Work", Labels: []string{"meetings"}})
+	result.AddSuccess(&DocumentResult{DocumentID: "doc-3", Folder: "Personal"})
+	result.AddFailure(DocumentFailure{DocumentID: "doc-4", Folder: "Work", Labels: []string{"urgent"}, Error: fmt.Errorf("boom")})
+	result.AddFailure(DocumentFailure{DocumentID: "doc-5", Folder: "Personal/Journal", Error: fmt.Errorf("boom")})
+	result.AddError("doc-6", "Root Doc", fmt.Errorf("boom"))
+
+	wantFolders := map[string]GroupStats{
+		"Work":             {SuccessCount: 2, FailureCount: 1},
+		"Personal":         {SuccessCount: 1, FailureCount: 0},
+		"Personal/Journal": {SuccessCount: 0, FailureCount: 1},
+		"":                 {SuccessCount: 0, FailureCount: 1},
+	}
+	if len(result.ByFolder) != len(wantFolders) {
+		t.Errorf("len(ByFolder) = %d, want %d", len(result.ByFolder), len(wantFolders))
+	}
+	for folder, want := range wantFolders {
+		got, ok := result.ByFolder[folder]
+		if !ok {
+			t.Errorf("ByFolder missing %q", folder)
+			continue
+		}
+		if *got != want {
+			t.Errorf("ByFolder[%q] = %+v, want %+v", folder, *got, want)
+		}
+	}
+
+	wantLabels := map[string]GroupStats{
+		"urgent":   {SuccessCount: 1, FailureCount: 1},
+		"meetings": {SuccessCount: 2, FailureCount: 0},
+	}
+	if len(result.ByLabel) != len(wantLabels) {
+		t.Errorf("len(ByLabel) = %d, want %d", len(result.ByLabel), len(wantLabels))
+	}
+	for label, want := range wantLabels {
+		got, ok := result.ByLabel[label]
+		if !ok {
+			t.Errorf("ByLabel missing %q", label)
+			continue
+		}
+		if *got != want {
+			t.Errorf("ByLabel[%q] = %+v, want %+v", label, *got, want)
+		}
+	}
+
+	summary := result.Summary()
+	for _, expected := range []string{
+		"By Folder:",
+		"Work: 2 successful, 1 failed",
+		"(root): 0 successful, 1 failed",
+		"By Label:",
+		"urgent: 1 successful, 1 failed",
+	} {
+		if !strings.Contains(summary, expected) {
+			t.Errorf("Summary should contain %q, got:\n%s", expected, summary)
+		}
+	}
+}
+
 func TestResult_HasFailures(t *testing.T) {
 	result := NewResult()
 
@@ -165,6 +229,39 @@ func TestResult_Summary_NoFailures(t *testing.T) {
 	}
 }
 
+func TestResult_Summary_OCR(t *testing.T) {
+	result := NewResult()
+	if strings.Contains(result.Summary(), "OCR:") {
+		t.Error("Summary should not contain an OCR section without OCR activity")
+	}
+
+	result.OCR = ocr.MetricsSnapshot{CacheHits: 3, CacheMisses: 1, Fallbacks: 2}
+	summary := result.Summary()
+	for _, want := range []string{"Cache: 3 hits, 1 misses", "Fallback: 2 requests"} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("Summary should contain %q:\n%s", want, summary)
+		}
+	}
+}
+
+func TestResult_Summary_DryRun(t *testing.T) {
+	result := NewResult()
+	result.DryRun = true
+	result.TotalDocuments = 3
+	result.Planned = append(result.Planned, PlannedDocument{DocumentID: "doc-1", Title: "Notes", Reason: SyncReasonNew})
+
+	summary := result.Summary()
+
+	for _, want := range []string{"Sync Plan (dry run):", "Would Sync: 1", "Notes (doc-1): new"} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("Summary should contain %q:\n%s", want, summary)
+		}
+	}
+	if strings.Contains(summary, "Successful:") {
+		t.Error("dry run Summary should not report processed counts")
+	}
+}
+
 func TestResult_String(t *testing.T) {
 	result := NewResult()
 	result.TotalDocuments = 5
@@ -0,0 +1,174 @@
+package sync
+
+import (
+	"context"
+	"time"
+
+	"github.com/platinummonkey/legible/internal/rmclient"
+	"github.com/platinummonkey/legible/internal/state"
+)
+
+// maxRetryDelay caps the exponential backoff between attempts at one document
+const maxRetryDelay = 5 * time.Minute
+
+// syncDocument processes a document, retrying failures with exponential backoff, and
+// records the outcome in result and the sync state
+//
+// Each failed attempt increments the document's RetryCount and is saved, so a sync that
+// is interrupted resumes with the retries that remain. Once a remote version has failed
+// more than MaxRetries times the document is given up on, and planSync skips it until a
+// new version is available.
+func (o *Orchestrator) syncDocument(ctx context.Context, doc rmclient.Document, docNum, totalDocs int, result *Result) {
+	o.syncDownloaded(ctx, doc, docNum, totalDocs, nil, result)
+}
+
+// syncDownloaded is syncDocument for a document the pipeline's download stage may have
+// downloaded already; the download is used for the first attempt only, and retries
+// download the document again
+func (o *Orchestrator) syncDownloaded(ctx context.Context, doc rmclient.Document, docNum, totalDocs int, download *prefetchedDownload, result *Result) {
+	for {
+		o.logger.WithFields(
+			"document", docNum,
+			"total", totalDocs,
+			"id", doc.ID,
+			"title", doc.Name,
+		).Info("Processing document")
+
+		docResult, err := o.processDocument(ctx, doc, docNum, totalDocs, download)
+		download = nil
+		if err == nil {
+			o.recordSuccess(doc, docResult, result)
+			return
+		}
+
+		failure := DocumentFailure{
+			DocumentID: doc.ID,
+			Title:      doc.Name,
+			Folder:     o.folderPath(doc.ID),
+			Labels:     doc.Tags,
+			Error:      err,
+		}
+
+		// A cancelled sync is not the document's fault, so it doesn't use up a retry
+		if ctx.Err() != nil {
+			o.logger.WithFields("id", doc.ID, "error", err).Warn("Document processing cancelled")
+			o.addFailure(result, failure)
+			return
+		}
+
+		retryCount, exhausted := o.recordFailure(doc, err)
+		if exhausted {
+			o.logger.WithFields("id", doc.ID, "retries", retryCount-1, "error", err).
+				Error("Document processing failed, skipping until a new version is available")
+			o.addFailure(result, failure)
+			return
+		}
+
+		delay := retryDelay(o.config.RetryBackoff, retryCount)
+		o.logger.WithFields("id", doc.ID, "attempt", retryCount, "max_retries", o.config.MaxRetries,
+			"delay", delay, "error", err).Warn("Document processing failed, retrying")
+
+		select {
+		case <-ctx.Done():
+			o.addFailure(result, failure)
+			return
+		case <-time.After(delay):
+		}
+	}
+}
+
+// retryDelay returns the delay before retrying a document that has failed failures times:
+// backoff, doubling with each further failure, up to maxRetryDelay
+func retryDelay(backoff time.Duration, failures int) time.Duration {
+	delay := backoff
+	for i := 1; i < failures && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+	return delay
+}
+
+// recordSuccess adds a synced document to result, and marks it as synced in the state
+// and saves it
+func (o *Orchestrator) recordSuccess(doc rmclient.Document, docResult *DocumentResult, result *Result) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	result.AddSuccess(docResult)
+
+	docState := o.documentState(doc)
+
+	docState.MarkSynced(doc.Version, doc.ModifiedClient, docResult.OutputPath, docResult.Hash)
+	docState.SetConversionStatus(state.ConversionStatusCompleted)
+	docState.PageHashes = docResult.PageHashes
+	if docResult.Size > 0 {
+		docState.Size = docResult.Size
+	}
+	recordOCRState(docState, docResult)
+
+	o.saveDocumentState(docState)
+}
+
+// recordOCRState records in a document's state whether its output has an OCR text
+// layer, and whether OCR was deferred to the next sync
+func recordOCRState(docState *state.DocumentState, docResult *DocumentResult) {
+	if docResult.OCRProcessed {
+		docState.MarkOCRComplete()
+	}
+	if docResult.OCRDeferred != "" {
+		docState.Defer(state.DeferredOCR, docResult.OCRDeferred)
+	} else {
+		docState.ClearDeferred()
+	}
+}
+
+// recordFailure records a failed attempt at a document in the state and saves it,
+// returning the number of failed attempts at the document's version and whether it has
+// used up its retries
+func (o *Orchestrator) recordFailure(doc rmclient.Document, err error) (int, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	docState := o.documentState(doc)
+	docState.MarkFailed(doc.Version, err)
+
+	o.saveDocumentState(docState)
+	return docState.RetryCount, docState.RetriesExhausted(doc.Version, o.config.MaxRetries)
+}
+
+// addFailure adds a failed document to result
+func (o *Orchestrator) addFailure(result *Result, failure DocumentFailure) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	result.AddFailure(failure)
+}
+
+// documentState returns the state of a document, creating it if needed, with its
+// metadata updated from doc
+func (o *Orchestrator) documentState(doc rmclient.Document) *state.DocumentState {
+	docState := o.stateStore.GetDocument(doc.ID)
+	if docState == nil {
+		docState = state.NewDocumentState(doc.ID, doc.Name, doc.Type, doc.Parent)
+	}
+
+	// Update document metadata (in case it changed)
+	docState.Name = doc.Name
+	docState.Parent = doc.Parent
+	docState.Type = doc.Type
+	docState.Labels = doc.Tags
+
+	return docState
+}
+
+// saveDocumentState stores a document's state and saves the state file, so progress
+// isn't lost if a later document fails
+//
+// Callers must hold o.mu.
+func (o *Orchestrator) saveDocumentState(docState *state.DocumentState) {
+	o.stateStore.AddDocument(docState)
+	if err := o.stateStore.Save(); err != nil {
+		o.logger.WithFields("error", err).Warn("Failed to save state")
+	}
+}
@@ -0,0 +1,191 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/platinummonkey/legible/internal/config"
+	"github.com/platinummonkey/legible/internal/converter"
+	"github.com/platinummonkey/legible/internal/logger"
+	"github.com/platinummonkey/legible/internal/rmclient"
+	"github.com/platinummonkey/legible/internal/state"
+)
+
+// flakyConverter fails its first failures conversions, then writes a stub PDF
+type flakyConverter struct {
+	failures int
+	calls    int
+	// retryCounts holds the document's RetryCount in the state at each call
+	retryCounts []int
+	store       *state.Manager
+	docID       string
+}
+
+func (c *flakyConverter) ConvertRmdocContext(_ context.Context, _, outputPath string) (*converter.ConversionResult, error) {
+	c.calls++
+	retries := 0
+	if doc := c.store.GetDocument(c.docID); doc != nil {
+		retries = doc.RetryCount
+	}
+	c.retryCounts = append(c.retryCounts, retries)
+
+	if c.calls <= c.failures {
+		return nil, errors.New("simulated conversion failure")
+	}
+	if err := os.WriteFile(outputPath, []byte("%PDF-1.4 stub"), 0644); err != nil {
+		return nil, err
+	}
+	result := converter.NewConversionResult()
+	result.PageCount = 1
+	return result, nil
+}
+
+// newRetryTestOrchestrator returns an orchestrator whose downloads of docs are already
+// cached, so documents can be processed without contacting the reMarkable cloud
+func newRetryTestOrchestrator(t *testing.T, conv DocumentConverter, maxRetries int, docs ...rmclient.Document) (*Orchestrator, *state.Manager) {
+	t.Helper()
+	tmpDir := t.TempDir()
+
+	store, err := state.LoadOrCreate(filepath.Join(tmpDir, "state.json"))
+	if err != nil {
+		t.Fatalf("LoadOrCreate() error = %v", err)
+	}
+
+	downloads := newDownloadCache(filepath.Join(tmpDir, "downloads"))
+	if err := os.MkdirAll(downloads.dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, doc := range docs {
+		if err := os.WriteFile(downloads.path(doc.ID, doc.Version), []byte("not a real rmdoc"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	orch := &Orchestrator{
+		config: &config.Config{
+			OutputDir:    filepath.Join(tmpDir, "output"),
+			MaxRetries:   maxRetries,
+			RetryBackoff: time.Millisecond,
+		},
+		logger:      logger.Get(),
+		rmClient:    &rmclient.Client{},
+		stateStore:  store,
+		converter:   conv,
+		names:       newOutputNamer(config.DuplicateNamesSuffix, store.GetState()),
+		downloads:   downloads,
+		concurrency: 1,
+	}
+	return orch, store
+}
+
+func TestSyncDocument_RetriesUntilSuccess(t *testing.T) {
+	doc := rmclient.Document{ID: "doc-1", Name: "Notes", Type: rmclient.DocumentType, Version: 3}
+	conv := &flakyConverter{failures: 2, docID: doc.ID}
+	orch, store := newRetryTestOrchestrator(t, conv, 3, doc)
+	conv.store = store
+
+	result := NewResult()
+	orch.syncDocument(context.Background(), doc, 1, 1, result)
+
+	if conv.calls != 3 {
+		t.Errorf("converter called %d times, want 3", conv.calls)
+	}
+	// Each failure is recorded in the state before the next attempt
+	if got := fmt.Sprint(conv.retryCounts); got != "[0 1 2]" {
+		t.Errorf("RetryCount at each attempt = %s, want [0 1 2]", got)
+	}
+
+	if result.SuccessCount != 1 || result.FailureCount != 0 {
+		t.Errorf("result = %d succeeded, %d failed, want 1 and 0", result.SuccessCount, result.FailureCount)
+	}
+
+	docState := store.GetDocument(doc.ID)
+	if docState == nil {
+		t.Fatal("document not recorded in state")
+	}
+	if docState.RetryCount != 0 || docState.Error != "" {
+		t.Errorf("state after success: RetryCount = %d, Error = %q, want both cleared", docState.RetryCount, docState.Error)
+	}
+	if docState.ConversionStatus != state.ConversionStatusCompleted || docState.Version != 3 {
+		t.Errorf("state after success: status %s, version %d", docState.ConversionStatus, docState.Version)
+	}
+	if _, err := os.Stat(docState.LocalPath); err != nil {
+		t.Errorf("output not written: %v", err)
+	}
+}
+
+func TestSyncDocument_GivesUpAfterMaxRetries(t *testing.T) {
+	doc := rmclient.Document{ID: "doc-1", Name: "Notes", Type: rmclient.DocumentType, Version: 3}
+	conv := &flakyConverter{failures: 100, docID: doc.ID}
+	orch, store := newRetryTestOrchestrator(t, conv, 2, doc)
+	conv.store = store
+
+	result := NewResult()
+	orch.syncDocument(context.Background(), doc, 1, 1, result)
+
+	if conv.calls != 3 {
+		t.Errorf("converter called %d times, want 3 (1 attempt + 2 retries)", conv.calls)
+	}
+	if result.FailureCount != 1 || result.SuccessCount != 0 {
+		t.Errorf("result = %d succeeded, %d failed, want 0 and 1", result.SuccessCount, result.FailureCount)
+	}
+
+	docState := store.GetDocument(doc.ID)
+	if docState.RetryCount != 3 || docState.FailedVersion != 3 {
+		t.Errorf("RetryCount = %d of version %d, want 3 of version 3", docState.RetryCount, docState.FailedVersion)
+	}
+	if docState.Error != "conversion failed: simulated conversion failure" {
+		t.Errorf("Error = %q, want last error recorded", docState.Error)
+	}
+
+	// The next sync skips the document until a new version is available
+	if toSync := orch.planSync([]rmclient.Document{doc}, store.BuildIndex()); len(toSync) != 0 {
+		t.Errorf("planSync() = %d documents, want the failed version skipped", len(toSync))
+	}
+	doc.Version = 4
+	if toSync := orch.planSync([]rmclient.Document{doc}, store.BuildIndex()); len(toSync) != 1 {
+		t.Errorf("planSync() = %d documents, want the new version synced", len(toSync))
+	}
+}
+
+func TestSyncDocument_ResumesRemainingRetries(t *testing.T) {
+	doc := rmclient.Document{ID: "doc-1", Name: "Notes", Type: rmclient.DocumentType, Version: 3}
+	conv := &flakyConverter{failures: 100, docID: doc.ID}
+	orch, store := newRetryTestOrchestrator(t, conv, 2, doc)
+	conv.store = store
+
+	// A previous sync already failed twice on this version
+	previous := state.NewDocumentState(doc.ID, doc.Name, doc.Type, "")
+	previous.MarkFailed(3, errors.New("earlier failure"))
+	previous.MarkFailed(3, errors.New("earlier failure"))
+	store.AddDocument(previous)
+
+	orch.syncDocument(context.Background(), doc, 1, 1, NewResult())
+
+	if conv.calls != 1 {
+		t.Errorf("converter called %d times, want 1 remaining attempt", conv.calls)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{failures: 1, want: 2 * time.Second},
+		{failures: 2, want: 4 * time.Second},
+		{failures: 4, want: 16 * time.Second},
+		{failures: 20, want: maxRetryDelay},
+	}
+
+	for _, tt := range tests {
+		if got := retryDelay(2*time.Second, tt.failures); got != tt.want {
+			t.Errorf("retryDelay(2s, %d) = %v, want %v", tt.failures, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package sync
+
+import "fmt"
+
+// ocrToggler is implemented by converters whose OCR can be turned on and off between
+// conversions; converter.Converter implements it
+type ocrToggler interface {
+	SetOCREnabled(enabled bool) error
+}
+
+// labels returns the labels documents are synced by
+func (o *Orchestrator) labels() []string {
+	o.settingsMu.Lock()
+	defer o.settingsMu.Unlock()
+	return o.config.Labels
+}
+
+// ocrEnabled reports whether documents are converted with OCR
+func (o *Orchestrator) ocrEnabled() bool {
+	o.settingsMu.Lock()
+	defer o.settingsMu.Unlock()
+	return o.config.OCREnabled
+}
+
+// SetLabels changes the labels documents are synced by, from the next sync
+func (o *Orchestrator) SetLabels(labels []string) {
+	o.settingsMu.Lock()
+	defer o.settingsMu.Unlock()
+	o.config.Labels = labels
+}
+
+// SetOCREnabled turns OCR on or off for the documents converted afterwards
+func (o *Orchestrator) SetOCREnabled(enabled bool) error {
+	if enabled == o.ocrEnabled() {
+		return nil
+	}
+	toggler, ok := o.converter.(ocrToggler)
+	if !ok {
+		return fmt.Errorf("the converter's OCR setting can't be changed")
+	}
+	if err := toggler.SetOCREnabled(enabled); err != nil {
+		return err
+	}
+
+	o.settingsMu.Lock()
+	defer o.settingsMu.Unlock()
+	o.config.OCREnabled = enabled
+	return nil
+}
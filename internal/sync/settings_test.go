@@ -0,0 +1,100 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/platinummonkey/legible/internal/converter"
+	"github.com/platinummonkey/legible/internal/rmclient"
+)
+
+// toggleConverter is a converter whose OCR can be turned on and off, except while
+// refuse is set
+type toggleConverter struct {
+	enabled bool
+	refuse  bool
+}
+
+func (c *toggleConverter) ConvertRmdocContext(_ context.Context, _, _ string) (*converter.ConversionResult, error) {
+	return converter.NewConversionResult(), nil
+}
+
+func (c *toggleConverter) SetOCREnabled(enabled bool) error {
+	if c.refuse {
+		return errors.New("refused")
+	}
+	c.enabled = enabled
+	return nil
+}
+
+func TestOrchestrator_SetLabels(t *testing.T) {
+	orch, _ := newRetryTestOrchestrator(t, &toggleConverter{}, 0)
+
+	orch.SetLabels([]string{"work", "urgent"})
+	if got := strings.Join(orch.labels(), ","); got != "work,urgent" {
+		t.Errorf("labels() = %q, want work,urgent", got)
+	}
+	orch.SetLabels(nil)
+	if got := orch.labels(); len(got) != 0 {
+		t.Errorf("labels() = %v, want none", got)
+	}
+}
+
+func TestOrchestrator_SetLabelsDuringSync(t *testing.T) {
+	conv := &flakyConverter{docID: "doc-1"}
+	orch, store := newRetryTestOrchestrator(t, conv, 0)
+	conv.store = store
+	orch.rmClient = &countingCloud{docs: []rmclient.Document{
+		{ID: "doc-1", Name: "Notes", Type: rmclient.DocumentType, Version: 1},
+	}}
+
+	// A reload changes the labels while syncs run; run with -race to catch unguarded reads
+	done := make(chan struct{})
+	reloaded := make(chan struct{})
+	go func() {
+		defer close(reloaded)
+		for i := 0; ; i++ {
+			select {
+			case <-done:
+				return
+			default:
+				orch.SetLabels([]string{fmt.Sprintf("label-%d", i)})
+			}
+		}
+	}()
+
+	for i := 0; i < 100; i++ {
+		if _, err := orch.Sync(context.Background()); err != nil {
+			t.Errorf("Sync() error = %v", err)
+		}
+	}
+	close(done)
+	<-reloaded
+}
+
+func TestOrchestrator_SetOCREnabled(t *testing.T) {
+	conv := &toggleConverter{}
+	orch, _ := newRetryTestOrchestrator(t, conv, 0)
+
+	if err := orch.SetOCREnabled(true); err != nil || !conv.enabled || !orch.ocrEnabled() {
+		t.Errorf("SetOCREnabled(true) error = %v, converter enabled = %t, orchestrator enabled = %t", err, conv.enabled, orch.ocrEnabled())
+	}
+
+	// A converter that refuses the change leaves the setting alone
+	conv.refuse = true
+	if err := orch.SetOCREnabled(false); err == nil || !orch.ocrEnabled() {
+		t.Errorf("SetOCREnabled(false) error = %v, enabled = %t, want an error leaving OCR on", err, orch.ocrEnabled())
+	}
+
+	// So does a converter whose OCR can't be changed, though keeping the setting is fine
+	fixed, _ := newRetryTestOrchestrator(t, &flakyConverter{}, 0)
+	if err := fixed.SetOCREnabled(false); err != nil {
+		t.Errorf("SetOCREnabled(false) error = %v, want none for an unchanged setting", err)
+	}
+	if err := fixed.SetOCREnabled(true); err == nil || fixed.ocrEnabled() {
+		t.Errorf("SetOCREnabled(true) error = %v, enabled = %t, want an error leaving OCR off", err, fixed.ocrEnabled())
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/platinummonkey/legible/internal/config"
@@ -20,22 +22,79 @@ import (
 type Orchestrator struct {
 	config      *config.Config
 	logger      *logger.Logger
-	rmClient    *rmclient.Client
-	stateStore  *state.Manager
-	converter   *converter.Converter
+	rmClient    CloudClient
+	stateStore  state.Store
+	converter   DocumentConverter
+	downloader  documentDownloader // downloads documents in place of rmClient, if set
 	ocrProc     *ocr.Processor
 	pdfEnhancer *pdfenhancer.PDFEnhancer
+	names       *outputNamer   // output path assignment for the current sync run
+	downloads   *downloadCache // nil when downloads are not cached
+	concurrency int            // documents processed in parallel
+	pipeline    int            // documents downloaded ahead of conversion, 0 = not pipelined
+	dryRun      bool           // plan the sync without processing documents
+	prune       pruneOptions   // removal of documents deleted from the cloud
+	lockTimeout time.Duration  // wait for the state lock held by another process
+
+	// downloadSlots limits the downloads in flight; nil when only concurrency limits them
+	downloadSlots chan struct{}
+	// onProgress receives the progress of document downloads, if set
+	onProgress func(DownloadProgress)
+
+	// mu serializes updates to the sync result and state between workers
+	mu sync.Mutex
+
+	// settingsMu guards the settings SetLabels changes between syncs
+	settingsMu sync.Mutex
+}
+
+// CloudClient lists and downloads documents from the reMarkable cloud; rmclient.Client
+// implements it
+type CloudClient interface {
+	Refresh() error
+	ListDocuments(labels []string) ([]rmclient.Document, error)
+	ListDocumentsInFolder(folder string, labels []string) ([]rmclient.Document, error)
+	GetFolderPath(documentID string) (string, error)
+	DownloadDocument(id, outputPath string) error
+	DownloadDocumentWithProgress(id, outputPath string, progress rmclient.ProgressFunc) error
+}
+
+// DocumentConverter converts downloaded .rmdoc files to PDF; converter.Converter implements it
+type DocumentConverter interface {
+	ConvertRmdocContext(ctx context.Context, rmdocPath, outputPath string) (*converter.ConversionResult, error)
 }
 
 // Config holds configuration for the sync orchestrator
 type Config struct {
 	Config       *config.Config
 	Logger       *logger.Logger
-	RMClient     *rmclient.Client
-	StateStore   *state.Manager
-	Converter    *converter.Converter
+	RMClient     CloudClient
+	StateStore   state.Store
+	Converter    DocumentConverter
 	OCRProcessor *ocr.Processor
 	PDFEnhancer  *pdfenhancer.PDFEnhancer
+	// Concurrency is the number of documents processed in parallel (default: 1)
+	Concurrency int
+	// DownloadConcurrency is the most documents downloaded at once; 0 leaves downloads
+	// limited only by Concurrency
+	DownloadConcurrency int
+	// PipelineDepth pipelines syncs: documents are downloaded one after another, up to
+	// this many ahead of the conversions, instead of each being converted right after
+	// its download (0 = not pipelined)
+	PipelineDepth int
+	// DryRun makes Sync report the documents it would sync, with the reason for each,
+	// without downloading, converting, or saving state
+	DryRun bool
+	// PruneDeleted removes the state entries of documents no longer in the cloud
+	PruneDeleted bool
+	// PruneOutputFiles also deletes the local PDFs of pruned documents
+	PruneOutputFiles bool
+	// DryRunPrune reports the documents PruneDeleted would remove without removing
+	// them (implied by DryRun)
+	DryRunPrune bool
+	// StateLockTimeout is how long Sync and ImportLocal wait for another process to
+	// release the state lock (0 = don't wait)
+	StateLockTimeout time.Duration
 }
 
 // New creates a new sync orchestrator
@@ -67,7 +126,13 @@ func New(cfg *Config) (*Orchestrator, error) {
 		return nil, fmt.Errorf("pdfEnhancer is required")
 	}
 
-	return &Orchestrator{
+	// Process documents one at a time unless configured otherwise
+	concurrency := cfg.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	orch := &Orchestrator{
 		config:      cfg.Config,
 		logger:      log,
 		rmClient:    cfg.RMClient,
@@ -75,7 +140,26 @@ func New(cfg *Config) (*Orchestrator, error) {
 		converter:   cfg.Converter,
 		ocrProc:     cfg.OCRProcessor,
 		pdfEnhancer: cfg.PDFEnhancer,
-	}, nil
+		concurrency: concurrency,
+		pipeline:    cfg.PipelineDepth,
+		dryRun:      cfg.DryRun,
+		lockTimeout: cfg.StateLockTimeout,
+		prune: pruneOptions{
+			enabled:     cfg.PruneDeleted,
+			outputFiles: cfg.PruneOutputFiles,
+			dryRun:      cfg.DryRun || cfg.DryRunPrune,
+		},
+	}
+
+	if cfg.DownloadConcurrency > 0 && cfg.DownloadConcurrency < concurrency {
+		orch.downloadSlots = make(chan struct{}, cfg.DownloadConcurrency)
+	}
+
+	if cfg.Config.DownloadDir != "" {
+		orch.downloads = newDownloadCache(cfg.Config.DownloadDir)
+	}
+
+	return orch, nil
 }
 
 // Sync performs a complete synchronization workflow
@@ -83,85 +167,129 @@ func (o *Orchestrator) Sync(ctx context.Context) (*Result, error) {
 	o.logger.Info("Starting sync workflow")
 	startTime := time.Now()
 
-	result := NewResult()
-
-	// Step 1: List documents from API (already filtered by labels in rmClient)
+	// Step 1: List documents from API (already filtered by folder and labels in rmClient),
+	// reloading the document tree first in case it changed since the last sync
 	o.logger.Info("Listing documents from reMarkable API")
-	docs, err := o.rmClient.ListDocuments(o.config.Labels)
+	if err := o.rmClient.Refresh(); err != nil {
+		return nil, fmt.Errorf("failed to list documents: %w", err)
+	}
+	var docs []rmclient.Document
+	var err error
+	labels := o.labels()
+	if o.config.Folder != "" {
+		docs, err = o.rmClient.ListDocumentsInFolder(o.config.Folder, labels)
+	} else {
+		docs, err = o.rmClient.ListDocuments(labels)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to list documents: %w", err)
 	}
 
-	if len(o.config.Labels) > 0 {
-		o.logger.WithFields("count", len(docs), "labels", o.config.Labels).
+	if len(labels) > 0 {
+		o.logger.WithFields("count", len(docs), "labels", labels).
 			Info("Retrieved and filtered documents from API")
 	} else {
 		o.logger.WithFields("count", len(docs)).Info("Retrieved documents from API")
 	}
 
-	// Step 2: Load current sync state
-	if err := o.stateStore.Load(); err != nil {
-		o.logger.WithFields("error", err).Warn("Failed to load state, starting fresh")
+	// Pruning needs every cloud document, not just those matching the filters
+	var cloudIDs map[string]bool
+	if o.prune.enabled {
+		cloudIDs, err = o.cloudDocumentIDs(docs, labels)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list documents for pruning: %w", err)
+		}
 	}
-	currentState := o.stateStore.GetState()
-
-	// Step 3: Identify new/changed documents
-	docsToSync := o.identifyDocumentsToSync(docs, currentState)
-	o.logger.WithFields("count", len(docsToSync)).Info("Identified documents to sync")
 
-	// Step 4: Process each document
-	for i, doc := range docsToSync {
-		docNum := i + 1
-		totalDocs := len(docsToSync)
-
-		o.logger.WithFields(
-			"document", docNum,
-			"total", totalDocs,
-			"id", doc.ID,
-			"title", doc.Name,
-		).Info("Processing document")
-
-		// Process document through pipeline
-		docResult, err := o.processDocument(ctx, doc, docNum, totalDocs)
+	// Hold the state lock from loading the state through its last save, so a sync in
+	// another process can't interleave its saves with this one's
+	if !o.dryRun {
+		unlock, err := o.lockState()
 		if err != nil {
-			o.logger.WithFields("id", doc.ID, "error", err).Error("Document processing failed")
-			result.AddError(doc.ID, doc.Name, err)
-			continue
+			return nil, err
 		}
+		defer unlock()
+	}
+
+	return o.syncDocuments(ctx, docs, cloudIDs, startTime), nil
+}
 
-		// Update result
-		result.AddSuccess(docResult)
+// ocrMetrics returns the OCR processor's counters, or nil without OCR
+func (o *Orchestrator) ocrMetrics() *ocr.Metrics {
+	if o.ocrProc == nil {
+		return nil
+	}
+	return o.ocrProc.Metrics()
+}
 
-		// Update state incrementally (don't lose progress on failures)
-		// Get existing state or create new one
-		docState := currentState.GetDocument(doc.ID)
-		if docState == nil {
-			docState = state.NewDocumentState(doc.ID, doc.Name, doc.Type, doc.Parent)
-			docState.Labels = doc.Tags
+// lockState takes the cross-process state lock, returning the function that releases it
+func (o *Orchestrator) lockState() (func(), error) {
+	lock, err := o.stateStore.Lock(o.lockTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock sync state: %w", err)
+	}
+	return func() {
+		if err := lock.Unlock(); err != nil {
+			o.logger.WithFields("error", err).Warn("Failed to unlock state")
 		}
+	}, nil
+}
 
-		// Update sync metadata
-		docState.MarkSynced(doc.Version, doc.ModifiedClient, docResult.OutputPath, "")
-		docState.SetConversionStatus(state.ConversionStatusCompleted)
+// syncDocuments syncs the documents listed from the API, or plans their sync in a dry
+// run, after pruning state entries for documents not in cloudIDs (if non-nil)
+func (o *Orchestrator) syncDocuments(ctx context.Context, docs []rmclient.Document, cloudIDs map[string]bool, startTime time.Time) *Result {
+	result := NewResult()
 
-		// Update document metadata (in case it changed)
-		docState.Name = doc.Name
-		docState.Parent = doc.Parent
-		docState.Type = doc.Type
-		docState.Labels = doc.Tags
+	// Step 2: Load current sync state, dropping documents deleted from the cloud
+	if err := o.stateStore.Load(); err != nil {
+		o.logger.WithFields("error", err).Warn("Failed to load state, starting fresh")
+	}
+	if cloudIDs != nil {
+		result.Pruned = o.pruneDeleted(cloudIDs)
+		result.PruneDryRun = o.prune.dryRun
+	}
+	currentState := o.stateStore.GetState()
+	o.names = newOutputNamer(o.config.DuplicateNames, currentState)
+
+	// Step 3: Identify new/changed documents using a snapshot index of the state
+	index := o.stateStore.BuildIndex()
+	plan := o.planSync(docs, index)
+	o.orderPlan(plan, index)
+	o.logger.WithFields("count", len(plan)).Info("Identified documents to sync")
+
+	if o.dryRun {
+		result.DryRun = true
+		for _, p := range plan {
+			result.Planned = append(result.Planned, PlannedDocument{
+				DocumentID: p.doc.ID,
+				Title:      p.doc.Name,
+				Version:    p.doc.Version,
+				Reason:     p.reason,
+			})
+		}
+		result.Duration = time.Since(startTime)
+		result.TotalDocuments = len(docs)
 
-		currentState.AddDocument(docState)
+		o.logger.WithFields("total", result.TotalDocuments, "planned", len(result.Planned)).
+			Info("Dry run completed, no documents were processed")
+		return result
+	}
 
-		// Save state after each document
-		if err := o.stateStore.Save(); err != nil {
-			o.logger.WithFields("error", err).Warn("Failed to save state")
-		}
+	docsToSync := make([]rmclient.Document, len(plan))
+	for i, p := range plan {
+		docsToSync[i] = p.doc
 	}
 
+	// Step 4: Process documents, retrying failures and saving state as it goes
+	ocrBefore := o.ocrMetrics().Snapshot()
+	o.processDocuments(ctx, docsToSync, result)
+	result.OCR = o.ocrMetrics().Snapshot().Sub(ocrBefore)
+
 	// Step 5: Finalize result
 	result.Duration = time.Since(startTime)
 	result.TotalDocuments = len(docs)
 	result.ProcessedDocuments = len(docsToSync)
+	o.recordSyncRun(startTime, result)
 
 	o.logger.WithFields(
 		"total", result.TotalDocuments,
@@ -170,59 +298,176 @@ func (o *Orchestrator) Sync(ctx context.Context) (*Result, error) {
 		"failed", result.FailureCount,
 		"duration", result.Duration,
 	).Info("Sync workflow completed")
+	if !result.OCR.IsZero() {
+		o.logger.WithFields(
+			"cache_hits", result.OCR.CacheHits,
+			"cache_misses", result.OCR.CacheMisses,
+			"fallbacks", result.OCR.Fallbacks,
+		).Info("OCR usage")
+	}
 
-	return result, nil
+	return result
+}
+
+// recordSyncRun adds the sync to the state's history and saves it
+// No-op syncs are coalesced by the state, so repeated syncs of an unchanged library
+// don't grow the history.
+func (o *Orchestrator) recordSyncRun(startTime time.Time, result *Result) {
+	run := state.SyncRun{
+		Started:   startTime,
+		Finished:  time.Now(),
+		Processed: result.ProcessedDocuments,
+		Succeeded: result.SuccessCount,
+		Failed:    result.FailureCount,
+	}
+	if !result.PruneDryRun {
+		run.Pruned = len(result.Pruned)
+	}
+	o.stateStore.RecordSyncRun(run)
+	if err := o.stateStore.Save(); err != nil {
+		o.logger.WithFields("error", err).Warn("Failed to save sync history")
+	}
+}
+
+// processDocuments syncs docs with up to o.concurrency documents in parallel
+//
+// Documents are started in order, so with a concurrency of 1 they are processed
+// one after another.
+func (o *Orchestrator) processDocuments(ctx context.Context, docs []rmclient.Document, result *Result) {
+	if o.pipeline > 0 {
+		o.processPipelined(ctx, docs, result)
+		return
+	}
+
+	workers := o.concurrency
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(docs) {
+		workers = len(docs)
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				o.syncDocument(ctx, docs[i], i+1, len(docs), result)
+			}
+		}()
+	}
+
+	for i := range docs {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// plannedSync is a document that needs syncing and the reason why
+type plannedSync struct {
+	doc    rmclient.Document
+	reason SyncReason
 }
 
-// identifyDocumentsToSync compares API documents with state to find new/changed documents
-func (o *Orchestrator) identifyDocumentsToSync(docs []rmclient.Document, currentState *state.SyncState) []rmclient.Document {
-	var toSync []rmclient.Document
+// planSync compares API documents with the state index and returns the documents to
+// sync, in order, with the reason each needs syncing
+// A document whose version changed is still downloaded, but not converted again if its
+// content hash matches the last sync; see unchangedContent.
+func (o *Orchestrator) planSync(docs []rmclient.Document, index *state.Index) []plannedSync {
+	var toSync []plannedSync
 
 	for _, doc := range docs {
 		// Check if document exists in state
-		docState := currentState.GetDocument(doc.ID)
+		entry, exists := index.Lookup(doc.ID)
+
+		// Skip documents that keep failing until a new version is available
+		if index.RetriesExhausted(doc.ID, doc.Version, o.config.MaxRetries) {
+			o.logger.WithFields("id", doc.ID, "name", doc.Name, "version", doc.Version, "retries", entry.RetryCount).
+				Debug("Document failed too many times, skipping until its version changes")
+			continue
+		}
 
 		// Sync if document is new
-		if docState == nil {
-			toSync = append(toSync, doc)
+		if !exists || !entry.Synced {
+			toSync = append(toSync, plannedSync{doc: doc, reason: SyncReasonNew})
 			continue
 		}
 
-		// Use NeedsSync to check if document has changed (version or modification time)
-		if docState.NeedsSync(doc.Version, doc.ModifiedClient) {
+		// Check if document has changed (version or modification time)
+		if index.NeedsSync(doc.ID, doc.Version, doc.ModifiedClient) {
 			o.logger.WithFields(
 				"id", doc.ID,
 				"name", doc.Name,
-				"local_version", docState.Version,
+				"local_version", entry.Version,
 				"remote_version", doc.Version,
-				"local_modified", docState.ModifiedClient,
+				"local_modified", time.Unix(entry.Modified, 0),
 				"remote_modified", doc.ModifiedClient,
 			).Info("Document changed, will re-sync")
-			toSync = append(toSync, doc)
+			toSync = append(toSync, plannedSync{doc: doc, reason: SyncReasonVersionChanged})
 			continue
 		}
 
 		// Check if local file is missing (requires resync)
-		if docState.LocalPath != "" {
-			if _, err := os.Stat(docState.LocalPath); os.IsNotExist(err) {
-				o.logger.WithFields("id", doc.ID, "path", docState.LocalPath).
+		if entry.LocalPath != "" {
+			if _, err := os.Stat(entry.LocalPath); os.IsNotExist(err) {
+				o.logger.WithFields("id", doc.ID, "path", entry.LocalPath).
 					Info("Local file missing, will re-sync")
-				toSync = append(toSync, doc)
+				toSync = append(toSync, plannedSync{doc: doc, reason: SyncReasonOutputMissing})
+				continue
 			}
 		}
+
+		// Work deferred by an earlier sync, even one before a restart, is picked up again
+		if entry.Deferred == state.DeferredOCR && o.ocrEnabled() {
+			o.logger.WithFields("id", doc.ID, "name", doc.Name, "reason", entry.Deferred).
+				Info("Document has deferred work, will re-sync")
+			toSync = append(toSync, plannedSync{doc: doc, reason: SyncReasonDeferred})
+			continue
+		}
+
+		// Documents synced while OCR was disabled are synced again to add the text layer
+		// PNG exports have no text layer to add, so they aren't synced again for OCR
+		if o.ocrEnabled() && entry.NeedsOCR &&
+			o.outputFormat(doc, o.folderPath(doc.ID)) != config.OutputFormatPNG {
+			o.logger.WithFields("id", doc.ID, "name", doc.Name).Info("Document not OCR'd, will re-sync")
+			toSync = append(toSync, plannedSync{doc: doc, reason: SyncReasonNeedsOCR})
+		}
 	}
 
 	return toSync
 }
 
 // processDocument processes a single document through the complete pipeline
-func (o *Orchestrator) processDocument(_ context.Context, doc rmclient.Document, docNum, totalDocs int) (*DocumentResult, error) {
+// A document already downloaded by the pipeline's download stage is passed as download,
+// and is used instead of downloading it again.
+func (o *Orchestrator) processDocument(ctx context.Context, doc rmclient.Document, docNum, totalDocs int, download *prefetchedDownload) (*DocumentResult, error) {
 	result := &DocumentResult{
 		DocumentID: doc.ID,
 		Title:      doc.Name,
+		Labels:     doc.Tags,
 		StartTime:  time.Now(),
 	}
 
+	// Stage 1: Determine output path with folder structure
+	outputPath, format, err := o.documentOutput(doc, docNum, result)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep an output that is newer than the document, such as a PDF edited locally
+	if o.keepNewerOutput(doc, outputPath) {
+		existing := o.stateStore.GetDocument(doc.ID)
+		if existing == nil {
+			existing = &state.DocumentState{}
+		}
+		keepExistingOutput(result, existing, outputPath)
+		result.Hash = existing.Hash
+		return result, nil
+	}
+
 	// Create temporary directory for processing
 	tmpDir, err := os.MkdirTemp("", fmt.Sprintf("rmsync-%s-*", doc.ID))
 	if err != nil {
@@ -232,31 +477,60 @@ func (o *Orchestrator) processDocument(_ context.Context, doc rmclient.Document,
 		_ = os.RemoveAll(tmpDir)
 	}()
 
-	// Stage 1: Download .rmdoc file
-	o.logger.WithFields("document", docNum, "total", totalDocs).
-		Info("Downloading document")
+	// Stage 2: Download .rmdoc file
+	rmdocPath, err := o.documentRmdoc(doc, docNum, totalDocs, download, tmpDir, result)
+	if err != nil {
+		return nil, err
+	}
 
-	rmdocPath := filepath.Join(tmpDir, fmt.Sprintf("%s.rmdoc", doc.ID))
-	if err := o.rmClient.DownloadDocument(doc.ID, rmdocPath); err != nil {
-		return nil, fmt.Errorf("download failed: %w", err)
+	// A version bump without a content change keeps the existing output
+	if result.Hash != "" && o.unchangedContent(doc, result.Hash, outputPath, format) {
+		keepExistingOutput(result, o.stateStore.GetDocument(doc.ID), outputPath)
+		o.logger.WithFields("id", doc.ID, "version", doc.Version, "output", outputPath).
+			Info("Content unchanged since the last sync, keeping the existing output")
+		return result, nil
 	}
 
-	// Stage 2: Convert .rmdoc to PDF
-	o.logger.WithFields("document", docNum, "total", totalDocs).
-		Info("Converting to PDF")
+	if format == config.OutputFormatPNG {
+		o.logger.WithFields("document", docNum, "total", totalDocs, "output", outputPath).
+			Info("Exporting pages as PNG")
+		return o.exportPNG(rmdocPath, outputPath, result)
+	}
 
+	// Stage 3: Convert .rmdoc to PDF, reusing the previous PDF if pages were only reordered
+	// OCR is left to the converter, which renders each page, OCRs it, and adds the
+	// searchable text layer, so synced PDFs match those from `legible convert`
 	pdfPath := filepath.Join(tmpDir, fmt.Sprintf("%s.pdf", doc.ID))
-	convResult, err := o.converter.ConvertRmdoc(rmdocPath, pdfPath)
+	sidecars, converted, err := o.convertOrReuse(ctx, doc, docNum, totalDocs, rmdocPath, pdfPath, result)
 	if err != nil {
-		return nil, fmt.Errorf("conversion failed: %w", err)
+		return nil, err
 	}
-	result.PageCount = convResult.PageCount
+	if !converted {
+		return result, nil
+	}
+
+	// Stage 4: Copy final PDF (with OCR text layer if enabled) to output location
+	if err := copyOutputs(pdfPath, outputPath, sidecars); err != nil {
+		return nil, err
+	}
+
+	result.OutputPath = outputPath
+	result.Duration = time.Since(result.StartTime)
+
+	o.logger.WithFields(
+		"document", docNum,
+		"total", totalDocs,
+		"output", outputPath,
+		"duration", result.Duration,
+	).Info("Document processing completed")
 
-	// Note: OCR processing is handled internally by the converter when enabled
-	// The converter will render PDF pages to images, perform OCR via Ollama,
-	// and add a searchable text layer to the PDF automatically
+	return result, nil
+}
 
-	// Stage 3: Determine output path with folder structure
+// documentOutput returns the output path of a document, in the output directory's copy
+// of its cloud folder, which it creates, and its output format, recording the folder
+// on result
+func (o *Orchestrator) documentOutput(doc rmclient.Document, docNum int, result *DocumentResult) (string, string, error) {
 	// Get the folder path for this document from reMarkable
 	folderPath, err := o.rmClient.GetFolderPath(doc.ID)
 	if err != nil {
@@ -264,6 +538,7 @@ func (o *Orchestrator) processDocument(_ context.Context, doc rmclient.Document,
 			Warn("Failed to get folder path, saving to root output directory")
 		folderPath = "" // Fall back to root if path lookup fails
 	}
+	result.Folder = folderPath
 
 	// Build output directory path (OutputDir + folder path)
 	outputDir := o.config.OutputDir
@@ -275,28 +550,211 @@ func (o *Orchestrator) processDocument(_ context.Context, doc rmclient.Document,
 
 	// Ensure output directory exists
 	if err := os.MkdirAll(outputDir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create output directory: %w", err)
+		return "", "", fmt.Errorf("failed to create output directory: %w", err)
 	}
 
-	outputFilename := fmt.Sprintf("%s.pdf", sanitizeFilename(doc.Name))
-	outputPath := filepath.Join(outputDir, outputFilename)
+	// Documents sharing a name in the same folder get a short ID suffix
+	outputPath := o.names.claim(outputDir, doc.Name, doc.ID)
 
-	// Copy final PDF (with OCR text layer if enabled) to output location
-	if err := copyFile(pdfPath, outputPath); err != nil {
-		return nil, fmt.Errorf("failed to copy to output directory: %w", err)
+	// PNG exports are a directory of page images named after the document
+	format := o.outputFormat(doc, folderPath)
+	if format == config.OutputFormatPNG {
+		outputPath = strings.TrimSuffix(outputPath, filepath.Ext(outputPath))
 	}
+	return outputPath, format, nil
+}
 
+// keepExistingOutput records on result that the existing output of a document, last
+// synced as existing, is kept rather than replaced
+func keepExistingOutput(result *DocumentResult, existing *state.DocumentState, outputPath string) {
+	result.PageHashes = existing.PageHashes
+	result.OCRProcessed = existing.OCRProcessed
 	result.OutputPath = outputPath
+	result.Skipped = true
 	result.Duration = time.Since(result.StartTime)
+}
 
-	o.logger.WithFields(
-		"document", docNum,
-		"total", totalDocs,
-		"output", outputPath,
-		"duration", result.Duration,
-	).Info("Document processing completed")
+// documentRmdoc returns the path of a document's .rmdoc, prefetched as download or
+// downloaded to tmpDir, recording its size and content hash on result
+func (o *Orchestrator) documentRmdoc(doc rmclient.Document, docNum, totalDocs int, download *prefetchedDownload, tmpDir string, result *DocumentResult) (string, error) {
+	var rmdocPath string
+	var err error
+	if download != nil {
+		rmdocPath, err = download.rmdocPath, download.err
+	} else {
+		o.logger.WithFields("document", docNum, "total", totalDocs).
+			Info("Downloading document")
+		rmdocPath, err = o.downloadDocument(doc, tmpDir)
+	}
+	if err != nil {
+		return "", fmt.Errorf("download failed: %w", err)
+	}
+	if info, err := os.Stat(rmdocPath); err == nil {
+		result.Size = info.Size()
+	}
 
-	return result, nil
+	hash, err := converter.ContentHash(rmdocPath)
+	if err != nil {
+		o.logger.WithFields("id", doc.ID, "error", err).Warn("Failed to hash document content")
+	}
+	result.Hash = hash
+	return rmdocPath, nil
+}
+
+// convertOrReuse writes a document's PDF to pdfPath, reusing its previous PDF if its
+// pages were only reordered, and returns the sidecars written next to it
+//
+// It returns false, with result completed, if no page of the document could be parsed.
+func (o *Orchestrator) convertOrReuse(ctx context.Context, doc rmclient.Document, docNum, totalDocs int, rmdocPath, pdfPath string, result *DocumentResult) ([]string, bool, error) {
+	pageHashes, err := converter.PageHashes(rmdocPath)
+	if err != nil {
+		o.logger.WithFields("id", doc.ID, "error", err).Warn("Failed to hash pages")
+	}
+	result.PageHashes = pageHashes
+
+	if pageHashes != nil && o.reuseReorderedPages(doc.ID, pageHashes, pdfPath) {
+		result.PageCount = len(pageHashes)
+		result.OCRProcessed = o.stateStore.GetDocument(doc.ID).OCRProcessed
+		return nil, true, nil
+	}
+
+	o.logger.WithFields("document", docNum, "total", totalDocs).
+		Info("Converting to PDF")
+	return o.convertDocument(ctx, rmdocPath, pdfPath, result)
+}
+
+// convertDocument converts a .rmdoc to pdfPath and records the conversion on result:
+// its page count, whether the PDF has an OCR text layer, and why OCR was deferred to
+// the next sync if it failed. It returns the sidecars written next to the PDF.
+//
+// A document none of whose pages could be parsed has no output under the converter's
+// skip policy, and isn't tried again until a new version is available; false is
+// returned for it, with result completed.
+func (o *Orchestrator) convertDocument(ctx context.Context, rmdocPath, pdfPath string, result *DocumentResult) ([]string, bool, error) {
+	convResult, err := o.converter.ConvertRmdocContext(ctx, rmdocPath, pdfPath)
+	if err != nil {
+		return nil, false, fmt.Errorf("conversion failed: %w", err)
+	}
+	if convResult.Skipped {
+		o.logger.WithFields("id", result.DocumentID, "warnings", convResult.Warnings).
+			Warn("No page of the document could be parsed, skipping it")
+		result.Duration = time.Since(result.StartTime)
+		return nil, false, nil
+	}
+	result.PageCount = convResult.PageCount
+	result.OCRProcessed = convResult.OCREnabled
+
+	// The PDF is kept without its text layer, and OCR is queued for the next sync
+	if o.ocrEnabled() && !convResult.OCREnabled {
+		result.OCRDeferred = "OCR text layer was not added"
+		if len(convResult.Warnings) > 0 {
+			result.OCRDeferred = strings.Join(convResult.Warnings, "; ")
+		}
+		o.logger.WithFields("id", result.DocumentID, "reason", result.OCRDeferred).
+			Warn("OCR failed, deferring it to the next sync")
+	}
+	return sidecarPaths(convResult), true, nil
+}
+
+// sidecarPaths returns the transcripts, OCR sidecars, page tag PDFs, and chunk PDFs the
+// converter wrote next to a PDF
+func sidecarPaths(convResult *converter.ConversionResult) []string {
+	var sidecars []string
+	for _, sidecar := range []string{convResult.MarkdownPath, convResult.SidecarPath} {
+		if sidecar != "" {
+			sidecars = append(sidecars, sidecar)
+		}
+	}
+	for _, tagPath := range convResult.PageTagPaths {
+		sidecars = append(sidecars, tagPath)
+	}
+	sidecars = append(sidecars, convResult.ChunkPaths...)
+	return sidecars
+}
+
+// copyOutputs copies a converted PDF to outputPath, and the sidecars written next to
+// it next to outputPath
+func copyOutputs(pdfPath, outputPath string, sidecars []string) error {
+	if err := copyFile(pdfPath, outputPath); err != nil {
+		return fmt.Errorf("failed to copy to output directory: %w", err)
+	}
+
+	for _, sidecar := range sidecars {
+		sidecarOutput := strings.TrimSuffix(outputPath, ".pdf") + strings.TrimPrefix(sidecar, strings.TrimSuffix(pdfPath, ".pdf"))
+		if err := copyFile(sidecar, sidecarOutput); err != nil {
+			return fmt.Errorf("failed to copy %s to output directory: %w", filepath.Base(sidecar), err)
+		}
+	}
+	return nil
+}
+
+// keepNewerOutput reports whether an existing output PDF should be kept rather than
+// overwritten: under the skip-newer policy, when it was modified after the document
+func (o *Orchestrator) keepNewerOutput(doc rmclient.Document, outputPath string) bool {
+	if o.config.ExistingOutput != config.ExistingOutputSkipNewer {
+		return false
+	}
+
+	info, err := os.Stat(outputPath)
+	if err != nil || !info.ModTime().After(doc.ModifiedClient) {
+		return false
+	}
+
+	o.logger.WithFields("id", doc.ID, "output", outputPath, "output_modified", info.ModTime(),
+		"document_modified", doc.ModifiedClient).Info("Output is newer than the document, keeping it")
+	return true
+}
+
+// downloadDocument downloads a document's .rmdoc, reusing a cached download of the same version when
+// a download directory is configured; otherwise the file is written to tmpDir
+func (o *Orchestrator) downloadDocument(doc rmclient.Document, tmpDir string) (string, error) {
+	if o.downloads == nil {
+		rmdocPath := filepath.Join(tmpDir, fmt.Sprintf("%s.rmdoc", doc.ID))
+		if err := o.fetchDocument(doc, rmdocPath); err != nil {
+			return "", err
+		}
+		return rmdocPath, nil
+	}
+
+	rmdocPath, cached, err := o.downloads.fetch(doc.ID, doc.Version, func(dst string) error {
+		return o.fetchDocument(doc, dst)
+	})
+	if err != nil {
+		return "", err
+	}
+	if cached {
+		o.logger.WithFields("id", doc.ID, "version", doc.Version, "path", rmdocPath).
+			Info("Reusing cached download")
+	}
+	return rmdocPath, nil
+}
+
+// unchangedContent reports whether a downloaded document has the same content hash as
+// when it was last synced to outputPath, so its existing output can be kept
+//
+// The output must still exist at the same path, and a PDF synced without OCR is
+// converted again when OCR is enabled.
+func (o *Orchestrator) unchangedContent(doc rmclient.Document, hash, outputPath, format string) bool {
+	existing := o.stateStore.GetDocument(doc.ID)
+	if existing == nil || existing.Hash != hash || existing.LocalPath != outputPath {
+		return false
+	}
+	if _, err := os.Stat(outputPath); err != nil {
+		return false
+	}
+	if format == config.OutputFormatPDF && o.ocrEnabled() && existing.NeedsOCR() {
+		return false
+	}
+	return true
+}
+
+// folderPath returns the folder path of a document, or "" (root) if it cannot be determined
+func (o *Orchestrator) folderPath(docID string) string {
+	folderPath, err := o.rmClient.GetFolderPath(docID)
+	if err != nil {
+		return ""
+	}
+	return folderPath
 }
 
 // sanitizeFilename removes or replaces characters that are invalid in filenames
@@ -1,8 +1,10 @@
 package sync
 
 import (
+	"context"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -109,7 +111,7 @@ func TestNew_MissingDependencies(t *testing.T) {
 	}
 }
 
-func TestIdentifyDocumentsToSync(t *testing.T) {
+func TestPlanSync(t *testing.T) {
 	tmpDir := t.TempDir()
 	orch := &Orchestrator{
 		config: &config.Config{
@@ -136,7 +138,7 @@ func TestIdentifyDocumentsToSync(t *testing.T) {
 	}
 
 	// Note: In real usage, docs wouldn't have duplicate IDs, but this tests the logic
-	toSync := orch.identifyDocumentsToSync(docs, currentState)
+	toSync := orch.planSync(docs, state.NewIndex(currentState))
 
 	// Should sync docs: 2, 3, and the updated version of 1
 	// Depends on deduplication logic, but at minimum should identify new and changed
@@ -145,7 +147,7 @@ func TestIdentifyDocumentsToSync(t *testing.T) {
 	}
 }
 
-func TestIdentifyDocumentsToSync_MissingLocalFile(t *testing.T) {
+func TestPlanSync_MissingLocalFile(t *testing.T) {
 	tmpDir := t.TempDir()
 	orch := &Orchestrator{
 		config: &config.Config{
@@ -199,19 +201,19 @@ func TestIdentifyDocumentsToSync_MissingLocalFile(t *testing.T) {
 		{ID: "doc3", Version: 1, ModifiedClient: modifiedTime}, // No local path
 	}
 
-	toSync := orch.identifyDocumentsToSync(docs, currentState)
+	toSync := orch.planSync(docs, state.NewIndex(currentState))
 
 	// Should only sync doc2 (missing file)
 	if len(toSync) != 1 {
 		t.Errorf("expected 1 document to sync, got %d", len(toSync))
 	}
 
-	if len(toSync) > 0 && toSync[0].ID != "doc2" {
-		t.Errorf("expected doc2 to be synced, got %s", toSync[0].ID)
+	if len(toSync) > 0 && toSync[0].doc.ID != "doc2" {
+		t.Errorf("expected doc2 to be synced, got %s", toSync[0].doc.ID)
 	}
 }
 
-func TestIdentifyDocumentsToSync_MissingFileAndVersionChange(t *testing.T) {
+func TestPlanSync_MissingFileAndVersionChange(t *testing.T) {
 	tmpDir := t.TempDir()
 	orch := &Orchestrator{
 		config: &config.Config{
@@ -237,7 +239,7 @@ func TestIdentifyDocumentsToSync_MissingFileAndVersionChange(t *testing.T) {
 		{ID: "doc1", Version: 2, ModifiedClient: baseTime.Add(1 * time.Hour)}, // Both missing file and version changed
 	}
 
-	toSync := orch.identifyDocumentsToSync(docs, currentState)
+	toSync := orch.planSync(docs, state.NewIndex(currentState))
 
 	// Should sync because of version change (checked first)
 	if len(toSync) != 1 {
@@ -346,3 +348,83 @@ func TestCopyFile_InvalidDestination(t *testing.T) {
 // Note: Full integration tests for Sync() would require mocking or test implementations
 // of rmclient, state, converter, and pdfenhancer, which is complex. The above tests
 // cover the individual components and helper functions of the orchestrator.
+
+// sidecarConverter writes a stub PDF with a Markdown transcript, a text sidecar, a page
+// tag PDF, and a chunk PDF next to it, as the converter does with those outputs enabled
+type sidecarConverter struct{}
+
+func (sidecarConverter) ConvertRmdocContext(_ context.Context, _, outputPath string) (*converter.ConversionResult, error) {
+	base := strings.TrimSuffix(outputPath, ".pdf")
+	result := converter.NewConversionResult()
+	result.MarkdownPath = base + ".md"
+	result.SidecarPath = base + ".txt"
+	result.PageTagPaths = map[string]string{"work": base + " - work.pdf"}
+	result.ChunkPaths = []string{base + "-001.pdf"}
+	for path, data := range map[string]string{
+		outputPath:                  "%PDF-1.4 stub",
+		result.MarkdownPath:         "# Notes",
+		result.SidecarPath:          "notes\f",
+		result.PageTagPaths["work"]: "%PDF-1.4 work",
+		result.ChunkPaths[0]:        "%PDF-1.4 chunk",
+	} {
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func TestProcessDocument_CopiesSidecars(t *testing.T) {
+	doc := rmclient.Document{ID: "doc-1", Name: "Notes", Type: rmclient.DocumentType, Version: 1}
+	orch, _ := newRetryTestOrchestrator(t, sidecarConverter{}, 0, doc)
+
+	result := NewResult()
+	orch.syncDocument(context.Background(), doc, 1, 1, result)
+	if result.SuccessCount != 1 {
+		t.Fatalf("sync failed: %+v", result.Failures)
+	}
+
+	// The sidecars are named after the output PDF, not the temporary conversion
+	for name, want := range map[string]string{
+		"Notes.pdf":        "%PDF-1.4 stub",
+		"Notes.md":         "# Notes",
+		"Notes.txt":        "notes\f",
+		"Notes - work.pdf": "%PDF-1.4 work",
+		"Notes-001.pdf":    "%PDF-1.4 chunk",
+	} {
+		data, err := os.ReadFile(filepath.Join(orch.config.OutputDir, name))
+		if err != nil || string(data) != want {
+			t.Errorf("%s = %q, %v, want %q", name, data, err, want)
+		}
+	}
+}
+
+// unparseableConverter converts documents as one none of whose pages could be parsed,
+// under the converter's skip policy
+type unparseableConverter struct{}
+
+func (unparseableConverter) ConvertRmdocContext(_ context.Context, _, _ string) (*converter.ConversionResult, error) {
+	result := converter.NewConversionResult()
+	result.Success, result.Unparseable, result.Skipped = true, true, true
+	result.AddWarning("Skipped the document, none of its 2 pages could be parsed")
+	return result, nil
+}
+
+func TestProcessDocument_UnparseableSkipped(t *testing.T) {
+	doc := rmclient.Document{ID: "doc-1", Name: "Notes", Type: rmclient.DocumentType, Version: 4}
+	orch, store := newRetryTestOrchestrator(t, unparseableConverter{}, 0, doc)
+
+	result := NewResult()
+	orch.syncDocument(context.Background(), doc, 1, 1, result)
+
+	// The document doesn't fail the sync, and isn't tried again until a new version
+	if result.SuccessCount != 1 || result.FailureCount != 0 {
+		t.Fatalf("successes = %d, failures = %+v, want the document skipped without failing", result.SuccessCount, result.Failures)
+	}
+	if _, err := os.Stat(filepath.Join(orch.config.OutputDir, "Notes.pdf")); !os.IsNotExist(err) {
+		t.Errorf("output stat error = %v, want no output written", err)
+	}
+	if docState := store.GetDocument(doc.ID); docState == nil || docState.Version != doc.Version || docState.LocalPath != "" {
+		t.Errorf("state = %+v, want version %d recorded without an output", docState, doc.Version)
+	}
+}